@@ -10,6 +10,7 @@ import (
 	"github.com/APTrust/registry/constants"
 	"github.com/APTrust/registry/helpers"
 	"github.com/APTrust/registry/middleware"
+	"github.com/APTrust/registry/web/api"
 	admin_api "github.com/APTrust/registry/web/api/admin"
 	common_api "github.com/APTrust/registry/web/api/common"
 	"github.com/APTrust/registry/web/webui"
@@ -44,6 +45,7 @@ func InitAppEngine(discardStdOut bool) *gin.Engine {
 	initTemplates(r)
 	initMiddleware(r)
 	initRoutes(r)
+	api.SetOpenAPIRoutes(r.Routes())
 	return r
 }
 
@@ -66,6 +68,7 @@ func initTemplates(router *gin.Engine) {
 		"formatFloat":     helpers.FormatFloat,
 		"formatInt":       helpers.FormatInt,
 		"formatInt64":     helpers.FormatInt64,
+		"humanDuration":   helpers.HumanDuration,
 		"humanSize":       helpers.HumanSize,
 		"iconFor":         helpers.IconFor,
 		"linkifyUrls":     helpers.LinkifyUrls,
@@ -112,6 +115,7 @@ func initMiddleware(router *gin.Engine) {
 
 	// Then authentication and authorization middleware
 	router.Use(middleware.Authenticate())
+	router.Use(middleware.RateLimit())
 	router.Use(middleware.Authorize())
 	router.Use(middleware.CSRF())
 }
@@ -146,6 +150,7 @@ func initRoutes(router *gin.Engine) {
 
 	router.Static("/static", "./static")
 	router.Static("/favicon.ico", "./static/img/favicon.png")
+	router.Static("/swagger", "./swagger")
 
 	webRoutes := router.Group("/")
 	{
@@ -156,6 +161,14 @@ func initRoutes(router *gin.Engine) {
 		webRoutes.POST("/alerts/mark_all_as_read", webui.AlertMarkAllAsRead)
 		webRoutes.PUT("/alerts/mark_as_unread", webui.AlertMarkAsUnreadXHR)
 
+		// Audit Log
+		webRoutes.GET("/audit_logs", webui.AuditLogIndex)
+		webRoutes.GET("/audit_logs/show/:id", webui.AuditLogShow)
+
+		// Bag Groups
+		webRoutes.GET("/bag_groups", webui.BagGroupIndex)
+		webRoutes.GET("/bag_groups/show/:id", webui.BagGroupShow)
+
 		// Deletion Requests
 		// Note that these routes are for read-only views.
 		// Routes for initiating, approving and rejecting deletions
@@ -172,6 +185,7 @@ func initRoutes(router *gin.Engine) {
 		// Reports
 		webRoutes.GET("/reports/deposits", webui.DepositReportShow)
 		webRoutes.GET("/reports/billing", webui.BillingReportShow)
+		webRoutes.GET("/reports/pipeline", webui.PipelineReportShow)
 
 		// GenericFiles
 		webRoutes.GET("/files", webui.GenericFileIndex)
@@ -180,6 +194,8 @@ func initRoutes(router *gin.Engine) {
 		webRoutes.GET("/files/request_restore/:id", webui.GenericFileRequestRestore)
 		webRoutes.POST("/files/init_delete/:id", webui.GenericFileInitDelete)
 		webRoutes.POST("/files/init_restore/:id", webui.GenericFileInitRestore)
+		webRoutes.POST("/files/undelete/:id", webui.GenericFileUndelete)
+		webRoutes.PUT("/files/undelete/:id", webui.GenericFileUndelete)
 
 		// Institutions
 		webRoutes.POST("/institutions/new", webui.InstitutionCreate)
@@ -205,10 +221,23 @@ func initRoutes(router *gin.Engine) {
 		webRoutes.POST("/objects/init_restore/:id", webui.IntellectualObjectInitRestore)
 		webRoutes.GET("/objects/events/:id", webui.IntellectualObjectEvents)
 		webRoutes.GET("/objects/files/:id", webui.IntellectualObjectFiles)
+		webRoutes.POST("/objects/undelete/:id", webui.IntellectualObjectUndelete)
+		webRoutes.PUT("/objects/undelete/:id", webui.IntellectualObjectUndelete)
+
+		// Object Versions
+		webRoutes.GET("/object_versions/diff/:id", webui.ObjectVersionDiff)
 
 		// InternalMetadata
 		webRoutes.GET("/internal_metadata", webui.InternalMetadataIndex)
 
+		// Legal Holds
+		webRoutes.GET("/legal_holds", webui.LegalHoldIndex)
+		webRoutes.GET("/legal_holds/new", webui.LegalHoldNew)
+		webRoutes.POST("/legal_holds/new", webui.LegalHoldCreate)
+		webRoutes.GET("/legal_holds/show/:id", webui.LegalHoldShow)
+		webRoutes.POST("/legal_holds/release/:id", webui.LegalHoldRelease)
+		webRoutes.PUT("/legal_holds/release/:id", webui.LegalHoldRelease)
+
 		// Maintenance
 		webRoutes.GET("/maintenance", webui.MaintenanceIndex)
 
@@ -217,6 +246,29 @@ func initRoutes(router *gin.Engine) {
 		webRoutes.GET("/events/show/:id", webui.PremisEventShow)
 		webRoutes.GET("/events/show_xhr/:id", webui.PremisEventShowXHR)
 
+		// Rate Limits
+		webRoutes.GET("/rate_limits", webui.RateLimitIndex)
+		webRoutes.GET("/rate_limits/new", webui.RateLimitOverrideNew)
+		webRoutes.POST("/rate_limits/new", webui.RateLimitOverrideCreate)
+		webRoutes.GET("/rate_limits/edit/:id", webui.RateLimitOverrideEdit)
+		webRoutes.PUT("/rate_limits/edit/:id", webui.RateLimitOverrideUpdate)
+		webRoutes.POST("/rate_limits/edit/:id", webui.RateLimitOverrideUpdate)
+		webRoutes.DELETE("/rate_limits/delete/:id", webui.RateLimitOverrideDelete)
+		webRoutes.POST("/rate_limits/delete/:id", webui.RateLimitOverrideDelete)
+
+		// Webhooks
+		webRoutes.GET("/webhooks", webui.WebhookIndex)
+		webRoutes.GET("/webhooks/new", webui.WebhookNew)
+		webRoutes.POST("/webhooks/new", webui.WebhookCreate)
+		webRoutes.GET("/webhooks/show/:id", webui.WebhookShow)
+		webRoutes.GET("/webhooks/edit/:id", webui.WebhookEdit)
+		webRoutes.PUT("/webhooks/edit/:id", webui.WebhookUpdate)
+		webRoutes.POST("/webhooks/edit/:id", webui.WebhookUpdate)
+		webRoutes.DELETE("/webhooks/delete/:id", webui.WebhookDelete)
+		webRoutes.POST("/webhooks/delete/:id", webui.WebhookDelete)
+		webRoutes.POST("/webhooks/test/:id", webui.WebhookTest)
+		webRoutes.GET("/webhooks/deliveries/:id", webui.WebhookDeliveryShow)
+
 		// WorkItems - Web UI allows only list, show, and limited editing for admin only
 		webRoutes.GET("/work_items", webui.WorkItemIndex)
 		webRoutes.GET("/work_items/show/:id", webui.WorkItemShow)
@@ -225,7 +277,14 @@ func initRoutes(router *gin.Engine) {
 		webRoutes.POST("/work_items/edit/:id", webui.WorkItemUpdate)
 		webRoutes.PUT("/work_items/requeue/:id", webui.WorkItemRequeue)
 		webRoutes.POST("/work_items/requeue/:id", webui.WorkItemRequeue)
+		webRoutes.GET("/work_items/bulk_requeue", webui.WorkItemBulkRequeuePreview)
+		webRoutes.PUT("/work_items/bulk_requeue", webui.WorkItemBulkRequeue)
+		webRoutes.POST("/work_items/bulk_requeue", webui.WorkItemBulkRequeue)
+		webRoutes.GET("/work_items/reconcile", webui.WorkItemReconcileShow)
+		webRoutes.PUT("/work_items/reconcile/:id", webui.WorkItemReconcile)
+		webRoutes.POST("/work_items/reconcile/:id", webui.WorkItemReconcile)
 		webRoutes.GET("/work_items/redis_list", webui.WorkItemRedisIndex)
+		webRoutes.GET("/work_items/redis/:id", webui.WorkItemRedisShow)
 		webRoutes.DELETE("/work_items/redis_delete/:id", webui.WorkItemRedisDelete)
 		webRoutes.POST("/work_items/redis_delete/:id", webui.WorkItemRedisDelete)
 
@@ -250,6 +309,12 @@ func initRoutes(router *gin.Engine) {
 		webRoutes.POST("/users/complete_password_reset/:id", webui.UserCompletePasswordReset)
 		webRoutes.POST("/users/get_api_key/:id", webui.UserGetAPIKey)
 
+		// API Keys
+		webRoutes.GET("/api_keys", webui.APIKeyIndex)
+		webRoutes.GET("/api_keys/new", webui.APIKeyNew)
+		webRoutes.POST("/api_keys/new", webui.APIKeyCreate)
+		webRoutes.POST("/api_keys/revoke/:id", webui.APIKeyRevoke)
+
 		// User two-factor setup
 		webRoutes.GET("/users/2fa_setup", webui.UserInit2FASetup)
 		webRoutes.POST("/users/2fa_setup", webui.UserComplete2FASetup)
@@ -276,6 +341,11 @@ func initRoutes(router *gin.Engine) {
 		webRoutes.GET("/nsq", webui.NsqShow)
 		webRoutes.POST("/nsq/init", webui.NsqInit)
 		webRoutes.POST("/nsq/admin", webui.NsqAdmin)
+		webRoutes.GET("/nsq/pauses", webui.NSQPauseWindowIndex)
+		webRoutes.GET("/nsq/pauses/new", webui.NSQPauseWindowNew)
+		webRoutes.POST("/nsq/pauses/new", webui.NSQPauseWindowCreate)
+		webRoutes.PUT("/nsq/pauses/cancel/:id", webui.NSQPauseWindowCancel)
+		webRoutes.POST("/nsq/pauses/cancel/:id", webui.NSQPauseWindowCancel)
 
 		// Accessibility Statement
 		webRoutes.GET("/accessibility_statement", webui.ShowAccessibilityStatement)
@@ -316,11 +386,22 @@ func initRoutes(router *gin.Engine) {
 	// Routes start with /member-api/v3
 	memberAPI := router.Group(fmt.Sprintf("%sv3", constants.APIPrefixMember))
 	{
+		// OpenAPI spec
+		memberAPI.GET("/openapi.json", common_api.OpenAPISpec)
+
 		// Alerts
 		// TODO: Delete this? Is there even a use case?
 		memberAPI.GET("/alerts", common_api.AlertIndex)
 		memberAPI.GET("/alerts/show/:id/:user_id", common_api.AlertShow)
 
+		// Audit Log
+		memberAPI.GET("/audit_logs", common_api.AuditLogIndex)
+		memberAPI.GET("/audit_logs/show/:id", common_api.AuditLogShow)
+
+		// Bag Groups
+		memberAPI.GET("/bag_groups", common_api.BagGroupIndex)
+		memberAPI.GET("/bag_groups/show/:id", common_api.BagGroupShow)
+
 		// Checksums
 		memberAPI.GET("/checksums", common_api.ChecksumIndex)
 		memberAPI.GET("/checksums/show/:id", common_api.ChecksumShow)
@@ -329,21 +410,42 @@ func initRoutes(router *gin.Engine) {
 		// TODO: Should we really expose this through the API?
 		memberAPI.GET("/deletions/show/:id", common_api.DeletionRequestShow)
 		memberAPI.GET("/deletions", common_api.DeletionRequestIndex)
+		memberAPI.POST("/deletions/approve/:id", common_api.DeletionRequestApprove)
+		memberAPI.POST("/deletions/cancel/:id", common_api.DeletionRequestCancel)
 
 		// Generic Files
 		memberAPI.GET("/files/show/*id", common_api.GenericFileShow)
+		memberAPI.GET("/files/manifest", common_api.GenericFileManifest)
+		memberAPI.GET("/files/changes", common_api.GenericFileChanges)
+		memberAPI.POST("/files/restore/*id", common_api.GenericFileRestore)
+		memberAPI.POST("/files/init_delete/*id", common_api.GenericFileInitDelete)
 		memberAPI.GET("/files", common_api.GenericFileIndex)
 
 		// Intellectual Objects
 		memberAPI.GET("/objects/show/*id", common_api.IntellectualObjectShow)
+		memberAPI.GET("/objects/manifest/*id", common_api.IntellectualObjectManifest)
+		memberAPI.GET("/objects/changes", common_api.IntellectualObjectChanges)
+		memberAPI.POST("/objects/restore/*id", common_api.IntellectualObjectRestore)
+		memberAPI.POST("/objects/init_delete/*id", common_api.IntellectualObjectInitDelete)
 		memberAPI.GET("/objects", common_api.IntellectualObjectIndex)
 
+		// Legal Holds
+		memberAPI.GET("/legal_holds", common_api.LegalHoldIndex)
+		memberAPI.GET("/legal_holds/show/:id", common_api.LegalHoldShow)
+
+		// Object Versions
+		memberAPI.GET("/object_versions", common_api.ObjectVersionIndex)
+		memberAPI.GET("/object_versions/show/:id", common_api.ObjectVersionShow)
+		memberAPI.GET("/object_versions/diff/:id", common_api.ObjectVersionDiff)
+
 		// Premis Events
 		memberAPI.GET("/events/show/*id", common_api.PremisEventShow)
+		memberAPI.GET("/events/changes", common_api.PremisEventChanges)
 		memberAPI.GET("/events", common_api.PremisEventIndex)
 
 		// Work Items
 		memberAPI.GET("/items/show/:id", common_api.WorkItemShow)
+		memberAPI.GET("/items/history/:id", common_api.WorkItemHistory)
 		memberAPI.GET("/items", common_api.WorkItemIndex)
 
 	}
@@ -355,12 +457,23 @@ func initRoutes(router *gin.Engine) {
 	// Routes start with /admin-api/v3
 	adminAPI := router.Group(fmt.Sprintf("%sv3", constants.APIPrefixAdmin))
 	{
+		// OpenAPI spec
+		adminAPI.GET("/openapi.json", common_api.OpenAPISpec)
+
 		// Alerts
 		// TODO: Delete this? Admin API doesn't really need it.
 		adminAPI.GET("/alerts", common_api.AlertIndex)
 		adminAPI.GET("/alerts/show/:id/:user_id", common_api.AlertShow)
 		adminAPI.POST("/alerts/generate_failed_fixity_alerts", admin_api.GenerateFailedFixityAlerts)
 
+		// Audit Log
+		adminAPI.GET("/audit_logs", common_api.AuditLogIndex)
+		adminAPI.GET("/audit_logs/show/:id", common_api.AuditLogShow)
+
+		// Bag Groups
+		adminAPI.GET("/bag_groups", common_api.BagGroupIndex)
+		adminAPI.GET("/bag_groups/show/:id", common_api.BagGroupShow)
+
 		// Checksums
 		adminAPI.GET("/checksums", common_api.ChecksumIndex)
 		adminAPI.GET("/checksums/show/:id", common_api.ChecksumShow)
@@ -373,11 +486,14 @@ func initRoutes(router *gin.Engine) {
 
 		// Generic Files
 		adminAPI.GET("/files/show/*id", common_api.GenericFileShow)
+		adminAPI.GET("/files/manifest", common_api.GenericFileManifest)
+		adminAPI.GET("/files/changes", common_api.GenericFileChanges)
 		adminAPI.GET("/files", admin_api.GenericFileIndex)
 		adminAPI.DELETE("/files/delete/:id", admin_api.GenericFileDelete)
 		adminAPI.POST("/files/create/:institution_id", admin_api.GenericFileCreate)
 		adminAPI.POST("/files/create_batch/:institution_id", admin_api.GenericFileCreateBatch)
 		adminAPI.PUT("/files/update/:id", admin_api.GenericFileUpdate)
+		adminAPI.PUT("/files/undelete/:id", admin_api.GenericFileUndelete)
 
 		// Institutions
 		adminAPI.GET("/institutions", admin_api.InstitutionIndex)
@@ -385,16 +501,29 @@ func initRoutes(router *gin.Engine) {
 
 		// Intellectual Objects
 		adminAPI.GET("/objects/show/*id", common_api.IntellectualObjectShow)
+		adminAPI.GET("/objects/manifest/*id", common_api.IntellectualObjectManifest)
+		adminAPI.GET("/objects/changes", common_api.IntellectualObjectChanges)
 		adminAPI.GET("/objects", common_api.IntellectualObjectIndex)
 		adminAPI.POST("/objects/create/:institution_id", admin_api.IntellectualObjectCreate)
 		adminAPI.PUT("/objects/update/:id", admin_api.IntellectualObjectUpdate)
 		adminAPI.DELETE("/objects/delete/:id", admin_api.IntellectualObjectDelete)
+		adminAPI.PUT("/objects/undelete/:id", admin_api.IntellectualObjectUndelete)
 		adminAPI.POST("/objects/init_restore/:id", admin_api.IntellectualObjectInitRestore)
 		adminAPI.POST("/objects/init_batch_delete", admin_api.IntellectualObjectInitBatchDelete)
 
+		// Legal Holds
+		adminAPI.GET("/legal_holds", common_api.LegalHoldIndex)
+		adminAPI.GET("/legal_holds/show/:id", common_api.LegalHoldShow)
+
+		// Object Versions
+		adminAPI.GET("/object_versions", common_api.ObjectVersionIndex)
+		adminAPI.GET("/object_versions/show/:id", common_api.ObjectVersionShow)
+		adminAPI.GET("/object_versions/diff/:id", common_api.ObjectVersionDiff)
+
 		// Premis Events
 		adminAPI.POST("/events/create", admin_api.PremisEventCreate)
 		adminAPI.GET("/events/show/*id", common_api.PremisEventShow)
+		adminAPI.GET("/events/changes", common_api.PremisEventChanges)
 		adminAPI.GET("/events", common_api.PremisEventIndex)
 
 		// Storage Records
@@ -402,12 +531,21 @@ func initRoutes(router *gin.Engine) {
 		adminAPI.GET("/storage_records/show/:id", admin_api.StorageRecordShow)
 		adminAPI.GET("/storage_records", admin_api.StorageRecordIndex)
 
+		// Reports
+		adminAPI.GET("/reports/pipeline", admin_api.PipelineReportShow)
+
 		// Work Items
 		adminAPI.PUT("/items/requeue/:id", admin_api.WorkItemRequeue)
+		adminAPI.PUT("/items/bulk_requeue", admin_api.WorkItemBulkRequeue)
+		adminAPI.GET("/items/reconcile", admin_api.WorkItemReconcileShow)
+		adminAPI.PUT("/items/reconcile/:id", admin_api.WorkItemReconcile)
 		adminAPI.POST("/items/create/:institution_id", admin_api.WorkItemCreate)
 		adminAPI.PUT("/items/update/:id", admin_api.WorkItemUpdate)
 		adminAPI.GET("/items/show/:id", common_api.WorkItemShow)
+		adminAPI.GET("/items/history/:id", common_api.WorkItemHistory)
+		adminAPI.GET("/items/stage_timing", admin_api.WorkItemStageTiming)
 		adminAPI.GET("/items", common_api.WorkItemIndex)
+		adminAPI.GET("/items/redis/:id", admin_api.WorkItemRedisShow)
 		adminAPI.DELETE("/items/redis_delete/:id", admin_api.WorkItemRedisDelete)
 
 		// Special test endpoints
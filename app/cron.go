@@ -1,6 +1,7 @@
 package app
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/APTrust/registry/common"
@@ -28,6 +29,12 @@ func initCronJobs(ctx *common.APTContext) {
 		updateHistoricalDepositStats(ctx)
 		populateEmptyDepositStats(ctx)
 		initRestorationSpotTests(ctx)
+		deliverWebhooks(ctx)
+		deliverNSQOutbox(ctx)
+		cleanUpNSQOutbox(ctx)
+		applyNSQPauseWindows(ctx)
+		detectStalledWorkItems(ctx)
+		reconcileWorkItems(ctx)
 		cronJobsInitialized = true
 	}
 }
@@ -161,6 +168,219 @@ func populateEmptyDepositStats(ctx *common.APTContext) {
 	}
 }
 
+// deliverWebhooks sends pending webhook deliveries every 30 seconds.
+// Deliveries that fail are rescheduled with exponential backoff. See
+// pgmodels.WebhookDelivery.Attempt.
+//
+// If we have multiple instances of Registry running in multiple containers,
+// each instance claims a different batch of deliveries, so no event is
+// sent twice at the same time.
+func deliverWebhooks(ctx *common.APTContext) {
+	if !cronJobsInitialized {
+		ctx.Log.Info().Msg("cron: initializing webhook delivery. This will run every 30 seconds.")
+		go func() {
+			for {
+				runWebhookDeliveries(ctx)
+				time.Sleep(30 * time.Second)
+			}
+		}()
+	}
+}
+
+func runWebhookDeliveries(ctx *common.APTContext) {
+	deliveries, err := pgmodels.WebhookDeliveriesClaimDue(50)
+	if err != nil {
+		ctx.Log.Error().Msgf("cron: error getting pending webhook deliveries: %v", err)
+		return
+	}
+	for _, delivery := range deliveries {
+		err = delivery.Attempt()
+		if err != nil {
+			ctx.Log.Error().Msgf("cron: error attempting webhook delivery %d: %v", delivery.ID, err)
+			continue
+		}
+		ctx.Log.Info().Msgf("cron: webhook delivery %d (%s) to webhook %d: status %s after %d attempts, response code %d %s", delivery.ID, delivery.EventType, delivery.WebhookID, delivery.Status, delivery.Attempts, delivery.ResponseCode, delivery.Error)
+	}
+}
+
+// deliverNSQOutbox pushes pending NSQ outbox messages into NSQ every
+// 10 seconds. Messages that fail are rescheduled with exponential
+// backoff. See pgmodels.NSQOutboxMessage.Attempt. When a message runs
+// out of attempts, this alerts APTrust admins that its WorkItem was
+// never queued.
+//
+// If we have multiple instances of Registry running in multiple containers,
+// each instance claims a different batch of messages, so no item is
+// queued twice at the same time.
+func deliverNSQOutbox(ctx *common.APTContext) {
+	if !cronJobsInitialized {
+		ctx.Log.Info().Msg("cron: initializing NSQ outbox delivery. This will run every 10 seconds.")
+		go func() {
+			for {
+				runNSQOutboxDeliveries(ctx)
+				time.Sleep(10 * time.Second)
+			}
+		}()
+	}
+}
+
+func runNSQOutboxDeliveries(ctx *common.APTContext) {
+	messages, err := pgmodels.NSQOutboxClaimDue(100)
+	if err != nil {
+		ctx.Log.Error().Msgf("cron: error getting pending NSQ outbox messages: %v", err)
+		return
+	}
+	failed := false
+	for _, msg := range messages {
+		err = msg.Attempt()
+		if err != nil {
+			ctx.Log.Error().Msgf("cron: error attempting NSQ outbox message %d: %v", msg.ID, err)
+			continue
+		}
+		ctx.Log.Info().Msgf("cron: NSQ outbox message %d (WorkItem %d to %s): status %s after %d attempts %s", msg.ID, msg.WorkItemID, msg.Topic, msg.Status, msg.Attempts, msg.Error)
+		if msg.Status == constants.OutboxStatusFailed {
+			failed = true
+		}
+	}
+	if failed {
+		runUnqueuedItemsAlert(ctx)
+	}
+}
+
+func runUnqueuedItemsAlert(ctx *common.APTContext) {
+	registryURL := fmt.Sprintf("%s://%s", ctx.Config.HTTPScheme(), ctx.Config.Cookies.Domain)
+	alert, err := pgmodels.CreateUnqueuedWorkItemsAlert(registryURL)
+	if err != nil {
+		ctx.Log.Error().Msgf("cron: error creating unqueued work items alert: %v", err)
+		return
+	}
+	if alert != nil {
+		ctx.Log.Warn().Msgf("cron: created alert %d for %d unqueued work items", alert.ID, len(alert.WorkItems))
+	}
+}
+
+// cleanUpNSQOutbox runs hourly, deleting NSQ outbox messages that were
+// delivered more than pgmodels.NSQOutboxRetention ago. It also alerts
+// APTrust admins about any failed messages that deliverNSQOutbox
+// couldn't alert them about, for example because the database was
+// briefly unavailable.
+func cleanUpNSQOutbox(ctx *common.APTContext) {
+	if !cronJobsInitialized {
+		ctx.Log.Info().Msg("cron: initializing NSQ outbox cleanup. This will run every hour.")
+		go func() {
+			// Stagger this, so it doesn't overlap with the stats updates
+			time.Sleep(48 * time.Minute)
+			for {
+				runUnqueuedItemsAlert(ctx)
+				before := time.Now().UTC().Add(-pgmodels.NSQOutboxRetention)
+				count, err := pgmodels.NSQOutboxDeleteDelivered(before)
+				if err != nil {
+					ctx.Log.Error().Msgf("cron: error deleting delivered NSQ outbox messages: %v", err)
+				} else {
+					ctx.Log.Info().Msgf("cron: deleted %d NSQ outbox messages delivered before %s", count, before.Format(time.RFC3339))
+				}
+				time.Sleep(1 * time.Hour)
+			}
+		}()
+	}
+}
+
+// applyNSQPauseWindows runs every minute, pausing NSQ topics and
+// channels whose scheduled pause windows have started and unpausing
+// those whose windows have ended or were cancelled. See
+// pgmodels.ApplyNSQPauseWindows.
+//
+// If we have multiple instances of Registry running in multiple containers,
+// they may all pause or unpause the same topic. That's harmless, because
+// pausing a paused topic does nothing.
+func applyNSQPauseWindows(ctx *common.APTContext) {
+	if !cronJobsInitialized {
+		ctx.Log.Info().Msg("cron: initializing NSQ pause windows. This will run every minute.")
+		go func() {
+			for {
+				err := pgmodels.ApplyNSQPauseWindows()
+				if err != nil {
+					ctx.Log.Error().Msgf("cron: error applying NSQ pause windows: %v", err)
+				}
+				time.Sleep(1 * time.Minute)
+			}
+		}()
+	}
+}
+
+// detectStalledWorkItems runs hourly, alerting APTrust admins about
+// WorkItems that have been stuck in one stage longer than the thresholds
+// in ctx.Config.StalledItems allow. Each stalled item appears in only
+// one alert, so admins won't get the same news every hour. See
+// pgmodels.StalledWorkItems.
+func detectStalledWorkItems(ctx *common.APTContext) {
+	if !cronJobsInitialized {
+		if !ctx.Config.StalledItems.Enabled {
+			ctx.Log.Info().Msg("cron: stalled work item detection is disabled.")
+			return
+		}
+		ctx.Log.Info().Msg("cron: initializing stalled work item detection. This will run every hour.")
+		go func() {
+			// Stagger this, so it doesn't overlap with the stats updates
+			time.Sleep(24 * time.Minute)
+			for {
+				runStalledItemDetection(ctx)
+				time.Sleep(1 * time.Hour)
+			}
+		}()
+	}
+}
+
+func runStalledItemDetection(ctx *common.APTContext) {
+	registryURL := fmt.Sprintf("%s://%s", ctx.Config.HTTPScheme(), ctx.Config.Cookies.Domain)
+	alert, err := pgmodels.CreateStalledWorkItemsAlert(ctx.Config.StalledItems, registryURL)
+	if err != nil {
+		ctx.Log.Error().Msgf("cron: error creating stalled work items alert: %v", err)
+		return
+	}
+	if alert == nil {
+		ctx.Log.Info().Msg("cron: no newly stalled work items")
+		return
+	}
+	ctx.Log.Warn().Msgf("cron: created alert %d for %d stalled work items", alert.ID, len(alert.WorkItems))
+}
+
+// reconcileWorkItems runs hourly, cross-checking incomplete WorkItems
+// against NSQ and Redis and alerting APTrust admins about orphans no
+// worker is handling. Admins fix orphans on the reconciliation page.
+// See pgmodels.ReconcileWorkItems.
+func reconcileWorkItems(ctx *common.APTContext) {
+	if !cronJobsInitialized {
+		if !ctx.Config.Reconciliation.Enabled {
+			ctx.Log.Info().Msg("cron: work item reconciliation is disabled.")
+			return
+		}
+		ctx.Log.Info().Msg("cron: initializing work item reconciliation. This will run every hour.")
+		go func() {
+			// Stagger this, so it doesn't overlap with stalled item detection
+			time.Sleep(36 * time.Minute)
+			for {
+				runWorkItemReconciliation(ctx)
+				time.Sleep(1 * time.Hour)
+			}
+		}()
+	}
+}
+
+func runWorkItemReconciliation(ctx *common.APTContext) {
+	registryURL := fmt.Sprintf("%s://%s", ctx.Config.HTTPScheme(), ctx.Config.Cookies.Domain)
+	alert, err := pgmodels.CreateOrphanedWorkItemsAlert(ctx.Config.Reconciliation, registryURL)
+	if err != nil {
+		ctx.Log.Error().Msgf("cron: error creating orphaned work items alert: %v", err)
+		return
+	}
+	if alert == nil {
+		ctx.Log.Info().Msg("cron: no newly orphaned work items")
+		return
+	}
+	ctx.Log.Warn().Msgf("cron: created alert %d for %d orphaned work items", alert.ID, len(alert.WorkItems))
+}
+
 func initRestorationSpotTests(ctx *common.APTContext) {
 	if !cronJobsInitialized {
 		ctx.Log.Info().Msg("cron: initializing restoration spot tests. These will run every 24 hours.")
@@ -232,25 +452,13 @@ func scheduleSpotRestoration(ctx *common.APTContext, inst *pgmodels.Institution,
 		return err
 	}
 	ctx.Log.Info().Msgf("runRestorationSpotTest: object %d - %s chosen for restore for %s", objView.ID, objView.Identifier, inst.Identifier)
+	// This creates the work item and queues it through the NSQ outbox.
 	workItem, err := pgmodels.NewRestorationItem(obj, nil, systemUser)
 	if err != nil {
 		ctx.Log.Error().Msgf("runRestorationSpotTest: error creating restoration work item for %s: %v", obj.Identifier, err)
 		return err
 	}
 
-	// Queue the work item in NSQ
-	topic := constants.TopicObjectRestore
-	if obj.IsGlacierOnly() {
-		topic = constants.TopicGlacierRestore
-	}
-	err = ctx.NSQClient.Enqueue(topic, workItem.ID)
-	if err != nil {
-		// Log this error and keep going.
-		// Admin can manually queue the item, if necessary.
-		// We want to record the LastSpotRestoreWorkItemID.
-		ctx.Log.Error().Msgf("runRestorationSpotTest: error queuing work item %d in topic %s: %v", workItem.ID, topic, err)
-	}
-
 	inst.LastSpotRestoreWorkItemID = workItem.ID
 	err = inst.Save()
 	if err != nil {
@@ -16,7 +16,10 @@ var templateNames = []string{
 	"alerts/deletion_confirmed.txt",
 	"alerts/deletion_requested.txt",
 	"alerts/failed_fixity.txt",
+	"alerts/legal_hold_placed.txt",
+	"alerts/legal_hold_released.txt",
 	"alerts/restoration_completed.txt",
+	"alerts/stalled_items.txt",
 }
 
 // Make sure these templates are loaded, and that they have
@@ -91,6 +91,78 @@ type EmailConfig struct {
 	SesEndpoint string
 }
 
+// RateLimitConfig describes the default token-bucket rate limits for
+// API requests. Each API user gets a bucket of UserBurst requests that
+// refills at UserPerMinute requests per minute, and all of the users at
+// an institution share a bucket of InstitutionBurst requests that refills
+// at InstitutionPerMinute. APTrust admins can override these limits for
+// specific users and institutions. See pgmodels.RateLimitOverride.
+type RateLimitConfig struct {
+	Enabled              bool
+	UserPerMinute        int
+	UserBurst            int
+	InstitutionPerMinute int
+	InstitutionBurst     int
+}
+
+// defaultStalledItemThreshold applies when STALLED_ITEMS_DEFAULT_THRESHOLD
+// is missing or invalid.
+const defaultStalledItemThreshold = 6 * time.Hour
+
+// StalledItemConfig describes how long a WorkItem may remain in one
+// stage before the stalled item detector tells APTrust admins about it.
+// StageThresholds overrides DefaultThreshold for specific stages, since
+// stages like Store and Restoring legitimately take much longer than
+// others. See pgmodels.StalledWorkItems.
+type StalledItemConfig struct {
+	Enabled          bool
+	DefaultThreshold time.Duration
+	StageThresholds  map[string]time.Duration
+}
+
+// ThresholdFor returns how long an item may remain in the
+// specified stage before we consider it stalled.
+func (sc *StalledItemConfig) ThresholdFor(stage string) time.Duration {
+	if threshold, ok := sc.StageThresholds[stage]; ok {
+		return threshold
+	}
+	return sc.DefaultThreshold
+}
+
+// MinThreshold returns the shortest threshold for any stage.
+func (sc *StalledItemConfig) MinThreshold() time.Duration {
+	min := sc.DefaultThreshold
+	for _, threshold := range sc.StageThresholds {
+		if threshold < min {
+			min = threshold
+		}
+	}
+	return min
+}
+
+// defaultReconciliationGracePeriod applies when RECONCILIATION_GRACE_PERIOD
+// is missing or invalid.
+const defaultReconciliationGracePeriod = 1 * time.Hour
+
+// ReconciliationConfig controls the job that cross-checks incomplete
+// WorkItems against NSQ and Redis. The job ignores items updated within
+// the last GracePeriod, because they may simply be in transit between
+// the registry, NSQ and the workers. See pgmodels.ReconcileWorkItems.
+type ReconciliationConfig struct {
+	Enabled     bool
+	GracePeriod time.Duration
+}
+
+// WebhookConfig controls outbound webhook deliveries. By default, we
+// refuse to connect to loopback, private, link-local, multicast and
+// unspecified addresses, so institution admins can't use webhooks to
+// reach hosts inside our network. AllowPrivateAddresses turns that
+// check off for dev and test environments, where webhook receivers
+// run on localhost.
+type WebhookConfig struct {
+	AllowPrivateAddresses bool
+}
+
 type RedisConfig struct {
 	URL       string
 	Password  string
@@ -141,7 +213,11 @@ type Config struct {
 	TwoFactor        *TwoFactorConfig
 	Email            *EmailConfig
 	Redis            *RedisConfig
+	RateLimit        *RateLimitConfig
 	RetentionMinimum *RetentionMinimum
+	StalledItems     *StalledItemConfig
+	Reconciliation   *ReconciliationConfig
+	Webhooks         *WebhookConfig
 
 	// BatchDeletionKey is a secret loaded from parameter store.
 	// Batch deletion requests must include this as an extra security token.
@@ -244,6 +320,18 @@ func loadConfig() *Config {
 		emailServiceType = constants.EmailServiceSMTP
 	}
 
+	stalledItemThreshold := v.GetDuration("STALLED_ITEMS_DEFAULT_THRESHOLD")
+	if stalledItemThreshold <= 0 {
+		fmt.Fprintf(os.Stderr, "STALLED_ITEMS_DEFAULT_THRESHOLD is not valid. Defaulting to %s.\n", defaultStalledItemThreshold)
+		stalledItemThreshold = defaultStalledItemThreshold
+	}
+
+	reconciliationGracePeriod := v.GetDuration("RECONCILIATION_GRACE_PERIOD")
+	if reconciliationGracePeriod <= 0 {
+		fmt.Fprintf(os.Stderr, "RECONCILIATION_GRACE_PERIOD is not valid. Defaulting to %s.\n", defaultReconciliationGracePeriod)
+		reconciliationGracePeriod = defaultReconciliationGracePeriod
+	}
+
 	return &Config{
 		Logging: &LoggingConfig{
 			File:         v.GetString("LOG_FILE"),
@@ -298,13 +386,58 @@ func loadConfig() *Config {
 			Password:  v.GetString("REDIS_PASSWORD"),
 			URL:       v.GetString("REDIS_URL"),
 		},
+		RateLimit: &RateLimitConfig{
+			Enabled:              v.GetBool("RATE_LIMIT_ENABLED"),
+			UserPerMinute:        v.GetInt("RATE_LIMIT_USER_PER_MINUTE"),
+			UserBurst:            v.GetInt("RATE_LIMIT_USER_BURST"),
+			InstitutionPerMinute: v.GetInt("RATE_LIMIT_INSTITUTION_PER_MINUTE"),
+			InstitutionBurst:     v.GetInt("RATE_LIMIT_INSTITUTION_BURST"),
+		},
 		RetentionMinimum: &RetentionMinimum{
 			Glacier:     v.GetInt("RETENTION_MINIMUM_GLACIER"),
 			GlacierDeep: v.GetInt("RETENTION_MINIMUM_GLACIER_DEEP"),
 			Wasabi:      v.GetInt("RETENTION_MINIMUM_NEWSTORAGEOPTION"),
 			Standard:    v.GetInt("RETENTION_MINIMUM_STANDARD"),
 		},
+		StalledItems: &StalledItemConfig{
+			Enabled:          v.GetBool("STALLED_ITEMS_ENABLED"),
+			DefaultThreshold: stalledItemThreshold,
+			StageThresholds:  parseStageThresholds(v.GetString("STALLED_ITEMS_STAGE_THRESHOLDS")),
+		},
+		Reconciliation: &ReconciliationConfig{
+			Enabled:     v.GetBool("RECONCILIATION_ENABLED"),
+			GracePeriod: reconciliationGracePeriod,
+		},
+		Webhooks: &WebhookConfig{
+			AllowPrivateAddresses: v.GetBool("WEBHOOKS_ALLOW_PRIVATE_ADDRESSES"),
+		},
+	}
+}
+
+// parseStageThresholds parses a list of stage thresholds like
+// "Store=24h,Restoring=72h" into a map of stage name to duration.
+// It skips invalid entries with a warning, so one typo doesn't
+// prevent the app from starting.
+func parseStageThresholds(setting string) map[string]time.Duration {
+	thresholds := make(map[string]time.Duration)
+	for _, entry := range strings.Split(setting, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			fmt.Fprintf(os.Stderr, "Ignoring invalid stalled item threshold '%s'. Expected Stage=Duration.\n", entry)
+			continue
+		}
+		stage := strings.TrimSpace(parts[0])
+		threshold, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || threshold <= 0 {
+			fmt.Fprintf(os.Stderr, "Ignoring invalid stalled item threshold '%s': %v\n", entry, err)
+			continue
+		}
+		thresholds[stage] = threshold
 	}
+	return thresholds
 }
 
 func getLogLevel(level int) zerolog.Level {
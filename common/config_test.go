@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/APTrust/registry/common"
 	"github.com/APTrust/registry/constants"
@@ -72,6 +73,15 @@ func TestNewConfig(t *testing.T) {
 	assert.Equal(t, GetExpectedConfigJson(), jsonString)
 }
 
+func TestStalledItemThresholds(t *testing.T) {
+	config := common.NewConfig()
+	require.NotNil(t, config.StalledItems)
+	assert.Equal(t, 24*time.Hour, config.StalledItems.ThresholdFor(constants.StageStore))
+	assert.Equal(t, 72*time.Hour, config.StalledItems.ThresholdFor(constants.StageRestoring))
+	assert.Equal(t, 6*time.Hour, config.StalledItems.ThresholdFor(constants.StageValidate))
+	assert.Equal(t, 6*time.Hour, config.StalledItems.MinThreshold())
+}
+
 func TestConfigBucketQualifier(t *testing.T) {
 	config := common.NewConfig()
 	assert.Equal(t, ".test", config.BucketQualifier())
@@ -199,12 +209,35 @@ var expectedConfigJson = `{
     "Password": "****dis",
     "DefaultDB": 0
   },
+  "RateLimit": {
+    "Enabled": false,
+    "UserPerMinute": 600,
+    "UserBurst": 100,
+    "InstitutionPerMinute": 1200,
+    "InstitutionBurst": 200
+  },
   "RetentionMinimum": {
     "Glacier": 90,
     "GlacierDeep": 180,
     "Wasabi": 90,
     "Standard": 0
   },
+  "StalledItems": {
+    "Enabled": false,
+    "DefaultThreshold": 21600000000000,
+    "StageThresholds": {
+      "Requested": 43200000000000,
+      "Restoring": 259200000000000,
+      "Store": 86400000000000
+    }
+  },
+  "Reconciliation": {
+    "Enabled": false,
+    "GracePeriod": 3600000000000
+  },
+  "Webhooks": {
+    "AllowPrivateAddresses": true
+  },
   "BatchDeletionKey": "****key",
   "MaintenanceMode": false,
   "EmailServiceType": "SMTP"
@@ -32,6 +32,10 @@ type APTContext struct {
 	// and lets us view NSQ admin stats.
 	NSQClient *network.NSQClient
 
+	// RateLimiter keeps the token buckets that limit API requests
+	// per user and per institution.
+	RateLimiter *RateLimiter
+
 	// RedisClient talks to Redis/Elasticache to retrieve info about
 	// WorkItems in progress.
 	RedisClient *network.RedisClient
@@ -91,6 +95,7 @@ func Context() *APTContext {
 			SNSClient:   network.NewSNSClient(config.TwoFactor.SMSEnabled, config.TwoFactor.AWSRegion, config.TwoFactor.SNSEndpoint, config.TwoFactor.SNSUser, config.TwoFactor.SNSPassword, zlogger),
 			SMTPClient:  network.NewSMTPClient(config.Email.Enabled, config.TwoFactor.AWSRegion, config.Email.SesEndpoint, config.Email.SesUser, config.Email.SesPassword, config.Email.FromAddress, zlogger),
 			RedisClient: redisClient,
+			RateLimiter: NewRateLimiter(),
 		}
 	}
 	return ctx
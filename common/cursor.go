@@ -0,0 +1,67 @@
+package common
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// Cursor marks a position in a result set for keyset (cursor) paging.
+// Value is the value of the sort column in the last row of the previous
+// page, and ID is that row's id, which breaks ties when multiple rows
+// share the same sort value.
+//
+// Cursors go out to API clients as opaque strings. Clients should not
+// try to construct or alter them. They should simply pass back what
+// the Registry gave them in the "next" link of a list response.
+type Cursor struct {
+	Column string      `json:"c"`
+	Value  interface{} `json:"v"`
+	ID     int64       `json:"id"`
+}
+
+// NewCursor returns a cursor pointing to the row having the specified
+// sort column value and id. Time values are converted to RFC3339 strings
+// with microsecond precision, which is the precision Postgres uses for
+// timestamps.
+func NewCursor(column string, value interface{}, id int64) *Cursor {
+	if t, ok := value.(time.Time); ok {
+		value = t.UTC().Format("2006-01-02T15:04:05.000000Z07:00")
+	}
+	return &Cursor{
+		Column: column,
+		Value:  value,
+		ID:     id,
+	}
+}
+
+// Encode returns this cursor as an opaque, URL-safe string.
+func (c *Cursor) Encode() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes an opaque cursor string created by Cursor.Encode.
+// Numeric values come back as json.Number, so we don't lose precision
+// on large int64 values. Returns ErrInvalidCursor if the string can't
+// be decoded.
+func DecodeCursor(encoded string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	cursor := &Cursor{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Column == "" || cursor.ID < 1 {
+		return nil, ErrInvalidCursor
+	}
+	return cursor, nil
+}
@@ -0,0 +1,53 @@
+package common_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorEncodeDecode(t *testing.T) {
+	cursor := common.NewCursor("size", int64(9007199254740993), 88)
+	encoded, err := cursor.Encode()
+	require.Nil(t, err)
+	assert.NotEmpty(t, encoded)
+	assert.NotContains(t, encoded, "=")
+
+	decoded, err := common.DecodeCursor(encoded)
+	require.Nil(t, err)
+	assert.Equal(t, "size", decoded.Column)
+	assert.Equal(t, json.Number("9007199254740993"), decoded.Value)
+	assert.Equal(t, int64(88), decoded.ID)
+}
+
+func TestCursorTimeValue(t *testing.T) {
+	ts := time.Date(2023, 4, 5, 6, 7, 8, 123456789, time.UTC)
+	cursor := common.NewCursor("updated_at", ts, 12)
+	assert.Equal(t, "2023-04-05T06:07:08.123456Z", cursor.Value)
+
+	encoded, err := cursor.Encode()
+	require.Nil(t, err)
+	decoded, err := common.DecodeCursor(encoded)
+	require.Nil(t, err)
+	assert.Equal(t, "2023-04-05T06:07:08.123456Z", decoded.Value)
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	_, err := common.DecodeCursor("this is not base64!")
+	assert.Equal(t, common.ErrInvalidCursor, err)
+
+	// Valid base64, but not JSON
+	_, err = common.DecodeCursor("aGVsbG8")
+	assert.Equal(t, common.ErrInvalidCursor, err)
+
+	// Valid JSON, but missing id
+	cursor := common.NewCursor("updated_at", "2023-01-01", 0)
+	encoded, err := cursor.Encode()
+	require.Nil(t, err)
+	_, err = common.DecodeCursor(encoded)
+	assert.Equal(t, common.ErrInvalidCursor, err)
+}
@@ -63,6 +63,11 @@ var ErrResourcePermission = errors.New("cannot determine permission type for req
 // and old versions of a bag's files.
 var ErrPendingWorkItems = errors.New("task cannot be completed because this object has pending work items")
 
+// ErrLegalHold occurs when someone tries to request, approve or
+// complete the deletion of an object or file that is under an active
+// legal hold, either directly or through the object's bag group.
+var ErrLegalHold = errors.New("this object is under a legal hold and cannot be deleted")
+
 // ErrInvalidToken means that the token presented for an action like
 // password reset or deletion confirmation does not match the encrypted
 // token in the database. When this error occurs, the user may not
@@ -160,6 +165,46 @@ var ErrRequestAlreadyApproved = errors.New("this request has already been approv
 // or cancel a request that was previously cancelled.
 var ErrRequestAlreadyCancelled = errors.New("this request has already been cancelled")
 
+// ErrInvalidCursor occurs when an API client passes a pagination
+// cursor that we can't decode, or that doesn't match the requested
+// sort order.
+var ErrInvalidCursor = errors.New("invalid or expired cursor")
+
+// ErrAPIKeyReadOnly occurs when someone tries to create, update or
+// delete a resource using a read-only API key.
+var ErrAPIKeyReadOnly = errors.New("this api key is read-only")
+
+// ErrNotDeleted occurs when someone tries to undelete an object or
+// file that is not in the deleted state.
+var ErrNotDeleted = errors.New("this item is not in deleted state")
+
+// ErrStorageRecordsMissing occurs when someone tries to undelete an
+// object or file whose storage records are gone. That means the
+// preservation copies were actually deleted, and there is nothing
+// left to undelete.
+var ErrStorageRecordsMissing = errors.New("storage records show this item's preservation copies have been deleted")
+
+// ErrObjectDeleted occurs when someone tries to undelete a file
+// whose parent object is deleted. They should undelete the object,
+// which undeletes its files.
+var ErrObjectDeleted = errors.New("this file's object is deleted; undelete the object instead")
+
+// ErrNoFilters occurs when someone tries a bulk operation without
+// any filters, which would apply it to every record in the table.
+var ErrNoFilters = errors.New("you must specify at least one filter")
+
+// ErrTooManyItems occurs when the filters for a bulk operation match
+// more records than we're willing to process in one request.
+var ErrTooManyItems = errors.New("too many items match these filters; please narrow your search")
+
+// ErrNotOrphaned occurs when someone tries to reconcile a WorkItem that
+// NSQ and Redis show is being handled normally.
+var ErrNotOrphaned = errors.New("this work item does not need reconciliation")
+
+// ErrInvalidFileFilter occurs when someone asks to see a WorkItem's
+// Redis files with a file_filter we don't support.
+var ErrInvalidFileFilter = errors.New("invalid file filter")
+
 type ValidationError struct {
 	Errors map[string]string
 }
@@ -182,3 +227,21 @@ func (v *ValidationError) Error() string {
 	}
 	return strings.Join(errs, "\n")
 }
+
+// IllegalTransitionError occurs when someone tries to save a WorkItem
+// with a stage or status that can't follow its current stage and status,
+// such as moving a successful item back to Started, or moving an ingest
+// into a restoration stage.
+type IllegalTransitionError struct {
+	Action     string
+	FromStage  string
+	FromStatus string
+	ToStage    string
+	ToStatus   string
+	Reason     string
+}
+
+func (e *IllegalTransitionError) Error() string {
+	return fmt.Sprintf("illegal %s transition from %s/%s to %s/%s: %s",
+		e.Action, e.FromStage, e.FromStatus, e.ToStage, e.ToStatus, e.Reason)
+}
@@ -19,6 +19,12 @@ type Pager struct {
 	PreviousLink     string
 	NextLink         string
 	URL              *url.URL
+	// Cursor is the opaque keyset cursor the client sent with this
+	// request. This is set only for cursor-mode requests.
+	Cursor string
+	// NextCursor is the cursor that will fetch the next page of
+	// results in cursor mode. This is empty on the last page.
+	NextCursor string
 }
 
 func NewPager(c *gin.Context, baseURL string, defaultPerPage int) (*Pager, error) {
@@ -61,3 +67,40 @@ func (pager *Pager) SetCounts(totalItems, itemsInResultSet int) {
 		pager.NextLink = fmt.Sprintf("%s?%s", pager.URL.Path, queryValues.Encode())
 	}
 }
+
+// NewCursorPager returns a pager for keyset (cursor) paging. Unlike
+// offset paging, cursor paging ignores the page param. It reads the
+// cursor param instead, which is empty when the client requests the
+// first page.
+func NewCursorPager(c *gin.Context, baseURL string, defaultPerPage int) (*Pager, error) {
+	pager, err := NewPager(c, baseURL, defaultPerPage)
+	if err != nil {
+		return nil, err
+	}
+	pager.Page = 0
+	pager.QueryOffset = 0
+	pager.ItemFirst = 1
+	pager.Cursor = c.Query("cursor")
+	return pager, nil
+}
+
+// SetCursorCounts sets the counts and next link for a pager in cursor
+// mode. Param totalItems should be -1 if the client asked us to skip
+// the count query. Param nextCursor should be empty if there are no
+// more results.
+//
+// Cursor mode does not provide a previous link. Clients walking large
+// result sets move forward only.
+func (pager *Pager) SetCursorCounts(totalItems, itemsInResultSet int, nextCursor string) {
+	pager.TotalItems = totalItems
+	pager.ItemsInResultSet = itemsInResultSet
+	pager.ItemLast = itemsInResultSet
+	pager.NextCursor = nextCursor
+	if nextCursor != "" {
+		queryValues := pager.URL.Query()
+		delete(queryValues, "page")
+		queryValues["per_page"] = []string{strconv.Itoa(pager.PerPage)}
+		queryValues["cursor"] = []string{nextCursor}
+		pager.NextLink = fmt.Sprintf("%s?%s", pager.URL.Path, queryValues.Encode())
+	}
+}
@@ -57,3 +57,32 @@ func TestPagerPerPageLimit(t *testing.T) {
 	pager = getPager(t, 999999)
 	assert.Equal(t, 1000, pager.PerPage)
 }
+
+func TestNewCursorPager(t *testing.T) {
+	var _url = "http://example.com/files?cursor=abc123&per_page=25&page=9&skip_count=true"
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = &http.Request{}
+	var err error
+	c.Request.URL, err = url.Parse(_url)
+	require.Nil(t, err)
+	pager, err := common.NewCursorPager(c, _url, 10)
+	require.Nil(t, err)
+
+	// Cursor paging ignores page number
+	assert.Equal(t, 0, pager.Page)
+	assert.Equal(t, 0, pager.QueryOffset)
+	assert.Equal(t, 25, pager.PerPage)
+	assert.Equal(t, "abc123", pager.Cursor)
+
+	pager.SetCursorCounts(-1, 25, "def456")
+	assert.Equal(t, -1, pager.TotalItems)
+	assert.Equal(t, 25, pager.ItemsInResultSet)
+	assert.Equal(t, "def456", pager.NextCursor)
+	assert.Equal(t, "/files?cursor=def456&per_page=25&skip_count=true", pager.NextLink)
+	assert.Empty(t, pager.PreviousLink)
+
+	// No next link on the last page
+	pager.NextLink = ""
+	pager.SetCursorCounts(-1, 3, "")
+	assert.Empty(t, pager.NextLink)
+}
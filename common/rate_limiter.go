@@ -0,0 +1,208 @@
+package common
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RateLimit describes a token bucket. A client may make up to Burst
+// requests at once, and the bucket refills at PerMinute tokens per
+// minute. A RateLimit with PerMinute <= 0 means no limit.
+type RateLimit struct {
+	PerMinute int
+	Burst     int
+}
+
+// Unlimited returns true if this RateLimit imposes no limit.
+func (limit RateLimit) Unlimited() bool {
+	return limit.PerMinute <= 0
+}
+
+// RateLimitCheck asks the RateLimiter to take one token from the
+// bucket identified by Key, which has the specified Limit. Name is
+// a human-readable description of the bucket's owner for the admin
+// dashboard, such as a user's email address or an institution's name.
+type RateLimitCheck struct {
+	Key   string
+	Name  string
+	Limit RateLimit
+}
+
+// RateLimitResult describes the outcome of RateLimiter.Allow. Limit,
+// Remaining and Reset describe the most restrictive bucket checked.
+// Reset is the time until that bucket is full again. RetryAfter is
+// the time until the client may make its next request, which is
+// zero if Allowed is true.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimitStats describes the current state of one bucket along
+// with counts of requests allowed and refused since the process
+// started.
+type RateLimitStats struct {
+	Key           string
+	Name          string
+	PerMinute     int
+	Burst         int
+	Remaining     int
+	Allowed       int64
+	Limited       int64
+	LastRequestAt time.Time
+	LastLimitedAt time.Time
+}
+
+type tokenBucket struct {
+	stats    RateLimitStats
+	tokens   float64
+	filledAt time.Time
+}
+
+// RateLimiter keeps a set of in-memory token buckets. It's safe for
+// concurrent use. Note that each Registry process has its own
+// RateLimiter, so when we run several containers behind a load
+// balancer, a client's effective limit is the configured limit
+// times the number of containers.
+type RateLimiter struct {
+	mutex   sync.Mutex
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+// NewRateLimiter returns a RateLimiter with no buckets.
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// SetClock replaces the limiter's clock. This is for testing.
+func (rl *RateLimiter) SetClock(now func() time.Time) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	rl.now = now
+}
+
+// Allow takes one token from each of the buckets in checks if every
+// one of them has a token to give. If any bucket is empty, this takes
+// nothing and returns a result with Allowed = false. Checks with
+// unlimited limits are ignored, so a client whose checks are all
+// unlimited is always allowed.
+func (rl *RateLimiter) Allow(checks ...RateLimitCheck) RateLimitResult {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	now := rl.now()
+	result := RateLimitResult{Allowed: true}
+	buckets := make([]*tokenBucket, 0, len(checks))
+	var mostRestrictive *tokenBucket
+	for _, check := range checks {
+		if check.Limit.Unlimited() {
+			continue
+		}
+		bucket := rl.bucket(check, now)
+		buckets = append(buckets, bucket)
+		if bucket.tokens < 1 {
+			result.Allowed = false
+			if wait := bucket.timeUntil(1); wait > result.RetryAfter {
+				result.RetryAfter = wait
+			}
+		}
+		if mostRestrictive == nil || bucket.tokens < mostRestrictive.tokens {
+			mostRestrictive = bucket
+		}
+	}
+	for _, bucket := range buckets {
+		bucket.stats.LastRequestAt = now
+		if !result.Allowed {
+			if bucket.tokens < 1 {
+				bucket.stats.Limited++
+				bucket.stats.LastLimitedAt = now
+			}
+			continue
+		}
+		bucket.tokens--
+		bucket.stats.Allowed++
+	}
+	if mostRestrictive != nil {
+		result.Limit = mostRestrictive.stats.Burst
+		result.Remaining = int(math.Floor(mostRestrictive.tokens))
+		result.Reset = mostRestrictive.timeUntil(float64(mostRestrictive.stats.Burst))
+	}
+	return result
+}
+
+// Stats returns the current state of all buckets, sorted by key.
+func (rl *RateLimiter) Stats() []RateLimitStats {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	now := rl.now()
+	stats := make([]RateLimitStats, 0, len(rl.buckets))
+	for _, bucket := range rl.buckets {
+		bucket.refill(now)
+		bucket.stats.Remaining = int(math.Floor(bucket.tokens))
+		stats = append(stats, bucket.stats)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Key < stats[j].Key })
+	return stats
+}
+
+// Reset discards all buckets and counters.
+func (rl *RateLimiter) Reset() {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	rl.buckets = make(map[string]*tokenBucket)
+}
+
+// bucket returns the bucket for check, creating it if necessary.
+// If the limit has changed since the bucket was created (because
+// an admin added or changed an override), this applies the new
+// limit without resetting the bucket's counters.
+func (rl *RateLimiter) bucket(check RateLimitCheck, now time.Time) *tokenBucket {
+	burst := check.Limit.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	bucket, ok := rl.buckets[check.Key]
+	if !ok {
+		bucket = &tokenBucket{
+			stats:    RateLimitStats{Key: check.Key},
+			tokens:   float64(burst),
+			filledAt: now,
+		}
+		rl.buckets[check.Key] = bucket
+	}
+	bucket.refill(now)
+	bucket.stats.Name = check.Name
+	bucket.stats.PerMinute = check.Limit.PerMinute
+	bucket.stats.Burst = burst
+	if bucket.tokens > float64(burst) {
+		bucket.tokens = float64(burst)
+	}
+	return bucket
+}
+
+// refill adds the tokens that accrued since the last refill.
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.filledAt)
+	if elapsed > 0 && b.stats.PerMinute > 0 {
+		b.tokens = math.Min(float64(b.stats.Burst), b.tokens+elapsed.Minutes()*float64(b.stats.PerMinute))
+	}
+	b.filledAt = now
+}
+
+// timeUntil returns how long until this bucket holds the specified
+// number of tokens.
+func (b *tokenBucket) timeUntil(tokens float64) time.Duration {
+	if b.tokens >= tokens || b.stats.PerMinute <= 0 {
+		return 0
+	}
+	minutes := (tokens - b.tokens) / float64(b.stats.PerMinute)
+	return time.Duration(math.Ceil(minutes * float64(time.Minute)))
+}
@@ -0,0 +1,121 @@
+package common_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRateLimiter() (*common.RateLimiter, *time.Time) {
+	now := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+	rl := common.NewRateLimiter()
+	rl.SetClock(func() time.Time { return now })
+	return rl, &now
+}
+
+func TestRateLimiterAllow(t *testing.T) {
+	rl, now := newTestRateLimiter()
+	check := common.RateLimitCheck{Key: "user:1", Name: "user@example.com", Limit: common.RateLimit{PerMinute: 60, Burst: 3}}
+
+	for i := 2; i >= 0; i-- {
+		result := rl.Allow(check)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, i, result.Remaining)
+		assert.Equal(t, time.Duration(0), result.RetryAfter)
+	}
+
+	// Bucket is empty. At 60 per minute, we get a new token
+	// every second.
+	result := rl.Allow(check)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	*now = now.Add(time.Second)
+	result = rl.Allow(check)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// Bucket never holds more than Burst tokens.
+	*now = now.Add(time.Hour)
+	result = rl.Allow(check)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+
+	stats := rl.Stats()
+	require.Equal(t, 1, len(stats))
+	assert.Equal(t, "user:1", stats[0].Key)
+	assert.Equal(t, "user@example.com", stats[0].Name)
+	assert.Equal(t, int64(5), stats[0].Allowed)
+	assert.Equal(t, int64(1), stats[0].Limited)
+	assert.Equal(t, 2, stats[0].Remaining)
+	assert.Equal(t, *now, stats[0].LastRequestAt)
+	assert.Equal(t, now.Add(-time.Hour-time.Second), stats[0].LastLimitedAt)
+}
+
+func TestRateLimiterAllowMultiple(t *testing.T) {
+	rl, _ := newTestRateLimiter()
+	user := common.RateLimitCheck{Key: "user:1", Limit: common.RateLimit{PerMinute: 60, Burst: 5}}
+	otherUser := common.RateLimitCheck{Key: "user:2", Limit: common.RateLimit{PerMinute: 60, Burst: 5}}
+	inst := common.RateLimitCheck{Key: "institution:1", Limit: common.RateLimit{PerMinute: 30, Burst: 2}}
+
+	// Institution bucket is more restrictive, so it determines
+	// the limit headers.
+	result := rl.Allow(user, inst)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Limit)
+	assert.Equal(t, 1, result.Remaining)
+
+	result = rl.Allow(otherUser, inst)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// Institution is out of tokens, so the user's request is refused
+	// and the user's bucket keeps its tokens.
+	result = rl.Allow(user, inst)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 2*time.Second, result.RetryAfter)
+
+	stats := rl.Stats()
+	require.Equal(t, 3, len(stats))
+	assert.Equal(t, "institution:1", stats[0].Key)
+	assert.Equal(t, int64(2), stats[0].Allowed)
+	assert.Equal(t, int64(1), stats[0].Limited)
+	assert.Equal(t, "user:1", stats[1].Key)
+	assert.Equal(t, int64(1), stats[1].Allowed)
+	assert.Equal(t, int64(0), stats[1].Limited)
+	assert.Equal(t, 4, stats[1].Remaining)
+
+	rl.Reset()
+	assert.Empty(t, rl.Stats())
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	rl, _ := newTestRateLimiter()
+	exempt := common.RateLimitCheck{Key: "user:1", Limit: common.RateLimit{PerMinute: 0}}
+	for i := 0; i < 100; i++ {
+		assert.True(t, rl.Allow(exempt).Allowed)
+	}
+	result := rl.Allow(exempt)
+	assert.Equal(t, 0, result.Limit)
+	assert.Empty(t, rl.Stats())
+}
+
+func TestRateLimiterChangeLimit(t *testing.T) {
+	rl, _ := newTestRateLimiter()
+	check := common.RateLimitCheck{Key: "user:1", Limit: common.RateLimit{PerMinute: 60, Burst: 10}}
+	rl.Allow(check)
+
+	// Lowering the burst caps the bucket at the new size.
+	check.Limit.Burst = 2
+	result := rl.Allow(check)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Limit)
+	assert.Equal(t, 1, result.Remaining)
+	assert.Equal(t, int64(2), rl.Stats()[0].Allowed)
+}
@@ -25,10 +25,14 @@ const (
 	AlertDeletionConfirmed     = "Deletion Confirmed"
 	AlertDeletionRequested     = "Deletion Requested"
 	AlertFailedFixity          = "Failed Fixity Check"
+	AlertLegalHoldPlaced       = "Legal Hold Placed"
+	AlertLegalHoldReleased     = "Legal Hold Released"
+	AlertOrphanedItems         = "Orphaned Work Items"
 	AlertPasswordChanged       = "Password Changed"
 	AlertPasswordReset         = "Password Reset"
 	AlertRestorationCompleted  = "Restoration Completed"
 	AlertStalledItems          = "Stalled Work Items"
+	AlertUnqueuedItems         = "Unqueued Work Items"
 	AlertWelcome               = "Welcome New User"
 	AlgMd5                     = "md5"
 	AlgSha1                    = "sha1"
@@ -36,6 +40,8 @@ const (
 	AlgSha512                  = "sha512"
 	APIUserHeader              = "X-Pharos-API-User"
 	APIKeyHeader               = "X-Pharos-API-Key"
+	APIKeyScopeReadOnly        = "read_only"
+	APIKeyScopeReadWrite       = "read_write"
 	APIPrefixAdmin             = "/admin-api/"
 	APIPrefixMember            = "/member-api/"
 	APTrustOpsEmail            = "ops@aptrust.org"
@@ -80,6 +86,9 @@ const (
 	MetaSpotTestsLastRun       = "spot restore last run"
 	OutcomeFailure             = "Failure"
 	OutcomeSuccess             = "Success"
+	RateLimitLimitHeader       = "X-RateLimit-Limit"
+	RateLimitRemainingHeader   = "X-RateLimit-Remaining"
+	RateLimitResetHeader       = "X-RateLimit-Reset"
 	RoleInstAdmin              = "institutional_admin"
 	RoleInstUser               = "institutional_user"
 	RoleNone                   = "none"
@@ -137,6 +146,40 @@ const (
 	TwoFactorSMS               = "sms"
 )
 
+// NSQ outbox message statuses. See pgmodels.NSQOutboxMessage.
+const (
+	OutboxStatusDelivered = "delivered"
+	OutboxStatusFailed    = "failed"
+	OutboxStatusPending   = "pending"
+)
+
+// Webhook event types, delivery statuses, and the headers we
+// send with each webhook delivery.
+const (
+	WebhookEventDeletionCompleted    = "deletion.completed"
+	WebhookEventFixityFailed         = "fixity.failed"
+	WebhookEventRestorationCompleted = "restoration.completed"
+	WebhookEventTest                 = "test"
+	WebhookEventWorkItemCompleted    = "work_item.completed"
+	WebhookStatusFailed              = "failed"
+	WebhookStatusPending             = "pending"
+	WebhookStatusSucceeded           = "succeeded"
+	WebhookDeliveryHeader            = "X-APTrust-Delivery"
+	WebhookEventHeader               = "X-APTrust-Event"
+	WebhookSignatureHeader           = "X-APTrust-Signature"
+	WebhookTimestampHeader           = "X-APTrust-Timestamp"
+)
+
+// Audit log actions. Delete and undelete are soft deletes and
+// undeletes, which are updates that change a record's state or
+// deactivation date.
+const (
+	AuditActionDelete   = "delete"
+	AuditActionInsert   = "insert"
+	AuditActionUndelete = "undelete"
+	AuditActionUpdate   = "update"
+)
+
 var AccessSettings = []string{
 	AccessConsortia,
 	AccessInstitution,
@@ -149,13 +192,29 @@ var AlertTypes = []string{
 	AlertDeletionConfirmed,
 	AlertDeletionRequested,
 	AlertFailedFixity,
+	AlertLegalHoldPlaced,
+	AlertLegalHoldReleased,
+	AlertOrphanedItems,
 	AlertRestorationCompleted,
 	AlertPasswordChanged,
 	AlertPasswordReset,
 	AlertStalledItems,
+	AlertUnqueuedItems,
 	AlertWelcome,
 }
 
+var AuditActions = []string{
+	AuditActionDelete,
+	AuditActionInsert,
+	AuditActionUndelete,
+	AuditActionUpdate,
+}
+
+var APIKeyScopes = []string{
+	APIKeyScopeReadOnly,
+	APIKeyScopeReadWrite,
+}
+
 var APIPrefixes = []string{
 	APIPrefixAdmin,
 	APIPrefixMember,
@@ -293,6 +352,28 @@ var UserActions = []string{
 	ActionRestoreFile,
 }
 
+// WebhookEventTypes are the events to which a webhook may subscribe.
+// This does not include WebhookEventTest, which we send only when
+// a user asks for it.
+var WebhookEventTypes = []string{
+	WebhookEventWorkItemCompleted,
+	WebhookEventRestorationCompleted,
+	WebhookEventDeletionCompleted,
+	WebhookEventFixityFailed,
+}
+
+var OutboxStatuses = []string{
+	OutboxStatusDelivered,
+	OutboxStatusFailed,
+	OutboxStatusPending,
+}
+
+var WebhookStatuses = []string{
+	WebhookStatusFailed,
+	WebhookStatusPending,
+	WebhookStatusSucceeded,
+}
+
 var WorkItemActions = []string{
 	ActionDelete,
 	ActionGlacierRestore,
@@ -322,6 +403,86 @@ var NSQIngestTopicFor = map[string]string{
 	StageCleanup:              IngestCleanup,
 }
 
+// WorkItemStagesFor lists, in order, the stages through which a WorkItem
+// with each action may pass. WorkItem.Save won't move an item into a
+// stage that isn't listed for its action, or back to an earlier stage
+// unless the item is being requeued.
+var WorkItemStagesFor = map[string][]string{
+	ActionDelete: {
+		StageRequested,
+		StageResolve,
+		StageCleanup,
+	},
+	ActionFixityCheck: {
+		StageRequested,
+		StageFetch,
+		StageValidate,
+		StageRecord,
+		StageResolve,
+		StageCleanup,
+	},
+	ActionGlacierRestore: {
+		StageRequested,
+		StageRestoring,
+		StageAvailableInS3,
+		StageResolve,
+		StageCleanup,
+	},
+	ActionIngest: IngestStagesInOrder,
+	ActionRestoreFile: {
+		StageRequested,
+		StageFetch,
+		StagePackage,
+		StageRestoring,
+		StageRecord,
+		StageAvailableInS3,
+		StageResolve,
+		StageCleanup,
+	},
+	ActionRestoreObject: {
+		StageRequested,
+		StageFetch,
+		StagePackage,
+		StageRestoring,
+		StageRecord,
+		StageAvailableInS3,
+		StageResolve,
+		StageCleanup,
+	},
+}
+
+// WorkItemStatusTransitions lists the statuses into which a WorkItem may
+// move from each status. Completed statuses are final. An admin who needs
+// to revive a completed item should requeue it.
+var WorkItemStatusTransitions = map[string][]string{
+	StatusPending: {
+		StatusPending,
+		StatusStarted,
+		StatusSuccess,
+		StatusFailed,
+		StatusCancelled,
+		StatusSuspended,
+	},
+	StatusStarted: {
+		StatusPending,
+		StatusStarted,
+		StatusSuccess,
+		StatusFailed,
+		StatusCancelled,
+		StatusSuspended,
+	},
+	StatusSuspended: {
+		StatusPending,
+		StatusStarted,
+		StatusFailed,
+		StatusCancelled,
+		StatusSuspended,
+	},
+	StatusSuccess:   {StatusSuccess},
+	StatusFailed:    {StatusFailed},
+	StatusCancelled: {StatusCancelled},
+}
+
 // All other common errors are defined in common. We had to move this
 // one into constants to prevent an illegal import cycle.
 
@@ -65,3 +65,18 @@ func TestTopicFor(t *testing.T) {
 	require.NotNil(t, err)
 	assert.Empty(t, topic)
 }
+
+func TestWorkItemStateMachine(t *testing.T) {
+	for _, action := range constants.WorkItemActions {
+		assert.NotEmpty(t, constants.WorkItemStagesFor[action], action)
+		for _, stage := range constants.WorkItemStagesFor[action] {
+			assert.Contains(t, constants.Stages, stage, action)
+		}
+	}
+	for _, status := range constants.Statuses {
+		assert.Contains(t, constants.WorkItemStatusTransitions[status], status)
+	}
+	for _, status := range constants.CompletedStatusValues {
+		assert.Equal(t, []string{status}, constants.WorkItemStatusTransitions[status])
+	}
+}
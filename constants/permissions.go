@@ -16,10 +16,12 @@ package constants
 type Permission string
 
 const (
+	APISpecRead                        = "APISpecRead"
 	AlertCreate                        = "AlertCreate"
 	AlertDelete                        = "AlertDelete"
 	AlertRead                          = "AlertRead"
 	AlertUpdate                        = "AlertUpdate"
+	AuditLogRead                       = "AuditLogRead"
 	BillingReportShow                  = "BillingReportShow"
 	ChecksumCreate                     = "ChecksumCreate"
 	ChecksumDelete                     = "ChecksumDelete"
@@ -40,6 +42,7 @@ const (
 	FileRead                           = "FileRead"
 	FileRequestDelete                  = "FileRequestDelete"
 	FileRestore                        = "FileRestore"
+	FileUndelete                       = "FileUndelete"
 	FileUpdate                         = "FileUpdate"
 	GenerateFailedFixityAlert          = "GenerateFailedFixityAlert"
 	InstitutionCreate                  = "InstitutionCreate"
@@ -55,11 +58,18 @@ const (
 	IntellectualObjectRead             = "IntellectualObjectRead"
 	IntellectualObjectRequestDelete    = "IntellectualObjectRequestDelete"
 	IntellectualObjectRestore          = "IntellectualObjectRestore"
+	IntellectualObjectUndelete         = "IntellectualObjectUndelete"
 	IntellectualObjectUpdate           = "IntellectualObjectUpdate"
 	InternalMetadataRead               = "InternalMetadataRead"
+	LegalHoldCreate                    = "LegalHoldCreate"
+	LegalHoldRead                      = "LegalHoldRead"
+	LegalHoldRelease                   = "LegalHoldRelease"
 	NsqAdmin                           = "NsqAdmin"
+	PipelineReportShow                 = "PipelineReportShow"
 	PrepareFileDelete                  = "PrepareFileDelete"
 	PrepareObjectDelete                = "PrepareObjectDelete"
+	RateLimitRead                      = "RateLimitRead"
+	RateLimitUpdate                    = "RateLimitUpdate"
 	ReportRead                         = "ReportRead"
 	RedisList                          = "RedisList"
 	RedisRead                          = "RedisRead"
@@ -86,6 +96,10 @@ const (
 	UserTwoFactorVerify                = "UserTwoFactorVerify"
 	UserUpdate                         = "UserUpdate"
 	UserUpdateSelf                     = "UserUpdateSelf"
+	WebhookCreate                      = "WebhookCreate"
+	WebhookDelete                      = "WebhookDelete"
+	WebhookRead                        = "WebhookRead"
+	WebhookUpdate                      = "WebhookUpdate"
 	WorkItemCreate                     = "WorkItemCreate"
 	WorkItemDelete                     = "WorkItemDelete"
 	WorkItemRead                       = "WorkItemRead"
@@ -95,10 +109,12 @@ const (
 )
 
 var Permissions = []Permission{
+	APISpecRead,
 	AlertCreate,
 	AlertDelete,
 	AlertRead,
 	AlertUpdate,
+	AuditLogRead,
 	BillingReportShow,
 	ChecksumCreate,
 	ChecksumDelete,
@@ -119,6 +135,7 @@ var Permissions = []Permission{
 	FileRead,
 	FileRequestDelete,
 	FileRestore,
+	FileUndelete,
 	FileUpdate,
 	GenerateFailedFixityAlert,
 	InstitutionCreate,
@@ -134,11 +151,18 @@ var Permissions = []Permission{
 	IntellectualObjectRead,
 	IntellectualObjectRequestDelete,
 	IntellectualObjectRestore,
+	IntellectualObjectUndelete,
 	IntellectualObjectUpdate,
 	InternalMetadataRead,
+	LegalHoldCreate,
+	LegalHoldRead,
+	LegalHoldRelease,
 	NsqAdmin,
+	PipelineReportShow,
 	PrepareFileDelete,
 	PrepareObjectDelete,
+	RateLimitRead,
+	RateLimitUpdate,
 	ReportRead,
 	RedisList,
 	RedisRead,
@@ -165,6 +189,10 @@ var Permissions = []Permission{
 	UserTwoFactorVerify,
 	UserUpdate,
 	UserUpdateSelf,
+	WebhookCreate,
+	WebhookDelete,
+	WebhookRead,
+	WebhookUpdate,
 	WorkItemCreate,
 	WorkItemDelete,
 	WorkItemRead,
@@ -210,6 +238,7 @@ var emptyList = make(map[Permission]bool)
 
 func initPermissions() {
 	// Institutional User Role
+	instUser[APISpecRead] = true
 	instUser[AlertRead] = true
 	instUser[AlertUpdate] = true
 	instUser[ChecksumRead] = true
@@ -223,6 +252,7 @@ func initPermissions() {
 	instUser[InstitutionRead] = true
 	instUser[IntellectualObjectRead] = true
 	instUser[IntellectualObjectRestore] = true
+	instUser[LegalHoldRead] = true
 	instUser[ReportRead] = true
 	instUser[StorageRecordRead] = true
 	instUser[UserComplete2FASetup] = true
@@ -242,8 +272,10 @@ func initPermissions() {
 	instUser[WorkItemRead] = true
 
 	// Institutional Admin Role
+	instAdmin[APISpecRead] = true
 	instAdmin[AlertRead] = true
 	instAdmin[AlertUpdate] = true
+	instAdmin[AuditLogRead] = true
 	instAdmin[ChecksumRead] = true
 	instAdmin[DashboardShow] = true
 	instAdmin[DeletionRequestApprove] = true
@@ -261,6 +293,9 @@ func initPermissions() {
 	instAdmin[IntellectualObjectRead] = true
 	instAdmin[IntellectualObjectRequestDelete] = true
 	instAdmin[IntellectualObjectRestore] = true
+	instAdmin[LegalHoldCreate] = true
+	instAdmin[LegalHoldRead] = true
+	instAdmin[LegalHoldRelease] = true
 	instAdmin[ReportRead] = true
 	instAdmin[StorageRecordRead] = true
 	instAdmin[UserComplete2FASetup] = true
@@ -281,13 +316,19 @@ func initPermissions() {
 	instAdmin[UserTwoFactorVerify] = true
 	instAdmin[UserUpdateSelf] = true
 	instAdmin[UserUpdate] = true
+	instAdmin[WebhookCreate] = true
+	instAdmin[WebhookDelete] = true
+	instAdmin[WebhookRead] = true
+	instAdmin[WebhookUpdate] = true
 	instAdmin[WorkItemRead] = true
 
 	// Sys Admin Role
+	sysAdmin[APISpecRead] = true
 	sysAdmin[AlertCreate] = true
 	sysAdmin[AlertDelete] = true
 	sysAdmin[AlertRead] = true
 	sysAdmin[AlertUpdate] = true
+	sysAdmin[AuditLogRead] = true
 	sysAdmin[BillingReportShow] = true
 	sysAdmin[ChecksumCreate] = true
 	sysAdmin[ChecksumDelete] = false // no one can do this
@@ -308,6 +349,7 @@ func initPermissions() {
 	sysAdmin[FileRead] = true
 	sysAdmin[FileRequestDelete] = false
 	sysAdmin[FileRestore] = true
+	sysAdmin[FileUndelete] = true
 	sysAdmin[FileUpdate] = true
 	sysAdmin[GenerateFailedFixityAlert] = true
 	sysAdmin[InstitutionCreate] = true
@@ -323,11 +365,18 @@ func initPermissions() {
 	sysAdmin[IntellectualObjectRead] = true
 	sysAdmin[IntellectualObjectRequestDelete] = false // inst admin only
 	sysAdmin[IntellectualObjectRestore] = true
+	sysAdmin[IntellectualObjectUndelete] = true
 	sysAdmin[IntellectualObjectUpdate] = true
 	sysAdmin[InternalMetadataRead] = true
+	sysAdmin[LegalHoldCreate] = true
+	sysAdmin[LegalHoldRead] = true
+	sysAdmin[LegalHoldRelease] = true
 	sysAdmin[NsqAdmin] = true
+	sysAdmin[PipelineReportShow] = true
 	sysAdmin[PrepareFileDelete] = true
 	sysAdmin[PrepareObjectDelete] = true
+	sysAdmin[RateLimitRead] = true
+	sysAdmin[RateLimitUpdate] = true
 	sysAdmin[ReportRead] = true
 	sysAdmin[RedisList] = true
 	sysAdmin[RedisRead] = true
@@ -354,6 +403,10 @@ func initPermissions() {
 	sysAdmin[UserTwoFactorVerify] = true
 	sysAdmin[UserUpdateSelf] = true
 	sysAdmin[UserUpdate] = true
+	sysAdmin[WebhookCreate] = true
+	sysAdmin[WebhookDelete] = true
+	sysAdmin[WebhookRead] = true
+	sysAdmin[WebhookUpdate] = true
 	sysAdmin[WorkItemCreate] = true
 	sysAdmin[WorkItemDelete] = true
 	sysAdmin[WorkItemRead] = true
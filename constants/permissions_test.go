@@ -15,24 +15,35 @@ func TestPermissions(t *testing.T) {
 	assert.True(t, constants.CheckPermission(constants.RoleInstUser, constants.FileRead))
 	assert.False(t, constants.CheckPermission(constants.RoleInstUser, constants.IntellectualObjectUpdate))
 	assert.False(t, constants.CheckPermission(constants.RoleInstUser, constants.WorkItemUpdate))
+	assert.False(t, constants.CheckPermission(constants.RoleInstUser, constants.AuditLogRead))
+	assert.True(t, constants.CheckPermission(constants.RoleInstUser, constants.LegalHoldRead))
+	assert.False(t, constants.CheckPermission(constants.RoleInstUser, constants.LegalHoldCreate))
 
 	// Spot check a few institutional admin permissions
 	assert.True(t, constants.CheckPermission(constants.RoleInstAdmin, constants.EventRead))
+	assert.True(t, constants.CheckPermission(constants.RoleInstAdmin, constants.AuditLogRead))
 	assert.True(t, constants.CheckPermission(constants.RoleInstAdmin, constants.FileRequestDelete))
 	assert.True(t, constants.CheckPermission(constants.RoleInstAdmin, constants.DeletionRequestApprove))
 	assert.True(t, constants.CheckPermission(constants.RoleInstAdmin, constants.FileRestore))
 	assert.True(t, constants.CheckPermission(constants.RoleInstAdmin, constants.IntellectualObjectRequestDelete))
 	assert.True(t, constants.CheckPermission(constants.RoleInstAdmin, constants.IntellectualObjectRestore))
+	assert.True(t, constants.CheckPermission(constants.RoleInstAdmin, constants.LegalHoldCreate))
+	assert.True(t, constants.CheckPermission(constants.RoleInstAdmin, constants.LegalHoldRelease))
 
 	assert.False(t, constants.CheckPermission(constants.RoleInstAdmin, constants.EventDelete))
 	assert.False(t, constants.CheckPermission(constants.RoleInstAdmin, constants.ChecksumUpdate))
 	assert.False(t, constants.CheckPermission(constants.RoleInstAdmin, constants.StorageRecordUpdate))
+	assert.False(t, constants.CheckPermission(constants.RoleInstAdmin, constants.IntellectualObjectUndelete))
+	assert.False(t, constants.CheckPermission(constants.RoleInstAdmin, constants.PipelineReportShow))
 
 	// Spot check SysAdmin privileges
 	assert.True(t, constants.CheckPermission(constants.RoleSysAdmin, constants.FileUpdate))
 	assert.True(t, constants.CheckPermission(constants.RoleSysAdmin, constants.IntellectualObjectUpdate))
 	assert.True(t, constants.CheckPermission(constants.RoleSysAdmin, constants.InstitutionUpdate))
 	assert.True(t, constants.CheckPermission(constants.RoleSysAdmin, constants.StorageRecordUpdate))
+	assert.True(t, constants.CheckPermission(constants.RoleSysAdmin, constants.IntellectualObjectUndelete))
+	assert.True(t, constants.CheckPermission(constants.RoleSysAdmin, constants.FileUndelete))
+	assert.True(t, constants.CheckPermission(constants.RoleSysAdmin, constants.PipelineReportShow))
 
 	assert.False(t, constants.CheckPermission(constants.RoleSysAdmin, constants.EventDelete))
 	assert.False(t, constants.CheckPermission(constants.RoleSysAdmin, constants.EventUpdate))
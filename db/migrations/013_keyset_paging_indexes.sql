@@ -0,0 +1,44 @@
+-- 013_keyset_paging_indexes.sql
+--
+-- This migration adds indexes to support cursor (keyset) paging
+-- in the member and admin APIs. Cursor requests order by
+-- (sort column, id) and select rows where (sort column, id) comes
+-- after the last row of the previous page. These composite indexes
+-- let Postgres seek directly to that row instead of scanning.
+--
+-- The institution_id variants cover the common case of a depositor's
+-- sync job walking all of its own files, objects, events or items.
+--
+-- Creating indexes on generic_files and premis_events will take a
+-- while in production. Run this one in a screen session.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('013_keyset_paging_indexes', now())
+on conflict ("version") do update set started_at = now();
+
+create index if not exists index_generic_files_on_updated_at_and_id
+on public.generic_files using btree (updated_at, id);
+
+create index if not exists index_generic_files_on_institution_id_updated_at_and_id
+on public.generic_files using btree (institution_id, updated_at, id);
+
+create index if not exists index_intellectual_objects_on_updated_at_and_id
+on public.intellectual_objects using btree (updated_at, id);
+
+create index if not exists index_intellectual_objects_on_institution_id_updated_at_and_id
+on public.intellectual_objects using btree (institution_id, updated_at, id);
+
+create index if not exists index_premis_events_on_date_time_and_id
+on public.premis_events using btree (date_time, id);
+
+create index if not exists index_premis_events_on_institution_id_date_time_and_id
+on public.premis_events using btree (institution_id, date_time, id);
+
+create index if not exists index_work_items_on_updated_at_and_id
+on public.work_items using btree (updated_at, id);
+
+create index if not exists index_work_items_on_institution_id_updated_at_and_id
+on public.work_items using btree (institution_id, updated_at, id);
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '013_keyset_paging_indexes';
@@ -0,0 +1,34 @@
+-- 014_api_keys.sql
+--
+-- This migration adds the api_keys table, which lets each user have
+-- multiple named API keys. Each key has a scope (read_only or
+-- read_write), an optional expiration date, an optional list of
+-- allowed IP addresses/CIDR blocks, and can be revoked individually.
+--
+-- The legacy single key in users.encrypted_api_secret_key continues
+-- to work.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('014_api_keys', now())
+on conflict ("version") do update set started_at = now();
+
+create table if not exists public.api_keys (
+	id bigserial primary key,
+	user_id int4 not null references public.users(id),
+	"label" varchar not null,
+	prefix varchar(8) not null,
+	encrypted_key varchar not null,
+	"scope" varchar not null default 'read_only',
+	allowed_ips varchar null,
+	expires_at timestamp null,
+	last_used_at timestamp null,
+	last_used_ip varchar null,
+	revoked_at timestamp null,
+	created_at timestamp not null,
+	updated_at timestamp not null
+);
+
+create unique index if not exists index_api_keys_on_user_id_and_prefix on public.api_keys using btree (user_id, prefix);
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '014_api_keys';
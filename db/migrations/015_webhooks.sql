@@ -0,0 +1,53 @@
+-- 015_webhooks.sql
+--
+-- This migration adds tables for outbound webhooks.
+--
+-- webhooks describes endpoints that institutions configure to receive
+-- HMAC-signed JSON notifications about completed work items, failed
+-- fixity checks, and completed deletions and restorations.
+--
+-- webhook_deliveries records each notification we send (or try to send)
+-- to each endpoint. Registry's cron job retries failed deliveries with
+-- exponential backoff until they succeed or run out of attempts.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('015_webhooks', now())
+on conflict ("version") do update set started_at = now();
+
+create table if not exists public.webhooks (
+	id bigserial primary key,
+	institution_id int4 not null references public.institutions(id),
+	url varchar not null,
+	description varchar null,
+	secret varchar not null,
+	event_types varchar[] not null default '{}',
+	enabled bool not null default true,
+	created_at timestamp not null,
+	updated_at timestamp not null
+);
+
+create index if not exists index_webhooks_on_institution_id on public.webhooks using btree (institution_id);
+
+create table if not exists public.webhook_deliveries (
+	id bigserial primary key,
+	webhook_id int8 not null references public.webhooks(id) on delete cascade,
+	institution_id int4 not null references public.institutions(id),
+	event_id varchar not null,
+	event_type varchar not null,
+	payload text not null,
+	status varchar not null default 'pending',
+	attempts int4 not null default 0,
+	next_attempt_at timestamp null,
+	last_attempt_at timestamp null,
+	response_code int4 null,
+	response_body text null,
+	error text null,
+	created_at timestamp not null,
+	updated_at timestamp not null
+);
+
+create index if not exists index_webhook_deliveries_on_webhook_id on public.webhook_deliveries using btree (webhook_id);
+create index if not exists index_webhook_deliveries_pending on public.webhook_deliveries using btree (next_attempt_at) where status = 'pending';
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '015_webhooks';
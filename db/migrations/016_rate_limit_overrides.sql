@@ -0,0 +1,32 @@
+-- 016_rate_limit_overrides.sql
+--
+-- This migration adds the rate_limit_overrides table, which lets APTrust
+-- admins replace the default API rate limits for a specific user or for
+-- all users at an institution.
+--
+-- When user_id is null, the override applies to the institution's shared
+-- bucket. Otherwise, it applies to that user's own bucket. Exempt overrides
+-- remove the limit entirely. We use these for preservation services
+-- accounts, which legitimately make many requests per second.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('016_rate_limit_overrides', now())
+on conflict ("version") do update set started_at = now();
+
+create table if not exists public.rate_limit_overrides (
+	id bigserial primary key,
+	institution_id int4 not null references public.institutions(id),
+	user_id int4 null references public.users(id) on delete cascade,
+	per_minute int4 not null default 0,
+	burst int4 not null default 0,
+	exempt bool not null default false,
+	note varchar null,
+	created_at timestamp not null,
+	updated_at timestamp not null
+);
+
+create unique index if not exists index_rate_limit_overrides_on_user_id on public.rate_limit_overrides using btree (user_id) where user_id is not null;
+create unique index if not exists index_rate_limit_overrides_on_institution_id on public.rate_limit_overrides using btree (institution_id) where user_id is null;
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '016_rate_limit_overrides';
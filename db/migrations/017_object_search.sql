@@ -0,0 +1,74 @@
+-- 017_object_search.sql
+--
+-- This migration adds full-text search over intellectual object metadata.
+-- It adds a generated tsvector column to intellectual_objects, covering
+-- title, alt_identifier, bag_group_identifier, description,
+-- source_organization and internal_sender_description, along with a GIN
+-- index so that searches stay fast as the table grows.
+--
+-- Weights let us rank title and alt identifier matches above matches in
+-- the description or sender fields.
+--
+-- We also add search_vector to the end of intellectual_objects_view, so
+-- that the objects list pages and the member API can filter on it. The
+-- rest of the view definition is unchanged. Postgres allows create or
+-- replace view only when new columns come after all existing columns.
+--
+-- Adding a stored generated column rewrites the intellectual_objects table.
+-- Run this one in a screen session in production.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('017_object_search', now())
+on conflict ("version") do update set started_at = now();
+
+alter table public.intellectual_objects add column if not exists search_vector tsvector
+generated always as (
+	setweight(to_tsvector('english'::regconfig, coalesce(title, '') || ' ' || coalesce(alt_identifier, '')), 'A') ||
+	setweight(to_tsvector('english'::regconfig, coalesce(bag_group_identifier, '') || ' ' || coalesce(description, '')), 'B') ||
+	setweight(to_tsvector('english'::regconfig, coalesce(source_organization, '') || ' ' || coalesce(internal_sender_description, '')), 'C')
+) stored;
+
+create index if not exists index_intellectual_objects_on_search_vector
+on public.intellectual_objects using gin (search_vector);
+
+CREATE OR REPLACE VIEW public.intellectual_objects_view
+AS SELECT io.id,
+    io.title,
+    io.description,
+    io.identifier,
+    io.alt_identifier,
+    io.access,
+    io.bag_name,
+    io.institution_id,
+    io.state,
+    io.etag,
+    io.bag_group_identifier,
+    io.storage_option,
+    io.bagit_profile_identifier,
+    io.source_organization,
+    io.internal_sender_identifier,
+    io.internal_sender_description,
+    io.created_at,
+    io.updated_at,
+    i.name AS institution_name,
+    i.identifier AS institution_identifier,
+    i.type AS institution_type,
+    i.member_institution_id AS institution_parent_id,
+    ( SELECT count(*) AS count
+           FROM generic_files gf
+          WHERE gf.intellectual_object_id = io.id AND gf.state::text = 'A'::text) AS file_count,
+    ( SELECT sum(gf.size) AS sum
+           FROM generic_files gf
+          WHERE gf.intellectual_object_id = io.id AND gf.state::text = 'A'::text) AS size,
+    ( SELECT count(*) AS count
+           FROM generic_files gf
+          WHERE gf.intellectual_object_id = io.id AND gf.state::text = 'A'::text AND gf.identifier::text ~~ concat(io.identifier, '/data/%')) AS payload_file_count,
+    ( SELECT sum(gf.size) AS sum
+           FROM generic_files gf
+          WHERE gf.intellectual_object_id = io.id AND gf.state::text = 'A'::text AND gf.identifier::text ~~ concat(io.identifier, '/data/%')) AS payload_size,
+    io.search_vector
+   FROM intellectual_objects io
+     LEFT JOIN institutions i ON io.institution_id = i.id;
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '017_object_search';
@@ -0,0 +1,87 @@
+-- 018_bag_groups.sql
+--
+-- This migration adds the bag_groups table, which makes bag groups
+-- (collections of objects that share a bag_group_identifier) first-class
+-- records with stable ids. Bag group identifiers are unique only within
+-- an institution, so the unique key is (institution_id, identifier).
+--
+-- IntellectualObject.Save adds bag groups as new identifiers come in.
+-- Here, we backfill bag groups for all existing objects.
+--
+-- bag_groups_view adds aggregate info about each group's active objects
+-- and files. The aggregates are lateral subqueries, so Postgres computes
+-- them only for the rows on the page being displayed when the list is
+-- sorted on a bag_groups column.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('018_bag_groups', now())
+on conflict ("version") do update set started_at = now();
+
+create table if not exists public.bag_groups (
+	id bigserial primary key,
+	institution_id int4 not null references public.institutions(id),
+	identifier varchar not null,
+	created_at timestamp not null,
+	updated_at timestamp not null
+);
+
+create unique index if not exists index_bag_groups_on_institution_id_and_identifier
+on public.bag_groups using btree (institution_id, identifier);
+
+create index if not exists index_bag_groups_on_identifier
+on public.bag_groups using btree (identifier);
+
+create index if not exists index_intellectual_objects_on_institution_id_and_bag_group_identifier
+on public.intellectual_objects using btree (institution_id, bag_group_identifier);
+
+insert into public.bag_groups (institution_id, identifier, created_at, updated_at)
+select institution_id, bag_group_identifier, min(created_at), max(updated_at)
+from public.intellectual_objects
+where bag_group_identifier is not null and bag_group_identifier != ''
+group by institution_id, bag_group_identifier
+on conflict (institution_id, identifier) do nothing;
+
+create or replace view public.bag_groups_view as
+select
+	bg.id,
+	bg.institution_id,
+	bg.identifier,
+	bg.created_at,
+	bg.updated_at,
+	i."name" as institution_name,
+	i.identifier as institution_identifier,
+	i.member_institution_id as institution_parent_id,
+	coalesce(objs.object_count, 0) as object_count,
+	coalesce(files.file_count, 0) as file_count,
+	coalesce(files.size, 0) as size,
+	ingests.last_ingested_at
+from bag_groups bg
+left join institutions i on i.id = bg.institution_id
+left join lateral (
+	select count(*) as object_count
+	from intellectual_objects io
+	where io.institution_id = bg.institution_id
+	and io.bag_group_identifier = bg.identifier
+	and io.state = 'A'
+) objs on true
+left join lateral (
+	select count(gf.id) as file_count, sum(gf."size") as "size"
+	from intellectual_objects io
+	inner join generic_files gf on gf.intellectual_object_id = io.id
+	where io.institution_id = bg.institution_id
+	and io.bag_group_identifier = bg.identifier
+	and io.state = 'A'
+	and gf.state = 'A'
+) files on true
+left join lateral (
+	select max(wi.date_processed) as last_ingested_at
+	from intellectual_objects io
+	inner join work_items wi on wi.intellectual_object_id = io.id
+	where io.institution_id = bg.institution_id
+	and io.bag_group_identifier = bg.identifier
+	and wi.action = 'Ingest'
+	and wi.status = 'Success'
+) ingests on true;
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '018_bag_groups';
@@ -0,0 +1,91 @@
+-- 019_object_versions.sql
+--
+-- This migration adds tables to record what an object looked like after
+-- each successful ingest. Reingest overwrites generic_files in place, so
+-- without these, we lose track of the files, sizes and checksums of prior
+-- versions.
+--
+-- object_versions has one row per successful ingest work item.
+-- version_number counts up from 1 for each object.
+--
+-- object_version_files has one row for each file that was active in the
+-- object when the version was recorded, with the file's latest digest
+-- for each algorithm.
+--
+-- We can't reconstruct past versions of existing objects, so we backfill
+-- a single version for each, describing its current files, and tie it to
+-- the object's most recent successful ingest.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('019_object_versions', now())
+on conflict ("version") do update set started_at = now();
+
+create table if not exists public.object_versions (
+	id bigserial primary key,
+	intellectual_object_id int4 not null references public.intellectual_objects(id),
+	institution_id int4 not null references public.institutions(id),
+	work_item_id int4 not null references public.work_items(id),
+	version_number int4 not null,
+	file_count int4 not null default 0,
+	"size" int8 not null default 0,
+	ingested_at timestamp not null,
+	created_at timestamp not null,
+	updated_at timestamp not null
+);
+
+create unique index if not exists index_object_versions_on_work_item_id
+on public.object_versions using btree (work_item_id);
+
+create unique index if not exists index_object_versions_on_object_id_and_version_number
+on public.object_versions using btree (intellectual_object_id, version_number);
+
+create index if not exists index_object_versions_on_institution_id
+on public.object_versions using btree (institution_id);
+
+create table if not exists public.object_version_files (
+	id bigserial primary key,
+	object_version_id int8 not null references public.object_versions(id) on delete cascade,
+	generic_file_id int4 not null,
+	identifier varchar not null,
+	"size" int8 not null,
+	md5 varchar null,
+	sha1 varchar null,
+	sha256 varchar null,
+	sha512 varchar null
+);
+
+create index if not exists index_object_version_files_on_object_version_id
+on public.object_version_files using btree (object_version_id);
+
+insert into public.object_versions (intellectual_object_id, institution_id, work_item_id,
+	version_number, ingested_at, created_at, updated_at)
+select distinct on (wi.intellectual_object_id)
+	wi.intellectual_object_id, wi.institution_id, wi.id, 1, wi.date_processed, now(), now()
+from public.work_items wi
+where wi.action = 'Ingest'
+and wi.status = 'Success'
+and wi.intellectual_object_id is not null
+and not exists (select 1 from public.object_versions ov where ov.intellectual_object_id = wi.intellectual_object_id)
+order by wi.intellectual_object_id, wi.date_processed desc;
+
+insert into public.object_version_files (object_version_id, generic_file_id, identifier, "size", md5, sha1, sha256, sha512)
+select ov.id, gf.id, gf.identifier, gf."size",
+	(select cs.digest from checksums cs where cs.generic_file_id = gf.id and cs."algorithm" = 'md5' order by cs.datetime desc limit 1),
+	(select cs.digest from checksums cs where cs.generic_file_id = gf.id and cs."algorithm" = 'sha1' order by cs.datetime desc limit 1),
+	(select cs.digest from checksums cs where cs.generic_file_id = gf.id and cs."algorithm" = 'sha256' order by cs.datetime desc limit 1),
+	(select cs.digest from checksums cs where cs.generic_file_id = gf.id and cs."algorithm" = 'sha512' order by cs.datetime desc limit 1)
+from public.object_versions ov
+inner join public.generic_files gf on gf.intellectual_object_id = ov.intellectual_object_id and gf.state = 'A'
+where not exists (select 1 from public.object_version_files ovf where ovf.object_version_id = ov.id);
+
+update public.object_versions ov
+set file_count = stats.file_count, "size" = stats."size"
+from (
+	select object_version_id, count(*) as file_count, sum("size") as "size"
+	from public.object_version_files
+	group by object_version_id
+) stats
+where stats.object_version_id = ov.id;
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '019_object_versions';
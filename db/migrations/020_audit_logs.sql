@@ -0,0 +1,38 @@
+-- 020_audit_logs.sql
+--
+-- This migration adds the audit_logs table, which records every insert,
+-- update and soft delete done through the pgmodels save paths, along with
+-- who made the change, from where, and what the record looked like before
+-- and after.
+--
+-- actor_id and institution_id are deliberately not foreign keys. Audit
+-- entries must outlive the records they describe, and some entries, such
+-- as changes to storage options, belong to no institution.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('020_audit_logs', now())
+on conflict ("version") do update set started_at = now();
+
+create table if not exists public.audit_logs (
+	id bigserial primary key,
+	actor_id int4 null,
+	actor_email varchar null,
+	remote_addr varchar null,
+	"method" varchar null,
+	route varchar null,
+	"action" varchar not null,
+	model_type varchar not null,
+	model_id int8 not null,
+	institution_id int4 null,
+	"before" jsonb null,
+	"after" jsonb null,
+	created_at timestamp not null
+);
+
+create index if not exists index_audit_logs_on_created_at on public.audit_logs using btree (created_at);
+create index if not exists index_audit_logs_on_institution_id on public.audit_logs using btree (institution_id, created_at);
+create index if not exists index_audit_logs_on_actor_id on public.audit_logs using btree (actor_id);
+create index if not exists index_audit_logs_on_model on public.audit_logs using btree (model_type, model_id);
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '020_audit_logs';
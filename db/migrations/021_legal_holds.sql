@@ -0,0 +1,67 @@
+-- 021_legal_holds.sql
+--
+-- This migration adds the legal_holds table. A legal hold freezes an
+-- intellectual object, or every object in a bag group, so that no one
+-- can request, approve or complete its deletion until the hold is
+-- released or expires. We use these when material must be preserved
+-- for litigation or audits.
+--
+-- Each hold applies to exactly one object or one bag group. Holds are
+-- never deleted. Releasing a hold sets released_at and released_by_id,
+-- so we keep a record of who froze what, why, and for how long.
+--
+-- legal_holds_view adds the names of the institution, target and users
+-- involved, and computes whether each hold is currently active.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('021_legal_holds', now())
+on conflict ("version") do update set started_at = now();
+
+create table if not exists public.legal_holds (
+	id bigserial primary key,
+	institution_id int4 not null references public.institutions(id),
+	intellectual_object_id int4 null references public.intellectual_objects(id),
+	bag_group_id int8 null references public.bag_groups(id),
+	reason varchar not null,
+	placed_by_id int4 not null references public.users(id),
+	expires_at timestamp null,
+	released_at timestamp null,
+	released_by_id int4 null references public.users(id),
+	created_at timestamp not null,
+	updated_at timestamp not null,
+	constraint legal_holds_one_target check ((intellectual_object_id is null) <> (bag_group_id is null))
+);
+
+create index if not exists index_legal_holds_on_institution_id on public.legal_holds using btree (institution_id);
+create index if not exists index_legal_holds_on_intellectual_object_id on public.legal_holds using btree (intellectual_object_id) where intellectual_object_id is not null;
+create index if not exists index_legal_holds_on_bag_group_id on public.legal_holds using btree (bag_group_id) where bag_group_id is not null;
+
+create or replace view public.legal_holds_view as
+select
+	lh.id,
+	lh.institution_id,
+	i."name" as institution_name,
+	lh.intellectual_object_id,
+	io.identifier as object_identifier,
+	lh.bag_group_id,
+	bg.identifier as bag_group_identifier,
+	lh.reason,
+	lh.placed_by_id,
+	pb."name" as placed_by_name,
+	pb.email as placed_by_email,
+	lh.expires_at,
+	lh.released_at,
+	lh.released_by_id,
+	rb."name" as released_by_name,
+	(lh.released_at is null and (lh.expires_at is null or lh.expires_at > now())) as active,
+	lh.created_at,
+	lh.updated_at
+from legal_holds lh
+left join institutions i on i.id = lh.institution_id
+left join intellectual_objects io on io.id = lh.intellectual_object_id
+left join bag_groups bg on bg.id = lh.bag_group_id
+left join users pb on pb.id = lh.placed_by_id
+left join users rb on rb.id = lh.released_by_id;
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '021_legal_holds';
@@ -0,0 +1,47 @@
+-- 022_work_item_transitions.sql
+--
+-- This migration adds a table to record the history of each WorkItem.
+-- WorkItem rows are overwritten on every update, so without this, we
+-- can't tell how long an item spent in each stage, or how many times
+-- it was retried.
+--
+-- work_item_transitions gets a row each time a WorkItem is created, and
+-- each time its stage, status or node changes. The row describes the
+-- item's state as of created_at. An item stays in that state until the
+-- created_at of its next transition.
+--
+-- We can't reconstruct the history of existing items, so we backfill
+-- one row per item describing its current state as of its last update.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('022_work_item_transitions', now())
+on conflict ("version") do update set started_at = now();
+
+create table if not exists public.work_item_transitions (
+	id bigserial primary key,
+	work_item_id int4 not null references public.work_items(id),
+	institution_id int4 not null references public.institutions(id),
+	"action" varchar not null,
+	stage varchar not null,
+	status varchar not null,
+	node varchar null,
+	pid int4 not null default 0,
+	note varchar null,
+	created_at timestamp not null
+);
+
+create index if not exists index_work_item_transitions_on_work_item_id
+on public.work_item_transitions using btree (work_item_id, created_at);
+
+create index if not exists index_work_item_transitions_on_created_at
+on public.work_item_transitions using btree (created_at);
+
+insert into public.work_item_transitions (work_item_id, institution_id, "action",
+	stage, status, node, pid, note, created_at)
+select wi.id, wi.institution_id, wi."action", wi.stage, wi.status, wi.node,
+	coalesce(wi.pid, 0), wi.note, wi.updated_at
+from public.work_items wi
+where not exists (select 1 from public.work_item_transitions t where t.work_item_id = wi.id);
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '022_work_item_transitions';
@@ -0,0 +1,38 @@
+-- 023_nsq_outbox.sql
+--
+-- This migration adds a transactional outbox for NSQ.
+--
+-- Creating or requeueing a WorkItem and pushing its id into NSQ used
+-- to be separate steps, so a crash or an NSQ outage between them left
+-- items that no worker would ever pick up. Now we write a row to
+-- nsq_outbox_messages in the same transaction that saves the WorkItem.
+-- Registry's cron job delivers pending messages to NSQ, retrying with
+-- exponential backoff until they succeed or run out of attempts.
+--
+-- The partial unique index allows only one pending message per item
+-- and topic, so saving the same request twice queues the item once.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('023_nsq_outbox', now())
+on conflict ("version") do update set started_at = now();
+
+create table if not exists public.nsq_outbox_messages (
+	id bigserial primary key,
+	work_item_id int8 not null references public.work_items(id) on delete cascade,
+	topic varchar not null,
+	status varchar not null default 'pending',
+	attempts int4 not null default 0,
+	next_attempt_at timestamp null,
+	last_attempt_at timestamp null,
+	delivered_at timestamp null,
+	error text null,
+	created_at timestamp not null,
+	updated_at timestamp not null
+);
+
+create index if not exists index_nsq_outbox_messages_on_work_item_id on public.nsq_outbox_messages using btree (work_item_id);
+create index if not exists index_nsq_outbox_messages_due on public.nsq_outbox_messages using btree (next_attempt_at) where status = 'pending';
+create unique index if not exists index_nsq_outbox_messages_pending_item_topic on public.nsq_outbox_messages using btree (work_item_id, topic) where status = 'pending';
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '023_nsq_outbox';
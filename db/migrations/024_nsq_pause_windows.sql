@@ -0,0 +1,37 @@
+-- 024_nsq_pause_windows.sql
+--
+-- This migration adds the nsq_pause_windows table, which lets APTrust
+-- admins schedule pauses of NSQ topics or channels ahead of planned
+-- maintenance, such as a storage provider's Glacier outage.
+--
+-- Registry's cron job pauses the topic (or the channel, if channel is
+-- not null) when the window starts and unpauses it when the window ends
+-- or is cancelled. paused_at and unpaused_at record when it actually did
+-- so. We keep windows after they end, so we know who paused what and why.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('024_nsq_pause_windows', now())
+on conflict ("version") do update set started_at = now();
+
+create table if not exists public.nsq_pause_windows (
+	id bigserial primary key,
+	topic varchar not null,
+	channel varchar null,
+	reason text not null,
+	starts_at timestamp not null,
+	ends_at timestamp not null,
+	created_by_id int4 not null references public.users(id),
+	cancelled_at timestamp null,
+	cancelled_by_id int4 null references public.users(id),
+	paused_at timestamp null,
+	unpaused_at timestamp null,
+	error text null,
+	created_at timestamp not null,
+	updated_at timestamp not null
+);
+
+create index if not exists index_nsq_pause_windows_on_ends_at on public.nsq_pause_windows using btree (ends_at);
+create index if not exists index_nsq_pause_windows_not_unpaused on public.nsq_pause_windows using btree (starts_at) where unpaused_at is null;
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '024_nsq_pause_windows';
@@ -0,0 +1,15 @@
+-- 025_webhook_deliveries_drop_response_body.sql
+--
+-- We no longer store the body of webhook recipients' responses.
+-- Institution admins can see their webhook deliveries, and stored
+-- response bodies would let them read responses from hosts they
+-- can't otherwise reach. Deliveries still record the response code.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('025_webhook_deliveries_drop_response_body', now())
+on conflict ("version") do update set started_at = now();
+
+alter table public.webhook_deliveries drop column if exists response_body;
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '025_webhook_deliveries_drop_response_body';
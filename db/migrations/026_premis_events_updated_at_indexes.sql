@@ -0,0 +1,23 @@
+-- 026_premis_events_updated_at_indexes.sql
+--
+-- The premis events changes feed orders by (updated_at, id) and pages
+-- with a keyset condition on the same columns, optionally filtered by
+-- institution. Migration 013 added these indexes for generic_files and
+-- intellectual_objects, but indexed premis_events on date_time only,
+-- so each page of the events feed had to scan and sort the whole table.
+--
+-- Creating indexes on premis_events will take a while in production.
+-- Run this one in a screen session.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('026_premis_events_updated_at_indexes', now())
+on conflict ("version") do update set started_at = now();
+
+create index if not exists index_premis_events_on_updated_at_and_id
+on public.premis_events using btree (updated_at, id);
+
+create index if not exists index_premis_events_on_institution_id_updated_at_and_id
+on public.premis_events using btree (institution_id, updated_at, id);
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '026_premis_events_updated_at_indexes';
@@ -0,0 +1,16 @@
+-- 027_nsq_outbox_delivered_index.sql
+--
+-- Registry's cron job now deletes NSQ outbox messages some time after
+-- they're delivered, so nsq_outbox_messages doesn't grow forever. This
+-- partial index lets it find old delivered messages without scanning
+-- the whole table. Failed messages are kept.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('027_nsq_outbox_delivered_index', now())
+on conflict ("version") do update set started_at = now();
+
+create index if not exists index_nsq_outbox_messages_delivered_at
+on public.nsq_outbox_messages using btree (delivered_at) where status = 'delivered';
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '027_nsq_outbox_delivered_index';
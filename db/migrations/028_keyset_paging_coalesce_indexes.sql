@@ -0,0 +1,28 @@
+-- 028_keyset_paging_coalesce_indexes.sql
+--
+-- premis_events.date_time is nullable, so cursor requests on events,
+-- which sort by date_time by default, order by and compare
+-- (coalesce(date_time, <zero time>), id) so events with no date_time
+-- still show up. Postgres can't use the plain (date_time, id) indexes
+-- from migration 013 for that expression. These expression indexes
+-- match it exactly.
+--
+-- The default must match what go-pg sends for Go's zero time.Time,
+-- which is '0001-01-01 00:00:00+00:00:00'. The offset is ignored for
+-- timestamp columns, so that's the same value as below.
+--
+-- Like 013, this will take a while in production. Run it in a screen
+-- session.
+
+-- Note that we're starting the migration.
+insert into schema_migrations ("version", started_at) values ('028_keyset_paging_coalesce_indexes', now())
+on conflict ("version") do update set started_at = now();
+
+create index if not exists index_premis_events_on_coalesce_date_time_and_id
+on public.premis_events using btree ((coalesce(date_time, '0001-01-01 00:00:00'::timestamp)), id);
+
+create index if not exists index_premis_events_on_institution_id_coalesce_date_time_and_id
+on public.premis_events using btree (institution_id, (coalesce(date_time, '0001-01-01 00:00:00'::timestamp)), id);
+
+-- Now note that the migration is complete.
+update schema_migrations set finished_at = now() where "version" = '028_keyset_paging_coalesce_indexes';
@@ -32,11 +32,15 @@ var LoadOrder = []string{
 	"institutions",
 	"users",
 	"intellectual_objects",
+	"bag_groups",
 	"generic_files",
 	"checksums",
 	"storage_records",
 	"premis_events",
 	"work_items",
+	"work_item_transitions",
+	"object_versions",
+	"object_version_files",
 	"deletion_requests",
 	"deletion_requests_generic_files",
 	"deletion_requests_intellectual_objects",
@@ -44,6 +48,8 @@ var LoadOrder = []string{
 	"alerts_premis_events",
 	"alerts_users",
 	"alerts_work_items",
+	"audit_logs",
+	"legal_holds",
 }
 
 // HasNoIDColumn lists tables that have no identity column. Attempting
@@ -80,9 +86,20 @@ var DropOrder = []string{
 	"alerts_users",
 	"alerts_premis_events",
 	"alerts",
+	"audit_logs",
+	"webhook_deliveries",
+	"webhooks",
+	"rate_limit_overrides",
+	"legal_holds",
+	"bag_groups",
+	"object_version_files",
+	"object_versions",
 	"deletion_requests_generic_files",
 	"deletion_requests_intellectual_objects",
 	"deletion_requests",
+	"nsq_pause_windows",
+	"nsq_outbox_messages",
+	"work_item_transitions",
 	"work_items",
 	"premis_events",
 	"storage_records",
@@ -90,6 +107,7 @@ var DropOrder = []string{
 	"generic_files",
 	"intellectual_objects",
 	"roles_users",
+	"api_keys",
 	"users",
 	"institutions",
 	"roles",
@@ -0,0 +1,72 @@
+package forms
+
+import (
+	"github.com/APTrust/registry/pgmodels"
+)
+
+type APIKeyForm struct {
+	Form
+}
+
+func NewAPIKeyForm(key *pgmodels.APIKey) *APIKeyForm {
+	form := &APIKeyForm{
+		Form: NewForm(key, "api_keys/form.html", "/api_keys"),
+	}
+	form.init()
+	form.SetValues()
+	return form
+}
+
+func (f *APIKeyForm) init() {
+	f.Fields["Label"] = &Field{
+		Name:        "Label",
+		Label:       "Label",
+		Placeholder: "What is this key for?",
+		ErrMsg:      pgmodels.ErrAPIKeyLabel,
+		Attrs: map[string]string{
+			"required":  "",
+			"maxlength": "100",
+		},
+	}
+	f.Fields["Scope"] = &Field{
+		Name:        "Scope",
+		Label:       "Scope",
+		Placeholder: "",
+		ErrMsg:      pgmodels.ErrAPIKeyScope,
+		Options:     APIKeyScopeList,
+		Attrs: map[string]string{
+			"required": "",
+		},
+	}
+	f.Fields["AllowedIPs"] = &Field{
+		Name:        "AllowedIPs",
+		Label:       "Allowed IP Addresses (optional)",
+		Placeholder: "E.g. 10.0.0.1, 192.168.1.0/24",
+		ErrMsg:      pgmodels.ErrAPIKeyAllowedIPs,
+		Attrs:       map[string]string{},
+	}
+	f.Fields["ExpiresAt"] = &Field{
+		Name:        "ExpiresAt",
+		Label:       "Expiration Date (optional)",
+		Placeholder: "",
+		ErrMsg:      pgmodels.ErrAPIKeyExpiration,
+		Attrs:       map[string]string{},
+	}
+}
+
+// SetValues sets the form values to match the APIKey values.
+func (f *APIKeyForm) SetValues() {
+	key := f.Model.(*pgmodels.APIKey)
+	f.Fields["Label"].Value = key.Label
+	f.Fields["Scope"].Value = key.Scope
+	f.Fields["AllowedIPs"].Value = key.AllowedIPs
+	if !key.ExpiresAt.IsZero() {
+		f.Fields["ExpiresAt"].Value = key.ExpiresAt.Format("2006-01-02")
+	}
+}
+
+// PostSaveURL returns the URL of the user's API key list. There is
+// no show page for individual keys.
+func (f *APIKeyForm) PostSaveURL() string {
+	return f.BaseURL
+}
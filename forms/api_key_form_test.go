@@ -0,0 +1,35 @@
+package forms_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyForm(t *testing.T) {
+	key := &pgmodels.APIKey{
+		Label:      "Nightly report",
+		Scope:      constants.APIKeyScopeReadWrite,
+		AllowedIPs: "10.0.0.1",
+		ExpiresAt:  time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	form := forms.NewAPIKeyForm(key)
+	require.NotNil(t, form)
+	assert.Equal(t, "api_keys/form.html", form.Template)
+	assert.Equal(t, "/api_keys/new", form.Action())
+	assert.Equal(t, "/api_keys", form.PostSaveURL())
+	assert.Equal(t, 2, len(form.Fields["Scope"].Options))
+
+	assert.Equal(t, key.Label, form.Fields["Label"].Value)
+	assert.Equal(t, key.Scope, form.Fields["Scope"].Value)
+	assert.Equal(t, key.AllowedIPs, form.Fields["AllowedIPs"].Value)
+	assert.Equal(t, "2030-06-01", form.Fields["ExpiresAt"].Value)
+
+	form = forms.NewAPIKeyForm(&pgmodels.APIKey{})
+	assert.Nil(t, form.Fields["ExpiresAt"].Value)
+}
@@ -0,0 +1,89 @@
+package forms
+
+import (
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/pgmodels"
+)
+
+// AuditLogFilterForm is the form that displays filtering options for
+// the audit log.
+type AuditLogFilterForm struct {
+	Form
+	FilterCollection  *pgmodels.FilterCollection
+	actingUserIsAdmin bool
+	instOptions       []*ListOption
+}
+
+func NewAuditLogFilterForm(fc *pgmodels.FilterCollection, actingUser *pgmodels.User) (FilterForm, error) {
+	f := &AuditLogFilterForm{
+		Form:              NewForm(nil, "audit_logs/_filters.html", "/audit_logs"),
+		FilterCollection:  fc,
+		actingUserIsAdmin: actingUser.IsAdmin(),
+	}
+	var err error
+	if actingUser.IsAdmin() {
+		// SysAdmin can view changes at all institutions.
+		f.instOptions, err = ListInstitutions(false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	f.init()
+	f.SetValues()
+	return f, nil
+}
+
+func (f *AuditLogFilterForm) init() {
+	f.Fields["action"] = &Field{
+		Name:        "action",
+		Label:       "Action",
+		Placeholder: "Action",
+		Options:     Options(constants.AuditActions),
+	}
+	f.Fields["actor_email"] = &Field{
+		Name:        "actor_email",
+		Label:       "Changed By (Email)",
+		Placeholder: "Changed By (Email)",
+	}
+	if f.actingUserIsAdmin {
+		f.Fields["institution_id"] = &Field{
+			Name:        "institution_id",
+			Label:       "Institution",
+			Placeholder: "Institution",
+			Options:     f.instOptions,
+		}
+	}
+	f.Fields["model_type"] = &Field{
+		Name:        "model_type",
+		Label:       "Record Type",
+		Placeholder: "Record Type",
+		Options:     AuditModelTypeList,
+	}
+	f.Fields["model_id"] = &Field{
+		Name:        "model_id",
+		Label:       "Record ID",
+		Placeholder: "Record ID",
+	}
+	f.Fields["created_at__gteq"] = &Field{
+		Name:        "created_at__gteq",
+		Label:       "Changed On or After",
+		Placeholder: "Changed On or After",
+	}
+	f.Fields["created_at__lteq"] = &Field{
+		Name:        "created_at__lteq",
+		Label:       "Changed On or Before",
+		Placeholder: "Changed On or Before",
+	}
+}
+
+// SetValues sets the form values to match the filter values.
+func (f *AuditLogFilterForm) SetValues() {
+	for _, fieldName := range pgmodels.AuditLogFilters {
+		if f.Fields[fieldName] == nil {
+			common.ConsoleDebug("No filter for %s", fieldName)
+			continue
+		}
+		f.Fields[fieldName].Value = f.FilterCollection.ValueOf(fieldName)
+	}
+}
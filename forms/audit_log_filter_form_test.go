@@ -0,0 +1,54 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getAuditLogFilters() *pgmodels.FilterCollection {
+	fc := pgmodels.NewFilterCollection()
+	fc.Add("action", []string{constants.AuditActionUpdate})
+	fc.Add("actor_email", []string{"admin@inst1.edu"})
+	fc.Add("created_at__gteq", []string{"2021-01-01"})
+	fc.Add("created_at__lteq", []string{"2021-12-31"})
+	fc.Add("institution_id", []string{"2"})
+	fc.Add("model_id", []string{"3"})
+	fc.Add("model_type", []string{"User"})
+	return fc
+}
+
+func TestAuditLogFilterFormSysAdmin(t *testing.T) {
+	sysAdmin := testutil.InitUser(t, "system@aptrust.org")
+	fc := getAuditLogFilters()
+	form, err := forms.NewAuditLogFilterForm(fc, sysAdmin)
+	require.Nil(t, err)
+	fields := form.GetFields()
+	for _, name := range pgmodels.AuditLogFilters {
+		if name == "actor_id" {
+			// Filter is available in the API only.
+			continue
+		}
+		assert.Equal(t, fc.ValueOf(name), fields[name].Value, name)
+	}
+	assert.True(t, len(fields["institution_id"].Options) > 1)
+	assert.Equal(t, len(constants.AuditActions), len(fields["action"].Options))
+	assert.Equal(t, forms.AuditModelTypeList, fields["model_type"].Options)
+}
+
+func TestAuditLogFilterFormInstAdmin(t *testing.T) {
+	instAdmin := testutil.InitUser(t, "admin@inst1.edu")
+	fc := getAuditLogFilters()
+	form, err := forms.NewAuditLogFilterForm(fc, instAdmin)
+	require.Nil(t, err)
+	fields := form.GetFields()
+	assert.Equal(t, fc.ValueOf("model_type"), fields["model_type"].Value)
+
+	// Inst admins can see only their own institution's changes.
+	assert.Nil(t, fields["institution_id"])
+}
@@ -0,0 +1,71 @@
+package forms
+
+import (
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/pgmodels"
+)
+
+// BagGroupFilterForm is the form that displays filtering options for
+// the bag group list page.
+type BagGroupFilterForm struct {
+	Form
+	FilterCollection  *pgmodels.FilterCollection
+	actingUserIsAdmin bool
+	instOptions       []*ListOption
+}
+
+func NewBagGroupFilterForm(fc *pgmodels.FilterCollection, actingUser *pgmodels.User) (FilterForm, error) {
+	f := &BagGroupFilterForm{
+		Form:              NewForm(nil, "bag_groups/_filters.html", "/bag_groups"),
+		FilterCollection:  fc,
+		actingUserIsAdmin: actingUser.IsAdmin(),
+	}
+	var err error
+	if actingUser.IsAdmin() {
+		// SysAdmin can view bag groups at all institutions.
+		f.instOptions, err = ListInstitutions(false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	f.init()
+	f.SetValues()
+	return f, nil
+}
+
+func (f *BagGroupFilterForm) init() {
+	f.Fields["identifier__starts_with"] = &Field{
+		Name:        "identifier__starts_with",
+		Label:       "Identifier Starts With",
+		Placeholder: "Identifier Starts With",
+	}
+	if f.actingUserIsAdmin {
+		f.Fields["institution_id"] = &Field{
+			Name:        "institution_id",
+			Label:       "Institution",
+			Placeholder: "Institution",
+			Options:     f.instOptions,
+		}
+	}
+	f.Fields["last_ingested_at__gteq"] = &Field{
+		Name:        "last_ingested_at__gteq",
+		Label:       "Last Ingested On or After",
+		Placeholder: "Last Ingested On or After",
+	}
+	f.Fields["last_ingested_at__lteq"] = &Field{
+		Name:        "last_ingested_at__lteq",
+		Label:       "Last Ingested On or Before",
+		Placeholder: "Last Ingested On or Before",
+	}
+}
+
+// SetValues sets the form values to match the filter values.
+func (f *BagGroupFilterForm) SetValues() {
+	for _, fieldName := range pgmodels.BagGroupFilters {
+		if f.Fields[fieldName] == nil {
+			common.ConsoleDebug("No filter for %s", fieldName)
+			continue
+		}
+		f.Fields[fieldName].Value = f.FilterCollection.ValueOf(fieldName)
+	}
+}
@@ -0,0 +1,45 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getBagGroupFilters() *pgmodels.FilterCollection {
+	fc := pgmodels.NewFilterCollection()
+	fc.Add("identifier__starts_with", []string{"carolina"})
+	fc.Add("institution_id", []string{"2"})
+	fc.Add("last_ingested_at__gteq", []string{"2016-01-01"})
+	fc.Add("last_ingested_at__lteq", []string{"2016-12-31"})
+	return fc
+}
+
+func TestBagGroupFilterFormSysAdmin(t *testing.T) {
+	sysAdmin := testutil.InitUser(t, "system@aptrust.org")
+	fc := getBagGroupFilters()
+	form, err := forms.NewBagGroupFilterForm(fc, sysAdmin)
+	require.Nil(t, err)
+	fields := form.GetFields()
+	assert.Equal(t, fc.ValueOf("identifier__starts_with"), fields["identifier__starts_with"].Value)
+	assert.Equal(t, fc.ValueOf("institution_id"), fields["institution_id"].Value)
+	assert.Equal(t, fc.ValueOf("last_ingested_at__gteq"), fields["last_ingested_at__gteq"].Value)
+	assert.Equal(t, fc.ValueOf("last_ingested_at__lteq"), fields["last_ingested_at__lteq"].Value)
+	assert.True(t, len(fields["institution_id"].Options) > 1)
+}
+
+func TestBagGroupFilterFormNonAdmin(t *testing.T) {
+	user := testutil.InitUser(t, "user@inst1.edu")
+	fc := getBagGroupFilters()
+	form, err := forms.NewBagGroupFilterForm(fc, user)
+	require.Nil(t, err)
+	fields := form.GetFields()
+	assert.Equal(t, fc.ValueOf("identifier__starts_with"), fields["identifier__starts_with"].Value)
+
+	// Non-admins can see only their own institution's bag groups.
+	assert.Nil(t, fields["institution_id"])
+}
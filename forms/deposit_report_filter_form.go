@@ -1,6 +1,8 @@
 package forms
 
 import (
+	"strconv"
+
 	"github.com/APTrust/registry/common"
 	"github.com/APTrust/registry/constants"
 	"github.com/APTrust/registry/pgmodels"
@@ -13,6 +15,8 @@ type DepositReportFilterForm struct {
 	FilterCollection  *pgmodels.FilterCollection
 	actingUserIsAdmin bool
 	instOptions       []*ListOption
+	bagGroupOptions   []*ListOption
+	tooManyBagGroups  bool
 }
 
 func NewDepositReportFilterForm(fc *pgmodels.FilterCollection, actingUser *pgmodels.User) (FilterForm, error) {
@@ -28,6 +32,18 @@ func NewDepositReportFilterForm(fc *pgmodels.FilterCollection, actingUser *pgmod
 			return nil, err
 		}
 	}
+	// Bag groups belong to a single institution, so we list them
+	// only when we know which institution the report is for.
+	bagGroupInstID := actingUser.InstitutionID
+	if actingUser.IsAdmin() {
+		bagGroupInstID, _ = strconv.ParseInt(fc.ValueOf("institution_id"), 10, 64)
+	}
+	if bagGroupInstID > 0 {
+		f.bagGroupOptions, f.tooManyBagGroups, err = ListBagGroups(bagGroupInstID, BagGroupListLimit)
+		if err != nil {
+			return nil, err
+		}
+	}
 	f.init()
 	f.SetValues()
 	return f, nil
@@ -56,6 +72,24 @@ func (f *DepositReportFilterForm) init() {
 		Attrs:       make(map[string]string),
 	}
 
+	f.Fields["bag_group_id"] = &Field{
+		Name:        "bag_group_id",
+		Label:       "Bag Group",
+		Placeholder: "Bag Group",
+		Options:     f.bagGroupOptions,
+		Attrs:       make(map[string]string),
+	}
+	// Institutions with too many bag groups to list type
+	// the identifier instead.
+	if f.tooManyBagGroups {
+		f.Fields["bag_group_identifier"] = &Field{
+			Name:        "bag_group_identifier",
+			Label:       "Bag Group",
+			Placeholder: "Bag group identifier",
+			Attrs:       make(map[string]string),
+		}
+	}
+
 	storageOpts := Options(constants.StorageOptions)
 	storageOpts = append(storageOpts, &ListOption{Value: "Total", Text: "Total"})
 	f.Fields["storage_option"] = &Field{
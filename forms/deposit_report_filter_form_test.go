@@ -36,6 +36,13 @@ func TestDepositReportFilterFormSysAdmin(t *testing.T) {
 	fields := form.GetFields()
 	testDepositReportFields(t, fc, fields)
 	assert.True(t, len(fields["institution_id"].Options) > 1)
+
+	// Bag group list comes from the institution filter.
+	assert.Equal(t, 2, len(fields["bag_group_id"].Options))
+	fc = pgmodels.NewFilterCollection()
+	form, err := forms.NewDepositReportFilterForm(fc, sysAdmin)
+	require.Nil(t, err)
+	assert.Empty(t, form.GetFields()["bag_group_id"].Options)
 }
 
 func TestDepositReportFilterFormNonAdmin(t *testing.T) {
@@ -52,6 +59,9 @@ func TestDepositReportFilterFormNonAdmin(t *testing.T) {
 		// Non sysadmin can see only their own alerts, so
 		// there should be no filter options in these lists.
 		assert.Empty(t, fields["institution_id"].Options)
+		// But they can choose from their own bag groups.
+		require.Equal(t, 2, len(fields["bag_group_id"].Options))
+		assert.Equal(t, "carolina-1", fields["bag_group_id"].Options[0].Text)
 	}
 }
 
@@ -0,0 +1,72 @@
+package forms
+
+import (
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/pgmodels"
+)
+
+// LegalHoldFilterForm is the form that displays filtering options for
+// the legal hold list.
+type LegalHoldFilterForm struct {
+	Form
+	FilterCollection  *pgmodels.FilterCollection
+	actingUserIsAdmin bool
+	instOptions       []*ListOption
+}
+
+func NewLegalHoldFilterForm(fc *pgmodels.FilterCollection, actingUser *pgmodels.User) (FilterForm, error) {
+	f := &LegalHoldFilterForm{
+		Form:              NewForm(nil, "legal_holds/_filters.html", "/legal_holds"),
+		FilterCollection:  fc,
+		actingUserIsAdmin: actingUser.IsAdmin(),
+	}
+	var err error
+	if actingUser.IsAdmin() {
+		// SysAdmin can view holds at all institutions.
+		f.instOptions, err = ListInstitutions(false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	f.init()
+	f.SetValues()
+	return f, nil
+}
+
+func (f *LegalHoldFilterForm) init() {
+	f.Fields["active"] = &Field{
+		Name:        "active",
+		Label:       "Active",
+		Placeholder: "Active",
+		Options:     YesNoList,
+	}
+	if f.actingUserIsAdmin {
+		f.Fields["institution_id"] = &Field{
+			Name:        "institution_id",
+			Label:       "Institution",
+			Placeholder: "Institution",
+			Options:     f.instOptions,
+		}
+	}
+	f.Fields["created_at__gteq"] = &Field{
+		Name:        "created_at__gteq",
+		Label:       "Placed On or After",
+		Placeholder: "Placed On or After",
+	}
+	f.Fields["created_at__lteq"] = &Field{
+		Name:        "created_at__lteq",
+		Label:       "Placed On or Before",
+		Placeholder: "Placed On or Before",
+	}
+}
+
+// SetValues sets the form values to match the filter values.
+func (f *LegalHoldFilterForm) SetValues() {
+	for _, fieldName := range pgmodels.LegalHoldFilters {
+		if f.Fields[fieldName] == nil {
+			common.ConsoleDebug("No filter for %s", fieldName)
+			continue
+		}
+		f.Fields[fieldName].Value = f.FilterCollection.ValueOf(fieldName)
+	}
+}
@@ -0,0 +1,45 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getLegalHoldFilters() *pgmodels.FilterCollection {
+	fc := pgmodels.NewFilterCollection()
+	fc.Add("active", []string{"true"})
+	fc.Add("created_at__gteq", []string{"2021-01-01"})
+	fc.Add("created_at__lteq", []string{"2021-12-31"})
+	fc.Add("institution_id", []string{"2"})
+	return fc
+}
+
+func TestLegalHoldFilterFormSysAdmin(t *testing.T) {
+	sysAdmin := testutil.InitUser(t, "system@aptrust.org")
+	fc := getLegalHoldFilters()
+	form, err := forms.NewLegalHoldFilterForm(fc, sysAdmin)
+	require.Nil(t, err)
+	fields := form.GetFields()
+	for _, name := range []string{"active", "created_at__gteq", "created_at__lteq", "institution_id"} {
+		assert.Equal(t, fc.ValueOf(name), fields[name].Value, name)
+	}
+	assert.True(t, len(fields["institution_id"].Options) > 1)
+	assert.Equal(t, forms.YesNoList, fields["active"].Options)
+}
+
+func TestLegalHoldFilterFormInstUser(t *testing.T) {
+	instUser := testutil.InitUser(t, "user@inst1.edu")
+	fc := getLegalHoldFilters()
+	form, err := forms.NewLegalHoldFilterForm(fc, instUser)
+	require.Nil(t, err)
+	fields := form.GetFields()
+	assert.Equal(t, fc.ValueOf("active"), fields["active"].Value)
+
+	// Non-admins can see only their own institution's holds.
+	assert.Nil(t, fields["institution_id"])
+}
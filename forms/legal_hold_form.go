@@ -0,0 +1,61 @@
+package forms
+
+import (
+	"github.com/APTrust/registry/pgmodels"
+)
+
+type LegalHoldForm struct {
+	Form
+}
+
+func NewLegalHoldForm(hold *pgmodels.LegalHold) *LegalHoldForm {
+	form := &LegalHoldForm{
+		Form: NewForm(hold, "legal_holds/form.html", "/legal_holds"),
+	}
+	form.init()
+	form.SetValues()
+	return form
+}
+
+func (f *LegalHoldForm) init() {
+	f.Fields["IntellectualObjectID"] = &Field{
+		Name:   "intellectual_object_id",
+		ErrMsg: pgmodels.ErrLegalHoldTarget,
+		Attrs:  map[string]string{},
+	}
+	f.Fields["BagGroupID"] = &Field{
+		Name:  "bag_group_id",
+		Attrs: map[string]string{},
+	}
+	f.Fields["Reason"] = &Field{
+		Name:        "Reason",
+		Label:       "Reason",
+		Placeholder: "Why must this material be preserved? E.g. case name or audit reference.",
+		ErrMsg:      pgmodels.ErrLegalHoldReason,
+		Attrs: map[string]string{
+			"required": "",
+		},
+	}
+	f.Fields["ExpiresAt"] = &Field{
+		Name:        "ExpiresAt",
+		Label:       "Expiration Date (optional)",
+		Placeholder: "",
+		ErrMsg:      pgmodels.ErrLegalHoldExpiration,
+		Attrs:       map[string]string{},
+	}
+}
+
+// SetValues sets the form values to match the LegalHold values.
+func (f *LegalHoldForm) SetValues() {
+	hold := f.Model.(*pgmodels.LegalHold)
+	if hold.IntellectualObjectID > 0 {
+		f.Fields["IntellectualObjectID"].Value = hold.IntellectualObjectID
+	}
+	if hold.BagGroupID > 0 {
+		f.Fields["BagGroupID"].Value = hold.BagGroupID
+	}
+	f.Fields["Reason"].Value = hold.Reason
+	if !hold.ExpiresAt.IsZero() {
+		f.Fields["ExpiresAt"].Value = hold.ExpiresAt.Format("2006-01-02")
+	}
+}
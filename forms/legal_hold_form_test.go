@@ -0,0 +1,34 @@
+package forms_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegalHoldForm(t *testing.T) {
+	hold := &pgmodels.LegalHold{
+		InstitutionID:        2,
+		IntellectualObjectID: 10,
+		Reason:               "Litigation",
+		ExpiresAt:            time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	form := forms.NewLegalHoldForm(hold)
+	require.NotNil(t, form)
+	assert.Equal(t, "legal_holds/form.html", form.Template)
+	assert.Equal(t, "/legal_holds/new", form.Action())
+
+	assert.Equal(t, hold.IntellectualObjectID, form.Fields["IntellectualObjectID"].Value)
+	assert.Nil(t, form.Fields["BagGroupID"].Value)
+	assert.Equal(t, hold.Reason, form.Fields["Reason"].Value)
+	assert.Equal(t, "2030-06-01", form.Fields["ExpiresAt"].Value)
+
+	form = forms.NewLegalHoldForm(&pgmodels.LegalHold{BagGroupID: 3})
+	assert.Equal(t, int64(3), form.Fields["BagGroupID"].Value)
+	assert.Nil(t, form.Fields["IntellectualObjectID"].Value)
+	assert.Nil(t, form.Fields["ExpiresAt"].Value)
+}
@@ -34,6 +34,36 @@ var AllRolesList = []*ListOption{
 	{constants.RoleSysAdmin, "APTrust System Administrator", false},
 }
 
+// APIKeyScopeList describes what an API key may do.
+var APIKeyScopeList = []*ListOption{
+	{constants.APIKeyScopeReadOnly, "Read Only", false},
+	{constants.APIKeyScopeReadWrite, "Read and Write", false},
+}
+
+// AuditModelTypeList lists the types of records that appear
+// in the audit log.
+var AuditModelTypeList = Options([]string{
+	"APIKey",
+	"Alert",
+	"BagGroup",
+	"Checksum",
+	"DeletionRequest",
+	"GenericFile",
+	"Institution",
+	"IntellectualObject",
+	"InternalMetadata",
+	"LegalHold",
+	"ObjectVersion",
+	"PremisEvent",
+	"RateLimitOverride",
+	"StorageOption",
+	"StorageRecord",
+	"User",
+	"Webhook",
+	"WebhookDelivery",
+	"WorkItem",
+})
+
 var BagItProfileIdentifiers = []*ListOption{
 	{constants.DefaultProfileIdentifier, "APTrust", false},
 	{constants.BTRProfileIdentifier, "BTR", false},
@@ -84,6 +114,15 @@ var TwoFactorMethodList = []*ListOption{
 	{constants.TwoFactorSMS, "Text Message", false},
 }
 
+// WebhookEventTypeList describes the events to which a webhook may
+// subscribe.
+var WebhookEventTypeList = []*ListOption{
+	{constants.WebhookEventWorkItemCompleted, "Work item completed (success, failure or cancellation)", false},
+	{constants.WebhookEventRestorationCompleted, "Restoration completed", false},
+	{constants.WebhookEventDeletionCompleted, "Deletion completed", false},
+	{constants.WebhookEventFixityFailed, "Fixity check failed", false},
+}
+
 var YesNoList = []*ListOption{
 	{"true", "Yes", false},
 	{"false", "No", false},
@@ -105,6 +144,31 @@ func ListInstitutions(membersOnly bool) ([]*ListOption, error) {
 	return options, nil
 }
 
+// BagGroupListLimit is the most bag groups we'll list in a select
+// list. Institutions with more than this can filter by bag group
+// identifier instead.
+const BagGroupListLimit = 500
+
+// ListBagGroups returns a list of up to limit bag groups belonging to
+// the specified institution. If the institution has more than limit
+// bag groups, this returns an empty list and truncated is true, since
+// a partial list would let users pick only some of their groups.
+func ListBagGroups(institutionID int64, limit int) (options []*ListOption, truncated bool, err error) {
+	query := pgmodels.NewQuery().Columns("id", "identifier").Where("institution_id", "=", institutionID).OrderBy("identifier", "asc").Limit(limit + 1).Offset(0)
+	bagGroups, err := pgmodels.BagGroupSelect(query)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(bagGroups) > limit {
+		return make([]*ListOption, 0), true, nil
+	}
+	options = make([]*ListOption, len(bagGroups))
+	for i, bagGroup := range bagGroups {
+		options[i] = &ListOption{strconv.FormatInt(bagGroup.ID, 10), bagGroup.Identifier, false}
+	}
+	return options, false, nil
+}
+
 func ListUsers(institutionID int64) ([]*ListOption, error) {
 	query := pgmodels.NewQuery().Columns("id", "name").OrderBy("name", "asc").Limit(200).Offset(0)
 	if institutionID > 0 {
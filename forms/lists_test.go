@@ -56,6 +56,22 @@ func TestListUsers(t *testing.T) {
 	}
 }
 
+func TestListBagGroups(t *testing.T) {
+	db.LoadFixtures()
+	options, truncated, err := forms.ListBagGroups(2, forms.BagGroupListLimit)
+	require.Nil(t, err)
+	assert.False(t, truncated)
+	require.Equal(t, 2, len(options))
+	assert.Equal(t, "carolina-1", options[0].Text)
+
+	// If the institution has more groups than we can list,
+	// we list none of them.
+	options, truncated, err = forms.ListBagGroups(2, 1)
+	require.Nil(t, err)
+	assert.True(t, truncated)
+	assert.Empty(t, options)
+}
+
 func TestListDepositReportDates(t *testing.T) {
 	options := forms.ListDepositReportDates(true)
 
@@ -0,0 +1,93 @@
+package forms
+
+import (
+	"sort"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/pgmodels"
+)
+
+// nsqDateTimeFormat is the format of datetime-local inputs.
+const nsqDateTimeFormat = "2006-01-02T15:04"
+
+type NSQPauseWindowForm struct {
+	Form
+}
+
+func NewNSQPauseWindowForm(window *pgmodels.NSQPauseWindow) *NSQPauseWindowForm {
+	form := &NSQPauseWindowForm{
+		Form: NewForm(window, "nsq/pause_form.html", "/nsq/pauses"),
+	}
+	form.init()
+	form.SetValues()
+	return form
+}
+
+func (f *NSQPauseWindowForm) init() {
+	f.Fields["Topic"] = &Field{
+		Name:    "Topic",
+		Label:   "Topic",
+		ErrMsg:  pgmodels.ErrNSQPauseTopic,
+		Options: nsqTopicOptions(),
+		Attrs: map[string]string{
+			"required": "",
+		},
+	}
+	f.Fields["Channel"] = &Field{
+		Name:        "Channel",
+		Label:       "Channel (optional; leave blank to pause the whole topic)",
+		Placeholder: "",
+		Attrs:       map[string]string{},
+	}
+	f.Fields["Reason"] = &Field{
+		Name:        "Reason",
+		Label:       "Reason (shown to all users while the pause is in effect)",
+		Placeholder: "E.g. Glacier restorations are paused for scheduled storage provider maintenance.",
+		ErrMsg:      pgmodels.ErrNSQPauseReason,
+		Attrs: map[string]string{
+			"required": "",
+		},
+	}
+	f.Fields["StartsAt"] = &Field{
+		Name:   "StartsAt",
+		Label:  "Start (UTC)",
+		ErrMsg: pgmodels.ErrNSQPauseStart,
+		Attrs: map[string]string{
+			"required": "",
+		},
+	}
+	f.Fields["EndsAt"] = &Field{
+		Name:   "EndsAt",
+		Label:  "End (UTC)",
+		ErrMsg: pgmodels.ErrNSQPauseEnd,
+		Attrs: map[string]string{
+			"required": "",
+		},
+	}
+}
+
+// SetValues sets the form values to match the NSQPauseWindow values.
+func (f *NSQPauseWindowForm) SetValues() {
+	window := f.Model.(*pgmodels.NSQPauseWindow)
+	f.Fields["Topic"].Value = window.Topic
+	f.Fields["Channel"].Value = window.Channel
+	f.Fields["Reason"].Value = window.Reason
+	if !window.StartsAt.IsZero() {
+		f.Fields["StartsAt"].Value = window.StartsAt.UTC().Format(nsqDateTimeFormat)
+	}
+	if !window.EndsAt.IsZero() {
+		f.Fields["EndsAt"].Value = window.EndsAt.UTC().Format(nsqDateTimeFormat)
+	}
+}
+
+// nsqTopicOptions returns a sorted list of the NSQ topics Registry
+// and the workers use.
+func nsqTopicOptions() []*ListOption {
+	topics := make([]string, len(constants.NonIngestTopics))
+	copy(topics, constants.NonIngestTopics)
+	for _, topic := range constants.NSQIngestTopicFor {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return Options(topics)
+}
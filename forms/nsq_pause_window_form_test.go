@@ -0,0 +1,45 @@
+package forms_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNSQPauseWindowForm(t *testing.T) {
+	window := &pgmodels.NSQPauseWindow{
+		Topic:    constants.TopicGlacierRestore,
+		Channel:  "restore_glacier_worker_chan",
+		Reason:   "Glacier maintenance",
+		StartsAt: time.Date(2030, 6, 1, 14, 30, 0, 0, time.UTC),
+		EndsAt:   time.Date(2030, 6, 2, 2, 0, 0, 0, time.UTC),
+	}
+	form := forms.NewNSQPauseWindowForm(window)
+	require.NotNil(t, form)
+	assert.Equal(t, "nsq/pause_form.html", form.Template)
+	assert.Equal(t, "/nsq/pauses/new", form.Action())
+
+	assert.Equal(t, window.Topic, form.Fields["Topic"].Value)
+	assert.Equal(t, window.Channel, form.Fields["Channel"].Value)
+	assert.Equal(t, window.Reason, form.Fields["Reason"].Value)
+	assert.Equal(t, "2030-06-01T14:30", form.Fields["StartsAt"].Value)
+	assert.Equal(t, "2030-06-02T02:00", form.Fields["EndsAt"].Value)
+
+	// Topic list includes ingest and non-ingest topics.
+	topics := make([]string, len(form.Fields["Topic"].Options))
+	for i, option := range form.Fields["Topic"].Options {
+		topics[i] = option.Value
+	}
+	assert.Contains(t, topics, constants.TopicGlacierRestore)
+	assert.Contains(t, topics, constants.TopicDelete)
+	assert.Contains(t, topics, constants.NSQIngestTopicFor[constants.StageReceive])
+
+	form = forms.NewNSQPauseWindowForm(&pgmodels.NSQPauseWindow{})
+	assert.Nil(t, form.Fields["StartsAt"].Value)
+	assert.Nil(t, form.Fields["EndsAt"].Value)
+}
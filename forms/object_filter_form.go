@@ -112,6 +112,11 @@ func (f *ObjectFilterForm) init() {
 		Label:       "Internal Sender Identifier",
 		Placeholder: "Internal Sender Identifier",
 	}
+	f.Fields["q"] = &Field{
+		Name:        "q",
+		Label:       "Search",
+		Placeholder: "Title, identifier, description, sender...",
+	}
 	f.Fields["size__gteq"] = &Field{
 		Name:        "size__gteq",
 		Label:       "Min Size",
@@ -166,6 +171,7 @@ func (f *ObjectFilterForm) SetValues() {
 	f.Fields["institution_id"].Value = f.FilterCollection.ValueOf("institution_id")
 	f.Fields["institution_parent_id"].Value = f.FilterCollection.ValueOf("institution_parent_id")
 	f.Fields["internal_sender_identifier"].Value = f.FilterCollection.ValueOf("internal_sender_identifier")
+	f.Fields["q"].Value = f.FilterCollection.ValueOf("q")
 
 	f.Fields["size__gteq"].Value = f.FilterCollection.ValueOf("size__gteq")
 	f.Fields["size__lteq"].Value = f.FilterCollection.ValueOf("size__lteq")
@@ -27,6 +27,7 @@ func getObjectFilters() *pgmodels.FilterCollection {
 	fc.Add("institution_id", []string{"2"})
 	fc.Add("institution__parent_id", []string{"3"})
 	fc.Add("internal_sender_identifier", []string{"8675309"})
+	fc.Add("q", []string{"photos"})
 	fc.Add("size__gteq", []string{"50"})
 	fc.Add("size__lteq", []string{"500"})
 	fc.Add("source_organization", []string{"test.edu"})
@@ -84,6 +85,7 @@ func testObjectFields(t *testing.T, fc *pgmodels.FilterCollection, fields map[st
 		//"institution_id", // admin only
 		"institution_parent_id",
 		"internal_sender_identifier",
+		"q",
 		"size__lteq",
 		"size__gteq",
 		"source_organization",
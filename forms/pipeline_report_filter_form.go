@@ -0,0 +1,62 @@
+package forms
+
+import (
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/pgmodels"
+)
+
+// PipelineReportFilterForm is the form that displays filtering options
+// for the pipeline throughput report. Only APTrust admins can see this
+// report.
+type PipelineReportFilterForm struct {
+	Form
+	FilterCollection *pgmodels.FilterCollection
+	instOptions      []*ListOption
+}
+
+func NewPipelineReportFilterForm(fc *pgmodels.FilterCollection, actingUser *pgmodels.User) (FilterForm, error) {
+	f := &PipelineReportFilterForm{
+		Form:             NewForm(nil, "reports/_pipeline_filters.html", "/reports/pipeline"),
+		FilterCollection: fc,
+	}
+	var err error
+	if actingUser.IsAdmin() {
+		f.instOptions, err = ListInstitutions(false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	f.init()
+	f.SetValues()
+	return f, nil
+}
+
+func (f *PipelineReportFilterForm) init() {
+	f.Fields["start_date"] = &Field{
+		Name:        "start_date",
+		Label:       "Processed On or After",
+		Placeholder: "Processed On or After",
+	}
+	f.Fields["end_date"] = &Field{
+		Name:        "end_date",
+		Label:       "Processed On or Before",
+		Placeholder: "Processed On or Before",
+	}
+	f.Fields["institution_id"] = &Field{
+		Name:        "institution_id",
+		Label:       "Institution",
+		Placeholder: "All Institutions",
+		Options:     f.instOptions,
+	}
+}
+
+// SetValues sets the form values to match the filter values.
+func (f *PipelineReportFilterForm) SetValues() {
+	for _, fieldName := range pgmodels.PipelineStatsFilters {
+		if f.Fields[fieldName] == nil {
+			common.ConsoleDebug("No filter for %s", fieldName)
+			continue
+		}
+		f.Fields[fieldName].Value = f.FilterCollection.ValueOf(fieldName)
+	}
+}
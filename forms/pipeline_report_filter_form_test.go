@@ -0,0 +1,29 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineReportFilterForm(t *testing.T) {
+	fc := pgmodels.NewFilterCollection()
+	fc.Add("start_date", []string{"2024-01-01"})
+	fc.Add("end_date", []string{"2024-01-31"})
+	fc.Add("institution_id", []string{"2"})
+
+	sysAdmin := testutil.InitUser(t, "system@aptrust.org")
+	form, err := forms.NewPipelineReportFilterForm(fc, sysAdmin)
+	require.Nil(t, err)
+	require.NotNil(t, form)
+
+	fields := form.GetFields()
+	assert.Equal(t, "2024-01-01", fields["start_date"].Value)
+	assert.Equal(t, "2024-01-31", fields["end_date"].Value)
+	assert.Equal(t, "2", fields["institution_id"].Value)
+	assert.True(t, len(fields["institution_id"].Options) > 1)
+}
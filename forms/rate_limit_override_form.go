@@ -0,0 +1,94 @@
+package forms
+
+import (
+	"fmt"
+
+	"github.com/APTrust/registry/pgmodels"
+)
+
+type RateLimitOverrideForm struct {
+	Form
+	instOptions []*ListOption
+	userOptions []*ListOption
+}
+
+func NewRateLimitOverrideForm(override *pgmodels.RateLimitOverride) (*RateLimitOverrideForm, error) {
+	overrideForm := &RateLimitOverrideForm{
+		Form: NewForm(override, "rate_limits/form.html", "/rate_limits"),
+	}
+	var err error
+	overrideForm.instOptions, err = ListInstitutions(false)
+	if err != nil {
+		return nil, err
+	}
+	overrideForm.userOptions, err = ListUsers(override.InstitutionID)
+	if err != nil {
+		return nil, err
+	}
+	overrideForm.init()
+	overrideForm.SetValues()
+	return overrideForm, nil
+}
+
+func (f *RateLimitOverrideForm) init() {
+	f.Fields["InstitutionID"] = &Field{
+		Name:    "InstitutionID",
+		Label:   "Institution",
+		ErrMsg:  pgmodels.ErrRateLimitInstID,
+		Options: f.instOptions,
+		Attrs: map[string]string{
+			"required": "",
+		},
+	}
+	f.Fields["UserID"] = &Field{
+		Name:    "UserID",
+		Label:   "User (choose Any to override the institution's shared limit)",
+		ErrMsg:  pgmodels.ErrRateLimitWrongInst,
+		Options: f.userOptions,
+		Attrs:   map[string]string{},
+	}
+	f.Fields["PerMinute"] = &Field{
+		Name:        "PerMinute",
+		Label:       "Requests per minute",
+		Placeholder: "600",
+		ErrMsg:      pgmodels.ErrRateLimitPerMinute,
+		Attrs: map[string]string{
+			"min": "0",
+		},
+	}
+	f.Fields["Burst"] = &Field{
+		Name:        "Burst",
+		Label:       "Burst",
+		Placeholder: "100",
+		ErrMsg:      pgmodels.ErrRateLimitBurst,
+		Attrs: map[string]string{
+			"min": "0",
+		},
+	}
+	f.Fields["Exempt"] = &Field{
+		Name:    "Exempt",
+		Label:   "Exempt from rate limits?",
+		ErrMsg:  "Please choose yes or no.",
+		Options: YesNoList,
+		Attrs: map[string]string{
+			"required": "",
+		},
+	}
+	f.Fields["Note"] = &Field{
+		Name:        "Note",
+		Label:       "Note",
+		Placeholder: "Why does this user or institution need a different limit?",
+		Attrs:       map[string]string{},
+	}
+}
+
+// SetValues sets the form values to match the RateLimitOverride values.
+func (f *RateLimitOverrideForm) SetValues() {
+	override := f.Model.(*pgmodels.RateLimitOverride)
+	f.Fields["InstitutionID"].Value = override.InstitutionID
+	f.Fields["UserID"].Value = override.UserID
+	f.Fields["PerMinute"].Value = override.PerMinute
+	f.Fields["Burst"].Value = override.Burst
+	f.Fields["Exempt"].Value = fmt.Sprintf("%t", override.Exempt)
+	f.Fields["Note"].Value = override.Note
+}
@@ -0,0 +1,44 @@
+package forms_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitOverrideForm(t *testing.T) {
+	user, err := pgmodels.UserByEmail("user@inst1.edu")
+	require.Nil(t, err)
+
+	override := &pgmodels.RateLimitOverride{
+		InstitutionID: user.InstitutionID,
+		UserID:        user.ID,
+		PerMinute:     60,
+		Burst:         10,
+	}
+	form, err := forms.NewRateLimitOverrideForm(override)
+	require.Nil(t, err)
+	require.NotNil(t, form)
+
+	assert.Equal(t, "/rate_limits/new", form.Action())
+	assert.Equal(t, user.InstitutionID, form.Fields["InstitutionID"].Value)
+	assert.Equal(t, user.ID, form.Fields["UserID"].Value)
+	assert.Equal(t, 60, form.Fields["PerMinute"].Value)
+	assert.Equal(t, 10, form.Fields["Burst"].Value)
+	assert.Equal(t, "false", form.Fields["Exempt"].Value)
+
+	// User list includes only users at the override's institution.
+	userOptions := form.Fields["UserID"].Options
+	require.NotEmpty(t, userOptions)
+	for _, option := range userOptions {
+		userID, err := strconv.ParseInt(option.Value, 10, 64)
+		require.Nil(t, err)
+		u, err := pgmodels.UserByID(userID)
+		require.Nil(t, err)
+		assert.Equal(t, user.InstitutionID, u.InstitutionID)
+	}
+}
@@ -0,0 +1,98 @@
+package forms
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/stew/slice"
+)
+
+type WebhookForm struct {
+	Form
+	instOptions []*ListOption
+}
+
+func NewWebhookForm(hook *pgmodels.Webhook, actingUser *pgmodels.User) (*WebhookForm, error) {
+	webhookForm := &WebhookForm{
+		Form: NewForm(hook, "webhooks/form.html", "/webhooks"),
+	}
+	var err error
+	if actingUser.IsAdmin() {
+		// SysAdmin can configure webhooks for any institution.
+		webhookForm.instOptions, err = ListInstitutions(false)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Inst admins can configure webhooks for their own institution only.
+		webhookForm.instOptions = []*ListOption{
+			{strconv.FormatInt(actingUser.InstitutionID, 10), actingUser.Institution.Name, false},
+		}
+		hook.InstitutionID = actingUser.InstitutionID
+	}
+	webhookForm.init()
+	webhookForm.SetValues()
+	return webhookForm, nil
+}
+
+func (f *WebhookForm) init() {
+	f.Fields["InstitutionID"] = &Field{
+		Name:    "InstitutionID",
+		Label:   "Institution",
+		ErrMsg:  pgmodels.ErrWebhookInstID,
+		Options: f.instOptions,
+		Attrs: map[string]string{
+			"required": "",
+		},
+	}
+	f.Fields["URL"] = &Field{
+		Name:        "URL",
+		Label:       "URL",
+		Placeholder: "https://example.edu/aptrust/webhook",
+		ErrMsg:      pgmodels.ErrWebhookURL,
+		Attrs: map[string]string{
+			"required": "",
+		},
+	}
+	f.Fields["Description"] = &Field{
+		Name:        "Description",
+		Label:       "Description (optional)",
+		Placeholder: "What does this endpoint do?",
+		Attrs:       map[string]string{},
+	}
+	f.Fields["EventTypes"] = &Field{
+		Name:    "EventTypes",
+		Label:   "Send these events",
+		ErrMsg:  pgmodels.ErrWebhookEventTypes,
+		Options: make([]*ListOption, len(WebhookEventTypeList)),
+		Attrs:   map[string]string{},
+	}
+	f.Fields["Enabled"] = &Field{
+		Name:    "Enabled",
+		Label:   "Enabled?",
+		ErrMsg:  "Please choose yes or no.",
+		Options: YesNoList,
+		Attrs: map[string]string{
+			"required": "",
+		},
+	}
+}
+
+// SetValues sets the form values to match the Webhook values.
+func (f *WebhookForm) SetValues() {
+	hook := f.Model.(*pgmodels.Webhook)
+	f.Fields["InstitutionID"].Value = hook.InstitutionID
+	f.Fields["URL"].Value = hook.URL
+	f.Fields["Description"].Value = hook.Description
+	f.Fields["Enabled"].Value = fmt.Sprintf("%t", hook.Enabled)
+
+	// Copy the options so we don't mark the shared list as selected.
+	for i, option := range WebhookEventTypeList {
+		f.Fields["EventTypes"].Options[i] = &ListOption{
+			Value:    option.Value,
+			Text:     option.Text,
+			Selected: slice.Contains(hook.EventTypes, option.Value),
+		}
+	}
+}
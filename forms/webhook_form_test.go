@@ -0,0 +1,42 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookForm(t *testing.T) {
+	admin, err := pgmodels.UserByEmail("admin@inst1.edu")
+	require.Nil(t, err)
+
+	hook := pgmodels.NewWebhook(admin.InstitutionID)
+	hook.URL = "https://example.com/hook"
+	hook.EventTypes = []string{constants.WebhookEventFixityFailed}
+	form, err := forms.NewWebhookForm(hook, admin)
+	require.Nil(t, err)
+	require.NotNil(t, form)
+
+	assert.Equal(t, "/webhooks/new", form.Action())
+	assert.Equal(t, hook.URL, form.Fields["URL"].Value)
+	assert.Equal(t, "true", form.Fields["Enabled"].Value)
+	assert.Equal(t, admin.InstitutionID, form.Fields["InstitutionID"].Value)
+
+	// Inst admin can choose only their own institution.
+	assert.Equal(t, 1, len(form.Fields["InstitutionID"].Options))
+
+	options := form.Fields["EventTypes"].Options
+	require.Equal(t, len(constants.WebhookEventTypes), len(options))
+	for _, option := range options {
+		assert.Equal(t, option.Value == constants.WebhookEventFixityFailed, option.Selected)
+	}
+
+	// Make sure we didn't alter the shared list.
+	for _, option := range forms.WebhookEventTypeList {
+		assert.False(t, option.Selected)
+	}
+}
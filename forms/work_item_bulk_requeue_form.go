@@ -0,0 +1,88 @@
+package forms
+
+import (
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/pgmodels"
+)
+
+// WorkItemBulkRequeueForm lets APTrust admins choose which WorkItems
+// to requeue in bulk, and the stage to which they should be requeued.
+type WorkItemBulkRequeueForm struct {
+	Form
+	FilterCollection *pgmodels.FilterCollection
+	instOptions      []*ListOption
+}
+
+func NewWorkItemBulkRequeueForm(fc *pgmodels.FilterCollection, actingUser *pgmodels.User) (FilterForm, error) {
+	f := &WorkItemBulkRequeueForm{
+		Form:             NewForm(nil, "work_items/bulk_requeue.html", "/work_items"),
+		FilterCollection: fc,
+	}
+	var err error
+	f.instOptions, err = ListInstitutions(false)
+	if err != nil {
+		return nil, err
+	}
+	f.init()
+	f.SetValues()
+	return f, nil
+}
+
+func (f *WorkItemBulkRequeueForm) init() {
+	f.Fields["action"] = &Field{
+		Name:        "action",
+		Label:       "Action",
+		Placeholder: "Action",
+		Options:     Options(constants.WorkItemActions),
+	}
+	f.Fields["date_processed__gteq"] = &Field{
+		Name:        "date_processed__gteq",
+		Label:       "Processed On or After",
+		Placeholder: "Processed On or After",
+	}
+	f.Fields["date_processed__lteq"] = &Field{
+		Name:        "date_processed__lteq",
+		Label:       "Processed On or Before",
+		Placeholder: "Processed On or Before",
+	}
+	f.Fields["institution_id"] = &Field{
+		Name:        "institution_id",
+		Label:       "Institution",
+		Placeholder: "Institution",
+		Options:     f.instOptions,
+	}
+	f.Fields["node"] = &Field{
+		Name:        "node",
+		Label:       "Worker Node",
+		Placeholder: "Worker Node",
+	}
+	f.Fields["stage"] = &Field{
+		Name:        "stage",
+		Label:       "Current Stage",
+		Placeholder: "Current Stage",
+		Options:     Options(constants.Stages),
+	}
+	f.Fields["status"] = &Field{
+		Name:        "status",
+		Label:       "Status",
+		Placeholder: "Status",
+		Options:     Options([]string{constants.StatusFailed, constants.StatusPending, constants.StatusStarted, constants.StatusSuspended}),
+	}
+	// This isn't a filter. It's the stage to requeue to.
+	f.Fields["target_stage"] = &Field{
+		Name:        "target_stage",
+		Label:       "Requeue To Stage",
+		Placeholder: "Current stage (ingest) or Requested (other)",
+		Options:     Options(constants.Stages),
+	}
+}
+
+// SetValues sets the form values to match the filter values.
+func (f *WorkItemBulkRequeueForm) SetValues() {
+	for _, fieldName := range pgmodels.WorkItemBulkRequeueFilters {
+		if f.Fields[fieldName] == nil {
+			continue
+		}
+		f.Fields[fieldName].Value = f.FilterCollection.ValueOf(fieldName)
+	}
+}
@@ -0,0 +1,34 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkItemBulkRequeueForm(t *testing.T) {
+	sysAdmin := testutil.InitUser(t, "system@aptrust.org")
+	fc := pgmodels.NewFilterCollection()
+	fc.Add("action", []string{constants.ActionIngest})
+	fc.Add("date_processed__gteq", []string{"2021-01-01"})
+	fc.Add("date_processed__lteq", []string{"2021-12-31"})
+	fc.Add("institution_id", []string{"2"})
+	fc.Add("node", []string{"worker-1"})
+	fc.Add("stage", []string{constants.StageStore})
+	fc.Add("status", []string{constants.StatusFailed})
+
+	form, err := forms.NewWorkItemBulkRequeueForm(fc, sysAdmin)
+	require.Nil(t, err)
+	fields := form.GetFields()
+	for _, name := range []string{"action", "date_processed__gteq", "date_processed__lteq", "institution_id", "node", "stage", "status"} {
+		assert.Equal(t, fc.ValueOf(name), fields[name].Value, name)
+	}
+	assert.True(t, len(fields["institution_id"].Options) > 1)
+	assert.Equal(t, len(constants.Stages), len(fields["target_stage"].Options))
+	assert.Empty(t, fields["target_stage"].Value)
+}
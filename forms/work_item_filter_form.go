@@ -111,6 +111,11 @@ func (f *WorkItemFilterForm) init() {
 		Placeholder: "Needs Admin Review",
 		Options:     YesNoList,
 	}
+	f.Fields["node"] = &Field{
+		Name:        "node",
+		Label:       "Worker Node",
+		Placeholder: "Worker Node",
+	}
 	f.Fields["node__not_null"] = &Field{
 		Name:        "node__not_null",
 		Label:       "Has Worker",
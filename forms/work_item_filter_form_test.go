@@ -27,6 +27,7 @@ func getWorkItemFilters() *pgmodels.FilterCollection {
 	fc.Add("institution_id", []string{"3"})
 	fc.Add("name", []string{"barney"})
 	fc.Add("needs_admin_review", []string{"true"})
+	fc.Add("node", []string{"worker-1"})
 	fc.Add("node__not_null", []string{"true"})
 	fc.Add("object_identifier", []string{"test.edu/bag"})
 	fc.Add("size__gteq", []string{"800"})
@@ -96,6 +97,7 @@ func testWorkItemFields(t *testing.T, fc *pgmodels.FilterCollection, fields map[
 		// "institution_id", --> sys admin only
 		"name",
 		"needs_admin_review",
+		"node",
 		"node__not_null",
 		"object_identifier",
 		"size__gteq",
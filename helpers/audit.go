@@ -0,0 +1,24 @@
+package helpers
+
+import (
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditActor returns an AuditActor describing the current request, so
+// handlers can pass it to pgmodels.AuditChangesBy. The audit log then
+// records which user made the change, from which IP address, and
+// through which route. For requests with no logged-in user, such as
+// password resets, it records IP address and route only.
+func AuditActor(c *gin.Context) *pgmodels.AuditActor {
+	actor := &pgmodels.AuditActor{
+		RemoteAddr: c.ClientIP(),
+		Method:     c.Request.Method,
+		Route:      c.Request.URL.Path,
+	}
+	if user := CurrentUser(c); user != nil {
+		actor.UserID = user.ID
+		actor.UserEmail = user.Email
+	}
+	return actor
+}
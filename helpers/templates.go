@@ -142,6 +142,12 @@ func HumanSize(size int64) string {
 	return common.ToHumanSize(size, 1024)
 }
 
+// HumanDuration returns a number of seconds in a human-readable
+// format, rounded to the nearest second. 5400.2 becomes 1h30m0s.
+func HumanDuration(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second).String()
+}
+
 // IconFor returns a FontAwesome icon for the specified string, as defined
 // in helpers.IconMap. If the IconMap has no entry for the string, this
 // returns helpers.IconMissing.
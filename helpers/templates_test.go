@@ -123,6 +123,13 @@ func TestHumanSize(t *testing.T) {
 	assert.Equal(t, "2.0 TB", helpers.HumanSize(2*1024*1024*1024*1024))
 }
 
+func TestHumanDuration(t *testing.T) {
+	assert.Equal(t, "0s", helpers.HumanDuration(0))
+	assert.Equal(t, "45s", helpers.HumanDuration(44.6))
+	assert.Equal(t, "1h30m0s", helpers.HumanDuration(5400.2))
+	assert.Equal(t, "50h0m0s", helpers.HumanDuration(180000))
+}
+
 func TestIconFor(t *testing.T) {
 	// Should return item defined in map
 	assert.Equal(
@@ -107,7 +107,17 @@ func GetUserFromAPIHeaders(c *gin.Context) (user *pgmodels.User, err error) {
 		ctx.Log.Error().Msgf("GetUserFromAPIHeaders: Attempt to look up user %s failed with error %v", apiUserEmail, err)
 		return nil, err
 	}
-	if common.ComparePasswords(user.EncryptedAPISecretKey, apiUserKey) {
+	apiKey, isKeyFormat, err := pgmodels.APIKeyForUser(user.ID, apiUserKey)
+	if isKeyFormat {
+		if err == nil && apiKeyIsValid(c, apiKey, apiUserKey) {
+			c.Set("UserIsApiAuthenticated", true)
+			c.Set("APIKey", apiKey)
+			if err = apiKey.RecordUse(c.ClientIP()); err != nil {
+				ctx.Log.Error().Msgf("GetUserFromAPIHeaders: Could not record use of API key %d: %v", apiKey.ID, err)
+			}
+			return user, nil
+		}
+	} else if common.ComparePasswords(user.EncryptedAPISecretKey, apiUserKey) {
 		// Set this because API requests bypass CSRF protection and
 		// we want to ensure user passed valid auth headers. This
 		// prevents a CSRF hijack where bad actor sends XHR PUT/POST
@@ -122,6 +132,24 @@ func GetUserFromAPIHeaders(c *gin.Context) (user *pgmodels.User, err error) {
 	return nil, common.ErrInvalidAPICredentials
 }
 
+// apiKeyIsValid returns true if plaintextKey matches apiKey and apiKey
+// is active and may be used from the client's IP address.
+func apiKeyIsValid(c *gin.Context, apiKey *pgmodels.APIKey, plaintextKey string) bool {
+	log := common.Context().Log
+	if !apiKey.Matches(plaintextKey) {
+		return false
+	}
+	if !apiKey.IsActive() {
+		log.Warn().Msgf("Attempt to use revoked or expired API key %d (user %d) from %s.", apiKey.ID, apiKey.UserID, c.ClientIP())
+		return false
+	}
+	if !apiKey.AllowsIP(c.ClientIP()) {
+		log.Warn().Msgf("Attempt to use API key %d (user %d) from disallowed IP %s.", apiKey.ID, apiKey.UserID, c.ClientIP())
+		return false
+	}
+	return true
+}
+
 // LoadCookies loads the user's flash and preference cookes into
 // the request context.
 func LoadCookies(c *gin.Context) error {
@@ -158,7 +186,7 @@ func LoadCookie(c *gin.Context, name string) error {
 // sensitive info. All other resources must use no-cache/no-store.
 func SetDefaultHeaders(c *gin.Context) {
 	p := c.FullPath()
-	if !strings.HasPrefix(p, "/static") && !strings.HasPrefix(p, "/favicon") {
+	if !strings.HasPrefix(p, "/static") && !strings.HasPrefix(p, "/favicon") && !strings.HasPrefix(p, "/swagger") {
 		c.Writer.Header().Set("Cache-Control", "no-cache")
 		c.Writer.Header().Set("Pragma", "no-store")
 	}
@@ -175,6 +203,11 @@ func SetDefaultHeaders(c *gin.Context) {
 	c.Writer.Header().Set("X-XSS-Protection", "1")
 	c.Writer.Header().Set("X-Content-Type-Options", "nosniff")
 	c.Writer.Header().Set("Content-Security-Policy", "default-src 'self'; font-src 'self' fonts.gstatic.com; style-src 'self' 'unsafe-inline' fonts.googleapis.com; script-src 'self' 'unsafe-inline'")
+
+	// The swagger UI draws its icons with inline data URIs.
+	if strings.HasPrefix(p, "/swagger") {
+		c.Writer.Header().Set("Content-Security-Policy", "default-src 'self'; img-src 'self' data:; style-src 'self' 'unsafe-inline'; script-src 'self' 'unsafe-inline'")
+	}
 }
 
 func forceCompletionOfPasswordChange(c *gin.Context, currentUser *pgmodels.User) bool {
@@ -240,6 +273,7 @@ func ExemptFromAuth(c *gin.Context) bool {
 		p == "/accessibility_statement" ||
 		strings.HasPrefix(p, "/static") ||
 		strings.HasPrefix(p, "/favicon") ||
+		strings.HasPrefix(p, "/swagger") ||
 		strings.HasPrefix(p, "/error") ||
 		strings.HasPrefix(p, "/users/complete_password_reset/")
 }
@@ -35,6 +35,10 @@ type AuthMetadata struct {
 // requests that hit an unguarded route will return  an internal server
 // error.
 var AuthMap = map[string]AuthMetadata{
+	"APIKeyCreate":                      {"APIKey", constants.UserUpdateSelf, "Create API Key"},
+	"APIKeyIndex":                       {"APIKey", constants.UserUpdateSelf, "API Keys"},
+	"APIKeyNew":                         {"APIKey", constants.UserUpdateSelf, "New API Key"},
+	"APIKeyRevoke":                      {"APIKey", constants.UserUpdateSelf, "Revoke API Key"},
 	"AlertCreate":                       {"Alert", constants.AlertCreate, "Create Alert"},
 	"AlertDelete":                       {"Alert", constants.AlertDelete, "Delete Alert"},
 	"AlertIndex":                        {"Alert", constants.AlertRead, "Alerts"},
@@ -44,6 +48,10 @@ var AuthMap = map[string]AuthMetadata{
 	"AlertMarkAsReadXHR":                {"Alert", constants.AlertUpdate, "Mark Alert as Read"},
 	"AlertMarkAllAsRead":                {"Alert", constants.AlertUpdate, "Mark All Alerts as Read"},
 	"AlertMarkAsUnreadXHR":              {"Alert", constants.AlertUpdate, "Mark Alert as Unread"},
+	"AuditLogIndex":                     {"AuditLog", constants.AuditLogRead, "Audit Log"},
+	"AuditLogShow":                      {"AuditLog", constants.AuditLogRead, "Audit Log Entry"},
+	"BagGroupIndex":                     {"BagGroup", constants.IntellectualObjectRead, "Bag Groups"},
+	"BagGroupShow":                      {"BagGroup", constants.IntellectualObjectRead, "Bag Group Detail"},
 	"BillingReportShow":                 {"DepositStats", constants.BillingReportShow, "Billing Report"},
 	"ChecksumCreate":                    {"Checksum", constants.ChecksumCreate, "Create Checksum"},
 	"ChecksumDelete":                    {"Checksum", constants.ChecksumDelete, "Delete Checksum"},
@@ -60,16 +68,20 @@ var AuthMap = map[string]AuthMetadata{
 	"DepositReportShow":                 {"DepositStats", constants.DepositReportShow, "Deposit Report"},
 	"GenerateFailedFixityAlerts":        {"Alert", constants.GenerateFailedFixityAlert, "Generate Failed Fixity Check"},
 	"GenericFileCreate":                 {"GenericFile", constants.FileCreate, "Create Generic File"},
+	"GenericFileChanges":                {"GenericFile", constants.FileRead, "Changed Files"},
 	"GenericFileCreateBatch":            {"GenericFile", constants.FileCreate, "Create Generic File Batch"},
 	"GenericFileDelete":                 {"GenericFile", constants.FileDelete, "Delete Generic File"},
 	"GenericFileFinishBulkDelete":       {"GenericFile", constants.FileFinishBulkDelete, "Generic File Bulk Deletion Complete"},
 	"GenericFileIndex":                  {"GenericFile", constants.FileRead, "Generic Files"},
 	"GenericFileInitDelete":             {"GenericFile", constants.FileRequestDelete, "Generic File - Begin Deletion"},
 	"GenericFileInitRestore":            {"GenericFile", constants.FileRestore, "Generic File - Begin Restoration"},
+	"GenericFileManifest":               {"GenericFile", constants.FileRead, "Generic File Manifest"},
 	"GenericFileNew":                    {"GenericFile", constants.FileCreate, "New Generic File"},
 	"GenericFileRequestDelete":          {"GenericFile", constants.FileRequestDelete, "Generic File - Request Deletion"},
 	"GenericFileRequestRestore":         {"GenericFile", constants.FileRestore, "Generic File - Request Restoration"},
+	"GenericFileRestore":                {"GenericFile", constants.FileRestore, "Restore Generic File"},
 	"GenericFileShow":                   {"GenericFile", constants.FileRead, "Generic File Detail"},
+	"GenericFileUndelete":               {"GenericFile", constants.FileUndelete, "Undelete Generic File"},
 	"GenericFileUpdate":                 {"GenericFile", constants.FileUpdate, "Update Generic File"},
 	"InstitutionCreate":                 {"Institution", constants.InstitutionCreate, "Create Institution"},
 	"InstitutionDelete":                 {"Institution", constants.InstitutionDelete, "Deactivate Institution"},
@@ -82,6 +94,7 @@ var AuthMap = map[string]AuthMetadata{
 	"InstitutionUpdate":                 {"Institution", constants.InstitutionUpdate, "Update Institution"},
 	"InstitutionUpdatePrefs":            {"Institution", constants.InstitutionUpdatePrefs, "Update Institution Preferences"},
 	"IntellectualObjectInitBatchDelete": {"IntellectualObject", constants.IntellectualObjectBatchDelete, "Intellectual Object Batch Delete"},
+	"IntellectualObjectChanges":         {"IntellectualObject", constants.IntellectualObjectRead, "Changed Intellectual Objects"},
 	"IntellectualObjectCreate":          {"IntellectualObject", constants.IntellectualObjectCreate, "Create Intellectual Object"},
 	"IntellectualObjectDelete":          {"IntellectualObject", constants.IntellectualObjectDelete, "Delete Intellectual Object"},
 	"IntellectualObjectEvents":          {"PremisEvent", constants.EventRead, "PREMIS Events"},
@@ -93,21 +106,45 @@ var AuthMap = map[string]AuthMetadata{
 	"IntellectualObjectIndex":            {"IntellectualObject", constants.IntellectualObjectRead, "Intellectual Objects"},
 	"IntellectualObjectInitDelete":       {"IntellectualObject", constants.IntellectualObjectRequestDelete, "Initialize Object Deletion"},
 	"IntellectualObjectInitRestore":      {"IntellectualObject", constants.IntellectualObjectRestore, "Initialize Object Restoration"},
+	"IntellectualObjectManifest":         {"IntellectualObject", constants.FileRead, "Object File Manifest"},
 	"IntellectualObjectNew":              {"IntellectualObject", constants.IntellectualObjectCreate, "New Intellectual Object"},
 	"IntellectualObjectRequestDelete":    {"IntellectualObject", constants.IntellectualObjectRequestDelete, "Request Object Deletion"},
 	"IntellectualObjectRequestRestore":   {"IntellectualObject", constants.IntellectualObjectRestore, "Request Object Restoration"},
+	"IntellectualObjectRestore":          {"IntellectualObject", constants.IntellectualObjectRestore, "Restore Intellectual Object"},
 	"IntellectualObjectShow":             {"IntellectualObject", constants.IntellectualObjectRead, "Intellectual Object Detail"},
+	"IntellectualObjectUndelete":         {"IntellectualObject", constants.IntellectualObjectUndelete, "Undelete Intellectual Object"},
 	"IntellectualObjectUpdate":           {"IntellectualObject", constants.IntellectualObjectUpdate, "Update Intellectual Object"},
 	"InternalMetadataIndex":              {"InternalMetadata", constants.InternalMetadataRead, "Internal Metadata"},
+	"LegalHoldCreate":                    {"LegalHold", constants.LegalHoldCreate, "Place Legal Hold"},
+	"LegalHoldIndex":                     {"LegalHold", constants.LegalHoldRead, "Legal Holds"},
+	"LegalHoldNew":                       {"LegalHold", constants.LegalHoldCreate, "Place Legal Hold"},
+	"LegalHoldRelease":                   {"LegalHold", constants.LegalHoldRelease, "Release Legal Hold"},
+	"LegalHoldShow":                      {"LegalHold", constants.LegalHoldRead, "Legal Hold"},
+	"NSQPauseWindowCancel":               {"NSQPauseWindow", constants.NsqAdmin, "Cancel NSQ Pause"},
+	"NSQPauseWindowCreate":               {"NSQPauseWindow", constants.NsqAdmin, "Schedule NSQ Pause"},
+	"NSQPauseWindowIndex":                {"NSQPauseWindow", constants.NsqAdmin, "NSQ Pause Windows"},
+	"NSQPauseWindowNew":                  {"NSQPauseWindow", constants.NsqAdmin, "Schedule NSQ Pause"},
 	"NsqShow":                            {"NSQ", constants.NsqAdmin, "NSQ Dashboard"},
 	"NsqAdmin":                           {"NSQ", constants.NsqAdmin, "NSQ Admin"},
 	"NsqInit":                            {"NSQ", constants.NsqAdmin, "NSQ"},
+	"ObjectVersionDiff":                  {"ObjectVersion", constants.IntellectualObjectRead, "Compare Object Versions"},
+	"ObjectVersionIndex":                 {"ObjectVersion", constants.IntellectualObjectRead, "Object Versions"},
+	"ObjectVersionShow":                  {"ObjectVersion", constants.IntellectualObjectRead, "Object Version Detail"},
+	"OpenAPISpec":                        {"OpenAPISpec", constants.APISpecRead, "API Spec"},
+	"PipelineReportShow":                 {"PipelineStats", constants.PipelineReportShow, "Pipeline Report"},
+	"PremisEventChanges":                 {"PremisEvent", constants.EventRead, "Changed PREMIS Events"},
 	"PremisEventCreate":                  {"PremisEvent", constants.EventCreate, "Create PREMIS Event"},
 	"PremisEventIndex":                   {"PremisEvent", constants.EventRead, "PREMIS Events"},
 	"PremisEventShow":                    {"PremisEvent", constants.EventRead, "PREMIS Event Detail"},
 	"PremisEventShowXHR":                 {"PremisEvent", constants.EventRead, "PREMIS Event Detail"},
 	"PrepareFileDelete":                  {"GenericFile", constants.PrepareFileDelete, "Prepare File Deletion"},
 	"PrepareObjectDelete":                {"IntellectualObject", constants.PrepareObjectDelete, "Prepare Object Deletion"},
+	"RateLimitIndex":                     {"RateLimitOverride", constants.RateLimitRead, "Rate Limits"},
+	"RateLimitOverrideCreate":            {"RateLimitOverride", constants.RateLimitUpdate, "Create Rate Limit Override"},
+	"RateLimitOverrideDelete":            {"RateLimitOverride", constants.RateLimitUpdate, "Delete Rate Limit Override"},
+	"RateLimitOverrideEdit":              {"RateLimitOverride", constants.RateLimitUpdate, "Edit Rate Limit Override"},
+	"RateLimitOverrideNew":               {"RateLimitOverride", constants.RateLimitUpdate, "New Rate Limit Override"},
+	"RateLimitOverrideUpdate":            {"RateLimitOverride", constants.RateLimitUpdate, "Update Rate Limit Override"},
 	"StorageRecordCreate":                {"StorageRecord", constants.StorageRecordCreate, "Create Storage Record"},
 	"StorageRecordDelete":                {"StorageRecord", constants.StorageRecordDelete, "Delete Storage Record"},
 	"StorageRecordIndex":                 {"StorageRecord", constants.StorageRecordRead, "Storage Records"},
@@ -141,15 +178,31 @@ var AuthMap = map[string]AuthMetadata{
 	"UserUpdate":                         {"User", constants.UserUpdate, "Update User"},
 	"UserUpdateXHR":                      {"User", constants.UserUpdate, "Update User"},
 	"UserUpdateSelf":                     {"User", constants.UserUpdateSelf, "Update User"},
+	"WebhookCreate":                      {"Webhook", constants.WebhookCreate, "Create Webhook"},
+	"WebhookDelete":                      {"Webhook", constants.WebhookDelete, "Delete Webhook"},
+	"WebhookDeliveryShow":                {"WebhookDelivery", constants.WebhookRead, "Webhook Delivery"},
+	"WebhookEdit":                        {"Webhook", constants.WebhookUpdate, "Edit Webhook"},
+	"WebhookIndex":                       {"Webhook", constants.WebhookRead, "Webhooks"},
+	"WebhookNew":                         {"Webhook", constants.WebhookCreate, "New Webhook"},
+	"WebhookShow":                        {"Webhook", constants.WebhookRead, "Webhook"},
+	"WebhookTest":                        {"Webhook", constants.WebhookUpdate, "Send Test Event"},
+	"WebhookUpdate":                      {"Webhook", constants.WebhookUpdate, "Update Webhook"},
+	"WorkItemBulkRequeue":                {"WorkItem", constants.WorkItemRequeue, "Bulk Requeue Work Items"},
+	"WorkItemBulkRequeuePreview":         {"WorkItem", constants.WorkItemRequeue, "Bulk Requeue Work Items"},
 	"WorkItemCreate":                     {"WorkItem", constants.WorkItemCreate, "Create Work Item"},
 	"WorkItemDelete":                     {"WorkItem", constants.WorkItemDelete, "Delete Work Item"},
 	"WorkItemEdit":                       {"WorkItem", constants.WorkItemUpdate, "Edit Work Item"},
+	"WorkItemHistory":                    {"WorkItem", constants.WorkItemRead, "Work Item History"},
 	"WorkItemIndex":                      {"WorkItem", constants.WorkItemRead, "Work Items"},
 	"WorkItemNew":                        {"WorkItem", constants.WorkItemCreate, "New Work Item"},
+	"WorkItemReconcile":                  {"WorkItem", constants.WorkItemRequeue, "Reconcile Work Item"},
+	"WorkItemReconcileShow":              {"WorkItem", constants.WorkItemRequeue, "Reconcile Work Items"},
 	"WorkItemRedisDelete":                {"WorkItem", constants.WorkItemRedisDelete, "Delete Redis Data"},
 	"WorkItemRedisIndex":                 {"WorkItem", constants.RedisList, "Redis Data"},
+	"WorkItemRedisShow":                  {"WorkItem", constants.RedisRead, "Redis State"},
 	"WorkItemRequeue":                    {"WorkItem", constants.WorkItemRequeue, "Requeue Work Item"},
 	"WorkItemShow":                       {"WorkItem", constants.WorkItemRead, "Work Item Detail"},
+	"WorkItemStageTiming":                {"WorkItem", constants.WorkItemRead, "Work Item Stage Timing"},
 	"WorkItemShowRequeue":                {"WorkItem", constants.WorkItemRequeue, "Requeue Work Item"},
 	"WorkItemUpdate":                     {"WorkItem", constants.WorkItemUpdate, "Update Work Item"},
 }
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit limits the number of API requests each user and each
+// institution can make, so that one misbehaving client can't tie up
+// all of our database connections. It takes one token from the user's
+// bucket and one from the institution's bucket on each API request.
+// If either bucket is empty, it responds with 429/Too Many Requests
+// and a Retry-After header.
+//
+// Responses to API requests include X-RateLimit-Limit,
+// X-RateLimit-Remaining and X-RateLimit-Reset headers describing the
+// more restrictive of the two buckets. Reset is the number of seconds
+// until that bucket is full again.
+//
+// This applies only to authenticated API requests. Web UI requests
+// and users with an exempt RateLimitOverride are not limited.
+func RateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := common.Context()
+		if !ctx.Config.RateLimit.Enabled || !IsAPIRoute(c) {
+			c.Next()
+			return
+		}
+		user := currentUser(c)
+		if user == nil {
+			c.Next()
+			return
+		}
+		checks, err := RateLimitChecks(user)
+		if err != nil {
+			// Don't lock users out because we can't read overrides.
+			ctx.Log.Error().Msgf("RateLimit: Error loading rate limit overrides for user %d: %v", user.ID, err)
+			c.Next()
+			return
+		}
+		result := ctx.RateLimiter.Allow(checks...)
+		if result.Limit > 0 {
+			c.Header(constants.RateLimitLimitHeader, strconv.Itoa(result.Limit))
+			c.Header(constants.RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+			c.Header(constants.RateLimitResetHeader, strconv.Itoa(ceilSeconds(result.Reset)))
+		}
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			ctx.Log.Warn().Msgf("RateLimit: Refused %s %s from user %s. Retry after %d seconds.", c.Request.Method, c.Request.URL.Path, user.Email, retryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, map[string]interface{}{
+				"StatusCode": http.StatusTooManyRequests,
+				"Error":      fmt.Sprintf("Rate limit exceeded. Please retry after %d seconds.", retryAfter),
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimitChecks returns the user and institution buckets that
+// apply to requests from user, with the limits from the config
+// file or from any admin overrides.
+func RateLimitChecks(user *pgmodels.User) ([]common.RateLimitCheck, error) {
+	config := common.Context().Config.RateLimit
+	userLimit := common.RateLimit{PerMinute: config.UserPerMinute, Burst: config.UserBurst}
+	instLimit := common.RateLimit{PerMinute: config.InstitutionPerMinute, Burst: config.InstitutionBurst}
+	userOverride, instOverride, err := pgmodels.RateLimitOverridesFor(user.ID, user.InstitutionID)
+	if err != nil {
+		return nil, err
+	}
+	if instOverride != nil {
+		instLimit = instOverride.RateLimit()
+	}
+	if userOverride != nil {
+		userLimit = userOverride.RateLimit()
+		if userOverride.Exempt {
+			// Exempt users don't count against their
+			// institution's limit either.
+			instLimit = common.RateLimit{}
+		}
+	}
+	instName := strconv.FormatInt(user.InstitutionID, 10)
+	if user.Institution != nil {
+		instName = user.Institution.Name
+	}
+	return []common.RateLimitCheck{
+		{Key: fmt.Sprintf("user:%d", user.ID), Name: user.Email, Limit: userLimit},
+		{Key: fmt.Sprintf("institution:%d", user.InstitutionID), Name: instName, Limit: instLimit},
+	}, nil
+}
+
+func currentUser(c *gin.Context) *pgmodels.User {
+	if user, ok := c.Get("CurrentUser"); ok && user != nil {
+		return user.(*pgmodels.User)
+	}
+	return nil
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
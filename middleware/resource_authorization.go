@@ -56,6 +56,14 @@ func (r *ResourceAuthorization) init() {
 		r.Error = common.ErrWrongAPI
 		return
 	}
+	if r.ReadOnlyAPIKeyIsRequestingWrite() {
+		r.Handler = "APIKeyScope"
+		r.ResourceType = "Forbidden"
+		r.Checked = true
+		r.Approved = false
+		r.Error = common.ErrAPIKeyReadOnly
+		return
+	}
 	r.getPermissionType()
 	if r.Error == nil {
 		r.readRequestIds()
@@ -202,6 +210,17 @@ func (r *ResourceAuthorization) NonAdminIsRequestingAdminAPI() bool {
 	return strings.HasPrefix(r.ginCtx.Request.URL.Path, constants.APIPrefixAdmin) && (currentUser == nil || !currentUser.IsAdmin())
 }
 
+// ReadOnlyAPIKeyIsRequestingWrite returns true if the user authenticated
+// with a read-only API key and is trying to do anything other than
+// read data.
+func (r *ResourceAuthorization) ReadOnlyAPIKeyIsRequestingWrite() bool {
+	apiKey, ok := r.ginCtx.Get("APIKey")
+	if !ok || apiKey == nil {
+		return false
+	}
+	return !apiKey.(*pgmodels.APIKey).AllowsMethod(r.ginCtx.Request.Method)
+}
+
 // String returns this object in string format, suitable for debugging.
 func (r *ResourceAuthorization) String() string {
 	user, exists := r.ginCtx.Get("CurrentUser")
@@ -126,6 +126,25 @@ func (data *NSQStatsData) GetChannel(topicName, channelName string) *NSQChannelS
 	return nil
 }
 
+// TopicIsEmpty returns true if the named topic and all of its channels
+// have no queued, in-flight or deferred messages. Topics that don't
+// exist are empty.
+func (data *NSQStatsData) TopicIsEmpty(name string) bool {
+	topic := data.GetTopic(name)
+	if topic == nil {
+		return true
+	}
+	if topic.Depth > 0 {
+		return false
+	}
+	for _, channel := range topic.Channels {
+		if channel.Depth > 0 || channel.InFlightCount > 0 || channel.DeferredCount > 0 {
+			return false
+		}
+	}
+	return true
+}
+
 func (data *NSQStatsData) ClientIsRunning(hostname string) bool {
 	for _, topic := range data.Topics {
 		for _, channel := range topic.Channels {
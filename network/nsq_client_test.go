@@ -229,3 +229,21 @@ func testEmptyAll(t *testing.T, client *network.NSQClient) {
 		}
 	}
 }
+
+func TestNSQTopicIsEmpty(t *testing.T) {
+	stats := &network.NSQStatsData{
+		Topics: []network.NSQTopicStats{
+			{TopicName: "empty", Channels: []network.NSQChannelStats{{ChannelName: "c"}}},
+			{TopicName: "queued", Depth: 3},
+			{TopicName: "channel_queued", Channels: []network.NSQChannelStats{{ChannelName: "c", Depth: 1}}},
+			{TopicName: "in_flight", Channels: []network.NSQChannelStats{{ChannelName: "c", InFlightCount: 1}}},
+			{TopicName: "deferred", Channels: []network.NSQChannelStats{{ChannelName: "c", DeferredCount: 2}}},
+		},
+	}
+	assert.True(t, stats.TopicIsEmpty("empty"))
+	assert.True(t, stats.TopicIsEmpty("does_not_exist"))
+	assert.False(t, stats.TopicIsEmpty("queued"))
+	assert.False(t, stats.TopicIsEmpty("channel_queued"))
+	assert.False(t, stats.TopicIsEmpty("in_flight"))
+	assert.False(t, stats.TopicIsEmpty("deferred"))
+}
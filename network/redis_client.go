@@ -132,6 +132,40 @@ func (c *RedisClient) RestorationObjectGet(workItemID int64, objIdentifier strin
 	return string(data), nil
 }
 
+// WorkItemState returns a typed view of everything the workers have
+// stored in Redis for the specified WorkItem. Unlike the other getters,
+// this doesn't need to know the object identifier, because it reads all
+// of the item's hash fields. See RedisWorkItemState for how we handle
+// data we don't recognize.
+//
+// This scans the hash in batches instead of loading it all at once,
+// and keeps only the page of files selected by fileFilter, offset and
+// limit. See NewRedisWorkItemState for what those params mean. HSCAN
+// may return a field twice if the workers update the hash while we're
+// scanning it, so counts may be slightly off while an item is being
+// processed.
+func (c *RedisClient) WorkItemState(workItemID int64, fileFilter string, offset, limit int) (*RedisWorkItemState, error) {
+	key := strconv.FormatInt(workItemID, 10)
+	state := newRedisWorkItemState(workItemID, fileFilter, offset, limit)
+	var cursor uint64
+	for {
+		fields, nextCursor, err := c.client.HScan(key, cursor, "", 500).Result()
+		if err != nil {
+			return nil, fmt.Errorf("WorkItemState (%d): %s", workItemID, err.Error())
+		}
+		// HSCAN returns field names and values, one after the other.
+		for i := 0; i+1 < len(fields); i += 2 {
+			state.add(fields[i], fields[i+1])
+		}
+		cursor = nextCursor
+		if cursor == 0 {
+			break
+		}
+	}
+	state.finish()
+	return state, nil
+}
+
 // WorkItemDelete deletes the Redis copy (NOT the Registry copy) of a WorkItem,
 // along with its associated IngestObject and IngestFile records.
 // This is dangerous and should be called only in two cases:
@@ -166,3 +200,24 @@ func (c *RedisClient) List(pattern string) ([]string, error) {
 	keys, _, err := c.client.Scan(0, pattern, 500).Result()
 	return keys, err
 }
+
+// Keys returns all keys in the Redis DB matching the specified pattern.
+// Unlike List, this follows the scan cursor until Redis has returned
+// every match, so use it when missing a key would give a wrong answer,
+// as in WorkItem reconciliation. Each key is a WorkItem.ID in string
+// form.
+func (c *RedisClient) Keys(pattern string) ([]string, error) {
+	keys := make([]string, 0)
+	var cursor uint64
+	for {
+		batch, nextCursor, err := c.client.Scan(cursor, pattern, 500).Result()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, batch...)
+		cursor = nextCursor
+		if cursor == 0 {
+			return keys, nil
+		}
+	}
+}
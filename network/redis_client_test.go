@@ -181,3 +181,71 @@ func TestRedisList(t *testing.T) {
 	assert.Contains(t, keys, strconv.FormatInt(redisIngestItemID, 10))
 	assert.Contains(t, keys, strconv.FormatInt(redisRestoreItemID, 10))
 }
+
+func TestRedisKeys(t *testing.T) {
+	client := getRedisClient()
+	assert.NotNil(t, client)
+
+	// Create more keys than a single scan returns, so Keys has to
+	// follow the cursor to find them all.
+	firstID := int64(700000)
+	expected := make(map[string]bool)
+	for id := firstID; id < firstID+1200; id++ {
+		require.Nil(t, client.SaveItem(id, "object:test.edu/bag", "{}"))
+		expected[strconv.FormatInt(id, 10)] = true
+	}
+	defer func() {
+		for id := firstID; id < firstID+1200; id++ {
+			client.WorkItemDelete(id)
+		}
+	}()
+
+	keys, err := client.Keys("7?????")
+	require.Nil(t, err)
+	found := 0
+	for _, key := range keys {
+		if expected[key] {
+			found++
+		}
+	}
+	assert.Equal(t, len(expected), found)
+}
+
+func TestRedisWorkItemState(t *testing.T) {
+	client := getRedisClient()
+	assert.NotNil(t, client)
+	createRedisIngestObject(t, client)
+
+	// The test fixtures don't look like real ingest data, so they
+	// should all come back as raw JSON.
+	state, err := client.WorkItemState(redisIngestItemID, network.RedisFileFilterAll, 0, 20)
+	require.Nil(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, redisIngestItemID, state.WorkItemID)
+	assert.Nil(t, state.IngestObject)
+	assert.Empty(t, state.Files)
+	assert.Empty(t, state.WorkResults)
+	assert.Equal(t, 10, len(state.Unknown))
+	assert.JSONEq(t, `{"key1":"value1"}`, string(state.Unknown["object:"+redisObjIdentifier]))
+
+	// Large hashes take more than one scan. We get the summary for all
+	// files, but only the page of files we asked for.
+	fileItemID := int64(5310)
+	for i := 0; i < 1200; i++ {
+		path := fmt.Sprintf("data/file_%04d.txt", i)
+		require.Nil(t, client.SaveItem(fileItemID, "file:test.edu/bag/"+path, fmt.Sprintf(`{"path_in_bag":"%s"}`, path)))
+	}
+	defer client.WorkItemDelete(fileItemID)
+	state, err = client.WorkItemState(fileItemID, network.RedisFileFilterAll, 600, 50)
+	require.Nil(t, err)
+	assert.Equal(t, 1200, state.FileSummary.Total)
+	assert.Equal(t, 1200, state.FilesMatched)
+	require.Equal(t, 50, len(state.Files))
+	assert.Equal(t, "data/file_0600.txt", state.Files[0].PathInBag)
+
+	// Missing keys return an empty state, not an error.
+	state, err = client.WorkItemState(int64(999999), network.RedisFileFilterAll, 0, 20)
+	require.Nil(t, err)
+	assert.Empty(t, state.Files)
+	assert.Empty(t, state.Unknown)
+}
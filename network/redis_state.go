@@ -0,0 +1,387 @@
+package network
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// These are the file filters NewRedisWorkItemState accepts.
+const (
+	RedisFileFilterAll          = ""
+	RedisFileFilterError        = "error"
+	RedisFileFilterNotValidated = "not_validated"
+	RedisFileFilterNotStored    = "not_stored"
+)
+
+// RedisFileFilters lists the valid file filters.
+var RedisFileFilters = []string{
+	RedisFileFilterAll,
+	RedisFileFilterError,
+	RedisFileFilterNotValidated,
+	RedisFileFilterNotStored,
+}
+
+// Checksum sources recorded by the ingest workers. Manifest checksums
+// come from the bag's manifests. Ingest checksums are the ones the
+// workers calculated.
+const (
+	checksumSourceIngest   = "ingest"
+	checksumSourceManifest = "manifest"
+)
+
+// maxRedisUnknownFields is the most undecodable fields we'll keep for
+// one WorkItem. If the workers change the shape of their file records,
+// every file would land in Unknown, so we count the rest instead.
+const maxRedisUnknownFields = 100
+
+// RedisWorkItemState is a typed view of the state that the ingest and
+// restoration workers keep in Redis for one WorkItem. The workers own
+// this data, and its structure may change over time. Fields with names
+// or shapes we don't recognize go into Unknown as raw JSON, so admins
+// can still see them. UnknownOmitted counts those we left out because
+// there were too many.
+//
+// Bags can contain hundreds of thousands of files, so this holds only
+// one page of files, sorted by path and limited to files matching a
+// filter. FilesMatched counts all files that match the filter, and
+// FileSummary counts all files.
+type RedisWorkItemState struct {
+	WorkItemID        int64                      `json:"work_item_id"`
+	IngestObject      *RedisIngestObject         `json:"ingest_object,omitempty"`
+	RestorationObject *RedisRestorationObject    `json:"restoration_object,omitempty"`
+	WorkResults       []*RedisWorkResult         `json:"work_results"`
+	Files             []*RedisIngestFile         `json:"files"`
+	FilesMatched      int                        `json:"files_matched"`
+	FileSummary       *RedisFileSummary          `json:"file_summary"`
+	Unknown           map[string]json.RawMessage `json:"unknown,omitempty"`
+	UnknownOmitted    int                        `json:"unknown_omitted,omitempty"`
+
+	fileFilter string
+	fileOffset int
+	fileLimit  int
+}
+
+// RedisIngestObject describes the bag being ingested.
+type RedisIngestObject struct {
+	ID                     int64     `json:"id,omitempty"`
+	Institution            string    `json:"institution,omitempty"`
+	InstitutionID          int64     `json:"institution_id,omitempty"`
+	S3Bucket               string    `json:"s3_bucket,omitempty"`
+	S3Key                  string    `json:"s3_key,omitempty"`
+	ETag                   string    `json:"etag,omitempty"`
+	Size                   int64     `json:"size,omitempty"`
+	FileCount              int       `json:"file_count"`
+	StorageOption          string    `json:"storage_option,omitempty"`
+	Serialization          string    `json:"serialization,omitempty"`
+	IsReingest             bool      `json:"is_reingest"`
+	HasFetchTxt            bool      `json:"has_fetch_txt"`
+	DeletedFromReceivingAt time.Time `json:"deleted_from_receiving_at,omitempty"`
+	ErrorMessage           string    `json:"error_message,omitempty"`
+}
+
+// RedisIngestFile describes one file in the bag being ingested. The
+// fields after ErrorMessage aren't stored in Redis. They summarize the
+// file's progress. See setProgress.
+type RedisIngestFile struct {
+	UUID              string                 `json:"uuid,omitempty"`
+	PathInBag         string                 `json:"path_in_bag,omitempty"`
+	Size              int64                  `json:"size"`
+	FileFormat        string                 `json:"file_format,omitempty"`
+	StorageOption     string                 `json:"storage_option,omitempty"`
+	Checksums         []*RedisIngestChecksum `json:"checksums"`
+	StorageRecords    []*RedisStorageRecord  `json:"storage_records,omitempty"`
+	SavedToRegistryAt time.Time              `json:"saved_to_registry_at,omitempty"`
+	ErrorMessage      string                 `json:"error_message,omitempty"`
+
+	Validated         bool     `json:"validated"`
+	Stored            bool     `json:"stored"`
+	ChecksumsComputed []string `json:"checksums_computed"`
+	Errors            []string `json:"errors"`
+}
+
+// RedisIngestChecksum is a checksum from the bag's manifests or one
+// that the workers calculated.
+type RedisIngestChecksum struct {
+	Algorithm string    `json:"algorithm"`
+	Digest    string    `json:"digest"`
+	Source    string    `json:"source"`
+	DateTime  time.Time `json:"datetime,omitempty"`
+}
+
+// RedisStorageRecord describes a copy of a file in preservation storage.
+type RedisStorageRecord struct {
+	URL        string    `json:"url,omitempty"`
+	StoredAt   time.Time `json:"stored_at,omitempty"`
+	VerifiedAt time.Time `json:"verified_at,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// RedisWorkResult describes the outcome of one worker's most recent
+// attempt at its stage of ingest or restoration.
+type RedisWorkResult struct {
+	Operation  string    `json:"operation"`
+	Attempt    int       `json:"attempt"`
+	Host       string    `json:"host,omitempty"`
+	Pid        int       `json:"pid,omitempty"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	Errors     []string  `json:"errors"`
+}
+
+// RedisRestorationObject describes an object or file being restored.
+type RedisRestorationObject struct {
+	Identifier        string    `json:"identifier"`
+	RestorationType   string    `json:"restoration_type,omitempty"`
+	RestorationSource string    `json:"restoration_source,omitempty"`
+	RestorationTarget string    `json:"restoration_target,omitempty"`
+	StorageOption     string    `json:"storage_option,omitempty"`
+	FileSize          int64     `json:"file_size,omitempty"`
+	ObjectSize        int64     `json:"object_size,omitempty"`
+	BagValidatedAt    time.Time `json:"bag_validated_at,omitempty"`
+	URL               string    `json:"url,omitempty"`
+	RestoredAt        time.Time `json:"restored_at,omitempty"`
+	ErrorMessage      string    `json:"error_message,omitempty"`
+}
+
+// RedisFileSummary counts files by progress.
+type RedisFileSummary struct {
+	Total             int `json:"total"`
+	Validated         int `json:"validated"`
+	Stored            int `json:"stored"`
+	ChecksumsComputed int `json:"checksums_computed"`
+	WithErrors        int `json:"with_errors"`
+}
+
+// NewRedisWorkItemState decodes the Redis hash fields for workItemID.
+// Param fields maps hash field names, such as "object:test.edu/bag" or
+// "file:test.edu/bag/data/file.txt", to their JSON values. Param
+// fileFilter should be one of the RedisFileFilter constants. Unknown
+// filters match no files. Params offset and limit select the page of
+// matching files to keep. A limit less than one keeps all of them.
+func NewRedisWorkItemState(workItemID int64, fields map[string]string, fileFilter string, offset, limit int) *RedisWorkItemState {
+	state := newRedisWorkItemState(workItemID, fileFilter, offset, limit)
+	for name, value := range fields {
+		state.add(name, value)
+	}
+	state.finish()
+	return state
+}
+
+func newRedisWorkItemState(workItemID int64, fileFilter string, offset, limit int) *RedisWorkItemState {
+	if offset < 0 {
+		offset = 0
+	}
+	return &RedisWorkItemState{
+		WorkItemID:  workItemID,
+		WorkResults: make([]*RedisWorkResult, 0),
+		Files:       make([]*RedisIngestFile, 0),
+		FileSummary: &RedisFileSummary{},
+		Unknown:     make(map[string]json.RawMessage),
+		fileFilter:  fileFilter,
+		fileOffset:  offset,
+		fileLimit:   limit,
+	}
+}
+
+// add decodes one Redis hash field into state. RedisClient.WorkItemState
+// calls this for each field as it scans the hash, so we never hold all
+// of a large bag's file records at once.
+func (state *RedisWorkItemState) add(name, value string) {
+	prefix := name
+	if i := strings.Index(name, ":"); i > -1 {
+		prefix = name[:i]
+	}
+	if !state.decode(prefix, name, value) {
+		state.addUnknown(name, value)
+	}
+}
+
+// addFile counts f in the file summary and, if f matches the file
+// filter, keeps it if it sorts within the requested page. Until finish
+// is called, Files holds the first offset + limit matching files.
+func (state *RedisWorkItemState) addFile(f *RedisIngestFile) {
+	f.setProgress()
+	state.FileSummary.add(f)
+	if !f.matches(state.fileFilter) {
+		return
+	}
+	state.FilesMatched++
+	i := sort.Search(len(state.Files), func(i int) bool {
+		return state.Files[i].PathInBag > f.PathInBag
+	})
+	keep := state.fileOffset + state.fileLimit
+	if state.fileLimit > 0 && i >= keep {
+		return
+	}
+	state.Files = append(state.Files, nil)
+	copy(state.Files[i+1:], state.Files[i:])
+	state.Files[i] = f
+	if state.fileLimit > 0 && len(state.Files) > keep {
+		state.Files = state.Files[:keep]
+	}
+}
+
+// finish drops the matching files that come before the requested page
+// and sorts the work results.
+func (state *RedisWorkItemState) finish() {
+	if state.fileOffset < len(state.Files) {
+		state.Files = state.Files[state.fileOffset:]
+	} else {
+		state.Files = make([]*RedisIngestFile, 0)
+	}
+	sort.Slice(state.WorkResults, func(i, j int) bool {
+		return state.WorkResults[i].Operation < state.WorkResults[j].Operation
+	})
+}
+
+// decode decodes a known field into state and returns true. It returns
+// false if it doesn't recognize the field's name or shape.
+func (state *RedisWorkItemState) decode(prefix, name, value string) bool {
+	switch prefix {
+	case "object":
+		obj := &RedisIngestObject{}
+		if decodeRedisJSON(value, obj, "institution", "s3_key", "file_count") {
+			state.IngestObject = obj
+			return true
+		}
+	case "file":
+		f := &RedisIngestFile{}
+		if decodeRedisJSON(value, f, "path_in_bag") {
+			state.addFile(f)
+			return true
+		}
+	case "workresult":
+		result := &RedisWorkResult{}
+		if decodeRedisJSON(value, result, "operation", "attempt", "started_at") {
+			if result.Operation == "" {
+				result.Operation = strings.TrimPrefix(name, "workresult:")
+			}
+			state.WorkResults = append(state.WorkResults, result)
+			return true
+		}
+	case "restoration":
+		obj := &RedisRestorationObject{}
+		if decodeRedisJSON(value, obj, "identifier", "restoration_type") {
+			state.RestorationObject = obj
+			return true
+		}
+	}
+	return false
+}
+
+func (state *RedisWorkItemState) addUnknown(name, value string) {
+	if len(state.Unknown) >= maxRedisUnknownFields {
+		state.UnknownOmitted++
+		return
+	}
+	if json.Valid([]byte(value)) {
+		state.Unknown[name] = json.RawMessage(value)
+	} else {
+		// Keep invalid JSON as a string, so we can still marshal it.
+		quoted, _ := json.Marshal(value)
+		state.Unknown[name] = json.RawMessage(quoted)
+	}
+}
+
+// UnknownJSON returns the fields we couldn't decode as indented JSON,
+// or an empty string if there are none.
+func (state *RedisWorkItemState) UnknownJSON() string {
+	if len(state.Unknown) == 0 {
+		return ""
+	}
+	data, err := json.MarshalIndent(state.Unknown, "", "  ")
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+// decodeRedisJSON decodes value into target and returns true if value
+// is a JSON object with at least one of the keys we expect.
+func decodeRedisJSON(value string, target interface{}, keys ...string) bool {
+	fields := make(map[string]json.RawMessage)
+	if json.Unmarshal([]byte(value), &fields) != nil {
+		return false
+	}
+	found := false
+	for _, key := range keys {
+		if _, ok := fields[key]; ok {
+			found = true
+			break
+		}
+	}
+	return found && json.Unmarshal([]byte(value), target) == nil
+}
+
+// setProgress summarizes this file's progress. A file is validated
+// when each manifest checksum matches the checksum the workers
+// calculated with the same algorithm, and stored when every storage
+// record has a stored_at timestamp and no error.
+func (f *RedisIngestFile) setProgress() {
+	f.ChecksumsComputed = make([]string, 0)
+	f.Errors = make([]string, 0)
+	if f.ErrorMessage != "" {
+		f.Errors = append(f.Errors, f.ErrorMessage)
+	}
+	calculated := make(map[string]string)
+	for _, cs := range f.Checksums {
+		if cs.Source == checksumSourceIngest {
+			calculated[cs.Algorithm] = cs.Digest
+			f.ChecksumsComputed = append(f.ChecksumsComputed, cs.Algorithm)
+		}
+	}
+	sort.Strings(f.ChecksumsComputed)
+	manifestCount := 0
+	f.Validated = true
+	for _, cs := range f.Checksums {
+		if cs.Source != checksumSourceManifest {
+			continue
+		}
+		manifestCount++
+		if calculated[cs.Algorithm] != cs.Digest {
+			f.Validated = false
+		}
+	}
+	f.Validated = f.Validated && manifestCount > 0
+	f.Stored = len(f.StorageRecords) > 0
+	for _, sr := range f.StorageRecords {
+		if sr.Error != "" {
+			f.Errors = append(f.Errors, sr.Error)
+		}
+		if sr.StoredAt.IsZero() || sr.Error != "" {
+			f.Stored = false
+		}
+	}
+}
+
+func (f *RedisIngestFile) matches(filter string) bool {
+	switch filter {
+	case RedisFileFilterAll:
+		return true
+	case RedisFileFilterError:
+		return len(f.Errors) > 0
+	case RedisFileFilterNotValidated:
+		return !f.Validated
+	case RedisFileFilterNotStored:
+		return !f.Stored
+	}
+	return false
+}
+
+func (s *RedisFileSummary) add(f *RedisIngestFile) {
+	s.Total++
+	if f.Validated {
+		s.Validated++
+	}
+	if f.Stored {
+		s.Stored++
+	}
+	if len(f.ChecksumsComputed) > 0 {
+		s.ChecksumsComputed++
+	}
+	if len(f.Errors) > 0 {
+		s.WithErrors++
+	}
+}
@@ -0,0 +1,150 @@
+package network_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/APTrust/registry/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var redisStateFields = map[string]string{
+	"object:test.edu/bag": `{"id":0,"institution":"test.edu","institution_id":3,"s3_bucket":"aptrust.receiving.test.edu","s3_key":"bag.tar","size":4096,"file_count":3,"storage_option":"Standard","is_reingest":false}`,
+	"file:test.edu/bag/data/good.txt": `{
+		"path_in_bag":"data/good.txt","uuid":"1111","size":100,
+		"checksums":[
+			{"algorithm":"md5","digest":"aaa","source":"manifest"},
+			{"algorithm":"md5","digest":"aaa","source":"ingest"},
+			{"algorithm":"sha256","digest":"bbb","source":"ingest"}],
+		"storage_records":[{"url":"https://s3.example.com/1111","stored_at":"2021-04-01T12:00:00Z"}]}`,
+	"file:test.edu/bag/data/bad.txt": `{
+		"path_in_bag":"data/bad.txt","uuid":"2222","size":200,
+		"checksums":[
+			{"algorithm":"md5","digest":"ccc","source":"manifest"},
+			{"algorithm":"md5","digest":"ddd","source":"ingest"}],
+		"error_message":"md5 digest does not match manifest"}`,
+	"file:test.edu/bag/data/unstored.txt": `{
+		"path_in_bag":"data/unstored.txt","uuid":"3333","size":300,
+		"checksums":[
+			{"algorithm":"md5","digest":"eee","source":"manifest"},
+			{"algorithm":"md5","digest":"eee","source":"ingest"}],
+		"storage_records":[{"url":"https://s3.example.com/3333","error":"connection reset"}]}`,
+	"workresult:ingest02_bag_validation": `{"operation":"ingest02_bag_validation","attempt":1,"host":"worker1","started_at":"2021-04-01T11:00:00Z","errors":["one bad file"]}`,
+	"workresult:ingest01_prefetch":       `{"operation":"ingest01_prefetch","attempt":2,"host":"worker1","started_at":"2021-04-01T10:00:00Z","errors":[]}`,
+	"file:test.edu/bag/data/odd.txt":     `{"something":"else"}`,
+	"mystery":                            `not json`,
+}
+
+func TestNewRedisWorkItemState(t *testing.T) {
+	state := network.NewRedisWorkItemState(77, redisStateFields, network.RedisFileFilterAll, 0, 0)
+	require.NotNil(t, state)
+	assert.Equal(t, int64(77), state.WorkItemID)
+
+	require.NotNil(t, state.IngestObject)
+	assert.Equal(t, "test.edu", state.IngestObject.Institution)
+	assert.Equal(t, 3, state.IngestObject.FileCount)
+	assert.Nil(t, state.RestorationObject)
+
+	// Work results and files are sorted.
+	require.Equal(t, 2, len(state.WorkResults))
+	assert.Equal(t, "ingest01_prefetch", state.WorkResults[0].Operation)
+	assert.Equal(t, 2, state.WorkResults[0].Attempt)
+	assert.Equal(t, []string{"one bad file"}, state.WorkResults[1].Errors)
+
+	require.Equal(t, 3, len(state.Files))
+	assert.Equal(t, 3, state.FilesMatched)
+	bad, good, unstored := state.Files[0], state.Files[1], state.Files[2]
+	assert.Equal(t, "data/bad.txt", bad.PathInBag)
+
+	assert.True(t, good.Validated)
+	assert.True(t, good.Stored)
+	assert.Equal(t, []string{"md5", "sha256"}, good.ChecksumsComputed)
+	assert.Empty(t, good.Errors)
+
+	assert.False(t, bad.Validated)
+	assert.False(t, bad.Stored)
+	assert.Equal(t, []string{"md5 digest does not match manifest"}, bad.Errors)
+
+	assert.True(t, unstored.Validated)
+	assert.False(t, unstored.Stored)
+	assert.Equal(t, []string{"connection reset"}, unstored.Errors)
+
+	assert.Equal(t, &network.RedisFileSummary{
+		Total:             3,
+		Validated:         2,
+		Stored:            1,
+		ChecksumsComputed: 3,
+		WithErrors:        2,
+	}, state.FileSummary)
+
+	// Shapes we don't recognize fall back to raw JSON.
+	require.Equal(t, 2, len(state.Unknown))
+	assert.JSONEq(t, `{"something":"else"}`, string(state.Unknown["file:test.edu/bag/data/odd.txt"]))
+	assert.JSONEq(t, `"not json"`, string(state.Unknown["mystery"]))
+	assert.Contains(t, state.UnknownJSON(), "something")
+}
+
+func TestRedisWorkItemStateFileFilters(t *testing.T) {
+	paths := func(filter string) []string {
+		state := network.NewRedisWorkItemState(77, redisStateFields, filter, 0, 0)
+		p := make([]string, len(state.Files))
+		for i, f := range state.Files {
+			p[i] = f.PathInBag
+		}
+		// The summary counts all files, whatever the filter.
+		assert.Equal(t, 3, state.FileSummary.Total)
+		assert.Equal(t, len(p), state.FilesMatched)
+		return p
+	}
+	assert.Equal(t, []string{"data/bad.txt", "data/good.txt", "data/unstored.txt"}, paths(network.RedisFileFilterAll))
+	assert.Equal(t, []string{"data/bad.txt", "data/unstored.txt"}, paths(network.RedisFileFilterError))
+	assert.Equal(t, []string{"data/bad.txt"}, paths(network.RedisFileFilterNotValidated))
+	assert.Equal(t, []string{"data/bad.txt", "data/unstored.txt"}, paths(network.RedisFileFilterNotStored))
+	assert.Empty(t, paths("bogus"))
+}
+
+func TestRedisWorkItemStateFilePages(t *testing.T) {
+	fields := make(map[string]string)
+	for i := 0; i < 250; i++ {
+		path := fmt.Sprintf("data/file_%03d.txt", i)
+		fields["file:test.edu/bag/"+path] = fmt.Sprintf(`{"path_in_bag":"%s"}`, path)
+	}
+	state := network.NewRedisWorkItemState(79, fields, network.RedisFileFilterAll, 100, 20)
+	assert.Equal(t, 250, state.FilesMatched)
+	assert.Equal(t, 250, state.FileSummary.Total)
+	require.Equal(t, 20, len(state.Files))
+	assert.Equal(t, "data/file_100.txt", state.Files[0].PathInBag)
+	assert.Equal(t, "data/file_119.txt", state.Files[19].PathInBag)
+
+	// The last page may be short, and pages past the end are empty.
+	state = network.NewRedisWorkItemState(79, fields, network.RedisFileFilterAll, 240, 20)
+	require.Equal(t, 10, len(state.Files))
+	assert.Equal(t, "data/file_249.txt", state.Files[9].PathInBag)
+	state = network.NewRedisWorkItemState(79, fields, network.RedisFileFilterAll, 260, 20)
+	assert.Empty(t, state.Files)
+	assert.Equal(t, 250, state.FilesMatched)
+}
+
+func TestRedisWorkItemStateUnknownLimit(t *testing.T) {
+	fields := make(map[string]string)
+	for i := 0; i < 150; i++ {
+		fields[fmt.Sprintf("file:test.edu/bag/data/%d.txt", i)] = `{"new_format":true}`
+	}
+	state := network.NewRedisWorkItemState(80, fields, network.RedisFileFilterAll, 0, 20)
+	assert.Equal(t, 100, len(state.Unknown))
+	assert.Equal(t, 50, state.UnknownOmitted)
+	assert.Equal(t, 0, state.FileSummary.Total)
+}
+
+func TestNewRedisWorkItemStateRestoration(t *testing.T) {
+	fields := map[string]string{
+		"restoration:test.edu/bag": `{"identifier":"test.edu/bag","restoration_type":"object","url":"https://s3.example.com/restore/bag.tar"}`,
+	}
+	state := network.NewRedisWorkItemState(78, fields, network.RedisFileFilterAll, 0, 20)
+	require.NotNil(t, state.RestorationObject)
+	assert.Equal(t, "test.edu/bag", state.RestorationObject.Identifier)
+	assert.Equal(t, "https://s3.example.com/restore/bag.tar", state.RestorationObject.URL)
+	assert.Empty(t, state.Unknown)
+	assert.Equal(t, 0, state.FileSummary.Total)
+}
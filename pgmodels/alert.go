@@ -221,6 +221,10 @@ func CreateAlert(alert *Alert, templateName string, alertData map[string]interfa
 		return nil, err
 	}
 
+	if alert.Type == constants.AlertFailedFixity {
+		alert.queueFailedFixityWebhook()
+	}
+
 	// Send the alert & mark as sent
 	for _, recipient := range alert.Users {
 		err := common.Context().SendEmail(recipient.Email, alert.Subject, alert.Content)
@@ -268,3 +272,18 @@ func NewFailedFixityAlert(institutionID int64, premisEvents []*PremisEvent, reci
 		Users:         recipients,
 	}
 }
+
+// queueFailedFixityWebhook queues a fixity.failed webhook event for
+// this alert's institution. Errors are logged, not returned, because
+// webhook problems should not prevent us from sending the alert.
+func (alert *Alert) queueFailedFixityWebhook() {
+	data := map[string]interface{}{
+		"alert_id":      alert.ID,
+		"subject":       alert.Subject,
+		"premis_events": alert.PremisEvents,
+	}
+	_, err := QueueWebhookEvent(alert.InstitutionID, constants.WebhookEventFixityFailed, data)
+	if err != nil {
+		common.Context().Log.Error().Msgf("Error queueing %s webhook for alert %d: %v", constants.WebhookEventFixityFailed, alert.ID, err)
+	}
+}
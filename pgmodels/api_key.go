@@ -0,0 +1,302 @@
+package pgmodels
+
+import (
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	v "github.com/asaskevich/govalidator"
+	"github.com/stretchr/stew/slice"
+)
+
+const (
+	ErrAPIKeyUser       = "API key requires a valid user id."
+	ErrAPIKeyLabel      = "Label must contain between 1 and 100 characters."
+	ErrAPIKeyScope      = "Please choose a valid scope."
+	ErrAPIKeyPrefix     = "API key requires a valid prefix."
+	ErrAPIKeyEncryption = "API key must be encrypted."
+	ErrAPIKeyExpiration = "Expiration date must be in the future."
+	ErrAPIKeyAllowedIPs = "Allowed IPs must be a comma-separated list of IP addresses or CIDR blocks."
+)
+
+// APIKeyPrefixLength is the length of the public prefix at the start
+// of each API key. We use the prefix to find the key's record in the
+// database, since we can't look up keys by their encrypted value.
+const APIKeyPrefixLength = 8
+
+// APIKeyUseInterval is how often we record the use of an API key
+// that keeps calling from the same IP address. Recording every use
+// would add a write to every API request.
+const APIKeyUseInterval = time.Minute
+
+var reAPIKey = regexp.MustCompile(`^([0-9a-f]{8})\.([0-9a-f]{32})$`)
+
+// APIKey is one of possibly many API keys belonging to a user.
+// Unlike the user's legacy API key (User.EncryptedAPISecretKey),
+// these keys have a label, a scope, and an optional expiration date
+// and IP restriction, and they can be revoked individually.
+//
+// The plaintext key has the format <prefix>.<secret>. We store the
+// prefix in plaintext so we can find the key, and the whole key in
+// encrypted form.
+type APIKey struct {
+	tableName struct{} `pg:"api_keys,alias:api_key"`
+	TimestampModel
+	AuditedModel
+
+	// UserID is the id of the user who owns this key.
+	UserID int64 `json:"user_id" form:"-" pg:"user_id"`
+
+	// Label describes what the key is for. E.g. "Nightly fixity report".
+	Label string `json:"label" pg:"label"`
+
+	// Prefix is the public part of the key. It appears in plaintext
+	// at the start of the key.
+	Prefix string `json:"prefix" form:"-" pg:"prefix"`
+
+	// EncryptedKey is the full key, encrypted.
+	EncryptedKey string `json:"-" form:"-" pg:"encrypted_key" audit:"secret"`
+
+	// Scope is constants.APIKeyScopeReadOnly or APIKeyScopeReadWrite.
+	// Read-only keys can make only GET and HEAD requests.
+	Scope string `json:"scope" pg:"scope"`
+
+	// AllowedIPs is an optional comma-separated list of IP addresses
+	// and/or CIDR blocks from which this key may be used. If empty,
+	// the key may be used from anywhere.
+	AllowedIPs string `json:"allowed_ips" pg:"allowed_ips"`
+
+	// ExpiresAt is the time at which this key stops working. If it's
+	// empty, the key does not expire.
+	ExpiresAt time.Time `json:"expires_at" time_format:"2006-01-02" pg:"expires_at"`
+
+	// LastUsedAt describes when this key was last used to
+	// successfully authenticate.
+	LastUsedAt time.Time `json:"last_used_at" form:"-" pg:"last_used_at"`
+
+	// LastUsedIP is the IP address from which this key was last used.
+	LastUsedIP string `json:"last_used_ip" form:"-" pg:"last_used_ip"`
+
+	// RevokedAt describes when the user revoked this key. Revoked
+	// keys can't be used.
+	RevokedAt time.Time `json:"revoked_at" form:"-" pg:"revoked_at"`
+
+	// PlaintextKey is set only when we first create a key, so we can
+	// show it to the user once. We never save it.
+	PlaintextKey string `json:"-" form:"-" pg:"-"`
+
+	User *User `json:"-" pg:"rel:has-one"`
+}
+
+// NewAPIKey returns a new, unsaved API key for the specified user,
+// with a read-only scope. Set the label and other options, then call
+// Save(). The returned key's PlaintextKey is the only copy of the
+// usable key. Show it to the user, then let it go out of scope.
+func NewAPIKey(userID int64) (*APIKey, error) {
+	prefix := common.RandomToken()[:APIKeyPrefixLength]
+	plaintext := prefix + "." + common.RandomToken()
+	encKey, err := common.EncryptPassword(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return &APIKey{
+		UserID:       userID,
+		Prefix:       prefix,
+		EncryptedKey: encKey,
+		Scope:        constants.APIKeyScopeReadOnly,
+		PlaintextKey: plaintext,
+	}, nil
+}
+
+// APIKeyByID returns the API key with the specified id.
+// Returns pg.ErrNoRows if there is no match.
+func APIKeyByID(id int64) (*APIKey, error) {
+	query := NewQuery().Where("id", "=", id)
+	return APIKeyGet(query)
+}
+
+// APIKeyGet returns the first API key matching the query.
+func APIKeyGet(query *Query) (*APIKey, error) {
+	var key APIKey
+	err := query.Select(&key)
+	return &key, err
+}
+
+// APIKeySelect returns all API keys matching the query.
+func APIKeySelect(query *Query) ([]*APIKey, error) {
+	var keys []*APIKey
+	err := query.Select(&keys)
+	return keys, err
+}
+
+// APIKeyForUser returns the API key belonging to the specified user
+// whose prefix matches plaintextKey. The second return value will be
+// false if plaintextKey isn't in the format of a per-key API key,
+// which means it may be the user's legacy API key. This returns
+// pg.ErrNoRows if plaintextKey looks right but there's no matching key.
+func APIKeyForUser(userID int64, plaintextKey string) (*APIKey, bool, error) {
+	match := reAPIKey.FindStringSubmatch(plaintextKey)
+	if match == nil {
+		return nil, false, nil
+	}
+	query := NewQuery().Where("user_id", "=", userID).Where("prefix", "=", match[1])
+	key, err := APIKeyGet(query)
+	return key, true, err
+}
+
+// Save saves this key to the database. This will peform an insert
+// if APIKey.ID is zero. Otherwise, it updates.
+func (key *APIKey) Save() error {
+	key.SetTimestamps()
+	key.Label = strings.TrimSpace(key.Label)
+	key.AllowedIPs = strings.TrimSpace(key.AllowedIPs)
+	err := key.Validate()
+	if err != nil {
+		return err
+	}
+	if key.ID == int64(0) {
+		return insert(key)
+	}
+	return update(key)
+}
+
+// Validate returns errors if this key is not valid.
+func (key *APIKey) Validate() *common.ValidationError {
+	errors := make(map[string]string)
+	if key.UserID < 1 {
+		errors["UserID"] = ErrAPIKeyUser
+	}
+	if !v.IsByteLength(key.Label, 1, 100) {
+		errors["Label"] = ErrAPIKeyLabel
+	}
+	if !slice.Contains(constants.APIKeyScopes, key.Scope) {
+		errors["Scope"] = ErrAPIKeyScope
+	}
+	if len(key.Prefix) != APIKeyPrefixLength {
+		errors["Prefix"] = ErrAPIKeyPrefix
+	}
+	if !common.LooksEncrypted(key.EncryptedKey) {
+		errors["EncryptedKey"] = ErrAPIKeyEncryption
+	}
+	if key.ID == 0 && !key.ExpiresAt.IsZero() && key.ExpiresAt.Before(time.Now().UTC()) {
+		errors["ExpiresAt"] = ErrAPIKeyExpiration
+	}
+	if _, err := parseAllowedIPs(key.AllowedIPs); err != nil {
+		errors["AllowedIPs"] = ErrAPIKeyAllowedIPs
+	}
+	if len(errors) > 0 {
+		return &common.ValidationError{Errors: errors}
+	}
+	return nil
+}
+
+// Matches returns true if plaintextKey matches this key.
+func (key *APIKey) Matches(plaintextKey string) bool {
+	return common.ComparePasswords(key.EncryptedKey, plaintextKey)
+}
+
+// IsActive returns true if this key has not been revoked and
+// has not expired.
+func (key *APIKey) IsActive() bool {
+	if !key.RevokedAt.IsZero() {
+		return false
+	}
+	return key.ExpiresAt.IsZero() || key.ExpiresAt.After(time.Now().UTC())
+}
+
+// IsReadOnly returns true if this key may be used only for
+// GET and HEAD requests.
+func (key *APIKey) IsReadOnly() bool {
+	return key.Scope != constants.APIKeyScopeReadWrite
+}
+
+// AllowsMethod returns true if this key's scope permits the
+// specified HTTP method.
+func (key *APIKey) AllowsMethod(method string) bool {
+	if !key.IsReadOnly() {
+		return true
+	}
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// AllowsIP returns true if this key may be used from the specified
+// IP address.
+func (key *APIKey) AllowsIP(ip string) bool {
+	networks, err := parseAllowedIPs(key.AllowedIPs)
+	if err != nil {
+		return false
+	}
+	if len(networks) == 0 {
+		return true
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Revoke permanently disables this key.
+func (key *APIKey) Revoke() error {
+	if key.RevokedAt.IsZero() {
+		key.RevokedAt = time.Now().UTC()
+	}
+	return key.Save()
+}
+
+// RecordUse updates this key's last used timestamp and IP address.
+// This updates only those two columns, so it's safe to call while
+// other requests are using the same key. It skips the update if the
+// key was last used from the same IP less than APIKeyUseInterval ago.
+func (key *APIKey) RecordUse(ip string) error {
+	if key.LastUsedIP == ip && time.Since(key.LastUsedAt) < APIKeyUseInterval {
+		return nil
+	}
+	key.LastUsedAt = time.Now().UTC()
+	key.LastUsedIP = ip
+	_, err := common.Context().DB.Model(key).
+		Column("last_used_at", "last_used_ip").
+		WherePK().
+		Update()
+	return err
+}
+
+// parseAllowedIPs converts a comma-separated list of IP addresses
+// and CIDR blocks into a list of networks. Single IP addresses become
+// networks containing only that address.
+func parseAllowedIPs(allowedIPs string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0)
+	for _, entry := range strings.Split(allowedIPs, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, common.ErrInvalidParam
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			entry = entry + "/" + strconv.Itoa(bits)
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
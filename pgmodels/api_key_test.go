@@ -0,0 +1,121 @@
+package pgmodels_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	key, err := pgmodels.NewAPIKey(3)
+	require.Nil(t, err)
+	assert.Equal(t, int64(3), key.UserID)
+	assert.Equal(t, constants.APIKeyScopeReadOnly, key.Scope)
+	assert.Equal(t, pgmodels.APIKeyPrefixLength, len(key.Prefix))
+	assert.True(t, common.LooksEncrypted(key.EncryptedKey))
+	assert.True(t, key.Matches(key.PlaintextKey))
+	assert.False(t, key.Matches(key.Prefix+".00000000000000000000000000000000"))
+}
+
+func TestAPIKeyValidate(t *testing.T) {
+	key := &pgmodels.APIKey{
+		ExpiresAt:  time.Now().UTC().AddDate(0, 0, -1),
+		AllowedIPs: "10.0.0.1, not-an-ip",
+	}
+	err := key.Validate()
+	require.NotNil(t, err)
+	assert.Equal(t, pgmodels.ErrAPIKeyUser, err.Errors["UserID"])
+	assert.Equal(t, pgmodels.ErrAPIKeyLabel, err.Errors["Label"])
+	assert.Equal(t, pgmodels.ErrAPIKeyScope, err.Errors["Scope"])
+	assert.Equal(t, pgmodels.ErrAPIKeyPrefix, err.Errors["Prefix"])
+	assert.Equal(t, pgmodels.ErrAPIKeyEncryption, err.Errors["EncryptedKey"])
+	assert.Equal(t, pgmodels.ErrAPIKeyExpiration, err.Errors["ExpiresAt"])
+	assert.Equal(t, pgmodels.ErrAPIKeyAllowedIPs, err.Errors["AllowedIPs"])
+
+	key, _ = pgmodels.NewAPIKey(3)
+	key.Label = "Test key"
+	key.AllowedIPs = "10.0.0.1, 192.168.1.0/24, ::1"
+	assert.Nil(t, key.Validate())
+}
+
+func TestAPIKeyAllowsIP(t *testing.T) {
+	key := &pgmodels.APIKey{}
+	assert.True(t, key.AllowsIP("10.0.0.1"))
+
+	key.AllowedIPs = "10.0.0.1, 192.168.1.0/24"
+	assert.True(t, key.AllowsIP("10.0.0.1"))
+	assert.True(t, key.AllowsIP("192.168.1.77"))
+	assert.False(t, key.AllowsIP("10.0.0.2"))
+	assert.False(t, key.AllowsIP("192.168.2.1"))
+	assert.False(t, key.AllowsIP("garbage"))
+}
+
+func TestAPIKeyAllowsMethod(t *testing.T) {
+	key := &pgmodels.APIKey{Scope: constants.APIKeyScopeReadOnly}
+	assert.True(t, key.AllowsMethod(http.MethodGet))
+	assert.True(t, key.AllowsMethod(http.MethodHead))
+	assert.False(t, key.AllowsMethod(http.MethodPost))
+	assert.False(t, key.AllowsMethod(http.MethodPut))
+	assert.False(t, key.AllowsMethod(http.MethodDelete))
+
+	key.Scope = constants.APIKeyScopeReadWrite
+	assert.True(t, key.AllowsMethod(http.MethodPost))
+	assert.True(t, key.AllowsMethod(http.MethodDelete))
+}
+
+func TestAPIKeySaveAndRevoke(t *testing.T) {
+	db.LoadFixtures()
+	key, err := pgmodels.NewAPIKey(3)
+	require.Nil(t, err)
+	key.Label = "Nightly report"
+	require.Nil(t, key.Save())
+	assert.True(t, key.ID > 0)
+	assert.True(t, key.IsActive())
+
+	found, isKeyFormat, err := pgmodels.APIKeyForUser(3, key.PlaintextKey)
+	require.Nil(t, err)
+	assert.True(t, isKeyFormat)
+	assert.Equal(t, key.ID, found.ID)
+	assert.True(t, found.Matches(key.PlaintextKey))
+
+	_, isKeyFormat, err = pgmodels.APIKeyForUser(3, "legacy-key")
+	assert.Nil(t, err)
+	assert.False(t, isKeyFormat)
+
+	require.Nil(t, found.RecordUse("10.0.0.1"))
+	found, err = pgmodels.APIKeyByID(key.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "10.0.0.1", found.LastUsedIP)
+	assert.False(t, found.LastUsedAt.IsZero())
+
+	// Repeated use from the same IP within the interval isn't
+	// written. Use from a new IP is.
+	lastUsedAt := found.LastUsedAt
+	require.Nil(t, found.RecordUse("10.0.0.1"))
+	found, err = pgmodels.APIKeyByID(key.ID)
+	require.Nil(t, err)
+	assert.True(t, found.LastUsedAt.Equal(lastUsedAt))
+	require.Nil(t, found.RecordUse("10.0.0.2"))
+	found, err = pgmodels.APIKeyByID(key.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "10.0.0.2", found.LastUsedIP)
+	assert.False(t, found.LastUsedAt.Before(lastUsedAt))
+
+	require.Nil(t, found.Revoke())
+	found, err = pgmodels.APIKeyByID(key.ID)
+	require.Nil(t, err)
+	assert.False(t, found.IsActive())
+
+	instID, err := pgmodels.InstIDFor("APIKey", key.ID)
+	require.Nil(t, err)
+	user, err := pgmodels.UserByID(3)
+	require.Nil(t, err)
+	assert.Equal(t, user.InstitutionID, instID)
+}
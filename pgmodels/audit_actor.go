@@ -0,0 +1,80 @@
+package pgmodels
+
+// AuditActor describes who is changing records through pgmodels and
+// how. Handlers build one for the current request with
+// helpers.AuditActor.
+type AuditActor struct {
+	UserID     int64
+	UserEmail  string
+	RemoteAddr string
+	Method     string
+	Route      string
+}
+
+// Auditable models can record user-initiated changes in the audit log.
+// Models implement this by embedding AuditedModel.
+type Auditable interface {
+	Model
+	auditedModel() *AuditedModel
+}
+
+// AuditedModel is embedded in models whose user-initiated changes
+// belong in the audit log, such as users, institutions and deletion
+// requests. Saving one of these models records an audit log entry
+// only if the caller passed it to AuditChangesBy or AuditChangesFrom
+// first. Handlers do that for every save they make on behalf of a
+// request, including requests from preservation services to the admin
+// API. Changes made by Registry's own cron jobs and other background
+// work are not audited.
+//
+// The audit log does not cover append-only records, such as Premis
+// events, or records that only Registry itself changes, such as alerts
+// and NSQ outbox messages.
+type AuditedModel struct {
+	auditActor  *AuditActor
+	auditBefore map[string]interface{}
+}
+
+func (am *AuditedModel) auditedModel() *AuditedModel {
+	return am
+}
+
+// AuditChangesBy tells pgmodels to record changes that are saved to
+// model as changes made by actor. Call this after loading model and
+// before changing it, so the audit log can compare the record you save
+// to the record you loaded. For new models, call it any time before
+// the first save.
+//
+// This returns an error only if model can't be serialized to JSON.
+func AuditChangesBy(model Auditable, actor *AuditActor) error {
+	am := model.auditedModel()
+	am.auditActor = actor
+	am.auditBefore = nil
+	if model.GetID() == 0 {
+		return nil
+	}
+	before, err := auditFields(model)
+	if err != nil {
+		return err
+	}
+	am.auditBefore = before
+	return nil
+}
+
+// AuditChangesFrom is like AuditChangesBy, for callers that save a new
+// copy of a record instead of the one they loaded, such as API handlers
+// that bind the request body to a new struct. The audit log compares
+// what's saved to model with original. Pass a nil original for new
+// records.
+//
+// This returns an error only if original can't be serialized to JSON.
+func AuditChangesFrom(original, model Auditable, actor *AuditActor) error {
+	am := model.auditedModel()
+	am.auditActor = actor
+	before, err := auditFields(original)
+	if err != nil {
+		return err
+	}
+	am.auditBefore = before
+	return nil
+}
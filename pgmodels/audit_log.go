@@ -0,0 +1,387 @@
+package pgmodels
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/go-pg/pg/v10"
+)
+
+// AuditLogFilters describes the allowed filters for searching
+// the audit log.
+var AuditLogFilters = []string{
+	"action",
+	"actor_email",
+	"actor_id",
+	"created_at__gteq",
+	"created_at__lteq",
+	"institution_id",
+	"model_id",
+	"model_type",
+}
+
+// AuditLog records one user-initiated insert, update, soft delete or
+// delete of an Auditable model, along with who did it and what the
+// record looked like before and after. See AuditedModel.
+//
+// Entries are written in the same transaction as the change they
+// describe, so we never have a change without an entry or an entry
+// without a change. ActorID is zero and ActorEmail is empty for
+// changes made by a request with no logged-in user, such as a
+// password reset.
+//
+// Audit log entries are never updated.
+type AuditLog struct {
+	ID            int64                  `json:"id"`
+	ActorID       int64                  `json:"actor_id"`
+	ActorEmail    string                 `json:"actor_email"`
+	RemoteAddr    string                 `json:"remote_addr"`
+	Method        string                 `json:"method"`
+	Route         string                 `json:"route"`
+	Action        string                 `json:"action"`
+	ModelType     string                 `json:"model_type"`
+	ModelID       int64                  `json:"model_id"`
+	InstitutionID int64                  `json:"institution_id"`
+	Before        map[string]interface{} `json:"before" pg:"before,type:jsonb"`
+	After         map[string]interface{} `json:"after" pg:"after,type:jsonb"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+// AuditLogChange describes a change to a single field.
+type AuditLogChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditLogByID returns the entry with the specified id.
+// Returns pg.ErrNoRows if there is no match.
+func AuditLogByID(id int64) (*AuditLog, error) {
+	query := NewQuery().Where("id", "=", id)
+	return AuditLogGet(query)
+}
+
+// AuditLogGet returns the first entry matching the query.
+func AuditLogGet(query *Query) (*AuditLog, error) {
+	var entry AuditLog
+	err := query.Select(&entry)
+	return &entry, err
+}
+
+// AuditLogSelect returns all entries matching the query.
+func AuditLogSelect(query *Query) ([]*AuditLog, error) {
+	var entries []*AuditLog
+	err := query.Select(&entries)
+	return entries, err
+}
+
+// GetID returns this entry's id.
+func (a *AuditLog) GetID() int64 {
+	return a.ID
+}
+
+// Save is not supported. The audit log is written by the insert
+// and update functions in this package.
+func (a *AuditLog) Save() error {
+	return common.ErrNotSupported
+}
+
+// Validate always returns nil. We don't validate audit log entries
+// because we never want to lose one.
+func (a *AuditLog) Validate() *common.ValidationError {
+	return nil
+}
+
+// Changes returns the fields that differ between Before and After,
+// sorted by field name. For inserts, that's every field.
+func (a *AuditLog) Changes() []*AuditLogChange {
+	changes := make([]*AuditLogChange, 0)
+	for field, after := range a.After {
+		before, ok := a.Before[field]
+		if ok && reflect.DeepEqual(before, after) {
+			continue
+		}
+		changes = append(changes, &AuditLogChange{
+			Field:  field,
+			Before: before,
+			After:  after,
+		})
+	}
+	for field, before := range a.Before {
+		if _, ok := a.After[field]; !ok {
+			changes = append(changes, &AuditLogChange{
+				Field:  field,
+				Before: before,
+			})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+// auditedInsert inserts model and records the insert in the audit
+// log if the caller passed model to AuditChangesBy. Run this inside a
+// transaction.
+func auditedInsert(tx *pg.Tx, model interface{}) error {
+	_, err := tx.Model(model).Insert()
+	if err != nil {
+		return err
+	}
+	return auditChanges(tx, model)
+}
+
+// auditedUpdate updates model and records the change in the audit
+// log if the caller passed model to AuditChangesBy. Run this inside a
+// transaction.
+func auditedUpdate(tx *pg.Tx, model interface{}) error {
+	_, err := tx.Model(model).WherePK().Update()
+	if err != nil {
+		return err
+	}
+	return auditChanges(tx, model)
+}
+
+// auditedDelete deletes model and records the deletion in the audit
+// log if the caller passed model to AuditChangesBy. Run this inside a
+// transaction. This is for records we really delete. Soft deletes are
+// updates.
+func auditedDelete(tx *pg.Tx, model interface{}) error {
+	_, err := tx.Model(model).WherePK().Delete()
+	if err != nil {
+		return err
+	}
+	auditable, ok := model.(Auditable)
+	if !ok {
+		return nil
+	}
+	am := auditable.auditedModel()
+	if am.auditActor == nil {
+		return nil
+	}
+	before, err := auditFields(model)
+	if err != nil {
+		return err
+	}
+	return insertAuditLog(tx, am.auditActor, constants.AuditActionDelete, model, before, nil)
+}
+
+// auditChanges records the change to model in the audit log, if model
+// is Auditable and has an actor. The next save of model is compared to
+// what we saved here.
+func auditChanges(tx *pg.Tx, model interface{}) error {
+	auditable, ok := model.(Auditable)
+	if !ok {
+		return nil
+	}
+	am := auditable.auditedModel()
+	if am.auditActor == nil {
+		return nil
+	}
+	after, err := recordAudit(tx, am.auditActor, am.auditBefore, model)
+	if err != nil {
+		return err
+	}
+	am.auditBefore = after
+	return nil
+}
+
+// recordAudit inserts an audit log entry describing actor's change from
+// before to model and returns model's audit fields. Param before should
+// be nil for inserts. This skips updates that didn't change anything
+// other than updated_at.
+func recordAudit(tx *pg.Tx, actor *AuditActor, before map[string]interface{}, model interface{}) (map[string]interface{}, error) {
+	after, err := auditFields(model)
+	if err != nil {
+		return nil, err
+	}
+	action := auditAction(before, after)
+	if action == constants.AuditActionUpdate && !auditFieldsChanged(before, after) {
+		return after, nil
+	}
+	return after, insertAuditLog(tx, actor, action, model, before, after)
+}
+
+// insertAuditLog inserts an audit log entry describing actor's change
+// to model. Param before is nil for inserts, and after is nil for
+// deletes.
+func insertAuditLog(tx *pg.Tx, actor *AuditActor, action string, model interface{}, before, after map[string]interface{}) error {
+	fields := after
+	if fields == nil {
+		fields = before
+	}
+	entry := &AuditLog{
+		ActorID:    actor.UserID,
+		ActorEmail: actor.UserEmail,
+		RemoteAddr: actor.RemoteAddr,
+		Method:     actor.Method,
+		Route:      actor.Route,
+		Action:     action,
+		ModelType:  reflect.TypeOf(model).Elem().Name(),
+		ModelID:    auditInt64(fields["id"]),
+		Before:     before,
+		After:      after,
+		CreatedAt:  time.Now().UTC(),
+	}
+	var err error
+	entry.InstitutionID, err = auditInstitutionID(tx, model, fields)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Model(entry).Insert()
+	return err
+}
+
+// auditFields returns model's JSON fields as a map. This omits empty
+// values and related records, such as a User's Institution or a
+// GenericFile's Checksums, so entries describe only the row that
+// changed. Because the map comes from JSON, it never includes secrets
+// such as encrypted passwords and API keys, which our models exclude
+// from JSON. See auditFingerprints for how we record changes to those.
+func auditFields(model interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range fields {
+		if isEmptyOrRelated(value) {
+			delete(fields, name)
+		}
+	}
+	for name, value := range auditFingerprints(model) {
+		fields[name] = value
+	}
+	return fields, nil
+}
+
+// auditFingerprints returns a short SHA-256 fingerprint of each
+// non-empty secret field in model, keyed by the field's column name
+// plus "_fingerprint". Secret fields are tagged audit:"secret". The
+// fingerprints let the audit log show that someone changed a password,
+// API key or two-factor secret without revealing anything about it.
+func auditFingerprints(model interface{}) map[string]interface{} {
+	fingerprints := make(map[string]interface{})
+	value := reflect.ValueOf(model)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fingerprints
+	}
+	value = value.Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Tag.Get("audit") != "secret" {
+			continue
+		}
+		secret := ""
+		switch v := value.Field(i).Interface().(type) {
+		case string:
+			secret = v
+		case []string:
+			secret = strings.Join(v, "\n")
+		}
+		if secret == "" {
+			continue
+		}
+		column := strings.Split(field.Tag.Get("pg"), ",")[0]
+		sum := sha256.Sum256([]byte(secret))
+		fingerprints[column+"_fingerprint"] = hex.EncodeToString(sum[:])[:12]
+	}
+	return fingerprints
+}
+
+// isEmptyOrRelated returns true if value is null, an empty array, a
+// JSON object or an array of JSON objects. We omit empty values because
+// a nil slice and an empty slice look different in JSON but are the
+// same in the database.
+func isEmptyOrRelated(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return true
+	case []interface{}:
+		if len(v) == 0 {
+			return true
+		}
+		_, isObject := v[0].(map[string]interface{})
+		return isObject
+	}
+	return false
+}
+
+// auditAction returns the audit action that describes the change from
+// before to after. Updates that change a record's state from active
+// to deleted or set its deactivation date are soft deletes. Updates
+// that reverse those are undeletes.
+func auditAction(before, after map[string]interface{}) string {
+	if before == nil {
+		return constants.AuditActionInsert
+	}
+	wasDeleted := isSoftDeleted(before)
+	isDeleted := isSoftDeleted(after)
+	if isDeleted && !wasDeleted {
+		return constants.AuditActionDelete
+	}
+	if wasDeleted && !isDeleted {
+		return constants.AuditActionUndelete
+	}
+	return constants.AuditActionUpdate
+}
+
+// isSoftDeleted returns true if fields describe a record that has
+// been marked deleted or deactivated.
+func isSoftDeleted(fields map[string]interface{}) bool {
+	if fields["state"] == constants.StateDeleted {
+		return true
+	}
+	deactivatedAt, ok := fields["deactivated_at"].(string)
+	return ok && deactivatedAt != "" && !strings.HasPrefix(deactivatedAt, "0001-01-01")
+}
+
+// auditFieldsChanged returns true if any field other than updated_at
+// differs between before and after.
+func auditFieldsChanged(before, after map[string]interface{}) bool {
+	for _, change := range (&AuditLog{Before: before, After: after}).Changes() {
+		if change.Field != "updated_at" {
+			return true
+		}
+	}
+	return false
+}
+
+// auditInstitutionID returns the id of the institution that owns the
+// changed record, so institutional admins can see changes to their
+// own records. This returns zero for records that belong to no
+// institution, such as storage options.
+func auditInstitutionID(tx *pg.Tx, model interface{}, fields map[string]interface{}) (int64, error) {
+	switch m := model.(type) {
+	case *Institution:
+		return m.ID, nil
+	case *APIKey:
+		user := &User{}
+		err := tx.Model(user).Column("institution_id").Where("id = ?", m.UserID).Select()
+		return user.InstitutionID, err
+	}
+	return auditInt64(fields["institution_id"]), nil
+}
+
+// auditInt64 converts a JSON number to int64. It returns zero for
+// anything else.
+func auditInt64(value interface{}) int64 {
+	if f, ok := value.(float64); ok {
+		return int64(f)
+	}
+	return 0
+}
@@ -0,0 +1,227 @@
+package pgmodels_test
+
+import (
+	"testing"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogChanges(t *testing.T) {
+	entry := &pgmodels.AuditLog{
+		Before: map[string]interface{}{
+			"id":    float64(3),
+			"name":  "Old Name",
+			"phone": "14345551212",
+		},
+		After: map[string]interface{}{
+			"id":   float64(3),
+			"name": "New Name",
+			"role": "institutional_user",
+		},
+	}
+	changes := entry.Changes()
+	require.Equal(t, 3, len(changes))
+	assert.Equal(t, &pgmodels.AuditLogChange{Field: "name", Before: "Old Name", After: "New Name"}, changes[0])
+	assert.Equal(t, &pgmodels.AuditLogChange{Field: "phone", Before: "14345551212"}, changes[1])
+	assert.Equal(t, &pgmodels.AuditLogChange{Field: "role", After: "institutional_user"}, changes[2])
+
+	// For inserts, every field is a change.
+	entry.Before = nil
+	assert.Equal(t, 3, len(entry.Changes()))
+}
+
+func TestAuditLogByID(t *testing.T) {
+	db.LoadFixtures()
+	entry, err := pgmodels.AuditLogByID(3)
+	require.Nil(t, err)
+	assert.Equal(t, "admin@inst2.edu", entry.ActorEmail)
+	assert.Equal(t, constants.AuditActionDelete, entry.Action)
+	assert.Equal(t, "User", entry.ModelType)
+	assert.EqualValues(t, 7, entry.ModelID)
+	assert.EqualValues(t, 3, entry.InstitutionID)
+	assert.Equal(t, "2021-06-03T10:00:00Z", entry.After["deactivated_at"])
+
+	assert.Equal(t, common.ErrNotSupported, entry.Save())
+}
+
+func TestAuditLogSelect(t *testing.T) {
+	db.LoadFixtures()
+	query := pgmodels.NewQuery().
+		Where("institution_id", "=", 2).
+		Where("model_type", "=", "User").
+		OrderBy("created_at", "desc")
+	entries, err := pgmodels.AuditLogSelect(query)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.EqualValues(t, 1, entries[0].ID)
+}
+
+func TestAuditLogRecordsInsertAndUpdate(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	actor := &pgmodels.AuditActor{
+		UserID:     2,
+		UserEmail:  "admin@inst1.edu",
+		RemoteAddr: "10.0.0.2",
+		Method:     "POST",
+		Route:      "/legal_holds/new",
+	}
+	hold := &pgmodels.LegalHold{
+		InstitutionID:        2,
+		IntellectualObjectID: 1,
+		Reason:               "Litigation",
+		PlacedByID:           2,
+	}
+	require.Nil(t, pgmodels.AuditChangesBy(hold, actor))
+	require.Nil(t, hold.Save())
+
+	entry := lastAuditLogFor(t, "LegalHold", hold.ID)
+	assert.Equal(t, constants.AuditActionInsert, entry.Action)
+	assert.EqualValues(t, 2, entry.ActorID)
+	assert.Equal(t, "admin@inst1.edu", entry.ActorEmail)
+	assert.Equal(t, "10.0.0.2", entry.RemoteAddr)
+	assert.Equal(t, "POST", entry.Method)
+	assert.Equal(t, "/legal_holds/new", entry.Route)
+	assert.EqualValues(t, 2, entry.InstitutionID)
+	assert.Nil(t, entry.Before)
+	assert.Equal(t, "Litigation", entry.After["reason"])
+
+	// Updates are compared to the hold as it was when we passed it
+	// to AuditChangesBy, or as we last saved it.
+	hold, err := pgmodels.LegalHoldByID(hold.ID)
+	require.Nil(t, err)
+	require.Nil(t, pgmodels.AuditChangesBy(hold, actor))
+	hold.Reason = "Litigation, round two"
+	require.Nil(t, hold.Save())
+	entry = lastAuditLogFor(t, "LegalHold", hold.ID)
+	assert.Equal(t, constants.AuditActionUpdate, entry.Action)
+	assert.Equal(t, "Litigation", entry.Before["reason"])
+	assert.Equal(t, "Litigation, round two", entry.After["reason"])
+
+	// Saving without changes should not add an entry.
+	lastID := entry.ID
+	require.Nil(t, hold.Save())
+	entry = lastAuditLogFor(t, "LegalHold", hold.ID)
+	assert.Equal(t, lastID, entry.ID)
+}
+
+func TestAuditLogSkipsUnauditedChanges(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	// Auditable models saved without an actor, such as work items
+	// that preservation services update during ingest, are not
+	// audited.
+	hold := &pgmodels.LegalHold{
+		InstitutionID:        2,
+		IntellectualObjectID: 1,
+		Reason:               "Litigation",
+		PlacedByID:           2,
+	}
+	require.Nil(t, hold.Save())
+	assert.Equal(t, 0, auditLogCountFor(t, "LegalHold", hold.ID))
+
+	// Neither are models that don't embed AuditedModel, such as
+	// Premis events.
+	event, err := pgmodels.PremisEventByID(1)
+	require.Nil(t, err)
+	event.ID = 0
+	event.Identifier = "2f0f4ef4-4e62-4d1b-a5ae-9bdf40d7a1d3"
+	require.Nil(t, event.Save())
+	assert.Equal(t, 0, auditLogCountFor(t, "PremisEvent", event.ID))
+}
+
+func TestAuditLogRecordsSoftDelete(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	actor := &pgmodels.AuditActor{
+		UserID:    2,
+		UserEmail: "admin@inst1.edu",
+	}
+	user, err := pgmodels.UserByEmail("user@inst1.edu")
+	require.Nil(t, err)
+
+	require.Nil(t, pgmodels.AuditChangesBy(user, actor))
+	require.Nil(t, user.Delete())
+	entry := lastAuditLogFor(t, "User", user.ID)
+	assert.Equal(t, constants.AuditActionDelete, entry.Action)
+	assert.EqualValues(t, user.InstitutionID, entry.InstitutionID)
+	assert.EqualValues(t, 2, entry.ActorID)
+
+	// Secrets never appear in the audit log.
+	assert.NotContains(t, entry.After, "encrypted_password")
+	assert.NotContains(t, entry.Before, "encrypted_password")
+
+	require.Nil(t, user.Undelete())
+	entry = lastAuditLogFor(t, "User", user.ID)
+	assert.Equal(t, constants.AuditActionUndelete, entry.Action)
+
+	obj, err := pgmodels.IntellectualObjectByID(1)
+	require.Nil(t, err)
+	require.Nil(t, pgmodels.AuditChangesBy(obj, actor))
+	obj.State = constants.StateDeleted
+	require.Nil(t, obj.Save())
+	entry = lastAuditLogFor(t, "IntellectualObject", obj.ID)
+	assert.Equal(t, constants.AuditActionDelete, entry.Action)
+	assert.Equal(t, constants.StateActive, entry.Before["state"])
+	assert.Equal(t, constants.StateDeleted, entry.After["state"])
+}
+
+func TestAuditLogRecordsHardDelete(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	actor := &pgmodels.AuditActor{
+		UserID:    2,
+		UserEmail: "admin@inst1.edu",
+	}
+	hook := pgmodels.NewWebhook(2)
+	hook.URL = "https://example.com/audit-hook"
+	hook.EventTypes = []string{constants.WebhookEventFixityFailed}
+	require.Nil(t, pgmodels.AuditChangesBy(hook, actor))
+	require.Nil(t, hook.Save())
+	entry := lastAuditLogFor(t, "Webhook", hook.ID)
+	assert.Equal(t, constants.AuditActionInsert, entry.Action)
+
+	// The secret is fingerprinted, not logged.
+	fingerprint := entry.After["secret_fingerprint"]
+	assert.Len(t, fingerprint, 12)
+	assert.NotContains(t, entry.After, "secret")
+
+	hook, err := pgmodels.WebhookByID(hook.ID)
+	require.Nil(t, err)
+	require.Nil(t, pgmodels.AuditChangesBy(hook, actor))
+	require.Nil(t, hook.Delete())
+	entry = lastAuditLogFor(t, "Webhook", hook.ID)
+	assert.Equal(t, constants.AuditActionDelete, entry.Action)
+	assert.EqualValues(t, 2, entry.InstitutionID)
+	assert.Equal(t, "https://example.com/audit-hook", entry.Before["url"])
+	assert.Equal(t, fingerprint, entry.Before["secret_fingerprint"])
+	assert.Nil(t, entry.After)
+}
+
+func lastAuditLogFor(t *testing.T, modelType string, modelID int64) *pgmodels.AuditLog {
+	query := pgmodels.NewQuery().
+		Where("model_type", "=", modelType).
+		Where("model_id", "=", modelID).
+		OrderBy("id", "desc")
+	entry, err := pgmodels.AuditLogGet(query)
+	require.Nil(t, err)
+	return entry
+}
+
+func auditLogCountFor(t *testing.T, modelType string, modelID int64) int {
+	query := pgmodels.NewQuery().
+		Where("model_type", "=", modelType).
+		Where("model_id", "=", modelID)
+	count, err := query.Count(&pgmodels.AuditLog{})
+	require.Nil(t, err)
+	return count
+}
@@ -0,0 +1,109 @@
+package pgmodels
+
+import (
+	"strings"
+
+	"github.com/APTrust/registry/common"
+)
+
+const (
+	ErrBagGroupInstID     = "Bag group requires a valid institution id."
+	ErrBagGroupIdentifier = "Bag group requires an identifier."
+)
+
+// BagGroup is a collection of intellectual objects that share the
+// same BagGroupIdentifier at one institution. Depositors set the
+// bag group identifier in bag-info.txt. Bag group identifiers are
+// unique within an institution, but not across institutions.
+//
+// IntellectualObject.Save creates bag groups as needed, so there's
+// generally no need to create them directly. Use BagGroupView to
+// get counts and sizes.
+type BagGroup struct {
+	TimestampModel
+	InstitutionID int64        `json:"institution_id" pg:"institution_id"`
+	Identifier    string       `json:"identifier" pg:"identifier"`
+	Institution   *Institution `json:"-" pg:"rel:has-one"`
+}
+
+// BagGroupByID returns the bag group with the specified id.
+// Returns pg.ErrNoRows if there is no match.
+func BagGroupByID(id int64) (*BagGroup, error) {
+	query := NewQuery().Where(`"bag_group"."id"`, "=", id)
+	return BagGroupGet(query)
+}
+
+// BagGroupByIdentifier returns the bag group with the specified
+// identifier at the specified institution.
+// Returns pg.ErrNoRows if there is no match.
+func BagGroupByIdentifier(institutionID int64, identifier string) (*BagGroup, error) {
+	query := NewQuery().
+		Where(`"bag_group"."institution_id"`, "=", institutionID).
+		Where(`"bag_group"."identifier"`, "=", identifier)
+	return BagGroupGet(query)
+}
+
+// BagGroupGet returns the first bag group matching the query.
+func BagGroupGet(query *Query) (*BagGroup, error) {
+	var bagGroup BagGroup
+	err := query.Select(&bagGroup)
+	return &bagGroup, err
+}
+
+// BagGroupSelect returns all bag groups matching the query.
+func BagGroupSelect(query *Query) ([]*BagGroup, error) {
+	var bagGroups []*BagGroup
+	err := query.Select(&bagGroups)
+	return bagGroups, err
+}
+
+// EnsureBagGroup creates a bag group with the specified identifier at
+// the specified institution if it doesn't already exist. This is a
+// no-op if identifier is empty.
+func EnsureBagGroup(institutionID int64, identifier string) error {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return nil
+	}
+	bagGroup := &BagGroup{
+		InstitutionID: institutionID,
+		Identifier:    identifier,
+	}
+	bagGroup.SetTimestamps()
+	if valErr := bagGroup.Validate(); valErr != nil {
+		return valErr
+	}
+	_, err := common.Context().DB.Model(bagGroup).
+		OnConflict("(institution_id, identifier) DO NOTHING").
+		Insert()
+	return err
+}
+
+// Save saves this bag group to the database. This will peform an insert
+// if BagGroup.ID is zero. Otherwise, it updates.
+func (bg *BagGroup) Save() error {
+	bg.SetTimestamps()
+	err := bg.Validate()
+	if err != nil {
+		return err
+	}
+	if bg.ID == int64(0) {
+		return insert(bg)
+	}
+	return update(bg)
+}
+
+// Validate returns errors if this bag group is not valid.
+func (bg *BagGroup) Validate() *common.ValidationError {
+	errors := make(map[string]string)
+	if bg.InstitutionID < 1 {
+		errors["InstitutionID"] = ErrBagGroupInstID
+	}
+	if strings.TrimSpace(bg.Identifier) == "" {
+		errors["Identifier"] = ErrBagGroupIdentifier
+	}
+	if len(errors) > 0 {
+		return &common.ValidationError{Errors: errors}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package pgmodels_test
+
+import (
+	"testing"
+
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBagGroupValidate(t *testing.T) {
+	bagGroup := &pgmodels.BagGroup{}
+	err := bagGroup.Validate()
+	require.NotNil(t, err)
+	assert.Equal(t, pgmodels.ErrBagGroupInstID, err.Errors["InstitutionID"])
+	assert.Equal(t, pgmodels.ErrBagGroupIdentifier, err.Errors["Identifier"])
+
+	bagGroup = &pgmodels.BagGroup{InstitutionID: 2, Identifier: "carolina-9"}
+	assert.Nil(t, bagGroup.Validate())
+}
+
+func TestBagGroupByIdentifier(t *testing.T) {
+	db.LoadFixtures()
+	bagGroup, err := pgmodels.BagGroupByIdentifier(2, "carolina-1")
+	require.Nil(t, err)
+	require.NotNil(t, bagGroup)
+	assert.Equal(t, int64(1), bagGroup.ID)
+
+	bagGroup, err = pgmodels.BagGroupByID(3)
+	require.Nil(t, err)
+	assert.Equal(t, "dakota-1", bagGroup.Identifier)
+	assert.Equal(t, int64(3), bagGroup.InstitutionID)
+
+	// Identifiers are unique only within an institution.
+	_, err = pgmodels.BagGroupByIdentifier(3, "carolina-1")
+	assert.True(t, pgmodels.IsNoRowError(err))
+}
+
+func TestEnsureBagGroup(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	// No-op for existing groups and empty identifiers.
+	require.Nil(t, pgmodels.EnsureBagGroup(2, "carolina-1"))
+	require.Nil(t, pgmodels.EnsureBagGroup(2, ""))
+	count, err := pgmodels.NewQuery().Count(&pgmodels.BagGroup{})
+	require.Nil(t, err)
+	assert.Equal(t, 4, count)
+
+	require.Nil(t, pgmodels.EnsureBagGroup(3, "carolina-1"))
+	bagGroup, err := pgmodels.BagGroupByIdentifier(3, "carolina-1")
+	require.Nil(t, err)
+	assert.True(t, bagGroup.ID > 4)
+	assert.False(t, bagGroup.CreatedAt.IsZero())
+}
+
+func TestIntellectualObjectSaveCreatesBagGroup(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	obj, err := pgmodels.IntellectualObjectByID(4)
+	require.Nil(t, err)
+	obj.BagGroupIdentifier = "dakota-3"
+	require.Nil(t, obj.Save())
+
+	bagGroup, err := pgmodels.BagGroupByIdentifier(obj.InstitutionID, "dakota-3")
+	require.Nil(t, err)
+	assert.Equal(t, obj.InstitutionID, bagGroup.InstitutionID)
+
+	bagGroupView, err := pgmodels.BagGroupViewByID(bagGroup.ID)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), bagGroupView.ObjectCount)
+}
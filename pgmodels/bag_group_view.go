@@ -0,0 +1,105 @@
+package pgmodels
+
+import (
+	"time"
+
+	"github.com/APTrust/registry/common"
+)
+
+// BagGroupFilters describes the allowed filters for searching bag groups.
+var BagGroupFilters = []string{
+	"identifier",
+	"identifier__starts_with",
+	"institution_id",
+	"institution_parent_id",
+	"last_ingested_at__gteq",
+	"last_ingested_at__lteq",
+	"object_count__gteq",
+	"object_count__lteq",
+	"size__gteq",
+	"size__lteq",
+	"updated_at__gteq",
+	"updated_at__lteq",
+}
+
+// BagGroupView is a read-only model describing a bag group along with
+// the number of active objects and files in the group, their total size,
+// and the date we last ingested an object in the group.
+type BagGroupView struct {
+	tableName             struct{}  `pg:"bag_groups_view"`
+	ID                    int64     `json:"id"`
+	InstitutionID         int64     `json:"institution_id"`
+	Identifier            string    `json:"identifier"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+	InstitutionName       string    `json:"institution_name"`
+	InstitutionIdentifier string    `json:"institution_identifier"`
+	InstitutionParentID   int64     `json:"institution_parent_id"`
+	ObjectCount           int64     `json:"object_count"`
+	FileCount             int64     `json:"file_count"`
+	Size                  int64     `json:"size"`
+	LastIngestedAt        time.Time `json:"last_ingested_at"`
+
+	// StorageOptions breaks down the group's contents by storage option.
+	// This is set only on the bag group show endpoints. Call
+	// LoadStorageOptionStats to set it.
+	StorageOptions []*BagGroupStorageStats `json:"storage_options,omitempty" pg:"-"`
+}
+
+// BagGroupStorageStats describes the active objects and files in one
+// storage option in a bag group.
+type BagGroupStorageStats struct {
+	StorageOption string `json:"storage_option"`
+	ObjectCount   int64  `json:"object_count"`
+	FileCount     int64  `json:"file_count"`
+	Size          int64  `json:"size"`
+}
+
+// BagGroupViewByID returns the bag group with the specified id.
+// Returns pg.ErrNoRows if there is no match.
+func BagGroupViewByID(id int64) (*BagGroupView, error) {
+	query := NewQuery().Where(`"bag_group_view"."id"`, "=", id)
+	return BagGroupViewGet(query)
+}
+
+// BagGroupViewGet returns the first bag group matching the query.
+func BagGroupViewGet(query *Query) (*BagGroupView, error) {
+	var bagGroup BagGroupView
+	err := query.Select(&bagGroup)
+	return &bagGroup, err
+}
+
+// BagGroupViewSelect returns all bag groups matching the query.
+func BagGroupViewSelect(query *Query) ([]*BagGroupView, error) {
+	var bagGroups []*BagGroupView
+	err := query.Select(&bagGroups)
+	return bagGroups, err
+}
+
+func (bg *BagGroupView) GetID() int64 {
+	return bg.ID
+}
+
+// LoadStorageOptionStats sets StorageOptions to a breakdown of the
+// group's active objects and files by storage option, sorted by
+// storage option.
+func (bg *BagGroupView) LoadStorageOptionStats() error {
+	query := `select io.storage_option,
+		count(distinct io.id) as object_count,
+		count(gf.id) as file_count,
+		coalesce(sum(gf."size"), 0) as "size"
+	from intellectual_objects io
+	left join generic_files gf on gf.intellectual_object_id = io.id and gf.state = 'A'
+	where io.institution_id = ?
+	and io.bag_group_identifier = ?
+	and io.state = 'A'
+	group by io.storage_option
+	order by io.storage_option`
+	stats := make([]*BagGroupStorageStats, 0)
+	_, err := common.Context().DB.Query(&stats, query, bg.InstitutionID, bg.Identifier)
+	if err != nil {
+		return err
+	}
+	bg.StorageOptions = stats
+	return nil
+}
@@ -0,0 +1,71 @@
+package pgmodels_test
+
+import (
+	"testing"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBagGroupViewByID(t *testing.T) {
+	db.LoadFixtures()
+	bagGroup, err := pgmodels.BagGroupViewByID(1)
+	require.Nil(t, err)
+	require.NotNil(t, bagGroup)
+	assert.Equal(t, int64(1), bagGroup.GetID())
+	assert.Equal(t, "carolina-1", bagGroup.Identifier)
+	assert.Equal(t, "Institution One", bagGroup.InstitutionName)
+	assert.Equal(t, int64(2), bagGroup.ObjectCount)
+	assert.Equal(t, int64(6), bagGroup.FileCount)
+	assert.Equal(t, int64(64399780000), bagGroup.Size)
+	assert.Equal(t, "2016-08-24", bagGroup.LastIngestedAt.Format("2006-01-02"))
+
+	// No ingest work items for this group.
+	bagGroup, err = pgmodels.BagGroupViewByID(3)
+	require.Nil(t, err)
+	assert.True(t, bagGroup.LastIngestedAt.IsZero())
+}
+
+func TestBagGroupViewSelect(t *testing.T) {
+	db.LoadFixtures()
+	query := pgmodels.NewQuery().
+		Where("institution_id", "=", 2).
+		OrderBy("identifier", "asc")
+	bagGroups, err := pgmodels.BagGroupViewSelect(query)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(bagGroups))
+	assert.Equal(t, "carolina-1", bagGroups[0].Identifier)
+	assert.Equal(t, "carolina-2", bagGroups[1].Identifier)
+
+	query = pgmodels.NewQuery().Where("identifier", "=", "dakota-2")
+	bagGroup, err := pgmodels.BagGroupViewGet(query)
+	require.Nil(t, err)
+	assert.Equal(t, int64(4), bagGroup.ID)
+}
+
+func TestBagGroupViewLoadStorageOptionStats(t *testing.T) {
+	db.LoadFixtures()
+	bagGroup, err := pgmodels.BagGroupViewByID(2)
+	require.Nil(t, err)
+	assert.Empty(t, bagGroup.StorageOptions)
+
+	require.Nil(t, bagGroup.LoadStorageOptionStats())
+	require.Equal(t, 2, len(bagGroup.StorageOptions))
+
+	deepOH := bagGroup.StorageOptions[0]
+	assert.Equal(t, constants.StorageOptionGlacierDeepOH, deepOH.StorageOption)
+	assert.Equal(t, int64(1), deepOH.ObjectCount)
+	assert.Equal(t, int64(3), deepOH.FileCount)
+	assert.Equal(t, int64(407188060000), deepOH.Size)
+
+	standard := bagGroup.StorageOptions[1]
+	assert.Equal(t, constants.StorageOptionStandard, standard.StorageOption)
+	assert.Equal(t, int64(1), standard.ObjectCount)
+	assert.Equal(t, int64(4), standard.FileCount)
+	assert.Equal(t, int64(13779270000), standard.Size)
+
+	assert.Equal(t, bagGroup.Size, deepOH.Size+standard.Size)
+}
@@ -133,6 +133,11 @@ func CanCountFromView(query *Query, model interface{}) bool {
 		return false
 	}
 
+	if query.IncludesOrCondition() {
+		common.Context().Log.Debug().Msgf("Cannot query count view for type %s because this specific query contains an OR clause", typeName)
+		return false
+	}
+
 	// Our views only contain certain counts. If the filters in
 	// the where clause are too specific, the view won't have
 	// counts for them, and we'll have to do a regular SQL count().
@@ -58,6 +58,10 @@ func TestCanCountFromView(t *testing.T) {
 	assert.False(t, pgmodels.CanCountFromView(q, pgmodels.WorkItem{}))
 	assert.False(t, pgmodels.CanCountFromView(q, pgmodels.WorkItemView{}))
 
+	// OR conditions can match more than one row in the count views.
+	qOr := pgmodels.NewQuery().Or([]string{"institution_id", "state"}, []string{"=", "="}, []interface{}{3, "A"})
+	assert.False(t, pgmodels.CanCountFromView(qOr, pgmodels.IntellectualObjectView{}))
+
 	// Action is present only in the WorkItem count view
 	q2 := pgmodels.NewQuery().Where("institution_id", "=", 3)
 	q2.Where("action", "=", constants.ActionIngest)
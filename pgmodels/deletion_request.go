@@ -32,6 +32,7 @@ func init() {
 
 type DeletionRequest struct {
 	BaseModel
+	AuditedModel
 	InstitutionID              int64                 `json:"institution_id"`
 	RequestedByID              int64                 `json:"-"`
 	RequestedAt                time.Time             `json:"requested_at"`
@@ -125,12 +126,12 @@ func (request *DeletionRequest) Save() error {
 	}
 	registryContext := common.Context()
 	db := registryContext.DB
-	return db.RunInTransaction(db.Context(), func(tx *pg.Tx) error {
+	txErr := db.RunInTransaction(db.Context(), func(tx *pg.Tx) error {
 		var err error
 		if request.ID == 0 {
-			_, err = tx.Model(request).Insert()
+			err = auditedInsert(tx, request)
 		} else {
-			_, err = tx.Model(request).WherePK().Update()
+			err = auditedUpdate(tx, request)
 		}
 		if err != nil {
 			registryContext.Log.Error().Msgf("Transaction failed. Model: %v. Error: %v", request, err)
@@ -138,6 +139,39 @@ func (request *DeletionRequest) Save() error {
 		}
 		return request.saveRelations(tx)
 	})
+	if txErr != nil {
+		return txErr
+	}
+	for _, item := range request.WorkItems {
+		item.afterSave()
+	}
+	return nil
+}
+
+// SaveAndQueueWorkItems saves this request and its WorkItems and, in the
+// same transaction, adds an NSQ outbox message for each item. If any of
+// that fails, none of it is saved, so we can't end up deleting some of
+// the request's files and objects but not others. After the transaction
+// commits, this tries to deliver the messages. See SaveAndEnqueue.
+func (request *DeletionRequest) SaveAndQueueWorkItems() error {
+	for _, item := range request.WorkItems {
+		topic, err := constants.TopicFor(item.Action, item.Stage)
+		if err != nil {
+			return err
+		}
+		item.outboxTopic = topic
+		item.outboxMessage = nil
+	}
+	err := request.Save()
+	for _, item := range request.WorkItems {
+		msg := item.outboxMessage
+		item.outboxTopic = ""
+		item.outboxMessage = nil
+		if err == nil {
+			item.deliverOutboxMessage(msg)
+		}
+	}
+	return err
 }
 
 // Validation enforces business rules, including who can request and
@@ -297,7 +331,7 @@ func (request *DeletionRequest) saveObjects(tx *pg.Tx) error {
 func (request *DeletionRequest) saveWorkItems(tx *pg.Tx) error {
 	for _, item := range request.WorkItems {
 		item.DeletionRequestID = request.ID
-		err := item.Save()
+		err := item.saveInTransaction(tx)
 		if err != nil {
 			return err
 		}
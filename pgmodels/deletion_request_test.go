@@ -288,6 +288,51 @@ func TestDeletionRequestIncludesFilesAndObjects(t *testing.T) {
 	assert.False(t, isIncluded)
 }
 
+func TestDeletionRequestSaveAndQueueWorkItems(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	req, err := pgmodels.NewDeletionRequest()
+	require.Nil(t, err)
+	inst2Admin := testutil.InitUser(t, "admin@inst2.edu")
+	req.RequestedBy = inst2Admin
+	req.RequestedByID = inst2Admin.ID
+	req.InstitutionID = inst2Admin.InstitutionID
+	req.RequestedAt = time.Now().UTC()
+	obj := pgmodels.RandomObject()
+	obj.InstitutionID = inst2Admin.InstitutionID
+	require.NoError(t, obj.Save())
+	req.AddObject(obj)
+	require.NoError(t, req.Save())
+
+	newItem := func(name string) *pgmodels.WorkItem {
+		item := pgmodels.RandomWorkItem(name, constants.ActionDelete, obj.ID, 0)
+		item.InstitutionID = inst2Admin.InstitutionID
+		return item
+	}
+	itemsForRequest := func() []*pgmodels.WorkItem {
+		items, err := pgmodels.WorkItemSelect(pgmodels.NewQuery().Where("deletion_request_id", "=", req.ID))
+		require.Nil(t, err)
+		return items
+	}
+
+	// If one item is invalid, none of them should be saved or queued.
+	invalidItem := newItem("")
+	req.WorkItems = []*pgmodels.WorkItem{newItem("delete_me.tar"), invalidItem}
+	require.NotNil(t, req.SaveAndQueueWorkItems())
+	assert.Empty(t, itemsForRequest())
+
+	req.WorkItems = []*pgmodels.WorkItem{newItem("delete_me.tar"), newItem("delete_me_too.tar")}
+	require.NoError(t, req.SaveAndQueueWorkItems())
+	items := itemsForRequest()
+	require.Equal(t, 2, len(items))
+	for _, item := range items {
+		msg, err := pgmodels.NSQOutboxMessageGet(pgmodels.NewQuery().Where("work_item_id", "=", item.ID))
+		require.Nil(t, err)
+		assert.Equal(t, constants.TopicDelete, msg.Topic)
+	}
+}
+
 func TestDeletionRequestConfirm(t *testing.T) {
 	user, err := pgmodels.UserByID(5)
 	require.Nil(t, err)
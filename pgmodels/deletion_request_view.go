@@ -5,6 +5,8 @@ import (
 )
 
 var DeletionRequestFilters = []string{
+	"cancelled_at__is_null",
+	"confirmed_at__is_null",
 	"institution_id",
 	"requested_at__gteq",
 	"requested_at__lteq",
@@ -10,6 +10,8 @@ import (
 // Note: chart_metric and report_type are ignored by backend.
 // Used only in front-end.
 var DepositStatsFilters = []string{
+	"bag_group_id",
+	"bag_group_identifier",
 	"chart_metric",
 	"end_date",
 	"institution_id",
@@ -116,6 +118,83 @@ func DepositStatsOverTime(institutionID int64, storageOption string, startDate,
 	return stats, err
 }
 
+// DepositStatsForBagGroup returns info about materials in the specified
+// bag group that were deposited before endDate, broken down by storage
+// option, with a Total row at the end. Unlike DepositStatsSelect, this
+// runs a live query, since we don't cache deposit stats for bag groups.
+// To report on all storage options, pass an empty string for storageOption.
+func DepositStatsForBagGroup(bagGroupID int64, storageOption string, endDate time.Time) ([]*DepositStats, error) {
+	stats, err := selectBagGroupDepositStats(bagGroupID, storageOption, endDate, endDate)
+	if len(stats) == 0 {
+		stats = []*DepositStats{
+			{
+				InstitutionName: "Total",
+				StorageOption:   "Total",
+				EndDate:         endDate,
+			},
+		}
+	}
+	return stats, err
+}
+
+// DepositStatsOverTimeForBagGroup returns month-end deposit stats for
+// the specified bag group for each first-of-month date between startDate
+// and endDate. Like the historical deposit stats, each row describes
+// files deposited before its end date.
+func DepositStatsOverTimeForBagGroup(bagGroupID int64, storageOption string, startDate, endDate time.Time) ([]*DepositStats, error) {
+	firstDate := time.Date(startDate.Year(), startDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if firstDate.Before(startDate) {
+		firstDate = firstDate.AddDate(0, 1, 0)
+	}
+	return selectBagGroupDepositStats(bagGroupID, storageOption, firstDate, endDate)
+}
+
+func selectBagGroupDepositStats(bagGroupID int64, storageOption string, firstDate, lastDate time.Time) ([]*DepositStats, error) {
+	var stats []*DepositStats
+	_, err := common.Context().DB.Query(&stats, bagGroupDepositStatsQuery,
+		firstDate, lastDate, bagGroupID, bagGroupID,
+		storageOption, storageOption)
+	return stats, err
+}
+
+// bagGroupDepositStatsQuery calculates deposit stats for a single bag
+// group at a series of monthly end dates. Total rows come from the rollup.
+// We calculate cost per file before rolling up, so the monthly cost of
+// each Total row is the sum of its storage options' costs.
+const bagGroupDepositStatsQuery = `select
+	i.id as institution_id,
+	i.member_institution_id,
+	i.name as institution_name,
+	coalesce(stats.storage_option, 'Total') as storage_option,
+	stats.file_count,
+	stats.object_count,
+	stats.total_bytes,
+	stats.total_bytes / 1073741824::numeric as total_gb,
+	stats.total_bytes / 1099511627776::numeric as total_tb,
+	coalesce(stats.cost_gb_per_month, 0) as cost_gb_per_month,
+	stats.monthly_cost,
+	stats.end_date
+	from (
+		select dates.end_date,
+		gf.storage_option,
+		count(gf.id) as file_count,
+		count(distinct gf.intellectual_object_id) as object_count,
+		sum(gf.size) as total_bytes,
+		case when gf.storage_option is null then null else max(so.cost_gb_per_month) end as cost_gb_per_month,
+		coalesce(sum(gf.size / 1073741824::numeric * so.cost_gb_per_month), 0) as monthly_cost
+		from generate_series(?::timestamp, ?::timestamp, interval '1 month') as dates(end_date)
+		join generic_files gf on gf.created_at < dates.end_date and gf.state = 'A'
+		join intellectual_objects io on io.id = gf.intellectual_object_id
+		join bag_groups bg on bg.institution_id = io.institution_id and bg.identifier = io.bag_group_identifier
+		left join storage_options so on so.name = gf.storage_option
+		where bg.id = ?
+		group by dates.end_date, rollup(gf.storage_option)
+	) stats
+	join bag_groups bg on bg.id = ?
+	join institutions i on i.id = bg.institution_id
+	where (? = '' or coalesce(stats.storage_option, 'Total') = ?)
+	order by stats.end_date, stats.storage_option nulls last`
+
 func getDepositTimelineQuery(institutionID int64) string {
 	// Basic depost stats query. Use the "is null / or" trick to deal with
 	// filters that may or may not be present. Also note that historical
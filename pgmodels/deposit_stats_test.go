@@ -68,3 +68,72 @@ func TestDepositStats(t *testing.T) {
 	assert.Equal(t, "Glacier-OR", stats[1].StorageOption)
 	assert.EqualValues(t, 2, stats[1].FileCount)
 }
+
+func TestDepositStatsForBagGroup(t *testing.T) {
+	db.LoadFixtures()
+
+	date2010, err := time.Parse(time.RFC3339, "2010-01-01T00:00:00Z")
+	require.Nil(t, err)
+	date2030, err := time.Parse(time.RFC3339, "2030-01-01T00:00:00Z")
+	require.Nil(t, err)
+
+	// Nothing prior to 2010
+	stats, err := pgmodels.DepositStatsForBagGroup(2, "", date2010)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(stats))
+	assert.Equal(t, "Total", stats[0].StorageOption)
+	assert.EqualValues(t, 0, stats[0].FileCount)
+
+	// Bag group 2 (carolina-2) has one Glacier-Deep-OH object
+	// and one Standard object.
+	stats, err = pgmodels.DepositStatsForBagGroup(2, "", date2030)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(stats))
+	assert.Equal(t, constants.StorageOptionGlacierDeepOH, stats[0].StorageOption)
+	assert.EqualValues(t, 3, stats[0].FileCount)
+	assert.EqualValues(t, 1, stats[0].ObjectCount)
+	assert.Equal(t, constants.StorageOptionStandard, stats[1].StorageOption)
+	assert.EqualValues(t, 4, stats[1].FileCount)
+	assert.EqualValues(t, 1, stats[1].ObjectCount)
+	assert.Equal(t, "Total", stats[2].StorageOption)
+	assert.EqualValues(t, 7, stats[2].FileCount)
+	assert.EqualValues(t, 2, stats[2].ObjectCount)
+	assert.EqualValues(t, 420967330000, stats[2].TotalBytes)
+	assert.Equal(t, "Institution One", stats[2].InstitutionName)
+	assert.InDelta(t, stats[0].MonthlyCost+stats[1].MonthlyCost, stats[2].MonthlyCost, 0.0001)
+	totalCost := stats[2].MonthlyCost
+
+	// Total only should include the cost of all storage options.
+	stats, err = pgmodels.DepositStatsForBagGroup(2, "Total", date2030)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(stats))
+	assert.InDelta(t, totalCost, stats[0].MonthlyCost, 0.0001)
+
+	stats, err = pgmodels.DepositStatsForBagGroup(2, constants.StorageOptionStandard, date2030)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(stats))
+	assert.EqualValues(t, 13779270000, stats[0].TotalBytes)
+}
+
+func TestDepositStatsOverTimeForBagGroup(t *testing.T) {
+	db.LoadFixtures()
+
+	// Fixture files were created on Jan. 12, 2021, so the
+	// Jan. 1 report has nothing, while Feb. 1 and Mar. 1
+	// each include all of the group's files.
+	startDate, err := time.Parse(time.RFC3339, "2020-12-15T00:00:00Z")
+	require.Nil(t, err)
+	endDate, err := time.Parse(time.RFC3339, "2021-03-01T00:00:00Z")
+	require.Nil(t, err)
+
+	stats, err := pgmodels.DepositStatsOverTimeForBagGroup(1, "Total", startDate, endDate)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(stats))
+	assert.Equal(t, "2021-02-01", stats[0].EndDate.Format("2006-01-02"))
+	assert.Equal(t, "2021-03-01", stats[1].EndDate.Format("2006-01-02"))
+	for _, stat := range stats {
+		assert.Equal(t, "Total", stat.StorageOption)
+		assert.EqualValues(t, 6, stat.FileCount)
+		assert.EqualValues(t, 2, stat.ObjectCount)
+	}
+}
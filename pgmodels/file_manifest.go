@@ -0,0 +1,170 @@
+package pgmodels
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/APTrust/registry/constants"
+)
+
+// FileManifestColumns are the column headers for CSV file manifests.
+// The order here matches the order of values returned by
+// FileManifestEntry.CSVRecord().
+var FileManifestColumns = []string{
+	"id",
+	"identifier",
+	"intellectual_object_id",
+	"institution_id",
+	"size",
+	"file_format",
+	"storage_option",
+	"state",
+	"uuid",
+	"mtime",
+	"created_at",
+	"updated_at",
+	"last_fixity_check",
+	constants.AlgMd5,
+	constants.AlgSha1,
+	constants.AlgSha256,
+	constants.AlgSha512,
+}
+
+// FileManifestChecksum is a slimmed-down Checksum for file manifests.
+type FileManifestChecksum struct {
+	Algorithm string    `json:"algorithm"`
+	Digest    string    `json:"digest"`
+	DateTime  time.Time `json:"datetime"`
+}
+
+// FileManifestEntry describes a single file in an object or filtered
+// file manifest. Depositors use these for audits, so they include
+// everything we know about a file's content and storage, along with
+// all of its checksums.
+type FileManifestEntry struct {
+	ID                   int64                   `json:"id"`
+	Identifier           string                  `json:"identifier"`
+	IntellectualObjectID int64                   `json:"intellectual_object_id"`
+	InstitutionID        int64                   `json:"institution_id"`
+	Size                 int64                   `json:"size"`
+	FileFormat           string                  `json:"file_format"`
+	StorageOption        string                  `json:"storage_option"`
+	State                string                  `json:"state"`
+	UUID                 string                  `json:"uuid"`
+	ModTime              time.Time               `json:"mtime"`
+	CreatedAt            time.Time               `json:"created_at"`
+	UpdatedAt            time.Time               `json:"updated_at"`
+	LastFixityCheck      time.Time               `json:"last_fixity_check"`
+	Checksums            []*FileManifestChecksum `json:"checksums"`
+}
+
+// NewFileManifestEntry creates a manifest entry from a GenericFile.
+// The file's Checksums relation should be loaded.
+func NewFileManifestEntry(gf *GenericFile) *FileManifestEntry {
+	checksums := make([]*FileManifestChecksum, len(gf.Checksums))
+	for i, cs := range gf.Checksums {
+		checksums[i] = &FileManifestChecksum{
+			Algorithm: cs.Algorithm,
+			Digest:    cs.Digest,
+			DateTime:  cs.DateTime,
+		}
+	}
+	return &FileManifestEntry{
+		ID:                   gf.ID,
+		Identifier:           gf.Identifier,
+		IntellectualObjectID: gf.IntellectualObjectID,
+		InstitutionID:        gf.InstitutionID,
+		Size:                 gf.Size,
+		FileFormat:           gf.FileFormat,
+		StorageOption:        gf.StorageOption,
+		State:                gf.State,
+		UUID:                 gf.UUID,
+		ModTime:              gf.ModTime,
+		CreatedAt:            gf.CreatedAt,
+		UpdatedAt:            gf.UpdatedAt,
+		LastFixityCheck:      gf.LastFixityCheck,
+		Checksums:            checksums,
+	}
+}
+
+// LatestDigest returns the most recent digest for the specified
+// algorithm, or an empty string if the file has no checksum of
+// that type. Reingested files may have several checksums for the
+// same algorithm.
+func (entry *FileManifestEntry) LatestDigest(alg string) string {
+	var latest *FileManifestChecksum
+	for _, cs := range entry.Checksums {
+		if cs.Algorithm == alg && (latest == nil || cs.DateTime.After(latest.DateTime)) {
+			latest = cs
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+	return latest.Digest
+}
+
+// CSVRecord returns this entry as a list of strings, in the order
+// of FileManifestColumns. Since CSV has one row per file, this includes
+// only the latest digest for each algorithm.
+func (entry *FileManifestEntry) CSVRecord() []string {
+	return []string{
+		strconv.FormatInt(entry.ID, 10),
+		entry.Identifier,
+		strconv.FormatInt(entry.IntellectualObjectID, 10),
+		strconv.FormatInt(entry.InstitutionID, 10),
+		strconv.FormatInt(entry.Size, 10),
+		entry.FileFormat,
+		entry.StorageOption,
+		entry.State,
+		entry.UUID,
+		manifestTime(entry.ModTime),
+		manifestTime(entry.CreatedAt),
+		manifestTime(entry.UpdatedAt),
+		manifestTime(entry.LastFixityCheck),
+		entry.LatestDigest(constants.AlgMd5),
+		entry.LatestDigest(constants.AlgSha1),
+		entry.LatestDigest(constants.AlgSha256),
+		entry.LatestDigest(constants.AlgSha512),
+	}
+}
+
+func manifestTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// FileManifestEach runs fn on every GenericFile matching query, in
+// order of id. It loads files in batches of batchSize, with their
+// checksums, so we can stream manifests for objects with hundreds of
+// thousands of files without holding them all in memory.
+//
+// Query should contain only where conditions. This function adds its
+// own ordering and limits. It stops and returns the first error from
+// fn or from the database.
+func FileManifestEach(query *Query, batchSize int, fn func(*FileManifestEntry) error) error {
+	lastID := int64(0)
+	for {
+		batchQuery := query.CopyForCount().
+			WhereAfter("id", "asc", lastID, lastID).
+			Relations("Checksums").
+			OrderBy("id", "asc").
+			Limit(batchSize)
+		var files []*GenericFile
+		err := batchQuery.Select(&files)
+		if err != nil {
+			return err
+		}
+		for _, gf := range files {
+			if err = fn(NewFileManifestEntry(gf)); err != nil {
+				return err
+			}
+			lastID = gf.ID
+		}
+		if len(files) < batchSize {
+			return nil
+		}
+	}
+}
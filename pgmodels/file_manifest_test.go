@@ -0,0 +1,66 @@
+package pgmodels_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileManifestEntryLatestDigest(t *testing.T) {
+	older := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	entry := &pgmodels.FileManifestEntry{
+		Checksums: []*pgmodels.FileManifestChecksum{
+			{Algorithm: constants.AlgMd5, Digest: "old-md5", DateTime: older},
+			{Algorithm: constants.AlgMd5, Digest: "new-md5", DateTime: newer},
+			{Algorithm: constants.AlgSha256, Digest: "sha256", DateTime: older},
+		},
+	}
+	assert.Equal(t, "new-md5", entry.LatestDigest(constants.AlgMd5))
+	assert.Equal(t, "sha256", entry.LatestDigest(constants.AlgSha256))
+	assert.Equal(t, "", entry.LatestDigest(constants.AlgSha512))
+
+	record := entry.CSVRecord()
+	require.Equal(t, len(pgmodels.FileManifestColumns), len(record))
+	assert.Equal(t, "new-md5", record[13])
+	assert.Equal(t, "", record[16])
+}
+
+func TestFileManifestEach(t *testing.T) {
+	db.LoadFixtures()
+
+	// Object 3 has 4 active files and 1 deleted file.
+	// Use a small batch size to force multiple batches.
+	query := pgmodels.NewQuery().Where("intellectual_object_id", "=", 3)
+	var entries []*pgmodels.FileManifestEntry
+	err := pgmodels.FileManifestEach(query, 2, func(entry *pgmodels.FileManifestEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, 5, len(entries))
+	for i, entry := range entries {
+		assert.Equal(t, int64(3), entry.IntellectualObjectID)
+		if entry.ID == 49 {
+			assert.Equal(t, 2, len(entry.Checksums))
+		}
+		if i > 0 {
+			assert.True(t, entry.ID > entries[i-1].ID)
+		}
+	}
+
+	// Query should be reusable, since we copy it for each batch.
+	query.Where("state", "=", constants.StateActive)
+	count := 0
+	err = pgmodels.FileManifestEach(query, 100, func(entry *pgmodels.FileManifestEntry) error {
+		count++
+		return nil
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 4, count)
+}
@@ -2,12 +2,20 @@ package pgmodels
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/APTrust/registry/common"
 	"github.com/APTrust/registry/constants"
+	"github.com/stretchr/stew/slice"
 )
 
+// reOrGroupKey matches query string params that belong to an OR group,
+// such as "or.status" or "or2.size__gt". See AddOrGroups.
+var reOrGroupKey = regexp.MustCompile(`^(or\d*)\.([a-z0-9_]+)$`)
+
 // FilterCollection converts query string params such as name__eq=Homer to
 // a pgmodels.Query object that allows us to build a SQL where clause as
 // we go.
@@ -39,6 +47,99 @@ func (fc *FilterCollection) Add(key string, values []string) (*ParamFilter, erro
 	return filter, nil
 }
 
+// AddToGroup adds a filter to the OR group named group. Param key is
+// a regular filter key, such as "status" or "size__gt". The collection
+// adds one filter for each value, except for "in" filters, which keep
+// all of their values. The group's filters are ORed together when the
+// collection is converted to a query, and the group as a whole is ANDed
+// with the other filters.
+//
+// Full-text search filters can't be used in OR groups.
+func (fc *FilterCollection) AddToGroup(group, key string, values []string) ([]*ParamFilter, error) {
+	filter, err := NewParamFilter(key, values)
+	if err != nil {
+		return nil, err
+	}
+	if filter.RawOp == "search" {
+		return nil, fmt.Errorf("Invalid query string param '%s.%s': search can't be used in an OR group", group, key)
+	}
+	valueSets := [][]string{values}
+	if filter.RawOp != "in" {
+		valueSets = make([][]string, 0, len(values))
+		for _, value := range values {
+			valueSets = append(valueSets, []string{value})
+		}
+	}
+	added := make([]*ParamFilter, 0, len(valueSets))
+	for _, valueSet := range valueSets {
+		if common.ListIsEmpty(valueSet) {
+			continue
+		}
+		groupFilter, _ := NewParamFilter(key, valueSet)
+		groupFilter.Key = group + "." + key
+		groupFilter.Group = group
+		fc.filters = append(fc.filters, groupFilter)
+		added = append(added, groupFilter)
+	}
+	return added, nil
+}
+
+// AddOrGroups adds the OR groups in query string params to the
+// collection. OR group params look like this:
+//
+// or.status=Failed&or.needs_admin_review=true
+//
+// which means "status = 'Failed' OR needs_admin_review = true".
+// To use more than one OR group, number them:
+//
+// or1.status=Failed&or1.status=Cancelled&or2.size__gt=1000&or2.storage_option=Glacier-OH
+//
+// which means "(status = 'Failed' OR status = 'Cancelled') AND
+// (size > 1000 OR storage_option = 'Glacier-OH')". The filter key
+// after the dot must be one of allowedFilters. This skips params that
+// aren't OR group params. It returns the filters it added, along with
+// an error describing any OR group params it could not add.
+func (fc *FilterCollection) AddOrGroups(params url.Values, allowedFilters []string) ([]*ParamFilter, error) {
+	keys := make([]string, 0)
+	for key := range params {
+		if _, _, ok := ParseOrGroupKey(key); ok {
+			keys = append(keys, key)
+		}
+	}
+	// Sort, so the where clause is the same on every request.
+	sort.Strings(keys)
+	added := make([]*ParamFilter, 0)
+	invalid := make([]string, 0)
+	for _, param := range keys {
+		group, key, _ := ParseOrGroupKey(param)
+		if !slice.Contains(allowedFilters, key) {
+			invalid = append(invalid, param)
+			continue
+		}
+		filters, err := fc.AddToGroup(group, key, params[param])
+		if err != nil {
+			invalid = append(invalid, param)
+			continue
+		}
+		added = append(added, filters...)
+	}
+	if len(invalid) > 0 {
+		return added, fmt.Errorf("Invalid OR group params: %s", strings.Join(invalid, ", "))
+	}
+	return added, nil
+}
+
+// ParseOrGroupKey splits an OR group query string param such as
+// "or2.size__gt" into its group name ("or2") and filter key
+// ("size__gt"). Param ok is false if param is not an OR group param.
+func ParseOrGroupKey(param string) (group, key string, ok bool) {
+	match := reOrGroupKey.FindStringSubmatch(param)
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], match[2], true
+}
+
 // AddOrderBy adds sort columns to the filters. Param colAndDir should
 // be in format "column_name__dir", where dir is "asc" or "desc".
 // If dir is omitted, it defaults to "asc."
@@ -55,27 +156,80 @@ func (fc *FilterCollection) HasExplicitSorting() bool {
 	return len(fc.sorts) > 0
 }
 
+// HasFilters returns true if this collection includes at least
+// one filter with a non-empty value.
+func (fc *FilterCollection) HasFilters() bool {
+	for _, filter := range fc.filters {
+		if !common.ListIsEmpty(filter.Values) {
+			return true
+		}
+	}
+	return false
+}
+
+// SortParams returns the sort params that have been added to this
+// collection, in the order they were added.
+func (fc *FilterCollection) SortParams() []*SortParam {
+	return fc.sorts
+}
+
 // ToQuery returns a query object based on the keys and values passed in.
 // The Query's WhereClause() will return the where conditions for the filters
 // passed in through Add(), and the Query's Params() method will return the
 // params. Conditions and params come back in the order they were added.
 func (fc *FilterCollection) ToQuery() (*Query, error) {
 	query := NewQuery()
+	groupNames := make([]string, 0)
+	groups := make(map[string][]*ParamFilter)
 	for _, filter := range fc.filters {
 		if common.ListIsEmpty(filter.Values) {
 			continue // no need to apply filter
 		}
+		if filter.Group != "" {
+			if _, ok := groups[filter.Group]; !ok {
+				groupNames = append(groupNames, filter.Group)
+			}
+			groups[filter.Group] = append(groups[filter.Group], filter)
+			continue
+		}
 		err := filter.AddToQuery(query)
 		if err != nil {
 			return nil, err
 		}
 	}
+	for _, name := range groupNames {
+		cols := make([]string, len(groups[name]))
+		ops := make([]string, len(groups[name]))
+		vals := make([]interface{}, len(groups[name]))
+		for i, filter := range groups[name] {
+			op, val, err := filter.orTerm()
+			if err != nil {
+				return nil, err
+			}
+			cols[i] = filter.Column
+			ops[i] = op
+			vals[i] = val
+		}
+		query.Or(cols, ops, vals)
+	}
 	for _, sort := range fc.sorts {
 		query.OrderBy(sort.Column, sort.Direction)
 	}
 	return query, nil
 }
 
+// SearchFilter returns the full-text search filter (the q param) in
+// this collection, or nil if there is no search or the search text is
+// empty.
+func (fc *FilterCollection) SearchFilter() *ParamFilter {
+	for _, pf := range fc.filters {
+		if pf.RawOp == "search" && pf.Group == "" && !common.ListIsEmpty(pf.Values) {
+			return pf
+		}
+	}
+	return nil
+}
+
 // ValueOf returns the value of the filter with the specified name.
 // Returns an empty string if the specified filter is missing or
 // has no value.
@@ -112,6 +266,10 @@ type ParamFilter struct {
 	SQLOp string
 	// Values are the values attached to Key in the query string.
 	Values []string
+	// Group is the name of the OR group this filter belongs to,
+	// such as "or" or "or2". It's empty for regular filters, which
+	// are ANDed together.
+	Group string
 }
 
 type SortParam struct {
@@ -124,6 +282,16 @@ type SortParam struct {
 // if it can't parse the key or values. The caller should log the error
 // and return a basic common.ErrInvalidParam.
 func NewParamFilter(key string, values []string) (*ParamFilter, error) {
+	// Full-text search param q searches the search_vector column.
+	if key == "q" {
+		return &ParamFilter{
+			Key:    "q",
+			Column: "search_vector",
+			RawOp:  "search",
+			SQLOp:  QueryOp["search"],
+			Values: values,
+		}, nil
+	}
 	// Parse the column and operator from the key name
 	colAndOp := strings.Split(key, "__")
 	if len(colAndOp) != 2 {
@@ -146,6 +314,12 @@ func NewParamFilter(key string, values []string) (*ParamFilter, error) {
 
 // ChipLabel returns a label to display on filter chips in the web UI.
 func (p *ParamFilter) ChipLabel() string {
+	if p.RawOp == "search" {
+		return "Search"
+	}
+	if p.Group != "" {
+		return fmt.Sprintf("%s (%s)", strings.Title(p.Column), p.Group)
+	}
 	return strings.Title(p.Column)
 }
 
@@ -153,6 +327,8 @@ func (p *ParamFilter) ChipLabel() string {
 func (p *ParamFilter) ChipValue() string {
 	if p.SQLOp == "IS NULL" || p.SQLOp == "IS NOT NULL" {
 		return p.SQLOp
+	} else if p.RawOp == "search" {
+		return strings.Join(p.Values, " ")
 	} else if len(p.Values) > 0 && p.Values[0] == constants.DefaultProfileIdentifier {
 		return "APTrust"
 	} else if len(p.Values) > 0 && p.Values[0] == constants.BTRProfileIdentifier {
@@ -222,12 +398,42 @@ func (pf *ParamFilter) AddToQuery(q *Query) error {
 		}
 	case "in":
 		q.WhereIn(pf.Column, pf.InterfaceValues()...)
+	case "search":
+		q.Search(pf.Column, pf.Values[0])
 	default:
 		return fmt.Errorf("Invalid query string param '%s': unknown operator '%s'", pf.Key, pf.RawOp)
 	}
 	return nil
 }
 
+// orTerm returns the SQL operator and value to pass to Query.Or for
+// this filter. The value for "in" filters is a []interface{}.
+func (pf *ParamFilter) orTerm() (string, interface{}, error) {
+	switch pf.RawOp {
+	case "eq", "ne", "gt", "gteq", "lt", "lteq":
+		return pf.SQLOp, pf.Values[0], nil
+	case "starts_with":
+		return pf.SQLOp, fmt.Sprintf("%s%%", pf.Values[0]), nil
+	case "contains":
+		return pf.SQLOp, fmt.Sprintf("%%%s%%", pf.Values[0]), nil
+	case "is_null", "not_null":
+		if pf.Values[0] != "true" && pf.Values[0] != "false" {
+			break
+		}
+		isNull := pf.Values[0] == "true"
+		if pf.RawOp == "not_null" {
+			isNull = !isNull
+		}
+		if isNull {
+			return "IS NULL", nil, nil
+		}
+		return "IS NOT NULL", nil, nil
+	case "in":
+		return pf.SQLOp, pf.InterfaceValues(), nil
+	}
+	return "", nil, fmt.Errorf("Invalid query string param '%s': can't use operator '%s' with value '%s' in an OR group", pf.Key, pf.RawOp, pf.Values[0])
+}
+
 // InterfaceValues converts []string Values to []interface{} values.
 // We get string values from the HTTP query string, but we need to provide
 // interface{} values to the pg library that will query the database.
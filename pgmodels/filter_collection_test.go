@@ -1,6 +1,7 @@
 package pgmodels_test
 
 import (
+	"net/url"
 	"testing"
 
 	"github.com/APTrust/registry/pgmodels"
@@ -86,6 +87,13 @@ var valid = []*pgmodels.ParamFilter{
 		SQLOp:  "IN",
 		Values: []string{"Bart", "Lisa", "Maggie"},
 	},
+	{
+		Key:    "q",
+		Column: "search_vector",
+		RawOp:  "search",
+		SQLOp:  "@@",
+		Values: []string{"Springfield nuclear"},
+	},
 }
 
 var invalid = []*pgmodels.ParamFilter{
@@ -123,6 +131,8 @@ func expectedQuery(index int) *pgmodels.Query {
 		q.IsNotNull("name")
 	case 10:
 		q.WhereIn("name", []interface{}{"Bart", "Lisa", "Maggie"}...)
+	case 11:
+		q.Search("search_vector", "Springfield nuclear")
 	}
 	return q
 }
@@ -170,6 +180,66 @@ func TestAddToQuery(t *testing.T) {
 	}
 }
 
+func TestParseOrGroupKey(t *testing.T) {
+	group, key, ok := pgmodels.ParseOrGroupKey("or.status")
+	assert.True(t, ok)
+	assert.Equal(t, "or", group)
+	assert.Equal(t, "status", key)
+
+	group, key, ok = pgmodels.ParseOrGroupKey("or12.size__gt")
+	assert.True(t, ok)
+	assert.Equal(t, "or12", group)
+	assert.Equal(t, "size__gt", key)
+
+	for _, param := range []string{"status", "or", "or.", "orx.status", "and.status", "or.status;drop"} {
+		_, _, ok = pgmodels.ParseOrGroupKey(param)
+		assert.False(t, ok, param)
+	}
+}
+
+func TestAddOrGroups(t *testing.T) {
+	allowed := []string{"institution_id", "needs_admin_review", "q", "size__gt", "stage__in", "status", "storage_option__starts_with"}
+	params := url.Values{
+		"institution_id":                  []string{"2"},
+		"or.status":                       []string{"Failed", "Cancelled"},
+		"or.needs_admin_review":           []string{"true"},
+		"or2.storage_option__starts_with": []string{"Glacier-"},
+		"or2.size__gt":                    []string{"1099511627776"},
+		"or3.stage__in":                   []string{"Receive", "Record"},
+		"or3.node__is_null":               []string{"true"},
+	}
+	fc := pgmodels.NewFilterCollection()
+	fc.Add("institution_id", params["institution_id"])
+	added, err := fc.AddOrGroups(params, allowed)
+
+	// node__is_null is not an allowed filter
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "or3.node__is_null")
+	require.Equal(t, 6, len(added))
+	assert.Equal(t, "or.needs_admin_review", added[0].Key)
+	assert.Equal(t, "Needs_admin_review (or)", added[0].ChipLabel())
+	assert.Equal(t, "or", added[1].Group)
+	assert.Equal(t, []string{"Failed"}, added[1].Values)
+	assert.Equal(t, []string{"Cancelled"}, added[2].Values)
+	assert.Equal(t, []string{"Receive", "Record"}, added[5].Values)
+
+	query, err := fc.ToQuery()
+	require.Nil(t, err)
+	assert.Equal(t, `(institution_id = ?) AND (needs_admin_review = ? OR status = ? OR status = ?) AND (size > ? OR storage_option ILIKE ?) AND (stage IN (?, ?))`, query.WhereClause())
+	assert.Equal(t, []interface{}{"2", "true", "Failed", "Cancelled", "1099511627776", "Glacier-%", "Receive", "Record"}, query.Params())
+	assert.True(t, query.IncludesOrCondition())
+
+	// OR group filters don't show up as regular filters.
+	assert.Equal(t, "", fc.ValueOf("status"))
+	assert.Equal(t, "Failed", fc.ValueOf("or.status"))
+
+	// Search can't be used in an OR group.
+	fc = pgmodels.NewFilterCollection()
+	_, err = fc.AddOrGroups(url.Values{"or.q": []string{"photos"}}, allowed)
+	require.NotNil(t, err)
+	assert.Nil(t, fc.SearchFilter())
+}
+
 // TestInterfaceValues ensures that we can get the filter's string values
 // as a slice of []interface{}.
 func TestInterfaceValues(t *testing.T) {
@@ -193,10 +263,24 @@ func TestFilterCollection(t *testing.T) {
 	query, err := fc.ToQuery()
 	require.Nil(t, err)
 	require.NotNil(t, query)
-	assert.Equal(t, `(name = ?) AND (name != ?) AND (age > ?) AND (age >= ?) AND (age < ?) AND (age <= ?) AND (name ILIKE ?) AND (name ILIKE ?) AND (name is null) AND (name is not null) AND (name IN (?, ?, ?))`, query.WhereClause())
-	assert.Equal(t, []interface{}{"Homer", "Homer", "38", "38", "38", "38", "Simpson%", "%Simpson%", "Bart", "Lisa", "Maggie"}, query.Params())
+	assert.Equal(t, `(name = ?) AND (name != ?) AND (age > ?) AND (age >= ?) AND (age < ?) AND (age <= ?) AND (name ILIKE ?) AND (name ILIKE ?) AND (name is null) AND (name is not null) AND (name IN (?, ?, ?)) AND (search_vector @@ websearch_to_tsquery('english', ?))`, query.WhereClause())
+	assert.Equal(t, []interface{}{"Homer", "Homer", "38", "38", "38", "38", "Simpson%", "%Simpson%", "Bart", "Lisa", "Maggie", "Springfield nuclear"}, query.Params())
 	assert.Equal(t, "Homer", fc.ValueOf("name__ne"))
 	assert.Equal(t, []string{"Bart", "Lisa", "Maggie"}, fc.ValuesOf("name__in"))
+
+	search := fc.SearchFilter()
+	require.NotNil(t, search)
+	assert.Equal(t, "q", search.Key)
+	assert.Equal(t, "Search", search.ChipLabel())
+	assert.Equal(t, "Springfield nuclear", search.ChipValue())
+
+	// Empty search is ignored.
+	fc = pgmodels.NewFilterCollection()
+	fc.Add("q", []string{""})
+	assert.Nil(t, fc.SearchFilter())
+	query, err = fc.ToQuery()
+	require.Nil(t, err)
+	assert.Equal(t, "", query.WhereClause())
 }
 
 func TestFCOrderBy(t *testing.T) {
@@ -219,6 +303,11 @@ func TestFCOrderBy(t *testing.T) {
 	assert.Equal(t, `(name = ?)`, query.WhereClause())
 	assert.Equal(t, []interface{}{"Homer"}, query.Params())
 	assert.Equal(t, []string{"name asc", "email asc", "created_at desc"}, query.GetOrderBy())
+
+	sorts := fc.SortParams()
+	require.Equal(t, 3, len(sorts))
+	assert.Equal(t, "created_at", sorts[2].Column)
+	assert.Equal(t, "desc", sorts[2].Direction)
 }
 
 func TestFilterString(t *testing.T) {
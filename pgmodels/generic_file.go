@@ -31,6 +31,7 @@ var GenericFileFilters = []string{
 
 type GenericFile struct {
 	TimestampModel
+	AuditedModel
 	FileFormat           string              `json:"file_format"`
 	Size                 int64               `json:"size"`
 	Identifier           string              `json:"identifier"`
@@ -152,9 +153,9 @@ func (gf *GenericFile) saveInTransaction(tx *pg.Tx) error {
 	}
 	var err error
 	if gf.ID == 0 {
-		_, err = tx.Model(gf).Insert()
+		err = auditedInsert(tx, gf)
 	} else {
-		_, err = tx.Model(gf).WherePK().Update()
+		err = auditedUpdate(tx, gf)
 	}
 	if err == nil {
 		err = gf.saveChecksumsTx(tx)
@@ -405,7 +406,7 @@ func (gf *GenericFile) Delete() error {
 	db := registryContext.DB
 	return db.RunInTransaction(db.Context(), func(tx *pg.Tx) error {
 		var err error
-		_, err = tx.Model(gf).WherePK().Update()
+		err = auditedUpdate(tx, gf)
 		if err != nil {
 			registryContext.Log.Error().Msgf("GenericFile deletion transaction failed on update of file. File: %d (%s). Error: %v", gf.ID, gf.Identifier, err)
 		}
@@ -423,6 +424,80 @@ func (gf *GenericFile) Delete() error {
 	})
 }
 
+// Undelete reverts a soft-delete by setting State back to 'A'. It also
+// creates a compensating PremisEvent, so the file's history shows both
+// the deletion and its reversal. We use this when a failed deletion run
+// marked a file deleted but never removed its preservation copies.
+//
+// Deletion removes a file's storage records, so this returns
+// common.ErrStorageRecordsMissing if the file has none. If the file's
+// object is also deleted, undelete the object instead.
+func (gf *GenericFile) Undelete(user *User) error {
+	err := gf.AssertUndeletePreconditions()
+	if err != nil {
+		return err
+	}
+
+	gf.State = constants.StateActive
+	gf.UpdatedAt = time.Now().UTC()
+	valErr := gf.Validate()
+	if valErr != nil {
+		return valErr
+	}
+
+	event := newUndeleteEvent(gf.InstitutionID, gf.IntellectualObjectID, gf.ID, user)
+	event.SetTimestamps()
+	valErr = event.Validate()
+	if valErr != nil {
+		return valErr
+	}
+
+	registryContext := common.Context()
+	db := registryContext.DB
+	return db.RunInTransaction(db.Context(), func(tx *pg.Tx) error {
+		err := auditedUpdate(tx, gf)
+		if err != nil {
+			registryContext.Log.Error().Msgf("GenericFile undelete transaction failed on update of file. File: %d (%s). Error: %v", gf.ID, gf.Identifier, err)
+			return err
+		}
+		_, err = tx.Model(event).Insert()
+		if err != nil {
+			registryContext.Log.Error().Msgf("GenericFile undelete transaction failed on insertion of event. File: %d (%s). Error: %v", gf.ID, gf.Identifier, err)
+		}
+		return err
+	})
+}
+
+// AssertUndeletePreconditions returns an error if this file cannot
+// be undeleted.
+func (gf *GenericFile) AssertUndeletePreconditions() error {
+	if gf.State != constants.StateDeleted {
+		return common.ErrNotDeleted
+	}
+	obj, err := IntellectualObjectByID(gf.IntellectualObjectID)
+	if err != nil {
+		return err
+	}
+	if obj.State == constants.StateDeleted {
+		return common.ErrObjectDeleted
+	}
+	pendingWorkItems, err := WorkItemsPendingForFile(gf.ID)
+	if err != nil {
+		return err
+	}
+	if len(pendingWorkItems) > 0 {
+		return common.ErrPendingWorkItems
+	}
+	count, err := common.Context().DB.Model((*StorageRecord)(nil)).Where("generic_file_id = ?", gf.ID).Count()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return common.ErrStorageRecordsMissing
+	}
+	return nil
+}
+
 // LastIngestEvent returns the latest ingest event for this file.
 // This should never be nil.
 func (gf *GenericFile) LastIngestEvent() (*PremisEvent, error) {
@@ -481,6 +556,9 @@ func (gf *GenericFile) AssertDeletionPreconditions() error {
 	if !gf.HasPassedMinimumRetentionPeriod() {
 		return fmt.Errorf("File has not passed minimum retention period")
 	}
+	if err := AssertNoLegalHold(gf.IntellectualObjectID); err != nil {
+		return err
+	}
 	_, _, err := gf.assertDeletionApproved()
 	return err
 }
@@ -315,6 +315,20 @@ func TestFileDeletionPreConditions(t *testing.T) {
 	err = gf.AssertDeletionPreconditions()
 	require.Nil(t, err)
 
+	// A legal hold on the file's object blocks deletion.
+	hold := &pgmodels.LegalHold{
+		InstitutionID:        gf.InstitutionID,
+		IntellectualObjectID: gf.IntellectualObjectID,
+		Reason:               "Precondition test",
+		PlacedByID:           testEduAdmin.ID,
+	}
+	require.Nil(t, hold.Save())
+	err = gf.AssertDeletionPreconditions()
+	assert.Equal(t, common.ErrLegalHold, err)
+	require.Nil(t, hold.Release(testEduAdmin))
+	err = gf.AssertDeletionPreconditions()
+	require.Nil(t, err)
+
 	testGenericFileDeleteSuccess(t, gf)
 }
 
@@ -464,3 +478,63 @@ func TestGenericFileMinRetention(t *testing.T) {
 	assert.Equal(t, expectedDate, gf.EarliestDeletionDate())
 	assert.True(t, gf.HasPassedMinimumRetentionPeriod())
 }
+
+func TestFileUndelete(t *testing.T) {
+	defer db.ForceFixtureReload()
+	db.ForceFixtureReload()
+	sysAdmin, err := pgmodels.UserByEmail("system@aptrust.org")
+	require.Nil(t, err)
+
+	// File 1 is active, so there's nothing to undelete.
+	gf, err := pgmodels.GenericFileByID(1)
+	require.Nil(t, err)
+	assert.Equal(t, common.ErrNotDeleted, gf.Undelete(sysAdmin))
+
+	// File 62 belongs to deleted object 14.
+	gf, err = pgmodels.GenericFileByID(62)
+	require.Nil(t, err)
+	assert.Equal(t, common.ErrObjectDeleted, gf.Undelete(sysAdmin))
+
+	// File 10 is deleted, but still has storage records.
+	gf, err = pgmodels.GenericFileByID(10)
+	require.Nil(t, err)
+	require.Equal(t, constants.StateDeleted, gf.State)
+
+	// Pending work items block undelete.
+	workItem := pgmodels.RandomWorkItem("glass.tar",
+		constants.ActionRestoreFile, gf.IntellectualObjectID, gf.ID)
+	workItem.InstitutionID = gf.InstitutionID
+	require.Nil(t, workItem.Save())
+	assert.Equal(t, common.ErrPendingWorkItems, gf.Undelete(sysAdmin))
+	workItem.Status = constants.StatusCancelled
+	require.Nil(t, workItem.Save())
+
+	require.Nil(t, gf.Undelete(sysAdmin))
+	gf, err = pgmodels.GenericFileByID(10)
+	require.Nil(t, err)
+	assert.Equal(t, constants.StateActive, gf.State)
+
+	event, err := gf.LastDeletionEvent()
+	require.Nil(t, err)
+	testUndeleteEventProperties(t, sysAdmin, gf.InstitutionID, gf.IntellectualObjectID, gf.ID, event)
+
+	// File 20 is deleted, but if its storage records are gone,
+	// so are its bytes.
+	_, err = common.Context().DB.Model((*pgmodels.StorageRecord)(nil)).Where("generic_file_id = ?", 20).Delete()
+	require.Nil(t, err)
+	gf, err = pgmodels.GenericFileByID(20)
+	require.Nil(t, err)
+	assert.Equal(t, common.ErrStorageRecordsMissing, gf.Undelete(sysAdmin))
+}
+
+func testUndeleteEventProperties(t *testing.T, user *pgmodels.User, instID, objID, gfID int64, event *pgmodels.PremisEvent) {
+	require.NotNil(t, event)
+	assert.Equal(t, "APTrust Registry", event.Agent)
+	assert.Equal(t, constants.EventDeletion, event.EventType)
+	assert.Equal(t, constants.OutcomeFailure, event.Outcome)
+	assert.Equal(t, user.Email, event.OutcomeDetail)
+	assert.True(t, common.LooksLikeUUID(event.Identifier))
+	assert.Equal(t, instID, event.InstitutionID)
+	assert.Equal(t, objID, event.IntellectualObjectID)
+	assert.Equal(t, gfID, event.GenericFileID)
+}
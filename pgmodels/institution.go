@@ -27,6 +27,7 @@ var InstitutionFilters = []string{
 
 type Institution struct {
 	TimestampModel
+	AuditedModel
 	Name                      string    `json:"name"`
 	Identifier                string    `json:"identifier"`
 	State                     string    `json:"state"`
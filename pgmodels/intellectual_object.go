@@ -17,6 +17,7 @@ import (
 
 type IntellectualObject struct {
 	TimestampModel
+	AuditedModel
 	Title                     string         `json:"title"`
 	Description               string         `json:"description"`
 	Identifier                string         `json:"identifier"`
@@ -100,6 +101,9 @@ func (obj *IntellectualObject) Save() error {
 	if err != nil {
 		return err
 	}
+	if bgErr := EnsureBagGroup(obj.InstitutionID, obj.BagGroupIdentifier); bgErr != nil {
+		return bgErr
+	}
 	if obj.ID == int64(0) {
 		return insert(obj)
 	}
@@ -160,7 +164,7 @@ func (obj *IntellectualObject) Delete() error {
 	db := registryContext.DB
 	return db.RunInTransaction(db.Context(), func(tx *pg.Tx) error {
 		var err error
-		_, err = tx.Model(obj).WherePK().Update()
+		err = auditedUpdate(tx, obj)
 		if err != nil {
 			registryContext.Log.Error().Msgf("Intellectual object deletion transaction failed on update of object. Object: %d (%s). Error: %v", obj.ID, obj.Identifier, err)
 			j, _ := json.Marshal(obj)
@@ -176,6 +180,100 @@ func (obj *IntellectualObject) Delete() error {
 	})
 }
 
+// Undelete reverts a soft-delete by setting State back to 'A'. We use
+// this when a failed deletion run marked an object deleted but never
+// removed its preservation copies. It also undeletes the object's
+// deleted files that still have storage records, and it creates a
+// compensating PremisEvent for the object and for each of those files.
+//
+// Deletion removes a file's storage records, so deleted files without
+// storage records stay deleted. If none of the object's files have
+// storage records, the bytes are gone and this returns
+// common.ErrStorageRecordsMissing.
+func (obj *IntellectualObject) Undelete(user *User) error {
+	files, err := obj.AssertUndeletePreconditions()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	obj.State = constants.StateActive
+	obj.UpdatedAt = now
+	if obj.BagItProfileIdentifier == "" {
+		obj.BagItProfileIdentifier = constants.DefaultProfileIdentifier
+	}
+	valErr := obj.Validate()
+	if valErr != nil {
+		return valErr
+	}
+	objEvent := newUndeleteEvent(obj.InstitutionID, obj.ID, 0, user)
+	objEvent.SetTimestamps()
+	fileEvents := make([]*PremisEvent, len(files))
+	for i, gf := range files {
+		gf.State = constants.StateActive
+		gf.UpdatedAt = now
+		fileEvents[i] = newUndeleteEvent(gf.InstitutionID, obj.ID, gf.ID, user)
+		fileEvents[i].SetTimestamps()
+	}
+
+	registryContext := common.Context()
+	db := registryContext.DB
+	return db.RunInTransaction(db.Context(), func(tx *pg.Tx) error {
+		err := auditedUpdate(tx, obj)
+		if err != nil {
+			registryContext.Log.Error().Msgf("Intellectual object undelete transaction failed on update of object. Object: %d (%s). Error: %v", obj.ID, obj.Identifier, err)
+			return err
+		}
+		_, err = tx.Model(objEvent).Insert()
+		if err != nil {
+			registryContext.Log.Error().Msgf("Intellectual object undelete transaction failed on insertion of event. Object: %d (%s). Error: %v", obj.ID, obj.Identifier, err)
+			return err
+		}
+		for i, gf := range files {
+			err = auditedUpdate(tx, gf)
+			if err != nil {
+				registryContext.Log.Error().Msgf("Intellectual object undelete transaction failed on update of file %d (%s). Object: %d (%s). Error: %v", gf.ID, gf.Identifier, obj.ID, obj.Identifier, err)
+				return err
+			}
+			_, err = tx.Model(fileEvents[i]).Insert()
+			if err != nil {
+				registryContext.Log.Error().Msgf("Intellectual object undelete transaction failed on insertion of event for file %d (%s). Object: %d (%s). Error: %v", gf.ID, gf.Identifier, obj.ID, obj.Identifier, err)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AssertUndeletePreconditions returns an error if this object cannot
+// be undeleted. Otherwise, it returns the object's deleted files that
+// still have storage records. Undelete restores those files along
+// with the object.
+func (obj *IntellectualObject) AssertUndeletePreconditions() ([]*GenericFile, error) {
+	if obj.State != constants.StateDeleted {
+		return nil, common.ErrNotDeleted
+	}
+	pendingWorkItems, err := WorkItemsPendingForObject(obj.InstitutionID, obj.BagName)
+	if err != nil {
+		return nil, err
+	}
+	if len(pendingWorkItems) > 0 {
+		return nil, common.ErrPendingWorkItems
+	}
+	var files []*GenericFile
+	err = common.Context().DB.Model(&files).
+		Where(`intellectual_object_id = ? and state = ? and exists (select 1 from storage_records sr where sr.generic_file_id = "generic_file"."id")`, obj.ID, constants.StateDeleted).
+		Order("id").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, common.ErrStorageRecordsMissing
+	}
+	return files, nil
+}
+
 // HasActiveFiles returns true if this object has any active (non-deleted)
 // files. We need to check this before marking an object as deleted.
 // Do not mark deleted until all files have been marked deleted.
@@ -284,6 +382,9 @@ func (obj *IntellectualObject) AssertDeletionPreconditions() error {
 			err = fmt.Errorf("Object has not passed minimum retention period")
 		}
 	}
+	if err == nil {
+		err = AssertNoLegalHold(obj.ID)
+	}
 	if err == nil {
 		_, _, err = obj.assertDeletionApproved()
 	}
@@ -327,7 +428,9 @@ func (obj *IntellectualObject) assertNotAlreadyDeleted() error {
 		if err != nil {
 			err = fmt.Errorf("Error checking for last deletion event: %v", err)
 		}
-		if lastDeletionEvent != nil && lastDeletionEvent.CreatedAt.After(lastIngestEvent.CreatedAt) {
+		// Undelete records a deletion event with a failure outcome,
+		// so only a successful deletion means the object is gone.
+		if lastDeletionEvent != nil && lastDeletionEvent.Outcome == constants.OutcomeSuccess && lastDeletionEvent.CreatedAt.After(lastIngestEvent.CreatedAt) {
 			err = fmt.Errorf("Object has already been deleted since last ingest")
 		}
 	}
@@ -329,6 +329,20 @@ func TestAssertObjDeletionPreconditions(t *testing.T) {
 	err = obj.AssertDeletionPreconditions()
 	assert.Nil(t, err)
 
+	// Unless someone places a legal hold
+	hold := &pgmodels.LegalHold{
+		InstitutionID:        obj.InstitutionID,
+		IntellectualObjectID: obj.ID,
+		Reason:               "Precondition test",
+		PlacedByID:           testEduAdmin.ID,
+	}
+	require.Nil(t, hold.Save())
+	err = obj.AssertDeletionPreconditions()
+	assert.Equal(t, common.ErrLegalHold, err)
+	require.Nil(t, hold.Release(testEduAdmin))
+	err = obj.AssertDeletionPreconditions()
+	assert.Nil(t, err)
+
 	// Now test the actual deletion
 	testObjectDelete(t, obj)
 }
@@ -479,3 +493,54 @@ func TestIntellectualObjectMinRetention(t *testing.T) {
 	assert.Equal(t, expectedDate, obj.EarliestDeletionDate())
 	assert.True(t, obj.HasPassedMinimumRetentionPeriod())
 }
+
+func TestObjUndelete(t *testing.T) {
+	defer db.ForceFixtureReload()
+	db.ForceFixtureReload()
+	sysAdmin, err := pgmodels.UserByEmail("system@aptrust.org")
+	require.Nil(t, err)
+
+	// Object 1 is active, so there's nothing to undelete.
+	obj, err := pgmodels.IntellectualObjectByID(1)
+	require.Nil(t, err)
+	assert.Equal(t, common.ErrNotDeleted, obj.Undelete(sysAdmin))
+
+	// Object 14 is deleted, and its only file has no storage
+	// records, so its bytes are gone.
+	obj, err = pgmodels.IntellectualObjectByID(14)
+	require.Nil(t, err)
+	assert.Equal(t, common.ErrStorageRecordsMissing, obj.Undelete(sysAdmin))
+
+	// Simulate a failed deletion run that marked object 5 and
+	// two of its files deleted, but deleted only file 15 from
+	// preservation storage.
+	ctx := common.Context()
+	_, err = ctx.DB.Model((*pgmodels.IntellectualObject)(nil)).Set("state = ?", constants.StateDeleted).Where("id = ?", 5).Update()
+	require.Nil(t, err)
+	_, err = ctx.DB.Model((*pgmodels.GenericFile)(nil)).Set("state = ?", constants.StateDeleted).Where("id in (?, ?)", 14, 15).Update()
+	require.Nil(t, err)
+	_, err = ctx.DB.Model((*pgmodels.StorageRecord)(nil)).Where("generic_file_id = ?", 15).Delete()
+	require.Nil(t, err)
+
+	obj, err = pgmodels.IntellectualObjectByID(5)
+	require.Nil(t, err)
+	require.Nil(t, obj.Undelete(sysAdmin))
+
+	obj, err = pgmodels.IntellectualObjectByID(5)
+	require.Nil(t, err)
+	assert.Equal(t, constants.StateActive, obj.State)
+	event, err := obj.LastDeletionEvent()
+	require.Nil(t, err)
+	testUndeleteEventProperties(t, sysAdmin, obj.InstitutionID, obj.ID, 0, event)
+
+	gf, err := pgmodels.GenericFileByID(14)
+	require.Nil(t, err)
+	assert.Equal(t, constants.StateActive, gf.State)
+	event, err = gf.LastDeletionEvent()
+	require.Nil(t, err)
+	testUndeleteEventProperties(t, sysAdmin, gf.InstitutionID, obj.ID, gf.ID, event)
+
+	gf, err = pgmodels.GenericFileByID(15)
+	require.Nil(t, err)
+	assert.Equal(t, constants.StateDeleted, gf.State)
+}
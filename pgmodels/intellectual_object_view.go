@@ -1,6 +1,8 @@
 package pgmodels
 
 import (
+	"html"
+	"strings"
 	"time"
 
 	"github.com/APTrust/registry/common"
@@ -31,6 +33,7 @@ var IntellectualObjectFilters = []string{
 	"institution_parent_id",
 	"internal_sender_description",
 	"internal_sender_identifier",
+	"q",
 	"size__gteq",
 	"size__lteq",
 	"source_organization",
@@ -70,6 +73,13 @@ type IntellectualObjectView struct {
 	Size                      int64     `json:"size"`
 	PayloadFileCount          int64     `json:"payload_file_count"`
 	PayloadSize               int64     `json:"payload_size"`
+
+	// SearchRank and SearchSnippet are set only on results of a
+	// full-text search (the q filter). SearchSnippet is HTML-escaped
+	// text with matching terms wrapped in <mark> tags, so it's safe
+	// to render as HTML.
+	SearchRank    float64 `json:"search_rank,omitempty" pg:"-"`
+	SearchSnippet string  `json:"search_snippet,omitempty" pg:"-"`
 }
 
 // IntellectualObjectViewByID returns the object with the specified id.
@@ -163,3 +173,64 @@ func (obj *IntellectualObjectView) EarliestDeletionDate() time.Time {
 func (obj *IntellectualObjectView) HasPassedMinimumRetentionPeriod() bool {
 	return obj.EarliestDeletionDate().Before(time.Now())
 }
+
+// Sentinels that ts_headline wraps around matching terms. We replace
+// these with <mark> tags after HTML-escaping the snippet.
+const (
+	snippetStartSel = "\x01"
+	snippetStopSel  = "\x02"
+)
+
+// AddSearchSnippets sets the SearchRank and SearchSnippet of each
+// object in objects for a full-text search on text. The snippet is
+// built from whichever of the object's searchable fields best match
+// the search.
+//
+// We run this on one page of results rather than in the main query
+// because ts_headline has to re-parse the original text, which is too
+// slow to do for every matching row in a large result set.
+func AddSearchSnippets(objects []*IntellectualObjectView, text string) error {
+	if len(objects) == 0 || strings.TrimSpace(text) == "" {
+		return nil
+	}
+	ids := make([]int64, len(objects))
+	for i, obj := range objects {
+		ids[i] = obj.ID
+	}
+	options := `StartSel="` + snippetStartSel + `", StopSel="` + snippetStopSel + `", MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter=" ... "`
+	query := `select io.id,
+	ts_rank(io.search_vector, q) as rank,
+	ts_headline('english', concat_ws(' ... ', nullif(io.title, ''), nullif(io.alt_identifier, ''),
+		nullif(io.bag_group_identifier, ''), nullif(io.description, ''),
+		nullif(io.source_organization, ''), nullif(io.internal_sender_description, '')), q, ?) as snippet
+	from intellectual_objects io, websearch_to_tsquery('english', ?) q
+	where io.id in (?)`
+	var rows []struct {
+		ID      int64
+		Rank    float64
+		Snippet string
+	}
+	_, err := common.Context().DB.Query(&rows, query, options, text, pg.In(ids))
+	if err != nil {
+		return err
+	}
+	byID := make(map[int64]*IntellectualObjectView, len(objects))
+	for _, obj := range objects {
+		byID[obj.ID] = obj
+	}
+	for _, row := range rows {
+		if obj, ok := byID[row.ID]; ok {
+			obj.SearchRank = row.Rank
+			obj.SearchSnippet = markSnippet(row.Snippet)
+		}
+	}
+	return nil
+}
+
+// markSnippet HTML-escapes a ts_headline snippet and converts our
+// sentinels to <mark> tags.
+func markSnippet(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, snippetStartSel, "<mark>")
+	return strings.ReplaceAll(snippet, snippetStopSel, "</mark>")
+}
@@ -34,6 +34,35 @@ func TestIntellectualObjectView(t *testing.T) {
 	assert.Equal(t, 10, len(objViews))
 }
 
+func TestIntellectualObjectViewSearch(t *testing.T) {
+	db.LoadFixtures()
+	query := pgmodels.NewQuery().
+		Search("search_vector", "glacier").
+		OrderByRank("search_vector", "glacier").
+		OrderBy("id", "asc")
+	objViews, err := pgmodels.IntellectualObjectViewSelect(query)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(objViews))
+
+	// Chocolate appears in object four's alt identifier
+	// and description.
+	query = pgmodels.NewQuery().Search("search_vector", "chocolate")
+	objViews, err = pgmodels.IntellectualObjectViewSelect(query)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(objViews))
+	assert.Equal(t, int64(4), objViews[0].ID)
+
+	err = pgmodels.AddSearchSnippets(objViews, "chocolate")
+	require.Nil(t, err)
+	assert.True(t, objViews[0].SearchRank > 0)
+	assert.Contains(t, objViews[0].SearchSnippet, "<mark>chocolate</mark>")
+
+	// Search syntax errors should not cause SQL errors.
+	query = pgmodels.NewQuery().Search("search_vector", `"unbalanced -`)
+	_, err = pgmodels.IntellectualObjectViewSelect(query)
+	require.Nil(t, err)
+}
+
 func TestSmallestObjectNotRestoredInXDays(t *testing.T) {
 	db.LoadFixtures()
 	defer db.ForceFixtureReload()
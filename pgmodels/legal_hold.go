@@ -0,0 +1,171 @@
+package pgmodels
+
+import (
+	"strings"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/go-pg/pg/v10"
+)
+
+const (
+	ErrLegalHoldInstID     = "Legal hold requires a valid institution id."
+	ErrLegalHoldTarget     = "Legal hold must apply to exactly one object or one bag group."
+	ErrLegalHoldWrongInst  = "The object or bag group does not belong to the selected institution."
+	ErrLegalHoldReason     = "Please explain the reason for this hold."
+	ErrLegalHoldPlacedBy   = "Legal hold requires a valid placed by id."
+	ErrLegalHoldExpiration = "Expiration date must be in the future."
+)
+
+// LegalHold freezes an intellectual object, or every object in a bag
+// group, so that no one can request, approve or complete its deletion.
+// We use holds when material must be preserved for litigation or audits.
+//
+// A hold stays in effect until someone releases it or until it reaches
+// its optional expiration date. Holds are never deleted, so we keep a
+// record of who froze what, why, and for how long.
+type LegalHold struct {
+	TimestampModel
+	AuditedModel
+	InstitutionID        int64     `json:"institution_id" form:"-" pg:"institution_id"`
+	IntellectualObjectID int64     `json:"intellectual_object_id" form:"-" pg:"intellectual_object_id"`
+	BagGroupID           int64     `json:"bag_group_id" form:"-" pg:"bag_group_id"`
+	Reason               string    `json:"reason" pg:"reason"`
+	PlacedByID           int64     `json:"placed_by_id" form:"-" pg:"placed_by_id"`
+	ExpiresAt            time.Time `json:"expires_at" time_format:"2006-01-02" pg:"expires_at"`
+	ReleasedAt           time.Time `json:"released_at" form:"-" pg:"released_at"`
+	ReleasedByID         int64     `json:"released_by_id" form:"-" pg:"released_by_id"`
+}
+
+// LegalHoldByID returns the legal hold with the specified id.
+// Returns pg.ErrNoRows if there is no match.
+func LegalHoldByID(id int64) (*LegalHold, error) {
+	query := NewQuery().Where(`"legal_hold"."id"`, "=", id)
+	return LegalHoldGet(query)
+}
+
+// LegalHoldGet returns the first legal hold matching the query.
+func LegalHoldGet(query *Query) (*LegalHold, error) {
+	var hold LegalHold
+	err := query.Select(&hold)
+	return &hold, err
+}
+
+// LegalHoldSelect returns all legal holds matching the query.
+func LegalHoldSelect(query *Query) ([]*LegalHold, error) {
+	var holds []*LegalHold
+	err := query.Select(&holds)
+	return holds, err
+}
+
+// AssertNoLegalHold returns common.ErrLegalHold if any of the specified
+// objects is under an active legal hold, either directly or through
+// its bag group.
+func AssertNoLegalHold(objIDs ...int64) error {
+	if len(objIDs) == 0 {
+		return nil
+	}
+	query := `select count(*) from legal_holds lh
+	where lh.released_at is null
+	and (lh.expires_at is null or lh.expires_at > now())
+	and (lh.intellectual_object_id in (?0)
+		or lh.bag_group_id in (
+			select bg.id from bag_groups bg
+			join intellectual_objects io
+			on io.institution_id = bg.institution_id
+			and io.bag_group_identifier = bg.identifier
+			where io.id in (?0)))`
+	var count int
+	_, err := common.Context().DB.QueryOne(pg.Scan(&count), query, pg.In(objIDs))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return common.ErrLegalHold
+	}
+	return nil
+}
+
+// Save saves this hold to the database. This will peform an insert
+// if LegalHold.ID is zero. Otherwise, it updates.
+func (hold *LegalHold) Save() error {
+	hold.SetTimestamps()
+	hold.Reason = strings.TrimSpace(hold.Reason)
+	err := hold.Validate()
+	if err != nil {
+		return err
+	}
+	instID, targetErr := hold.targetInstitutionID()
+	if targetErr != nil {
+		return targetErr
+	}
+	if instID != hold.InstitutionID {
+		return &common.ValidationError{Errors: map[string]string{"InstitutionID": ErrLegalHoldWrongInst}}
+	}
+	if hold.ID == int64(0) {
+		return insert(hold)
+	}
+	return update(hold)
+}
+
+// Validate returns errors if this hold is not valid.
+func (hold *LegalHold) Validate() *common.ValidationError {
+	errors := make(map[string]string)
+	if hold.InstitutionID < 1 {
+		errors["InstitutionID"] = ErrLegalHoldInstID
+	}
+	if (hold.IntellectualObjectID > 0) == (hold.BagGroupID > 0) {
+		errors["IntellectualObjectID"] = ErrLegalHoldTarget
+	}
+	if strings.TrimSpace(hold.Reason) == "" {
+		errors["Reason"] = ErrLegalHoldReason
+	}
+	if hold.PlacedByID < 1 {
+		errors["PlacedByID"] = ErrLegalHoldPlacedBy
+	}
+	if hold.ID == 0 && !hold.ExpiresAt.IsZero() && hold.ExpiresAt.Before(time.Now().UTC()) {
+		errors["ExpiresAt"] = ErrLegalHoldExpiration
+	}
+	if len(errors) > 0 {
+		return &common.ValidationError{Errors: errors}
+	}
+	return nil
+}
+
+// IsActive returns true if this hold has not been released and
+// has not expired.
+func (hold *LegalHold) IsActive() bool {
+	if !hold.ReleasedAt.IsZero() {
+		return false
+	}
+	return hold.ExpiresAt.IsZero() || hold.ExpiresAt.After(time.Now().UTC())
+}
+
+// Release releases this hold on behalf of user and saves it.
+// Releasing a hold that was already released or has expired
+// is a no-op.
+func (hold *LegalHold) Release(user *User) error {
+	if !hold.IsActive() {
+		return nil
+	}
+	hold.ReleasedAt = time.Now().UTC()
+	hold.ReleasedByID = user.ID
+	return hold.Save()
+}
+
+// targetInstitutionID returns the id of the institution that owns
+// the object or bag group this hold applies to.
+func (hold *LegalHold) targetInstitutionID() (int64, error) {
+	if hold.IntellectualObjectID > 0 {
+		obj, err := IntellectualObjectByID(hold.IntellectualObjectID)
+		if err != nil {
+			return 0, err
+		}
+		return obj.InstitutionID, nil
+	}
+	bagGroup, err := BagGroupByID(hold.BagGroupID)
+	if err != nil {
+		return 0, err
+	}
+	return bagGroup.InstitutionID, nil
+}
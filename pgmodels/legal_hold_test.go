@@ -0,0 +1,115 @@
+package pgmodels_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegalHoldValidate(t *testing.T) {
+	hold := &pgmodels.LegalHold{}
+	err := hold.Validate()
+	require.NotNil(t, err)
+	assert.Equal(t, pgmodels.ErrLegalHoldInstID, err.Errors["InstitutionID"])
+	assert.Equal(t, pgmodels.ErrLegalHoldTarget, err.Errors["IntellectualObjectID"])
+	assert.Equal(t, pgmodels.ErrLegalHoldReason, err.Errors["Reason"])
+	assert.Equal(t, pgmodels.ErrLegalHoldPlacedBy, err.Errors["PlacedByID"])
+
+	// Can't apply to both an object and a bag group.
+	hold = &pgmodels.LegalHold{
+		InstitutionID:        2,
+		IntellectualObjectID: 1,
+		BagGroupID:           1,
+		Reason:               "Audit",
+		PlacedByID:           2,
+		ExpiresAt:            time.Now().UTC().AddDate(0, 0, -1),
+	}
+	err = hold.Validate()
+	require.NotNil(t, err)
+	assert.Equal(t, pgmodels.ErrLegalHoldTarget, err.Errors["IntellectualObjectID"])
+	assert.Equal(t, pgmodels.ErrLegalHoldExpiration, err.Errors["ExpiresAt"])
+
+	hold.BagGroupID = 0
+	hold.ExpiresAt = time.Now().UTC().AddDate(0, 1, 0)
+	assert.Nil(t, hold.Validate())
+}
+
+func TestLegalHoldByID(t *testing.T) {
+	db.LoadFixtures()
+	hold, err := pgmodels.LegalHoldByID(1)
+	require.Nil(t, err)
+	assert.Equal(t, int64(10), hold.IntellectualObjectID)
+	assert.True(t, hold.IsActive())
+
+	// Released
+	hold, err = pgmodels.LegalHoldByID(2)
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), hold.BagGroupID)
+	assert.False(t, hold.IsActive())
+
+	// Expired
+	hold, err = pgmodels.LegalHoldByID(3)
+	require.Nil(t, err)
+	assert.False(t, hold.IsActive())
+}
+
+func TestLegalHoldSaveAndRelease(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	// Object 1 belongs to institution 2, not 3.
+	hold := &pgmodels.LegalHold{
+		InstitutionID:        3,
+		IntellectualObjectID: 1,
+		Reason:               "Wrong institution",
+		PlacedByID:           5,
+	}
+	err := hold.Save()
+	require.NotNil(t, err)
+	valErr, ok := err.(*common.ValidationError)
+	require.True(t, ok)
+	assert.Equal(t, pgmodels.ErrLegalHoldWrongInst, valErr.Errors["InstitutionID"])
+
+	hold.InstitutionID = 2
+	hold.PlacedByID = 2
+	require.Nil(t, hold.Save())
+	assert.True(t, hold.ID > 0)
+	assert.Equal(t, common.ErrLegalHold, pgmodels.AssertNoLegalHold(1))
+
+	user, err := pgmodels.UserByID(2)
+	require.Nil(t, err)
+	require.Nil(t, hold.Release(user))
+	assert.False(t, hold.IsActive())
+	assert.Equal(t, user.ID, hold.ReleasedByID)
+	assert.Nil(t, pgmodels.AssertNoLegalHold(1))
+}
+
+func TestAssertNoLegalHold(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	// Object 10 has an active hold. Object 11's hold has expired.
+	// Object 3's bag group, carolina-2, had a hold that was released.
+	assert.Equal(t, common.ErrLegalHold, pgmodels.AssertNoLegalHold(10))
+	assert.Equal(t, common.ErrLegalHold, pgmodels.AssertNoLegalHold(1, 10))
+	assert.Nil(t, pgmodels.AssertNoLegalHold(11))
+	assert.Nil(t, pgmodels.AssertNoLegalHold(1, 3))
+	assert.Nil(t, pgmodels.AssertNoLegalHold())
+
+	// A hold on a bag group applies to every object in the group.
+	// Bag group 3 (dakota-1) contains object 12.
+	hold := &pgmodels.LegalHold{
+		InstitutionID: 3,
+		BagGroupID:    3,
+		Reason:        "Bag group hold test",
+		PlacedByID:    5,
+	}
+	require.Nil(t, hold.Save())
+	assert.Equal(t, common.ErrLegalHold, pgmodels.AssertNoLegalHold(12))
+	assert.Nil(t, pgmodels.AssertNoLegalHold(13))
+}
@@ -0,0 +1,100 @@
+package pgmodels
+
+import (
+	"time"
+)
+
+// LegalHoldFilters describes the allowed filters for searching
+// legal holds.
+var LegalHoldFilters = []string{
+	"active",
+	"bag_group_id",
+	"created_at__gteq",
+	"created_at__lteq",
+	"institution_id",
+	"intellectual_object_id",
+	"placed_by_id",
+}
+
+// LegalHoldView is a read-only model describing a legal hold along
+// with the names of its institution, target and the users who placed
+// and released it.
+type LegalHoldView struct {
+	tableName            struct{}  `pg:"legal_holds_view"`
+	ID                   int64     `json:"id"`
+	InstitutionID        int64     `json:"institution_id"`
+	InstitutionName      string    `json:"institution_name"`
+	IntellectualObjectID int64     `json:"intellectual_object_id"`
+	ObjectIdentifier     string    `json:"object_identifier"`
+	BagGroupID           int64     `json:"bag_group_id"`
+	BagGroupIdentifier   string    `json:"bag_group_identifier"`
+	Reason               string    `json:"reason"`
+	PlacedByID           int64     `json:"placed_by_id"`
+	PlacedByName         string    `json:"placed_by_name"`
+	PlacedByEmail        string    `json:"placed_by_email"`
+	ExpiresAt            time.Time `json:"expires_at"`
+	ReleasedAt           time.Time `json:"released_at"`
+	ReleasedByID         int64     `json:"released_by_id"`
+	ReleasedByName       string    `json:"released_by_name"`
+	Active               bool      `json:"active"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// LegalHoldViewByID returns the legal hold with the specified id.
+// Returns pg.ErrNoRows if there is no match.
+func LegalHoldViewByID(id int64) (*LegalHoldView, error) {
+	query := NewQuery().Where(`"legal_hold_view"."id"`, "=", id)
+	return LegalHoldViewGet(query)
+}
+
+// LegalHoldViewGet returns the first legal hold matching the query.
+func LegalHoldViewGet(query *Query) (*LegalHoldView, error) {
+	var hold LegalHoldView
+	err := query.Select(&hold)
+	return &hold, err
+}
+
+// LegalHoldViewSelect returns all legal holds matching the query.
+func LegalHoldViewSelect(query *Query) ([]*LegalHoldView, error) {
+	var holds []*LegalHoldView
+	err := query.Select(&holds)
+	return holds, err
+}
+
+// LegalHoldsForObject returns the active legal holds that prevent
+// deletion of the specified object, including holds on the bag group
+// with the specified identifier. Pass the object's institution id and
+// bag group identifier, so callers can use either an IntellectualObject
+// or an IntellectualObjectView.
+func LegalHoldsForObject(institutionID, objID int64, bagGroupIdentifier string) ([]*LegalHoldView, error) {
+	query := NewQuery().
+		Where(`"legal_hold_view"."active"`, "=", true).
+		OrderBy(`"legal_hold_view"."created_at"`, "desc")
+	bagGroup, err := BagGroupByIdentifier(institutionID, bagGroupIdentifier)
+	if err == nil {
+		query.Or(
+			[]string{`"legal_hold_view"."intellectual_object_id"`, `"legal_hold_view"."bag_group_id"`},
+			[]string{"=", "="},
+			[]interface{}{objID, bagGroup.ID})
+	} else if IsNoRowError(err) {
+		query.Where(`"legal_hold_view"."intellectual_object_id"`, "=", objID)
+	} else {
+		return nil, err
+	}
+	return LegalHoldViewSelect(query)
+}
+
+// LegalHoldsForBagGroup returns all legal holds, active or not,
+// placed on the specified bag group.
+func LegalHoldsForBagGroup(bagGroupID int64) ([]*LegalHoldView, error) {
+	query := NewQuery().
+		Where(`"legal_hold_view"."bag_group_id"`, "=", bagGroupID).
+		OrderBy(`"legal_hold_view"."created_at"`, "desc")
+	return LegalHoldViewSelect(query)
+}
+
+// GetID returns this hold's id.
+func (hold *LegalHoldView) GetID() int64 {
+	return hold.ID
+}
@@ -0,0 +1,76 @@
+package pgmodels_test
+
+import (
+	"testing"
+
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegalHoldViewByID(t *testing.T) {
+	db.LoadFixtures()
+	hold, err := pgmodels.LegalHoldViewByID(1)
+	require.Nil(t, err)
+	assert.Equal(t, "Institution One", hold.InstitutionName)
+	assert.Equal(t, "admin@inst1.edu", hold.PlacedByEmail)
+	assert.NotEmpty(t, hold.ObjectIdentifier)
+	assert.True(t, hold.Active)
+
+	hold, err = pgmodels.LegalHoldViewByID(2)
+	require.Nil(t, err)
+	assert.Equal(t, "carolina-2", hold.BagGroupIdentifier)
+	assert.NotEmpty(t, hold.ReleasedByName)
+	assert.False(t, hold.Active)
+
+	hold, err = pgmodels.LegalHoldViewByID(3)
+	require.Nil(t, err)
+	assert.False(t, hold.Active)
+}
+
+func TestLegalHoldsForObject(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	obj, err := pgmodels.IntellectualObjectByID(10)
+	require.Nil(t, err)
+	holds, err := pgmodels.LegalHoldsForObject(obj.InstitutionID, obj.ID, obj.BagGroupIdentifier)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(holds))
+	assert.Equal(t, int64(1), holds[0].ID)
+
+	// Expired holds don't count.
+	obj, err = pgmodels.IntellectualObjectByID(11)
+	require.Nil(t, err)
+	holds, err = pgmodels.LegalHoldsForObject(obj.InstitutionID, obj.ID, obj.BagGroupIdentifier)
+	require.Nil(t, err)
+	assert.Empty(t, holds)
+
+	// Holds on the object's bag group do.
+	obj, err = pgmodels.IntellectualObjectByID(12)
+	require.Nil(t, err)
+	hold := &pgmodels.LegalHold{
+		InstitutionID: 3,
+		BagGroupID:    3,
+		Reason:        "Bag group hold test",
+		PlacedByID:    5,
+	}
+	require.Nil(t, hold.Save())
+	holds, err = pgmodels.LegalHoldsForObject(obj.InstitutionID, obj.ID, obj.BagGroupIdentifier)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(holds))
+	assert.Equal(t, hold.ID, holds[0].ID)
+}
+
+func TestLegalHoldsForBagGroup(t *testing.T) {
+	db.LoadFixtures()
+	holds, err := pgmodels.LegalHoldsForBagGroup(2)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(holds))
+	assert.False(t, holds[0].Active)
+
+	holds, err = pgmodels.LegalHoldsForBagGroup(1)
+	require.Nil(t, err)
+	assert.Empty(t, holds)
+}
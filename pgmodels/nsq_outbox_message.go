@@ -0,0 +1,317 @@
+package pgmodels
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/stew/slice"
+)
+
+// NSQOutboxMaxAttempts is the number of times we'll try to push a
+// WorkItem into NSQ before giving up on it. See
+// CreateUnqueuedWorkItemsAlert for how we tell admins about messages
+// that fail.
+const NSQOutboxMaxAttempts = 10
+
+// NSQOutboxRetention is how long we keep delivered messages. After
+// that, NSQOutboxDeleteDelivered removes them. We keep failed messages,
+// since admins may need them to figure out what went wrong.
+const NSQOutboxRetention = 30 * 24 * time.Hour
+
+// nsqOutboxLease is how long a process may spend trying to deliver a
+// batch of claimed messages before another process can claim them.
+const nsqOutboxLease = 5 * time.Minute
+
+// NSQOutboxMessage tells the outbox cron job to push a WorkItem's id
+// into an NSQ topic. We write these in the same transaction that saves
+// the WorkItem (see WorkItem.SaveAndEnqueue), so an item can't be saved
+// without being queued, even if NSQ is down or Registry crashes before
+// queueing it. Pending messages are retried with exponential backoff
+// until they're delivered or until we've tried NSQOutboxMaxAttempts
+// times.
+type NSQOutboxMessage struct {
+	tableName struct{} `pg:"nsq_outbox_messages,alias:nsq_outbox_message"`
+	TimestampModel
+	WorkItemID    int64     `json:"work_item_id" pg:"work_item_id"`
+	Topic         string    `json:"topic" pg:"topic"`
+	Status        string    `json:"status" pg:"status"`
+	Attempts      int       `json:"attempts" pg:"attempts,use_zero"`
+	NextAttemptAt time.Time `json:"next_attempt_at" pg:"next_attempt_at"`
+	LastAttemptAt time.Time `json:"last_attempt_at" pg:"last_attempt_at"`
+	DeliveredAt   time.Time `json:"delivered_at" pg:"delivered_at"`
+	Error         string    `json:"error" pg:"error"`
+}
+
+// NSQOutboxMessageByID returns the message with the specified id.
+// Returns pg.ErrNoRows if there is no match.
+func NSQOutboxMessageByID(id int64) (*NSQOutboxMessage, error) {
+	query := NewQuery().Where("id", "=", id)
+	return NSQOutboxMessageGet(query)
+}
+
+// NSQOutboxMessageGet returns the first message matching the query.
+func NSQOutboxMessageGet(query *Query) (*NSQOutboxMessage, error) {
+	var msg NSQOutboxMessage
+	err := query.Select(&msg)
+	return &msg, err
+}
+
+// NSQOutboxMessageSelect returns all messages matching the query.
+func NSQOutboxMessageSelect(query *Query) ([]*NSQOutboxMessage, error) {
+	var messages []*NSQOutboxMessage
+	err := query.Select(&messages)
+	return messages, err
+}
+
+// NSQOutboxClaimDue returns up to limit pending messages that are due
+// to be delivered. It pushes their next attempt time a few minutes into
+// the future so that other Registry instances running the same cron
+// job won't deliver them too.
+func NSQOutboxClaimDue(limit int) ([]*NSQOutboxMessage, error) {
+	now := time.Now().UTC()
+	var messages []*NSQOutboxMessage
+	sql := `update nsq_outbox_messages set next_attempt_at = ?, updated_at = ?
+	        where id in (
+	          select id from nsq_outbox_messages
+	          where status = ? and next_attempt_at <= ?
+	          order by next_attempt_at
+	          limit ?
+	          for update skip locked)
+	        returning *`
+	_, err := common.Context().DB.Query(&messages, sql, now.Add(nsqOutboxLease), now, constants.OutboxStatusPending, now, limit)
+	return messages, err
+}
+
+// NSQOutboxHasPending returns true if the WorkItem has a message
+// waiting to be delivered to NSQ.
+func NSQOutboxHasPending(workItemID int64) (bool, error) {
+	return common.Context().DB.Model((*NSQOutboxMessage)(nil)).
+		Where("work_item_id = ?", workItemID).
+		Where("status = ?", constants.OutboxStatusPending).
+		Exists()
+}
+
+// NSQOutboxDeleteDelivered deletes messages that were delivered before
+// the specified time, and returns the number of messages deleted.
+func NSQOutboxDeleteDelivered(before time.Time) (int, error) {
+	result, err := common.Context().DB.Model((*NSQOutboxMessage)(nil)).
+		Where("status = ?", constants.OutboxStatusDelivered).
+		Where("delivered_at < ?", before).
+		Delete()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// CreateUnqueuedWorkItemsAlert creates one alert telling APTrust admins
+// about WorkItems whose outbox messages have failed since we last
+// alerted them. These items never made it into NSQ, so no worker will
+// pick them up until an admin requeues them. Each item in the alert
+// links to its requeue form. Param registryURL is the scheme and host
+// to use in those links. This returns nil and no error if no messages
+// have newly failed.
+func CreateUnqueuedWorkItemsAlert(registryURL string) (*Alert, error) {
+	query := NewQuery().
+		Where("status", "=", constants.OutboxStatusFailed).
+		Where("updated_at", ">", time.Now().UTC().Add(-NSQOutboxRetention)).
+		OrderBy("id", "asc")
+	failed, err := NSQOutboxMessageSelect(query)
+	if err != nil || len(failed) == 0 {
+		return nil, err
+	}
+	ids := make([]int64, len(failed))
+	for i, msg := range failed {
+		ids[i] = msg.WorkItemID
+	}
+	lastAlerted, err := workItemsLastAlerted(constants.AlertUnqueuedItems, ids)
+	if err != nil {
+		return nil, err
+	}
+	var messages []*NSQOutboxMessage
+	var itemIDs []interface{}
+	for _, msg := range failed {
+		alertedAt, ok := lastAlerted[msg.WorkItemID]
+		if ok && alertedAt.After(msg.UpdatedAt) {
+			continue
+		}
+		messages = append(messages, msg)
+		itemIDs = append(itemIDs, msg.WorkItemID)
+	}
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	items, err := WorkItemSelect(NewQuery().WhereIn("id", itemIDs...).OrderBy("id", "asc"))
+	if err != nil {
+		return nil, err
+	}
+	itemsByID := make(map[int64]*WorkItem, len(items))
+	for _, item := range items {
+		itemsByID[item.ID] = item
+	}
+	aptrust, err := InstitutionByIdentifier("aptrust.org")
+	if err != nil {
+		return nil, err
+	}
+	adminsQuery := NewQuery().
+		Where("role", "=", constants.RoleSysAdmin).
+		IsNull("deactivated_at")
+	admins, err := UserSelect(adminsQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	itemData := make([]map[string]interface{}, 0, len(messages))
+	for _, msg := range messages {
+		item, ok := itemsByID[msg.WorkItemID]
+		if !ok {
+			continue
+		}
+		itemData = append(itemData, map[string]interface{}{
+			"ID":         item.ID,
+			"Name":       item.Name,
+			"Action":     item.Action,
+			"Stage":      item.Stage,
+			"Status":     item.Status,
+			"Topic":      msg.Topic,
+			"Attempts":   msg.Attempts,
+			"Error":      msg.Error,
+			"FailedAt":   msg.UpdatedAt.Format(time.RFC3339),
+			"RequeueURL": fmt.Sprintf("%s/work_items/show/%d#workItemRequeueForm", registryURL, item.ID),
+		})
+	}
+	alertData := map[string]interface{}{
+		"ItemCount": len(items),
+		"Items":     itemData,
+	}
+	alert := &Alert{
+		InstitutionID: aptrust.ID,
+		Type:          constants.AlertUnqueuedItems,
+		Subject:       fmt.Sprintf("%d Unqueued Work Items", len(items)),
+		Users:         admins,
+		WorkItems:     items,
+	}
+	return CreateAlert(alert, "alerts/unqueued_items.txt", alertData)
+}
+
+// Save saves this message to the database. This will peform an insert
+// if NSQOutboxMessage.ID is zero. Otherwise, it updates.
+func (msg *NSQOutboxMessage) Save() error {
+	msg.SetTimestamps()
+	err := msg.Validate()
+	if err != nil {
+		return err
+	}
+	if msg.ID == int64(0) {
+		return insert(msg)
+	}
+	return update(msg)
+}
+
+// Validate returns errors if this message is not valid.
+func (msg *NSQOutboxMessage) Validate() *common.ValidationError {
+	errors := make(map[string]string)
+	if msg.WorkItemID < 1 {
+		errors["WorkItemID"] = "WorkItemID is required."
+	}
+	if common.IsEmptyString(msg.Topic) {
+		errors["Topic"] = "Topic is required."
+	}
+	if !slice.Contains(constants.OutboxStatuses, msg.Status) {
+		errors["Status"] = "Status is missing or invalid."
+	}
+	if len(errors) > 0 {
+		return &common.ValidationError{Errors: errors}
+	}
+	return nil
+}
+
+// Attempt tries to push this message's WorkItem id into its NSQ topic,
+// records the outcome, and saves this record. On success, it also sets
+// the WorkItem's QueuedAt timestamp. This does nothing if the message
+// has already been delivered or has failed, so delivering a message
+// twice can't queue its item twice. The returned error describes
+// problems saving the record, not failure to deliver.
+func (msg *NSQOutboxMessage) Attempt() error {
+	if msg.Status != constants.OutboxStatusPending {
+		return nil
+	}
+	now := time.Now().UTC()
+	msg.Attempts++
+	msg.LastAttemptAt = now
+	msg.Error = ""
+
+	err := common.Context().NSQClient.Enqueue(msg.Topic, msg.WorkItemID)
+	if err == nil {
+		msg.Status = constants.OutboxStatusDelivered
+		msg.DeliveredAt = now
+		msg.NextAttemptAt = time.Time{}
+		return msg.saveDelivered()
+	}
+	msg.Error = err.Error()
+	if msg.Attempts >= NSQOutboxMaxAttempts {
+		msg.Status = constants.OutboxStatusFailed
+		msg.NextAttemptAt = time.Time{}
+	} else {
+		msg.NextAttemptAt = now.Add(NSQOutboxBackoff(msg.Attempts))
+	}
+	return msg.Save()
+}
+
+// saveDelivered saves this message and sets its WorkItem's QueuedAt
+// timestamp in a single transaction. We update only queued_at, so we
+// don't overwrite changes a worker makes to the item in the meantime.
+func (msg *NSQOutboxMessage) saveDelivered() error {
+	msg.SetTimestamps()
+	db := common.Context().DB
+	return db.RunInTransaction(db.Context(), func(tx *pg.Tx) error {
+		_, err := tx.Model(msg).WherePK().Update()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Model((*WorkItem)(nil)).
+			Set("queued_at = ?", msg.DeliveredAt).
+			Where("id = ?", msg.WorkItemID).
+			Update()
+		return err
+	})
+}
+
+// NSQOutboxBackoff returns how long to wait before the next delivery
+// attempt after the specified number of failed attempts. The wait
+// doubles with each attempt, starting at 15 seconds.
+func NSQOutboxBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return 15 * time.Second * time.Duration(1<<uint(attempts-1))
+}
+
+// insertNSQOutboxMessage adds a pending message for workItemID to the
+// outbox inside transaction tx. If the item already has a pending
+// message for topic, this inserts nothing and returns nil. The new
+// message isn't due until the lease expires, which gives the caller
+// time to try delivering it right away.
+func insertNSQOutboxMessage(tx *pg.Tx, workItemID int64, topic string) (*NSQOutboxMessage, error) {
+	msg := &NSQOutboxMessage{
+		WorkItemID:    workItemID,
+		Topic:         topic,
+		Status:        constants.OutboxStatusPending,
+		NextAttemptAt: time.Now().UTC().Add(nsqOutboxLease),
+	}
+	msg.SetTimestamps()
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	result, err := tx.Model(msg).OnConflict("DO NOTHING").Insert()
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected() == 0 {
+		return nil, nil
+	}
+	return msg, nil
+}
@@ -0,0 +1,219 @@
+package pgmodels_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/network"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNSQOutboxBackoff(t *testing.T) {
+	assert.Equal(t, 15*time.Second, pgmodels.NSQOutboxBackoff(1))
+	assert.Equal(t, 30*time.Second, pgmodels.NSQOutboxBackoff(2))
+	assert.Equal(t, 16*time.Minute, pgmodels.NSQOutboxBackoff(7))
+}
+
+func TestNSQOutboxMessageValidate(t *testing.T) {
+	msg := &pgmodels.NSQOutboxMessage{}
+	err := msg.Validate()
+	require.NotNil(t, err)
+	assert.Equal(t, 3, len(err.Errors))
+
+	msg.WorkItemID = 1
+	msg.Topic = constants.TopicDelete
+	msg.Status = constants.OutboxStatusPending
+	assert.Nil(t, msg.Validate())
+}
+
+func TestWorkItemSaveAndEnqueue(t *testing.T) {
+	db.LoadFixtures()
+	ctx := common.Context()
+
+	// Stand in for nsqd, so we can make it fail.
+	responseCode := http.StatusInternalServerError
+	var published []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		published = append(published, r.URL.Query().Get("topic"))
+		w.WriteHeader(responseCode)
+	}))
+	defer server.Close()
+	nsqClient := ctx.NSQClient
+	ctx.NSQClient = network.NewNSQClient(server.URL, ctx.Log)
+	defer func() { ctx.NSQClient = nsqClient }()
+
+	// If NSQ is down, the item is saved with a pending message.
+	item := pgmodels.RandomWorkItem("outbox_test.tar", constants.ActionDelete, 0, 0)
+	item.QueuedAt = time.Time{}
+	require.Nil(t, item.SaveAndEnqueue(constants.TopicDelete))
+	require.True(t, item.ID > 0)
+	assert.True(t, item.QueuedAt.IsZero())
+	assert.Equal(t, []string{constants.TopicDelete}, published)
+
+	query := pgmodels.NewQuery().Where("work_item_id", "=", item.ID)
+	messages, err := pgmodels.NSQOutboxMessageSelect(query)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(messages))
+	msg := messages[0]
+	assert.Equal(t, constants.OutboxStatusPending, msg.Status)
+	assert.Equal(t, 1, msg.Attempts)
+	assert.NotEmpty(t, msg.Error)
+	assert.True(t, msg.NextAttemptAt.After(time.Now().UTC()))
+
+	pending, err := pgmodels.NSQOutboxHasPending(item.ID)
+	require.Nil(t, err)
+	assert.True(t, pending)
+
+	// Saving the same request again doesn't queue the item twice.
+	require.Nil(t, item.SaveAndEnqueue(constants.TopicDelete))
+	count, err := query.Count(&pgmodels.NSQOutboxMessage{})
+	require.Nil(t, err)
+	assert.Equal(t, 1, count)
+
+	// The message isn't due yet, so the cron job won't claim it.
+	claimed, err := pgmodels.NSQOutboxClaimDue(100)
+	require.Nil(t, err)
+	for _, c := range claimed {
+		assert.NotEqual(t, msg.ID, c.ID)
+	}
+
+	// Once it's due, the cron job claims it once and delivers it.
+	msg.NextAttemptAt = time.Now().UTC().Add(-1 * time.Second)
+	require.Nil(t, msg.Save())
+	claimed, err = pgmodels.NSQOutboxClaimDue(100)
+	require.Nil(t, err)
+	var claimedMsg *pgmodels.NSQOutboxMessage
+	for _, c := range claimed {
+		if c.ID == msg.ID {
+			claimedMsg = c
+		}
+	}
+	require.NotNil(t, claimedMsg)
+	claimed, err = pgmodels.NSQOutboxClaimDue(100)
+	require.Nil(t, err)
+	for _, c := range claimed {
+		assert.NotEqual(t, msg.ID, c.ID)
+	}
+
+	// Delivery sets only the item's queued_at, so it doesn't undo
+	// anything a worker changed after the message was saved.
+	_, err = ctx.DB.Exec("update work_items set note = 'Changed by worker' where id = ?", item.ID)
+	require.Nil(t, err)
+
+	responseCode = http.StatusOK
+	require.Nil(t, claimedMsg.Attempt())
+	assert.Equal(t, constants.OutboxStatusDelivered, claimedMsg.Status)
+	assert.Equal(t, 2, claimedMsg.Attempts)
+	assert.Empty(t, claimedMsg.Error)
+	assert.False(t, claimedMsg.DeliveredAt.IsZero())
+
+	savedItem, err := pgmodels.WorkItemByID(item.ID)
+	require.Nil(t, err)
+	assert.Equal(t, claimedMsg.DeliveredAt.Unix(), savedItem.QueuedAt.Unix())
+	assert.Equal(t, "Changed by worker", savedItem.Note)
+
+	// Delivered messages aren't sent again.
+	publishCount := len(published)
+	require.Nil(t, claimedMsg.Attempt())
+	assert.Equal(t, publishCount, len(published))
+
+	// Now that nothing is pending, requeueing adds a new message,
+	// and delivers it right away.
+	require.Nil(t, item.SetForRequeue(constants.StageRequested))
+	assert.False(t, item.QueuedAt.IsZero())
+	count, err = query.Count(&pgmodels.NSQOutboxMessage{})
+	require.Nil(t, err)
+	assert.Equal(t, 2, count)
+	pending, err = pgmodels.NSQOutboxHasPending(item.ID)
+	require.Nil(t, err)
+	assert.False(t, pending)
+}
+
+func TestNSQOutboxMessageFails(t *testing.T) {
+	db.LoadFixtures()
+	ctx := common.Context()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	nsqClient := ctx.NSQClient
+	ctx.NSQClient = network.NewNSQClient(server.URL, ctx.Log)
+	defer func() { ctx.NSQClient = nsqClient }()
+
+	item := pgmodels.RandomWorkItem("outbox_fail_test.tar", constants.ActionDelete, 0, 0)
+	require.Nil(t, item.Save())
+	msg := &pgmodels.NSQOutboxMessage{
+		WorkItemID:    item.ID,
+		Topic:         constants.TopicDelete,
+		Status:        constants.OutboxStatusPending,
+		Attempts:      pgmodels.NSQOutboxMaxAttempts - 1,
+		NextAttemptAt: time.Now().UTC(),
+	}
+	require.Nil(t, msg.Save())
+
+	// We give up after the last attempt.
+	require.Nil(t, msg.Attempt())
+	assert.Equal(t, constants.OutboxStatusFailed, msg.Status)
+	assert.Equal(t, pgmodels.NSQOutboxMaxAttempts, msg.Attempts)
+	assert.True(t, msg.NextAttemptAt.IsZero())
+	assert.NotEmpty(t, msg.Error)
+
+	saved, err := pgmodels.NSQOutboxMessageByID(msg.ID)
+	require.Nil(t, err)
+	assert.Equal(t, constants.OutboxStatusFailed, saved.Status)
+
+	// Admins hear about the failure once.
+	alert, err := pgmodels.CreateUnqueuedWorkItemsAlert("https://example.com")
+	require.Nil(t, err)
+	require.NotNil(t, alert)
+	assert.Equal(t, constants.AlertUnqueuedItems, alert.Type)
+	require.Equal(t, 1, len(alert.WorkItems))
+	assert.Equal(t, item.ID, alert.WorkItems[0].ID)
+	assert.Contains(t, alert.Content, item.Name)
+	assert.Contains(t, alert.Content, fmt.Sprintf("https://example.com/work_items/show/%d#workItemRequeueForm", item.ID))
+	assert.NotEmpty(t, alert.Users)
+
+	alert, err = pgmodels.CreateUnqueuedWorkItemsAlert("https://example.com")
+	require.Nil(t, err)
+	assert.Nil(t, alert)
+}
+
+func TestNSQOutboxDeleteDelivered(t *testing.T) {
+	db.LoadFixtures()
+	item := pgmodels.RandomWorkItem("outbox_cleanup_test.tar", constants.ActionDelete, 0, 0)
+	require.Nil(t, item.Save())
+
+	now := time.Now().UTC()
+	newMessage := func(status string, deliveredAt time.Time) *pgmodels.NSQOutboxMessage {
+		msg := &pgmodels.NSQOutboxMessage{
+			WorkItemID:  item.ID,
+			Topic:       constants.TopicDelete,
+			Status:      status,
+			DeliveredAt: deliveredAt,
+		}
+		require.Nil(t, msg.Save())
+		return msg
+	}
+	oldDelivered := newMessage(constants.OutboxStatusDelivered, now.Add(-31*24*time.Hour))
+	newDelivered := newMessage(constants.OutboxStatusDelivered, now.Add(-1*time.Hour))
+	failed := newMessage(constants.OutboxStatusFailed, time.Time{})
+
+	count, err := pgmodels.NSQOutboxDeleteDelivered(now.Add(-pgmodels.NSQOutboxRetention))
+	require.Nil(t, err)
+	assert.Equal(t, 1, count)
+
+	_, err = pgmodels.NSQOutboxMessageByID(oldDelivered.ID)
+	assert.True(t, pgmodels.IsNoRowError(err))
+	_, err = pgmodels.NSQOutboxMessageByID(newDelivered.ID)
+	assert.Nil(t, err)
+	_, err = pgmodels.NSQOutboxMessageByID(failed.ID)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,314 @@
+package pgmodels
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/APTrust/registry/common"
+)
+
+const (
+	ErrNSQPauseTopic     = "Please choose a topic to pause."
+	ErrNSQPauseReason    = "Please explain the reason for this pause."
+	ErrNSQPauseStart     = "Please enter a start time."
+	ErrNSQPauseEnd       = "End time must be after the start time and in the future."
+	ErrNSQPauseCreatedBy = "Pause window requires a valid created by id."
+)
+
+// These describe where a pause window is in its life cycle.
+const (
+	NSQPauseScheduled = "Scheduled"
+	NSQPauseActive    = "Active"
+	NSQPauseCompleted = "Completed"
+	NSQPauseCancelled = "Cancelled"
+)
+
+// nsqPauseWindowsTTL is how long we cache current and upcoming pause
+// windows for the banner at the top of each page.
+const nsqPauseWindowsTTL = time.Minute
+
+// NSQPauseWindow pauses an NSQ topic, or one channel of a topic, for a
+// scheduled period. APTrust admins schedule these ahead of planned
+// maintenance, such as a storage provider's Glacier outage, so workers
+// stop pulling items they can't process. The cron job in
+// ApplyNSQPauseWindows pauses the topic or channel when the window
+// starts and unpauses it when the window ends or someone cancels it.
+// PausedAt and UnpausedAt record when that actually happened. If NSQ
+// returns an error, Error describes it and the cron job tries again
+// on its next run.
+//
+// Windows are never deleted, so we keep a record of who paused what,
+// when, and why.
+type NSQPauseWindow struct {
+	tableName struct{} `pg:"nsq_pause_windows,alias:nsq_pause_window"`
+	TimestampModel
+	AuditedModel
+	Topic         string    `json:"topic" pg:"topic"`
+	Channel       string    `json:"channel" pg:"channel"`
+	Reason        string    `json:"reason" pg:"reason"`
+	StartsAt      time.Time `json:"starts_at" time_format:"2006-01-02T15:04" time_utc:"1" pg:"starts_at"`
+	EndsAt        time.Time `json:"ends_at" time_format:"2006-01-02T15:04" time_utc:"1" pg:"ends_at"`
+	CreatedByID   int64     `json:"created_by_id" form:"-" pg:"created_by_id"`
+	CancelledAt   time.Time `json:"cancelled_at" form:"-" pg:"cancelled_at"`
+	CancelledByID int64     `json:"cancelled_by_id" form:"-" pg:"cancelled_by_id"`
+	PausedAt      time.Time `json:"paused_at" form:"-" pg:"paused_at"`
+	UnpausedAt    time.Time `json:"unpaused_at" form:"-" pg:"unpaused_at"`
+	Error         string    `json:"error" form:"-" pg:"error"`
+	CreatedBy     *User     `json:"-" form:"-" pg:"rel:has-one"`
+	CancelledBy   *User     `json:"-" form:"-" pg:"rel:has-one"`
+}
+
+// nsqPauseWindows caches windows that haven't yet ended, so we don't
+// have to query the database on every page load to show the banner.
+// Saving a window clears the cache in this process. Other Registry
+// processes pick up the change within nsqPauseWindowsTTL.
+var nsqPauseWindows = struct {
+	sync.Mutex
+	loadedAt time.Time
+	windows  []*NSQPauseWindow
+}{}
+
+// NSQPauseWindowByID returns the pause window with the specified id.
+// Returns pg.ErrNoRows if there is no match.
+func NSQPauseWindowByID(id int64) (*NSQPauseWindow, error) {
+	query := NewQuery().Where(`"nsq_pause_window"."id"`, "=", id)
+	return NSQPauseWindowGet(query)
+}
+
+// NSQPauseWindowGet returns the first pause window matching the query.
+func NSQPauseWindowGet(query *Query) (*NSQPauseWindow, error) {
+	var window NSQPauseWindow
+	err := query.Select(&window)
+	return &window, err
+}
+
+// NSQPauseWindowSelect returns all pause windows matching the query.
+func NSQPauseWindowSelect(query *Query) ([]*NSQPauseWindow, error) {
+	var windows []*NSQPauseWindow
+	err := query.Select(&windows)
+	return windows, err
+}
+
+// NSQPauseWindowsActive returns the windows that are in effect right
+// now, for the banner at the top of each page. This reads from a cache
+// that may be up to a minute old.
+func NSQPauseWindowsActive() ([]*NSQPauseWindow, error) {
+	nsqPauseWindows.Lock()
+	defer nsqPauseWindows.Unlock()
+	now := time.Now().UTC()
+	if now.Sub(nsqPauseWindows.loadedAt) > nsqPauseWindowsTTL {
+		query := NewQuery().
+			IsNull("cancelled_at").
+			Where("ends_at", ">", now).
+			OrderBy("starts_at", "asc")
+		windows, err := NSQPauseWindowSelect(query)
+		if err != nil {
+			return nil, err
+		}
+		nsqPauseWindows.windows = windows
+		nsqPauseWindows.loadedAt = now
+	}
+	active := make([]*NSQPauseWindow, 0)
+	for _, window := range nsqPauseWindows.windows {
+		if window.IsActive() {
+			active = append(active, window)
+		}
+	}
+	return active, nil
+}
+
+// ClearNSQPauseWindowCache forces the next call to NSQPauseWindowsActive
+// to reload windows from the database.
+func ClearNSQPauseWindowCache() {
+	nsqPauseWindows.Lock()
+	defer nsqPauseWindows.Unlock()
+	nsqPauseWindows.loadedAt = time.Time{}
+}
+
+// ApplyNSQPauseWindows pauses the topics and channels of windows that
+// have started, and unpauses those of windows that have ended or were
+// cancelled. If NSQ returns an error for a window, we record it on the
+// window and try again next time. This returns an error only if we
+// can't read or save windows.
+func ApplyNSQPauseWindows() error {
+	now := time.Now().UTC()
+	ending, err := NSQPauseWindowSelect(NewQuery().
+		IsNotNull("paused_at").
+		IsNull("unpaused_at").
+		OrderBy("id", "asc"))
+	if err != nil {
+		return err
+	}
+	for _, window := range ending {
+		if window.IsActive() {
+			continue
+		}
+		if err := window.unpause(); err != nil {
+			return err
+		}
+	}
+	starting, err := NSQPauseWindowSelect(NewQuery().
+		IsNull("paused_at").
+		IsNull("cancelled_at").
+		Where("starts_at", "<=", now).
+		Where("ends_at", ">", now).
+		OrderBy("id", "asc"))
+	if err != nil {
+		return err
+	}
+	for _, window := range starting {
+		if err := window.pause(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save saves this window to the database. This will peform an insert
+// if NSQPauseWindow.ID is zero. Otherwise, it updates.
+func (w *NSQPauseWindow) Save() error {
+	w.SetTimestamps()
+	w.Topic = strings.TrimSpace(w.Topic)
+	w.Channel = strings.TrimSpace(w.Channel)
+	w.Reason = strings.TrimSpace(w.Reason)
+	err := w.Validate()
+	if err != nil {
+		return err
+	}
+	defer ClearNSQPauseWindowCache()
+	if w.ID == int64(0) {
+		return insert(w)
+	}
+	return update(w)
+}
+
+// Validate returns errors if this window is not valid.
+func (w *NSQPauseWindow) Validate() *common.ValidationError {
+	errors := make(map[string]string)
+	if strings.TrimSpace(w.Topic) == "" {
+		errors["Topic"] = ErrNSQPauseTopic
+	}
+	if strings.TrimSpace(w.Reason) == "" {
+		errors["Reason"] = ErrNSQPauseReason
+	}
+	if w.StartsAt.IsZero() {
+		errors["StartsAt"] = ErrNSQPauseStart
+	}
+	if !w.EndsAt.After(w.StartsAt) || (w.ID == 0 && !w.EndsAt.After(time.Now().UTC())) {
+		errors["EndsAt"] = ErrNSQPauseEnd
+	}
+	if w.CreatedByID < 1 {
+		errors["CreatedByID"] = ErrNSQPauseCreatedBy
+	}
+	if len(errors) > 0 {
+		return &common.ValidationError{Errors: errors}
+	}
+	return nil
+}
+
+// Target describes the topic or channel this window pauses.
+func (w *NSQPauseWindow) Target() string {
+	if w.Channel != "" {
+		return fmt.Sprintf("channel %s of topic %s", w.Channel, w.Topic)
+	}
+	return fmt.Sprintf("topic %s", w.Topic)
+}
+
+// IsActive returns true if this window has started, has not ended,
+// and has not been cancelled.
+func (w *NSQPauseWindow) IsActive() bool {
+	now := time.Now().UTC()
+	return w.CancelledAt.IsZero() && !w.StartsAt.After(now) && w.EndsAt.After(now)
+}
+
+// Status returns one of the NSQPause status constants. A window that
+// has ended stays Active until the cron job unpauses its topic.
+func (w *NSQPauseWindow) Status() string {
+	if !w.CancelledAt.IsZero() {
+		return NSQPauseCancelled
+	}
+	if !w.UnpausedAt.IsZero() {
+		return NSQPauseCompleted
+	}
+	if w.IsActive() || !w.PausedAt.IsZero() {
+		return NSQPauseActive
+	}
+	if w.StartsAt.After(time.Now().UTC()) {
+		return NSQPauseScheduled
+	}
+	return NSQPauseCompleted
+}
+
+// Cancel cancels this window on behalf of user and saves it. If the
+// window's topic is already paused, the next call to
+// ApplyNSQPauseWindows unpauses it. Cancelling a window that has
+// already ended or been cancelled is a no-op.
+func (w *NSQPauseWindow) Cancel(user *User) error {
+	if !w.CancelledAt.IsZero() || !w.EndsAt.After(time.Now().UTC()) {
+		return nil
+	}
+	w.CancelledAt = time.Now().UTC()
+	w.CancelledByID = user.ID
+	return w.Save()
+}
+
+// pause pauses this window's topic or channel and records the outcome.
+func (w *NSQPauseWindow) pause() error {
+	nsqClient := common.Context().NSQClient
+	var err error
+	if w.Channel != "" {
+		err = nsqClient.PauseChannel(w.Topic, w.Channel)
+	} else {
+		err = nsqClient.PauseTopic(w.Topic)
+	}
+	if err != nil {
+		common.Context().Log.Error().Msgf("Could not pause %s for NSQ pause window %d: %v", w.Target(), w.ID, err)
+		w.Error = err.Error()
+	} else {
+		common.Context().Log.Info().Msgf("Paused %s for NSQ pause window %d", w.Target(), w.ID)
+		w.Error = ""
+		w.PausedAt = time.Now().UTC()
+	}
+	return w.Save()
+}
+
+// unpause unpauses this window's topic or channel and records the
+// outcome. If another window that covers the same topic or channel is
+// in effect, we leave it paused and let that window unpause it.
+func (w *NSQPauseWindow) unpause() error {
+	now := time.Now().UTC()
+	overlapping, err := common.Context().DB.Model((*NSQPauseWindow)(nil)).
+		Where("id != ?", w.ID).
+		Where("topic = ?", w.Topic).
+		Where("coalesce(channel, '') = ?", w.Channel).
+		Where("cancelled_at is null").
+		Where("starts_at <= ?", now).
+		Where("ends_at > ?", now).
+		Exists()
+	if err != nil {
+		return err
+	}
+	if overlapping {
+		common.Context().Log.Info().Msgf("NSQ pause window %d ended, but another window keeps %s paused", w.ID, w.Target())
+	} else {
+		nsqClient := common.Context().NSQClient
+		if w.Channel != "" {
+			err = nsqClient.UnpauseChannel(w.Topic, w.Channel)
+		} else {
+			err = nsqClient.UnpauseTopic(w.Topic)
+		}
+	}
+	if err != nil {
+		common.Context().Log.Error().Msgf("Could not unpause %s for NSQ pause window %d: %v", w.Target(), w.ID, err)
+		w.Error = err.Error()
+		return w.Save()
+	}
+	if !overlapping {
+		common.Context().Log.Info().Msgf("Unpaused %s for NSQ pause window %d", w.Target(), w.ID)
+	}
+	w.Error = ""
+	w.UnpausedAt = now
+	return w.Save()
+}
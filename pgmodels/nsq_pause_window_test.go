@@ -0,0 +1,169 @@
+package pgmodels_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/network"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNSQPauseWindowValidate(t *testing.T) {
+	window := &pgmodels.NSQPauseWindow{}
+	err := window.Validate()
+	require.NotNil(t, err)
+	assert.Equal(t, pgmodels.ErrNSQPauseTopic, err.Errors["Topic"])
+	assert.Equal(t, pgmodels.ErrNSQPauseReason, err.Errors["Reason"])
+	assert.Equal(t, pgmodels.ErrNSQPauseStart, err.Errors["StartsAt"])
+	assert.Equal(t, pgmodels.ErrNSQPauseEnd, err.Errors["EndsAt"])
+	assert.Equal(t, pgmodels.ErrNSQPauseCreatedBy, err.Errors["CreatedByID"])
+
+	// End must follow start, and new windows must end in the future.
+	now := time.Now().UTC()
+	window = &pgmodels.NSQPauseWindow{
+		Topic:       constants.TopicGlacierRestore,
+		Reason:      "Glacier maintenance",
+		StartsAt:    now.Add(-2 * time.Hour),
+		EndsAt:      now.Add(-1 * time.Hour),
+		CreatedByID: 1,
+	}
+	err = window.Validate()
+	require.NotNil(t, err)
+	assert.Equal(t, pgmodels.ErrNSQPauseEnd, err.Errors["EndsAt"])
+
+	window.EndsAt = now.Add(time.Hour)
+	assert.Nil(t, window.Validate())
+}
+
+func TestNSQPauseWindowStatus(t *testing.T) {
+	now := time.Now().UTC()
+	window := &pgmodels.NSQPauseWindow{
+		Topic:    constants.TopicDelete,
+		StartsAt: now.Add(time.Hour),
+		EndsAt:   now.Add(2 * time.Hour),
+	}
+	assert.Equal(t, "topic delete_item", window.Target())
+	assert.False(t, window.IsActive())
+	assert.Equal(t, pgmodels.NSQPauseScheduled, window.Status())
+
+	window.StartsAt = now.Add(-time.Hour)
+	assert.True(t, window.IsActive())
+	assert.Equal(t, pgmodels.NSQPauseActive, window.Status())
+
+	// Ended, but the cron job hasn't unpaused it yet.
+	window.EndsAt = now.Add(-time.Minute)
+	window.PausedAt = now.Add(-time.Hour)
+	assert.False(t, window.IsActive())
+	assert.Equal(t, pgmodels.NSQPauseActive, window.Status())
+
+	window.UnpausedAt = now
+	assert.Equal(t, pgmodels.NSQPauseCompleted, window.Status())
+
+	window.CancelledAt = now
+	assert.Equal(t, pgmodels.NSQPauseCancelled, window.Status())
+
+	window.Channel = "delete_item_worker_chan"
+	assert.Equal(t, "channel delete_item_worker_chan of topic delete_item", window.Target())
+}
+
+func TestApplyNSQPauseWindows(t *testing.T) {
+	db.LoadFixtures()
+	ctx := common.Context()
+	pgmodels.ClearNSQPauseWindowCache()
+
+	// Stand in for nsqd, so we can see what Registry pauses and make
+	// it fail.
+	responseCode := http.StatusOK
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, fmt.Sprintf("%s %s %s", r.URL.Path, r.URL.Query().Get("topic"), r.URL.Query().Get("channel")))
+		w.WriteHeader(responseCode)
+	}))
+	defer server.Close()
+	nsqClient := ctx.NSQClient
+	ctx.NSQClient = network.NewNSQClient(server.URL, ctx.Log)
+	defer func() { ctx.NSQClient = nsqClient }()
+
+	user, err := pgmodels.UserByEmail("system@aptrust.org")
+	require.Nil(t, err)
+	now := time.Now().UTC()
+	newWindow := func(topic, channel string, startsAt time.Time) *pgmodels.NSQPauseWindow {
+		window := &pgmodels.NSQPauseWindow{
+			Topic:       topic,
+			Channel:     channel,
+			Reason:      "Storage provider maintenance",
+			StartsAt:    startsAt,
+			EndsAt:      startsAt.Add(time.Hour),
+			CreatedByID: user.ID,
+		}
+		require.Nil(t, window.Save())
+		return window
+	}
+	reload := func(window *pgmodels.NSQPauseWindow) *pgmodels.NSQPauseWindow {
+		w, err := pgmodels.NSQPauseWindowByID(window.ID)
+		require.Nil(t, err)
+		return w
+	}
+
+	// Only windows that have started get paused.
+	glacier := newWindow(constants.TopicGlacierRestore, "", now.Add(-time.Minute))
+	later := newWindow(constants.TopicDelete, "delete_item_worker_chan", now.Add(time.Hour))
+	require.Nil(t, pgmodels.ApplyNSQPauseWindows())
+	assert.Equal(t, []string{"/topic/pause restore_glacier "}, calls)
+	glacier = reload(glacier)
+	assert.False(t, glacier.PausedAt.IsZero())
+	assert.Empty(t, glacier.Error)
+	assert.True(t, reload(later).PausedAt.IsZero())
+
+	active, err := pgmodels.NSQPauseWindowsActive()
+	require.Nil(t, err)
+	require.Equal(t, 1, len(active))
+	assert.Equal(t, glacier.ID, active[0].ID)
+
+	// Running again does nothing new.
+	calls = nil
+	require.Nil(t, pgmodels.ApplyNSQPauseWindows())
+	assert.Empty(t, calls)
+
+	// A second window keeps the topic paused when the first is cancelled.
+	overlap := newWindow(constants.TopicGlacierRestore, "", now.Add(-time.Minute))
+	require.Nil(t, glacier.Cancel(user))
+	calls = nil
+	require.Nil(t, pgmodels.ApplyNSQPauseWindows())
+	assert.Equal(t, []string{"/topic/pause restore_glacier "}, calls)
+	glacier = reload(glacier)
+	assert.False(t, glacier.UnpausedAt.IsZero())
+	assert.Equal(t, pgmodels.NSQPauseCancelled, glacier.Status())
+	assert.Equal(t, user.ID, glacier.CancelledByID)
+
+	// Cancelling the second window unpauses the topic.
+	overlap = reload(overlap)
+	require.Nil(t, overlap.Cancel(user))
+	calls = nil
+	require.Nil(t, pgmodels.ApplyNSQPauseWindows())
+	assert.Equal(t, []string{"/topic/unpause restore_glacier "}, calls)
+
+	// If NSQ fails, we record the error and try again next time.
+	responseCode = http.StatusInternalServerError
+	failing := newWindow(constants.TopicFileRestore, "restore_file_worker_chan", now.Add(-time.Minute))
+	calls = nil
+	require.Nil(t, pgmodels.ApplyNSQPauseWindows())
+	assert.Equal(t, []string{"/channel/pause restore_file restore_file_worker_chan"}, calls)
+	failing = reload(failing)
+	assert.True(t, failing.PausedAt.IsZero())
+	assert.NotEmpty(t, failing.Error)
+
+	responseCode = http.StatusOK
+	require.Nil(t, pgmodels.ApplyNSQPauseWindows())
+	failing = reload(failing)
+	assert.False(t, failing.PausedAt.IsZero())
+	assert.Empty(t, failing.Error)
+}
@@ -0,0 +1,310 @@
+package pgmodels
+
+import (
+	"sort"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/go-pg/pg/v10"
+)
+
+// ObjectVersionFilters describes the allowed filters for searching
+// object versions.
+var ObjectVersionFilters = []string{
+	"ingested_at__gteq",
+	"ingested_at__lteq",
+	"institution_id",
+	"intellectual_object_id",
+	"version_number",
+	"version_number__gteq",
+	"version_number__lteq",
+	"work_item_id",
+}
+
+// ObjectVersion records what an intellectual object looked like after
+// a successful ingest. Reingest overwrites GenericFiles in place, so
+// versions are the only record of the files, sizes and checksums that
+// made up earlier versions of an object.
+//
+// We record a new version each time an ingest WorkItem for the object
+// completes successfully. VersionNumber counts up from 1 for each object.
+// Versions are never updated.
+type ObjectVersion struct {
+	TimestampModel
+	IntellectualObjectID int64                `json:"intellectual_object_id"`
+	InstitutionID        int64                `json:"institution_id"`
+	WorkItemID           int64                `json:"work_item_id"`
+	VersionNumber        int                  `json:"version_number"`
+	FileCount            int                  `json:"file_count" pg:",use_zero"`
+	Size                 int64                `json:"size" pg:",use_zero"`
+	IngestedAt           time.Time            `json:"ingested_at"`
+	Files                []*ObjectVersionFile `json:"files,omitempty" pg:"rel:has-many"`
+}
+
+// ObjectVersionFile describes a file that was active in an object when
+// an ObjectVersion was recorded. It includes the file's latest digest
+// for each algorithm at that time.
+type ObjectVersionFile struct {
+	ID              int64  `json:"id"`
+	ObjectVersionID int64  `json:"object_version_id"`
+	GenericFileID   int64  `json:"generic_file_id"`
+	Identifier      string `json:"identifier"`
+	Size            int64  `json:"size" pg:",use_zero"`
+	Md5             string `json:"md5,omitempty" pg:"md5"`
+	Sha1            string `json:"sha1,omitempty" pg:"sha1"`
+	Sha256          string `json:"sha256,omitempty" pg:"sha256"`
+	Sha512          string `json:"sha512,omitempty" pg:"sha512"`
+}
+
+// ObjectVersionByID returns the version with the specified id.
+// Returns pg.ErrNoRows if there is no match.
+func ObjectVersionByID(id int64) (*ObjectVersion, error) {
+	query := NewQuery().Where(`"object_version"."id"`, "=", id)
+	return ObjectVersionGet(query)
+}
+
+// ObjectVersionGet returns the first version matching the query.
+func ObjectVersionGet(query *Query) (*ObjectVersion, error) {
+	var version ObjectVersion
+	err := query.Select(&version)
+	return &version, err
+}
+
+// ObjectVersionSelect returns all versions matching the query.
+func ObjectVersionSelect(query *Query) ([]*ObjectVersion, error) {
+	var versions []*ObjectVersion
+	err := query.Select(&versions)
+	return versions, err
+}
+
+// ObjectVersionsForObject returns all versions of the specified object,
+// newest first.
+func ObjectVersionsForObject(objID int64) ([]*ObjectVersion, error) {
+	query := NewQuery().
+		Where("intellectual_object_id", "=", objID).
+		OrderBy("version_number", "desc")
+	return ObjectVersionSelect(query)
+}
+
+// RecordObjectVersion records the current active files of the item's
+// object as a new version. This is a no-op that returns the existing
+// version if we've already recorded a version for this item.
+func RecordObjectVersion(item *WorkItem) (*ObjectVersion, error) {
+	existing, err := ObjectVersionGet(NewQuery().Where("work_item_id", "=", item.ID))
+	if err == nil {
+		return existing, nil
+	}
+	if !IsNoRowError(err) {
+		return nil, err
+	}
+	ingestedAt := item.DateProcessed
+	if ingestedAt.IsZero() {
+		ingestedAt = time.Now().UTC()
+	}
+	version := &ObjectVersion{
+		IntellectualObjectID: item.IntellectualObjectID,
+		InstitutionID:        item.InstitutionID,
+		WorkItemID:           item.ID,
+		IngestedAt:           ingestedAt,
+	}
+	version.SetTimestamps()
+	db := common.Context().DB
+	err = db.RunInTransaction(db.Context(), func(tx *pg.Tx) error {
+		_, err := tx.QueryOne(pg.Scan(&version.VersionNumber),
+			`select coalesce(max(version_number), 0) + 1 from object_versions where intellectual_object_id = ?`,
+			version.IntellectualObjectID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Model(version).Insert()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(recordObjectVersionFilesQuery, version.ID, version.IntellectualObjectID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.QueryOne(pg.Scan(&version.FileCount, &version.Size),
+			`update object_versions ov set file_count = stats.file_count, "size" = stats."size"
+			from (select count(*) as file_count, coalesce(sum("size"), 0) as "size"
+			from object_version_files where object_version_id = ?) stats
+			where ov.id = ? returning ov.file_count, ov."size"`,
+			version.ID, version.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// recordObjectVersionFilesQuery copies an object's active files, with
+// their latest digests, into object_version_files.
+const recordObjectVersionFilesQuery = `insert into object_version_files
+	(object_version_id, generic_file_id, identifier, "size", md5, sha1, sha256, sha512)
+	select ?0, gf.id, gf.identifier, gf."size",
+	(select cs.digest from checksums cs where cs.generic_file_id = gf.id and cs."algorithm" = 'md5' order by cs.datetime desc limit 1),
+	(select cs.digest from checksums cs where cs.generic_file_id = gf.id and cs."algorithm" = 'sha1' order by cs.datetime desc limit 1),
+	(select cs.digest from checksums cs where cs.generic_file_id = gf.id and cs."algorithm" = 'sha256' order by cs.datetime desc limit 1),
+	(select cs.digest from checksums cs where cs.generic_file_id = gf.id and cs."algorithm" = 'sha512' order by cs.datetime desc limit 1)
+	from generic_files gf
+	where gf.intellectual_object_id = ?1 and gf.state = 'A'`
+
+// Save is not supported. Versions are immutable. Use
+// RecordObjectVersion to create them.
+func (v *ObjectVersion) Save() error {
+	return common.ErrNotSupported
+}
+
+// LoadFiles loads the version's files, sorted by identifier.
+func (v *ObjectVersion) LoadFiles() error {
+	var files []*ObjectVersionFile
+	err := common.Context().DB.Model(&files).
+		Where("object_version_id = ?", v.ID).
+		Order("identifier asc").
+		Select()
+	if err != nil {
+		return err
+	}
+	v.Files = files
+	return nil
+}
+
+// Previous returns the version of the same object that came before
+// this one. Returns pg.ErrNoRows if this is the first version.
+func (v *ObjectVersion) Previous() (*ObjectVersion, error) {
+	query := NewQuery().
+		Where("intellectual_object_id", "=", v.IntellectualObjectID).
+		Where("version_number", "<", v.VersionNumber).
+		OrderBy("version_number", "desc").
+		Limit(1)
+	return ObjectVersionGet(query)
+}
+
+// SameContent returns true if this file has the same size and digests
+// as other. We compare only digests that both files have, since older
+// ingests didn't always calculate every algorithm.
+func (f *ObjectVersionFile) SameContent(other *ObjectVersionFile) bool {
+	if f.Size != other.Size {
+		return false
+	}
+	digests := [][2]string{
+		{f.Md5, other.Md5},
+		{f.Sha1, other.Sha1},
+		{f.Sha256, other.Sha256},
+		{f.Sha512, other.Sha512},
+	}
+	for _, pair := range digests {
+		if pair[0] != "" && pair[1] != "" && pair[0] != pair[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// ObjectVersionDiff describes the files that were added, removed and
+// changed between two versions of an object. Files are matched by
+// identifier.
+type ObjectVersionDiff struct {
+	FromVersion    *ObjectVersion             `json:"from_version,omitempty"`
+	ToVersion      *ObjectVersion             `json:"to_version"`
+	Added          []*ObjectVersionFile       `json:"added"`
+	Removed        []*ObjectVersionFile       `json:"removed"`
+	Changed        []*ObjectVersionFileChange `json:"changed"`
+	UnchangedCount int                        `json:"unchanged_count"`
+}
+
+// ObjectVersionFileChange describes a file whose size or digests
+// changed between two versions.
+type ObjectVersionFileChange struct {
+	Identifier string             `json:"identifier"`
+	From       *ObjectVersionFile `json:"from"`
+	To         *ObjectVersionFile `json:"to"`
+}
+
+// ObjectVersionDiffFor loads the versions with the specified ids and
+// returns the diff between them. If fromID is zero, this diffs against
+// the version before toVersionID. For an object's first version, that
+// means all files are added. Returns common.ErrIDMismatch if the versions
+// belong to different objects.
+func ObjectVersionDiffFor(fromID, toID int64) (*ObjectVersionDiff, error) {
+	to, err := ObjectVersionByID(toID)
+	if err != nil {
+		return nil, err
+	}
+	var from *ObjectVersion
+	if fromID == 0 {
+		from, err = to.Previous()
+		if IsNoRowError(err) {
+			from, err = nil, nil
+		}
+	} else {
+		from, err = ObjectVersionByID(fromID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if from != nil {
+		if from.IntellectualObjectID != to.IntellectualObjectID {
+			return nil, common.ErrIDMismatch
+		}
+		if err = from.LoadFiles(); err != nil {
+			return nil, err
+		}
+	}
+	if err = to.LoadFiles(); err != nil {
+		return nil, err
+	}
+	return DiffObjectVersions(from, to), nil
+}
+
+// DiffObjectVersions compares the files of two versions. Both versions
+// should have their files loaded. From may be nil, in which case all of
+// to's files are added. The versions in the result don't include files,
+// since the diff itself describes them.
+func DiffObjectVersions(from, to *ObjectVersion) *ObjectVersionDiff {
+	diff := &ObjectVersionDiff{
+		ToVersion: versionWithoutFiles(to),
+		Added:     make([]*ObjectVersionFile, 0),
+		Removed:   make([]*ObjectVersionFile, 0),
+		Changed:   make([]*ObjectVersionFileChange, 0),
+	}
+	fromFiles := make(map[string]*ObjectVersionFile)
+	if from != nil {
+		diff.FromVersion = versionWithoutFiles(from)
+		for _, f := range from.Files {
+			fromFiles[f.Identifier] = f
+		}
+	}
+	toFiles := make(map[string]*ObjectVersionFile, len(to.Files))
+	for _, f := range to.Files {
+		toFiles[f.Identifier] = f
+		oldFile, ok := fromFiles[f.Identifier]
+		if !ok {
+			diff.Added = append(diff.Added, f)
+		} else if !oldFile.SameContent(f) {
+			diff.Changed = append(diff.Changed, &ObjectVersionFileChange{
+				Identifier: f.Identifier,
+				From:       oldFile,
+				To:         f,
+			})
+		} else {
+			diff.UnchangedCount++
+		}
+	}
+	for identifier, f := range fromFiles {
+		if _, ok := toFiles[identifier]; !ok {
+			diff.Removed = append(diff.Removed, f)
+		}
+	}
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Identifier < diff.Added[j].Identifier })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Identifier < diff.Removed[j].Identifier })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Identifier < diff.Changed[j].Identifier })
+	return diff
+}
+
+func versionWithoutFiles(v *ObjectVersion) *ObjectVersion {
+	versionCopy := *v
+	versionCopy.Files = nil
+	return &versionCopy
+}
@@ -0,0 +1,155 @@
+package pgmodels_test
+
+import (
+	"testing"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectVersionsForObject(t *testing.T) {
+	db.LoadFixtures()
+	versions, err := pgmodels.ObjectVersionsForObject(1)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(versions))
+	assert.Equal(t, int64(25), versions[0].WorkItemID)
+	assert.Equal(t, 1, versions[0].VersionNumber)
+	assert.Equal(t, 3, versions[0].FileCount)
+	assert.Empty(t, versions[0].Files)
+
+	version, err := pgmodels.ObjectVersionByID(1)
+	require.Nil(t, err)
+	require.Nil(t, version.LoadFiles())
+	require.Equal(t, 3, len(version.Files))
+	assert.Equal(t, "institution1.edu/photos/picture1", version.Files[0].Identifier)
+	assert.Equal(t, "12345678", version.Files[0].Md5)
+	assert.Equal(t, "9876543210", version.Files[0].Sha256)
+
+	_, err = version.Previous()
+	assert.True(t, pgmodels.IsNoRowError(err))
+	assert.Equal(t, common.ErrNotSupported, version.Save())
+}
+
+func TestRecordObjectVersion(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	// Reingest changes one file, deletes one and adds one.
+	gf, err := pgmodels.GenericFileByID(2)
+	require.Nil(t, err)
+	gf.Size = 5000
+	require.Nil(t, gf.Save())
+
+	gf, err = pgmodels.GenericFileByID(3)
+	require.Nil(t, err)
+	gf.State = constants.StateDeleted
+	require.Nil(t, gf.Save())
+
+	newFile := pgmodels.RandomGenericFile(1, "institution1.edu/photos")
+	newFile.InstitutionID = 2
+	newFile.Size = 8000
+	require.Nil(t, newFile.Save())
+
+	// Completing a successful ingest records a new version.
+	item := pgmodels.RandomWorkItem("photos.tar", constants.ActionIngest, 1, 0)
+	item.InstitutionID = 2
+	item.Stage = constants.StageCleanup
+	item.Status = constants.StatusSuccess
+	require.Nil(t, item.Save())
+
+	versions, err := pgmodels.ObjectVersionsForObject(1)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(versions))
+	latest := versions[0]
+	assert.Equal(t, 2, latest.VersionNumber)
+	assert.Equal(t, item.ID, latest.WorkItemID)
+	assert.Equal(t, 3, latest.FileCount)
+	assert.Equal(t, int64(243855000+5000+8000), latest.Size)
+
+	// Recording is idempotent.
+	again, err := pgmodels.RecordObjectVersion(item)
+	require.Nil(t, err)
+	assert.Equal(t, latest.ID, again.ID)
+
+	diff, err := pgmodels.ObjectVersionDiffFor(0, latest.ID)
+	require.Nil(t, err)
+	require.NotNil(t, diff.FromVersion)
+	assert.Equal(t, 1, diff.FromVersion.VersionNumber)
+	require.Equal(t, 1, len(diff.Added))
+	assert.Equal(t, newFile.Identifier, diff.Added[0].Identifier)
+	require.Equal(t, 1, len(diff.Removed))
+	assert.Equal(t, "institution1.edu/photos/picture3", diff.Removed[0].Identifier)
+	require.Equal(t, 1, len(diff.Changed))
+	assert.Equal(t, "institution1.edu/photos/picture2", diff.Changed[0].Identifier)
+	assert.Equal(t, int64(1169355000), diff.Changed[0].From.Size)
+	assert.Equal(t, int64(5000), diff.Changed[0].To.Size)
+	assert.Equal(t, 1, diff.UnchangedCount)
+}
+
+func TestObjectVersionDiffFor(t *testing.T) {
+	db.LoadFixtures()
+
+	// First version has no previous, so all files are added.
+	diff, err := pgmodels.ObjectVersionDiffFor(0, 1)
+	require.Nil(t, err)
+	assert.Nil(t, diff.FromVersion)
+	assert.Equal(t, 3, len(diff.Added))
+	assert.Empty(t, diff.ToVersion.Files)
+
+	// Versions must belong to the same object.
+	_, err = pgmodels.ObjectVersionDiffFor(2, 1)
+	assert.Equal(t, common.ErrIDMismatch, err)
+}
+
+func TestDiffObjectVersions(t *testing.T) {
+	from := &pgmodels.ObjectVersion{
+		VersionNumber: 1,
+		Files: []*pgmodels.ObjectVersionFile{
+			{Identifier: "obj/data/a.txt", Size: 10, Md5: "aaa"},
+			{Identifier: "obj/data/b.txt", Size: 20, Sha256: "bbb"},
+			{Identifier: "obj/data/c.txt", Size: 30, Md5: "ccc"},
+			{Identifier: "obj/data/d.txt", Size: 40, Md5: "ddd"},
+		},
+	}
+	to := &pgmodels.ObjectVersion{
+		VersionNumber: 2,
+		Files: []*pgmodels.ObjectVersionFile{
+			// Same size and md5, plus a new sha256: unchanged
+			{Identifier: "obj/data/a.txt", Size: 10, Md5: "aaa", Sha256: "a256"},
+			// Same size, different sha256: changed
+			{Identifier: "obj/data/b.txt", Size: 20, Sha256: "xxx"},
+			// Different size: changed
+			{Identifier: "obj/data/c.txt", Size: 31, Md5: "ccc"},
+			{Identifier: "obj/data/e.txt", Size: 50, Md5: "eee"},
+		},
+	}
+	diff := pgmodels.DiffObjectVersions(from, to)
+	assert.Equal(t, 1, diff.FromVersion.VersionNumber)
+	assert.Equal(t, 2, diff.ToVersion.VersionNumber)
+	assert.Empty(t, diff.FromVersion.Files)
+	assert.Empty(t, diff.ToVersion.Files)
+	assert.Equal(t, 1, diff.UnchangedCount)
+
+	require.Equal(t, 1, len(diff.Added))
+	assert.Equal(t, "obj/data/e.txt", diff.Added[0].Identifier)
+	require.Equal(t, 1, len(diff.Removed))
+	assert.Equal(t, "obj/data/d.txt", diff.Removed[0].Identifier)
+	require.Equal(t, 2, len(diff.Changed))
+	assert.Equal(t, "obj/data/b.txt", diff.Changed[0].Identifier)
+	assert.Equal(t, "bbb", diff.Changed[0].From.Sha256)
+	assert.Equal(t, "xxx", diff.Changed[0].To.Sha256)
+	assert.Equal(t, "obj/data/c.txt", diff.Changed[1].Identifier)
+
+	// Original versions keep their files.
+	assert.Equal(t, 4, len(from.Files))
+
+	// With no from version, everything is added.
+	diff = pgmodels.DiffObjectVersions(nil, to)
+	assert.Nil(t, diff.FromVersion)
+	assert.Equal(t, 4, len(diff.Added))
+	assert.Empty(t, diff.Removed)
+}
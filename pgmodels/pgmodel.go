@@ -44,6 +44,7 @@ type xactType int
 const (
 	TypeInsert xactType = iota
 	TypeUpdate
+	TypeDelete
 )
 
 func insert(model interface{}) error {
@@ -54,15 +55,24 @@ func update(model interface{}) error {
 	return transact(model, TypeUpdate)
 }
 
+// hardDelete deletes model's row. Most of our models are soft deleted
+// by updating their state instead.
+func hardDelete(model interface{}) error {
+	return transact(model, TypeDelete)
+}
+
 func transact(model interface{}, action xactType) error {
 	registryContext := common.Context()
 	db := registryContext.DB
 	return db.RunInTransaction(db.Context(), func(tx *pg.Tx) error {
 		var err error
-		if action == TypeInsert {
-			_, err = tx.Model(model).Insert()
-		} else {
-			_, err = tx.Model(model).WherePK().Update()
+		switch action {
+		case TypeInsert:
+			err = auditedInsert(tx, model)
+		case TypeDelete:
+			err = auditedDelete(tx, model)
+		default:
+			err = auditedUpdate(tx, model)
 		}
 		if err != nil {
 			registryContext.Log.Error().Msgf("Transaction failed. Model: %v. Error: %v", model, err)
@@ -83,10 +93,24 @@ func InstIDFor(resourceType string, resourceID int64) (id int64, err error) {
 	ctx := common.Context()
 	db := ctx.DB
 	switch resourceType {
+	case "APIKey":
+		key := &APIKey{}
+		err = db.Model(key).Column("_").Relation("User.institution_id").Where(`"api_key"."id" = ?`, resourceID).Select()
+		if key != nil && key.User != nil {
+			id = key.User.InstitutionID
+		}
 	case "Alert":
 		alert := &Alert{}
 		err = db.Model(alert).Column("institution_id").Where("id = ?", resourceID).Select()
 		id = alert.InstitutionID
+	case "AuditLog":
+		entry := &AuditLog{}
+		err = db.Model(entry).Column("institution_id").Where("id = ?", resourceID).Select()
+		id = entry.InstitutionID
+	case "BagGroup":
+		bagGroup := &BagGroup{}
+		err = db.Model(bagGroup).Column("institution_id").Where("id = ?", resourceID).Select()
+		id = bagGroup.InstitutionID
 	case "Checksum":
 		cs := &Checksum{}
 		err = db.Model(cs).Column("_").Relation("GenericFile.institution_id").Where(`"checksum"."id" = ?`, resourceID).Select()
@@ -107,10 +131,27 @@ func InstIDFor(resourceType string, resourceID int64) (id int64, err error) {
 		obj := &IntellectualObject{}
 		err = db.Model(obj).Column("institution_id").Where("id = ?", resourceID).Select()
 		id = obj.InstitutionID
+	case "LegalHold":
+		hold := &LegalHold{}
+		err = db.Model(hold).Column("institution_id").Where("id = ?", resourceID).Select()
+		id = hold.InstitutionID
+	case "NSQPauseWindow":
+		// Pause windows belong to no institution. Only sys admins can
+		// manage them, so we just check that the window exists.
+		window := &NSQPauseWindow{}
+		err = db.Model(window).Column("id").Where("id = ?", resourceID).Select()
+	case "ObjectVersion":
+		version := &ObjectVersion{}
+		err = db.Model(version).Column("institution_id").Where("id = ?", resourceID).Select()
+		id = version.InstitutionID
 	case "PremisEvent":
 		pe := &PremisEvent{}
 		err = db.Model(pe).Column("institution_id").Where("id = ?", resourceID).Select()
 		id = pe.InstitutionID
+	case "RateLimitOverride":
+		override := &RateLimitOverride{}
+		err = db.Model(override).Column("institution_id").Where("id = ?", resourceID).Select()
+		id = override.InstitutionID
 	case "StorageRecord":
 		sr := &StorageRecord{}
 		err = db.Model(sr).Column("_").Relation("GenericFile.institution_id").Where(`"storage_record"."id" = ?`, resourceID).Select()
@@ -121,6 +162,14 @@ func InstIDFor(resourceType string, resourceID int64) (id int64, err error) {
 		user := &User{}
 		err = db.Model(user).Column("institution_id").Where("id = ?", resourceID).Select()
 		id = user.InstitutionID
+	case "Webhook":
+		hook := &Webhook{}
+		err = db.Model(hook).Column("institution_id").Where("id = ?", resourceID).Select()
+		id = hook.InstitutionID
+	case "WebhookDelivery":
+		delivery := &WebhookDelivery{}
+		err = db.Model(delivery).Column("institution_id").Where("id = ?", resourceID).Select()
+		id = delivery.InstitutionID
 	case "WorkItem":
 		item := &WorkItem{}
 		err = db.Model(item).Column("institution_id").Where("id = ?", resourceID).Select()
@@ -146,12 +195,17 @@ func FiltersFor(typeName string) []string {
 func initFilters() {
 	filters = make(map[string][]string)
 	filters["Alert"] = AlertFilters
+	filters["AuditLog"] = AuditLogFilters
+	filters["BagGroup"] = BagGroupFilters
 	filters["Checksum"] = ChecksumFilters
 	filters["DeletionRequest"] = DeletionRequestFilters
 	filters["DepositStats"] = DepositStatsFilters
+	filters["PipelineStats"] = PipelineStatsFilters
 	filters["GenericFile"] = GenericFileFilters
 	filters["IntellectualObject"] = IntellectualObjectFilters
 	filters["Institution"] = InstitutionFilters
+	filters["LegalHold"] = LegalHoldFilters
+	filters["ObjectVersion"] = ObjectVersionFilters
 	filters["PremisEvent"] = PremisEventFilters
 	filters["StorageRecord"] = StorageRecordFilters
 	filters["User"] = UserFilters
@@ -14,7 +14,11 @@ func TestInstIDFor(t *testing.T) {
 	db.LoadFixtures()
 
 	// This is all known fixture data...
-	id, err := pgmodels.InstIDFor("Checksum", 8)
+	id, err := pgmodels.InstIDFor("AuditLog", 3)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3, id)
+
+	id, err = pgmodels.InstIDFor("Checksum", 8)
 	assert.Nil(t, err)
 	assert.EqualValues(t, 4, id)
 
@@ -22,6 +26,10 @@ func TestInstIDFor(t *testing.T) {
 	assert.Nil(t, err)
 	assert.EqualValues(t, 2, id)
 
+	id, err = pgmodels.InstIDFor("LegalHold", 4)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3, id)
+
 	id, err = pgmodels.InstIDFor("Institution", 3)
 	assert.Nil(t, err)
 	assert.EqualValues(t, 3, id)
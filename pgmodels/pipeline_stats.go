@@ -0,0 +1,190 @@
+package pgmodels
+
+import (
+	"time"
+
+	"github.com/APTrust/registry/common"
+)
+
+// PipelineStatsFilters are the filters the pipeline report accepts.
+// Dates are in YYYY-MM-DD format.
+var PipelineStatsFilters = []string{
+	"end_date",
+	"institution_id",
+	"start_date",
+}
+
+// PipelineStats describes WorkItem throughput and latency for items
+// processed between StartDate (inclusive) and EndDate (exclusive).
+// If InstitutionID is zero, stats cover all institutions.
+type PipelineStats struct {
+	InstitutionID int64                  `json:"institution_id"`
+	StartDate     time.Time              `json:"start_date"`
+	EndDate       time.Time              `json:"end_date"`
+	Actions       []*PipelineActionStats `json:"actions"`
+	Stages        []*PipelineStageStats  `json:"stages"`
+	Nodes         []*PipelineNodeStats   `json:"nodes"`
+}
+
+// PipelineActionStats describes throughput for one WorkItem action.
+// Processing times run from the item's creation to its last update
+// and include only successful items. Times are in seconds.
+// FailureRate is the fraction of completed items that failed.
+type PipelineActionStats struct {
+	Action       string  `json:"action"`
+	ItemCount    int64   `json:"item_count"`
+	Completed    int64   `json:"completed"`
+	Succeeded    int64   `json:"succeeded"`
+	Failed       int64   `json:"failed"`
+	FailureRate  float64 `json:"failure_rate"`
+	TotalBytes   int64   `json:"total_bytes"`
+	BytesPerHour int64   `json:"bytes_per_hour"`
+	ItemsPerDay  float64 `json:"items_per_day"`
+	P50Seconds   float64 `json:"p50_seconds"`
+	P90Seconds   float64 `json:"p90_seconds"`
+	P99Seconds   float64 `json:"p99_seconds"`
+}
+
+// PipelineStageStats describes latency for items that passed through
+// Stage during the report period, based on their transitions. Queue
+// wait is the time an item spent Pending in the stage, and processing
+// time is the time it spent Started. Both include every visit and
+// retry, so an item that was requeued to the stage counts once, with
+// its times summed. Times are in seconds. Failed counts items that
+// failed in this stage. FailureRate is the fraction of the action's
+// completed items that failed in this stage, so the stage with the
+// highest rate is where most failures happen.
+type PipelineStageStats struct {
+	Action               string  `json:"action"`
+	Stage                string  `json:"stage"`
+	ItemCount            int64   `json:"item_count"`
+	Failed               int64   `json:"failed"`
+	FailureRate          float64 `json:"failure_rate"`
+	AvgQueueSeconds      float64 `json:"avg_queue_seconds"`
+	P50QueueSeconds      float64 `json:"p50_queue_seconds"`
+	P90QueueSeconds      float64 `json:"p90_queue_seconds"`
+	P50ProcessingSeconds float64 `json:"p50_processing_seconds"`
+	P90ProcessingSeconds float64 `json:"p90_processing_seconds"`
+	P99ProcessingSeconds float64 `json:"p99_processing_seconds"`
+}
+
+// PipelineNodeStats describes the work done by one worker node.
+// FailureRate is the fraction of the node's completed items that
+// failed.
+type PipelineNodeStats struct {
+	Node        string  `json:"node"`
+	ItemCount   int64   `json:"item_count"`
+	Completed   int64   `json:"completed"`
+	Failed      int64   `json:"failed"`
+	FailureRate float64 `json:"failure_rate"`
+	TotalBytes  int64   `json:"total_bytes"`
+}
+
+var pipelineActionQuery = `select action,
+	count(*) as item_count,
+	count(*) filter (where status in ('Cancelled', 'Failed', 'Success')) as completed,
+	count(*) filter (where status = 'Success') as succeeded,
+	count(*) filter (where status = 'Failed') as failed,
+	coalesce(sum(size) filter (where status = 'Success'), 0) as total_bytes,
+	coalesce(percentile_cont(0.5) within group (order by extract(epoch from (updated_at - created_at))) filter (where status = 'Success'), 0) as p50_seconds,
+	coalesce(percentile_cont(0.9) within group (order by extract(epoch from (updated_at - created_at))) filter (where status = 'Success'), 0) as p90_seconds,
+	coalesce(percentile_cont(0.99) within group (order by extract(epoch from (updated_at - created_at))) filter (where status = 'Success'), 0) as p99_seconds
+	from work_items
+	where date_processed >= ? and date_processed < ?
+	and (? = 0 or institution_id = ?)
+	group by action
+	order by action`
+
+// pipelineStageQuery sums the Pending and Started times from
+// transitionTimesQuery per item and stage, then aggregates per action
+// and stage. Unlike stageTimingQuery, it keeps each item's final
+// transition, so items that failed in a stage count as failures there.
+var pipelineStageQuery = `select action, stage,
+	count(*) as item_count,
+	count(*) filter (where failed) as failed,
+	coalesce(avg(queue_seconds), 0) as avg_queue_seconds,
+	coalesce(percentile_cont(0.5) within group (order by queue_seconds), 0) as p50_queue_seconds,
+	coalesce(percentile_cont(0.9) within group (order by queue_seconds), 0) as p90_queue_seconds,
+	coalesce(percentile_cont(0.5) within group (order by processing_seconds), 0) as p50_processing_seconds,
+	coalesce(percentile_cont(0.9) within group (order by processing_seconds), 0) as p90_processing_seconds,
+	coalesce(percentile_cont(0.99) within group (order by processing_seconds), 0) as p99_processing_seconds
+	from (
+		select work_item_id, action, stage,
+		sum(seconds) filter (where status = 'Pending') as queue_seconds,
+		sum(seconds) filter (where status = 'Started') as processing_seconds,
+		bool_or(status = 'Failed') as failed
+		from (` + transitionTimesQuery + `) transition_times
+		group by work_item_id, action, stage
+	) item_stage_times
+	group by action, stage
+	order by action, stage`
+
+var pipelineNodeQuery = `select node,
+	count(*) as item_count,
+	count(*) filter (where status in ('Cancelled', 'Failed', 'Success')) as completed,
+	count(*) filter (where status = 'Failed') as failed,
+	coalesce(sum(size) filter (where status = 'Success'), 0) as total_bytes
+	from work_items
+	where date_processed >= ? and date_processed < ?
+	and (? = 0 or institution_id = ?)
+	and coalesce(node, '') != ''
+	group by node
+	order by node`
+
+// PipelineStatsSelect returns throughput and latency stats for WorkItems
+// processed on or after startDate and before endDate. Stage stats come
+// from the transitions recorded in that range. Set institutionID to
+// zero to include all institutions.
+func PipelineStatsSelect(institutionID int64, startDate, endDate time.Time) (*PipelineStats, error) {
+	stats := &PipelineStats{
+		InstitutionID: institutionID,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		Actions:       make([]*PipelineActionStats, 0),
+		Stages:        make([]*PipelineStageStats, 0),
+		Nodes:         make([]*PipelineNodeStats, 0),
+	}
+	db := common.Context().DB
+	_, err := db.Query(&stats.Actions, pipelineActionQuery, startDate, endDate, institutionID, institutionID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Query(&stats.Stages, pipelineStageQuery, startDate, endDate, institutionID, institutionID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Query(&stats.Nodes, pipelineNodeQuery, startDate, endDate, institutionID, institutionID)
+	if err != nil {
+		return nil, err
+	}
+	stats.setRates()
+	return stats, nil
+}
+
+// setRates calculates the rates that depend on the length of the
+// report period or on totals from other sections of the report.
+func (stats *PipelineStats) setRates() {
+	hours := stats.EndDate.Sub(stats.StartDate).Hours()
+	completedByAction := make(map[string]int64)
+	for _, a := range stats.Actions {
+		a.FailureRate = fraction(a.Failed, a.Completed)
+		if hours > 0 {
+			a.BytesPerHour = int64(float64(a.TotalBytes) / hours)
+			a.ItemsPerDay = float64(a.Succeeded) / (hours / 24)
+		}
+		completedByAction[a.Action] = a.Completed
+	}
+	for _, s := range stats.Stages {
+		s.FailureRate = fraction(s.Failed, completedByAction[s.Action])
+	}
+	for _, n := range stats.Nodes {
+		n.FailureRate = fraction(n.Failed, n.Completed)
+	}
+}
+
+func fraction(numerator, denominator int64) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
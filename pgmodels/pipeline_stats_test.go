@@ -0,0 +1,234 @@
+package pgmodels_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pipelineDay = time.Date(2019, 3, 5, 0, 0, 0, 0, time.UTC)
+
+// createPipelineItem inserts a WorkItem directly, so Save doesn't
+// overwrite the timestamps the pipeline stats depend on.
+func createPipelineItem(t *testing.T, instID int64, action, stage, status, node string, size int64, queueWait, inStage time.Duration) *pgmodels.WorkItem {
+	item := pgmodels.RandomWorkItem("pipeline.tar", action, 0, 0)
+	item.InstitutionID = instID
+	item.Stage = stage
+	item.Status = status
+	item.Node = node
+	item.Size = size
+	item.CreatedAt = pipelineDay
+	item.QueuedAt = pipelineDay
+	item.StageStartedAt = pipelineDay.Add(queueWait)
+	item.UpdatedAt = item.StageStartedAt.Add(inStage)
+	item.DateProcessed = item.UpdatedAt
+	_, err := common.Context().DB.Model(item).Insert()
+	require.Nil(t, err)
+	return item
+}
+
+// pipelineStep is a transition that happened offset after pipelineDay.
+type pipelineStep struct {
+	offset time.Duration
+	stage  string
+	status string
+}
+
+// createPipelineTransitions inserts item's transition history.
+func createPipelineTransitions(t *testing.T, item *pgmodels.WorkItem, steps []pipelineStep) {
+	for _, step := range steps {
+		transition := &pgmodels.WorkItemTransition{
+			WorkItemID:    item.ID,
+			InstitutionID: item.InstitutionID,
+			Action:        item.Action,
+			Stage:         step.stage,
+			Status:        step.status,
+			CreatedAt:     pipelineDay.Add(step.offset),
+		}
+		_, err := common.Context().DB.Model(transition).Insert()
+		require.Nil(t, err)
+	}
+}
+
+func TestPipelineStatsSelect(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	ingested := createPipelineItem(t, 2, constants.ActionIngest, constants.StageCleanup, constants.StatusSuccess, "pipeline-a", 2400, 10*time.Minute, 50*time.Minute)
+	createPipelineItem(t, 2, constants.ActionIngest, constants.StageCleanup, constants.StatusSuccess, "pipeline-a", 4800, 20*time.Minute, 160*time.Minute)
+	invalid := createPipelineItem(t, 2, constants.ActionIngest, constants.StageValidate, constants.StatusFailed, "pipeline-b", 1000, 5*time.Minute, 25*time.Minute)
+	createPipelineItem(t, 3, constants.ActionIngest, constants.StageStore, constants.StatusStarted, "pipeline-b", 500, time.Hour, time.Hour)
+	createPipelineItem(t, 3, constants.ActionDelete, constants.StageResolve, constants.StatusSuccess, "pipeline-a", 0, time.Minute, time.Minute)
+
+	// This item passes through every ingest stage. It waits an hour
+	// for Store, where a worker retries it once.
+	createPipelineTransitions(t, ingested, []pipelineStep{
+		{0, constants.StageReceive, constants.StatusPending},
+		{5 * time.Minute, constants.StageReceive, constants.StatusStarted},
+		{15 * time.Minute, constants.StageValidate, constants.StatusPending},
+		{20 * time.Minute, constants.StageValidate, constants.StatusStarted},
+		{45 * time.Minute, constants.StageStore, constants.StatusPending},
+		{105 * time.Minute, constants.StageStore, constants.StatusStarted},
+		{135 * time.Minute, constants.StageStore, constants.StatusPending},
+		{140 * time.Minute, constants.StageStore, constants.StatusStarted},
+		{180 * time.Minute, constants.StageCleanup, constants.StatusPending},
+		{190 * time.Minute, constants.StageCleanup, constants.StatusStarted},
+		{210 * time.Minute, constants.StageCleanup, constants.StatusSuccess},
+	})
+
+	// This one fails validation.
+	createPipelineTransitions(t, invalid, []pipelineStep{
+		{0, constants.StageReceive, constants.StatusPending},
+		{time.Minute, constants.StageReceive, constants.StatusStarted},
+		{11 * time.Minute, constants.StageValidate, constants.StatusPending},
+		{12 * time.Minute, constants.StageValidate, constants.StatusStarted},
+		{22 * time.Minute, constants.StageValidate, constants.StatusFailed},
+	})
+
+	// Ten days, from March 1 through March 10.
+	startDate := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2019, 3, 11, 0, 0, 0, 0, time.UTC)
+	stats, err := pgmodels.PipelineStatsSelect(0, startDate, endDate)
+	require.Nil(t, err)
+	require.NotNil(t, stats)
+	assert.Equal(t, startDate, stats.StartDate)
+	assert.Equal(t, endDate, stats.EndDate)
+
+	require.Equal(t, 2, len(stats.Actions))
+	deletion := stats.Actions[0]
+	assert.Equal(t, constants.ActionDelete, deletion.Action)
+	assert.EqualValues(t, 1, deletion.Succeeded)
+
+	ingest := stats.Actions[1]
+	assert.Equal(t, constants.ActionIngest, ingest.Action)
+	assert.EqualValues(t, 4, ingest.ItemCount)
+	assert.EqualValues(t, 3, ingest.Completed)
+	assert.EqualValues(t, 2, ingest.Succeeded)
+	assert.EqualValues(t, 1, ingest.Failed)
+	assert.InDelta(t, 1.0/3.0, ingest.FailureRate, 0.0001)
+	assert.EqualValues(t, 7200, ingest.TotalBytes)
+	assert.EqualValues(t, 30, ingest.BytesPerHour)
+	assert.InDelta(t, 0.2, ingest.ItemsPerDay, 0.0001)
+	assert.InDelta(t, 7200, ingest.P50Seconds, 0.01)
+	assert.InDelta(t, 10080, ingest.P90Seconds, 0.01)
+
+	stages := pipelineStagesByName(stats)
+	require.Equal(t, 4, len(stages))
+	receive := stages["Ingest/Receive"]
+	require.NotNil(t, receive)
+	assert.EqualValues(t, 2, receive.ItemCount)
+	assert.EqualValues(t, 0, receive.Failed)
+	assert.InDelta(t, 180, receive.AvgQueueSeconds, 0.01)
+	assert.InDelta(t, 180, receive.P50QueueSeconds, 0.01)
+	assert.InDelta(t, 600, receive.P50ProcessingSeconds, 0.01)
+	validate := stages["Ingest/Validate"]
+	require.NotNil(t, validate)
+	assert.EqualValues(t, 2, validate.ItemCount)
+	assert.EqualValues(t, 1, validate.Failed)
+	assert.InDelta(t, 1.0/3.0, validate.FailureRate, 0.0001)
+	assert.InDelta(t, 180, validate.AvgQueueSeconds, 0.01)
+	assert.InDelta(t, 1050, validate.P50ProcessingSeconds, 0.01)
+	store := stages["Ingest/Store"]
+	require.NotNil(t, store)
+	assert.EqualValues(t, 1, store.ItemCount)
+	assert.EqualValues(t, 0, store.Failed)
+	assert.InDelta(t, 3900, store.AvgQueueSeconds, 0.01)
+	assert.InDelta(t, 4200, store.P50ProcessingSeconds, 0.01)
+	cleanup := stages["Ingest/Cleanup"]
+	require.NotNil(t, cleanup)
+	assert.EqualValues(t, 1, cleanup.ItemCount)
+	assert.InDelta(t, 600, cleanup.AvgQueueSeconds, 0.01)
+	assert.InDelta(t, 1200, cleanup.P50ProcessingSeconds, 0.01)
+
+	require.Equal(t, 2, len(stats.Nodes))
+	assert.Equal(t, "pipeline-a", stats.Nodes[0].Node)
+	assert.EqualValues(t, 3, stats.Nodes[0].ItemCount)
+	assert.EqualValues(t, 0, stats.Nodes[0].FailureRate)
+	assert.EqualValues(t, 7200, stats.Nodes[0].TotalBytes)
+	assert.Equal(t, "pipeline-b", stats.Nodes[1].Node)
+	assert.EqualValues(t, 2, stats.Nodes[1].ItemCount)
+	assert.EqualValues(t, 1, stats.Nodes[1].Completed)
+	assert.EqualValues(t, 1, stats.Nodes[1].FailureRate)
+
+	// Filter on institution.
+	stats, err = pgmodels.PipelineStatsSelect(2, startDate, endDate)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(stats.Actions))
+	assert.EqualValues(t, 3, stats.Actions[0].ItemCount)
+	assert.EqualValues(t, 7200, stats.Actions[0].TotalBytes)
+	assert.Equal(t, 4, len(stats.Stages))
+
+	// Institution 3's items have no transitions.
+	stats, err = pgmodels.PipelineStatsSelect(3, startDate, endDate)
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(stats.Actions))
+	assert.Empty(t, stats.Stages)
+
+	// Nothing processed in this range.
+	stats, err = pgmodels.PipelineStatsSelect(0, startDate.AddDate(-1, 0, 0), startDate.AddDate(0, -10, 0))
+	require.Nil(t, err)
+	assert.Empty(t, stats.Actions)
+	assert.Empty(t, stats.Stages)
+	assert.Empty(t, stats.Nodes)
+}
+
+func TestPipelineStatsWindowEdges(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	item := createPipelineItem(t, 2, constants.ActionIngest, constants.StageStore, constants.StatusSuccess, "pipeline-a", 1000, time.Minute, time.Hour)
+
+	// The window runs from one hour to two hours after pipelineDay.
+	// The item starts validation inside the window, waits for Store
+	// across the end of the window, and does everything else outside.
+	createPipelineTransitions(t, item, []pipelineStep{
+		{0, constants.StageReceive, constants.StatusPending},
+		{30 * time.Minute, constants.StageReceive, constants.StatusStarted},
+		{50 * time.Minute, constants.StageValidate, constants.StatusPending},
+		{70 * time.Minute, constants.StageValidate, constants.StatusStarted},
+		{100 * time.Minute, constants.StageStore, constants.StatusPending},
+		{130 * time.Minute, constants.StageStore, constants.StatusStarted},
+		{150 * time.Minute, constants.StageStore, constants.StatusSuccess},
+	})
+	startDate := pipelineDay.Add(time.Hour)
+	endDate := pipelineDay.Add(2 * time.Hour)
+
+	// Only transitions inside the window count, but their times run
+	// to the next transition, even if that's outside the window.
+	stats, err := pgmodels.PipelineStatsSelect(2, startDate, endDate)
+	require.Nil(t, err)
+	stages := pipelineStagesByName(stats)
+	require.Equal(t, 2, len(stages))
+	validate := stages["Ingest/Validate"]
+	require.NotNil(t, validate)
+	assert.EqualValues(t, 0, validate.AvgQueueSeconds)
+	assert.InDelta(t, 1800, validate.P50ProcessingSeconds, 0.01)
+	store := stages["Ingest/Store"]
+	require.NotNil(t, store)
+	assert.InDelta(t, 1800, store.AvgQueueSeconds, 0.01)
+	assert.EqualValues(t, 0, store.P50ProcessingSeconds)
+
+	timings, err := pgmodels.StageTimingStatsSelect(startDate, endDate)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(timings))
+	assert.Equal(t, constants.StageStore, timings[0].Stage)
+	assert.EqualValues(t, 1800, timings[0].AvgSeconds)
+	assert.EqualValues(t, 0, timings[0].Attempts)
+	assert.Equal(t, constants.StageValidate, timings[1].Stage)
+	assert.EqualValues(t, 1800, timings[1].AvgSeconds)
+	assert.EqualValues(t, 1, timings[1].Attempts)
+}
+
+func pipelineStagesByName(stats *pgmodels.PipelineStats) map[string]*pgmodels.PipelineStageStats {
+	stages := make(map[string]*pgmodels.PipelineStageStats)
+	for _, s := range stats.Stages {
+		stages[s.Action+"/"+s.Stage] = s
+	}
+	return stages
+}
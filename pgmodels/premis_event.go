@@ -1,10 +1,12 @@
 package pgmodels
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/APTrust/registry/common"
 	"github.com/APTrust/registry/constants"
+	"github.com/google/uuid"
 	"github.com/stretchr/stew/slice"
 )
 
@@ -127,3 +129,26 @@ func (event *PremisEvent) Validate() *common.ValidationError {
 func ObjectEventCount(intellectualObjectID int64) (int, error) {
 	return common.Context().DB.Model((*PremisEvent)(nil)).Where(`intellectual_object_id = ? and generic_file_id is null`, intellectualObjectID).Count()
 }
+
+// newUndeleteEvent returns a compensating event for an object or file
+// that a failed deletion run marked as deleted. It's a deletion event
+// with a failure outcome, so it supersedes the original deletion event
+// and the item can be deleted again later. Pass genericFileID zero for
+// object-level events.
+func newUndeleteEvent(institutionID, intellectualObjectID, genericFileID int64, user *User) *PremisEvent {
+	now := time.Now().UTC()
+	return &PremisEvent{
+		Agent:                "APTrust Registry",
+		DateTime:             now,
+		Detail:               "Deletion reverted because preservation copies still exist",
+		EventType:            constants.EventDeletion,
+		GenericFileID:        genericFileID,
+		Identifier:           uuid.NewString(),
+		InstitutionID:        institutionID,
+		IntellectualObjectID: intellectualObjectID,
+		Object:               "APTrust Registry",
+		Outcome:              constants.OutcomeFailure,
+		OutcomeDetail:        user.Email,
+		OutcomeInformation:   fmt.Sprintf("State restored to active by %s. Storage records show the preservation copies were not deleted.", user.Email),
+	}
+}
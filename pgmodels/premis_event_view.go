@@ -16,6 +16,8 @@ var PremisEventFilters = []string{
 	"intellectual_object_id",
 	"intellectual_object_identifier",
 	"outcome",
+	"updated_at__gteq",
+	"updated_at__lteq",
 }
 
 type PremisEventView struct {
@@ -1,10 +1,14 @@
 package pgmodels_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/APTrust/registry/common"
 	"github.com/APTrust/registry/db"
 	"github.com/APTrust/registry/pgmodels"
+	"github.com/go-pg/pg/v10"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,3 +31,43 @@ func TestPremisEventView(t *testing.T) {
 	require.Nil(t, err)
 	assert.Equal(t, 13, len(eventViews))
 }
+
+// Cursor requests on events sort by coalesce(date_time, ...) because
+// date_time is nullable. Make sure Postgres can seek to the start of
+// the page with the expression indexes from migration 028, both for
+// admins and for members, whose queries filter on institution_id.
+func TestPremisEventViewCursorUsesIndex(t *testing.T) {
+	db.LoadFixtures()
+
+	// Use one connection, so the settings below apply to the EXPLAIN.
+	// The fixtures are small enough that Postgres would rather scan
+	// and sort the whole table than use any index.
+	conn := common.Context().DB.Conn()
+	defer conn.Close()
+	_, err := conn.Exec("set enable_seqscan = off")
+	require.Nil(t, err)
+	_, err = conn.Exec("set enable_sort = off")
+	require.Nil(t, err)
+
+	indexes := map[int64]string{
+		0: "index_premis_events_on_coalesce_date_time_and_id",
+		2: "index_premis_events_on_institution_id_coalesce_date_time_and_id",
+	}
+	for institutionID, indexName := range indexes {
+		query := pgmodels.NewQuery()
+		if institutionID > 0 {
+			query.Where("institution_id", "=", institutionID)
+		}
+		query.WhereAfterNullable("date_time", "desc", TestDate, 500, time.Time{}).
+			OrderByKeyset("date_time", "desc", time.Time{}).
+			Limit(20)
+		var events []*pgmodels.PremisEventView
+		sql, err := query.SelectSQL(&events)
+		require.Nil(t, err)
+
+		var plan pg.Strings
+		_, err = conn.Query(&plan, "EXPLAIN "+sql)
+		require.Nil(t, err)
+		assert.Contains(t, strings.Join(plan, "\n"), indexName, sql)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/APTrust/registry/common"
+	"github.com/go-pg/pg/v10/orm"
 )
 
 var QueryOp = map[string]string{
@@ -20,6 +21,7 @@ var QueryOp = map[string]string{
 	"not_in":      "NOT IN",
 	"is_null":     "IS NULL",
 	"not_null":    "IS NOT NULL",
+	"search":      "@@",
 }
 
 // Query provides a fluent model for constructing SQL queries,
@@ -49,9 +51,14 @@ type Query struct {
 	whereColumns        []string
 	relations           []string
 	orderBy             []string
+	orderByExpr         string
+	orderByParams       []interface{}
+	rankColumn          string
+	rankText            string
 	offset              int
 	limit               int
 	includesInCondition bool
+	includesOrCondition bool
 }
 
 func NewQuery() *Query {
@@ -65,6 +72,7 @@ func NewQuery() *Query {
 		offset:              -1,
 		limit:               -1,
 		includesInCondition: false,
+		includesOrCondition: false,
 	}
 }
 
@@ -90,11 +98,20 @@ func (q *Query) IsNotNull(col string) *Query {
 	return q
 }
 
+// Or adds a condition that is true if any of the comparisons in cols,
+// ops and vals is true. Each op may be any operator in QueryOp except
+// "@@". For IN and NOT IN, the corresponding val should be a
+// []interface{}. For IS NULL and IS NOT NULL, val is ignored.
 func (q *Query) Or(cols, ops []string, vals []interface{}) *Query {
+	if len(vals) > 0 && len(cols) == len(vals) {
+		q.includesOrCondition = true
+	}
 	q.multi(cols, ops, vals, " OR ")
 	return q
 }
 
+// And adds a condition that is true if all of the comparisons in cols,
+// ops and vals are true. See Or for a description of the params.
 func (q *Query) And(cols, ops []string, vals []interface{}) *Query {
 	q.multi(cols, ops, vals, " AND ")
 	return q
@@ -104,9 +121,20 @@ func (q *Query) multi(cols, ops []string, vals []interface{}, logicOp string) {
 	if len(vals) > 0 && len(cols) == len(vals) {
 		conditions := make([]string, len(cols))
 		for i, col := range cols {
-			conditions[i] = fmt.Sprintf(`%s %s ?`, common.SanitizeIdentifier(col), ops[i])
-			q.params = append(q.params, vals[i])
-			q.whereColumns = append(q.whereColumns, common.SanitizeIdentifier(col))
+			col = common.SanitizeIdentifier(col)
+			switch ops[i] {
+			case "IN", "NOT IN":
+				inVals, _ := vals[i].([]interface{})
+				conditions[i] = fmt.Sprintf(`%s %s (%s)`, col, ops[i], q.MakePlaceholders(len(q.params), len(inVals)))
+				q.params = append(q.params, inVals...)
+				q.includesInCondition = true
+			case "IS NULL", "IS NOT NULL":
+				conditions[i] = fmt.Sprintf(`%s %s`, col, strings.ToLower(ops[i]))
+			default:
+				conditions[i] = fmt.Sprintf(`%s %s ?`, col, ops[i])
+				q.params = append(q.params, vals[i])
+			}
+			q.whereColumns = append(q.whereColumns, col)
 		}
 		cond := fmt.Sprintf("(%s)", strings.Join(conditions, logicOp))
 		q.conditions = append(q.conditions, cond)
@@ -129,6 +157,64 @@ func (q *Query) BetweenExclusive(col string, low, high interface{}) *Query {
 	return q
 }
 
+// WhereAfter adds a keyset condition that selects rows coming after
+// the row with sort column value val and primary key id, in the
+// specified sort direction. This is for cursor paging, which is much
+// faster than offset paging deep into large tables, because Postgres
+// can seek directly to the starting row using an index on (col, id)
+// instead of scanning and discarding all rows before the offset.
+//
+// If col is "id", the condition compares only the id. Otherwise, it
+// compares the tuple (col, id), using id as a tie-breaker for rows
+// that share the same sort value. The caller must order the query by
+// col and then id, in the same direction. Use OrderByKeyset for that.
+func (q *Query) WhereAfter(col, direction string, val interface{}, id int64) *Query {
+	return q.WhereAfterNullable(col, direction, val, id, nil)
+}
+
+// WhereAfterNullable is like WhereAfter, for sort columns that may
+// contain NULLs. A tuple comparison with a NULL is never true, so rows
+// with a NULL sort value would never come after the cursor. If nullValue
+// is not nil, this compares coalesce(col, nullValue) instead, so those
+// rows sort as if they had nullValue. Pass the same nullValue to
+// OrderByKeyset.
+//
+// Postgres can't use a plain (col, id) index for the coalesce
+// expression. Nullable columns that are cursor paged by default need
+// an expression index on (coalesce(col, nullValue), id). See
+// db/migrations/028_keyset_paging_coalesce_indexes.sql.
+func (q *Query) WhereAfterNullable(col, direction string, val interface{}, id int64, nullValue interface{}) *Query {
+	op := ">"
+	if strings.ToLower(direction) == "desc" {
+		op = "<"
+	}
+	col = common.SanitizeIdentifier(col)
+	if col == "id" {
+		return q.Where("id", op, id)
+	}
+	q.whereColumns = append(q.whereColumns, col, "id")
+	if nullValue != nil {
+		q.conditions = append(q.conditions, fmt.Sprintf(`((coalesce(%s, ?), id) %s (?, ?))`, col, op))
+		q.params = append(q.params, nullValue, val, id)
+	} else {
+		q.conditions = append(q.conditions, fmt.Sprintf(`((%s, id) %s (?, ?))`, col, op))
+		q.params = append(q.params, val, id)
+	}
+	return q
+}
+
+// Search adds a full-text search condition on tsvector column col.
+// Param text is parsed with websearch_to_tsquery, so it supports
+// quoted phrases, "or" and -exclusions, and it never raises a syntax
+// error on user input.
+func (q *Query) Search(col, text string) *Query {
+	cond := fmt.Sprintf(`(%s @@ websearch_to_tsquery('english', ?))`, common.SanitizeIdentifier(col))
+	q.whereColumns = append(q.whereColumns, common.SanitizeIdentifier(col))
+	q.conditions = append(q.conditions, cond)
+	q.params = append(q.params, text)
+	return q
+}
+
 func (q *Query) WhereIn(col string, vals ...interface{}) *Query {
 	return q.inOrNotIn(col, "IN", vals...)
 }
@@ -165,6 +251,14 @@ func (q *Query) IncludesInCondition() bool {
 	return q.includesInCondition
 }
 
+// IncludesOrCondition returns true if this query's where clause includes
+// a condition added with Or. Like IN conditions, OR conditions can match
+// multiple rows in the *_counts tables, so we can't use those tables to
+// count the results of these queries.
+func (q *Query) IncludesOrCondition() bool {
+	return q.includesOrCondition
+}
+
 func (q *Query) MakePlaceholders(start, count int) string {
 	placeholders := make([]string, count)
 	for i := 0; i < count; i++ {
@@ -251,6 +345,39 @@ func (q *Query) OrderBy(column, direction string) *Query {
 	return q
 }
 
+// OrderByKeyset replaces any existing ordering with col and then id,
+// both in direction, for cursor paging. If nullValue is not nil, this
+// orders by coalesce(col, nullValue), to match WhereAfterNullable.
+func (q *Query) OrderByKeyset(col, direction string, nullValue interface{}) *Query {
+	dir := strings.ToLower(direction)
+	if dir != "desc" {
+		dir = "asc"
+	}
+	col = common.SanitizeIdentifier(col)
+	q.orderBy = make([]string, 0)
+	q.orderByExpr = ""
+	q.orderByParams = nil
+	if col == "id" {
+		return q.OrderBy("id", dir)
+	}
+	if nullValue != nil {
+		q.orderByExpr = fmt.Sprintf("coalesce(%s, ?) %s", col, dir)
+		q.orderByParams = []interface{}{nullValue}
+	} else {
+		q.OrderBy(col, dir)
+	}
+	return q.OrderBy("id", dir)
+}
+
+// OrderByRank orders results by how well tsvector column col matches
+// search text, best matches first. The rank ordering comes before any
+// orderings added with OrderBy, so those act as tie-breakers.
+func (q *Query) OrderByRank(col, text string) *Query {
+	q.rankColumn = common.SanitizeIdentifier(col)
+	q.rankText = text
+	return q
+}
+
 // Select executes a query and stores the result in structOrSlice,
 // which should be either a pointer to a struct (if you want a
 // single result) or a slice of pointers if you want multiple results.
@@ -266,6 +393,21 @@ func (q *Query) OrderBy(column, direction string) *Query {
 // var users []*User
 // err := query.Select(&users)
 func (q *Query) Select(structOrSlice interface{}) error {
+	return q.selectQuery(structOrSlice).Select()
+}
+
+// SelectSQL returns the SQL that Select would run for structOrSlice,
+// with all params filled in. This is handy for logging slow queries
+// and for checking query plans with EXPLAIN.
+func (q *Query) SelectSQL(structOrSlice interface{}) (string, error) {
+	sql, err := q.selectQuery(structOrSlice).AppendQuery(common.Context().DB.Formatter(), nil)
+	return string(sql), err
+}
+
+// selectQuery returns a go-pg select query for structOrSlice, built
+// from this query's columns, relations, conditions, ordering, limit
+// and offset.
+func (q *Query) selectQuery(structOrSlice interface{}) *orm.Query {
 	orm := common.Context().DB.Model(structOrSlice)
 	for _, rel := range q.GetRelations() {
 		orm.Relation(rel)
@@ -278,6 +420,12 @@ func (q *Query) Select(structOrSlice interface{}) error {
 	if q.WhereClause() != "" {
 		orm.Where(q.WhereClause(), q.Params()...)
 	}
+	if q.rankColumn != "" {
+		orm.OrderExpr(fmt.Sprintf(`ts_rank(%s, websearch_to_tsquery('english', ?)) desc`, q.rankColumn), q.rankText)
+	}
+	if q.orderByExpr != "" {
+		orm.OrderExpr(q.orderByExpr, q.orderByParams...)
+	}
 	for _, orderBy := range q.GetOrderBy() {
 		orm.Order(orderBy)
 	}
@@ -287,7 +435,7 @@ func (q *Query) Select(structOrSlice interface{}) error {
 	if q.GetOffset() >= 0 {
 		orm.Offset(q.GetOffset())
 	}
-	return orm.Select()
+	return orm
 }
 
 func (q *Query) Count(model interface{}) (int, error) {
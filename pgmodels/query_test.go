@@ -80,6 +80,21 @@ func TestOr(t *testing.T) {
 	assert.Equal(t, 3, len(q.Params()))
 }
 
+func TestOrWithInAndNull(t *testing.T) {
+	q := pgmodels.NewQuery()
+	assert.False(t, q.IncludesOrCondition())
+	cols := []string{"col1", "col2", "col3"}
+	ops := []string{"IN", "IS NULL", ">"}
+	vals := []interface{}{[]interface{}{"a", "b"}, nil, 5}
+	q.Or(cols, ops, vals)
+
+	assert.Equal(t, `(col1 IN (?, ?) OR col2 is null OR col3 > ?)`, q.WhereClause())
+	assert.Equal(t, []interface{}{"a", "b", 5}, q.Params())
+	assert.Equal(t, []string{"col1", "col2", "col3"}, q.GetColumnsInWhereClause())
+	assert.True(t, q.IncludesOrCondition())
+	assert.True(t, q.IncludesInCondition())
+}
+
 func TestAnd(t *testing.T) {
 	q := pgmodels.NewQuery()
 	cols := []string{"col1", "col2", "col3"}
@@ -105,6 +120,77 @@ func TestBetweenExclusive(t *testing.T) {
 	assert.Equal(t, 2, len(q.Params()))
 }
 
+func TestWhereAfter(t *testing.T) {
+	q := pgmodels.NewQuery()
+	q.WhereAfter("updated_at", "desc", TestDate, 500)
+	assert.Equal(t, `((updated_at, id) < (?, ?))`, q.WhereClause())
+	assert.Equal(t, []interface{}{TestDate, int64(500)}, q.Params())
+	assert.Equal(t, []string{"updated_at", "id"}, q.GetColumnsInWhereClause())
+
+	q = pgmodels.NewQuery()
+	q.WhereAfter("size", "asc", 1024, 500)
+	assert.Equal(t, `((size, id) > (?, ?))`, q.WhereClause())
+	assert.Equal(t, []interface{}{1024, int64(500)}, q.Params())
+
+	// Sorting on id alone needs no tie-breaker
+	q = pgmodels.NewQuery()
+	q.WhereAfter("id", "asc", int64(500), 500)
+	assert.Equal(t, `(id > ?)`, q.WhereClause())
+	assert.Equal(t, []interface{}{int64(500)}, q.Params())
+}
+
+func TestWhereAfterNullable(t *testing.T) {
+	q := pgmodels.NewQuery()
+	q.WhereAfterNullable("date_time", "desc", TestDate, 500, time.Time{})
+	assert.Equal(t, `((coalesce(date_time, ?), id) < (?, ?))`, q.WhereClause())
+	assert.Equal(t, []interface{}{time.Time{}, TestDate, int64(500)}, q.Params())
+	assert.Equal(t, []string{"date_time", "id"}, q.GetColumnsInWhereClause())
+
+	q = pgmodels.NewQuery()
+	q.WhereAfterNullable("updated_at", "asc", TestDate, 500, nil)
+	assert.Equal(t, `((updated_at, id) > (?, ?))`, q.WhereClause())
+}
+
+func TestOrderByKeyset(t *testing.T) {
+	q := pgmodels.NewQuery()
+	q.OrderBy("identifier", "asc")
+	q.OrderByKeyset("updated_at", "desc", nil)
+	assert.Equal(t, []string{"updated_at desc", "id desc"}, q.GetOrderBy())
+
+	q.OrderByKeyset("id", "asc", nil)
+	assert.Equal(t, []string{"id asc"}, q.GetOrderBy())
+
+	// Nullable columns are ordered by an expression, with id as
+	// the tie-breaker.
+	q.OrderByKeyset("date_time", "desc", time.Time{})
+	assert.Equal(t, []string{"id desc"}, q.GetOrderBy())
+}
+
+func TestSelectSQL(t *testing.T) {
+	// This is the shape of a cursor request for the second page of
+	// events, sorted by the default date_time desc. The coalesce
+	// expressions must match the ones in migration 028 exactly, or
+	// Postgres won't use those indexes.
+	q := pgmodels.NewQuery().
+		Where("institution_id", "=", 2).
+		WhereAfterNullable("date_time", "desc", TestDate, 500, time.Time{}).
+		OrderByKeyset("date_time", "desc", time.Time{}).
+		Limit(20)
+	var events []*pgmodels.PremisEventView
+	sql, err := q.SelectSQL(&events)
+	require.Nil(t, err)
+	assert.Contains(t, sql, `WHERE ((institution_id = 2) AND ((coalesce(date_time, '0001-01-01 00:00:00+00:00:00'), id) < ('2021-06-16 10:24:16+00:00:00', 500)))`)
+	assert.Contains(t, sql, `ORDER BY coalesce(date_time, '0001-01-01 00:00:00+00:00:00') desc, "id" desc LIMIT 20`)
+}
+
+func TestSearch(t *testing.T) {
+	q := pgmodels.NewQuery()
+	q.Search("search_vector", "photos -glass")
+	assert.Equal(t, `(search_vector @@ websearch_to_tsquery('english', ?))`, q.WhereClause())
+	assert.Equal(t, []interface{}{"photos -glass"}, q.Params())
+	assert.Equal(t, []string{"search_vector"}, q.GetColumnsInWhereClause())
+}
+
 func TestMakePlaceholders(t *testing.T) {
 	q := pgmodels.NewQuery()
 	assert.Equal(t, "?, ?, ?, ?", q.MakePlaceholders(0, 4))
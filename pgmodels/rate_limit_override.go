@@ -0,0 +1,220 @@
+package pgmodels
+
+import (
+	"sync"
+	"time"
+
+	"github.com/APTrust/registry/common"
+)
+
+const (
+	ErrRateLimitInstID    = "Override requires a valid institution id."
+	ErrRateLimitPerMinute = "Requests per minute must be greater than zero, unless the override is exempt."
+	ErrRateLimitBurst     = "Burst must be greater than zero, unless the override is exempt."
+	ErrRateLimitWrongInst = "User does not belong to the selected institution."
+)
+
+// rateLimitOverridesTTL is how long we cache overrides in memory.
+const rateLimitOverridesTTL = time.Minute
+
+// RateLimitOverride replaces the default API rate limits in
+// common.RateLimitConfig for one user or, if UserID is zero, for the
+// bucket shared by all users at an institution. If Exempt is true,
+// requests from that user or institution are not limited at all.
+// APTrust admins use exempt overrides for preservation services
+// accounts.
+type RateLimitOverride struct {
+	TimestampModel
+	AuditedModel
+	InstitutionID int64        `json:"institution_id" pg:"institution_id"`
+	UserID        int64        `json:"user_id" pg:"user_id"`
+	PerMinute     int          `json:"per_minute" pg:"per_minute,use_zero"`
+	Burst         int          `json:"burst" pg:"burst,use_zero"`
+	Exempt        bool         `json:"exempt" pg:"exempt,use_zero"`
+	Note          string       `json:"note" pg:"note"`
+	Institution   *Institution `json:"-" pg:"rel:has-one"`
+	User          *User        `json:"-" pg:"rel:has-one"`
+}
+
+// rateLimitOverrides caches all overrides, so that the rate limit
+// middleware doesn't have to query the database on every API request.
+// Saving or deleting an override clears the cache in this process.
+// Other Registry processes pick up the change within
+// rateLimitOverridesTTL.
+//
+// Only one request at a time reloads the cache. Other requests keep
+// using the stale overrides until the reload finishes, so they don't
+// wait on the database. See RateLimitOverridesFor.
+var rateLimitOverrides = struct {
+	sync.RWMutex
+	loadedAt   time.Time
+	loaded     bool
+	generation int
+	byUser     map[int64]*RateLimitOverride
+	byInst     map[int64]*RateLimitOverride
+}{}
+
+// rateLimitOverridesReload ensures that only one request at a time
+// reloads rateLimitOverrides.
+var rateLimitOverridesReload sync.Mutex
+
+// RateLimitOverrideByID returns the override with the specified id.
+// Returns pg.ErrNoRows if there is no match.
+func RateLimitOverrideByID(id int64) (*RateLimitOverride, error) {
+	query := NewQuery().Where(`"rate_limit_override"."id"`, "=", id)
+	return RateLimitOverrideGet(query)
+}
+
+// RateLimitOverrideGet returns the first override matching the query.
+func RateLimitOverrideGet(query *Query) (*RateLimitOverride, error) {
+	var override RateLimitOverride
+	err := query.Select(&override)
+	return &override, err
+}
+
+// RateLimitOverrideSelect returns all overrides matching the query.
+func RateLimitOverrideSelect(query *Query) ([]*RateLimitOverride, error) {
+	var overrides []*RateLimitOverride
+	err := query.Select(&overrides)
+	return overrides, err
+}
+
+// RateLimitOverridesFor returns the overrides that apply to the
+// specified user and to that user's institution. Either or both
+// may be nil if no override applies.
+//
+// This reads from a cache that may be up to rateLimitOverridesTTL old.
+// When the cache expires, one caller reloads it while the others get
+// the stale overrides. Only the first call in a process has to wait for
+// the database. If the reload fails, we keep the stale overrides and
+// try again when the TTL expires, rather than querying a failing
+// database on every request.
+func RateLimitOverridesFor(userID, institutionID int64) (userOverride, instOverride *RateLimitOverride, err error) {
+	rateLimitOverrides.RLock()
+	fresh := time.Since(rateLimitOverrides.loadedAt) <= rateLimitOverridesTTL
+	loaded := rateLimitOverrides.loaded
+	rateLimitOverrides.RUnlock()
+	if !fresh {
+		if loaded {
+			if rateLimitOverridesReload.TryLock() {
+				err = reloadRateLimitOverrides()
+				rateLimitOverridesReload.Unlock()
+			}
+		} else {
+			rateLimitOverridesReload.Lock()
+			err = reloadRateLimitOverrides()
+			rateLimitOverridesReload.Unlock()
+		}
+	}
+	rateLimitOverrides.RLock()
+	defer rateLimitOverrides.RUnlock()
+	return rateLimitOverrides.byUser[userID], rateLimitOverrides.byInst[institutionID], err
+}
+
+// reloadRateLimitOverrides loads all overrides into the cache, unless
+// another caller did so while we were waiting. Call this only while
+// holding rateLimitOverridesReload.
+func reloadRateLimitOverrides() error {
+	rateLimitOverrides.RLock()
+	fresh := time.Since(rateLimitOverrides.loadedAt) <= rateLimitOverridesTTL
+	generation := rateLimitOverrides.generation
+	rateLimitOverrides.RUnlock()
+	if fresh {
+		return nil
+	}
+	overrides, err := RateLimitOverrideSelect(NewQuery())
+	rateLimitOverrides.Lock()
+	defer rateLimitOverrides.Unlock()
+	// If someone cleared the cache while we were querying, what we
+	// loaded may be out of date, so let the next caller load it again.
+	if generation == rateLimitOverrides.generation {
+		rateLimitOverrides.loadedAt = time.Now()
+	}
+	if err != nil {
+		return err
+	}
+	rateLimitOverrides.byUser = make(map[int64]*RateLimitOverride)
+	rateLimitOverrides.byInst = make(map[int64]*RateLimitOverride)
+	for _, override := range overrides {
+		if override.UserID > 0 {
+			rateLimitOverrides.byUser[override.UserID] = override
+		} else {
+			rateLimitOverrides.byInst[override.InstitutionID] = override
+		}
+	}
+	rateLimitOverrides.loaded = true
+	return nil
+}
+
+// ClearRateLimitOverrideCache forces the next call to
+// RateLimitOverridesFor to reload overrides from the database.
+func ClearRateLimitOverrideCache() {
+	rateLimitOverrides.Lock()
+	defer rateLimitOverrides.Unlock()
+	rateLimitOverrides.loadedAt = time.Time{}
+	rateLimitOverrides.generation++
+}
+
+// RateLimit returns the limit this override imposes.
+// Exempt overrides return an unlimited RateLimit.
+func (o *RateLimitOverride) RateLimit() common.RateLimit {
+	if o.Exempt {
+		return common.RateLimit{}
+	}
+	return common.RateLimit{PerMinute: o.PerMinute, Burst: o.Burst}
+}
+
+// Save saves this override to the database. This will peform an insert
+// if RateLimitOverride.ID is zero. Otherwise, it updates.
+func (o *RateLimitOverride) Save() error {
+	o.SetTimestamps()
+	if o.Exempt {
+		o.PerMinute = 0
+		o.Burst = 0
+	}
+	err := o.Validate()
+	if err != nil {
+		return err
+	}
+	if o.UserID > 0 {
+		user, err := UserByID(o.UserID)
+		if err != nil {
+			return err
+		}
+		if user.InstitutionID != o.InstitutionID {
+			return &common.ValidationError{Errors: map[string]string{"UserID": ErrRateLimitWrongInst}}
+		}
+	}
+	defer ClearRateLimitOverrideCache()
+	if o.ID == int64(0) {
+		return insert(o)
+	}
+	return update(o)
+}
+
+// Delete deletes this override. The user or institution goes back
+// to the default rate limits.
+func (o *RateLimitOverride) Delete() error {
+	defer ClearRateLimitOverrideCache()
+	return hardDelete(o)
+}
+
+// Validate returns errors if this override is not valid.
+func (o *RateLimitOverride) Validate() *common.ValidationError {
+	errors := make(map[string]string)
+	if o.InstitutionID < 1 {
+		errors["InstitutionID"] = ErrRateLimitInstID
+	}
+	if !o.Exempt {
+		if o.PerMinute < 1 {
+			errors["PerMinute"] = ErrRateLimitPerMinute
+		}
+		if o.Burst < 1 {
+			errors["Burst"] = ErrRateLimitBurst
+		}
+	}
+	if len(errors) > 0 {
+		return &common.ValidationError{Errors: errors}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package pgmodels_test
+
+import (
+	"testing"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitOverrideValidate(t *testing.T) {
+	override := &pgmodels.RateLimitOverride{}
+	err := override.Validate()
+	require.NotNil(t, err)
+	assert.Equal(t, pgmodels.ErrRateLimitInstID, err.Errors["InstitutionID"])
+	assert.Equal(t, pgmodels.ErrRateLimitPerMinute, err.Errors["PerMinute"])
+	assert.Equal(t, pgmodels.ErrRateLimitBurst, err.Errors["Burst"])
+
+	// Exempt overrides don't need limits.
+	override = &pgmodels.RateLimitOverride{InstitutionID: 2, Exempt: true}
+	assert.Nil(t, override.Validate())
+	assert.True(t, override.RateLimit().Unlimited())
+
+	override = &pgmodels.RateLimitOverride{InstitutionID: 2, PerMinute: 60, Burst: 5}
+	assert.Nil(t, override.Validate())
+	assert.Equal(t, common.RateLimit{PerMinute: 60, Burst: 5}, override.RateLimit())
+}
+
+func TestRateLimitOverrideSave(t *testing.T) {
+	db.LoadFixtures()
+	user, err := pgmodels.UserByEmail("user@inst1.edu")
+	require.Nil(t, err)
+
+	userOverride, instOverride, err := pgmodels.RateLimitOverridesFor(user.ID, user.InstitutionID)
+	require.Nil(t, err)
+	assert.Nil(t, userOverride)
+	assert.Nil(t, instOverride)
+
+	// User must belong to the override's institution.
+	override := &pgmodels.RateLimitOverride{
+		InstitutionID: user.InstitutionID + 1,
+		UserID:        user.ID,
+		PerMinute:     60,
+		Burst:         5,
+	}
+	err = override.Save()
+	require.NotNil(t, err)
+	valErr, ok := err.(*common.ValidationError)
+	require.True(t, ok)
+	assert.Equal(t, pgmodels.ErrRateLimitWrongInst, valErr.Errors["UserID"])
+
+	override.InstitutionID = user.InstitutionID
+	require.Nil(t, override.Save())
+
+	instLimit := &pgmodels.RateLimitOverride{
+		InstitutionID: user.InstitutionID,
+		Exempt:        true,
+		PerMinute:     99,
+		Burst:         99,
+	}
+	require.Nil(t, instLimit.Save())
+
+	// Exempt overrides don't keep limits.
+	assert.Equal(t, 0, instLimit.PerMinute)
+	assert.Equal(t, 0, instLimit.Burst)
+
+	// Saving clears the cache, so we should see both overrides.
+	userOverride, instOverride, err = pgmodels.RateLimitOverridesFor(user.ID, user.InstitutionID)
+	require.Nil(t, err)
+	require.NotNil(t, userOverride)
+	require.NotNil(t, instOverride)
+	assert.Equal(t, override.ID, userOverride.ID)
+	assert.Equal(t, instLimit.ID, instOverride.ID)
+
+	// Within the TTL, we read overrides from the cache. Changes that
+	// bypass Save don't show up until the cache is cleared.
+	_, err = common.Context().DB.Model((*pgmodels.RateLimitOverride)(nil)).
+		Set("burst = ?", 7).
+		Where("id = ?", override.ID).
+		Update()
+	require.Nil(t, err)
+	userOverride, _, err = pgmodels.RateLimitOverridesFor(user.ID, user.InstitutionID)
+	require.Nil(t, err)
+	assert.Equal(t, 5, userOverride.Burst)
+	pgmodels.ClearRateLimitOverrideCache()
+	userOverride, _, err = pgmodels.RateLimitOverridesFor(user.ID, user.InstitutionID)
+	require.Nil(t, err)
+	assert.Equal(t, 7, userOverride.Burst)
+
+	// A second override for the same user violates a unique index.
+	duplicate := &pgmodels.RateLimitOverride{
+		InstitutionID: user.InstitutionID,
+		UserID:        user.ID,
+		PerMinute:     10,
+		Burst:         1,
+	}
+	assert.NotNil(t, duplicate.Save())
+
+	require.Nil(t, override.Delete())
+	require.Nil(t, instLimit.Delete())
+	userOverride, instOverride, err = pgmodels.RateLimitOverridesFor(user.ID, user.InstitutionID)
+	require.Nil(t, err)
+	assert.Nil(t, userOverride)
+	assert.Nil(t, instOverride)
+}
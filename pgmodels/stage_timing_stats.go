@@ -0,0 +1,72 @@
+package pgmodels
+
+import (
+	"time"
+
+	"github.com/APTrust/registry/common"
+)
+
+// StageTimingStats describes how long WorkItems with a given action
+// spent in a given stage. Times are in seconds. ItemCount is the number
+// of items that finished the stage. Attempts counts the times workers
+// started the stage, so Attempts greater than ItemCount means some
+// items were retried.
+type StageTimingStats struct {
+	Action        string  `json:"action"`
+	Stage         string  `json:"stage"`
+	ItemCount     int64   `json:"item_count"`
+	Attempts      int64   `json:"attempts"`
+	AvgSeconds    float64 `json:"avg_seconds"`
+	MedianSeconds float64 `json:"median_seconds"`
+	MaxSeconds    float64 `json:"max_seconds"`
+}
+
+// transitionTimesQuery returns each work item transition recorded
+// between a start and end date, with the number of seconds until the
+// item's next transition. That's how long the item stayed in the
+// transition's stage and status. An item's final transition has no
+// successor, so its seconds are null. The last two params are an
+// institution id, which may be zero to include all institutions.
+//
+// The inner query finds each transition's successor before we apply
+// the end date, so a transition near the end of the range gets its
+// time from a successor recorded after the range. Successors always
+// come later, so the inner query can skip transitions before the
+// start date.
+var transitionTimesQuery = `select work_item_id, action, stage, status,
+	extract(epoch from (next_created_at - created_at)) as seconds
+	from (
+		select work_item_id, action, stage, status, created_at,
+		lead(created_at) over (partition by work_item_id order by created_at, id) as next_created_at
+		from work_item_transitions
+		where created_at >= ?0
+		and (?2 = 0 or institution_id = ?3)
+	) item_transitions
+	where created_at < ?1`
+
+// stageTimingQuery sums the transition times per item and stage, then
+// aggregates per action and stage.
+var stageTimingQuery = `select action, stage,
+	count(*) as item_count,
+	sum(attempts) as attempts,
+	avg(seconds) as avg_seconds,
+	percentile_cont(0.5) within group (order by seconds) as median_seconds,
+	max(seconds) as max_seconds
+	from (
+		select work_item_id, action, stage,
+		sum(seconds) as seconds,
+		sum(case when status = 'Started' then 1 else 0 end) as attempts
+		from (` + transitionTimesQuery + `) transition_times
+		where seconds is not null
+		group by work_item_id, action, stage
+	) item_stage_times
+	group by action, stage
+	order by action, stage`
+
+// StageTimingStatsSelect returns timing stats for each action and stage,
+// based on transitions recorded between startDate and endDate.
+func StageTimingStatsSelect(startDate, endDate time.Time) ([]*StageTimingStats, error) {
+	var stats []*StageTimingStats
+	_, err := common.Context().DB.Query(&stats, stageTimingQuery, startDate, endDate, 0, 0)
+	return stats, err
+}
@@ -0,0 +1,49 @@
+package pgmodels_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageTimingStatsSelect(t *testing.T) {
+	db.LoadFixtures()
+	startDate := time.Date(2016, 8, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2016, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	stats, err := pgmodels.StageTimingStatsSelect(startDate, endDate)
+	require.Nil(t, err)
+
+	// The transition fixtures for work item 26 spent time in five
+	// Ingest stages. The final transition has no successor, so the
+	// Cleanup time runs from the first Cleanup row to the last.
+	expected := map[string][]float64{
+		// stage: attempts, seconds
+		constants.StageReceive:  {1, 300},
+		constants.StageValidate: {1, 1500},
+		constants.StageStore:    {2, 9000},
+		constants.StageRecord:   {1, 1800},
+		constants.StageCleanup:  {1, 900},
+	}
+	require.Equal(t, len(expected), len(stats))
+	for _, s := range stats {
+		values, ok := expected[s.Stage]
+		require.True(t, ok, s.Stage)
+		assert.Equal(t, constants.ActionIngest, s.Action)
+		assert.EqualValues(t, 1, s.ItemCount, s.Stage)
+		assert.EqualValues(t, values[0], s.Attempts, s.Stage)
+		assert.EqualValues(t, values[1], s.AvgSeconds, s.Stage)
+		assert.EqualValues(t, values[1], s.MedianSeconds, s.Stage)
+		assert.EqualValues(t, values[1], s.MaxSeconds, s.Stage)
+	}
+
+	// No transitions in this range.
+	stats, err = pgmodels.StageTimingStatsSelect(startDate.AddDate(-5, 0, 0), startDate.AddDate(-4, 0, 0))
+	require.Nil(t, err)
+	assert.Empty(t, stats)
+}
@@ -0,0 +1,154 @@
+package pgmodels
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/go-pg/pg/v10"
+)
+
+// workItemAlertRecord describes when a WorkItem last appeared
+// in an alert of a given type.
+type workItemAlertRecord struct {
+	WorkItemID    int64
+	LastAlertedAt time.Time
+}
+
+// StalledSince returns the time this item started waiting in its
+// current stage. If a worker has claimed the item (Node is set), that's
+// when the worker started the stage. Otherwise, it's the later of when
+// we queued the item and when someone last updated it, because requeueing
+// an item updates it without changing QueuedAt.
+func (item *WorkItem) StalledSince() time.Time {
+	if item.Node != "" && !item.StageStartedAt.IsZero() {
+		return item.StageStartedAt
+	}
+	if item.QueuedAt.After(item.UpdatedAt) {
+		return item.QueuedAt
+	}
+	return item.UpdatedAt
+}
+
+// IsStalled returns true if this item is incomplete and has been in its
+// current stage longer than config allows for that stage.
+func (item *WorkItem) IsStalled(config *common.StalledItemConfig, now time.Time) bool {
+	if item.HasCompleted() || item.Status == constants.StatusSuspended {
+		return false
+	}
+	return now.Sub(item.StalledSince()) > config.ThresholdFor(item.Stage)
+}
+
+// StalledWorkItems returns pending and started WorkItems that have been
+// in their current stage longer than config allows. It skips items that
+// already appeared in a Stalled Work Items alert after they entered their
+// current stage, so admins hear about each stall only once.
+func StalledWorkItems(config *common.StalledItemConfig, now time.Time) ([]*WorkItem, error) {
+	query := NewQuery().
+		WhereIn("status", common.InterfaceList(constants.IncompleteStatusValues)...).
+		OrderBy("id", "asc")
+	items, err := WorkItemSelect(query)
+	if err != nil {
+		return nil, err
+	}
+	var stalled []*WorkItem
+	var ids []int64
+	for _, item := range items {
+		if item.IsStalled(config, now) {
+			stalled = append(stalled, item)
+			ids = append(ids, item.ID)
+		}
+	}
+	if len(stalled) == 0 {
+		return stalled, nil
+	}
+	lastAlerted, err := workItemsLastAlerted(constants.AlertStalledItems, ids)
+	if err != nil {
+		return nil, err
+	}
+	var unreported []*WorkItem
+	for _, item := range stalled {
+		alertedAt, ok := lastAlerted[item.ID]
+		if ok && alertedAt.After(item.StalledSince()) {
+			continue
+		}
+		unreported = append(unreported, item)
+	}
+	return unreported, nil
+}
+
+// workItemsLastAlerted returns a map of WorkItem id to the time we
+// last included that item in an alert of type alertType. Items that
+// have never been in such an alert are not in the map.
+func workItemsLastAlerted(alertType string, ids []int64) (map[int64]time.Time, error) {
+	var records []*workItemAlertRecord
+	sql := `select awi.work_item_id, max(a.created_at) as last_alerted_at
+	from alerts_work_items awi
+	join alerts a on a.id = awi.alert_id
+	where a.type = ? and awi.work_item_id in (?)
+	group by awi.work_item_id`
+	_, err := common.Context().DB.Query(&records, sql, alertType, pg.In(ids))
+	if err != nil {
+		return nil, err
+	}
+	lastAlerted := make(map[int64]time.Time, len(records))
+	for _, record := range records {
+		lastAlerted[record.WorkItemID] = record.LastAlertedAt
+	}
+	return lastAlerted, nil
+}
+
+// CreateStalledWorkItemsAlert creates one alert telling APTrust admins
+// about all WorkItems that have stalled since we last alerted them.
+// Each item in the alert links to the work item page, where admins can
+// requeue it. Param registryURL is the scheme and host to use in those
+// links. This returns nil and no error if nothing has stalled.
+func CreateStalledWorkItemsAlert(config *common.StalledItemConfig, registryURL string) (*Alert, error) {
+	now := time.Now().UTC()
+	items, err := StalledWorkItems(config, now)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	aptrust, err := InstitutionByIdentifier("aptrust.org")
+	if err != nil {
+		return nil, err
+	}
+	adminsQuery := NewQuery().
+		Where("role", "=", constants.RoleSysAdmin).
+		IsNull("deactivated_at")
+	admins, err := UserSelect(adminsQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	itemData := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		since := item.StalledSince()
+		itemData[i] = map[string]interface{}{
+			"ID":           item.ID,
+			"Name":         item.Name,
+			"Action":       item.Action,
+			"Stage":        item.Stage,
+			"Status":       item.Status,
+			"Node":         item.Node,
+			"PID":          item.PID,
+			"StalledSince": since.Format(time.RFC3339),
+			"StalledFor":   now.Sub(since).Round(time.Minute).String(),
+			"Threshold":    config.ThresholdFor(item.Stage).String(),
+			"RequeueURL":   fmt.Sprintf("%s/work_items/show/%d#workItemRequeueForm", registryURL, item.ID),
+		}
+	}
+	alertData := map[string]interface{}{
+		"ItemCount": len(items),
+		"Items":     itemData,
+	}
+	alert := &Alert{
+		InstitutionID: aptrust.ID,
+		Type:          constants.AlertStalledItems,
+		Subject:       fmt.Sprintf("%d Stalled Work Items", len(items)),
+		Users:         admins,
+		WorkItems:     items,
+	}
+	return CreateAlert(alert, "alerts/stalled_items.txt", alertData)
+}
@@ -0,0 +1,114 @@
+package pgmodels_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stalledItemConfig() *common.StalledItemConfig {
+	return &common.StalledItemConfig{
+		Enabled:          true,
+		DefaultThreshold: 6 * time.Hour,
+		StageThresholds: map[string]time.Duration{
+			constants.StageStore: 24 * time.Hour,
+		},
+	}
+}
+
+func TestWorkItemStalledSince(t *testing.T) {
+	now := time.Now().UTC()
+	item := &pgmodels.WorkItem{
+		QueuedAt:       now.Add(-10 * time.Hour),
+		StageStartedAt: now.Add(-8 * time.Hour),
+	}
+	item.UpdatedAt = now.Add(-9 * time.Hour)
+
+	// No worker has claimed this, so it's been waiting since
+	// the later of QueuedAt and UpdatedAt.
+	assert.Equal(t, item.UpdatedAt, item.StalledSince())
+	item.QueuedAt = now.Add(-1 * time.Hour)
+	assert.Equal(t, item.QueuedAt, item.StalledSince())
+
+	// Once a worker claims it, it's been waiting since
+	// the worker started the stage.
+	item.Node = "worker-1"
+	item.PID = 1234
+	assert.Equal(t, item.StageStartedAt, item.StalledSince())
+}
+
+func TestWorkItemIsStalled(t *testing.T) {
+	config := stalledItemConfig()
+	now := time.Now().UTC()
+	item := &pgmodels.WorkItem{
+		Stage:          constants.StageValidate,
+		Status:         constants.StatusStarted,
+		Node:           "worker-1",
+		PID:            1234,
+		StageStartedAt: now.Add(-8 * time.Hour),
+	}
+	assert.True(t, item.IsStalled(config, now))
+
+	// Store gets a longer threshold.
+	item.Stage = constants.StageStore
+	assert.False(t, item.IsStalled(config, now))
+	item.StageStartedAt = now.Add(-25 * time.Hour)
+	assert.True(t, item.IsStalled(config, now))
+
+	// Completed and suspended items don't stall.
+	item.Status = constants.StatusSuccess
+	assert.False(t, item.IsStalled(config, now))
+	item.Status = constants.StatusSuspended
+	assert.False(t, item.IsStalled(config, now))
+}
+
+func TestCreateStalledWorkItemsAlert(t *testing.T) {
+	db.ForceFixtureReload()
+	defer db.ForceFixtureReload()
+	config := stalledItemConfig()
+
+	// All pending items in our fixtures were queued years ago.
+	items, err := pgmodels.StalledWorkItems(config, time.Now().UTC())
+	require.Nil(t, err)
+	require.Equal(t, 24, len(items))
+
+	alert, err := pgmodels.CreateStalledWorkItemsAlert(config, "https://example.com")
+	require.Nil(t, err)
+	require.NotNil(t, alert)
+	assert.Equal(t, constants.AlertStalledItems, alert.Type)
+	assert.Equal(t, "24 Stalled Work Items", alert.Subject)
+	assert.Equal(t, 24, len(alert.WorkItems))
+	require.NotEmpty(t, alert.Users)
+	for _, user := range alert.Users {
+		assert.Equal(t, constants.RoleSysAdmin, user.Role)
+	}
+	assert.Contains(t, alert.Content, "https://example.com/work_items/show/1#workItemRequeueForm")
+
+	// We've already told admins about these, so we
+	// shouldn't alert them again.
+	alert, err = pgmodels.CreateStalledWorkItemsAlert(config, "https://example.com")
+	require.Nil(t, err)
+	assert.Nil(t, alert)
+
+	// A newly stalled item should get a new alert.
+	item := pgmodels.RandomWorkItem("stalled.tar", constants.ActionIngest, 0, 0)
+	item.Stage = constants.StageValidate
+	item.Status = constants.StatusStarted
+	item.Node = "worker-1"
+	item.PID = 4321
+	item.StageStartedAt = time.Now().UTC().Add(-7 * time.Hour)
+	require.Nil(t, item.Save())
+
+	alert, err = pgmodels.CreateStalledWorkItemsAlert(config, "https://example.com")
+	require.Nil(t, err)
+	require.NotNil(t, alert)
+	require.Equal(t, 1, len(alert.WorkItems))
+	assert.Equal(t, item.ID, alert.WorkItems[0].ID)
+	assert.Contains(t, alert.Content, "worker-1")
+}
@@ -34,6 +34,7 @@ const (
 // in production.
 type User struct {
 	TimestampModel
+	AuditedModel
 
 	// Name is the user's display name.
 	Name string `json:"name" pg:"name"`
@@ -47,12 +48,12 @@ type User struct {
 	PhoneNumber string `json:"phone_number" pg:"phone_number"`
 
 	// EncryptedPassword is the user's password, encrypted.
-	EncryptedPassword string `json:"-" form:"-" pg:"encrypted_password"`
+	EncryptedPassword string `json:"-" form:"-" pg:"encrypted_password" audit:"secret"`
 
 	// ResetPasswordToken is an encrypted version of the token sent to a
 	// user who wants to reset their password. The plaintext version of
 	// this is emailed to the user.
-	ResetPasswordToken string `json:"-" form:"-" pg:"reset_password_token"`
+	ResetPasswordToken string `json:"-" form:"-" pg:"reset_password_token" audit:"secret"`
 
 	// ResetPasswordSentAt is a timestamp describing when we sent a password
 	// reset email. The ResetPasswordToken should be valid for only a few
@@ -87,7 +88,7 @@ type User struct {
 
 	// EncryptedAPISecretKey is the user's encrypted API key. This may
 	// be empty if the user has never requested a key.
-	EncryptedAPISecretKey string `json:"-" form:"-" pg:"encrypted_api_secret_key"`
+	EncryptedAPISecretKey string `json:"-" form:"-" pg:"encrypted_api_secret_key" audit:"secret"`
 
 	// PasswordChangedAt is the date and time the user last changed their
 	// password.
@@ -97,7 +98,7 @@ type User struct {
 	// password. The plaintext is usually a six-digit code sent via SMS.
 	// This will be empty if we didn't send a code, or if the user has
 	// correctly entered it and completed two-factor login.
-	EncryptedOTPSecret string `json:"-" form:"-" pg:"encrypted_otp_secret"`
+	EncryptedOTPSecret string `json:"-" form:"-" pg:"encrypted_otp_secret" audit:"secret"`
 
 	// EncryptedOTPSecretIV is a legacy field from Devise. Not used.
 	// TODO: Delete this.
@@ -144,12 +145,12 @@ type User struct {
 
 	// OTPBackupCodes is a list of backup codes the user can use to
 	// log if they can't get in via SMS or Authy.
-	OTPBackupCodes []string `json:"-" form:"-" pg:"otp_backup_codes,array"`
+	OTPBackupCodes []string `json:"-" form:"-" pg:"otp_backup_codes,array" audit:"secret"`
 
 	// AuthyID is the user's Authy ID. We need this to send them push
 	// messages to complete one-touch sign-in. This will be empty for
 	// those who don't use Authy.
-	AuthyID string `json:"-" form:"-" pg:"authy_id"`
+	AuthyID string `json:"-" form:"-" pg:"authy_id" audit:"secret"`
 
 	// LastSignInWithAuthy is the timestamp of this user's last
 	// successful sign-in with Authy.
@@ -0,0 +1,153 @@
+package pgmodels
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/stretchr/stew/slice"
+)
+
+const (
+	ErrWebhookInstID     = "Webhook requires a valid institution id."
+	ErrWebhookURL        = "URL must be a valid http or https URL."
+	ErrWebhookEventTypes = "Please choose at least one event."
+	ErrWebhookSecret     = "Webhook requires a signing secret."
+)
+
+// Webhook is an endpoint to which we send HMAC-signed JSON notifications
+// when things happen at an institution. Each webhook subscribes to one
+// or more of the event types in constants.WebhookEventTypes.
+//
+// We sign each delivery with the webhook's secret. Recipients should
+// compute HMAC-SHA256(secret, timestamp + "." + body), where timestamp
+// is the value of the X-APTrust-Timestamp header, and compare the hex
+// digest to the value after "sha256=" in the X-APTrust-Signature header.
+type Webhook struct {
+	TimestampModel
+	AuditedModel
+	InstitutionID int64        `json:"institution_id" pg:"institution_id"`
+	URL           string       `json:"url" pg:"url"`
+	Description   string       `json:"description" pg:"description"`
+	Secret        string       `json:"-" form:"-" pg:"secret" audit:"secret"`
+	EventTypes    []string     `json:"event_types" pg:"event_types,array"`
+	Enabled       bool         `json:"enabled" pg:"enabled,use_zero"`
+	Institution   *Institution `json:"-" pg:"rel:has-one"`
+}
+
+// NewWebhook returns a new, unsaved, enabled webhook with a random
+// signing secret.
+func NewWebhook(institutionID int64) *Webhook {
+	return &Webhook{
+		InstitutionID: institutionID,
+		Secret:        common.RandomToken() + common.RandomToken(),
+		EventTypes:    make([]string, 0),
+		Enabled:       true,
+	}
+}
+
+// WebhookByID returns the webhook with the specified id.
+// Returns pg.ErrNoRows if there is no match.
+func WebhookByID(id int64) (*Webhook, error) {
+	query := NewQuery().Where("id", "=", id)
+	return WebhookGet(query)
+}
+
+// WebhookGet returns the first webhook matching the query.
+func WebhookGet(query *Query) (*Webhook, error) {
+	var hook Webhook
+	err := query.Select(&hook)
+	return &hook, err
+}
+
+// WebhookSelect returns all webhooks matching the query.
+func WebhookSelect(query *Query) ([]*Webhook, error) {
+	var hooks []*Webhook
+	err := query.Select(&hooks)
+	return hooks, err
+}
+
+// WebhooksSubscribedTo returns the enabled webhooks at the specified
+// institution that subscribe to the specified event type.
+func WebhooksSubscribedTo(institutionID int64, eventType string) ([]*Webhook, error) {
+	var hooks []*Webhook
+	err := common.Context().DB.Model(&hooks).
+		Where("institution_id = ?", institutionID).
+		Where("enabled = true").
+		Where("? = any(event_types)", eventType).
+		Select()
+	return hooks, err
+}
+
+// Save saves this webhook to the database. This will peform an insert
+// if Webhook.ID is zero. Otherwise, it updates.
+func (hook *Webhook) Save() error {
+	hook.SetTimestamps()
+	hook.URL = strings.TrimSpace(hook.URL)
+	err := hook.Validate()
+	if err != nil {
+		return err
+	}
+	if hook.ID == int64(0) {
+		return insert(hook)
+	}
+	return update(hook)
+}
+
+// Delete deletes this webhook. The database deletes the webhook's
+// delivery records along with it.
+func (hook *Webhook) Delete() error {
+	return hardDelete(hook)
+}
+
+// Validate returns errors if this webhook is not valid.
+func (hook *Webhook) Validate() *common.ValidationError {
+	errors := make(map[string]string)
+	if hook.InstitutionID < 1 {
+		errors["InstitutionID"] = ErrWebhookInstID
+	}
+	if !isWebhookURL(hook.URL) {
+		errors["URL"] = ErrWebhookURL
+	}
+	if len(hook.EventTypes) == 0 {
+		errors["EventTypes"] = ErrWebhookEventTypes
+	}
+	for _, eventType := range hook.EventTypes {
+		if !slice.Contains(constants.WebhookEventTypes, eventType) {
+			errors["EventTypes"] = ErrWebhookEventTypes
+		}
+	}
+	if len(hook.Secret) < 32 {
+		errors["Secret"] = ErrWebhookSecret
+	}
+	if len(errors) > 0 {
+		return &common.ValidationError{Errors: errors}
+	}
+	return nil
+}
+
+// SubscribesTo returns true if this webhook wants to receive
+// events of the specified type.
+func (hook *Webhook) SubscribesTo(eventType string) bool {
+	return slice.Contains(hook.EventTypes, eventType)
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 signature of timestamp
+// and payload, using this webhook's secret.
+func (hook *Webhook) Sign(timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(hook.Secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func isWebhookURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return u.Scheme == "http" || u.Scheme == "https"
+}
@@ -0,0 +1,346 @@
+package pgmodels
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/google/uuid"
+	"github.com/stretchr/stew/slice"
+)
+
+// WebhookMaxAttempts is the number of times we'll try to deliver
+// an event before giving up on it.
+const WebhookMaxAttempts = 8
+
+// webhookMaxResponseBody is the maximum number of bytes of the
+// recipient's response that we read before closing the connection.
+// We don't keep the body. See WebhookDelivery.post.
+const webhookMaxResponseBody = 2048
+
+// webhookLease is how long a process may spend trying to deliver
+// a batch of claimed events before another process can claim them.
+const webhookLease = 5 * time.Minute
+
+// webhookHTTPClient refuses to connect to addresses that webhooks may
+// not use (see webhookDialControl), and it doesn't follow redirects, so
+// a public host can't redirect us to one of those addresses. We check
+// the address at dial time, after DNS resolution, so a hostname that
+// resolves to a public address at validation time and a private one
+// later doesn't get around the check. We don't use a proxy, because
+// then we'd be checking the proxy's address instead of the webhook's.
+var webhookHTTPClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: webhookDialControl,
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// WebhookEvent is the JSON body of each webhook delivery.
+type WebhookEvent struct {
+	ID            string      `json:"id"`
+	Type          string      `json:"type"`
+	InstitutionID int64       `json:"institution_id"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Data          interface{} `json:"data"`
+}
+
+// WebhookDelivery records our attempts to deliver one event to one
+// webhook. Pending deliveries are retried with exponential backoff
+// until they succeed or until we've tried WebhookMaxAttempts times.
+type WebhookDelivery struct {
+	TimestampModel
+	WebhookID     int64     `json:"webhook_id" pg:"webhook_id"`
+	InstitutionID int64     `json:"institution_id" pg:"institution_id"`
+	EventID       string    `json:"event_id" pg:"event_id"`
+	EventType     string    `json:"event_type" pg:"event_type"`
+	Payload       string    `json:"payload" pg:"payload"`
+	Status        string    `json:"status" pg:"status"`
+	Attempts      int       `json:"attempts" pg:"attempts,use_zero"`
+	NextAttemptAt time.Time `json:"next_attempt_at" pg:"next_attempt_at"`
+	LastAttemptAt time.Time `json:"last_attempt_at" pg:"last_attempt_at"`
+	ResponseCode  int       `json:"response_code" pg:"response_code"`
+	Error         string    `json:"error" pg:"error"`
+	Webhook       *Webhook  `json:"-" pg:"rel:has-one"`
+}
+
+// WebhookDeliveryByID returns the delivery with the specified id.
+// Returns pg.ErrNoRows if there is no match.
+func WebhookDeliveryByID(id int64) (*WebhookDelivery, error) {
+	query := NewQuery().Where("id", "=", id)
+	return WebhookDeliveryGet(query)
+}
+
+// WebhookDeliveryGet returns the first delivery matching the query.
+func WebhookDeliveryGet(query *Query) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	err := query.Select(&delivery)
+	return &delivery, err
+}
+
+// WebhookDeliverySelect returns all deliveries matching the query.
+func WebhookDeliverySelect(query *Query) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	err := query.Select(&deliveries)
+	return deliveries, err
+}
+
+// QueueWebhookEvent creates a pending delivery of the specified event
+// for each enabled webhook at the institution that subscribes to
+// eventType. Param data becomes the "data" property of the JSON body.
+//
+// This does not send anything. The webhook cron job picks up pending
+// deliveries and sends them.
+func QueueWebhookEvent(institutionID int64, eventType string, data interface{}) ([]*WebhookDelivery, error) {
+	hooks, err := WebhooksSubscribedTo(institutionID, eventType)
+	if err != nil || len(hooks) == 0 {
+		return nil, err
+	}
+	payload, eventID, err := webhookPayload(institutionID, eventType, data)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]*WebhookDelivery, len(hooks))
+	for i, hook := range hooks {
+		deliveries[i] = newWebhookDelivery(hook, eventID, eventType, payload)
+		err = deliveries[i].Save()
+		if err != nil {
+			return deliveries, err
+		}
+	}
+	return deliveries, nil
+}
+
+// SendWebhookTestEvent sends a test event to the webhook right away,
+// whether or not the webhook is enabled, and returns a record of the
+// delivery. Check the returned delivery's status and error to see
+// whether it succeeded. If the first attempt fails, the cron job will
+// retry it like any other delivery.
+func SendWebhookTestEvent(hook *Webhook) (*WebhookDelivery, error) {
+	data := map[string]interface{}{
+		"webhook_id": hook.ID,
+		"message":    "This is a test event from APTrust Registry.",
+	}
+	payload, eventID, err := webhookPayload(hook.InstitutionID, constants.WebhookEventTest, data)
+	if err != nil {
+		return nil, err
+	}
+	delivery := newWebhookDelivery(hook, eventID, constants.WebhookEventTest, payload)
+	err = delivery.Save()
+	if err != nil {
+		return nil, err
+	}
+	delivery.Webhook = hook
+	err = delivery.Attempt()
+	return delivery, err
+}
+
+// WebhookDeliveriesClaimDue returns up to limit pending deliveries
+// that are due to be sent. It pushes their next attempt time a few
+// minutes into the future so that other Registry instances running
+// the same cron job won't send them too.
+func WebhookDeliveriesClaimDue(limit int) ([]*WebhookDelivery, error) {
+	now := time.Now().UTC()
+	var deliveries []*WebhookDelivery
+	sql := `update webhook_deliveries set next_attempt_at = ?, updated_at = ?
+	        where id in (
+	          select id from webhook_deliveries
+	          where status = ? and next_attempt_at <= ?
+	          order by next_attempt_at
+	          limit ?
+	          for update skip locked)
+	        returning *`
+	_, err := common.Context().DB.Query(&deliveries, sql, now.Add(webhookLease), now, constants.WebhookStatusPending, now, limit)
+	return deliveries, err
+}
+
+// Save saves this delivery to the database. This will peform an insert
+// if WebhookDelivery.ID is zero. Otherwise, it updates.
+func (d *WebhookDelivery) Save() error {
+	d.SetTimestamps()
+	err := d.Validate()
+	if err != nil {
+		return err
+	}
+	if d.ID == int64(0) {
+		return insert(d)
+	}
+	return update(d)
+}
+
+// Validate returns errors if this delivery is not valid.
+func (d *WebhookDelivery) Validate() *common.ValidationError {
+	errors := make(map[string]string)
+	if d.WebhookID < 1 {
+		errors["WebhookID"] = "WebhookID is required."
+	}
+	if d.InstitutionID < 1 {
+		errors["InstitutionID"] = ErrWebhookInstID
+	}
+	if common.IsEmptyString(d.EventID) {
+		errors["EventID"] = "EventID is required."
+	}
+	if common.IsEmptyString(d.Payload) {
+		errors["Payload"] = "Payload is required."
+	}
+	if !slice.Contains(constants.WebhookStatuses, d.Status) {
+		errors["Status"] = "Status is missing or invalid."
+	}
+	if len(errors) > 0 {
+		return &common.ValidationError{Errors: errors}
+	}
+	return nil
+}
+
+// Attempt tries to send this delivery to its webhook, records
+// the outcome, and saves this record. A non-2xx response or a
+// network error counts as a failed attempt. The returned error
+// describes problems saving the record, not failure to deliver.
+func (d *WebhookDelivery) Attempt() error {
+	hook := d.Webhook
+	if hook == nil {
+		var err error
+		hook, err = WebhookByID(d.WebhookID)
+		if err != nil {
+			return err
+		}
+	}
+	now := time.Now().UTC()
+	d.Attempts++
+	d.LastAttemptAt = now
+	d.ResponseCode = 0
+	d.Error = ""
+
+	if !hook.Enabled && d.EventType != constants.WebhookEventTest {
+		d.Status = constants.WebhookStatusFailed
+		d.NextAttemptAt = time.Time{}
+		d.Error = "Webhook is disabled."
+		return d.Save()
+	}
+
+	d.ResponseCode, d.Error = d.post(hook, now)
+	if d.Error == "" && d.ResponseCode >= 200 && d.ResponseCode < 300 {
+		d.Status = constants.WebhookStatusSucceeded
+		d.NextAttemptAt = time.Time{}
+	} else if d.Attempts >= WebhookMaxAttempts {
+		d.Status = constants.WebhookStatusFailed
+		d.NextAttemptAt = time.Time{}
+	} else {
+		d.Status = constants.WebhookStatusPending
+		d.NextAttemptAt = now.Add(WebhookBackoff(d.Attempts))
+	}
+	return d.Save()
+}
+
+// post sends the signed payload to the webhook's URL and returns the
+// response code and an error message if the request could not be
+// completed. We don't keep the response body. Institution admins can
+// see their deliveries, and we don't want webhooks to become a way to
+// read responses from hosts they couldn't otherwise reach.
+func (d *WebhookDelivery) post(hook *Webhook, now time.Time) (int, string) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, strings.NewReader(d.Payload))
+	if err != nil {
+		return 0, err.Error()
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "APTrust-Registry-Webhook")
+	req.Header.Set(constants.WebhookDeliveryHeader, d.EventID)
+	req.Header.Set(constants.WebhookEventHeader, d.EventType)
+	req.Header.Set(constants.WebhookTimestampHeader, timestamp)
+	req.Header.Set(constants.WebhookSignatureHeader, "sha256="+hook.Sign(timestamp, d.Payload))
+	resp, err := webhookHTTPClient.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, webhookMaxResponseBody))
+	return resp.StatusCode, ""
+}
+
+// webhookDialControl refuses connections to loopback, private,
+// link-local, multicast and unspecified addresses, unless the config
+// allows them. The net package calls this with the resolved IP address
+// just before it connects.
+func webhookDialControl(network, address string, conn syscall.RawConn) error {
+	if common.Context().Config.Webhooks.AllowPrivateAddresses {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !WebhookAddressAllowed(net.ParseIP(host)) {
+		return fmt.Errorf("webhooks may not connect to address %s", host)
+	}
+	return nil
+}
+
+// WebhookAddressAllowed returns true if webhooks may connect to ip.
+// Webhooks may not connect to loopback, private, link-local, multicast
+// or unspecified addresses.
+func WebhookAddressAllowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	return !(ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified())
+}
+
+// WebhookBackoff returns how long to wait before the next delivery
+// attempt after the specified number of failed attempts. The wait
+// doubles with each attempt, starting at one minute.
+func WebhookBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return time.Minute * time.Duration(1<<uint(attempts-1))
+}
+
+func newWebhookDelivery(hook *Webhook, eventID, eventType, payload string) *WebhookDelivery {
+	return &WebhookDelivery{
+		WebhookID:     hook.ID,
+		InstitutionID: hook.InstitutionID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        constants.WebhookStatusPending,
+		NextAttemptAt: time.Now().UTC(),
+	}
+}
+
+func webhookPayload(institutionID int64, eventType string, data interface{}) (string, string, error) {
+	event := &WebhookEvent{
+		ID:            uuid.New().String(),
+		Type:          eventType,
+		InstitutionID: institutionID,
+		CreatedAt:     time.Now().UTC(),
+		Data:          data,
+	}
+	jsonBytes, err := json.Marshal(event)
+	if err != nil {
+		return "", "", err
+	}
+	return string(jsonBytes), event.ID, nil
+}
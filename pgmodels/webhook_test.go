@@ -0,0 +1,212 @@
+package pgmodels_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookValidate(t *testing.T) {
+	hook := &pgmodels.Webhook{
+		URL:        "ftp://example.com/hook",
+		EventTypes: []string{"not.an.event"},
+	}
+	err := hook.Validate()
+	require.NotNil(t, err)
+	assert.Equal(t, pgmodels.ErrWebhookInstID, err.Errors["InstitutionID"])
+	assert.Equal(t, pgmodels.ErrWebhookURL, err.Errors["URL"])
+	assert.Equal(t, pgmodels.ErrWebhookEventTypes, err.Errors["EventTypes"])
+	assert.Equal(t, pgmodels.ErrWebhookSecret, err.Errors["Secret"])
+
+	hook = pgmodels.NewWebhook(4)
+	hook.URL = "https://example.com/hook"
+	assert.NotNil(t, hook.Validate())
+	hook.EventTypes = []string{constants.WebhookEventFixityFailed}
+	assert.Nil(t, hook.Validate())
+	assert.True(t, hook.SubscribesTo(constants.WebhookEventFixityFailed))
+	assert.False(t, hook.SubscribesTo(constants.WebhookEventDeletionCompleted))
+}
+
+func TestWebhookSign(t *testing.T) {
+	hook := &pgmodels.Webhook{Secret: "secret"}
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000.{}"))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), hook.Sign("1700000000", "{}"))
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, time.Minute, pgmodels.WebhookBackoff(1))
+	assert.Equal(t, 2*time.Minute, pgmodels.WebhookBackoff(2))
+	assert.Equal(t, 64*time.Minute, pgmodels.WebhookBackoff(7))
+}
+
+func TestWebhookAddressAllowed(t *testing.T) {
+	denied := []string{
+		"127.0.0.1",
+		"::1",
+		"10.1.2.3",
+		"172.16.0.1",
+		"192.168.1.1",
+		"169.254.169.254",
+		"fe80::1",
+		"fd00::1",
+		"224.0.0.1",
+		"ff02::1",
+		"0.0.0.0",
+		"::",
+		"::ffff:127.0.0.1",
+	}
+	for _, addr := range denied {
+		assert.False(t, pgmodels.WebhookAddressAllowed(net.ParseIP(addr)), addr)
+	}
+	assert.False(t, pgmodels.WebhookAddressAllowed(nil))
+	for _, addr := range []string{"8.8.8.8", "52.1.2.3", "2607:f8b0:4004:800::200e"} {
+		assert.True(t, pgmodels.WebhookAddressAllowed(net.ParseIP(addr)), addr)
+	}
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	db.LoadFixtures()
+
+	responseCode := http.StatusInternalServerError
+	var received *http.Request
+	var receivedBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ := io.ReadAll(r.Body)
+		receivedBody = string(body)
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		w.WriteHeader(responseCode)
+	}))
+	defer server.Close()
+
+	hook := pgmodels.NewWebhook(4)
+	hook.URL = server.URL
+	hook.EventTypes = []string{constants.WebhookEventWorkItemCompleted, constants.WebhookEventDeletionCompleted}
+	require.Nil(t, hook.Save())
+
+	// Nothing is queued for event types the hook doesn't want.
+	deliveries, err := pgmodels.QueueWebhookEvent(4, constants.WebhookEventFixityFailed, "data")
+	require.Nil(t, err)
+	assert.Empty(t, deliveries)
+
+	// Moving a WorkItem into a completed status queues events.
+	item := pgmodels.RandomWorkItem("webhook_test.tar", constants.ActionDelete, 0, 0)
+	require.Nil(t, item.Save())
+	item.Status = constants.StatusSuccess
+	require.Nil(t, item.Save())
+	query := pgmodels.NewQuery().Where("webhook_id", "=", hook.ID).OrderBy("id", "asc")
+	deliveries, err = pgmodels.WebhookDeliverySelect(query)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(deliveries))
+	assert.Equal(t, constants.WebhookEventWorkItemCompleted, deliveries[0].EventType)
+	assert.Equal(t, constants.WebhookEventDeletionCompleted, deliveries[1].EventType)
+
+	// Saving an item that's already complete doesn't queue more events.
+	require.Nil(t, item.Save())
+	count, err := query.Count(&pgmodels.WebhookDelivery{})
+	require.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	// Claimed deliveries can't be claimed again until their lease expires.
+	claimed, err := pgmodels.WebhookDeliveriesClaimDue(10)
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(claimed))
+	claimed, err = pgmodels.WebhookDeliveriesClaimDue(10)
+	require.Nil(t, err)
+	assert.Empty(t, claimed)
+
+	// Failed delivery is rescheduled.
+	delivery := deliveries[0]
+	require.Nil(t, delivery.Attempt())
+	assert.Equal(t, constants.WebhookStatusPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.ResponseCode)
+	assert.True(t, delivery.NextAttemptAt.After(time.Now().UTC()))
+
+	// Successful delivery is signed and marked as succeeded.
+	responseCode = http.StatusOK
+	require.Nil(t, delivery.Attempt())
+	assert.Equal(t, constants.WebhookStatusSucceeded, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.True(t, delivery.NextAttemptAt.IsZero())
+
+	require.NotNil(t, received)
+	assert.Equal(t, delivery.Payload, receivedBody)
+	assert.Equal(t, constants.WebhookEventWorkItemCompleted, received.Header.Get(constants.WebhookEventHeader))
+	assert.Equal(t, delivery.EventID, received.Header.Get(constants.WebhookDeliveryHeader))
+	timestamp := received.Header.Get(constants.WebhookTimestampHeader)
+	assert.Equal(t, "sha256="+hook.Sign(timestamp, receivedBody), received.Header.Get(constants.WebhookSignatureHeader))
+
+	event := &pgmodels.WebhookEvent{}
+	require.Nil(t, json.Unmarshal([]byte(receivedBody), event))
+	assert.Equal(t, constants.WebhookEventWorkItemCompleted, event.Type)
+	assert.Equal(t, int64(4), event.InstitutionID)
+
+	// We give up after too many attempts.
+	responseCode = http.StatusNotFound
+	delivery = deliveries[1]
+	delivery.Attempts = pgmodels.WebhookMaxAttempts - 1
+	require.Nil(t, delivery.Attempt())
+	assert.Equal(t, constants.WebhookStatusFailed, delivery.Status)
+
+	// Test events go out right away.
+	responseCode = http.StatusNoContent
+	testDelivery, err := pgmodels.SendWebhookTestEvent(hook)
+	require.Nil(t, err)
+	assert.Equal(t, constants.WebhookEventTest, testDelivery.EventType)
+	assert.Equal(t, constants.WebhookStatusSucceeded, testDelivery.Status)
+
+	instID, err := pgmodels.InstIDFor("WebhookDelivery", testDelivery.ID)
+	require.Nil(t, err)
+	assert.Equal(t, int64(4), instID)
+
+	// We don't follow redirects, because they could lead to addresses
+	// that webhooks can't use.
+	received = nil
+	hook.URL = server.URL + "/redirect"
+	testDelivery, err = pgmodels.SendWebhookTestEvent(hook)
+	require.Nil(t, err)
+	assert.Equal(t, constants.WebhookStatusPending, testDelivery.Status)
+	assert.Equal(t, http.StatusFound, testDelivery.ResponseCode)
+	require.NotNil(t, received)
+	assert.Equal(t, "/redirect", received.URL.Path)
+
+	// Outside of dev and test, webhooks can't reach our test server,
+	// because it's on localhost.
+	config := common.Context().Config.Webhooks
+	config.AllowPrivateAddresses = false
+	defer func() { config.AllowPrivateAddresses = true }()
+	received = nil
+	hook.URL = server.URL
+	testDelivery, err = pgmodels.SendWebhookTestEvent(hook)
+	require.Nil(t, err)
+	assert.Equal(t, constants.WebhookStatusPending, testDelivery.Status)
+	assert.Equal(t, 0, testDelivery.ResponseCode)
+	assert.Contains(t, testDelivery.Error, "webhooks may not connect to address 127.0.0.1")
+	assert.Nil(t, received)
+	config.AllowPrivateAddresses = true
+
+	// Deleting the webhook deletes its deliveries.
+	require.Nil(t, hook.Delete())
+	count, err = query.Count(&pgmodels.WebhookDelivery{})
+	require.Nil(t, err)
+	assert.Equal(t, 0, count)
+}
@@ -44,6 +44,7 @@ const (
 // audit trail.
 type WorkItem struct {
 	TimestampModel
+	AuditedModel
 	Name                 string    `json:"name" pg:"name"`
 	ETag                 string    `json:"etag" pg:"etag"`
 	InstitutionID        int64     `json:"institution_id"`
@@ -68,6 +69,26 @@ type WorkItem struct {
 	APTrustApprover      string    `json:"aptrust_approver" pg:"aptrust_approver"`
 	InstApprover         string    `json:"inst_approver"`
 	DeletionRequestID    int64     `json:"deletion_request_id"`
+
+	// OverrideTransitionCheck tells Save to allow stage and status
+	// changes that WorkItemStagesFor and WorkItemStatusTransitions
+	// would otherwise prohibit. Only admins should set this, when
+	// they need to repair an item by hand.
+	OverrideTransitionCheck bool `json:"-" pg:"-"`
+
+	// outboxTopic tells Save to add a message to the NSQ outbox in
+	// the same transaction that saves this item. SaveAndEnqueue sets
+	// it, and Save puts the new message in outboxMessage.
+	outboxTopic   string            `pg:"-"`
+	outboxMessage *NSQOutboxMessage `pg:"-"`
+
+	// requeue tells Save that SetForRequeue is sending this item back
+	// to an earlier stage. See AssertLegalTransition.
+	requeue bool `pg:"-"`
+
+	// justCompleted tells afterSave that this save moved the item
+	// into a completed status.
+	justCompleted bool `pg:"-"`
 }
 
 // WorkItemByID returns the work item with the specified id.
@@ -141,33 +162,135 @@ func (item *WorkItem) HasCompleted() bool {
 
 // Save saves this work item to the database. This will peform an insert
 // if WorkItem.ID is zero. Otherwise, it updates.
+//
+// The item's transition history and NSQ outbox message are saved in the
+// same transaction. Alerts, object versions and webhooks that follow
+// from the new state are handled after the transaction commits.
 func (item *WorkItem) Save() error {
+	registryContext := common.Context()
+	db := registryContext.DB
+	err := db.RunInTransaction(db.Context(), func(tx *pg.Tx) error {
+		return item.saveInTransaction(tx)
+	})
+	if err != nil {
+		return err
+	}
+	item.afterSave()
+	return nil
+}
+
+// saveInTransaction inserts or updates this item inside tx, after
+// locking its saved row and checking that the change is legal. It also
+// records the item's transition and adds its NSQ outbox message, if
+// it has one. Callers that run their own transaction, like
+// DeletionRequest, must call afterSave once tx commits.
+func (item *WorkItem) saveInTransaction(tx *pg.Tx) error {
 	item.SetTimestamps()
 	validationErr := item.Validate()
 	if validationErr != nil {
 		return validationErr
 	}
-	var err error
+	saved, err := item.savedState(tx)
+	if err != nil {
+		return err
+	}
+	if saved != nil {
+		err = item.assertSameAction(saved)
+		if err != nil {
+			return err
+		}
+		transitionErr := item.AssertLegalTransition(saved.Stage, saved.Status)
+		if transitionErr != nil && !item.OverrideTransitionCheck {
+			return transitionErr
+		}
+		if transitionErr != nil {
+			common.Context().Log.Warn().Msgf("Overriding transition check for WorkItem %d: %v", item.ID, transitionErr)
+		}
+	}
+	item.justCompleted = item.HasCompleted() &&
+		(saved == nil || !slice.Contains(constants.CompletedStatusValues, saved.Status))
 	if item.ID == int64(0) {
-		err = insert(item)
+		err = auditedInsert(tx, item)
 	} else {
-		err = update(item)
+		err = auditedUpdate(tx, item)
 	}
-	if err == nil && (item.Action == constants.ActionRestoreObject || item.Action == constants.ActionRestoreFile) && item.Status == constants.StatusSuccess {
-		item.AlertOnSuccessfulRestore()
+	if err == nil && item.hasTransitionedFrom(saved) {
+		err = item.recordTransition(tx)
+	}
+	if err == nil && item.outboxTopic != "" {
+		item.outboxMessage, err = insertNSQOutboxMessage(tx, item.ID, item.outboxTopic)
+	}
+	if err != nil {
+		common.Context().Log.Error().Msgf("Transaction failed. Model: %v. Error: %v", item, err)
 	}
 	return err
 }
 
-// SetForRequeue sets properies so this item can be requeued.
-// Note that it saves the object. It will return
-// constants.ErrInvalidRequeue if the stage is not valid, and
-// may return validation or pg error if the object cannot be saved.
-//
-// The call is responsible for actually pushing the WorkItem.ID into
-// the correct NSQ topic.
+// afterSave sends the alerts, object versions and webhooks that follow
+// from this item's new state. These run after the save commits, so
+// their problems are logged rather than returned.
+func (item *WorkItem) afterSave() {
+	if (item.Action == constants.ActionRestoreObject || item.Action == constants.ActionRestoreFile) && item.Status == constants.StatusSuccess {
+		item.AlertOnSuccessfulRestore()
+	}
+	if item.justCompleted && item.shouldRecordVersion() {
+		item.RecordObjectVersion()
+	}
+	if item.justCompleted {
+		item.QueueWebhookEvents()
+	}
+	item.justCompleted = false
+}
+
+// shouldRecordVersion returns true if this is a successful ingest
+// of a known object.
+func (item *WorkItem) shouldRecordVersion() bool {
+	return item.Action == constants.ActionIngest &&
+		item.Status == constants.StatusSuccess &&
+		item.IntellectualObjectID > 0
+}
+
+// RecordObjectVersion records the object's current files as a new
+// ObjectVersion. Errors are logged, not returned, because we don't
+// want version history problems to prevent us from recording the
+// item's status.
+func (item *WorkItem) RecordObjectVersion() {
+	_, err := RecordObjectVersion(item)
+	if err != nil {
+		common.Context().Log.Error().Msgf("Error recording object version for WorkItem %d: %v", item.ID, err)
+	}
+}
+
+// QueueWebhookEvents queues webhook notifications for this item's
+// institution, saying that the item has completed. Successful
+// deletions and restorations also trigger deletion.completed and
+// restoration.completed events. Errors are logged, not returned,
+// because webhook problems should not prevent us from recording
+// the item's status.
+func (item *WorkItem) QueueWebhookEvents() {
+	eventTypes := []string{constants.WebhookEventWorkItemCompleted}
+	if item.Status == constants.StatusSuccess {
+		switch item.Action {
+		case constants.ActionDelete:
+			eventTypes = append(eventTypes, constants.WebhookEventDeletionCompleted)
+		case constants.ActionRestoreObject, constants.ActionRestoreFile:
+			eventTypes = append(eventTypes, constants.WebhookEventRestorationCompleted)
+		}
+	}
+	for _, eventType := range eventTypes {
+		_, err := QueueWebhookEvent(item.InstitutionID, eventType, item)
+		if err != nil {
+			common.Context().Log.Error().Msgf("Error queueing %s webhook for WorkItem %d: %v", eventType, item.ID, err)
+		}
+	}
+}
+
+// SetForRequeue sets properies so this item can be requeued, then
+// saves it and queues it in the NSQ topic for stage. See SaveAndEnqueue.
+// It will return constants.ErrInvalidRequeue if the stage is not valid,
+// and may return validation or pg error if the object cannot be saved.
 func (item *WorkItem) SetForRequeue(stage string) error {
-	_, err := constants.TopicFor(item.Action, stage)
+	topic, err := constants.TopicFor(item.Action, stage)
 	if err != nil {
 		return err
 	}
@@ -179,7 +302,54 @@ func (item *WorkItem) SetForRequeue(stage string) error {
 	item.Outcome = ""
 	item.PID = 0
 	item.Note = fmt.Sprintf("Requeued for %s", item.Stage)
-	return item.Save()
+
+	// Requeueing is how admins revive failed and cancelled items,
+	// so it's allowed to move them out of completed statuses and
+	// back to earlier stages.
+	item.requeue = true
+	defer func() { item.requeue = false }()
+	return item.SaveAndEnqueue(topic)
+}
+
+// SaveAndEnqueue saves this item and, in the same transaction, adds a
+// message to the NSQ outbox telling the workers on topic to pick it up.
+// If the item already has a pending message for topic, this won't add
+// another. After the save, this tries to deliver the message right
+// away. If that fails, the outbox cron job retries it, so callers don't
+// have to handle NSQ errors. The returned error describes problems
+// saving the item.
+//
+// The item's QueuedAt timestamp is set when the message is delivered.
+func (item *WorkItem) SaveAndEnqueue(topic string) error {
+	item.outboxTopic = topic
+	item.outboxMessage = nil
+	err := item.Save()
+	msg := item.outboxMessage
+	item.outboxTopic = ""
+	item.outboxMessage = nil
+	if err != nil {
+		return err
+	}
+	item.deliverOutboxMessage(msg)
+	return nil
+}
+
+// deliverOutboxMessage tries to deliver msg, the outbox message saved
+// with this item, right away. Problems are logged, not returned,
+// because the outbox cron job retries undelivered messages. msg may be
+// nil if the item already had a pending message for the topic.
+func (item *WorkItem) deliverOutboxMessage(msg *NSQOutboxMessage) {
+	if msg == nil {
+		return
+	}
+	attemptErr := msg.Attempt()
+	if attemptErr != nil {
+		common.Context().Log.Error().Msgf("Error recording NSQ delivery of WorkItem %d to %s: %v", item.ID, msg.Topic, attemptErr)
+	} else if msg.Status == constants.OutboxStatusDelivered {
+		item.QueuedAt = msg.DeliveredAt
+	} else {
+		common.Context().Log.Warn().Msgf("Could not queue WorkItem %d in %s. Will retry. %s", item.ID, msg.Topic, msg.Error)
+	}
 }
 
 func (item *WorkItem) Validate() *common.ValidationError {
@@ -515,7 +685,7 @@ func NewItemFromLastSuccessfulIngest(objID int64) (*WorkItem, error) {
 }
 
 // NewRestorationItem creates and saves a new WorkItem
-// for an object or file restoration.
+// for an object or file restoration, and queues it in NSQ.
 //
 // Param obj (required) is the object to be restored.
 // gf is the GenericFile to be restored. This can be zero
@@ -555,11 +725,18 @@ func NewRestorationItem(obj *IntellectualObject, gf *GenericFile, user *User) (*
 		restorationItem.GenericFileID = gf.ID
 	}
 	restorationItem.User = user.Email
-	err = restorationItem.Save()
+	topic, err := constants.TopicFor(restorationItem.Action, restorationItem.Stage)
+	if err != nil {
+		return nil, err
+	}
+	err = restorationItem.SaveAndEnqueue(topic)
 	return restorationItem, err
 }
 
 // NewDeletionItem creates a new work item to delete a file or object.
+// This does not save or queue the item. Deletions usually include many
+// items, so callers should save all of them with the DeletionRequest
+// before queueing any, and then queue each one with SaveAndEnqueue.
 // Param obj is required. If gf is not nil, this will create a WorkItem
 // to delete file gf. Otherwise, it creates a WorkItem to delete object obj.
 //
@@ -599,6 +776,5 @@ func NewDeletionItem(obj *IntellectualObject, gf *GenericFile, requestedBy, appr
 	deletionItem.User = requestedBy.Email
 	deletionItem.InstApprover = approvedBy.Email
 	deletionItem.DeletionRequestID = deletionRequestID
-	err = deletionItem.Save()
-	return deletionItem, err
+	return deletionItem, nil
 }
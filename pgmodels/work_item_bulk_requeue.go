@@ -0,0 +1,127 @@
+package pgmodels
+
+import (
+	"fmt"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+)
+
+// MaxBulkRequeueItems is the maximum number of WorkItems we'll
+// requeue in a single bulk requeue request.
+const MaxBulkRequeueItems = 500
+
+// WorkItemBulkRequeueFilters describes the filters allowed when
+// selecting WorkItems for bulk requeue.
+var WorkItemBulkRequeueFilters = []string{
+	"action",
+	"action__in",
+	"date_processed__gteq",
+	"date_processed__lteq",
+	"institution_id",
+	"node",
+	"stage",
+	"stage__in",
+	"status",
+	"status__in",
+}
+
+// BulkRequeueItem describes what a bulk requeue will do, or did,
+// to a single WorkItem.
+type BulkRequeueItem struct {
+	WorkItemID      int64  `json:"work_item_id"`
+	Name            string `json:"name"`
+	InstitutionName string `json:"institution_name"`
+	Action          string `json:"action"`
+	Stage           string `json:"stage"`
+	Status          string `json:"status"`
+	Node            string `json:"node"`
+	TargetStage     string `json:"target_stage"`
+	Topic           string `json:"topic"`
+	Requeued        bool   `json:"requeued"`
+	Error           string `json:"error,omitempty"`
+}
+
+// BulkRequeuePreview returns a description of what will happen to each
+// WorkItem matching the filters in fc if we requeue them to targetStage.
+// If targetStage is empty, ingests go back to their current stage and
+// all other items go back to Requested. Items that can't be requeued
+// to the target stage have a non-empty Error.
+//
+// This never includes successful or cancelled items. It returns
+// common.ErrNoFilters if fc has no filters, and common.ErrTooManyItems
+// if more than MaxBulkRequeueItems items match.
+func BulkRequeuePreview(fc *FilterCollection, targetStage string) ([]*BulkRequeueItem, error) {
+	if !fc.HasFilters() {
+		return nil, common.ErrNoFilters
+	}
+	query, err := fc.ToQuery()
+	if err != nil {
+		return nil, err
+	}
+	query.WhereNotIn("status", constants.StatusSuccess, constants.StatusCancelled).
+		OrderBy("id", "asc").
+		Limit(MaxBulkRequeueItems + 1)
+	items, err := WorkItemViewSelect(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > MaxBulkRequeueItems {
+		return nil, common.ErrTooManyItems
+	}
+	preview := make([]*BulkRequeueItem, len(items))
+	for i, item := range items {
+		preview[i] = newBulkRequeueItem(item, targetStage)
+	}
+	return preview, nil
+}
+
+func newBulkRequeueItem(item *WorkItemView, targetStage string) *BulkRequeueItem {
+	if targetStage == "" {
+		targetStage = constants.StageRequested
+		if item.Action == constants.ActionIngest {
+			targetStage = item.Stage
+		}
+	}
+	requeueItem := &BulkRequeueItem{
+		WorkItemID:      item.ID,
+		Name:            item.Name,
+		InstitutionName: item.InstitutionName,
+		Action:          item.Action,
+		Stage:           item.Stage,
+		Status:          item.Status,
+		Node:            item.Node,
+		TargetStage:     targetStage,
+	}
+	topic, err := constants.TopicFor(item.Action, targetStage)
+	if err != nil {
+		requeueItem.Error = err.Error()
+		return requeueItem
+	}
+	requeueItem.Topic = topic
+	if item.Action == constants.ActionIngest && ingestStageIndex(targetStage) > ingestStageIndex(item.Stage) {
+		requeueItem.Error = fmt.Sprintf("cannot requeue to %s because item has not yet reached that stage", targetStage)
+	}
+	return requeueItem
+}
+
+// ingestStageIndex returns the position of stage in
+// constants.IngestStagesInOrder, or -1 if it's not an ingest stage.
+func ingestStageIndex(stage string) int {
+	for i, ingestStage := range constants.IngestStagesInOrder {
+		if ingestStage == stage {
+			return i
+		}
+	}
+	return -1
+}
+
+// BulkRequeueResult describes the outcome of a bulk requeue or,
+// for a dry run, what the outcome would be.
+type BulkRequeueResult struct {
+	DryRun   bool               `json:"dry_run"`
+	Matched  int                `json:"matched"`
+	Requeued int                `json:"requeued"`
+	Errors   int                `json:"errors"`
+	Items    []*BulkRequeueItem `json:"items"`
+}
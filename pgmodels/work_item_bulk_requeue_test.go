@@ -0,0 +1,62 @@
+package pgmodels_test
+
+import (
+	"testing"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkRequeuePreview(t *testing.T) {
+	db.LoadFixtures()
+
+	// No filters means no requeue.
+	_, err := pgmodels.BulkRequeuePreview(pgmodels.NewFilterCollection(), "")
+	assert.Equal(t, common.ErrNoFilters, err)
+
+	// Ingests go back to their current stage by default.
+	fc := pgmodels.NewFilterCollection()
+	fc.Add("action", []string{constants.ActionIngest})
+	fc.Add("stage", []string{constants.StageReceive})
+	items, err := pgmodels.BulkRequeuePreview(fc, "")
+	require.Nil(t, err)
+	require.NotEmpty(t, items)
+	for _, item := range items {
+		assert.Equal(t, constants.StageReceive, item.TargetStage)
+		assert.Equal(t, constants.IngestPreFetch, item.Topic)
+		assert.Empty(t, item.Error)
+		assert.False(t, item.Requeued)
+	}
+
+	// Can't requeue ingests to stages they haven't reached.
+	items, err = pgmodels.BulkRequeuePreview(fc, constants.StageStore)
+	require.Nil(t, err)
+	require.NotEmpty(t, items)
+	for _, item := range items {
+		assert.Equal(t, constants.IngestStorage, item.Topic)
+		assert.Contains(t, item.Error, "has not yet reached that stage")
+	}
+
+	// Other actions go back to Requested.
+	fc = pgmodels.NewFilterCollection()
+	fc.Add("action", []string{constants.ActionRestoreObject})
+	items, err = pgmodels.BulkRequeuePreview(fc, "")
+	require.Nil(t, err)
+	require.NotEmpty(t, items)
+	for _, item := range items {
+		assert.Equal(t, constants.StageRequested, item.TargetStage)
+		assert.Equal(t, constants.TopicObjectRestore, item.Topic)
+		assert.NotEqual(t, constants.StatusSuccess, item.Status)
+	}
+
+	// Successful items are never included.
+	fc = pgmodels.NewFilterCollection()
+	fc.Add("status", []string{constants.StatusSuccess})
+	items, err = pgmodels.BulkRequeuePreview(fc, "")
+	require.Nil(t, err)
+	assert.Empty(t, items)
+}
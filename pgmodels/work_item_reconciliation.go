@@ -0,0 +1,309 @@
+package pgmodels
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/network"
+	"github.com/stretchr/stew/slice"
+)
+
+// These describe the problems reconciliation can find.
+const (
+	// OrphanNeverQueued means the item is incomplete, was never
+	// pushed into NSQ, and has no pending NSQ outbox message, so no
+	// worker will ever pick it up.
+	OrphanNeverQueued = "Never Queued"
+
+	// OrphanNoRedisState means the item was queued, but its NSQ topic
+	// is empty and the workers have no state for it in Redis, so no
+	// worker is handling it.
+	OrphanNoRedisState = "Queued Without Redis State"
+
+	// OrphanStaleRedisState means the item completed successfully or
+	// was cancelled, but its Redis state was never cleaned up.
+	OrphanStaleRedisState = "Redis State for Completed Item"
+)
+
+// These describe how to fix an orphaned item.
+const (
+	OrphanFixRequeue     = "requeue"
+	OrphanFixRedisDelete = "redis_delete"
+)
+
+// MaxReconcileItems is the maximum number of incomplete WorkItems one
+// reconciliation run will check.
+const MaxReconcileItems = 1000
+
+// redisStateActions are the actions whose workers keep state in Redis.
+var redisStateActions = []string{
+	constants.ActionIngest,
+	constants.ActionRestoreFile,
+	constants.ActionRestoreObject,
+}
+
+// OrphanedWorkItem describes a WorkItem that NSQ and Redis show no one
+// is working on, or that left state behind in Redis. Fix describes what
+// will fix it. For requeues, TargetStage and Topic say where the item
+// will go. Fixed and Error describe the outcome after an attempted fix.
+type OrphanedWorkItem struct {
+	WorkItemID      int64     `json:"work_item_id"`
+	Name            string    `json:"name"`
+	InstitutionName string    `json:"institution_name"`
+	Action          string    `json:"action"`
+	Stage           string    `json:"stage"`
+	Status          string    `json:"status"`
+	Node            string    `json:"node"`
+	QueuedAt        time.Time `json:"queued_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Problem         string    `json:"problem"`
+	Fix             string    `json:"fix"`
+	TargetStage     string    `json:"target_stage,omitempty"`
+	Topic           string    `json:"topic,omitempty"`
+	Fixed           bool      `json:"fixed"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// WorkItemReconciliation describes the results of cross-checking
+// WorkItems against NSQ and Redis. Items updated after Cutoff were
+// skipped, because they may still be in transit. If NSQError is not
+// empty, we couldn't get NSQ stats, so we couldn't check for items
+// that were queued but lost their Redis state.
+type WorkItemReconciliation struct {
+	CheckedAt    time.Time           `json:"checked_at"`
+	Cutoff       time.Time           `json:"cutoff"`
+	ItemsChecked int                 `json:"items_checked"`
+	RedisKeys    int                 `json:"redis_keys"`
+	NSQError     string              `json:"nsq_error,omitempty"`
+	Orphans      []*OrphanedWorkItem `json:"orphans"`
+}
+
+type workItemReconciler struct {
+	cutoff time.Time
+	stats  *network.NSQStatsData
+}
+
+func newWorkItemReconciler(gracePeriod time.Duration) (*workItemReconciler, error) {
+	stats, err := common.Context().NSQClient.GetStats()
+	if err != nil {
+		common.Context().Log.Warn().Msgf("Reconciliation can't get NSQ stats: %v", err)
+	}
+	return &workItemReconciler{
+		cutoff: time.Now().UTC().Add(-gracePeriod),
+		stats:  stats,
+	}, err
+}
+
+// ReconcileWorkItems cross-checks incomplete WorkItems with NSQ topic
+// stats and Redis, and checks Redis for state belonging to completed
+// items. It ignores items updated within the last gracePeriod and
+// suspended items, which admins have stopped on purpose. It also
+// ignores Redis state for failed items, since admins may requeue those
+// from the stage where they failed.
+func ReconcileWorkItems(gracePeriod time.Duration) (*WorkItemReconciliation, error) {
+	r, nsqErr := newWorkItemReconciler(gracePeriod)
+	result := &WorkItemReconciliation{
+		CheckedAt: time.Now().UTC(),
+		Cutoff:    r.cutoff,
+		Orphans:   make([]*OrphanedWorkItem, 0),
+	}
+	if nsqErr != nil {
+		result.NSQError = nsqErr.Error()
+	}
+
+	query := NewQuery().
+		WhereIn("status", common.InterfaceList(constants.IncompleteStatusValues)...).
+		Where("updated_at", "<", r.cutoff).
+		OrderBy("id", "asc").
+		Limit(MaxReconcileItems)
+	incomplete, err := WorkItemViewSelect(query)
+	if err != nil {
+		return nil, err
+	}
+	completed, err := r.completedItemsInRedis(result)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range append(incomplete, completed...) {
+		result.ItemsChecked++
+		if orphan := r.check(item); orphan != nil {
+			result.Orphans = append(result.Orphans, orphan)
+		}
+	}
+	return result, nil
+}
+
+// ReconcileWorkItem checks a single WorkItem. It returns the item's
+// problem and fix, or common.ErrNotOrphaned if the item is fine. This
+// returns the NSQ error if it can't get NSQ stats, since without them
+// it can't tell whether the item is fine.
+func ReconcileWorkItem(workItemID int64, gracePeriod time.Duration) (*OrphanedWorkItem, error) {
+	item, err := WorkItemViewByID(workItemID)
+	if err != nil {
+		return nil, err
+	}
+	r, err := newWorkItemReconciler(gracePeriod)
+	if err != nil {
+		return nil, err
+	}
+	orphan := r.check(item)
+	if orphan == nil {
+		return nil, common.ErrNotOrphaned
+	}
+	return orphan, nil
+}
+
+// completedItemsInRedis returns the successful and cancelled items
+// that still have state in Redis.
+func (r *workItemReconciler) completedItemsInRedis(result *WorkItemReconciliation) ([]*WorkItemView, error) {
+	keys, err := common.Context().RedisClient.Keys("*")
+	if err != nil {
+		return nil, err
+	}
+	result.RedisKeys = len(keys)
+	ids := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		if id, err := strconv.ParseInt(key, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	query := NewQuery().
+		WhereIn("id", ids...).
+		WhereIn("status", constants.StatusSuccess, constants.StatusCancelled).
+		OrderBy("id", "asc")
+	return WorkItemViewSelect(query)
+}
+
+// check returns a description of item's problem, or nil if NSQ and
+// Redis show the item is being handled normally.
+func (r *workItemReconciler) check(item *WorkItemView) *OrphanedWorkItem {
+	if item.UpdatedAt.After(r.cutoff) {
+		return nil
+	}
+	redis := common.Context().RedisClient
+	if item.HasCompleted() {
+		if item.Status != constants.StatusFailed && redis.KeyExists(item.ID) {
+			return newOrphanedWorkItem(item, OrphanStaleRedisState, OrphanFixRedisDelete, "")
+		}
+		return nil
+	}
+	if item.Status == constants.StatusSuspended {
+		return nil
+	}
+	if item.QueuedAt.IsZero() {
+		// The outbox cron job may still deliver it.
+		if pending, err := NSQOutboxHasPending(item.ID); err != nil || pending {
+			return nil
+		}
+		targetStage := constants.StageRequested
+		if item.Action == constants.ActionIngest {
+			targetStage = item.Stage
+		}
+		return newOrphanedWorkItem(item, OrphanNeverQueued, OrphanFixRequeue, targetStage)
+	}
+	if r.stats == nil || !slice.Contains(redisStateActions, item.Action) {
+		return nil
+	}
+	topic, err := constants.TopicFor(item.Action, item.Stage)
+	if err != nil || !r.stats.TopicIsEmpty(topic) || redis.KeyExists(item.ID) {
+		return nil
+	}
+	// Without Redis state, workers have to start over from the beginning.
+	targetStage := constants.StageRequested
+	if item.Action == constants.ActionIngest {
+		targetStage = constants.StageReceive
+	}
+	return newOrphanedWorkItem(item, OrphanNoRedisState, OrphanFixRequeue, targetStage)
+}
+
+func newOrphanedWorkItem(item *WorkItemView, problem, fix, targetStage string) *OrphanedWorkItem {
+	orphan := &OrphanedWorkItem{
+		WorkItemID:      item.ID,
+		Name:            item.Name,
+		InstitutionName: item.InstitutionName,
+		Action:          item.Action,
+		Stage:           item.Stage,
+		Status:          item.Status,
+		Node:            item.Node,
+		QueuedAt:        item.QueuedAt,
+		UpdatedAt:       item.UpdatedAt,
+		Problem:         problem,
+		Fix:             fix,
+		TargetStage:     targetStage,
+	}
+	if fix == OrphanFixRequeue {
+		topic, err := constants.TopicFor(item.Action, targetStage)
+		if err != nil {
+			orphan.Error = err.Error()
+		}
+		orphan.Topic = topic
+	}
+	return orphan
+}
+
+// CreateOrphanedWorkItemsAlert reconciles WorkItems and creates one
+// alert telling APTrust admins about orphans they haven't yet heard
+// about. Each item in the alert links to the reconciliation page, where
+// admins can fix it. Param registryURL is the scheme and host to use in
+// those links. This returns nil and no error if there's nothing new.
+func CreateOrphanedWorkItemsAlert(config *common.ReconciliationConfig, registryURL string) (*Alert, error) {
+	result, err := ReconcileWorkItems(config.GracePeriod)
+	if err != nil || len(result.Orphans) == 0 {
+		return nil, err
+	}
+	ids := make([]int64, len(result.Orphans))
+	for i, orphan := range result.Orphans {
+		ids[i] = orphan.WorkItemID
+	}
+	lastAlerted, err := workItemsLastAlerted(constants.AlertOrphanedItems, ids)
+	if err != nil {
+		return nil, err
+	}
+	var orphans []*OrphanedWorkItem
+	var itemIDs []interface{}
+	for _, orphan := range result.Orphans {
+		alertedAt, ok := lastAlerted[orphan.WorkItemID]
+		if ok && alertedAt.After(orphan.UpdatedAt) {
+			continue
+		}
+		orphans = append(orphans, orphan)
+		itemIDs = append(itemIDs, orphan.WorkItemID)
+	}
+	if len(orphans) == 0 {
+		return nil, nil
+	}
+	items, err := WorkItemSelect(NewQuery().WhereIn("id", itemIDs...))
+	if err != nil {
+		return nil, err
+	}
+	aptrust, err := InstitutionByIdentifier("aptrust.org")
+	if err != nil {
+		return nil, err
+	}
+	adminsQuery := NewQuery().
+		Where("role", "=", constants.RoleSysAdmin).
+		IsNull("deactivated_at")
+	admins, err := UserSelect(adminsQuery)
+	if err != nil {
+		return nil, err
+	}
+	alertData := map[string]interface{}{
+		"ItemCount":    len(orphans),
+		"Items":        orphans,
+		"ReconcileURL": fmt.Sprintf("%s/work_items/reconcile", registryURL),
+	}
+	alert := &Alert{
+		InstitutionID: aptrust.ID,
+		Type:          constants.AlertOrphanedItems,
+		Subject:       fmt.Sprintf("%d Orphaned Work Items", len(orphans)),
+		Users:         admins,
+		WorkItems:     items,
+	}
+	return CreateAlert(alert, "alerts/orphaned_items.txt", alertData)
+}
@@ -0,0 +1,144 @@
+package pgmodels_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func neverQueuedWorkItem(t *testing.T, name, action, stage string) *pgmodels.WorkItem {
+	item := pgmodels.RandomWorkItem(name, action, 0, 0)
+	item.Stage = stage
+	item.QueuedAt = time.Time{}
+	require.Nil(t, item.Save())
+	return item
+}
+
+func TestReconcileWorkItem(t *testing.T) {
+	db.ForceFixtureReload()
+	defer db.ForceFixtureReload()
+	redis := common.Context().RedisClient
+
+	ingest := neverQueuedWorkItem(t, "unqueued.tar", constants.ActionIngest, constants.StageValidate)
+	orphan, err := pgmodels.ReconcileWorkItem(ingest.ID, 0)
+	require.Nil(t, err)
+	require.NotNil(t, orphan)
+	assert.Equal(t, pgmodels.OrphanNeverQueued, orphan.Problem)
+	assert.Equal(t, pgmodels.OrphanFixRequeue, orphan.Fix)
+	assert.Equal(t, constants.StageValidate, orphan.TargetStage)
+	assert.Equal(t, constants.IngestValidation, orphan.Topic)
+	assert.Empty(t, orphan.Error)
+
+	// Items updated within the grace period may still be in transit.
+	_, err = pgmodels.ReconcileWorkItem(ingest.ID, time.Hour)
+	assert.Equal(t, common.ErrNotOrphaned, err)
+
+	// Items waiting in the NSQ outbox will be queued soon.
+	msg := &pgmodels.NSQOutboxMessage{
+		WorkItemID:    ingest.ID,
+		Topic:         constants.IngestValidation,
+		Status:        constants.OutboxStatusPending,
+		NextAttemptAt: time.Now().UTC().Add(time.Hour),
+	}
+	require.Nil(t, msg.Save())
+	_, err = pgmodels.ReconcileWorkItem(ingest.ID, 0)
+	assert.Equal(t, common.ErrNotOrphaned, err)
+
+	// Restorations go back to Requested.
+	restore := neverQueuedWorkItem(t, "unqueued_restore.tar", constants.ActionRestoreObject, constants.StageRequested)
+	orphan, err = pgmodels.ReconcileWorkItem(restore.ID, 0)
+	require.Nil(t, err)
+	assert.Equal(t, constants.StageRequested, orphan.TargetStage)
+	assert.Equal(t, constants.TopicObjectRestore, orphan.Topic)
+
+	// Admins suspend items on purpose.
+	restore.Status = constants.StatusSuspended
+	require.Nil(t, restore.Save())
+	_, err = pgmodels.ReconcileWorkItem(restore.ID, 0)
+	assert.Equal(t, common.ErrNotOrphaned, err)
+
+	// Completed items are fine, unless they left state in Redis.
+	_, err = redis.WorkItemDelete(30)
+	require.Nil(t, err)
+	_, err = pgmodels.ReconcileWorkItem(30, 0)
+	assert.Equal(t, common.ErrNotOrphaned, err)
+
+	require.Nil(t, redis.SaveItem(30, "object:test", `{"key":"value"}`))
+	defer redis.WorkItemDelete(30)
+	orphan, err = pgmodels.ReconcileWorkItem(30, 0)
+	require.Nil(t, err)
+	assert.Equal(t, pgmodels.OrphanStaleRedisState, orphan.Problem)
+	assert.Equal(t, pgmodels.OrphanFixRedisDelete, orphan.Fix)
+	assert.Empty(t, orphan.Topic)
+
+	_, err = pgmodels.ReconcileWorkItem(999999, 0)
+	assert.NotNil(t, err)
+}
+
+func TestReconcileWorkItems(t *testing.T) {
+	db.ForceFixtureReload()
+	defer db.ForceFixtureReload()
+	redis := common.Context().RedisClient
+
+	item := neverQueuedWorkItem(t, "unqueued.tar", constants.ActionIngest, constants.StageReceive)
+	require.Nil(t, redis.SaveItem(31, "object:test", `{"key":"value"}`))
+	defer redis.WorkItemDelete(31)
+
+	result, err := pgmodels.ReconcileWorkItems(0)
+	require.Nil(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.ItemsChecked > 0)
+	assert.True(t, result.RedisKeys > 0)
+	assert.False(t, result.Cutoff.After(result.CheckedAt))
+
+	problems := make(map[int64]string)
+	for _, orphan := range result.Orphans {
+		problems[orphan.WorkItemID] = orphan.Problem
+	}
+	assert.Equal(t, pgmodels.OrphanNeverQueued, problems[item.ID])
+	assert.Equal(t, pgmodels.OrphanStaleRedisState, problems[31])
+
+	// A long grace period skips everything.
+	result, err = pgmodels.ReconcileWorkItems(100000 * time.Hour)
+	require.Nil(t, err)
+	assert.Empty(t, result.Orphans)
+}
+
+func TestCreateOrphanedWorkItemsAlert(t *testing.T) {
+	db.ForceFixtureReload()
+	defer db.ForceFixtureReload()
+	config := &common.ReconciliationConfig{
+		Enabled:     true,
+		GracePeriod: 0,
+	}
+
+	item := neverQueuedWorkItem(t, "unqueued.tar", constants.ActionIngest, constants.StageReceive)
+	alert, err := pgmodels.CreateOrphanedWorkItemsAlert(config, "https://example.com")
+	require.Nil(t, err)
+	require.NotNil(t, alert)
+	assert.Equal(t, constants.AlertOrphanedItems, alert.Type)
+	require.NotEmpty(t, alert.Users)
+	for _, user := range alert.Users {
+		assert.Equal(t, constants.RoleSysAdmin, user.Role)
+	}
+	ids := make([]int64, len(alert.WorkItems))
+	for i, workItem := range alert.WorkItems {
+		ids[i] = workItem.ID
+	}
+	assert.Contains(t, ids, item.ID)
+	assert.Contains(t, alert.Content, "unqueued.tar")
+	assert.Contains(t, alert.Content, pgmodels.OrphanNeverQueued)
+	assert.Contains(t, alert.Content, "https://example.com/work_items/reconcile")
+
+	// We've already told admins about these, so we
+	// shouldn't alert them again.
+	alert, err = pgmodels.CreateOrphanedWorkItemsAlert(config, "https://example.com")
+	require.Nil(t, err)
+	assert.Nil(t, alert)
+}
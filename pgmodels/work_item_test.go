@@ -314,6 +314,7 @@ func TestNewItemFromLastSuccessfulIngest(t *testing.T) {
 	item, err := pgmodels.WorkItemByID(24)
 	require.Nil(t, err)
 	item.Status = constants.StatusPending
+	item.OverrideTransitionCheck = true
 	item.Save()
 
 	// Then try to create a new WorkItem. Even though there are no successful ingest WorkItems,
@@ -356,10 +357,16 @@ func TestNewRestorationItem(t *testing.T) {
 	assert.Empty(t, item.PID)
 	assert.True(t, item.Retry)
 	assert.False(t, item.NeedsAdminReview)
-	assert.Empty(t, item.QueuedAt)
 	assert.Equal(t, constants.StageRequested, item.Stage)
 	assert.Equal(t, constants.StatusPending, item.Status)
 
+	// The item is queued through the NSQ outbox.
+	assert.False(t, item.QueuedAt.IsZero())
+	msg, err := pgmodels.NSQOutboxMessageGet(pgmodels.NewQuery().Where("work_item_id", "=", item.ID))
+	require.Nil(t, err)
+	assert.Equal(t, constants.TopicObjectRestore, msg.Topic)
+	assert.Equal(t, constants.OutboxStatusDelivered, msg.Status)
+
 	// File restoration
 	item, err = pgmodels.NewRestorationItem(obj, file, user)
 	require.Nil(t, err)
@@ -438,6 +445,10 @@ func TestNewDeletionItem(t *testing.T) {
 	assert.Empty(t, item1.GenericFileID)
 	assert.Equal(t, deletionRequest.ID, item1.DeletionRequestID)
 
+	// Callers save and queue deletion items themselves.
+	assert.Equal(t, int64(0), item1.ID)
+	assert.True(t, item1.QueuedAt.IsZero())
+
 	query2 := pgmodels.NewQuery().
 		Where("intellectual_object_id", "=", obj.ID).
 		Where("state", "=", constants.StateActive).
@@ -0,0 +1,262 @@
+package pgmodels
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/stew/slice"
+)
+
+// AssertLegalTransition returns a *common.IllegalTransitionError if
+// this item may not move from fromStage and fromStatus to its current
+// stage and status. See constants.WorkItemStagesFor and
+// constants.WorkItemStatusTransitions for the rules. Saving an item
+// without changing its stage or status is always legal.
+//
+// Items normally move only forward through their action's stages.
+// SetForRequeue is the one way back: it may send an item to any of its
+// action's stages, from any status, as long as the item is pending.
+func (item *WorkItem) AssertLegalTransition(fromStage, fromStatus string) error {
+	if item.Stage == fromStage && item.Status == fromStatus {
+		return nil
+	}
+	reason := ""
+	if item.requeue {
+		reason = item.requeueProblem()
+	} else {
+		reason = item.transitionProblem(fromStage, fromStatus)
+	}
+	if reason == "" {
+		return nil
+	}
+	return &common.IllegalTransitionError{
+		Action:     item.Action,
+		FromStage:  fromStage,
+		FromStatus: fromStatus,
+		ToStage:    item.Stage,
+		ToStatus:   item.Status,
+		Reason:     reason,
+	}
+}
+
+// transitionProblem describes why this item may not move from
+// fromStage and fromStatus to its current stage and status in the
+// normal course of processing. It returns an empty string if the
+// move is legal.
+func (item *WorkItem) transitionProblem(fromStage, fromStatus string) string {
+	stages := constants.WorkItemStagesFor[item.Action]
+	if !slice.Contains(constants.WorkItemStatusTransitions[fromStatus], item.Status) {
+		return fmt.Sprintf("%s items cannot move to %s", fromStatus, item.Status)
+	}
+	if item.Stage == fromStage {
+		return ""
+	}
+	if slice.Contains(constants.CompletedStatusValues, fromStatus) {
+		return fmt.Sprintf("%s items cannot change stage", fromStatus)
+	}
+	if !slice.Contains(stages, item.Stage) {
+		return fmt.Sprintf("stage %s does not belong to action %s", item.Stage, item.Action)
+	}
+	// Legacy items may be in a stage that isn't listed for their
+	// action. Let those move into any listed stage.
+	fromIndex := stageIndex(stages, fromStage)
+	if fromIndex >= 0 && stageIndex(stages, item.Stage) < fromIndex {
+		return fmt.Sprintf("stage %s comes before %s, so the item must be requeued", item.Stage, fromStage)
+	}
+	return ""
+}
+
+// requeueProblem describes why this item can't be requeued into its
+// current stage and status. It returns an empty string if it can.
+func (item *WorkItem) requeueProblem() string {
+	if item.Status != constants.StatusPending {
+		return fmt.Sprintf("requeued items must be %s", constants.StatusPending)
+	}
+	if !slice.Contains(constants.WorkItemStagesFor[item.Action], item.Stage) {
+		return fmt.Sprintf("stage %s does not belong to action %s", item.Stage, item.Action)
+	}
+	return ""
+}
+
+// stageIndex returns the position of stage in stages, or -1 if
+// stages doesn't include it.
+func stageIndex(stages []string, stage string) int {
+	for i, s := range stages {
+		if s == stage {
+			return i
+		}
+	}
+	return -1
+}
+
+// assertSameAction returns a *common.IllegalTransitionError if this
+// item's action differs from the saved item's action. An item's action
+// never changes. Create a new item instead.
+func (item *WorkItem) assertSameAction(saved *WorkItem) error {
+	if item.Action == saved.Action {
+		return nil
+	}
+	return &common.IllegalTransitionError{
+		Action:     item.Action,
+		FromStage:  saved.Stage,
+		FromStatus: saved.Status,
+		ToStage:    item.Stage,
+		ToStatus:   item.Status,
+		Reason:     fmt.Sprintf("action cannot change from %s", saved.Action),
+	}
+}
+
+// savedState returns the action, stage, status and node of the saved
+// version of this item. It returns nil if the item hasn't been saved.
+// This locks the item's row until tx ends, so no one else can change
+// its state between our check and our update.
+func (item *WorkItem) savedState(tx *pg.Tx) (*WorkItem, error) {
+	if item.ID == 0 {
+		return nil, nil
+	}
+	saved := &WorkItem{}
+	err := tx.Model(saved).
+		Column("action", "stage", "status", "node").
+		Where("id = ?", item.ID).
+		For("UPDATE").
+		Select()
+	if IsNoRowError(err) {
+		return nil, nil
+	}
+	return saved, err
+}
+
+// hasTransitionedFrom returns true if this item's stage, status or
+// node differ from saved. A nil saved means this is a new item, so
+// that counts as a transition too.
+func (item *WorkItem) hasTransitionedFrom(saved *WorkItem) bool {
+	return saved == nil ||
+		item.Stage != saved.Stage ||
+		item.Status != saved.Status ||
+		item.Node != saved.Node
+}
+
+// recordTransition records this item's current stage, status and
+// node in its transition history, in the transaction that saves
+// the item.
+func (item *WorkItem) recordTransition(tx *pg.Tx) error {
+	transition := &WorkItemTransition{
+		WorkItemID:    item.ID,
+		InstitutionID: item.InstitutionID,
+		Action:        item.Action,
+		Stage:         item.Stage,
+		Status:        item.Status,
+		Node:          item.Node,
+		PID:           item.PID,
+		Note:          item.Note,
+		CreatedAt:     item.UpdatedAt,
+	}
+	_, err := tx.Model(transition).Insert()
+	return err
+}
+
+// WorkItemTransition records a WorkItem's stage, status and node at
+// the time one of them changed. The item stayed in this state until
+// the CreatedAt of its next transition. Transitions are never updated.
+type WorkItemTransition struct {
+	ID            int64     `json:"id"`
+	WorkItemID    int64     `json:"work_item_id"`
+	InstitutionID int64     `json:"institution_id"`
+	Action        string    `json:"action"`
+	Stage         string    `json:"stage"`
+	Status        string    `json:"status"`
+	Node          string    `json:"node"`
+	PID           int       `json:"pid" pg:",use_zero"`
+	Note          string    `json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// WorkItemTransitions returns the transition history of the WorkItem
+// with the specified id, oldest first.
+func WorkItemTransitions(workItemID int64) ([]*WorkItemTransition, error) {
+	var transitions []*WorkItemTransition
+	err := NewQuery().
+		Where("work_item_id", "=", workItemID).
+		OrderBy("created_at", "asc").
+		OrderBy("id", "asc").
+		Select(&transitions)
+	return transitions, err
+}
+
+// WorkItemStageDuration describes one visit of a WorkItem to a stage.
+// An item that's requeued to an earlier stage visits that stage again,
+// and each visit gets its own WorkItemStageDuration. Attempts is the
+// number of times a worker started the stage during the visit, so
+// anything more than one means the stage was retried.
+type WorkItemStageDuration struct {
+	Stage           string        `json:"stage"`
+	StartedAt       time.Time     `json:"started_at"`
+	Duration        time.Duration `json:"-"`
+	DurationSeconds int64         `json:"duration_seconds"`
+	Attempts        int           `json:"attempts"`
+	InProgress      bool          `json:"in_progress"`
+}
+
+// WorkItemStageDurations returns the time spent in each stage, based on
+// the transitions, which must be sorted oldest first. If the last
+// transition is not a completed status, the last stage is still in
+// progress, and its duration runs until now.
+func WorkItemStageDurations(transitions []*WorkItemTransition, now time.Time) []*WorkItemStageDuration {
+	durations := make([]*WorkItemStageDuration, 0)
+	var current *WorkItemStageDuration
+	for _, t := range transitions {
+		if current == nil || current.Stage != t.Stage {
+			if current != nil {
+				current.setDuration(t.CreatedAt)
+			}
+			current = &WorkItemStageDuration{
+				Stage:     t.Stage,
+				StartedAt: t.CreatedAt,
+			}
+			durations = append(durations, current)
+		}
+		if t.Status == constants.StatusStarted {
+			current.Attempts++
+		}
+	}
+	if current != nil {
+		last := transitions[len(transitions)-1]
+		if slice.Contains(constants.CompletedStatusValues, last.Status) {
+			current.setDuration(last.CreatedAt)
+		} else {
+			current.InProgress = true
+			current.setDuration(now)
+		}
+	}
+	return durations
+}
+
+func (d *WorkItemStageDuration) setDuration(endedAt time.Time) {
+	d.Duration = endedAt.Sub(d.StartedAt)
+	d.DurationSeconds = int64(d.Duration.Seconds())
+}
+
+// WorkItemHistory describes the transitions of a single WorkItem
+// and how long it spent in each stage.
+type WorkItemHistory struct {
+	WorkItemID     int64                    `json:"work_item_id"`
+	Transitions    []*WorkItemTransition    `json:"transitions"`
+	StageDurations []*WorkItemStageDuration `json:"stage_durations"`
+}
+
+// WorkItemHistoryFor returns the history of the WorkItem with the
+// specified id.
+func WorkItemHistoryFor(workItemID int64) (*WorkItemHistory, error) {
+	transitions, err := WorkItemTransitions(workItemID)
+	if err != nil {
+		return nil, err
+	}
+	return &WorkItemHistory{
+		WorkItemID:     workItemID,
+		Transitions:    transitions,
+		StageDurations: WorkItemStageDurations(transitions, time.Now().UTC()),
+	}, nil
+}
@@ -0,0 +1,245 @@
+package pgmodels_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkItemAssertLegalTransition(t *testing.T) {
+	item := &pgmodels.WorkItem{
+		Action: constants.ActionIngest,
+		Stage:  constants.StageValidate,
+		Status: constants.StatusStarted,
+	}
+
+	// Legal transitions
+	assert.Nil(t, item.AssertLegalTransition(constants.StageValidate, constants.StatusStarted))
+	assert.Nil(t, item.AssertLegalTransition(constants.StageReceive, constants.StatusPending))
+	assert.Nil(t, item.AssertLegalTransition(constants.StageReceive, constants.StatusSuspended))
+
+	// Completed items can't be restarted.
+	err := item.AssertLegalTransition(constants.StageValidate, constants.StatusSuccess)
+	require.NotNil(t, err)
+	transitionErr, ok := err.(*common.IllegalTransitionError)
+	require.True(t, ok)
+	assert.Equal(t, constants.StatusSuccess, transitionErr.FromStatus)
+	assert.Equal(t, constants.StatusStarted, transitionErr.ToStatus)
+	assert.Equal(t, "illegal Ingest transition from Validate/Success to Validate/Started: Success items cannot move to Started", err.Error())
+
+	// Completed items can't change stage.
+	item.Stage = constants.StageCleanup
+	item.Status = constants.StatusFailed
+	err = item.AssertLegalTransition(constants.StageRecord, constants.StatusFailed)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Failed items cannot change stage")
+
+	// Ingests can't move into restoration stages.
+	item.Stage = constants.StageAvailableInS3
+	item.Status = constants.StatusStarted
+	err = item.AssertLegalTransition(constants.StageRecord, constants.StatusPending)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "stage Available in S3 does not belong to action Ingest")
+
+	// But restorations can.
+	item.Action = constants.ActionRestoreObject
+	assert.Nil(t, item.AssertLegalTransition(constants.StageRestoring, constants.StatusStarted))
+
+	// Items can skip stages going forward, but they can't go back
+	// without a requeue.
+	item.Action = constants.ActionIngest
+	item.Stage = constants.StageStore
+	item.Status = constants.StatusPending
+	assert.Nil(t, item.AssertLegalTransition(constants.StageValidate, constants.StatusStarted))
+	item.Stage = constants.StageReceive
+	err = item.AssertLegalTransition(constants.StageStore, constants.StatusStarted)
+	require.NotNil(t, err)
+	assert.Equal(t, "illegal Ingest transition from Store/Started to Receive/Pending: stage Receive comes before Store, so the item must be requeued", err.Error())
+}
+
+func TestWorkItemSaveEnforcesTransitions(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	item := pgmodels.RandomWorkItem("transition.tar", constants.ActionDelete, 0, 0)
+	require.Nil(t, item.Save())
+
+	item.Stage = constants.StageResolve
+	item.Status = constants.StatusStarted
+	require.Nil(t, item.Save())
+
+	item.Status = constants.StatusSuccess
+	require.Nil(t, item.Save())
+
+	// Can't go from Success back to Started.
+	item.Status = constants.StatusStarted
+	err := item.Save()
+	require.NotNil(t, err)
+	_, ok := err.(*common.IllegalTransitionError)
+	assert.True(t, ok)
+
+	saved, err := pgmodels.WorkItemByID(item.ID)
+	require.Nil(t, err)
+	assert.Equal(t, constants.StatusSuccess, saved.Status)
+
+	// Admins can override the check.
+	item.OverrideTransitionCheck = true
+	require.Nil(t, item.Save())
+	saved, err = pgmodels.WorkItemByID(item.ID)
+	require.Nil(t, err)
+	assert.Equal(t, constants.StatusStarted, saved.Status)
+
+	// Requeueing may move items out of completed statuses
+	// and back to earlier stages.
+	saved.Status = constants.StatusFailed
+	require.Nil(t, saved.Save())
+	require.Nil(t, saved.SetForRequeue(constants.StageRequested))
+	assert.Equal(t, constants.StatusPending, saved.Status)
+
+	// But not by a plain save.
+	saved.Stage = constants.StageResolve
+	require.Nil(t, saved.Save())
+	saved.Stage = constants.StageRequested
+	err = saved.Save()
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "stage Requested comes before Resolve")
+
+	// An item's action never changes, even when admins override
+	// the transition check.
+	saved.Stage = constants.StageResolve
+	saved.Action = constants.ActionRestoreObject
+	saved.OverrideTransitionCheck = true
+	err = saved.Save()
+	require.NotNil(t, err)
+	_, ok = err.(*common.IllegalTransitionError)
+	assert.True(t, ok)
+	assert.Contains(t, err.Error(), "action cannot change from Delete")
+	saved, err = pgmodels.WorkItemByID(item.ID)
+	require.Nil(t, err)
+	assert.Equal(t, constants.ActionDelete, saved.Action)
+}
+
+func TestWorkItemTransitions(t *testing.T) {
+	db.LoadFixtures()
+	transitions, err := pgmodels.WorkItemTransitions(26)
+	require.Nil(t, err)
+	require.Equal(t, 12, len(transitions))
+	assert.Equal(t, constants.StageReceive, transitions[0].Stage)
+	assert.Equal(t, constants.StatusPending, transitions[0].Status)
+	assert.Equal(t, constants.StageCleanup, transitions[11].Stage)
+	assert.Equal(t, constants.StatusSuccess, transitions[11].Status)
+	for i := 1; i < len(transitions); i++ {
+		assert.False(t, transitions[i].CreatedAt.Before(transitions[i-1].CreatedAt))
+	}
+
+	transitions, err = pgmodels.WorkItemTransitions(999999)
+	require.Nil(t, err)
+	assert.Empty(t, transitions)
+}
+
+func TestWorkItemStageDurations(t *testing.T) {
+	start := time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC)
+	transitions := []*pgmodels.WorkItemTransition{
+		{Stage: constants.StageReceive, Status: constants.StatusPending, CreatedAt: start},
+		{Stage: constants.StageReceive, Status: constants.StatusStarted, CreatedAt: start.Add(1 * time.Minute)},
+		{Stage: constants.StageValidate, Status: constants.StatusStarted, CreatedAt: start.Add(10 * time.Minute)},
+		{Stage: constants.StageValidate, Status: constants.StatusPending, CreatedAt: start.Add(20 * time.Minute)},
+		{Stage: constants.StageValidate, Status: constants.StatusStarted, CreatedAt: start.Add(30 * time.Minute)},
+		{Stage: constants.StageStore, Status: constants.StatusPending, CreatedAt: start.Add(60 * time.Minute)},
+	}
+	now := start.Add(90 * time.Minute)
+
+	durations := pgmodels.WorkItemStageDurations(transitions, now)
+	require.Equal(t, 3, len(durations))
+
+	assert.Equal(t, constants.StageReceive, durations[0].Stage)
+	assert.Equal(t, start, durations[0].StartedAt)
+	assert.Equal(t, 10*time.Minute, durations[0].Duration)
+	assert.EqualValues(t, 600, durations[0].DurationSeconds)
+	assert.Equal(t, 1, durations[0].Attempts)
+	assert.False(t, durations[0].InProgress)
+
+	assert.Equal(t, constants.StageValidate, durations[1].Stage)
+	assert.Equal(t, 50*time.Minute, durations[1].Duration)
+	assert.Equal(t, 2, durations[1].Attempts)
+	assert.False(t, durations[1].InProgress)
+
+	// Last stage is still running
+	assert.Equal(t, constants.StageStore, durations[2].Stage)
+	assert.Equal(t, 30*time.Minute, durations[2].Duration)
+	assert.Equal(t, 0, durations[2].Attempts)
+	assert.True(t, durations[2].InProgress)
+
+	// Completed items stop the clock at their final transition
+	transitions = append(transitions, &pgmodels.WorkItemTransition{
+		Stage:     constants.StageStore,
+		Status:    constants.StatusFailed,
+		CreatedAt: start.Add(70 * time.Minute),
+	})
+	durations = pgmodels.WorkItemStageDurations(transitions, now)
+	require.Equal(t, 3, len(durations))
+	assert.Equal(t, 10*time.Minute, durations[2].Duration)
+	assert.False(t, durations[2].InProgress)
+
+	assert.Empty(t, pgmodels.WorkItemStageDurations(nil, now))
+}
+
+func TestWorkItemSaveRecordsTransitions(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+
+	item := pgmodels.RandomWorkItem("history.tar", constants.ActionIngest, 0, 0)
+	item.Stage = constants.StageReceive
+	require.Nil(t, item.Save())
+
+	transitions, err := pgmodels.WorkItemTransitions(item.ID)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(transitions))
+	assert.Equal(t, item.InstitutionID, transitions[0].InstitutionID)
+	assert.Equal(t, constants.ActionIngest, transitions[0].Action)
+	assert.Equal(t, constants.StageReceive, transitions[0].Stage)
+	assert.Equal(t, constants.StatusPending, transitions[0].Status)
+
+	// Saving without a change to stage, status or node
+	// should not add to the history.
+	item.Note = "Nothing to see here"
+	require.Nil(t, item.Save())
+	transitions, err = pgmodels.WorkItemTransitions(item.ID)
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(transitions))
+
+	item.Status = constants.StatusStarted
+	item.Node = "history-worker"
+	item.PID = 4321
+	require.Nil(t, item.Save())
+
+	item.Stage = constants.StageValidate
+	item.Status = constants.StatusPending
+	item.Node = ""
+	item.PID = 0
+	require.Nil(t, item.Save())
+
+	transitions, err = pgmodels.WorkItemTransitions(item.ID)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(transitions))
+	assert.Equal(t, constants.StatusStarted, transitions[1].Status)
+	assert.Equal(t, "history-worker", transitions[1].Node)
+	assert.Equal(t, 4321, transitions[1].PID)
+	assert.Equal(t, constants.StageValidate, transitions[2].Stage)
+	assert.Equal(t, constants.StatusPending, transitions[2].Status)
+
+	history, err := pgmodels.WorkItemHistoryFor(item.ID)
+	require.Nil(t, err)
+	assert.Equal(t, item.ID, history.WorkItemID)
+	assert.Equal(t, 3, len(history.Transitions))
+	require.Equal(t, 2, len(history.StageDurations))
+	assert.Equal(t, constants.StageReceive, history.StageDurations[0].Stage)
+	assert.Equal(t, 1, history.StageDurations[0].Attempts)
+	assert.True(t, history.StageDurations[1].InProgress)
+}
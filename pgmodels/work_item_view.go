@@ -32,6 +32,7 @@ var WorkItemFilters = []string{
 	"intellectual_object_id__is_null",
 	"name",
 	"needs_admin_review",
+	"node",
 	"node__is_null",
 	"node__not_null",
 	"object_identifier",
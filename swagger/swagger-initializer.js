@@ -1,9 +1,19 @@
 window.onload = function() {
   //<editor-fold desc="Changeable Configuration Block">
 
+  // When the Registry serves this page at /swagger/, load the spec it
+  // generates from its own routes. Add ?api=admin to the URL to see the
+  // admin API. Elsewhere (e.g. GitHub pages), fall back to the member
+  // API spec in the repo.
+  var specUrl = "https://raw.githubusercontent.com/APTrust/registry/master/member_api_v3.yml";
+  if (window.location.pathname.indexOf("/swagger") === 0) {
+    var api = new URLSearchParams(window.location.search).get("api");
+    specUrl = api === "admin" ? "/admin-api/v3/openapi.json" : "/member-api/v3/openapi.json";
+  }
+
   // the following lines will be replaced by docker/configurator, when it runs in a docker-container
   window.ui = SwaggerUIBundle({
-    url: "https://raw.githubusercontent.com/APTrust/registry/master/member_api_v3.yml",
+    url: specUrl,
     dom_id: '#swagger-ui',
     deepLinking: true,
     presets: [
@@ -1,8 +1,6 @@
 package admin_api
 
 import (
-	"net/http"
-
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
 	"github.com/gin-gonic/gin"
@@ -18,5 +16,5 @@ func DeletionRequestShow(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, deletionRequest.ToMin())
+	api.ConditionalJSON(c, deletionRequest.ToMin())
 }
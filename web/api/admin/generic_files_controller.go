@@ -6,6 +6,7 @@ import (
 
 	"github.com/APTrust/registry/common"
 	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/helpers"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
 	"github.com/gin-gonic/gin"
@@ -25,7 +26,7 @@ func GenericFileIndex(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, api.NewJsonList(files, pager))
+	api.ConditionalJSON(c, api.NewJsonList(files, pager))
 }
 
 // GenericFileDelete marks a generic file record as deleted.
@@ -40,6 +41,9 @@ func GenericFileDelete(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
+	if api.AbortIfError(c, pgmodels.AuditChangesBy(gf, helpers.AuditActor(c))) {
+		return
+	}
 	err = gf.Delete()
 	if api.AbortIfError(c, err) {
 		return
@@ -47,6 +51,30 @@ func GenericFileDelete(c *gin.Context) {
 	c.JSON(http.StatusOK, gf)
 }
 
+// GenericFileUndelete reverts the soft-delete of a generic file
+// record that a failed deletion run marked as deleted. It also creates
+// a compensating premis event. This returns a 409 if the file isn't
+// deleted, if its object is deleted, or if it has no storage records.
+// See the GenericFile model for more info.
+//
+// PUT /admin-api/v3/files/undelete/:id
+func GenericFileUndelete(c *gin.Context) {
+	req := api.NewRequest(c)
+	gf, err := pgmodels.GenericFileByID(req.Auth.ResourceID)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	if api.AbortIfError(c, pgmodels.AuditChangesBy(gf, helpers.AuditActor(c))) {
+		return
+	}
+	err = gf.Undelete(req.CurrentUser)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	common.Context().Log.Info().Msgf("User %s undeleted file %d (%s)", req.CurrentUser.Email, gf.ID, gf.Identifier)
+	c.JSON(http.StatusOK, gf)
+}
+
 // GenericFileCreate creates a new GenericFile.
 //
 // TODO: Change institution_id to object_id?
@@ -104,7 +132,11 @@ func GenericFileUpdate(c *gin.Context) {
 
 func CreateOrUpdateFile(c *gin.Context) (*pgmodels.GenericFile, error) {
 	req := api.NewRequest(c)
-	gf, err := GenericFileFromJson(req)
+	gf, existing, err := GenericFileFromJson(req)
+	if err != nil {
+		return nil, err
+	}
+	err = pgmodels.AuditChangesFrom(existing, gf, helpers.AuditActor(c))
 	if err != nil {
 		return nil, err
 	}
@@ -117,20 +149,21 @@ func CreateOrUpdateFile(c *gin.Context) (*pgmodels.GenericFile, error) {
 // the database (if there is one). It returns an error if the JSON
 // can't be parsed, if the existing file can't be found, or if
 // changes made to the existing object are not allowed.
-func GenericFileFromJson(req *api.Request) (*pgmodels.GenericFile, error) {
+func GenericFileFromJson(req *api.Request) (*pgmodels.GenericFile, *pgmodels.GenericFile, error) {
 	submittedFile := &pgmodels.GenericFile{}
+	var existingFile *pgmodels.GenericFile
 	err := req.GinContext.BindJSON(submittedFile)
 	if err != nil {
-		return submittedFile, err
+		return submittedFile, nil, err
 	}
 	err = req.AssertValidIDs(submittedFile.ID, submittedFile.InstitutionID)
 	if err != nil {
-		return submittedFile, err
+		return submittedFile, nil, err
 	}
 	if req.Auth.ResourceID > 0 {
-		existingFile, err := pgmodels.GenericFileByID(req.Auth.ResourceID)
+		existingFile, err = pgmodels.GenericFileByID(req.Auth.ResourceID)
 		if err != nil {
-			return submittedFile, err
+			return submittedFile, nil, err
 		}
 		CoerceFileStorageOption(existingFile, submittedFile)
 
@@ -139,7 +172,7 @@ func GenericFileFromJson(req *api.Request) (*pgmodels.GenericFile, error) {
 
 		err = existingFile.ValidateChanges(submittedFile)
 	}
-	return submittedFile, err
+	return submittedFile, existingFile, err
 }
 
 // CoerceFileStorageOption forces submittedFile.StorageOption to match
@@ -189,6 +189,7 @@ func testFileCreate(t *testing.T) *pgmodels.GenericFile {
 
 func testFileUpdate(t *testing.T, gf *pgmodels.GenericFile) *pgmodels.GenericFile {
 	origUpdatedAt := gf.UpdatedAt
+	origFileFormat := gf.FileFormat
 	copyOfGf := gf
 	copyOfGf.Size = gf.Size + 200
 	copyOfGf.FileFormat = "txt/screed"
@@ -208,6 +209,19 @@ func testFileUpdate(t *testing.T, gf *pgmodels.GenericFile) *pgmodels.GenericFil
 	assert.InDelta(t, gf.CreatedAt.Unix(), updatedGf.CreatedAt.Unix(), 1)
 	assert.True(t, updatedGf.UpdatedAt.After(origUpdatedAt))
 
+	// The update should be in the audit log, attributed to the
+	// API user.
+	auditQuery := pgmodels.NewQuery().
+		Where("model_type", "=", "GenericFile").
+		Where("model_id", "=", gf.ID).
+		OrderBy("id", "desc")
+	entry, err := pgmodels.AuditLogGet(auditQuery)
+	require.Nil(t, err)
+	assert.Equal(t, constants.AuditActionUpdate, entry.Action)
+	assert.Equal(t, tu.SysAdmin.Email, entry.ActorEmail)
+	assert.Equal(t, origFileFormat, entry.Before["file_format"])
+	assert.Equal(t, copyOfGf.FileFormat, entry.After["file_format"])
+
 	return updatedGf
 }
 
@@ -254,6 +268,42 @@ func testFileDelete(t *testing.T, gf *pgmodels.GenericFile) {
 	require.True(t, event.DateTime.After(time.Now().UTC().Add(-5*time.Second)))
 }
 
+func TestGenericFileUndelete(t *testing.T) {
+	defer db.ForceFixtureReload()
+	tu.InitHTTPTests(t)
+
+	// Non sys-admins can't undelete.
+	tu.Inst1AdminClient.PUT("/admin-api/v3/files/undelete/{id}", 10).
+		Expect().Status(http.StatusForbidden)
+
+	// File 10 is deleted, but still has storage records.
+	resp := tu.SysAdminClient.PUT("/admin-api/v3/files/undelete/{id}", 10).
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		Expect().Status(http.StatusOK)
+	gf := &pgmodels.GenericFile{}
+	err := json.Unmarshal([]byte(resp.Body().Raw()), gf)
+	require.Nil(t, err)
+	assert.Equal(t, int64(10), gf.ID)
+	assert.Equal(t, constants.StateActive, gf.State)
+
+	event, err := gf.LastDeletionEvent()
+	require.Nil(t, err)
+	assert.Equal(t, constants.OutcomeFailure, event.Outcome)
+
+	// Now it's active, so there's nothing to undelete.
+	tu.SysAdminClient.PUT("/admin-api/v3/files/undelete/{id}", 10).
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		Expect().Status(http.StatusConflict)
+
+	// File 62 belongs to a deleted object.
+	tu.SysAdminClient.PUT("/admin-api/v3/files/undelete/{id}", 62).
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		Expect().Status(http.StatusConflict)
+}
+
 // POST /admin-api/v3/files/create_batch/:institution_id
 func TestGenericFileCreateBatch(t *testing.T) {
 	defer db.ForceFixtureReload()
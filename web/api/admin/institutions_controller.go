@@ -1,8 +1,6 @@
 package admin_api
 
 import (
-	"net/http"
-
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
 	"github.com/gin-gonic/gin"
@@ -18,7 +16,7 @@ func InstitutionIndex(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, api.NewJsonList(institutions, pager))
+	api.ConditionalJSON(c, api.NewJsonList(institutions, pager))
 }
 
 // InstitutionShow returns the institution with the specified id.
@@ -30,5 +28,5 @@ func InstitutionShow(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, inst)
+	api.ConditionalJSON(c, inst)
 }
@@ -116,6 +116,10 @@ func TestObjectBatchDelete(t *testing.T) {
 	// with a pending WorkItem.
 	testObjectBatchDeleteWithPendingWorkItem(t, paramsBadPendingWorkItem)
 
+	// Ensure that we get failure if one of the objects
+	// is under a legal hold.
+	testObjectBatchDeleteWithLegalHold(t, validParams)
+
 	// Ensure that we get failure if we include an object
 	// that belongs to another institution.
 	testObjectBatchDeleteWithOtherInstItem(t, paramsBadOtherInstItem)
@@ -261,6 +265,25 @@ func testObjectBatchDeleteWithPendingWorkItem(t *testing.T, params admin_api.Obj
 	assert.Equal(t, `{"StatusCode":409,"Error":"task cannot be completed because this object has pending work items"}`, resp.Body().Raw())
 }
 
+func testObjectBatchDeleteWithLegalHold(t *testing.T, params admin_api.ObjectBatchDeleteParams) {
+	hold := &pgmodels.LegalHold{
+		InstitutionID:        params.InstitutionID,
+		IntellectualObjectID: params.ObjectIDs[0],
+		Reason:               "Batch deletion test",
+		PlacedByID:           params.RequestorID,
+	}
+	require.Nil(t, hold.Save())
+	defer hold.Release(tu.Inst2Admin)
+
+	resp := tu.SysAdminClient.POST("/admin-api/v3/objects/init_batch_delete").
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		WithJSON(params).
+		Expect()
+	resp.Status(http.StatusConflict)
+	assert.Equal(t, `{"StatusCode":409,"Error":"this object is under a legal hold and cannot be deleted"}`, resp.Body().Raw())
+}
+
 func testObjectBatchDeleteWithOtherInstItem(t *testing.T, params admin_api.ObjectBatchDeleteParams) {
 	resp := tu.SysAdminClient.POST("/admin-api/v3/objects/init_batch_delete").
 		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
@@ -9,6 +9,7 @@ import (
 
 	"github.com/APTrust/registry/common"
 	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/helpers"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
 	"github.com/APTrust/registry/web/webui"
@@ -140,7 +141,7 @@ func IntellectualObjectInitBatchDelete(c *gin.Context) {
 	// certain internal checks fail. E.g. RequestorID does not belong
 	// to an inst admin, one or more files in the batch belongs to another
 	// institution, or has already been deleted.
-	del, err := webui.NewDeletionForObjectBatch(params.RequestorID, params.InstitutionID, params.ObjectIDs, req.BaseURL())
+	del, err := webui.NewDeletionForObjectBatch(params.RequestorID, params.InstitutionID, params.ObjectIDs, req.BaseURL(), helpers.AuditActor(c))
 	if api.AbortIfError(c, err) {
 		common.Context().Log.Error().Msgf("IntellectualObjectInitBatchDelete: Creating batch deletion failed: %v", err)
 		return
@@ -173,6 +174,9 @@ func IntellectualObjectDelete(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
+	if api.AbortIfError(c, pgmodels.AuditChangesBy(obj, helpers.AuditActor(c))) {
+		return
+	}
 	err = obj.Delete()
 	if api.AbortIfError(c, err) {
 		return
@@ -180,9 +184,38 @@ func IntellectualObjectDelete(c *gin.Context) {
 	c.JSON(http.StatusOK, obj)
 }
 
+// IntellectualObjectUndelete reverts the soft-delete of an object
+// record that a failed deletion run marked as deleted. It also
+// undeletes the object's deleted files that still have storage records
+// and creates compensating premis events. This returns a 409 if the
+// object isn't deleted or if none of its files have storage records.
+// See the IntellectualObject model for more info.
+//
+// PUT /admin-api/v3/objects/undelete/:id
+func IntellectualObjectUndelete(c *gin.Context) {
+	req := api.NewRequest(c)
+	obj, err := pgmodels.IntellectualObjectByID(req.Auth.ResourceID)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	if api.AbortIfError(c, pgmodels.AuditChangesBy(obj, helpers.AuditActor(c))) {
+		return
+	}
+	err = obj.Undelete(req.CurrentUser)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	common.Context().Log.Info().Msgf("User %s undeleted object %d (%s)", req.CurrentUser.Email, obj.ID, obj.Identifier)
+	c.JSON(http.StatusOK, obj)
+}
+
 func CreateOrUpdateObject(c *gin.Context) (*pgmodels.IntellectualObject, error) {
 	req := api.NewRequest(c)
-	obj, err := IntellectualObjectFromJson(req)
+	obj, existing, err := IntellectualObjectFromJson(req)
+	if err != nil {
+		return nil, err
+	}
+	err = pgmodels.AuditChangesFrom(existing, obj, helpers.AuditActor(c))
 	if err != nil {
 		return nil, err
 	}
@@ -198,20 +231,21 @@ func CreateOrUpdateObject(c *gin.Context) (*pgmodels.IntellectualObject, error)
 // the database (if there is one). It returns an error if the JSON
 // can't be parsed, if the existing object can't be found, or if
 // changes made to the existing object are not allowed.
-func IntellectualObjectFromJson(req *api.Request) (*pgmodels.IntellectualObject, error) {
+func IntellectualObjectFromJson(req *api.Request) (*pgmodels.IntellectualObject, *pgmodels.IntellectualObject, error) {
 	submittedObject := &pgmodels.IntellectualObject{}
+	var existingObject *pgmodels.IntellectualObject
 	err := req.GinContext.BindJSON(submittedObject)
 	if err != nil {
-		return submittedObject, err
+		return submittedObject, nil, err
 	}
 	err = req.AssertValidIDs(submittedObject.ID, submittedObject.InstitutionID)
 	if err != nil {
-		return submittedObject, err
+		return submittedObject, nil, err
 	}
 	if req.Auth.ResourceID > 0 {
-		existingObject, err := pgmodels.IntellectualObjectByID(req.Auth.ResourceID)
+		existingObject, err = pgmodels.IntellectualObjectByID(req.Auth.ResourceID)
 		if err != nil {
-			return submittedObject, err
+			return submittedObject, nil, err
 		}
 		CoerceObjectStorageOption(existingObject, submittedObject)
 
@@ -221,7 +255,7 @@ func IntellectualObjectFromJson(req *api.Request) (*pgmodels.IntellectualObject,
 
 		err = existingObject.ValidateChanges(submittedObject)
 	}
-	return submittedObject, err
+	return submittedObject, existingObject, err
 }
 
 // CoerceObjectStorageOption forces submittedObject.StorageOption to match
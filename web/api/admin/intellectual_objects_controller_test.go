@@ -153,6 +153,7 @@ func testObjectCreate(t *testing.T) *pgmodels.IntellectualObject {
 
 func testObjectUpdate(t *testing.T, obj *pgmodels.IntellectualObject) *pgmodels.IntellectualObject {
 	origUpdatedAt := obj.UpdatedAt
+	origTitle := obj.Title
 	copyOfObj := obj
 	copyOfObj.Access = constants.AccessConsortia
 	copyOfObj.Title = "Updated Title"
@@ -174,6 +175,19 @@ func testObjectUpdate(t *testing.T, obj *pgmodels.IntellectualObject) *pgmodels.
 	assert.InDelta(t, obj.CreatedAt.Unix(), updatedObj.CreatedAt.Unix(), 1)
 	assert.True(t, updatedObj.UpdatedAt.After(origUpdatedAt))
 
+	// The update should be in the audit log, attributed to the
+	// API user.
+	auditQuery := pgmodels.NewQuery().
+		Where("model_type", "=", "IntellectualObject").
+		Where("model_id", "=", obj.ID).
+		OrderBy("id", "desc")
+	entry, err := pgmodels.AuditLogGet(auditQuery)
+	require.Nil(t, err)
+	assert.Equal(t, constants.AuditActionUpdate, entry.Action)
+	assert.Equal(t, tu.SysAdmin.Email, entry.ActorEmail)
+	assert.Equal(t, origTitle, entry.Before["title"])
+	assert.Equal(t, copyOfObj.Title, entry.After["title"])
+
 	return updatedObj
 }
 
@@ -203,6 +217,48 @@ func testObjectDelete(t *testing.T, obj *pgmodels.IntellectualObject) {
 	require.True(t, event.DateTime.After(time.Now().UTC().Add(-5*time.Second)))
 }
 
+func TestObjectUndelete(t *testing.T) {
+	defer db.ForceFixtureReload()
+	tu.InitHTTPTests(t)
+
+	// Non sys-admins can't undelete.
+	tu.Inst2AdminClient.PUT("/admin-api/v3/objects/undelete/{id}", 14).
+		Expect().Status(http.StatusForbidden)
+
+	// Object 1 is active, so there's nothing to undelete.
+	tu.SysAdminClient.PUT("/admin-api/v3/objects/undelete/{id}", 1).
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		Expect().Status(http.StatusConflict)
+
+	// Object 14 is deleted, but its files have no storage records.
+	tu.SysAdminClient.PUT("/admin-api/v3/objects/undelete/{id}", 14).
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		Expect().Status(http.StatusConflict)
+
+	// Simulate a failed deletion that left object 5's files in place.
+	ctx := common.Context()
+	_, err := ctx.DB.Model((*pgmodels.IntellectualObject)(nil)).Set("state = ?", constants.StateDeleted).Where("id = ?", 5).Update()
+	require.Nil(t, err)
+	_, err = ctx.DB.Model((*pgmodels.GenericFile)(nil)).Set("state = ?", constants.StateDeleted).Where("intellectual_object_id = ?", 5).Update()
+	require.Nil(t, err)
+
+	resp := tu.SysAdminClient.PUT("/admin-api/v3/objects/undelete/{id}", 5).
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		Expect().Status(http.StatusOK)
+	obj := &pgmodels.IntellectualObject{}
+	err = json.Unmarshal([]byte(resp.Body().Raw()), obj)
+	require.Nil(t, err)
+	assert.Equal(t, int64(5), obj.ID)
+	assert.Equal(t, constants.StateActive, obj.State)
+
+	hasActiveFiles, err := obj.HasActiveFiles()
+	require.Nil(t, err)
+	assert.True(t, hasActiveFiles)
+}
+
 func TestObjectCreateUnauthorized(t *testing.T) {
 	tu.InitHTTPTests(t)
 
@@ -0,0 +1,27 @@
+package admin_api
+
+import (
+	"net/http"
+
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/api"
+	"github.com/APTrust/registry/web/webui"
+	"github.com/gin-gonic/gin"
+)
+
+// PipelineReportShow returns WorkItem throughput and latency stats by
+// action, stage and node. See webui.GetPipelineReportParams for the
+// query params and their defaults.
+//
+// GET /admin-api/v3/reports/pipeline
+func PipelineReportShow(c *gin.Context) {
+	params, err := webui.GetPipelineReportParams(c)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	stats, err := pgmodels.PipelineStatsSelect(params.InstitutionID, params.StartDate, params.EndDate)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
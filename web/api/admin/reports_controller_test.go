@@ -0,0 +1,42 @@
+package admin_api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/pgmodels"
+	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineReportShow(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	resp := tu.SysAdminClient.GET("/admin-api/v3/reports/pipeline").
+		WithQuery("start_date", "2016-08-01").
+		WithQuery("end_date", "2016-08-31").
+		WithQuery("institution_id", 2).
+		Expect().Status(http.StatusOK)
+	stats := &pgmodels.PipelineStats{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), stats))
+	assert.EqualValues(t, 2, stats.InstitutionID)
+	assert.Equal(t, "2016-08-01", stats.StartDate.Format("2006-01-02"))
+	assert.Equal(t, "2016-09-01", stats.EndDate.Format("2006-01-02"))
+	require.NotEmpty(t, stats.Actions)
+	actions := make([]string, len(stats.Actions))
+	for i, a := range stats.Actions {
+		actions[i] = a.Action
+	}
+	assert.Contains(t, actions, constants.ActionIngest)
+	assert.NotEmpty(t, stats.Stages)
+
+	tu.SysAdminClient.GET("/admin-api/v3/reports/pipeline").
+		WithQuery("institution_id", "two").
+		Expect().Status(http.StatusBadRequest)
+
+	tu.Inst1AdminClient.GET("/admin-api/v3/reports/pipeline").
+		Expect().Status(http.StatusForbidden)
+}
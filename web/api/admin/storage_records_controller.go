@@ -24,7 +24,7 @@ func StorageRecordIndex(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, api.NewJsonList(storageRecords, pager))
+	api.ConditionalJSON(c, api.NewJsonList(storageRecords, pager))
 }
 
 // StorageRecordShow returns the object with the specified id.
@@ -36,7 +36,7 @@ func StorageRecordShow(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, sr)
+	api.ConditionalJSON(c, sr)
 }
 
 // StorageRecordCreate creates a new StorageRecord. We only do this when
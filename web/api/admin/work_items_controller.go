@@ -6,9 +6,10 @@ import (
 	"strconv"
 
 	"github.com/APTrust/registry/common"
-	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/helpers"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
+	"github.com/APTrust/registry/web/webui"
 	"github.com/gin-gonic/gin"
 )
 
@@ -23,7 +24,10 @@ func WorkItemCreate(c *gin.Context) {
 	c.JSON(http.StatusCreated, gf)
 }
 
-// WorkItemUpdate updates an existing WorkItem record.
+// WorkItemUpdate updates an existing WorkItem record. This returns
+// 409 if the new stage or status can't follow the saved stage and
+// status, unless the query string includes
+// override_transition_check=true.
 //
 // PUT /admin-api/v3/items/update/:id
 func WorkItemUpdate(c *gin.Context) {
@@ -55,21 +59,97 @@ func WorkItemRequeue(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
+	data := map[string]interface{}{
+		"StatusCode": http.StatusOK,
+		"Message":    fmt.Sprintf("Requeued WorkItem %d to %s", itemID, stage),
+	}
+	c.JSON(http.StatusOK, data)
+}
 
-	topic, err := constants.TopicFor(item.Action, stage)
+// WorkItemBulkRequeue requeues all WorkItems matching the filters in
+// the query string to the stage in the target_stage query param. If
+// target_stage is empty, ingests go back to their current stage and
+// other items go back to Requested. With dry_run=true, this returns
+// the items and NSQ topics it would requeue without requeueing them.
+//
+// PUT /admin-api/v3/items/bulk_requeue
+func WorkItemBulkRequeue(c *gin.Context) {
+	req := api.NewRequest(c)
+	fc := webui.BulkRequeueFilterCollection(c)
+	dryRun := common.IsTrueString(c.Query("dry_run"))
+	result, err := webui.BulkRequeueWorkItems(fc, c.Query("target_stage"), req.CurrentUser, dryRun)
 	if api.AbortIfError(c, err) {
 		return
 	}
+	c.JSON(http.StatusOK, result)
+}
 
-	err = common.Context().NSQClient.Enqueue(topic, itemID)
+// WorkItemReconcileShow cross-checks incomplete WorkItems against NSQ
+// and Redis and returns the orphans no worker is handling, along with
+// completed items that left state in Redis. Each orphan says how
+// WorkItemReconcile would fix it.
+//
+// GET /admin-api/v3/items/reconcile
+func WorkItemReconcileShow(c *gin.Context) {
+	gracePeriod := common.Context().Config.Reconciliation.GracePeriod
+	result, err := pgmodels.ReconcileWorkItems(gracePeriod)
 	if api.AbortIfError(c, err) {
 		return
 	}
-	data := map[string]interface{}{
-		"StatusCode": http.StatusOK,
-		"Message":    fmt.Sprintf("Requeued WorkItem %d to %s", itemID, stage),
+	c.JSON(http.StatusOK, result)
+}
+
+// WorkItemReconcile checks a single WorkItem against NSQ and Redis and,
+// if it's still orphaned, fixes it. This returns the orphan with its
+// outcome, or 409 if the item doesn't need reconciliation.
+//
+// PUT /admin-api/v3/items/reconcile/:id
+func WorkItemReconcile(c *gin.Context) {
+	req := api.NewRequest(c)
+	gracePeriod := common.Context().Config.Reconciliation.GracePeriod
+	orphan, err := pgmodels.ReconcileWorkItem(req.Auth.ResourceID, gracePeriod)
+	if api.AbortIfError(c, err) {
+		return
 	}
-	c.JSON(http.StatusOK, data)
+	webui.FixOrphanedWorkItem(orphan, req.CurrentUser)
+	c.JSON(http.StatusOK, orphan)
+}
+
+// WorkItemStageTiming returns average, median and maximum times that
+// WorkItems spent in each stage, by action, for items that moved through
+// the stage between start_date and end_date. See
+// webui.GetReportDateRange for the date params and their defaults.
+//
+// GET /admin-api/v3/items/stage_timing
+func WorkItemStageTiming(c *gin.Context) {
+	startDate, endDate, err := webui.GetReportDateRange(c)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	stats, err := pgmodels.StageTimingStatsSelect(startDate, endDate)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// WorkItemRedisShow returns the typed ingest or restoration state the
+// workers have stored in Redis for a WorkItem. Use the file_filter
+// param to return only files in error, not validated, or not stored,
+// and the page and per_page params to page through files.
+//
+// GET /admin-api/v3/items/redis/:id
+func WorkItemRedisShow(c *gin.Context) {
+	req := api.NewRequest(c)
+	pager, err := common.NewPager(c, req.PathAndQuery, 100)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	state, err := webui.LoadRedisWorkItemState(req.Auth.ResourceID, c.Query("file_filter"), pager)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, state)
 }
 
 // WorkItemRedisDelete deletes a WorkItem's Redis record.
@@ -94,10 +174,18 @@ func WorkItemRedisDelete(c *gin.Context) {
 
 func CreateOrUpdateItem(c *gin.Context) (*pgmodels.WorkItem, error) {
 	req := api.NewRequest(c)
-	gf, err := WorkItemFromJson(req)
+	gf, existing, err := WorkItemFromJson(req)
 	if err != nil {
 		return nil, err
 	}
+	err = pgmodels.AuditChangesFrom(existing, gf, helpers.AuditActor(c))
+	if err != nil {
+		return nil, err
+	}
+	if req.GinContext.Query("override_transition_check") == "true" {
+		common.Context().Log.Warn().Msgf("User %s is overriding the transition check for WorkItem %d", req.CurrentUser.Email, gf.ID)
+		gf.OverrideTransitionCheck = true
+	}
 	err = gf.Save()
 	return gf, err
 }
@@ -107,22 +195,23 @@ func CreateOrUpdateItem(c *gin.Context) (*pgmodels.WorkItem, error) {
 // the database (if there is one). It returns an error if the JSON
 // can't be parsed, if the existing file can't be found, or if
 // changes made to the existing object are not allowed.
-func WorkItemFromJson(req *api.Request) (*pgmodels.WorkItem, error) {
+func WorkItemFromJson(req *api.Request) (*pgmodels.WorkItem, *pgmodels.WorkItem, error) {
 	submittedItem := &pgmodels.WorkItem{}
+	var existingItem *pgmodels.WorkItem
 	err := req.GinContext.BindJSON(submittedItem)
 	if err != nil {
-		return submittedItem, err
+		return submittedItem, nil, err
 	}
 	err = req.AssertValidIDs(submittedItem.ID, submittedItem.InstitutionID)
 	if err != nil {
-		return submittedItem, err
+		return submittedItem, nil, err
 	}
 	if req.Auth.ResourceID > 0 {
-		existingItem, err := pgmodels.WorkItemByID(req.Auth.ResourceID)
+		existingItem, err = pgmodels.WorkItemByID(req.Auth.ResourceID)
 		if err != nil {
-			return submittedItem, err
+			return submittedItem, nil, err
 		}
 		err = existingItem.ValidateChanges(submittedItem)
 	}
-	return submittedItem, err
+	return submittedItem, existingItem, err
 }
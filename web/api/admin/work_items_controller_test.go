@@ -2,10 +2,13 @@ package admin_api_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"testing"
 
+	"github.com/APTrust/registry/common"
 	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/network"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
 	"github.com/APTrust/registry/web/testutil"
@@ -129,6 +132,146 @@ func TestWorkItemRequeue(t *testing.T) {
 		Expect().Status(http.StatusForbidden)
 }
 
+func TestWorkItemBulkRequeue(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	workItem := testutil.CreateWorkItem(t, "unit_test_bulk_requeue2.tar")
+
+	// Give this item a node no other item has, so our filters match only this.
+	workItem.Node = fmt.Sprintf("bulk-requeue-test-%d", workItem.ID)
+	require.Nil(t, workItem.Save())
+
+	// Dry run shows what we'd do without doing it.
+	resp := tu.SysAdminClient.PUT("/admin-api/v3/items/bulk_requeue").
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		WithQuery("node", workItem.Node).
+		WithQuery("dry_run", "true").
+		Expect().Status(http.StatusOK)
+	result := &pgmodels.BulkRequeueResult{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), result))
+	assert.True(t, result.DryRun)
+	require.Equal(t, 1, result.Matched)
+	assert.Equal(t, 0, result.Requeued)
+	assert.Equal(t, workItem.ID, result.Items[0].WorkItemID)
+	assert.Equal(t, constants.StageRecord, result.Items[0].TargetStage)
+	assert.Equal(t, constants.IngestRecord, result.Items[0].Topic)
+	assert.False(t, result.Items[0].Requeued)
+
+	// Now requeue for real.
+	resp = tu.SysAdminClient.PUT("/admin-api/v3/items/bulk_requeue").
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		WithQuery("node", workItem.Node).
+		Expect().Status(http.StatusOK)
+	result = &pgmodels.BulkRequeueResult{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), result))
+	assert.False(t, result.DryRun)
+	assert.Equal(t, 1, result.Requeued)
+	assert.True(t, result.Items[0].Requeued)
+
+	item, err := pgmodels.WorkItemByID(workItem.ID)
+	require.Nil(t, err)
+	assert.Equal(t, constants.StatusPending, item.Status)
+	assert.Empty(t, item.Node)
+
+	// Filters are required.
+	tu.SysAdminClient.PUT("/admin-api/v3/items/bulk_requeue").
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		Expect().Status(http.StatusBadRequest)
+
+	// Other roles can't requeue.
+	tu.Inst1AdminClient.PUT("/admin-api/v3/items/bulk_requeue").
+		WithHeader(constants.APIUserHeader, tu.Inst1Admin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		WithQuery("status", constants.StatusFailed).
+		Expect().Status(http.StatusForbidden)
+}
+
+func TestWorkItemReconcile(t *testing.T) {
+	tu.InitHTTPTests(t)
+	ctx := common.Context()
+
+	// This item completed, but left state in Redis.
+	require.Nil(t, ctx.RedisClient.SaveItem(33, "object:test", `{"key":"value"}`))
+	defer ctx.RedisClient.WorkItemDelete(33)
+
+	resp := tu.SysAdminClient.GET("/admin-api/v3/items/reconcile").
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		Expect().Status(http.StatusOK)
+	result := &pgmodels.WorkItemReconciliation{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), result))
+	assert.True(t, result.ItemsChecked > 0)
+	var found *pgmodels.OrphanedWorkItem
+	for _, orphan := range result.Orphans {
+		if orphan.WorkItemID == 33 {
+			found = orphan
+		}
+	}
+	require.NotNil(t, found)
+	assert.Equal(t, pgmodels.OrphanStaleRedisState, found.Problem)
+	assert.Equal(t, pgmodels.OrphanFixRedisDelete, found.Fix)
+
+	resp = tu.SysAdminClient.PUT("/admin-api/v3/items/reconcile/33").
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		Expect().Status(http.StatusOK)
+	orphan := &pgmodels.OrphanedWorkItem{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), orphan))
+	assert.True(t, orphan.Fixed)
+	assert.Empty(t, orphan.Error)
+	assert.False(t, ctx.RedisClient.KeyExists(33))
+
+	// Now it doesn't need reconciliation.
+	tu.SysAdminClient.PUT("/admin-api/v3/items/reconcile/33").
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		Expect().Status(http.StatusConflict)
+
+	// Non sys-admins can't get here.
+	tu.Inst1AdminClient.GET("/admin-api/v3/items/reconcile").
+		WithHeader(constants.APIUserHeader, tu.Inst1Admin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		Expect().Status(http.StatusForbidden)
+	tu.Inst1AdminClient.PUT("/admin-api/v3/items/reconcile/33").
+		WithHeader(constants.APIUserHeader, tu.Inst1Admin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		Expect().Status(http.StatusForbidden)
+}
+
+func TestWorkItemStageTiming(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	resp := tu.SysAdminClient.GET("/admin-api/v3/items/stage_timing").
+		WithQuery("start_date", "2016-08-01").
+		WithQuery("end_date", "2016-08-31").
+		Expect().Status(http.StatusOK)
+	var stats []*pgmodels.StageTimingStats
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), &stats))
+	require.Equal(t, 5, len(stats))
+	for _, s := range stats {
+		assert.Equal(t, constants.ActionIngest, s.Action)
+		if s.Stage == constants.StageStore {
+			assert.EqualValues(t, 2, s.Attempts)
+			assert.EqualValues(t, 9000, s.AvgSeconds)
+		}
+	}
+
+	// Default range is the last 30 days.
+	tu.SysAdminClient.GET("/admin-api/v3/items/stage_timing").
+		Expect().Status(http.StatusOK)
+
+	tu.SysAdminClient.GET("/admin-api/v3/items/stage_timing").
+		WithQuery("start_date", "last tuesday").
+		Expect().Status(http.StatusBadRequest)
+
+	// Non sys-admins can't get here.
+	tu.Inst1AdminClient.GET("/admin-api/v3/items/stage_timing").
+		Expect().Status(http.StatusForbidden)
+}
+
 func TestWorkItemRedisDelete(t *testing.T) {
 	tu.InitHTTPTests(t)
 	workItem := testutil.CreateWorkItem(t, "unit_test_bag2.tar")
@@ -152,7 +295,8 @@ func TestWorkItemRedisDelete(t *testing.T) {
 
 func TestItemCreateAndUpdate(t *testing.T) {
 	item := testItemCreate(t)
-	testItemUpdate(t, item)
+	item = testItemUpdate(t, item)
+	testItemIllegalTransition(t, item)
 }
 
 func testItemCreate(t *testing.T) *pgmodels.WorkItem {
@@ -185,6 +329,7 @@ func testItemCreate(t *testing.T) *pgmodels.WorkItem {
 
 func testItemUpdate(t *testing.T, item *pgmodels.WorkItem) *pgmodels.WorkItem {
 	origUpdatedAt := item.UpdatedAt
+	origOutcome := item.Outcome
 	copyOfItem := item
 	copyOfItem.Size = item.Size + 200
 	copyOfItem.Outcome = "This outcome has been edited"
@@ -204,5 +349,99 @@ func testItemUpdate(t *testing.T, item *pgmodels.WorkItem) *pgmodels.WorkItem {
 	assert.Equal(t, item.CreatedAt, updatedItem.CreatedAt)
 	assert.True(t, updatedItem.UpdatedAt.After(origUpdatedAt))
 
+	// The update should be in the audit log, attributed to the
+	// API user.
+	auditQuery := pgmodels.NewQuery().
+		Where("model_type", "=", "WorkItem").
+		Where("model_id", "=", item.ID).
+		OrderBy("id", "desc")
+	entry, err := pgmodels.AuditLogGet(auditQuery)
+	require.Nil(t, err)
+	assert.Equal(t, constants.AuditActionUpdate, entry.Action)
+	assert.Equal(t, tu.SysAdmin.Email, entry.ActorEmail)
+	assert.Equal(t, origOutcome, entry.Before["outcome"])
+	assert.Equal(t, copyOfItem.Outcome, entry.After["outcome"])
+
 	return updatedItem
 }
+
+func testItemIllegalTransition(t *testing.T, item *pgmodels.WorkItem) {
+	item.Stage = constants.StageCleanup
+	item.Status = constants.StatusSuccess
+	tu.SysAdminClient.PUT("/admin-api/v3/items/update/{id}", item.ID).
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		WithJSON(item).Expect().Status(http.StatusOK)
+
+	// Successful items can't go back to Started.
+	item.Status = constants.StatusStarted
+	resp := tu.SysAdminClient.PUT("/admin-api/v3/items/update/{id}", item.ID).
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		WithJSON(item).Expect()
+	resp.Status(http.StatusConflict)
+	resp.JSON().Object().Value("Error").String().Contains("Success items cannot move to Started")
+
+	// Unless an admin overrides the check.
+	tu.SysAdminClient.PUT("/admin-api/v3/items/update/{id}", item.ID).
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		WithQuery("override_transition_check", "true").
+		WithJSON(item).Expect().Status(http.StatusOK)
+
+	savedItem, err := pgmodels.WorkItemByID(item.ID)
+	require.Nil(t, err)
+	assert.Equal(t, constants.StatusStarted, savedItem.Status)
+}
+
+func TestWorkItemRedisShow(t *testing.T) {
+	tu.InitHTTPTests(t)
+	ctx := common.Context()
+	require.Nil(t, ctx.RedisClient.SaveItem(29, "file:test.edu/bag/data/good.txt",
+		`{"path_in_bag":"data/good.txt","storage_records":[{"url":"https://example.com/1","stored_at":"2021-04-01T12:00:00Z"}]}`))
+	require.Nil(t, ctx.RedisClient.SaveItem(29, "file:test.edu/bag/data/unstored.txt",
+		`{"path_in_bag":"data/unstored.txt"}`))
+	require.Nil(t, ctx.RedisClient.SaveItem(29, "object:test.edu/bag", `{"key1":"value1"}`))
+	defer ctx.RedisClient.WorkItemDelete(29)
+
+	resp := tu.SysAdminClient.GET("/admin-api/v3/items/redis/29").
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		WithQuery("file_filter", network.RedisFileFilterNotStored).
+		Expect().Status(http.StatusOK)
+	state := &network.RedisWorkItemState{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), state))
+	assert.Equal(t, int64(29), state.WorkItemID)
+	require.Equal(t, 1, len(state.Files))
+	assert.Equal(t, "data/unstored.txt", state.Files[0].PathInBag)
+	assert.Equal(t, 1, state.FilesMatched)
+	assert.Equal(t, 2, state.FileSummary.Total)
+	assert.Equal(t, 1, state.FileSummary.Stored)
+	assert.Nil(t, state.IngestObject)
+	assert.JSONEq(t, `{"key1":"value1"}`, string(state.Unknown["object:test.edu/bag"]))
+
+	// Files come one page at a time, sorted by path.
+	resp = tu.SysAdminClient.GET("/admin-api/v3/items/redis/29").
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		WithQuery("page", 2).
+		WithQuery("per_page", 1).
+		Expect().Status(http.StatusOK)
+	state = &network.RedisWorkItemState{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), state))
+	assert.Equal(t, 2, state.FilesMatched)
+	require.Equal(t, 1, len(state.Files))
+	assert.Equal(t, "data/unstored.txt", state.Files[0].PathInBag)
+
+	tu.SysAdminClient.GET("/admin-api/v3/items/redis/29").
+		WithHeader(constants.APIUserHeader, tu.SysAdmin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		WithQuery("file_filter", "bogus").
+		Expect().Status(http.StatusBadRequest)
+
+	// Non sys-admins can't get here.
+	tu.Inst1AdminClient.GET("/admin-api/v3/items/redis/29").
+		WithHeader(constants.APIUserHeader, tu.Inst1Admin.Email).
+		WithHeader(constants.APIKeyHeader, "password").
+		Expect().Status(http.StatusForbidden)
+}
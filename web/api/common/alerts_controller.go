@@ -1,7 +1,6 @@
 package common_api
 
 import (
-	"net/http"
 	"strconv"
 
 	"github.com/APTrust/registry/common"
@@ -23,7 +22,7 @@ func AlertShow(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, alertView)
+	api.ConditionalJSON(c, alertView)
 }
 
 // AlertIndex shows list of alerts for the logged-in user, unless
@@ -38,7 +37,7 @@ func AlertIndex(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, api.NewJsonList(alerts, pager))
+	api.ConditionalJSON(c, api.NewJsonList(alerts, pager))
 }
 
 func alertLoad(req *api.Request) (*pgmodels.AlertView, error) {
@@ -0,0 +1,81 @@
+package common_api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPIKey(t *testing.T, user *pgmodels.User, scope, allowedIPs string) *pgmodels.APIKey {
+	key, err := pgmodels.NewAPIKey(user.ID)
+	require.Nil(t, err)
+	key.Label = "Test key " + scope
+	key.Scope = scope
+	key.AllowedIPs = allowedIPs
+	require.Nil(t, key.Save())
+	return key
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	require.Nil(t, db.ForceFixtureReload())
+	tu.InitHTTPTests(t)
+	client := tu.GetAnonymousClient(t)
+
+	readOnly := newTestAPIKey(t, tu.Inst1User, constants.APIKeyScopeReadOnly, "")
+	readWrite := newTestAPIKey(t, tu.Inst1User, constants.APIKeyScopeReadWrite, "")
+	restricted := newTestAPIKey(t, tu.Inst1User, constants.APIKeyScopeReadWrite, "203.0.113.5")
+
+	// Read-only key can read but not write.
+	client.GET("/member-api/v3/objects/show/{id}", 1).
+		WithHeader(constants.APIUserHeader, tu.Inst1User.Email).
+		WithHeader(constants.APIKeyHeader, readOnly.PlaintextKey).
+		Expect().Status(http.StatusOK)
+	client.POST("/member-api/v3/objects/restore/{id}", 2).
+		WithHeader(constants.APIUserHeader, tu.Inst1User.Email).
+		WithHeader(constants.APIKeyHeader, readOnly.PlaintextKey).
+		Expect().Status(http.StatusForbidden)
+
+	// Read-write key can write.
+	client.POST("/member-api/v3/objects/restore/{id}", 2).
+		WithHeader(constants.APIUserHeader, tu.Inst1User.Email).
+		WithHeader(constants.APIKeyHeader, readWrite.PlaintextKey).
+		Expect().Status(http.StatusCreated)
+
+	// Key is tied to its owner.
+	client.GET("/member-api/v3/objects/show/{id}", 1).
+		WithHeader(constants.APIUserHeader, tu.Inst1Admin.Email).
+		WithHeader(constants.APIKeyHeader, readWrite.PlaintextKey).
+		Expect().Status(http.StatusUnauthorized)
+
+	// Key can't be used from an IP address outside its allowed list.
+	client.GET("/member-api/v3/objects/show/{id}", 1).
+		WithHeader(constants.APIUserHeader, tu.Inst1User.Email).
+		WithHeader(constants.APIKeyHeader, restricted.PlaintextKey).
+		Expect().Status(http.StatusUnauthorized)
+
+	// Successful use is recorded.
+	key, err := pgmodels.APIKeyByID(readWrite.ID)
+	require.Nil(t, err)
+	assert.False(t, key.LastUsedAt.IsZero())
+
+	// Revoked and expired keys don't work.
+	require.Nil(t, key.Revoke())
+	client.GET("/member-api/v3/objects/show/{id}", 1).
+		WithHeader(constants.APIUserHeader, tu.Inst1User.Email).
+		WithHeader(constants.APIKeyHeader, readWrite.PlaintextKey).
+		Expect().Status(http.StatusUnauthorized)
+
+	readOnly.ExpiresAt = time.Now().UTC().Add(-1 * time.Minute)
+	require.Nil(t, readOnly.Save())
+	client.GET("/member-api/v3/objects/show/{id}", 1).
+		WithHeader(constants.APIUserHeader, tu.Inst1User.Email).
+		WithHeader(constants.APIKeyHeader, readOnly.PlaintextKey).
+		Expect().Status(http.StatusUnauthorized)
+}
@@ -0,0 +1,36 @@
+package common_api
+
+import (
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/api"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLogShow returns the audit log entry with the specified id.
+//
+// GET /member-api/v3/audit_logs/show/:id
+// GET /admin-api/v3/audit_logs/show/:id
+func AuditLogShow(c *gin.Context) {
+	req := api.NewRequest(c)
+	entry, err := pgmodels.AuditLogByID(req.Auth.ResourceID)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	api.ConditionalJSON(c, entry)
+}
+
+// AuditLogIndex returns a list of audit log entries, newest first.
+// Institutional admins see only changes to their own institution's
+// records.
+//
+// GET /member-api/v3/audit_logs
+// GET /admin-api/v3/audit_logs
+func AuditLogIndex(c *gin.Context) {
+	req := api.NewRequest(c)
+	var entries []*pgmodels.AuditLog
+	pager, err := req.LoadResourceList(&entries, "created_at", "desc")
+	if api.AbortIfError(c, err) {
+		return
+	}
+	api.ConditionalJSON(c, api.NewJsonList(entries, pager))
+}
@@ -0,0 +1,96 @@
+package common_api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/api"
+	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogShow(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// Sysadmin and admins at the owning institution can see the entry.
+	for _, client := range []*httpexpect.Expect{tu.SysAdminClient, tu.Inst2AdminClient} {
+		resp := client.GET("/member-api/v3/audit_logs/show/{id}", 3).Expect().Status(http.StatusOK)
+		record := &pgmodels.AuditLog{}
+		err := json.Unmarshal([]byte(resp.Body().Raw()), record)
+		require.Nil(t, err)
+		assert.Equal(t, int64(3), record.ID)
+		assert.Equal(t, constants.AuditActionDelete, record.Action)
+		assert.Equal(t, "User", record.ModelType)
+		assert.Equal(t, int64(7), record.ModelID)
+		assert.Equal(t, "admin@inst2.edu", record.ActorEmail)
+		assert.Equal(t, "2021-06-03T10:00:00Z", record.After["deactivated_at"])
+	}
+
+	// Admins at other institutions cannot, and neither can
+	// institutional users.
+	tu.Inst1AdminClient.GET("/member-api/v3/audit_logs/show/{id}", 3).
+		Expect().Status(http.StatusForbidden)
+	tu.Inst2UserClient.GET("/member-api/v3/audit_logs/show/{id}", 3).
+		Expect().Status(http.StatusForbidden)
+
+	// Only sysadmin can see changes to records that belong
+	// to no institution.
+	tu.SysAdminClient.GET("/member-api/v3/audit_logs/show/{id}", 4).
+		Expect().Status(http.StatusOK)
+	tu.Inst1AdminClient.GET("/member-api/v3/audit_logs/show/{id}", 4).
+		Expect().Status(http.StatusForbidden)
+
+	// Admin API returns the same data.
+	tu.SysAdminClient.GET("/admin-api/v3/audit_logs/show/{id}", 3).
+		Expect().Status(http.StatusOK)
+}
+
+func TestAuditLogIndex(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// Other tests add entries to the audit log, so we filter
+	// by record type to get a predictable count.
+	resp := tu.SysAdminClient.GET("/member-api/v3/audit_logs").
+		WithQuery("model_type", "StorageOption").
+		Expect().Status(http.StatusOK)
+	list := api.AuditLogList{}
+	err := json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	require.Equal(t, 1, list.Count)
+	assert.Equal(t, int64(4), list.Results[0].ID)
+
+	// Newest entries come first.
+	resp = tu.SysAdminClient.GET("/admin-api/v3/audit_logs").
+		WithQuery("actor_email", "admin@inst1.edu").
+		WithQuery("created_at__lteq", "2021-12-31").
+		Expect().Status(http.StatusOK)
+	err = json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	require.Equal(t, 2, list.Count)
+	assert.Equal(t, int64(2), list.Results[0].ID)
+	assert.Equal(t, int64(1), list.Results[1].ID)
+
+	// Inst admins see only their own institution's changes.
+	resp = tu.Inst1AdminClient.GET("/member-api/v3/audit_logs").
+		Expect().Status(http.StatusOK)
+	err = json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	assert.True(t, list.Count >= 3)
+	for _, entry := range list.Results {
+		assert.Equal(t, tu.Inst1Admin.InstitutionID, entry.InstitutionID)
+	}
+
+	// And can't ask for other institutions' changes.
+	tu.Inst1AdminClient.GET("/member-api/v3/audit_logs").
+		WithQuery("institution_id", tu.Inst2Admin.InstitutionID).
+		Expect().Status(http.StatusForbidden)
+
+	// Institutional users can't see the audit log.
+	tu.Inst1UserClient.GET("/member-api/v3/audit_logs").
+		Expect().Status(http.StatusForbidden)
+}
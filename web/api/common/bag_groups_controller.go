@@ -0,0 +1,40 @@
+package common_api
+
+import (
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/api"
+	"github.com/gin-gonic/gin"
+)
+
+// BagGroupShow returns the bag group with the specified id, including
+// a breakdown of its contents by storage option.
+//
+// GET /member-api/v3/bag_groups/show/:id
+// GET /admin-api/v3/bag_groups/show/:id
+func BagGroupShow(c *gin.Context) {
+	req := api.NewRequest(c)
+	bagGroup, err := pgmodels.BagGroupViewByID(req.Auth.ResourceID)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	err = bagGroup.LoadStorageOptionStats()
+	if api.AbortIfError(c, err) {
+		return
+	}
+	api.ConditionalJSON(c, bagGroup)
+}
+
+// BagGroupIndex returns a list of bag groups. Non-admins see only
+// bag groups belonging to their own institution.
+//
+// GET /member-api/v3/bag_groups
+// GET /admin-api/v3/bag_groups
+func BagGroupIndex(c *gin.Context) {
+	req := api.NewRequest(c)
+	var bagGroups []*pgmodels.BagGroupView
+	pager, err := req.LoadResourceList(&bagGroups, "identifier", "asc")
+	if api.AbortIfError(c, err) {
+		return
+	}
+	api.ConditionalJSON(c, api.NewJsonList(bagGroups, pager))
+}
@@ -0,0 +1,83 @@
+package common_api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/api"
+	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBagGroupShow(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// Sysadmin and users at the owning institution can see
+	// the bag group, with its storage option breakdown.
+	for _, client := range []*httpexpect.Expect{tu.SysAdminClient, tu.Inst1AdminClient, tu.Inst1UserClient} {
+		resp := client.GET("/member-api/v3/bag_groups/show/{id}", 2).Expect().Status(http.StatusOK)
+		record := &pgmodels.BagGroupView{}
+		err := json.Unmarshal([]byte(resp.Body().Raw()), record)
+		require.Nil(t, err)
+		assert.Equal(t, int64(2), record.ID)
+		assert.Equal(t, "carolina-2", record.Identifier)
+		assert.Equal(t, int64(2), record.ObjectCount)
+		assert.Equal(t, int64(7), record.FileCount)
+		require.Equal(t, 2, len(record.StorageOptions))
+		assert.Equal(t, constants.StorageOptionGlacierDeepOH, record.StorageOptions[0].StorageOption)
+		assert.Equal(t, constants.StorageOptionStandard, record.StorageOptions[1].StorageOption)
+	}
+
+	// Users at other institutions cannot.
+	tu.Inst2AdminClient.GET("/member-api/v3/bag_groups/show/{id}", 2).
+		Expect().Status(http.StatusForbidden)
+	tu.Inst2UserClient.GET("/member-api/v3/bag_groups/show/{id}", 2).
+		Expect().Status(http.StatusForbidden)
+
+	// Admin API returns the same data.
+	tu.SysAdminClient.GET("/admin-api/v3/bag_groups/show/{id}", 2).
+		Expect().Status(http.StatusOK)
+}
+
+func TestBagGroupIndex(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// Sysadmin sees all bag groups.
+	resp := tu.SysAdminClient.GET("/member-api/v3/bag_groups").
+		Expect().Status(http.StatusOK)
+	list := api.BagGroupViewList{}
+	err := json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	assert.Equal(t, 4, list.Count)
+	assert.Equal(t, "carolina-1", list.Results[0].Identifier)
+
+	// Filters work.
+	resp = tu.SysAdminClient.GET("/member-api/v3/bag_groups").
+		WithQuery("identifier__starts_with", "dakota").
+		WithQuery("sort", "identifier__desc").
+		Expect().Status(http.StatusOK)
+	err = json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	assert.Equal(t, 2, list.Count)
+	assert.Equal(t, "dakota-2", list.Results[0].Identifier)
+
+	// Non-admins see only their own institution's bag groups.
+	resp = tu.Inst2UserClient.GET("/member-api/v3/bag_groups").
+		Expect().Status(http.StatusOK)
+	err = json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	assert.Equal(t, 2, list.Count)
+	for _, bagGroup := range list.Results {
+		assert.Equal(t, tu.Inst2User.InstitutionID, bagGroup.InstitutionID)
+	}
+
+	// And can't ask for other institutions' bag groups.
+	tu.Inst2UserClient.GET("/member-api/v3/bag_groups").
+		WithQuery("institution_id", tu.Inst1User.InstitutionID).
+		Expect().Status(http.StatusForbidden)
+}
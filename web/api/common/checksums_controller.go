@@ -1,8 +1,6 @@
 package common_api
 
 import (
-	"net/http"
-
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
 	"github.com/gin-gonic/gin"
@@ -19,7 +17,7 @@ func ChecksumIndex(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, api.NewJsonList(checksums, pager))
+	api.ConditionalJSON(c, api.NewJsonList(checksums, pager))
 }
 
 // ChecksumShow returns the object with the specified id.
@@ -32,5 +30,5 @@ func ChecksumShow(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, cs)
+	api.ConditionalJSON(c, cs)
 }
@@ -3,8 +3,10 @@ package common_api
 import (
 	"net/http"
 
+	"github.com/APTrust/registry/helpers"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
+	"github.com/APTrust/registry/web/webui"
 	"github.com/gin-gonic/gin"
 )
 
@@ -27,7 +29,7 @@ func DeletionRequestShow(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, deletionRequestView)
+	api.ConditionalJSON(c, deletionRequestView)
 }
 
 // DeletionRequestIndex shows list of deletion requests.
@@ -41,5 +43,63 @@ func DeletionRequestIndex(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, api.NewJsonList(deletions, pager))
+	api.ConditionalJSON(c, api.NewJsonList(deletions, pager))
+}
+
+// DeletionRequestApprove lets an institutional admin approve a pending
+// deletion request. This creates and queues the deletion WorkItems. An
+// admin cannot approve their own request unless they are the only
+// admin at their institution. Unlike the web UI, this does not require
+// the confirmation token from the deletion request email.
+//
+// POST /member-api/v3/deletions/approve/:id
+func DeletionRequestApprove(c *gin.Context) {
+	req := api.NewRequest(c)
+	del, err := webui.NewDeletionForAPIReview(req.Auth.ResourceID, req.CurrentUser, req.BaseURL(), helpers.AuditActor(c))
+	if api.AbortIfError(c, err) {
+		return
+	}
+	err = del.Approve()
+	if api.AbortIfError(c, err) {
+		return
+	}
+	respondWithDeletionRequest(c, http.StatusOK, del.DeletionRequest.ID)
+}
+
+// DeletionRequestCancel lets an institutional admin cancel (reject)
+// a pending deletion request.
+//
+// POST /member-api/v3/deletions/cancel/:id
+func DeletionRequestCancel(c *gin.Context) {
+	req := api.NewRequest(c)
+	del, err := webui.NewDeletionForAPIReview(req.Auth.ResourceID, req.CurrentUser, req.BaseURL(), helpers.AuditActor(c))
+	if api.AbortIfError(c, err) {
+		return
+	}
+	err = del.Cancel()
+	if api.AbortIfError(c, err) {
+		return
+	}
+	respondWithDeletionRequest(c, http.StatusOK, del.DeletionRequest.ID)
+}
+
+// initDeletion sends the deletion request alert to the institution's
+// admins and responds with the new deletion request.
+func initDeletion(c *gin.Context, del *webui.Deletion) {
+	_, err := del.CreateRequestAlert()
+	if api.AbortIfError(c, err) {
+		return
+	}
+	respondWithDeletionRequest(c, http.StatusCreated, del.DeletionRequest.ID)
+}
+
+// respondWithDeletionRequest responds with the DeletionRequestView for
+// the specified deletion request, which is what the show and index
+// endpoints return.
+func respondWithDeletionRequest(c *gin.Context, status int, deletionRequestID int64) {
+	deletionRequestView, err := pgmodels.DeletionRequestViewByID(deletionRequestID)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	c.JSON(status, deletionRequestView)
 }
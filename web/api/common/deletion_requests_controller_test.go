@@ -5,9 +5,13 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
 	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/gavv/httpexpect/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -107,3 +111,121 @@ func TestDeletionRequestIndex(t *testing.T) {
 		Expect().Status(http.StatusForbidden)
 
 }
+
+func TestDeletionRequestApproveViaAPI(t *testing.T) {
+	require.Nil(t, db.ForceFixtureReload())
+	defer db.ForceFixtureReload()
+	tu.InitHTTPTests(t)
+
+	// Inst users and sys admins can't request deletions.
+	tu.Inst1UserClient.POST("/member-api/v3/objects/init_delete/{id}", 1).
+		Expect().Status(http.StatusForbidden)
+	tu.SysAdminClient.POST("/member-api/v3/objects/init_delete/{id}", 1).
+		Expect().Status(http.StatusForbidden)
+
+	resp := tu.Inst1AdminClient.POST("/member-api/v3/objects/init_delete/{id}", 1).
+		Expect().Status(http.StatusCreated)
+	deletion := &pgmodels.DeletionRequestView{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), deletion))
+	assert.True(t, deletion.ID > 0)
+	assert.Equal(t, tu.Inst1Admin.ID, deletion.RequestedByID)
+	assert.Equal(t, int64(1), deletion.ObjectCount)
+	assert.True(t, deletion.ConfirmedAt.IsZero())
+
+	// New request should show up in the list of pending requests.
+	resp = tu.Inst1AdminClient.GET("/member-api/v3/deletions").
+		WithQuery("confirmed_at__is_null", "true").
+		WithQuery("cancelled_at__is_null", "true").
+		Expect().Status(http.StatusOK)
+	list := api.DeletionRequestViewList{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), &list))
+	found := false
+	for _, pending := range list.Results {
+		assert.True(t, pending.ConfirmedAt.IsZero())
+		assert.True(t, pending.CancelledAt.IsZero())
+		if pending.ID == deletion.ID {
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	// Only an inst admin at the right institution can approve.
+	tu.Inst1UserClient.POST("/member-api/v3/deletions/approve/{id}", deletion.ID).
+		Expect().Status(http.StatusForbidden)
+	tu.Inst2AdminClient.POST("/member-api/v3/deletions/approve/{id}", deletion.ID).
+		Expect().Status(http.StatusForbidden)
+
+	// When the institution has a second admin, the requester
+	// can't approve their own request. The other admin has to.
+	secondAdmin, secondAdminClient := createSecondInst1Admin(t)
+	resp = tu.Inst1AdminClient.POST("/member-api/v3/deletions/approve/{id}", deletion.ID).
+		Expect()
+	resp.Status(http.StatusBadRequest)
+	resp.JSON().Object().Value("Error").String().Contains(pgmodels.ErrDeletionBadAdmin)
+
+	resp = secondAdminClient.POST("/member-api/v3/deletions/approve/{id}", deletion.ID).
+		Expect().Status(http.StatusOK)
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), deletion))
+	assert.Equal(t, secondAdmin.ID, deletion.ConfirmedByID)
+	assert.False(t, deletion.ConfirmedAt.IsZero())
+
+	record, err := pgmodels.DeletionRequestByID(deletion.ID)
+	require.Nil(t, err)
+	assert.NotEmpty(t, record.WorkItems)
+
+	// Can't approve or cancel twice.
+	tu.Inst1AdminClient.POST("/member-api/v3/deletions/approve/{id}", deletion.ID).
+		Expect().Status(http.StatusConflict)
+	tu.Inst1AdminClient.POST("/member-api/v3/deletions/cancel/{id}", deletion.ID).
+		Expect().Status(http.StatusConflict)
+
+	// Can't request deletion while the approved deletion is pending.
+	tu.Inst1AdminClient.POST("/member-api/v3/objects/init_delete/{id}", 1).
+		Expect().Status(http.StatusConflict)
+}
+
+func TestDeletionRequestCancelViaAPI(t *testing.T) {
+	require.Nil(t, db.ForceFixtureReload())
+	defer db.ForceFixtureReload()
+	tu.InitHTTPTests(t)
+
+	resp := tu.Inst1AdminClient.POST("/member-api/v3/files/init_delete/{id}", 2).
+		Expect().Status(http.StatusCreated)
+	deletion := &pgmodels.DeletionRequestView{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), deletion))
+	assert.Equal(t, int64(1), deletion.FileCount)
+
+	resp = tu.Inst1AdminClient.POST("/member-api/v3/deletions/cancel/{id}", deletion.ID).
+		Expect().Status(http.StatusOK)
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), deletion))
+	assert.Equal(t, tu.Inst1Admin.ID, deletion.CancelledByID)
+	assert.False(t, deletion.CancelledAt.IsZero())
+
+	// Cancelled requests create no work items.
+	record, err := pgmodels.DeletionRequestByID(deletion.ID)
+	require.Nil(t, err)
+	assert.Empty(t, record.WorkItems)
+
+	tu.Inst1AdminClient.POST("/member-api/v3/deletions/approve/{id}", deletion.ID).
+		Expect().Status(http.StatusConflict)
+}
+
+// createSecondInst1Admin adds a second institutional admin to inst1
+// and returns the admin with a signed-in client. The caller should
+// reload fixtures when it's done.
+func createSecondInst1Admin(t *testing.T) (*pgmodels.User, *httpexpect.Expect) {
+	encPassword, err := common.EncryptPassword("password")
+	require.Nil(t, err)
+	admin := &pgmodels.User{
+		Name:                   "Second Inst 1 Admin",
+		Email:                  "admin2@inst1.edu",
+		InstitutionID:          tu.Inst1Admin.InstitutionID,
+		Role:                   constants.RoleInstAdmin,
+		EncryptedPassword:      encPassword,
+		EmailVerified:          true,
+		InitialPasswordUpdated: true,
+	}
+	require.Nil(t, admin.Save())
+	client, _ := tu.InitClient(t, admin.Email)
+	return admin, client
+}
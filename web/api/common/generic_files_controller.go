@@ -3,11 +3,30 @@ package common_api
 import (
 	"net/http"
 
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/helpers"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
+	"github.com/APTrust/registry/web/webui"
 	"github.com/gin-gonic/gin"
 )
 
+// GenericFileChanges returns files that changed at or after the time
+// in the required updated_at__gteq param, oldest first, using cursor
+// paging. See api.Request.LoadChanges.
+//
+// GET /member-api/v3/files/changes
+// GET /admin-api/v3/files/changes
+func GenericFileChanges(c *gin.Context) {
+	req := api.NewRequest(c)
+	var files []*pgmodels.GenericFileView
+	pager, err := req.LoadChanges(&files)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	api.ConditionalJSON(c, api.NewJsonList(files, pager))
+}
+
 // GenericFileIndex shows list of files. Unlike the admin API,
 // the member API version returns a list of GenericFileView objects.
 //
@@ -19,7 +38,7 @@ func GenericFileIndex(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, api.NewJsonList(files, pager))
+	api.ConditionalJSON(c, api.NewJsonList(files, pager))
 }
 
 // GenericFileShow returns the object with the specified id.
@@ -32,5 +51,73 @@ func GenericFileShow(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, gf)
+	api.ConditionalJSON(c, gf)
+}
+
+// GenericFileManifest streams a manifest of all files matching the
+// filters in the query string as CSV or newline-delimited JSON.
+// This accepts the same filters as GenericFileIndex, plus format=csv
+// or format=ndjson (the default). Unlike the index endpoint, this
+// is not paged. It returns every matching file, with all checksums.
+//
+// GET /member-api/v3/files/manifest
+// GET /admin-api/v3/files/manifest
+func GenericFileManifest(c *gin.Context) {
+	req := api.NewRequest(c)
+	format, err := req.ManifestFormat()
+	if api.AbortIfError(c, err) {
+		return
+	}
+	err = req.ValidateFilters("format")
+	if api.AbortIfError(c, err) {
+		return
+	}
+	query, err := req.GetFilterCollection().ToQuery()
+	if api.AbortIfError(c, err) {
+		return
+	}
+	req.ApplyInstitutionScope(query, &[]*pgmodels.GenericFile{})
+	req.StreamFileManifest(query, format, "file_manifest")
+}
+
+// GenericFileRestore creates and queues a restoration WorkItem for
+// the specified file and returns the WorkItem. This fails with
+// 409/Conflict if other operations are pending on the file.
+//
+// POST /member-api/v3/files/restore/*id
+func GenericFileRestore(c *gin.Context) {
+	req := api.NewRequest(c)
+	gf, err := pgmodels.GenericFileByID(req.Auth.ResourceID)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	_, workItem, err := webui.InitFileRestoration(gf, req.CurrentUser)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	c.JSON(http.StatusCreated, workItem)
+}
+
+// GenericFileInitDelete creates a deletion request for the specified
+// file and emails it to the institution's admins for approval. The file
+// will not be deleted until an admin approves the request. This fails
+// with 409/Conflict if other operations are pending on the file.
+//
+// POST /member-api/v3/files/init_delete/*id
+func GenericFileInitDelete(c *gin.Context) {
+	req := api.NewRequest(c)
+	del, err := webui.NewDeletionForFile(req.Auth.ResourceID, req.CurrentUser, req.BaseURL(), helpers.AuditActor(c))
+	if api.AbortIfError(c, err) {
+		return
+	}
+	initDeletion(c, del)
+}
+
+// fileManifestState returns the file state to include in an object
+// manifest. By default, that's active files only.
+func fileManifestState(c *gin.Context) string {
+	if c.Query("state") == constants.StateDeleted {
+		return constants.StateDeleted
+	}
+	return constants.StateActive
 }
@@ -4,7 +4,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
 	tu "github.com/APTrust/registry/web/testutil"
@@ -125,3 +129,187 @@ func TestGenericFileIndex(t *testing.T) {
 		Expect().Status(http.StatusForbidden)
 
 }
+
+func TestGenericFileIndexCursor(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// Walk all of inst 1's files, five at a time, using
+	// cursor paging. We should see every file exactly once.
+	seen := make(map[int64]bool)
+	resp := tu.Inst1AdminClient.GET("/member-api/v3/files").
+		WithQuery("cursor", "").
+		WithQuery("per_page", 5).
+		WithQuery("sort", "id__asc").
+		Expect().Status(http.StatusOK)
+	list := api.GenericFileViewList{}
+	err := json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	assert.Equal(t, 18, list.Count)
+	assert.Equal(t, "", list.Previous)
+
+	pages := 1
+	for {
+		for _, gf := range list.Results {
+			assert.Equal(t, tu.Inst1User.InstitutionID, gf.InstitutionID)
+			assert.False(t, seen[gf.ID])
+			seen[gf.ID] = true
+		}
+		if list.Next == "" {
+			break
+		}
+		assert.Contains(t, list.Next, "cursor=")
+		assert.NotContains(t, list.Next, "page=")
+		resp = tu.Inst1AdminClient.GET(list.Next).Expect().Status(http.StatusOK)
+		list = api.GenericFileViewList{}
+		err = json.Unmarshal([]byte(resp.Body().Raw()), &list)
+		require.Nil(t, err)
+		pages++
+	}
+	assert.NotEmpty(t, seen)
+	assert.Equal(t, 4, pages)
+
+	// Client can skip the count query.
+	resp = tu.Inst1AdminClient.GET("/member-api/v3/files").
+		WithQuery("cursor", "").
+		WithQuery("skip_count", "true").
+		Expect().Status(http.StatusOK)
+	list = api.GenericFileViewList{}
+	err = json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	assert.Equal(t, -1, list.Count)
+	assert.Equal(t, 18, len(list.Results))
+
+	// Garbage cursors and multi-column sorts are bad requests.
+	tu.Inst1AdminClient.GET("/member-api/v3/files").
+		WithQuery("cursor", "garbage!").
+		Expect().Status(http.StatusBadRequest)
+	tu.Inst1AdminClient.GET("/member-api/v3/files").
+		WithQuery("cursor", "").
+		WithQuery("sort", "size__desc").
+		WithQuery("sort", "identifier__asc").
+		Expect().Status(http.StatusBadRequest).
+		Body().Contains("only one sort column")
+}
+
+func TestGenericFileIndexCursorNullSortColumn(t *testing.T) {
+	require.Nil(t, db.ForceFixtureReload())
+	defer db.ForceFixtureReload()
+	tu.InitHTTPTests(t)
+
+	// Rows with a NULL in the sort column must not drop out
+	// of a cursor walk.
+	files, err := pgmodels.GenericFileSelect(pgmodels.NewQuery().
+		Where("institution_id", "=", tu.Inst1Admin.InstitutionID).
+		OrderBy("id", "asc").
+		Limit(2))
+	require.Nil(t, err)
+	require.Equal(t, 2, len(files))
+	_, err = common.Context().DB.Model((*pgmodels.GenericFile)(nil)).
+		Set("file_format = null").
+		Where("id in (?, ?)", files[0].ID, files[1].ID).
+		Update()
+	require.Nil(t, err)
+
+	for _, direction := range []string{"asc", "desc"} {
+		seen := make(map[int64]bool)
+		next := "/member-api/v3/files?cursor=&per_page=5&sort=file_format__" + direction
+		for next != "" {
+			resp := tu.Inst1AdminClient.GET(next).Expect().Status(http.StatusOK)
+			list := api.GenericFileViewList{}
+			require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), &list))
+			for _, gf := range list.Results {
+				assert.False(t, seen[gf.ID])
+				seen[gf.ID] = true
+			}
+			next = list.Next
+		}
+		assert.Equal(t, 18, len(seen), direction)
+		for _, gf := range files {
+			assert.True(t, seen[gf.ID], direction)
+		}
+	}
+}
+
+func TestGenericFileRestore(t *testing.T) {
+	// Force fixture reload to prevent "pending work item"
+	// error when requesting restoration.
+	require.Nil(t, db.ForceFixtureReload())
+	tu.InitHTTPTests(t)
+
+	// Inst2 user cannot restore inst1's file
+	tu.Inst2UserClient.POST("/member-api/v3/files/restore/{id}", 2).
+		Expect().Status(http.StatusForbidden)
+
+	resp := tu.Inst1UserClient.POST("/member-api/v3/files/restore/{id}", 2).
+		Expect().Status(http.StatusCreated)
+	workItem := &pgmodels.WorkItem{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), workItem))
+	assert.True(t, workItem.ID > 0)
+	assert.Equal(t, int64(2), workItem.GenericFileID)
+	assert.Equal(t, constants.ActionRestoreFile, workItem.Action)
+	assert.False(t, workItem.QueuedAt.IsZero())
+
+	// Second request conflicts with the pending restoration
+	tu.Inst1UserClient.POST("/member-api/v3/files/restore/{id}", 2).
+		Expect().Status(http.StatusConflict)
+}
+
+func TestGenericFileShowConditional(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	gf, err := pgmodels.GenericFileByID(1)
+	require.Nil(t, err)
+
+	resp := tu.Inst1AdminClient.GET("/member-api/v3/files/show/{id}", gf.ID).Expect().Status(http.StatusOK)
+	etag := resp.Header("ETag").Raw()
+	require.NotEmpty(t, etag)
+
+	// Last-Modified accounts for the file's checksums and events,
+	// so it can't be earlier than the file's own UpdatedAt.
+	lastModified, err := http.ParseTime(resp.Header("Last-Modified").Raw())
+	require.Nil(t, err)
+	assert.False(t, lastModified.Before(gf.UpdatedAt.Truncate(time.Second)))
+
+	tu.Inst1AdminClient.GET("/member-api/v3/files/show/{id}", gf.ID).
+		WithHeader("If-None-Match", etag).
+		Expect().Status(http.StatusNotModified)
+	tu.Inst1AdminClient.GET("/member-api/v3/files/show/{id}", gf.ID).
+		WithHeader("If-Modified-Since", resp.Header("Last-Modified").Raw()).
+		Expect().Status(http.StatusNotModified)
+}
+
+func TestGenericFileChanges(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	tu.Inst1AdminClient.GET("/member-api/v3/files/changes").
+		Expect().Status(http.StatusBadRequest)
+
+	seen := make(map[int64]bool)
+	var lastUpdate time.Time
+	url := "/member-api/v3/files/changes?updated_at__gteq=2000-01-01T00:00:00Z&per_page=5"
+	for url != "" {
+		resp := tu.Inst1AdminClient.GET(url).Expect().Status(http.StatusOK)
+		list := api.GenericFileViewList{}
+		require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), &list))
+		for _, gf := range list.Results {
+			assert.Equal(t, tu.Inst1Admin.InstitutionID, gf.InstitutionID)
+			assert.False(t, seen[gf.ID])
+			assert.False(t, gf.UpdatedAt.Before(lastUpdate))
+			seen[gf.ID] = true
+			lastUpdate = gf.UpdatedAt
+		}
+		url = list.Next
+	}
+	assert.NotEmpty(t, seen)
+
+	// Changes feeds accept the same filters as the index.
+	resp := tu.Inst1AdminClient.GET("/member-api/v3/files/changes").
+		WithQuery("updated_at__gteq", "2000-01-01T00:00:00Z").
+		WithQuery("intellectual_object_id", 1).
+		Expect().Status(http.StatusOK)
+	list := api.GenericFileViewList{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), &list))
+	for _, gf := range list.Results {
+		assert.EqualValues(t, 1, gf.IntellectualObjectID)
+	}
+}
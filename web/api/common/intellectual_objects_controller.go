@@ -1,13 +1,32 @@
 package common_api
 
 import (
+	"fmt"
 	"net/http"
 
+	"github.com/APTrust/registry/helpers"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
+	"github.com/APTrust/registry/web/webui"
 	"github.com/gin-gonic/gin"
 )
 
+// IntellectualObjectChanges returns objects that changed at or after
+// the time in the required updated_at__gteq param, oldest first, using
+// cursor paging. See api.Request.LoadChanges.
+//
+// GET /member-api/v3/objects/changes
+// GET /admin-api/v3/objects/changes
+func IntellectualObjectChanges(c *gin.Context) {
+	req := api.NewRequest(c)
+	var objs []*pgmodels.IntellectualObjectView
+	pager, err := req.LoadChanges(&objs)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	api.ConditionalJSON(c, api.NewJsonList(objs, pager))
+}
+
 // IntellectualObjectIndex shows list of objects.
 //
 // GET /member-api/v3/objects
@@ -19,7 +38,11 @@ func IntellectualObjectIndex(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, api.NewJsonList(objs, pager))
+	err = pgmodels.AddSearchSnippets(objs, c.Query("q"))
+	if api.AbortIfError(c, err) {
+		return
+	}
+	api.ConditionalJSON(c, api.NewJsonList(objs, pager))
 }
 
 // IntellectualObjectShow returns the object with the specified id.
@@ -32,5 +55,61 @@ func IntellectualObjectShow(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, obj)
+	api.ConditionalJSON(c, obj)
+}
+
+// IntellectualObjectManifest streams a manifest of all of an object's
+// active files as CSV or newline-delimited JSON, including size, format,
+// storage option, UUID, mtime and all checksums. Use format=csv or
+// format=ndjson (the default). Use state=D to get deleted files instead
+// of active files.
+//
+// GET /member-api/v3/objects/manifest/*id
+// GET /admin-api/v3/objects/manifest/*id
+func IntellectualObjectManifest(c *gin.Context) {
+	req := api.NewRequest(c)
+	format, err := req.ManifestFormat()
+	if api.AbortIfError(c, err) {
+		return
+	}
+	query := pgmodels.NewQuery().
+		Where("intellectual_object_id", "=", req.Auth.ResourceID).
+		Where("state", "=", fileManifestState(c))
+	req.ApplyInstitutionScope(query, &[]*pgmodels.GenericFile{})
+	req.StreamFileManifest(query, format, fmt.Sprintf("object_%d_manifest", req.Auth.ResourceID))
+}
+
+// IntellectualObjectRestore creates and queues a restoration WorkItem
+// for the specified object and returns the WorkItem. Objects stored
+// only in Glacier go to the Glacier restore queue. This fails with
+// 409/Conflict if other operations are pending on the object.
+//
+// POST /member-api/v3/objects/restore/*id
+func IntellectualObjectRestore(c *gin.Context) {
+	req := api.NewRequest(c)
+	obj, err := pgmodels.IntellectualObjectByID(req.Auth.ResourceID)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	workItem, err := webui.InitObjectRestoration(obj, req.CurrentUser)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	c.JSON(http.StatusCreated, workItem)
+}
+
+// IntellectualObjectInitDelete creates a deletion request for the
+// specified object and emails it to the institution's admins for
+// approval. The object will not be deleted until an admin approves
+// the request. This fails with 409/Conflict if other operations are
+// pending on the object.
+//
+// POST /member-api/v3/objects/init_delete/*id
+func IntellectualObjectInitDelete(c *gin.Context) {
+	req := api.NewRequest(c)
+	del, err := webui.NewDeletionForObject(req.Auth.ResourceID, req.CurrentUser, req.BaseURL(), helpers.AuditActor(c))
+	if api.AbortIfError(c, err) {
+		return
+	}
+	initDeletion(c, del)
 }
@@ -1,11 +1,17 @@
 package common_api_test
 
 import (
+	"bufio"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
 	tu "github.com/APTrust/registry/web/testutil"
@@ -123,3 +129,227 @@ func TestIntellectualObjectIndex(t *testing.T) {
 		Expect().Status(http.StatusForbidden)
 
 }
+
+func TestIntellectualObjectSearch(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// "glacier deep" matches both words in the titles of objects
+	// 9 and 12, but object 12 belongs to another institution.
+	resp := tu.Inst1AdminClient.GET("/member-api/v3/objects").
+		WithQuery("q", "glacier deep").
+		Expect().Status(http.StatusOK)
+	list := api.IntellectualObjectList{}
+	err := json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	require.Equal(t, 1, list.Count)
+	require.Equal(t, 1, len(list.Results))
+	assert.Equal(t, "institution1.edu/gl-dp-oh", list.Results[0].Identifier)
+	assert.True(t, list.Results[0].SearchRank > 0)
+	assert.Contains(t, list.Results[0].SearchSnippet, "<mark>")
+
+	// Without an explicit sort, best matches come first.
+	resp = tu.SysAdminClient.GET("/member-api/v3/objects").
+		WithQuery("q", "glacier or chocolate").
+		Expect().Status(http.StatusOK)
+	list = api.IntellectualObjectList{}
+	err = json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	require.Equal(t, 4, list.Count)
+	for i := 1; i < len(list.Results); i++ {
+		assert.True(t, list.Results[i-1].SearchRank >= list.Results[i].SearchRank)
+	}
+
+	// Explicit sort overrides ranking.
+	resp = tu.SysAdminClient.GET("/member-api/v3/objects").
+		WithQuery("q", "glacier or chocolate").
+		WithQuery("sort", "id__desc").
+		Expect().Status(http.StatusOK)
+	list = api.IntellectualObjectList{}
+	err = json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	require.Equal(t, 4, len(list.Results))
+	assert.Equal(t, int64(12), list.Results[0].ID)
+}
+
+func TestIntellectualObjectManifest(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// Object 3 belongs to inst1 and has 4 active files
+	// and one deleted file. Default format is NDJSON.
+	resp := tu.Inst1UserClient.GET("/member-api/v3/objects/manifest/{id}", 3).
+		Expect().Status(http.StatusOK)
+	resp.Header("Content-Type").Equal("application/x-ndjson")
+	scanner := bufio.NewScanner(strings.NewReader(resp.Body().Raw()))
+	entries := make([]*pgmodels.FileManifestEntry, 0)
+	for scanner.Scan() {
+		entry := &pgmodels.FileManifestEntry{}
+		require.Nil(t, json.Unmarshal(scanner.Bytes(), entry))
+		entries = append(entries, entry)
+	}
+	require.Equal(t, 4, len(entries))
+	for _, entry := range entries {
+		assert.Equal(t, int64(3), entry.IntellectualObjectID)
+		assert.Equal(t, constants.StateActive, entry.State)
+		assert.NotEmpty(t, entry.UUID)
+	}
+
+	// Deleted files only
+	resp = tu.Inst1UserClient.GET("/member-api/v3/objects/manifest/{id}", 3).
+		WithQuery("state", "D").
+		WithQuery("format", "csv").
+		Expect().Status(http.StatusOK)
+	resp.Header("Content-Type").Equal("text/csv; charset=utf-8")
+	records, err := csv.NewReader(strings.NewReader(resp.Body().Raw())).ReadAll()
+	require.Nil(t, err)
+	require.Equal(t, 2, len(records))
+	assert.Equal(t, pgmodels.FileManifestColumns, records[0])
+	assert.Equal(t, "institution1.edu/glass/shard4_deleted", records[1][1])
+
+	// Bad format
+	tu.Inst1UserClient.GET("/member-api/v3/objects/manifest/{id}", 3).
+		WithQuery("format", "xml").
+		Expect().Status(http.StatusBadRequest)
+
+	// Other institutions can't see this manifest
+	tu.Inst2AdminClient.GET("/member-api/v3/objects/manifest/{id}", 3).
+		Expect().Status(http.StatusForbidden)
+}
+
+func TestGenericFileManifest(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// Inst user sees only files from own institution.
+	resp := tu.Inst1UserClient.GET("/member-api/v3/files/manifest").
+		WithQuery("format", "csv").
+		Expect().Status(http.StatusOK)
+	records, err := csv.NewReader(strings.NewReader(resp.Body().Raw())).ReadAll()
+	require.Nil(t, err)
+	assert.Equal(t, 19, len(records)) // header + 18 files
+	for _, record := range records[1:] {
+		assert.Equal(t, fmt.Sprintf("%d", tu.Inst1User.InstitutionID), record[3])
+	}
+
+	// Filters work as they do in the files index.
+	resp = tu.SysAdminClient.GET("/member-api/v3/files/manifest").
+		WithQuery("intellectual_object_id", 3).
+		WithQuery("state", "A").
+		Expect().Status(http.StatusOK)
+	lines := strings.Split(strings.TrimSpace(resp.Body().Raw()), "\n")
+	assert.Equal(t, 4, len(lines))
+
+	// Invalid filters are rejected.
+	tu.Inst1UserClient.GET("/member-api/v3/files/manifest").
+		WithQuery("no_such_filter", "true").
+		Expect().Status(http.StatusInternalServerError)
+}
+
+func TestIntellectualObjectRestore(t *testing.T) {
+	// Force fixture reload to prevent "pending work item"
+	// error when requesting restoration.
+	require.Nil(t, db.ForceFixtureReload())
+	tu.InitHTTPTests(t)
+
+	// Inst2 user cannot restore inst1's object
+	tu.Inst2UserClient.POST("/member-api/v3/objects/restore/{id}", 2).
+		Expect().Status(http.StatusForbidden)
+
+	resp := tu.Inst1UserClient.POST("/member-api/v3/objects/restore/{id}", 2).
+		Expect().Status(http.StatusCreated)
+	workItem := &pgmodels.WorkItem{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), workItem))
+	assert.True(t, workItem.ID > 0)
+	assert.Equal(t, int64(2), workItem.IntellectualObjectID)
+	assert.Equal(t, constants.ActionRestoreObject, workItem.Action)
+	assert.False(t, workItem.QueuedAt.IsZero())
+
+	// Second request conflicts with the pending restoration
+	tu.Inst1UserClient.POST("/member-api/v3/objects/restore/{id}", 2).
+		Expect().Status(http.StatusConflict)
+}
+
+func TestIntellectualObjectShowConditional(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	obj, err := pgmodels.IntellectualObjectByID(1)
+	require.Nil(t, err)
+
+	resp := tu.Inst1AdminClient.GET("/member-api/v3/objects/show/{id}", obj.ID).Expect().Status(http.StatusOK)
+	etag := resp.Header("ETag").Raw()
+	lastModified := resp.Header("Last-Modified").Raw()
+	require.NotEmpty(t, etag)
+	require.NotEmpty(t, lastModified)
+
+	// Client has the current version.
+	tu.Inst1AdminClient.GET("/member-api/v3/objects/show/{id}", obj.ID).
+		WithHeader("If-None-Match", etag).
+		Expect().Status(http.StatusNotModified).
+		Body().IsEmpty()
+	tu.Inst1AdminClient.GET("/member-api/v3/objects/show/{id}", obj.ID).
+		WithHeader("If-Modified-Since", lastModified).
+		Expect().Status(http.StatusNotModified)
+
+	// Client has an old version.
+	tu.Inst1AdminClient.GET("/member-api/v3/objects/show/{id}", obj.ID).
+		WithHeader("If-None-Match", `"not-the-current-etag"`).
+		Expect().Status(http.StatusOK)
+	tu.Inst1AdminClient.GET("/member-api/v3/objects/show/{id}", obj.ID).
+		WithHeader("If-Modified-Since", "Mon, 01 Jan 2001 00:00:00 GMT").
+		Expect().Status(http.StatusOK)
+
+	// 304 doesn't leak records across institutions.
+	tu.Inst2AdminClient.GET("/member-api/v3/objects/show/{id}", obj.ID).
+		WithHeader("If-None-Match", etag).
+		Expect().Status(http.StatusForbidden)
+
+	// Lists support If-None-Match too.
+	resp = tu.Inst1AdminClient.GET("/member-api/v3/objects").Expect().Status(http.StatusOK)
+	listETag := resp.Header("ETag").Raw()
+	require.NotEmpty(t, listETag)
+	tu.Inst1AdminClient.GET("/member-api/v3/objects").
+		WithHeader("If-None-Match", listETag).
+		Expect().Status(http.StatusNotModified)
+}
+
+func TestIntellectualObjectChanges(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// updated_at__gteq is required and sort is not allowed.
+	tu.Inst1AdminClient.GET("/member-api/v3/objects/changes").
+		Expect().Status(http.StatusBadRequest)
+	tu.Inst1AdminClient.GET("/member-api/v3/objects/changes").
+		WithQuery("updated_at__gteq", "2000-01-01T00:00:00Z").
+		WithQuery("sort", "identifier__asc").
+		Expect().Status(http.StatusBadRequest)
+
+	// Walk the whole feed, two at a time. Items come oldest first.
+	seen := make(map[int64]bool)
+	var lastUpdate time.Time
+	url := "/member-api/v3/objects/changes?updated_at__gteq=2000-01-01T00:00:00Z&per_page=2"
+	for url != "" {
+		resp := tu.Inst1AdminClient.GET(url).Expect().Status(http.StatusOK)
+		list := api.IntellectualObjectList{}
+		require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), &list))
+		for _, obj := range list.Results {
+			assert.Equal(t, tu.Inst1Admin.InstitutionID, obj.InstitutionID)
+			assert.False(t, seen[obj.ID])
+			assert.False(t, obj.UpdatedAt.Before(lastUpdate))
+			seen[obj.ID] = true
+			lastUpdate = obj.UpdatedAt
+		}
+		url = list.Next
+	}
+	assert.True(t, len(seen) > 2)
+
+	// After we touch an object, a feed starting now includes it.
+	since := time.Now().UTC().Add(-1 * time.Second)
+	obj, err := pgmodels.IntellectualObjectByID(1)
+	require.Nil(t, err)
+	require.Nil(t, obj.Save())
+	resp := tu.Inst1AdminClient.GET("/member-api/v3/objects/changes").
+		WithQuery("updated_at__gteq", since.Format(time.RFC3339)).
+		Expect().Status(http.StatusOK)
+	list := api.IntellectualObjectList{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), &list))
+	require.Equal(t, 1, len(list.Results))
+	assert.Equal(t, obj.ID, list.Results[0].ID)
+}
@@ -0,0 +1,35 @@
+package common_api
+
+import (
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/api"
+	"github.com/gin-gonic/gin"
+)
+
+// LegalHoldShow returns the legal hold with the specified id.
+//
+// GET /member-api/v3/legal_holds/show/:id
+// GET /admin-api/v3/legal_holds/show/:id
+func LegalHoldShow(c *gin.Context) {
+	req := api.NewRequest(c)
+	hold, err := pgmodels.LegalHoldViewByID(req.Auth.ResourceID)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	api.ConditionalJSON(c, hold)
+}
+
+// LegalHoldIndex returns a list of legal holds, newest first.
+// Non-admins see only holds at their own institution.
+//
+// GET /member-api/v3/legal_holds
+// GET /admin-api/v3/legal_holds
+func LegalHoldIndex(c *gin.Context) {
+	req := api.NewRequest(c)
+	var holds []*pgmodels.LegalHoldView
+	pager, err := req.LoadResourceList(&holds, "created_at", "desc")
+	if api.AbortIfError(c, err) {
+		return
+	}
+	api.ConditionalJSON(c, api.NewJsonList(holds, pager))
+}
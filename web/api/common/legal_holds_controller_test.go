@@ -0,0 +1,71 @@
+package common_api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/api"
+	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegalHoldShow(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// Sysadmin and all users at the owning institution can see the hold.
+	for _, client := range []*httpexpect.Expect{tu.SysAdminClient, tu.Inst1AdminClient, tu.Inst1UserClient} {
+		resp := client.GET("/member-api/v3/legal_holds/show/{id}", 1).Expect().Status(http.StatusOK)
+		record := &pgmodels.LegalHoldView{}
+		err := json.Unmarshal([]byte(resp.Body().Raw()), record)
+		require.Nil(t, err)
+		assert.Equal(t, int64(1), record.ID)
+		assert.Equal(t, int64(10), record.IntellectualObjectID)
+		assert.Equal(t, "admin@inst1.edu", record.PlacedByEmail)
+		assert.True(t, record.Active)
+	}
+
+	// Users at other institutions cannot.
+	tu.Inst2AdminClient.GET("/member-api/v3/legal_holds/show/{id}", 1).
+		Expect().Status(http.StatusForbidden)
+	tu.Inst2UserClient.GET("/member-api/v3/legal_holds/show/{id}", 1).
+		Expect().Status(http.StatusForbidden)
+
+	// Admin API returns the same data.
+	tu.SysAdminClient.GET("/admin-api/v3/legal_holds/show/{id}", 1).
+		Expect().Status(http.StatusOK)
+}
+
+func TestLegalHoldIndex(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	resp := tu.SysAdminClient.GET("/member-api/v3/legal_holds").
+		Expect().Status(http.StatusOK)
+	list := api.LegalHoldViewList{}
+	err := json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	assert.Equal(t, 4, list.Count)
+
+	resp = tu.SysAdminClient.GET("/admin-api/v3/legal_holds").
+		WithQuery("active", "true").
+		Expect().Status(http.StatusOK)
+	list = api.LegalHoldViewList{}
+	err = json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	require.Equal(t, 2, list.Count)
+	for _, hold := range list.Results {
+		assert.True(t, hold.Active)
+	}
+
+	// Non-admins see only their own institution's holds.
+	resp = tu.Inst2UserClient.GET("/member-api/v3/legal_holds").
+		Expect().Status(http.StatusOK)
+	list = api.LegalHoldViewList{}
+	err = json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	require.Equal(t, 1, list.Count)
+	assert.Equal(t, int64(4), list.Results[0].ID)
+}
@@ -0,0 +1,75 @@
+package common_api
+
+import (
+	"strconv"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/api"
+	"github.com/gin-gonic/gin"
+)
+
+// ObjectVersionIndex returns a list of object versions. Filter on
+// intellectual_object_id to get the version history of one object.
+//
+// GET /member-api/v3/object_versions
+// GET /admin-api/v3/object_versions
+func ObjectVersionIndex(c *gin.Context) {
+	req := api.NewRequest(c)
+	var versions []*pgmodels.ObjectVersion
+	pager, err := req.LoadResourceList(&versions, "version_number", "desc")
+	if api.AbortIfError(c, err) {
+		return
+	}
+	api.ConditionalJSON(c, api.NewJsonList(versions, pager))
+}
+
+// ObjectVersionShow returns the object version with the specified id,
+// including its files.
+//
+// GET /member-api/v3/object_versions/show/:id
+// GET /admin-api/v3/object_versions/show/:id
+func ObjectVersionShow(c *gin.Context) {
+	req := api.NewRequest(c)
+	version, err := pgmodels.ObjectVersionByID(req.Auth.ResourceID)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	err = version.LoadFiles()
+	if api.AbortIfError(c, err) {
+		return
+	}
+	api.ConditionalJSON(c, version)
+}
+
+// ObjectVersionDiff returns the files added, removed and changed
+// between the version in the from param and the version with the
+// specified id. If from is missing, this diffs against the previous
+// version of the same object.
+//
+// GET /member-api/v3/object_versions/diff/:id
+// GET /admin-api/v3/object_versions/diff/:id
+func ObjectVersionDiff(c *gin.Context) {
+	req := api.NewRequest(c)
+	fromID, err := objectVersionFromParam(c)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	diff, err := pgmodels.ObjectVersionDiffFor(fromID, req.Auth.ResourceID)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	api.ConditionalJSON(c, diff)
+}
+
+func objectVersionFromParam(c *gin.Context) (int64, error) {
+	from := c.Query("from")
+	if from == "" {
+		return 0, nil
+	}
+	fromID, err := strconv.ParseInt(from, 10, 64)
+	if err != nil {
+		return 0, common.ErrWrongDataType
+	}
+	return fromID, nil
+}
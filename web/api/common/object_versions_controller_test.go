@@ -0,0 +1,91 @@
+package common_api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/api"
+	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectVersionIndex(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	resp := tu.Inst1UserClient.GET("/member-api/v3/object_versions").
+		WithQuery("intellectual_object_id", 1).
+		Expect().Status(http.StatusOK)
+	list := api.ObjectVersionList{}
+	err := json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	require.Equal(t, 1, list.Count)
+	assert.Equal(t, int64(1), list.Results[0].IntellectualObjectID)
+	assert.Equal(t, 1, list.Results[0].VersionNumber)
+	assert.Empty(t, list.Results[0].Files)
+
+	// Non-admins see only their own institution's versions.
+	resp = tu.Inst2UserClient.GET("/member-api/v3/object_versions").
+		Expect().Status(http.StatusOK)
+	err = json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	assert.Equal(t, 0, list.Count)
+
+	tu.Inst2UserClient.GET("/member-api/v3/object_versions").
+		WithQuery("institution_id", tu.Inst1User.InstitutionID).
+		Expect().Status(http.StatusForbidden)
+
+	resp = tu.SysAdminClient.GET("/admin-api/v3/object_versions").
+		Expect().Status(http.StatusOK)
+	err = json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	assert.Equal(t, 3, list.Count)
+}
+
+func TestObjectVersionShow(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	for _, client := range []*httpexpect.Expect{tu.SysAdminClient, tu.Inst1AdminClient, tu.Inst1UserClient} {
+		resp := client.GET("/member-api/v3/object_versions/show/{id}", 1).
+			Expect().Status(http.StatusOK)
+		version := &pgmodels.ObjectVersion{}
+		err := json.Unmarshal([]byte(resp.Body().Raw()), version)
+		require.Nil(t, err)
+		assert.Equal(t, int64(1), version.ID)
+		require.Equal(t, 3, len(version.Files))
+		assert.Equal(t, "institution1.edu/photos/picture1", version.Files[0].Identifier)
+		assert.Equal(t, "9876543210", version.Files[0].Sha256)
+	}
+	tu.Inst2AdminClient.GET("/member-api/v3/object_versions/show/{id}", 1).
+		Expect().Status(http.StatusForbidden)
+}
+
+func TestObjectVersionDiff(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// No previous version, so everything was added.
+	resp := tu.Inst1UserClient.GET("/member-api/v3/object_versions/diff/{id}", 1).
+		Expect().Status(http.StatusOK)
+	diff := &pgmodels.ObjectVersionDiff{}
+	err := json.Unmarshal([]byte(resp.Body().Raw()), diff)
+	require.Nil(t, err)
+	assert.Nil(t, diff.FromVersion)
+	assert.Equal(t, int64(1), diff.ToVersion.ID)
+	assert.Equal(t, 3, len(diff.Added))
+	assert.Empty(t, diff.Removed)
+	assert.Empty(t, diff.Changed)
+
+	// Versions of different objects can't be compared.
+	tu.Inst1UserClient.GET("/member-api/v3/object_versions/diff/{id}", 1).
+		WithQuery("from", 2).
+		Expect().Status(http.StatusBadRequest)
+	tu.Inst1UserClient.GET("/member-api/v3/object_versions/diff/{id}", 1).
+		WithQuery("from", "two").
+		Expect().Status(http.StatusBadRequest)
+
+	tu.Inst2UserClient.GET("/member-api/v3/object_versions/diff/{id}", 1).
+		Expect().Status(http.StatusForbidden)
+}
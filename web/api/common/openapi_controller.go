@@ -0,0 +1,30 @@
+package common_api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/web/api"
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPISpec returns the OpenAPI 3 document for the API that
+// received the request. We generate the document from the running
+// app's routes, so it always matches what this Registry serves.
+// The swagger UI at /swagger/ loads this document.
+//
+// GET /member-api/v3/openapi.json
+// GET /admin-api/v3/openapi.json
+func OpenAPISpec(c *gin.Context) {
+	prefix := constants.APIPrefixMember
+	if strings.HasPrefix(c.FullPath(), constants.APIPrefixAdmin) {
+		prefix = constants.APIPrefixAdmin
+	}
+	doc, err := api.OpenAPISpecFor(prefix)
+	if err != nil {
+		common.Context().Log.Warn().Msgf("OpenAPISpec: %v", err)
+	}
+	c.JSON(http.StatusOK, doc)
+}
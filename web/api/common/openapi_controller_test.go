@@ -0,0 +1,39 @@
+package common_api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/APTrust/registry/web/api"
+	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPISpec(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// All users can read the member API spec.
+	for _, client := range tu.AllClients {
+		resp := client.GET("/member-api/v3/openapi.json").Expect().Status(http.StatusOK)
+		doc := &api.OpenAPIDoc{}
+		err := json.Unmarshal([]byte(resp.Body().Raw()), doc)
+		require.Nil(t, err)
+		assert.Equal(t, api.OpenAPIVersion, doc.OpenAPI)
+		assert.Equal(t, "/member-api/v3", doc.Servers[0].URL)
+		assert.NotNil(t, doc.Paths["/objects"]["get"])
+		assert.Nil(t, doc.Paths["/objects/create/{institution_id}"])
+	}
+
+	// Only APTrust admins can read the admin API spec.
+	resp := tu.SysAdminClient.GET("/admin-api/v3/openapi.json").Expect().Status(http.StatusOK)
+	doc := &api.OpenAPIDoc{}
+	err := json.Unmarshal([]byte(resp.Body().Raw()), doc)
+	require.Nil(t, err)
+	assert.Equal(t, "/admin-api/v3", doc.Servers[0].URL)
+	assert.NotNil(t, doc.Paths["/objects/create/{institution_id}"]["post"])
+
+	tu.Inst1AdminClient.GET("/admin-api/v3/openapi.json").Expect().Status(http.StatusForbidden)
+	tu.Inst1UserClient.GET("/admin-api/v3/openapi.json").Expect().Status(http.StatusForbidden)
+}
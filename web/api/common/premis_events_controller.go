@@ -1,13 +1,27 @@
 package common_api
 
 import (
-	"net/http"
-
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
 	"github.com/gin-gonic/gin"
 )
 
+// PremisEventChanges returns events that changed at or after the time
+// in the required updated_at__gteq param, oldest first, using cursor
+// paging. See api.Request.LoadChanges.
+//
+// GET /member-api/v3/events/changes
+// GET /admin-api/v3/events/changes
+func PremisEventChanges(c *gin.Context) {
+	req := api.NewRequest(c)
+	var events []*pgmodels.PremisEventView
+	pager, err := req.LoadChanges(&events)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	api.ConditionalJSON(c, api.NewJsonList(events, pager))
+}
+
 // PremisEventIndex shows list of objects.
 //
 // GET /member-api/v3/events
@@ -19,7 +33,7 @@ func PremisEventIndex(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, api.NewJsonList(events, pager))
+	api.ConditionalJSON(c, api.NewJsonList(events, pager))
 }
 
 // PremisEventShow returns the object with the specified id.
@@ -32,5 +46,5 @@ func PremisEventShow(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, gf)
+	api.ConditionalJSON(c, gf)
 }
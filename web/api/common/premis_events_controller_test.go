@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
@@ -132,3 +133,32 @@ func TestPremisEventIndex(t *testing.T) {
 		Expect().Status(http.StatusForbidden)
 
 }
+
+func TestPremisEventChanges(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	tu.Inst1AdminClient.GET("/member-api/v3/events/changes").
+		Expect().Status(http.StatusBadRequest)
+
+	resp := tu.Inst1AdminClient.GET("/member-api/v3/events/changes").
+		WithQuery("updated_at__gteq", "2000-01-01T00:00:00Z").
+		WithQuery("per_page", 100).
+		Expect().Status(http.StatusOK)
+	list := api.PremisEventViewList{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), &list))
+	require.NotEmpty(t, list.Results)
+	for i, event := range list.Results {
+		assert.Equal(t, tu.Inst1Admin.InstitutionID, event.InstitutionID)
+		if i > 0 {
+			assert.False(t, event.UpdatedAt.Before(list.Results[i-1].UpdatedAt))
+		}
+	}
+
+	// Nothing has changed in the future.
+	resp = tu.Inst1AdminClient.GET("/member-api/v3/events/changes").
+		WithQuery("updated_at__gteq", time.Now().UTC().Add(time.Hour).Format(time.RFC3339)).
+		Expect().Status(http.StatusOK)
+	list = api.PremisEventViewList{}
+	require.Nil(t, json.Unmarshal([]byte(resp.Body().Raw()), &list))
+	assert.Empty(t, list.Results)
+}
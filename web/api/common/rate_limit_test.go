@@ -0,0 +1,118 @@
+package common_api_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enableRateLimits turns on rate limiting with tiny limits for the
+// duration of a test. Call the returned function to restore the
+// original config.
+func enableRateLimits(userBurst, instBurst int) func() {
+	ctx := common.Context()
+	original := *ctx.Config.RateLimit
+	ctx.Config.RateLimit.Enabled = true
+	ctx.Config.RateLimit.UserPerMinute = 1
+	ctx.Config.RateLimit.UserBurst = userBurst
+	ctx.Config.RateLimit.InstitutionPerMinute = 1
+	ctx.Config.RateLimit.InstitutionBurst = instBurst
+	ctx.RateLimiter.Reset()
+	pgmodels.ClearRateLimitOverrideCache()
+	return func() {
+		*ctx.Config.RateLimit = original
+		ctx.RateLimiter.Reset()
+	}
+}
+
+func TestRateLimitPerUser(t *testing.T) {
+	require.Nil(t, db.ForceFixtureReload())
+	tu.InitHTTPTests(t)
+	defer enableRateLimits(2, 10)()
+
+	for i := 1; i >= 0; i-- {
+		resp := tu.Inst1UserClient.GET("/member-api/v3/objects").Expect().Status(http.StatusOK)
+		resp.Header(constants.RateLimitLimitHeader).Equal("2")
+		resp.Header(constants.RateLimitRemainingHeader).Equal(strconv.Itoa(i))
+		resp.Header(constants.RateLimitResetHeader).NotEmpty()
+	}
+
+	resp := tu.Inst1UserClient.GET("/member-api/v3/objects").Expect().Status(http.StatusTooManyRequests)
+	resp.Header("Retry-After").Equal("60")
+	resp.Header(constants.RateLimitRemainingHeader).Equal("0")
+	resp.JSON().Object().Value("StatusCode").Equal(http.StatusTooManyRequests)
+
+	// Other users at the same institution have their own bucket.
+	tu.Inst1AdminClient.GET("/member-api/v3/objects").Expect().Status(http.StatusOK)
+
+	// Web UI requests are not limited.
+	tu.Inst1UserClient.GET("/objects").Expect().Status(http.StatusOK)
+
+	// The counters show up in the stats.
+	var userStats common.RateLimitStats
+	for _, stats := range common.Context().RateLimiter.Stats() {
+		if stats.Name == tu.Inst1User.Email {
+			userStats = stats
+		}
+	}
+	assert.Equal(t, int64(2), userStats.Allowed)
+	assert.Equal(t, int64(1), userStats.Limited)
+}
+
+func TestRateLimitPerInstitution(t *testing.T) {
+	require.Nil(t, db.ForceFixtureReload())
+	tu.InitHTTPTests(t)
+	defer enableRateLimits(10, 2)()
+
+	tu.Inst1UserClient.GET("/member-api/v3/objects").Expect().Status(http.StatusOK)
+	tu.Inst1AdminClient.GET("/member-api/v3/objects").Expect().Status(http.StatusOK)
+
+	// Both users share the institution's bucket, which is now empty.
+	tu.Inst1UserClient.GET("/member-api/v3/objects").Expect().Status(http.StatusTooManyRequests)
+	tu.Inst1AdminClient.GET("/member-api/v3/objects").Expect().Status(http.StatusTooManyRequests)
+
+	// Users at other institutions are not affected.
+	tu.Inst2UserClient.GET("/member-api/v3/objects").Expect().Status(http.StatusOK)
+}
+
+func TestRateLimitOverrides(t *testing.T) {
+	require.Nil(t, db.ForceFixtureReload())
+	tu.InitHTTPTests(t)
+	defer enableRateLimits(1, 1)()
+
+	// Preservation services accounts are exempt from all limits,
+	// including their institution's.
+	exempt := &pgmodels.RateLimitOverride{
+		InstitutionID: tu.SysAdmin.InstitutionID,
+		UserID:        tu.SysAdmin.ID,
+		Exempt:        true,
+		Note:          "Preservation services",
+	}
+	require.Nil(t, exempt.Save())
+	for i := 0; i < 5; i++ {
+		resp := tu.SysAdminClient.GET("/admin-api/v3/objects").Expect().Status(http.StatusOK)
+		resp.Header(constants.RateLimitLimitHeader).Empty()
+	}
+
+	// Institution override raises the shared limit.
+	instOverride := &pgmodels.RateLimitOverride{
+		InstitutionID: tu.Inst2User.InstitutionID,
+		PerMinute:     60,
+		Burst:         3,
+	}
+	require.Nil(t, instOverride.Save())
+	tu.Inst2UserClient.GET("/member-api/v3/objects").Expect().Status(http.StatusOK).
+		Header(constants.RateLimitLimitHeader).Equal("1")
+	tu.Inst2AdminClient.GET("/member-api/v3/objects").Expect().Status(http.StatusOK)
+
+	// Each user still has their own default limit.
+	tu.Inst2AdminClient.GET("/member-api/v3/objects").Expect().Status(http.StatusTooManyRequests)
+}
@@ -1,8 +1,6 @@
 package common_api
 
 import (
-	"net/http"
-
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
 	"github.com/gin-gonic/gin"
@@ -19,7 +17,7 @@ func WorkItemIndex(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, api.NewJsonList(items, pager))
+	api.ConditionalJSON(c, api.NewJsonList(items, pager))
 }
 
 // WorkItemShow returns the object with the specified id.
@@ -32,5 +30,19 @@ func WorkItemShow(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
-	c.JSON(http.StatusOK, item)
+	api.ConditionalJSON(c, item)
+}
+
+// WorkItemHistory returns the transition history of the WorkItem with
+// the specified id, along with the time it spent in each stage.
+//
+// GET /member-api/v3/items/history/:id
+// GET /admin-api/v3/items/history/:id
+func WorkItemHistory(c *gin.Context) {
+	req := api.NewRequest(c)
+	history, err := pgmodels.WorkItemHistoryFor(req.Auth.ResourceID)
+	if api.AbortIfError(c, err) {
+		return
+	}
+	api.ConditionalJSON(c, history)
 }
@@ -5,9 +5,11 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/APTrust/registry/constants"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/APTrust/registry/web/api"
 	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/gavv/httpexpect/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -53,6 +55,31 @@ func TestWorkItemShow(t *testing.T) {
 
 }
 
+func TestWorkItemHistory(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// Item 26 belongs to inst 2 and has transition fixtures.
+	for _, client := range []*httpexpect.Expect{tu.SysAdminClient, tu.Inst2AdminClient, tu.Inst2UserClient} {
+		resp := client.GET("/member-api/v3/items/history/26").Expect().Status(http.StatusOK)
+		history := &pgmodels.WorkItemHistory{}
+		err := json.Unmarshal([]byte(resp.Body().Raw()), history)
+		require.Nil(t, err)
+		assert.EqualValues(t, 26, history.WorkItemID)
+		assert.Equal(t, 12, len(history.Transitions))
+		require.Equal(t, 5, len(history.StageDurations))
+		assert.Equal(t, constants.StageStore, history.StageDurations[2].Stage)
+		assert.EqualValues(t, 9000, history.StageDurations[2].DurationSeconds)
+		assert.Equal(t, 2, history.StageDurations[2].Attempts)
+	}
+
+	// Sys admin can also use the admin API.
+	tu.SysAdminClient.GET("/admin-api/v3/items/history/26").Expect().Status(http.StatusOK)
+
+	// Other institutions can't see this item's history.
+	tu.Inst1AdminClient.GET("/member-api/v3/items/history/26").Expect().Status(http.StatusForbidden)
+	tu.Inst1UserClient.GET("/member-api/v3/items/history/26").Expect().Status(http.StatusForbidden)
+}
+
 func TestWorkItemIndex(t *testing.T) {
 	tu.InitHTTPTests(t)
 
@@ -122,3 +149,62 @@ func TestWorkItemIndex(t *testing.T) {
 		Expect().Status(http.StatusForbidden)
 
 }
+
+func TestWorkItemIndexOrGroups(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// (stage = Record OR stage = Format Identification) AND status = Pending
+	resp := tu.SysAdminClient.GET("/member-api/v3/items").
+		WithQuery("or.stage", constants.StageRecord).
+		WithQuery("or.stage", constants.StageFormatIdentification).
+		WithQuery("status", constants.StatusPending).
+		Expect().Status(http.StatusOK)
+	list := api.WorkItemViewList{}
+	err := json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	require.True(t, list.Count >= 2)
+	ids := make([]int64, 0)
+	for _, item := range list.Results {
+		assert.Contains(t, []string{constants.StageRecord, constants.StageFormatIdentification}, item.Stage)
+		assert.Equal(t, constants.StatusPending, item.Status)
+		ids = append(ids, item.ID)
+	}
+	assert.Contains(t, ids, int64(27))
+	assert.Contains(t, ids, int64(29))
+
+	// Two groups: (action = Delete OR action = Restore Object) AND
+	// (status = Pending OR needs_admin_review = true)
+	resp = tu.SysAdminClient.GET("/member-api/v3/items").
+		WithQuery("or1.action", constants.ActionDelete).
+		WithQuery("or1.action", constants.ActionRestoreObject).
+		WithQuery("or2.status", constants.StatusPending).
+		WithQuery("or2.needs_admin_review", true).
+		Expect().Status(http.StatusOK)
+	list = api.WorkItemViewList{}
+	err = json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	require.NotEmpty(t, list.Results)
+	for _, item := range list.Results {
+		assert.Contains(t, []string{constants.ActionDelete, constants.ActionRestoreObject}, item.Action)
+		assert.True(t, item.Status == constants.StatusPending || item.NeedsAdminReview)
+	}
+
+	// OR groups can't get around institution scoping.
+	resp = tu.Inst1UserClient.GET("/member-api/v3/items").
+		WithQuery("or.institution_id", tu.Inst2User.InstitutionID).
+		WithQuery("or.status", constants.StatusPending).
+		Expect().Status(http.StatusOK)
+	list = api.WorkItemViewList{}
+	err = json.Unmarshal([]byte(resp.Body().Raw()), &list)
+	require.Nil(t, err)
+	require.NotEmpty(t, list.Results)
+	for _, item := range list.Results {
+		assert.Equal(t, tu.Inst1User.InstitutionID, item.InstitutionID)
+	}
+
+	// OR group filters must be valid filters for the resource.
+	tu.SysAdminClient.GET("/member-api/v3/items").
+		WithQuery("or.bogus_column", "1").
+		WithQuery("or.status", constants.StatusPending).
+		Expect().Status(http.StatusInternalServerError)
+}
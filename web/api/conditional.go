@@ -0,0 +1,127 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConditionalJSON responds with obj as JSON, along with an ETag header
+// and, for single resources, a Last-Modified header. If the client's
+// If-None-Match or If-Modified-Since header shows that it already has
+// the current version, this responds 304/Not Modified with no body.
+//
+// The ETag is a hash of the JSON response, so it changes whenever the
+// resource or any of the related records included in the response
+// changes. Last-Modified is the latest UpdatedAt of the resource and
+// its related records (see LastModified).
+//
+// We don't send Last-Modified for lists, because removing an item
+// from a result set doesn't change the UpdatedAt of anything still
+// in it. Clients should use the ETag for lists.
+func ConditionalJSON(c *gin.Context, obj interface{}) {
+	data, err := json.Marshal(obj)
+	if AbortIfError(c, err) {
+		return
+	}
+	etag := ETagFor(data)
+	c.Header("ETag", etag)
+
+	var lastModified time.Time
+	if _, isList := obj.(*JsonList); !isList {
+		lastModified = LastModified(obj)
+	}
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if NotModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// ETagFor returns a strong ETag for the specified response body.
+func ETagFor(data []byte) string {
+	digest := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(digest[:16]) + `"`
+}
+
+// NotModified returns true if the request's conditional headers say the
+// client already has the version of the resource described by etag and
+// lastModified. Per RFC 7232, we ignore If-Modified-Since when the
+// request includes If-None-Match, and we ignore both on requests other
+// than GET and HEAD.
+func NotModified(req *http.Request, etag string, lastModified time.Time) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, tag := range strings.Split(ifNoneMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ifModifiedSince := req.Header.Get("If-Modified-Since"); ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err != nil {
+			return false
+		}
+		// HTTP dates have one-second resolution.
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+var lastModifiedType = reflect.TypeOf(time.Time{})
+
+// LastModified returns the latest UpdatedAt timestamp in obj and in
+// any structs it contains, such as a GenericFile's checksums, storage
+// records and events. Returns the zero time if obj has no UpdatedAt.
+func LastModified(obj interface{}) time.Time {
+	return latestUpdatedAt(reflect.ValueOf(obj), time.Time{})
+}
+
+func latestUpdatedAt(v reflect.Value, latest time.Time) time.Time {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return latest
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			latest = latestUpdatedAt(v.Index(i), latest)
+		}
+	case reflect.Struct:
+		if v.Type() == lastModifiedType {
+			return latest
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+			if field.Name == "UpdatedAt" && field.Type == lastModifiedType {
+				if updatedAt := v.Field(i).Interface().(time.Time); updatedAt.After(latest) {
+					latest = updatedAt
+				}
+				continue
+			}
+			latest = latestUpdatedAt(v.Field(i), latest)
+		}
+	}
+	return latest
+}
@@ -0,0 +1,119 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/api"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETagFor(t *testing.T) {
+	etag := api.ETagFor([]byte(`{"id":1}`))
+	assert.Equal(t, 34, len(etag))
+	assert.Equal(t, etag, api.ETagFor([]byte(`{"id":1}`)))
+	assert.NotEqual(t, etag, api.ETagFor([]byte(`{"id":2}`)))
+}
+
+func TestNotModified(t *testing.T) {
+	etag := `"abc123"`
+	lastModified := time.Date(2024, 3, 1, 12, 0, 0, 500, time.UTC)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.False(t, api.NotModified(req, etag, lastModified))
+
+	req.Header.Set("If-None-Match", `"xyz", "abc123"`)
+	assert.True(t, api.NotModified(req, etag, lastModified))
+	req.Header.Set("If-None-Match", `W/"abc123"`)
+	assert.True(t, api.NotModified(req, etag, lastModified))
+	req.Header.Set("If-None-Match", "*")
+	assert.True(t, api.NotModified(req, etag, lastModified))
+
+	// If-None-Match takes precedence over If-Modified-Since
+	req.Header.Set("If-None-Match", `"xyz"`)
+	req.Header.Set("If-Modified-Since", lastModified.Format(http.TimeFormat))
+	assert.False(t, api.NotModified(req, etag, lastModified))
+
+	req.Header.Del("If-None-Match")
+	assert.True(t, api.NotModified(req, etag, lastModified))
+	req.Header.Set("If-Modified-Since", lastModified.Add(-1*time.Second).Format(http.TimeFormat))
+	assert.False(t, api.NotModified(req, etag, lastModified))
+	req.Header.Set("If-Modified-Since", "not a date")
+	assert.False(t, api.NotModified(req, etag, lastModified))
+
+	// Without a last modified date, we can't honor If-Modified-Since
+	req.Header.Set("If-Modified-Since", lastModified.Format(http.TimeFormat))
+	assert.False(t, api.NotModified(req, etag, time.Time{}))
+
+	// Conditional headers apply to GET and HEAD only
+	req = httptest.NewRequest(http.MethodPut, "/", nil)
+	req.Header.Set("If-None-Match", etag)
+	assert.False(t, api.NotModified(req, etag, lastModified))
+}
+
+func TestLastModified(t *testing.T) {
+	fileUpdated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	checksumUpdated := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	gf := &pgmodels.GenericFile{
+		Checksums: []*pgmodels.Checksum{
+			{TimestampModel: pgmodels.TimestampModel{UpdatedAt: checksumUpdated}},
+		},
+	}
+	gf.UpdatedAt = fileUpdated
+
+	// Related records count.
+	assert.Equal(t, checksumUpdated, api.LastModified(gf))
+
+	gf.Checksums = nil
+	assert.Equal(t, fileUpdated, api.LastModified(gf))
+
+	// Fields hidden from JSON don't count.
+	gf.Institution = &pgmodels.Institution{}
+	gf.Institution.UpdatedAt = checksumUpdated
+	assert.Equal(t, fileUpdated, api.LastModified(gf))
+
+	assert.True(t, api.LastModified(&api.StatusMessage{}).IsZero())
+	assert.True(t, api.LastModified(nil).IsZero())
+}
+
+func TestConditionalJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	obj := &pgmodels.IntellectualObjectView{
+		ID:        1,
+		UpdatedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/member-api/v3/objects/show/1", nil)
+	api.ConditionalJSON(c, obj)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "Fri, 01 Mar 2024 12:00:00 GMT", w.Header().Get("Last-Modified"))
+	assert.Contains(t, w.Body.String(), `"id":1`)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/member-api/v3/objects/show/1", nil)
+	c.Request.Header.Set("If-None-Match", etag)
+	api.ConditionalJSON(c, obj)
+	c.Writer.WriteHeaderNow()
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	assert.Empty(t, w.Body.String())
+
+	// Lists get an ETag, but no Last-Modified.
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/member-api/v3/objects", nil)
+	api.ConditionalJSON(c, &api.JsonList{Count: 1, Results: []*pgmodels.IntellectualObjectView{obj}})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.Empty(t, w.Header().Get("Last-Modified"))
+}
@@ -42,6 +42,9 @@ func StatusCodeForError(err error) (status int) {
 	if _, ok := err.(*common.ValidationError); ok {
 		return http.StatusBadRequest
 	}
+	if _, ok := err.(*common.IllegalTransitionError); ok {
+		return http.StatusConflict
+	}
 	if pgmodels.IsNoRowError(err) {
 		return http.StatusNotFound
 	}
@@ -59,11 +62,13 @@ func StatusCodeForError(err error) (status int) {
 		status = http.StatusMethodNotAllowed
 	case common.ErrInternal:
 		status = http.StatusInternalServerError
-	case common.ErrPendingWorkItems:
+	case common.ErrPendingWorkItems, common.ErrRequestAlreadyApproved, common.ErrRequestAlreadyCancelled, common.ErrLegalHold,
+		common.ErrNotDeleted, common.ErrStorageRecordsMissing, common.ErrObjectDeleted, common.ErrNotOrphaned:
 		status = http.StatusConflict
 	case common.ErrWrongDataType, common.ErrIDMismatch, common.ErrInstIDChange, common.ErrIdentifierChange,
 		common.ErrStorageOptionChange, common.ErrDecodeCookie, common.ErrInvalidObjectID,
-		common.ErrInvalidRequestorID, common.ErrInvalidToken:
+		common.ErrInvalidRequestorID, common.ErrInvalidToken, common.ErrInvalidCursor,
+		common.ErrNoFilters, common.ErrTooManyItems, common.ErrInvalidFileFilter:
 		status = http.StatusBadRequest
 	default:
 		status = http.StatusInternalServerError
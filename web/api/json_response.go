@@ -11,8 +11,11 @@ import (
 // that contains a list of items.
 type JsonList struct {
 	// Count is the total number of items in the result set.
+	// This will be -1 for cursor requests that include
+	// skip_count=true.
 	Count int `json:"count"`
-	// Next is the URL for the next page of results.
+	// Next is the URL for the next page of results. For cursor
+	// requests, this URL includes the opaque cursor for the next page.
 	Next string `json:"next"`
 	// Previous is the URL for the previous page of results.
 	Previous string `json:"previous"`
@@ -47,6 +50,24 @@ type AlertViewList struct {
 	Results  []*pgmodels.AlertView `json:"results"`
 }
 
+// AuditLogList is used in testing to convert a generic
+// JsonList into a typed list that we can test with assertions.
+type AuditLogList struct {
+	Count    int                  `json:"count"`
+	Next     string               `json:"next"`
+	Previous string               `json:"previous"`
+	Results  []*pgmodels.AuditLog `json:"results"`
+}
+
+// BagGroupViewList is used in testing to convert a generic
+// JsonList into a typed list that we can test with assertions.
+type BagGroupViewList struct {
+	Count    int                      `json:"count"`
+	Next     string                   `json:"next"`
+	Previous string                   `json:"previous"`
+	Results  []*pgmodels.BagGroupView `json:"results"`
+}
+
 // ChecksumViewList is used in testing to convert a generic
 // JsonList into a typed list that we can test with assertions.
 type ChecksumViewList struct {
@@ -101,6 +122,24 @@ type IntellectualObjectList struct {
 	Results  []*pgmodels.IntellectualObjectView `json:"results"`
 }
 
+// LegalHoldViewList is used in testing to convert a generic
+// JsonList into a typed list that we can test with assertions.
+type LegalHoldViewList struct {
+	Count    int                       `json:"count"`
+	Next     string                    `json:"next"`
+	Previous string                    `json:"previous"`
+	Results  []*pgmodels.LegalHoldView `json:"results"`
+}
+
+// ObjectVersionList is used in testing to convert a generic
+// JsonList into a typed list that we can test with assertions.
+type ObjectVersionList struct {
+	Count    int                       `json:"count"`
+	Next     string                    `json:"next"`
+	Previous string                    `json:"previous"`
+	Results  []*pgmodels.ObjectVersion `json:"results"`
+}
+
 // PremisEventViewList is used in testing to convert a generic
 // JsonList into a typed list that we can test with assertions.
 type PremisEventViewList struct {
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/pgmodels"
+)
+
+const (
+	ManifestFormatCSV    = "csv"
+	ManifestFormatNDJSON = "ndjson"
+)
+
+// manifestBatchSize is the number of files we load from the DB
+// at a time while streaming a manifest.
+const manifestBatchSize = 500
+
+// ManifestFormat returns the manifest format requested in the
+// format query param. This defaults to NDJSON. It returns a
+// ValidationError if the client requested an unknown format.
+func (req *Request) ManifestFormat() (string, error) {
+	format := req.GinContext.DefaultQuery("format", ManifestFormatNDJSON)
+	if format != ManifestFormatCSV && format != ManifestFormatNDJSON {
+		valErr := common.NewValidationError()
+		valErr.Errors["format"] = "Format must be csv or ndjson"
+		return "", valErr
+	}
+	return format, nil
+}
+
+// StreamFileManifest writes a manifest of all files matching query
+// to the response as either CSV or newline-delimited JSON. The caller
+// is responsible for applying institution scoping to the query.
+//
+// This writes files as it reads them from the database, in batches,
+// so memory use stays flat no matter how many files match. Because
+// we've already sent a 200 status by the time we start writing rows,
+// we can't report errors that occur mid-stream through the status code.
+// We log them and stop writing, so the client will see a truncated
+// response. For NDJSON, we also write a final error line.
+func (req *Request) StreamFileManifest(query *pgmodels.Query, format, filename string) error {
+	c := req.GinContext
+	var err error
+
+	// We flush after every batch, so clients start receiving data
+	// right away and we're not buffering large amounts of output.
+	written := 0
+	if format == ManifestFormatCSV {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+		c.Status(http.StatusOK)
+		writer := csv.NewWriter(c.Writer)
+		err = writer.Write(pgmodels.FileManifestColumns)
+		if err == nil {
+			err = pgmodels.FileManifestEach(query, manifestBatchSize, func(entry *pgmodels.FileManifestEntry) error {
+				if err := writer.Write(entry.CSVRecord()); err != nil {
+					return err
+				}
+				written++
+				if written%manifestBatchSize == 0 {
+					writer.Flush()
+					c.Writer.Flush()
+				}
+				return writer.Error()
+			})
+		}
+		writer.Flush()
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.ndjson"`, filename))
+		c.Status(http.StatusOK)
+		encoder := json.NewEncoder(c.Writer)
+		err = pgmodels.FileManifestEach(query, manifestBatchSize, func(entry *pgmodels.FileManifestEntry) error {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+			written++
+			if written%manifestBatchSize == 0 {
+				c.Writer.Flush()
+			}
+			return nil
+		})
+		if err != nil {
+			encoder.Encode(map[string]string{"error": err.Error()})
+		}
+	}
+	if err != nil {
+		common.Context().Log.Error().Msgf("Error streaming file manifest for user %s, %s: %v", req.CurrentUser.Email, req.PathAndQuery, err)
+	}
+	c.Writer.Flush()
+	return err
+}
@@ -0,0 +1,565 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/middleware"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIVersion is the version of the OpenAPI spec our
+// generated documents conform to.
+const OpenAPIVersion = "3.0.3"
+
+// OpenAPIDoc is an OpenAPI 3 document describing the member API or
+// the admin API. We generate these from the gin route table, the
+// AuthMap, and the filters each model supports, so they describe
+// exactly what the running Registry serves.
+type OpenAPIDoc struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       *OpenAPIInfo                            `json:"info"`
+	Servers    []*OpenAPIServer                        `json:"servers"`
+	Tags       []*OpenAPITag                           `json:"tags"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components *OpenAPIComponents                      `json:"components"`
+	Security   []map[string][]string                   `json:"security"`
+}
+
+// OpenAPIInfo describes the API as a whole.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIServer is the base URL for all paths in the document.
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+// OpenAPITag groups operations. We tag each operation with
+// the type of resource it acts on.
+type OpenAPITag struct {
+	Name string `json:"name"`
+}
+
+// OpenAPIOperation describes one method on one path.
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter describes a path or query string parameter.
+type OpenAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIRequestBody describes the body of a POST or PUT request.
+type OpenAPIRequestBody struct {
+	Description string                       `json:"description,omitempty"`
+	Required    bool                         `json:"required"`
+	Content     map[string]*OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse describes one possible response to an operation.
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType describes the schema of a request or response body.
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPISchema is the subset of JSON schema we need to describe
+// our models and params.
+type OpenAPISchema struct {
+	Ref         string                    `json:"$ref,omitempty"`
+	Type        string                    `json:"type,omitempty"`
+	Format      string                    `json:"format,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	Enum        []string                  `json:"enum,omitempty"`
+	Items       *OpenAPISchema            `json:"items,omitempty"`
+	Properties  map[string]*OpenAPISchema `json:"properties,omitempty"`
+}
+
+// OpenAPIComponents holds the schemas that operations refer to by
+// $ref, along with the security schemes for the API.
+type OpenAPIComponents struct {
+	Schemas         map[string]*OpenAPISchema         `json:"schemas"`
+	SecuritySchemes map[string]*OpenAPISecurityScheme `json:"securitySchemes"`
+}
+
+// OpenAPISecurityScheme describes one of our API auth headers.
+type OpenAPISecurityScheme struct {
+	Type        string `json:"type"`
+	In          string `json:"in"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// StatusMessage is the JSON that some admin endpoints return
+// when they have nothing more specific to say.
+type StatusMessage struct {
+	StatusCode int    `json:"StatusCode"`
+	Message    string `json:"Message"`
+}
+
+var routeParam = regexp.MustCompile(`[:*]([A-Za-z_]+)`)
+
+// openAPIRoutes is the route table of the running app.
+// InitAppEngine sets this through SetOpenAPIRoutes after
+// defining all routes.
+var openAPIRoutes gin.RoutesInfo
+
+// SetOpenAPIRoutes tells the OpenAPISpec endpoint which routes
+// the app serves.
+func SetOpenAPIRoutes(routes gin.RoutesInfo) {
+	openAPIRoutes = routes
+}
+
+// OpenAPISpecFor returns the OpenAPI document for the API at
+// prefix, which should be constants.APIPrefixMember or
+// constants.APIPrefixAdmin. See GenerateOpenAPISpec.
+func OpenAPISpecFor(prefix string) (*OpenAPIDoc, error) {
+	return GenerateOpenAPISpec(openAPIRoutes, prefix)
+}
+
+// GenerateOpenAPISpec returns an OpenAPI document describing all of
+// the routes whose paths begin with prefix. Routes come from the gin
+// engine's Routes() method. Summaries and tags come from the AuthMap,
+// query filters come from pgmodels.FiltersFor, and schemas come from
+// the JSON tags of the models each endpoint accepts and returns (see
+// openAPIEndpoints).
+//
+// This always returns a document. It also returns an error listing
+// any route, filter or schema it could not fully describe. That
+// usually means someone added a route without adding its handler to
+// the AuthMap or to openAPIEndpoints.
+func GenerateOpenAPISpec(routes gin.RoutesInfo, prefix string) (*OpenAPIDoc, error) {
+	gen := &openAPIGenerator{
+		schemas:  make(map[string]*OpenAPISchema),
+		problems: make([]string, 0),
+	}
+	basePath := strings.TrimSuffix(prefix, "/") + "/v3"
+	doc := &OpenAPIDoc{
+		OpenAPI: OpenAPIVersion,
+		Info:    openAPIInfo(prefix),
+		Servers: []*OpenAPIServer{{URL: basePath}},
+		Tags:    make([]*OpenAPITag, 0),
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+		Components: &OpenAPIComponents{
+			Schemas:         gen.schemas,
+			SecuritySchemes: openAPISecuritySchemes(),
+		},
+		Security: []map[string][]string{
+			{"apiUser": {}, "apiKey": {}},
+		},
+	}
+	gen.schemaFor(reflect.TypeOf(RequestError{}))
+
+	tags := make(map[string]bool)
+	operationIDs := make(map[string]bool)
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, basePath+"/") {
+			continue
+		}
+		op := gen.operation(route)
+		if op == nil {
+			continue
+		}
+		if operationIDs[op.OperationID] {
+			op.OperationID = op.OperationID + strings.Title(strings.ToLower(route.Method))
+		}
+		operationIDs[op.OperationID] = true
+		for _, tag := range op.Tags {
+			tags[tag] = true
+		}
+		path := OpenAPIPath(strings.TrimPrefix(route.Path, basePath))
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*OpenAPIOperation)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, &OpenAPITag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	if len(gen.problems) > 0 {
+		sort.Strings(gen.problems)
+		return doc, fmt.Errorf("OpenAPI spec for %s is incomplete:\n%s", prefix, strings.Join(gen.problems, "\n"))
+	}
+	return doc, nil
+}
+
+// OpenAPIPath converts a gin route path to an OpenAPI path.
+// For example, "/objects/show/*id" becomes "/objects/show/{id}".
+func OpenAPIPath(ginPath string) string {
+	return routeParam.ReplaceAllString(ginPath, "{$1}")
+}
+
+// HandlerShortName returns the package-qualified name of a gin
+// handler, without the module path. For example,
+// "github.com/APTrust/registry/web/api/common.AlertIndex" becomes
+// "common.AlertIndex".
+func HandlerShortName(handler string) string {
+	return handler[strings.LastIndex(handler, "/")+1:]
+}
+
+type openAPIGenerator struct {
+	schemas  map[string]*OpenAPISchema
+	problems []string
+}
+
+func (gen *openAPIGenerator) problem(format string, args ...interface{}) {
+	gen.problems = append(gen.problems, fmt.Sprintf(format, args...))
+}
+
+// operation describes a single route. It returns nil for routes
+// we don't document at all.
+func (gen *openAPIGenerator) operation(route gin.RouteInfo) *OpenAPIOperation {
+	handler := HandlerShortName(route.Handler)
+	funcName := handler[strings.LastIndex(handler, ".")+1:]
+	if funcName == "OpenAPISpec" {
+		return nil
+	}
+	authMeta, hasAuth := middleware.AuthMap[funcName]
+	if !hasAuth {
+		gen.problem("%s %s: handler %s has no AuthMap entry", route.Method, route.Path, handler)
+	}
+	endpoint, ok := openAPIEndpoints[handler]
+	if !ok {
+		gen.problem("%s %s: handler %s has no entry in openAPIEndpoints", route.Method, route.Path, handler)
+		endpoint = &openAPIEndpoint{Status: http.StatusOK}
+	}
+	op := &OpenAPIOperation{
+		OperationID: funcName,
+		Summary:     authMeta.PageTitle,
+		Description: endpoint.Description,
+		Tags:        []string{authMeta.ResourceType},
+		Parameters:  gen.pathParams(route.Path),
+		Responses:   make(map[string]*OpenAPIResponse),
+	}
+	if endpoint.Filters {
+		op.Description = strings.TrimSpace(op.Description + "\n\n" + openAPIOrGroupDescription)
+		op.Parameters = append(op.Parameters, gen.filterParams(route, authMeta.ResourceType, endpoint)...)
+	}
+	if endpoint.Paged {
+		op.Parameters = append(op.Parameters, pagingParams(PagingParams...)...)
+	}
+	op.Parameters = append(op.Parameters, endpoint.Query...)
+	if endpoint.Body != nil {
+		op.RequestBody = &OpenAPIRequestBody{
+			Description: endpoint.BodyDescription,
+			Required:    true,
+			Content:     map[string]*OpenAPIMediaType{"application/json": {Schema: gen.schemaFor(reflect.TypeOf(endpoint.Body))}},
+		}
+	}
+	op.Responses[strconv.Itoa(endpoint.Status)] = gen.successResponse(endpoint)
+	if route.Method == http.MethodGet && endpoint.Response != nil && len(endpoint.ContentTypes) == 0 {
+		op.Responses["304"] = &OpenAPIResponse{Description: "Not Modified. The ETag in If-None-Match or the date in If-Modified-Since is current."}
+	}
+	errorResponse := &OpenAPIResponse{
+		Description: "Error",
+		Content:     map[string]*OpenAPIMediaType{"application/json": {Schema: &OpenAPISchema{Ref: "#/components/schemas/RequestError"}}},
+	}
+	if endpoint.Filters || endpoint.Body != nil {
+		op.Responses["400"] = errorResponse
+	}
+	op.Responses["401"] = errorResponse
+	op.Responses["403"] = errorResponse
+	if routeParam.MatchString(route.Path) {
+		op.Responses["404"] = errorResponse
+	}
+	if endpoint.Conflict {
+		op.Responses["409"] = errorResponse
+	}
+	op.Responses["429"] = &OpenAPIResponse{
+		Description: "Too Many Requests. The Retry-After header says how many seconds to wait.",
+		Content:     errorResponse.Content,
+	}
+	return op
+}
+
+func (gen *openAPIGenerator) successResponse(endpoint *openAPIEndpoint) *OpenAPIResponse {
+	response := &OpenAPIResponse{Description: http.StatusText(endpoint.Status)}
+	if endpoint.Response == nil {
+		return response
+	}
+	contentTypes := endpoint.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = []string{"application/json"}
+	}
+	var schema *OpenAPISchema
+	responseType := reflect.TypeOf(endpoint.Response)
+	if responseType.Kind() == reflect.Slice && len(endpoint.ContentTypes) == 0 {
+		schema = gen.listSchema(responseType.Elem())
+	} else {
+		schema = gen.schemaFor(responseType)
+	}
+	response.Content = make(map[string]*OpenAPIMediaType)
+	for _, contentType := range contentTypes {
+		response.Content[contentType] = &OpenAPIMediaType{Schema: schema}
+	}
+	return response
+}
+
+// listSchema returns the schema for a JsonList whose results
+// are of type itemType.
+func (gen *openAPIGenerator) listSchema(itemType reflect.Type) *OpenAPISchema {
+	for itemType.Kind() == reflect.Ptr {
+		itemType = itemType.Elem()
+	}
+	name := itemType.Name() + "List"
+	if _, ok := gen.schemas[name]; !ok {
+		gen.schemas[name] = &OpenAPISchema{
+			Type: "object",
+			Properties: map[string]*OpenAPISchema{
+				"count":    {Type: "integer", Description: "Total number of items in the result set. This is -1 for cursor requests that include skip_count=true."},
+				"next":     {Type: "string", Description: "URL of the next page of results."},
+				"previous": {Type: "string", Description: "URL of the previous page of results."},
+				"results":  {Type: "array", Items: gen.schemaFor(itemType)},
+			},
+		}
+	}
+	return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+func (gen *openAPIGenerator) pathParams(ginPath string) []*OpenAPIParameter {
+	params := make([]*OpenAPIParameter, 0)
+	for _, match := range routeParam.FindAllStringSubmatch(ginPath, -1) {
+		param := &OpenAPIParameter{
+			Name:        match[1],
+			In:          "path",
+			Required:    true,
+			Description: fmt.Sprintf("The %s of the record.", strings.ReplaceAll(match[1], "_", " ")),
+			Schema:      &OpenAPISchema{Type: "integer", Format: "int64"},
+		}
+		if strings.HasPrefix(match[0], "*") {
+			param.Description = "The id or the identifier of the record. Identifiers may contain slashes, which should not be URL-encoded."
+			param.Schema = &OpenAPISchema{Type: "string"}
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// filterParams describes the filters in pgmodels.FiltersFor(resourceType).
+// It takes the type of each filter from the corresponding JSON field of
+// the endpoint's filter model.
+func (gen *openAPIGenerator) filterParams(route gin.RouteInfo, resourceType string, endpoint *openAPIEndpoint) []*OpenAPIParameter {
+	model := endpoint.FilterModel
+	if model == nil {
+		model = endpoint.Response
+	}
+	fields := make(map[string]reflect.Type)
+	if model != nil {
+		jsonFields(reflect.TypeOf(model), fields)
+	}
+	filters := pgmodels.FiltersFor(resourceType)
+	if len(filters) == 0 {
+		gen.problem("%s %s: pgmodels.FiltersFor(%q) returned no filters", route.Method, route.Path, resourceType)
+	}
+	params := make([]*OpenAPIParameter, 0, len(filters))
+	for _, filter := range filters {
+		paramFilter, err := pgmodels.NewParamFilter(filter, nil)
+		if err != nil {
+			gen.problem("%s %s: filter %s: %v", route.Method, route.Path, filter, err)
+			continue
+		}
+		// Some filters refer to columns the model doesn't
+		// serialize. We describe those as strings.
+		fieldType, ok := fields[paramFilter.Column]
+		if !ok {
+			fieldType = reflect.TypeOf("")
+		}
+		schema := gen.schemaFor(fieldType)
+		description := fmt.Sprintf("Return records whose %s %s the specified value.", paramFilter.Column, openAPIFilterOps[paramFilter.RawOp])
+		switch paramFilter.RawOp {
+		case "in", "not_in":
+			schema = &OpenAPISchema{Type: "array", Items: schema}
+			description = fmt.Sprintf("Return records whose %s %s the specified values. Repeat the param to specify more than one value.", paramFilter.Column, openAPIFilterOps[paramFilter.RawOp])
+		case "is_null", "not_null":
+			schema = &OpenAPISchema{Type: "boolean"}
+			description = fmt.Sprintf("Return records whose %s %s. The value of this param is ignored.", paramFilter.Column, openAPIFilterOps[paramFilter.RawOp])
+		case "search":
+			schema = &OpenAPISchema{Type: "string"}
+			description = "Full-text search. Supports quoted phrases, or, and -word to exclude a word. Unless the request includes a sort param, best matches come first, and each result includes a search_rank and an HTML search_snippet with matching words in <mark> tags."
+		}
+		params = append(params, &OpenAPIParameter{
+			Name:        filter,
+			In:          "query",
+			Description: description,
+			Schema:      schema,
+		})
+	}
+	return params
+}
+
+// openAPIOrGroupDescription explains OR group params, which OpenAPI
+// can't describe as individual parameters because their names vary.
+// See pgmodels.FilterCollection.AddOrGroups.
+const openAPIOrGroupDescription = "Filters are ANDed together. To OR filters, prefix them with or. as in or.status=Failed&or.needs_admin_review=true. Use numbered groups such as or1. and or2. for more than one OR group. Each group is ANDed with the other filters. Any filter below except q may be used in an OR group."
+
+var openAPIFilterOps = map[string]string{
+	"eq":          "equals",
+	"ne":          "does not equal",
+	"gt":          "is greater than",
+	"gteq":        "is greater than or equal to",
+	"lt":          "is less than",
+	"lteq":        "is less than or equal to",
+	"starts_with": "starts with",
+	"contains":    "contains",
+	"in":          "is one of",
+	"not_in":      "is not one of",
+	"is_null":     "is null",
+	"not_null":    "is not null",
+}
+
+var pagingParamDescriptions = map[string]*OpenAPIParameter{
+	"sort":       {Description: "Sort by column and direction, e.g. updated_at__desc. Repeat to sort on more than one column.", Schema: &OpenAPISchema{Type: "string"}},
+	"page":       {Description: "Page number, starting at 1. Ignored in cursor mode.", Schema: &OpenAPISchema{Type: "integer"}},
+	"per_page":   {Description: "Number of items per page.", Schema: &OpenAPISchema{Type: "integer"}},
+	"cursor":     {Description: "Opaque cursor from a previous response's next link. Pass an empty value to start keyset paging.", Schema: &OpenAPISchema{Type: "string"}},
+	"skip_count": {Description: "In cursor mode, set to true to skip counting the result set. The response count will be -1.", Schema: &OpenAPISchema{Type: "boolean"}},
+}
+
+func pagingParams(names ...string) []*OpenAPIParameter {
+	params := make([]*OpenAPIParameter, len(names))
+	for i, name := range names {
+		params[i] = &OpenAPIParameter{
+			Name:        name,
+			In:          "query",
+			Description: pagingParamDescriptions[name].Description,
+			Schema:      pagingParamDescriptions[name].Schema,
+		}
+	}
+	return params
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor returns the schema for Go type t. Structs become named
+// component schemas, so the returned schema for a struct is a $ref.
+func (gen *openAPIGenerator) schemaFor(t reflect.Type) *OpenAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &OpenAPISchema{Type: "number"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &OpenAPISchema{Type: "array", Items: gen.schemaFor(t.Elem())}
+	case reflect.Map, reflect.Interface:
+		return &OpenAPISchema{Type: "object"}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := gen.schemas[name]; !ok {
+			schema := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+			// Register before describing fields, in case the
+			// struct refers to itself.
+			gen.schemas[name] = schema
+			fields := make(map[string]reflect.Type)
+			jsonFields(t, fields)
+			for fieldName, fieldType := range fields {
+				schema.Properties[fieldName] = gen.schemaFor(fieldType)
+			}
+		}
+		return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+	}
+	gen.problem("no schema for Go type %s", t.String())
+	return &OpenAPISchema{}
+}
+
+// jsonFields adds the JSON name and Go type of each field that
+// encoding/json would serialize for struct type t to fields.
+func jsonFields(t reflect.Type, fields map[string]reflect.Type) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			jsonFields(field.Type, fields)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+}
+
+func openAPIInfo(prefix string) *OpenAPIInfo {
+	if prefix == constants.APIPrefixAdmin {
+		return &OpenAPIInfo{
+			Title:       "APTrust Registry Admin API",
+			Description: "Version 3 of the APTrust Admin API. This API is available to APTrust administrators and preservation services only. This document is generated from the Registry's routes.",
+			Version:     "3.0",
+		}
+	}
+	return &OpenAPIInfo{
+		Title:       "APTrust Registry Member API",
+		Description: "Version 3 of the APTrust Member API. This document is generated from the Registry's routes.",
+		Version:     "3.0",
+	}
+}
+
+func openAPISecuritySchemes() map[string]*OpenAPISecurityScheme {
+	return map[string]*OpenAPISecurityScheme{
+		"apiUser": {
+			Type:        "apiKey",
+			In:          "header",
+			Name:        constants.APIUserHeader,
+			Description: "User's email address",
+		},
+		"apiKey": {
+			Type:        "apiKey",
+			In:          "header",
+			Name:        constants.APIKeyHeader,
+			Description: "User's secret API key. Create keys under My Account > Manage API Keys. Read-only keys may make only GET requests.",
+		},
+	}
+}
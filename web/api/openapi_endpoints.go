@@ -0,0 +1,521 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/APTrust/registry/network"
+	"github.com/APTrust/registry/pgmodels"
+)
+
+// openAPIEndpoint describes what an API handler accepts and returns,
+// which is the part of the OpenAPI spec we can't learn from the route
+// table or the AuthMap.
+type openAPIEndpoint struct {
+	// Description is a longer explanation of what the endpoint does.
+	Description string
+	// Status is the HTTP status code of a successful response.
+	Status int
+	// Response is an instance of the type the endpoint returns.
+	// A slice means the endpoint returns a JsonList of that type.
+	Response interface{}
+	// ContentTypes are the content types of the response, if it's
+	// something other than application/json.
+	ContentTypes []string
+	// Body is an instance of the type the endpoint expects in the
+	// JSON request body, if any.
+	Body interface{}
+	// BodyDescription describes the request body.
+	BodyDescription string
+	// Filters indicates whether the endpoint accepts the query filters
+	// returned by pgmodels.FiltersFor for its resource type.
+	Filters bool
+	// FilterModel is an instance of the type whose JSON fields the
+	// filters refer to. If nil, we use Response.
+	FilterModel interface{}
+	// Paged indicates whether the endpoint accepts the params in
+	// PagingParams.
+	Paged bool
+	// Query describes query string params specific to this endpoint.
+	Query []*OpenAPIParameter
+	// Conflict indicates whether the endpoint may return 409 when
+	// other operations are pending on the resource.
+	Conflict bool
+}
+
+var manifestFormatParam = &OpenAPIParameter{
+	Name:        "format",
+	In:          "query",
+	Description: "Manifest format. Defaults to ndjson.",
+	Schema:      &OpenAPISchema{Type: "string", Enum: []string{ManifestFormatNDJSON, ManifestFormatCSV}},
+}
+
+var manifestContentTypes = []string{"application/x-ndjson", "text/csv"}
+
+const changesDescription = "Returns records that changed at or after the time in updated_at__gteq, which is required, oldest first. This always uses cursor paging. Follow the next links until there are none, then start your next sync from the updated_at of the last record you received."
+
+// openAPIEndpoints describes every API handler, keyed by package and
+// function name. Handlers in web/api/common serve both the member API
+// and the admin API.
+//
+// If you add a route to the member or admin API, add its handler here
+// too. TestOpenAPISpec fails for any route that has no entry.
+var openAPIEndpoints = map[string]*openAPIEndpoint{
+	"common.AlertIndex": {
+		Status:   http.StatusOK,
+		Response: []*pgmodels.AlertView{},
+		Filters:  true,
+		Paged:    true,
+	},
+	"common.AlertShow": {
+		Description: "Returns an alert as seen by the specified user.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.AlertView{},
+	},
+	"common.AuditLogIndex": {
+		Description: "Returns audit log entries, newest first. Institutional admins see only changes to their own institution's records.",
+		Status:      http.StatusOK,
+		Response:    []*pgmodels.AuditLog{},
+		Filters:     true,
+		Paged:       true,
+	},
+	"common.AuditLogShow": {
+		Description: "Returns an audit log entry, including the record's fields before and after the change.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.AuditLog{},
+	},
+	"common.BagGroupIndex": {
+		Status:   http.StatusOK,
+		Response: []*pgmodels.BagGroupView{},
+		Filters:  true,
+		Paged:    true,
+	},
+	"common.BagGroupShow": {
+		Description: "Returns a bag group with its object count, file count and total size, broken down by storage option.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.BagGroupView{},
+	},
+	"common.ChecksumIndex": {
+		Status:   http.StatusOK,
+		Response: []*pgmodels.ChecksumView{},
+		Filters:  true,
+		Paged:    true,
+	},
+	"common.ChecksumShow": {
+		Status:   http.StatusOK,
+		Response: &pgmodels.ChecksumView{},
+	},
+	"common.DeletionRequestApprove": {
+		Description: "Approves a pending deletion request and queues the deletion. Only institutional admins can approve deletions.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.DeletionRequestView{},
+		Conflict:    true,
+	},
+	"common.DeletionRequestCancel": {
+		Description: "Cancels a pending deletion request. Only institutional admins can cancel deletions.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.DeletionRequestView{},
+		Conflict:    true,
+	},
+	"common.DeletionRequestIndex": {
+		Status:   http.StatusOK,
+		Response: []*pgmodels.DeletionRequestView{},
+		Filters:  true,
+		Paged:    true,
+	},
+	"common.DeletionRequestShow": {
+		Status:   http.StatusOK,
+		Response: &pgmodels.DeletionRequestView{},
+	},
+	"common.GenericFileChanges": {
+		Description: changesDescription,
+		Status:      http.StatusOK,
+		Response:    []*pgmodels.GenericFileView{},
+		Filters:     true,
+		Query:       pagingParams("per_page", "cursor", "skip_count"),
+	},
+	"common.GenericFileIndex": {
+		Status:   http.StatusOK,
+		Response: []*pgmodels.GenericFileView{},
+		Filters:  true,
+		Paged:    true,
+	},
+	"common.GenericFileInitDelete": {
+		Description: "Creates a deletion request for the file and emails it to the institution's admins for approval.",
+		Status:      http.StatusCreated,
+		Response:    &pgmodels.DeletionRequestView{},
+		Conflict:    true,
+	},
+	"common.GenericFileManifest": {
+		Description:  "Streams a manifest of all files matching the filters, with all checksums. NDJSON manifests contain one file per line. This is not paged.",
+		Status:       http.StatusOK,
+		Response:     &pgmodels.FileManifestEntry{},
+		ContentTypes: manifestContentTypes,
+		Filters:      true,
+		FilterModel:  &pgmodels.GenericFileView{},
+		Query:        []*OpenAPIParameter{manifestFormatParam},
+	},
+	"common.GenericFileRestore": {
+		Description: "Creates and queues a restoration WorkItem for the file.",
+		Status:      http.StatusCreated,
+		Response:    &pgmodels.WorkItem{},
+		Conflict:    true,
+	},
+	"common.GenericFileShow": {
+		Status:   http.StatusOK,
+		Response: &pgmodels.GenericFile{},
+	},
+	"common.IntellectualObjectChanges": {
+		Description: changesDescription,
+		Status:      http.StatusOK,
+		Response:    []*pgmodels.IntellectualObjectView{},
+		Filters:     true,
+		Query:       pagingParams("per_page", "cursor", "skip_count"),
+	},
+	"common.IntellectualObjectIndex": {
+		Status:   http.StatusOK,
+		Response: []*pgmodels.IntellectualObjectView{},
+		Filters:  true,
+		Paged:    true,
+	},
+	"common.IntellectualObjectInitDelete": {
+		Description: "Creates a deletion request for the object and emails it to the institution's admins for approval.",
+		Status:      http.StatusCreated,
+		Response:    &pgmodels.DeletionRequestView{},
+		Conflict:    true,
+	},
+	"common.IntellectualObjectManifest": {
+		Description:  "Streams a manifest of the object's files, with all checksums. NDJSON manifests contain one file per line.",
+		Status:       http.StatusOK,
+		Response:     &pgmodels.FileManifestEntry{},
+		ContentTypes: manifestContentTypes,
+		Query: []*OpenAPIParameter{
+			manifestFormatParam,
+			{
+				Name:        "state",
+				In:          "query",
+				Description: "Use D to list deleted files instead of active files.",
+				Schema:      &OpenAPISchema{Type: "string", Enum: []string{"A", "D"}},
+			},
+		},
+	},
+	"common.IntellectualObjectRestore": {
+		Description: "Creates and queues a restoration WorkItem for the object.",
+		Status:      http.StatusCreated,
+		Response:    &pgmodels.WorkItem{},
+		Conflict:    true,
+	},
+	"common.IntellectualObjectShow": {
+		Status:   http.StatusOK,
+		Response: &pgmodels.IntellectualObjectView{},
+	},
+	"common.LegalHoldIndex": {
+		Description: "Returns legal holds, newest first. Filter on active=true to see only holds that currently block deletion.",
+		Status:      http.StatusOK,
+		Response:    []*pgmodels.LegalHoldView{},
+		Filters:     true,
+		Paged:       true,
+	},
+	"common.LegalHoldShow": {
+		Description: "Returns a legal hold on an object or bag group.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.LegalHoldView{},
+	},
+	"common.ObjectVersionDiff": {
+		Description: "Returns the files added, removed and changed between two versions of an object. Use the from param for the id of the older version. Without it, this compares to the previous version.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.ObjectVersionDiff{},
+		Query: []*OpenAPIParameter{
+			{
+				Name:        "from",
+				In:          "query",
+				Description: "The id of the version to compare to. This must be a version of the same object.",
+				Schema:      &OpenAPISchema{Type: "integer"},
+			},
+		},
+	},
+	"common.ObjectVersionIndex": {
+		Description: "Lists the versions recorded at each successful ingest. Filter on intellectual_object_id for one object's history.",
+		Status:      http.StatusOK,
+		Response:    []*pgmodels.ObjectVersion{},
+		Filters:     true,
+		Paged:       true,
+	},
+	"common.ObjectVersionShow": {
+		Description: "Returns an object version with its files, sizes and checksums.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.ObjectVersion{},
+	},
+	"common.PremisEventChanges": {
+		Description: changesDescription,
+		Status:      http.StatusOK,
+		Response:    []*pgmodels.PremisEventView{},
+		Filters:     true,
+		Query:       pagingParams("per_page", "cursor", "skip_count"),
+	},
+	"common.PremisEventIndex": {
+		Status:   http.StatusOK,
+		Response: []*pgmodels.PremisEventView{},
+		Filters:  true,
+		Paged:    true,
+	},
+	"common.PremisEventShow": {
+		Status:   http.StatusOK,
+		Response: &pgmodels.PremisEventView{},
+	},
+	"common.WorkItemHistory": {
+		Description: "Returns each change to the WorkItem's stage, status and node, oldest first, along with the time it spent in each stage.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.WorkItemHistory{},
+	},
+	"common.WorkItemIndex": {
+		Status:   http.StatusOK,
+		Response: []*pgmodels.WorkItemView{},
+		Filters:  true,
+		Paged:    true,
+	},
+	"common.WorkItemShow": {
+		Status:   http.StatusOK,
+		Response: &pgmodels.WorkItemView{},
+	},
+
+	"admin.ChecksumCreate": {
+		Status:   http.StatusCreated,
+		Response: &pgmodels.Checksum{},
+		Body:     &pgmodels.Checksum{},
+	},
+	"admin.DeletionRequestShow": {
+		Status:   http.StatusOK,
+		Response: &pgmodels.DeletionRequestMin{},
+	},
+	"admin.GenerateFailedFixityAlerts": {
+		Description: "Sends alerts describing failed fixity checks since the last run. Returns 201 if it created alerts, 200 if there was nothing to report.",
+		Status:      http.StatusCreated,
+		Response:    []*pgmodels.FailedFixitySummary{},
+		Conflict:    true,
+	},
+	"admin.GenericFileCreate": {
+		Status:   http.StatusCreated,
+		Response: &pgmodels.GenericFile{},
+		Body:     &pgmodels.GenericFile{},
+	},
+	"admin.GenericFileCreateBatch": {
+		Description: "Creates a batch of new files along with their checksums, events and storage records.",
+		Status:      http.StatusCreated,
+		Response:    []*pgmodels.GenericFile{},
+		Body:        []*pgmodels.GenericFile{},
+	},
+	"admin.GenericFileDelete": {
+		Description: "Marks a file as deleted. The file must have an approved deletion request and a deletion WorkItem.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.GenericFile{},
+	},
+	"admin.GenericFileIndex": {
+		Status:   http.StatusOK,
+		Response: []*pgmodels.GenericFile{},
+		Filters:  true,
+		Paged:    true,
+	},
+	"admin.GenericFileUndelete": {
+		Description: "Marks a deleted file as active again and records a compensating event. Use this when a failed deletion left the file's preservation copies in place. Returns 409 if the file isn't deleted, its object is deleted, or it has no storage records.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.GenericFile{},
+		Conflict:    true,
+	},
+	"admin.GenericFileUpdate": {
+		Status:   http.StatusOK,
+		Response: &pgmodels.GenericFile{},
+		Body:     &pgmodels.GenericFile{},
+	},
+	"admin.InstitutionIndex": {
+		Status:   http.StatusOK,
+		Response: []*pgmodels.InstitutionView{},
+		Filters:  true,
+		Paged:    true,
+	},
+	"admin.InstitutionShow": {
+		Status:   http.StatusOK,
+		Response: &pgmodels.InstitutionView{},
+	},
+	"admin.IntellectualObjectCreate": {
+		Status:   http.StatusCreated,
+		Response: &pgmodels.IntellectualObject{},
+		Body:     &pgmodels.IntellectualObject{},
+	},
+	"admin.IntellectualObjectDelete": {
+		Description: "Marks an object as deleted. The object must have an approved deletion request and a deletion WorkItem.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.IntellectualObject{},
+	},
+	"admin.IntellectualObjectInitBatchDelete": {
+		Description:     "Creates a deletion request for many objects at once. An admin at the depositing institution must approve it.",
+		Status:          http.StatusCreated,
+		Response:        &pgmodels.DeletionRequest{},
+		Body:            map[string]interface{}{},
+		BodyDescription: "JSON object with institutionId, requestorId, objectIds and secretKey, which must match the BatchDeletionKey setting.",
+	},
+	"admin.IntellectualObjectInitRestore": {
+		Status:   http.StatusCreated,
+		Response: &pgmodels.WorkItem{},
+		Conflict: true,
+	},
+	"admin.IntellectualObjectUndelete": {
+		Description: "Marks a deleted object and its deleted files that still have storage records as active again, and records compensating events. Use this when a failed deletion left the object's preservation copies in place. Returns 409 if the object isn't deleted or none of its files have storage records.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.IntellectualObject{},
+		Conflict:    true,
+	},
+	"admin.IntellectualObjectUpdate": {
+		Status:   http.StatusOK,
+		Response: &pgmodels.IntellectualObject{},
+		Body:     &pgmodels.IntellectualObject{},
+	},
+	"admin.PipelineReportShow": {
+		Description: "Returns WorkItem throughput, queue wait, processing time percentiles, failure rates and bytes per hour by action, stage and node, for items processed in the specified date range.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.PipelineStats{},
+		Query: []*OpenAPIParameter{
+			{
+				Name:        "start_date",
+				In:          "query",
+				Description: "Include items processed on or after this date (YYYY-MM-DD). Defaults to 30 days before end_date.",
+				Schema:      &OpenAPISchema{Type: "string", Format: "date"},
+			},
+			{
+				Name:        "end_date",
+				In:          "query",
+				Description: "Include items processed on or before this date (YYYY-MM-DD). Defaults to today.",
+				Schema:      &OpenAPISchema{Type: "string", Format: "date"},
+			},
+			{
+				Name:        "institution_id",
+				In:          "query",
+				Description: "Include only items belonging to this institution. Omit to include all institutions.",
+				Schema:      &OpenAPISchema{Type: "integer"},
+			},
+		},
+	},
+	"admin.PremisEventCreate": {
+		Status:   http.StatusCreated,
+		Response: &pgmodels.PremisEvent{},
+		Body:     &pgmodels.PremisEvent{},
+	},
+	"admin.PrepareFileDelete": {
+		Description: "Creates the records needed to delete a file. This is available only in test and integration builds.",
+		Status:      http.StatusOK,
+	},
+	"admin.PrepareObjectDelete": {
+		Description: "Creates the records needed to delete an object. This is available only in test and integration builds.",
+		Status:      http.StatusOK,
+	},
+	"admin.StorageRecordCreate": {
+		Status:   http.StatusCreated,
+		Response: &pgmodels.StorageRecord{},
+		Body:     &pgmodels.StorageRecord{},
+	},
+	"admin.StorageRecordIndex": {
+		Status:   http.StatusOK,
+		Response: []*pgmodels.StorageRecord{},
+		Filters:  true,
+		Paged:    true,
+	},
+	"admin.StorageRecordShow": {
+		Status:   http.StatusOK,
+		Response: &pgmodels.StorageRecord{},
+	},
+	"admin.WorkItemBulkRequeue": {
+		Description: "Requeues all WorkItems matching the action, status, stage, institution_id, node, date_processed__gteq and date_processed__lteq filters in the query string. Successful and cancelled items are never requeued. Returns the result for each item.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.BulkRequeueResult{},
+		Query: []*OpenAPIParameter{
+			{
+				Name:        "target_stage",
+				In:          "query",
+				Description: "Stage to requeue to. Defaults to the current stage for ingests and Requested for other actions.",
+				Schema:      &OpenAPISchema{Type: "string"},
+			},
+			{
+				Name:        "dry_run",
+				In:          "query",
+				Description: "Set to true to preview the items and NSQ topics without requeueing anything.",
+				Schema:      &OpenAPISchema{Type: "boolean"},
+			},
+		},
+	},
+	"admin.WorkItemCreate": {
+		Status:   http.StatusCreated,
+		Response: &pgmodels.WorkItem{},
+		Body:     &pgmodels.WorkItem{},
+	},
+	"admin.WorkItemReconcile": {
+		Description: "Checks the WorkItem against NSQ and Redis again and, if no worker is handling it, requeues it or deletes its stale Redis state. Returns the outcome.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.OrphanedWorkItem{},
+		Conflict:    true,
+	},
+	"admin.WorkItemReconcileShow": {
+		Description: "Cross-checks incomplete WorkItems against NSQ topic stats and Redis, and returns items no worker is handling, plus completed items that left state in Redis. Items updated within the reconciliation grace period are skipped.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.WorkItemReconciliation{},
+	},
+	"admin.WorkItemRedisDelete": {
+		Description: "Deletes the WorkItem's processing state from Redis.",
+		Status:      http.StatusOK,
+		Response:    &StatusMessage{},
+	},
+	"admin.WorkItemRedisShow": {
+		Description: "Returns the ingest or restoration state the workers have stored in Redis for the WorkItem, with per-file progress. Files are sorted by path and returned one page at a time. files_matched counts all files that match file_filter. Data we can't decode is returned as raw JSON under unknown.",
+		Status:      http.StatusOK,
+		Response:    &network.RedisWorkItemState{},
+		Query: append(pagingParams("page", "per_page"),
+			&OpenAPIParameter{
+				Name:        "file_filter",
+				In:          "query",
+				Description: "Return only files with errors, files not yet validated, or files not yet stored. The file summary still counts all files.",
+				Schema: &OpenAPISchema{Type: "string", Enum: []string{
+					network.RedisFileFilterError,
+					network.RedisFileFilterNotValidated,
+					network.RedisFileFilterNotStored,
+				}},
+			},
+		),
+	},
+	"admin.WorkItemRequeue": {
+		Description: "Requeues the WorkItem to the stage in the form param named stage.",
+		Status:      http.StatusOK,
+		Response:    &StatusMessage{},
+	},
+	"admin.WorkItemStageTiming": {
+		Description: "Returns the average, median and maximum seconds WorkItems spent in each stage, by action.",
+		Status:      http.StatusOK,
+		Response:    []*pgmodels.StageTimingStats{},
+		Query: []*OpenAPIParameter{
+			{
+				Name:        "start_date",
+				In:          "query",
+				Description: "Include stages entered on or after this date (YYYY-MM-DD). Defaults to 30 days before end_date.",
+				Schema:      &OpenAPISchema{Type: "string", Format: "date"},
+			},
+			{
+				Name:        "end_date",
+				In:          "query",
+				Description: "Include stages entered on or before this date (YYYY-MM-DD). Defaults to today.",
+				Schema:      &OpenAPISchema{Type: "string", Format: "date"},
+			},
+		},
+	},
+	"admin.WorkItemUpdate": {
+		Description: "Updates the WorkItem. Returns 409 if the new stage or status can't follow the saved stage and status.",
+		Status:      http.StatusOK,
+		Response:    &pgmodels.WorkItem{},
+		Body:        &pgmodels.WorkItem{},
+		Conflict:    true,
+		Query: []*OpenAPIParameter{
+			{
+				Name:        "override_transition_check",
+				In:          "query",
+				Description: "Set to true to allow an otherwise illegal stage or status change.",
+				Schema:      &OpenAPISchema{Type: "boolean"},
+			},
+		},
+	},
+}
@@ -0,0 +1,102 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/APTrust/registry/app"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/middleware"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/APTrust/registry/web/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var schemaRef = regexp.MustCompile(`"\$ref":"#/components/schemas/([A-Za-z]+)"`)
+
+func TestOpenAPIPath(t *testing.T) {
+	assert.Equal(t, "/objects", api.OpenAPIPath("/objects"))
+	assert.Equal(t, "/objects/show/{id}", api.OpenAPIPath("/objects/show/*id"))
+	assert.Equal(t, "/alerts/show/{id}/{user_id}", api.OpenAPIPath("/alerts/show/:id/:user_id"))
+}
+
+func TestHandlerShortName(t *testing.T) {
+	assert.Equal(t, "common.AlertIndex", api.HandlerShortName("github.com/APTrust/registry/web/api/common.AlertIndex"))
+	assert.Equal(t, "admin.WorkItemCreate", api.HandlerShortName("github.com/APTrust/registry/web/api/admin.WorkItemCreate"))
+}
+
+// TestOpenAPISpec fails when the spec for either API is missing
+// a route, or when an index endpoint is missing one of its filters.
+// If this fails after you add a route, add the route's handler to
+// openAPIEndpoints in openapi_endpoints.go.
+func TestOpenAPISpec(t *testing.T) {
+	routes := app.InitAppEngine(true).Routes()
+	for _, prefix := range constants.APIPrefixes {
+		doc, err := api.GenerateOpenAPISpec(routes, prefix)
+		require.Nil(t, err, err)
+		require.NotNil(t, doc)
+		assert.Equal(t, api.OpenAPIVersion, doc.OpenAPI)
+		basePath := doc.Servers[0].URL
+		assert.Equal(t, prefix+"v3", basePath)
+
+		routeCount := 0
+		for _, route := range routes {
+			if !strings.HasPrefix(route.Path, prefix) || strings.HasSuffix(route.Path, "/openapi.json") {
+				continue
+			}
+			routeCount++
+			path := api.OpenAPIPath(strings.TrimPrefix(route.Path, basePath))
+			op := doc.Paths[path][strings.ToLower(route.Method)]
+			require.NotNil(t, op, "No spec for %s %s", route.Method, route.Path)
+			assert.NotEmpty(t, op.Summary, route.Path)
+			assert.NotEmpty(t, op.Responses, route.Path)
+
+			handler := api.HandlerShortName(route.Handler)
+			if !strings.HasSuffix(handler, "Index") {
+				continue
+			}
+			funcName := handler[strings.Index(handler, ".")+1:]
+			params := make(map[string]bool)
+			for _, param := range op.Parameters {
+				params[param.Name] = true
+			}
+			for _, filter := range pgmodels.FiltersFor(middleware.AuthMap[funcName].ResourceType) {
+				assert.True(t, params[filter], "%s %s is missing filter %s", route.Method, route.Path, filter)
+			}
+			for _, param := range api.PagingParams {
+				assert.True(t, params[param], "%s %s is missing param %s", route.Method, route.Path, param)
+			}
+		}
+		assert.True(t, routeCount > 10)
+
+		// Every $ref must point to a schema in the document.
+		data, err := json.Marshal(doc)
+		require.Nil(t, err)
+		refs := schemaRef.FindAllStringSubmatch(string(data), -1)
+		assert.NotEmpty(t, refs)
+		for _, match := range refs {
+			assert.NotNil(t, doc.Components.Schemas[match[1]], "Missing schema %s", match[1])
+		}
+		assert.NotNil(t, doc.Components.Schemas["IntellectualObjectView"])
+		assert.NotNil(t, doc.Components.Schemas["RequestError"])
+	}
+}
+
+func TestOpenAPISpecMissingEndpoint(t *testing.T) {
+	routes := app.InitAppEngine(true).Routes()
+	for i := range routes {
+		if routes[i].Path == "/member-api/v3/objects" {
+			routes[i].Handler = "github.com/APTrust/registry/web/api/common.NoSuchHandler"
+		}
+	}
+	doc, err := api.GenerateOpenAPISpec(routes, constants.APIPrefixMember)
+	require.NotNil(t, doc)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "common.NoSuchHandler has no AuthMap entry")
+	assert.Contains(t, err.Error(), "common.NoSuchHandler has no entry in openAPIEndpoints")
+	assert.Equal(t, http.StatusText(http.StatusOK), doc.Paths["/objects"]["get"].Responses["200"].Description)
+}
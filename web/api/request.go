@@ -14,6 +14,11 @@ import (
 	"github.com/stretchr/stew/slice"
 )
 
+// PagingParams are the query string params that control paging and
+// sorting on index endpoints. Every index endpoint accepts these in
+// addition to the filters for its resource type.
+var PagingParams = []string{"sort", "page", "per_page", "cursor", "skip_count"}
+
 type Request struct {
 	PathAndQuery string                            `json:"pathAndQuery"`
 	CurrentUser  *pgmodels.User                    `json:"currentUser"`
@@ -50,11 +55,18 @@ func NewRequest(c *gin.Context) *Request {
 //
 // It's much better to fail and force the developer (that jerk!) to fix
 // the issues in preservation services.
-func (req *Request) ValidateFilters() error {
+//
+// Param extraParams lets endpoints allow params specific to themselves,
+// such as the format param on manifest requests.
+func (req *Request) ValidateFilters(extraParams ...string) error {
 	allowedFilters := pgmodels.FiltersFor(req.Auth.ResourceType)
-	allowedParams := append(allowedFilters, "sort", "page", "per_page")
+	allowedParams := append(allowedFilters, PagingParams...)
+	allowedParams = append(allowedParams, extraParams...)
 	invalid := make([]string, 0)
 	for paramName, _ := range req.GinContext.Request.URL.Query() {
+		if _, _, isOrGroup := pgmodels.ParseOrGroupKey(paramName); isOrGroup {
+			continue // checked below
+		}
 		if !slice.Contains(allowedParams, paramName) {
 			invalid = append(invalid, paramName)
 		}
@@ -62,7 +74,8 @@ func (req *Request) ValidateFilters() error {
 	if len(invalid) > 0 {
 		return fmt.Errorf("Invalid query params: %s", strings.Join(invalid, ", "))
 	}
-	return nil
+	_, err := pgmodels.NewFilterCollection().AddOrGroups(req.GinContext.Request.URL.Query(), allowedFilters)
+	return err
 }
 
 // GetFilterCollection returns a collection of filters the user
@@ -75,6 +88,7 @@ func (req *Request) GetFilterCollection() *pgmodels.FilterCollection {
 	for _, key := range allowedFilters {
 		fc.Add(key, req.GinContext.QueryArray(key))
 	}
+	fc.AddOrGroups(req.GinContext.Request.URL.Query(), allowedFilters)
 	for _, value := range req.GinContext.QueryArray("sort") {
 		fc.AddOrderBy(value)
 	}
@@ -96,24 +110,191 @@ func (req *Request) BaseURL() string {
 // orderByColumn and direction indicate a default sort order to be
 // applied if the request did not explicitly include a sort order.
 // (I.e. no sort=column__direction on the query string.)
+//
+// If the query string includes a cursor param (even an empty one),
+// this uses keyset paging instead of offset/limit. See
+// loadResourceListByCursor below.
 func (req *Request) LoadResourceList(items interface{}, orderByColumn, direction string) (*common.Pager, error) {
+	query, filterCollection, err := req.filteredQuery(items)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.IsCursorRequest() {
+		return req.loadResourceListByCursor(items, query, filterCollection, orderByColumn, direction)
+	}
+
+	if !filterCollection.HasExplicitSorting() {
+		// Full-text searches show best matches first.
+		if search := filterCollection.SearchFilter(); search != nil {
+			query.OrderByRank(search.Column, search.Values[0])
+		}
+		query.OrderBy(orderByColumn, direction)
+	}
+	pager, err := common.NewPager(req.GinContext, req.PathAndQuery, 20)
+	if err != nil {
+		return nil, err
+	}
+	query.Offset(pager.QueryOffset).Limit(pager.PerPage)
+
+	err = req.selectItems(query, items)
+	if err != nil {
+		return nil, err
+	}
+	count, err := req.countItems(query, items)
+	if err != nil {
+		return nil, err
+	}
+	pager.SetCounts(count, reflect.ValueOf(items).Elem().Len())
+	return pager, err
+}
+
+// LoadChanges loads one page of a "changes since" feed: all of the
+// resources matching the query string filters that changed at or after
+// the time in the required updated_at__gteq param. Mirror and sync jobs
+// use this to pick up changes without re-downloading everything.
+//
+// The feed always uses cursor paging, ordered by updated_at and then
+// id, oldest first, so clients can walk forward through the next links
+// until there are no more, then start the next sync from the updated_at
+// of the last item they saw. Explicit sort params are not allowed,
+// because the cursor depends on this order.
+func (req *Request) LoadChanges(items interface{}) (*common.Pager, error) {
+	valErr := common.NewValidationError()
+	if req.GinContext.Query("updated_at__gteq") == "" {
+		valErr.Errors["updated_at__gteq"] = "Changes feeds require updated_at__gteq."
+	}
+	if len(req.GinContext.QueryArray("sort")) > 0 {
+		valErr.Errors["sort"] = "Changes feeds are always sorted by updated_at, oldest first."
+	}
+	if len(valErr.Errors) > 0 {
+		return nil, valErr
+	}
+	query, filterCollection, err := req.filteredQuery(items)
+	if err != nil {
+		return nil, err
+	}
+	return req.loadResourceListByCursor(items, query, filterCollection, "updated_at", "asc")
+}
+
+// filteredQuery returns a query built from the filters in the query
+// string, restricted to the current user's institution, along with
+// the filter collection it came from.
+func (req *Request) filteredQuery(items interface{}) (*pgmodels.Query, *pgmodels.FilterCollection, error) {
 	// Ensure that items is a pointer to a slice of pointers, so we don't
 	// get a panic in call to Elem() below.
 	if items == nil || !strings.HasPrefix(reflect.TypeOf(items).String(), "*[]*pgmodels.") {
 		common.Context().Log.Error().Msgf("Request.LoadResourceList: Param items should be pointer to slice of pointers.")
-		return nil, common.ErrInvalidParam
+		return nil, nil, common.ErrInvalidParam
 	}
 
 	err := req.ValidateFilters()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	filterCollection := req.GetFilterCollection()
 	query, err := filterCollection.ToQuery()
+	if err != nil {
+		return nil, nil, err
+	}
+	req.ApplyInstitutionScope(query, items)
+	return query, filterCollection, nil
+}
+
+// IsCursorRequest returns true if the client requested keyset (cursor)
+// paging by including a cursor param in the query string. The param
+// may be empty on the request for the first page.
+func (req *Request) IsCursorRequest() bool {
+	_, isCursorRequest := req.GinContext.GetQuery("cursor")
+	return isCursorRequest
+}
+
+// SkipCount returns true if the client asked us to skip the count
+// query in cursor mode with skip_count=true. Counts on large tables
+// can take longer than fetching the page itself, and sync jobs walking
+// an entire institution generally don't need them.
+func (req *Request) SkipCount() bool {
+	return req.GinContext.Query("skip_count") == "true"
+}
+
+// loadResourceListByCursor pages through results by (sort column, id)
+// rather than by offset/limit. Offset paging has to read and discard
+// every row before the offset, which gets very slow deep into tables
+// like generic_files and premis_events. Keyset paging seeks directly
+// to the first row after the cursor.
+//
+// Cursor mode supports a single sort column, with id as a tie-breaker.
+// The cursor is tied to that column, so clients can't change the sort
+// order midway through a result set. Sort columns that may be NULL
+// are compared as coalesce(column, zero value). See cursorNullValue.
+func (req *Request) loadResourceListByCursor(items interface{}, query *pgmodels.Query, filterCollection *pgmodels.FilterCollection, orderByColumn, direction string) (*common.Pager, error) {
+	sortColumn, sortDirection := orderByColumn, direction
+	if filterCollection.HasExplicitSorting() {
+		sorts := filterCollection.SortParams()
+		if len(sorts) > 1 {
+			valErr := common.NewValidationError()
+			valErr.Errors["sort"] = "Cursor paging supports only one sort column. Results are always sorted by id after that column."
+			return nil, valErr
+		}
+		sortColumn, sortDirection = sorts[0].Column, sorts[0].Direction
+	}
+	nullValue := cursorNullValue(items, common.SanitizeIdentifier(sortColumn))
+	query.OrderByKeyset(sortColumn, sortDirection, nullValue)
+
+	pager, err := common.NewCursorPager(req.GinContext, req.PathAndQuery, 20)
 	if err != nil {
 		return nil, err
 	}
+
+	// Count before adding the keyset condition, so the count
+	// reflects the whole result set and not just what's left
+	// after the cursor.
+	countQuery := query.CopyForCount()
+
+	if pager.Cursor != "" {
+		cursor, err := common.DecodeCursor(pager.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Column != common.SanitizeIdentifier(sortColumn) {
+			return nil, common.ErrInvalidCursor
+		}
+		query.WhereAfterNullable(sortColumn, sortDirection, cursor.Value, cursor.ID, nullValue)
+	}
+
+	// Fetch one extra row so we know whether there's a next page.
+	query.Offset(0).Limit(pager.PerPage + 1)
+	err = req.selectItems(query, items)
+	if err != nil {
+		return nil, err
+	}
+
+	nextCursor := ""
+	itemList := reflect.ValueOf(items).Elem()
+	if itemList.Len() > pager.PerPage {
+		itemList.Set(itemList.Slice(0, pager.PerPage))
+		nextCursor, err = cursorForItem(itemList.Index(pager.PerPage-1), common.SanitizeIdentifier(sortColumn))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	count := -1
+	if !req.SkipCount() {
+		count, err = req.countItems(countQuery, items)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pager.SetCursorCounts(count, itemList.Len(), nextCursor)
+	return pager, nil
+}
+
+// ApplyInstitutionScope restricts non-admin users to records belonging
+// to their own institution. For alerts, it further restricts them to
+// alerts addressed to them.
+func (req *Request) ApplyInstitutionScope(query *pgmodels.Query, items interface{}) {
 	if !req.CurrentUser.IsAdmin() {
 		query.Where("institution_id", "=", req.CurrentUser.InstitutionID)
 		objType := reflect.ValueOf(items).Elem().Type()
@@ -121,15 +302,10 @@ func (req *Request) LoadResourceList(items interface{}, orderByColumn, direction
 			query.Where("user_id", "=", req.CurrentUser.ID)
 		}
 	}
-	if !filterCollection.HasExplicitSorting() {
-		query.OrderBy(orderByColumn, direction)
-	}
-	pager, err := common.NewPager(req.GinContext, req.PathAndQuery, 20)
-	if err != nil {
-		return nil, err
-	}
-	query.Offset(pager.QueryOffset).Limit(pager.PerPage)
+}
 
+func (req *Request) selectItems(query *pgmodels.Query, items interface{}) error {
+	var err error
 	// This sucks. Maybe there's a way to call the underlying
 	// type's select method, because that would handle this.
 	if reflect.ValueOf(items).Elem().Type() == reflect.TypeOf([]*pgmodels.GenericFile{}) {
@@ -139,10 +315,13 @@ func (req *Request) LoadResourceList(items interface{}, orderByColumn, direction
 	}
 	if err != nil {
 		common.Context().Log.Error().Msgf("Error running main query in API LoadResourceItemList. Where = %s. Error = %v", query.WhereClause(), err)
-		return nil, err
 	}
+	return err
+}
 
+func (req *Request) countItems(query *pgmodels.Query, items interface{}) (int, error) {
 	var count int
+	var err error
 	if pgmodels.CanCountFromView(query, items) {
 		common.Context().Log.Info().Msgf("API: Using view to count query '%s'", query.WhereClause())
 		count, err = pgmodels.GetCountFromView(query, items)
@@ -156,12 +335,73 @@ func (req *Request) LoadResourceList(items interface{}, orderByColumn, direction
 			common.Context().Log.Error().Msgf("Error running standard count with where clause %s: %v", query.WhereClause(), err)
 		}
 	}
+	return count, err
+}
 
-	if err != nil {
-		return nil, err
+// cursorNotNullColumns are sort columns that are never NULL in any of
+// the tables and views the API lists. We compare these directly, so
+// Postgres can use the (column, id) indexes from migration 013.
+var cursorNotNullColumns = []string{"id", "created_at", "updated_at"}
+
+// cursorNullValue returns the value that NULLs in column should sort
+// as in cursor mode. This is the zero value of the column's struct field,
+// because that's what go-pg loads for a NULL, and so it's the value
+// cursorForItem puts into the cursor for a row with a NULL. Returns nil
+// for columns that are never NULL, or if items has no field for column.
+func cursorNullValue(items interface{}, column string) interface{} {
+	if slice.Contains(cursorNotNullColumns, column) {
+		return nil
 	}
-	pager.SetCounts(count, reflect.ValueOf(items).Elem().Len())
-	return pager, err
+	itemType := reflect.TypeOf(items).Elem().Elem().Elem()
+	field, ok := fieldByJsonName(reflect.New(itemType), column)
+	if !ok || field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		return nil
+	}
+	return reflect.Zero(field.Type()).Interface()
+}
+
+// cursorForItem returns an encoded cursor pointing to item, which
+// should be a pointer to one of our pgmodels structs. Param column
+// is the name of the sort column. We find the corresponding struct
+// field by its json tag, which matches the column name in all of
+// our models and views.
+func cursorForItem(item reflect.Value, column string) (string, error) {
+	idField, ok := fieldByJsonName(item, "id")
+	if !ok {
+		return "", common.ErrInvalidCursor
+	}
+	valueField, ok := fieldByJsonName(item, column)
+	if !ok {
+		common.Context().Log.Warn().Msgf("API cursor: type %s has no field for sort column %s", item.Type().String(), column)
+		return "", common.ErrInvalidCursor
+	}
+	return common.NewCursor(column, valueField.Interface(), idField.Int()).Encode()
+}
+
+// fieldByJsonName returns the struct field whose json tag matches name.
+// This descends into embedded structs, such as TimestampModel.
+func fieldByJsonName(v reflect.Value, name string) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			if f, ok := fieldByJsonName(v.Field(i), name); ok {
+				return f, true
+			}
+			continue
+		}
+		tagName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tagName == name && field.IsExported() {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
 }
 
 // AssertValidIDs returns an error if resource or institution ID in an
@@ -0,0 +1,98 @@
+package webui
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/helpers"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyIndex shows the current user's API keys, including
+// revoked and expired keys.
+//
+// GET /api_keys
+func APIKeyIndex(c *gin.Context) {
+	req := NewRequest(c)
+	query := pgmodels.NewQuery().Where("user_id", "=", req.CurrentUser.ID).OrderBy("created_at", "desc")
+	keys, err := pgmodels.APIKeySelect(query)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["apiKeys"] = keys
+	c.HTML(http.StatusOK, "api_keys/index.html", req.TemplateData)
+}
+
+// APIKeyNew shows the form for creating a new API key.
+//
+// GET /api_keys/new
+func APIKeyNew(c *gin.Context) {
+	req := NewRequest(c)
+	key, err := pgmodels.NewAPIKey(req.CurrentUser.ID)
+	if AbortIfError(c, err) {
+		return
+	}
+	form := forms.NewAPIKeyForm(key)
+	req.TemplateData["form"] = form
+	c.HTML(http.StatusOK, form.Template, req.TemplateData)
+}
+
+// APIKeyCreate creates a new API key for the current user and
+// displays it. This is the only time the user will see the
+// plaintext key.
+//
+// POST /api_keys/new
+func APIKeyCreate(c *gin.Context) {
+	req := NewRequest(c)
+	key, err := pgmodels.NewAPIKey(req.CurrentUser.ID)
+	if AbortIfError(c, err) {
+		return
+	}
+	c.ShouldBind(key)
+
+	// Users can create keys only for themselves.
+	key.ID = 0
+	key.UserID = req.CurrentUser.ID
+	if AbortIfError(c, pgmodels.AuditChangesBy(key, helpers.AuditActor(c))) {
+		return
+	}
+
+	form := forms.NewAPIKeyForm(key)
+	req.TemplateData["form"] = form
+	if form.Save() {
+		common.Context().Log.Info().Msgf("User %d created API key %d (%s) with scope %s", key.UserID, key.ID, key.Prefix, key.Scope)
+		req.TemplateData["apiKey"] = key
+		c.HTML(http.StatusCreated, "api_keys/created.html", req.TemplateData)
+	} else {
+		req.TemplateData["FormError"] = form.Error
+		c.HTML(form.Status, form.Template, req.TemplateData)
+	}
+}
+
+// APIKeyRevoke revokes one of the current user's API keys.
+//
+// POST /api_keys/revoke/:id
+func APIKeyRevoke(c *gin.Context) {
+	req := NewRequest(c)
+	key, err := pgmodels.APIKeyByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(key, helpers.AuditActor(c))) {
+		return
+	}
+	if key.UserID != req.CurrentUser.ID {
+		common.Context().Log.Warn().Msgf("Permission denied: User %d tried to revoke API key %d belonging to user %d", req.CurrentUser.ID, key.ID, key.UserID)
+		AbortIfError(c, common.ErrPermissionDenied)
+		return
+	}
+	err = key.Revoke()
+	if AbortIfError(c, err) {
+		return
+	}
+	helpers.SetFlashCookie(c, fmt.Sprintf("API key '%s' has been revoked.", key.Label))
+	c.Redirect(http.StatusSeeOther, "/api_keys")
+}
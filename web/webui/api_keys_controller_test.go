@@ -0,0 +1,61 @@
+package webui_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/pgmodels"
+	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyCreateAndRevoke(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	tu.Inst1UserClient.GET("/api_keys").Expect().Status(http.StatusOK)
+	tu.Inst1UserClient.GET("/api_keys/new").Expect().Status(http.StatusOK)
+
+	html := tu.Inst1UserClient.POST("/api_keys/new").
+		WithFormField(constants.CSRFTokenName, tu.Inst1UserToken).
+		WithFormField("Label", "Test key for user").
+		WithFormField("Scope", constants.APIKeyScopeReadWrite).
+		WithFormField("UserID", tu.Inst2User.ID).
+		Expect().Status(http.StatusCreated).Body().Raw()
+	assert.Contains(t, html, "Test key for user")
+
+	// Key should belong to the current user, even though
+	// the form tried to assign it to someone else.
+	query := pgmodels.NewQuery().Where("label", "=", "Test key for user")
+	key, err := pgmodels.APIKeyGet(query)
+	require.Nil(t, err)
+	assert.Equal(t, tu.Inst1User.ID, key.UserID)
+	assert.Equal(t, constants.APIKeyScopeReadWrite, key.Scope)
+
+	// Invalid data should re-display the form.
+	tu.Inst1UserClient.POST("/api_keys/new").
+		WithFormField(constants.CSRFTokenName, tu.Inst1UserToken).
+		WithFormField("Label", "Bad IPs").
+		WithFormField("Scope", constants.APIKeyScopeReadOnly).
+		WithFormField("AllowedIPs", "not-an-ip").
+		Expect().Status(http.StatusBadRequest)
+
+	// Other users can't revoke this key. Not even the
+	// inst admin at the same institution.
+	revokeURL := fmt.Sprintf("/api_keys/revoke/%d", key.ID)
+	tu.Inst1AdminClient.POST(revokeURL).
+		WithFormField(constants.CSRFTokenName, tu.Inst1AdminToken).
+		Expect().Status(http.StatusForbidden)
+	tu.Inst2UserClient.POST(revokeURL).
+		WithFormField(constants.CSRFTokenName, tu.Inst2UserToken).
+		Expect().Status(http.StatusForbidden)
+
+	tu.Inst1UserClient.POST(revokeURL).
+		WithFormField(constants.CSRFTokenName, tu.Inst1UserToken).
+		Expect().Status(http.StatusOK)
+	key, err = pgmodels.APIKeyByID(key.ID)
+	require.Nil(t, err)
+	assert.False(t, key.RevokedAt.IsZero())
+}
@@ -0,0 +1,38 @@
+package webui
+
+import (
+	"net/http"
+
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLogIndex shows a list of audit log entries, newest first.
+// Institutional admins see only changes to their own institution's
+// records.
+// GET /audit_logs
+func AuditLogIndex(c *gin.Context) {
+	req := NewRequest(c)
+	template := "audit_logs/index.html"
+	var entries []*pgmodels.AuditLog
+	err := req.LoadResourceList(&entries, "created_at", "desc", forms.NewAuditLogFilterForm)
+	if AbortIfError(c, err) {
+		return
+	}
+	c.HTML(http.StatusOK, template, req.TemplateData)
+}
+
+// AuditLogShow shows a single audit log entry, including the fields
+// that changed.
+// GET /audit_logs/show/:id
+func AuditLogShow(c *gin.Context) {
+	req := NewRequest(c)
+	entry, err := pgmodels.AuditLogByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["entry"] = entry
+	req.TemplateData["changes"] = entry.Changes()
+	c.HTML(http.StatusOK, "audit_logs/show.html", req.TemplateData)
+}
@@ -0,0 +1,76 @@
+package webui_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLogIndex(t *testing.T) {
+	testutil.InitHTTPTests(t)
+
+	inst1Changes := []string{
+		"/users/edit/3",
+		"/institutions/edit_prefs/2",
+	}
+	inst2Changes := []string{
+		"/users/delete/7",
+	}
+	noInstChanges := []string{
+		"/storage_options/edit/1",
+	}
+
+	// Other tests add entries to the audit log, so we filter
+	// out anything newer than the fixtures.
+	html := testutil.SysAdminClient.GET("/audit_logs").
+		WithQuery("created_at__lteq", "2021-12-31").
+		Expect().Status(http.StatusOK).Body().Raw()
+	testutil.AssertMatchesAll(t, html, inst1Changes)
+	testutil.AssertMatchesAll(t, html, inst2Changes)
+	testutil.AssertMatchesAll(t, html, noInstChanges)
+
+	html = testutil.SysAdminClient.GET("/audit_logs").
+		WithQuery("model_type", "User").
+		WithQuery("action", "delete").
+		Expect().Status(http.StatusOK).Body().Raw()
+	testutil.AssertMatchesAll(t, html, inst2Changes)
+	testutil.AssertMatchesNone(t, html, inst1Changes)
+	testutil.AssertMatchesResultCount(t, html, 1)
+
+	// Inst admins see only their own institution's changes.
+	html = testutil.Inst1AdminClient.GET("/audit_logs").
+		WithQuery("created_at__lteq", "2021-12-31").
+		Expect().Status(http.StatusOK).Body().Raw()
+	testutil.AssertMatchesAll(t, html, inst1Changes)
+	testutil.AssertMatchesNone(t, html, inst2Changes)
+	testutil.AssertMatchesNone(t, html, noInstChanges)
+
+	// Inst users can't see the audit log.
+	testutil.Inst1UserClient.GET("/audit_logs").
+		Expect().Status(http.StatusForbidden)
+}
+
+func TestAuditLogShow(t *testing.T) {
+	testutil.InitHTTPTests(t)
+
+	html := testutil.Inst1AdminClient.GET("/audit_logs/show/1").
+		Expect().Status(http.StatusOK).Body().Raw()
+	testutil.AssertMatchesAll(t, html, []string{
+		"admin@inst1.edu",
+		"10.0.0.2",
+		"POST /users/edit/3",
+		"Inst One User",
+		"Inst One User (Edited)",
+	})
+	// Unchanged fields aren't listed.
+	assert.NotContains(t, html, "institutional_user")
+
+	testutil.SysAdminClient.GET("/audit_logs/show/1").
+		Expect().Status(http.StatusOK)
+	testutil.Inst2AdminClient.GET("/audit_logs/show/1").
+		Expect().Status(http.StatusForbidden)
+	testutil.Inst1UserClient.GET("/audit_logs/show/1").
+		Expect().Status(http.StatusForbidden)
+}
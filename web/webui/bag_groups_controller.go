@@ -0,0 +1,44 @@
+package webui
+
+import (
+	"net/http"
+
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/gin-gonic/gin"
+)
+
+// BagGroupIndex shows a list of bag groups.
+// GET /bag_groups
+func BagGroupIndex(c *gin.Context) {
+	req := NewRequest(c)
+	template := "bag_groups/index.html"
+	var bagGroups []*pgmodels.BagGroupView
+	err := req.LoadResourceList(&bagGroups, "identifier", "asc", forms.NewBagGroupFilterForm)
+	if AbortIfError(c, err) {
+		return
+	}
+	c.HTML(http.StatusOK, template, req.TemplateData)
+}
+
+// BagGroupShow shows a bag group, with a breakdown of its contents
+// by storage option and a list of legal holds placed on the group.
+// GET /bag_groups/show/:id
+func BagGroupShow(c *gin.Context) {
+	req := NewRequest(c)
+	bagGroup, err := pgmodels.BagGroupViewByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	err = bagGroup.LoadStorageOptionStats()
+	if AbortIfError(c, err) {
+		return
+	}
+	legalHolds, err := pgmodels.LegalHoldsForBagGroup(bagGroup.ID)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["bagGroup"] = bagGroup
+	req.TemplateData["legalHolds"] = legalHolds
+	c.HTML(http.StatusOK, "bag_groups/show.html", req.TemplateData)
+}
@@ -0,0 +1,70 @@
+package webui_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/APTrust/registry/web/testutil"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBagGroupIndex(t *testing.T) {
+	testutil.InitHTTPTests(t)
+
+	inst1Groups := []string{
+		"carolina-1",
+		"carolina-2",
+	}
+	inst2Groups := []string{
+		"dakota-1",
+		"dakota-2",
+	}
+
+	html := testutil.SysAdminClient.GET("/bag_groups").
+		Expect().Status(http.StatusOK).Body().Raw()
+	testutil.AssertMatchesAll(t, html, inst1Groups)
+	testutil.AssertMatchesAll(t, html, inst2Groups)
+	testutil.AssertMatchesResultCount(t, html, 4)
+
+	html = testutil.SysAdminClient.GET("/bag_groups").
+		WithQuery("identifier__starts_with", "dakota").
+		Expect().Status(http.StatusOK).Body().Raw()
+	testutil.AssertMatchesAll(t, html, inst2Groups)
+	testutil.AssertMatchesNone(t, html, inst1Groups)
+
+	// Non-admins see only their own institution's groups.
+	for _, client := range []*httpexpect.Expect{testutil.Inst1AdminClient, testutil.Inst1UserClient} {
+		html = client.GET("/bag_groups").
+			Expect().Status(http.StatusOK).Body().Raw()
+		testutil.AssertMatchesAll(t, html, inst1Groups)
+		testutil.AssertMatchesNone(t, html, inst2Groups)
+		testutil.AssertMatchesResultCount(t, html, 2)
+	}
+}
+
+func TestBagGroupShow(t *testing.T) {
+	testutil.InitHTTPTests(t)
+
+	expected := []string{
+		"carolina-2",
+		"Institution One",
+		"Glacier-Deep-OH",
+		"Standard",
+		"/objects?state=A&bag_group_identifier=carolina-2&institution_id=2",
+	}
+	for _, client := range []*httpexpect.Expect{testutil.SysAdminClient, testutil.Inst1AdminClient, testutil.Inst1UserClient} {
+		html := client.GET("/bag_groups/show/2").
+			Expect().Status(http.StatusOK).Body().Raw()
+		testutil.AssertMatchesAll(t, html, expected)
+	}
+
+	testutil.Inst2AdminClient.GET("/bag_groups/show/2").
+		Expect().Status(http.StatusForbidden)
+	testutil.Inst2UserClient.GET("/bag_groups/show/2").
+		Expect().Status(http.StatusForbidden)
+
+	html := testutil.Inst2UserClient.GET("/bag_groups/show/3").
+		Expect().Status(http.StatusOK).Body().Raw()
+	assert.Contains(t, html, "Never")
+}
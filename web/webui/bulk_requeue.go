@@ -0,0 +1,67 @@
+package webui
+
+import (
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/gin-gonic/gin"
+)
+
+// BulkRequeueFilterCollection returns the bulk requeue filters from
+// the request's query string. See pgmodels.WorkItemBulkRequeueFilters.
+func BulkRequeueFilterCollection(c *gin.Context) *pgmodels.FilterCollection {
+	fc := pgmodels.NewFilterCollection()
+	for _, key := range pgmodels.WorkItemBulkRequeueFilters {
+		fc.Add(key, c.QueryArray(key))
+	}
+	return fc
+}
+
+// BulkRequeueWorkItems requeues all WorkItems matching the filters in
+// fc to targetStage. (See pgmodels.BulkRequeuePreview for what happens
+// when targetStage is empty.) If dryRun is true, this returns what it
+// would do without requeueing anything. Items that can't be requeued
+// are skipped, and their errors appear in the result.
+//
+// The web UI and admin API share this function.
+func BulkRequeueWorkItems(fc *pgmodels.FilterCollection, targetStage string, user *pgmodels.User, dryRun bool) (*pgmodels.BulkRequeueResult, error) {
+	items, err := pgmodels.BulkRequeuePreview(fc, targetStage)
+	if err != nil {
+		return nil, err
+	}
+	result := &pgmodels.BulkRequeueResult{
+		DryRun:  dryRun,
+		Matched: len(items),
+		Items:   items,
+	}
+	for _, item := range items {
+		if item.Error == "" && !dryRun {
+			requeueBulkItem(item, user)
+		}
+		if item.Requeued {
+			result.Requeued++
+		} else if item.Error != "" {
+			result.Errors++
+		}
+	}
+	if !dryRun {
+		common.Context().Log.Info().Msgf("User %s bulk requeued %d of %d WorkItems (%d errors)", user.Email, result.Requeued, result.Matched, result.Errors)
+	}
+	return result, nil
+}
+
+// requeueBulkItem requeues a single item from a bulk requeue and
+// records the outcome in item.
+func requeueBulkItem(item *pgmodels.BulkRequeueItem, user *pgmodels.User) {
+	ctx := common.Context()
+	workItem, err := pgmodels.WorkItemByID(item.WorkItemID)
+	if err == nil {
+		ctx.Log.Info().Msgf("User %s is requeueing WorkItem %d to %s", user.Email, item.WorkItemID, item.TargetStage)
+		err = workItem.SetForRequeue(item.TargetStage)
+	}
+	if err != nil {
+		ctx.Log.Error().Msgf("Bulk requeue of WorkItem %d failed: %v", item.WorkItemID, err)
+		item.Error = err.Error()
+		return
+	}
+	item.Requeued = true
+}
@@ -22,6 +22,7 @@ type Deletion struct {
 	// or cancel the request before we move forward.
 	InstAdmins []*pgmodels.User
 
+	auditActor  *pgmodels.AuditActor
 	baseURL     string
 	currentUser *pgmodels.User
 }
@@ -29,8 +30,9 @@ type Deletion struct {
 // NewDeletionForFile creates a new DeletionRequest for a GenericFile
 // and returns the Deletion object. This constructor is only for initializing
 // new DeletionRequests, not for reviewing, approving or cancelling
-// existing requests.
-func NewDeletionForFile(genericFileID int64, currentUser *pgmodels.User, baseURL string) (*Deletion, error) {
+// existing requests. The audit log attributes changes to the
+// DeletionRequest to actor.
+func NewDeletionForFile(genericFileID int64, currentUser *pgmodels.User, baseURL string, actor *pgmodels.AuditActor) (*Deletion, error) {
 	// Make sure there are no pending work items for this
 	// generic file or its parent object.
 	pendingWorkItems, err := pgmodels.WorkItemsPendingForFile(genericFileID)
@@ -41,7 +43,18 @@ func NewDeletionForFile(genericFileID int64, currentUser *pgmodels.User, baseURL
 		return nil, common.ErrPendingWorkItems
 	}
 
+	// Make sure the file's object isn't under a legal hold.
+	gf, err := pgmodels.GenericFileByID(genericFileID)
+	if err != nil {
+		return nil, err
+	}
+	err = pgmodels.AssertNoLegalHold(gf.IntellectualObjectID)
+	if err != nil {
+		return nil, err
+	}
+
 	del := &Deletion{
+		auditActor:  actor,
 		baseURL:     baseURL,
 		currentUser: currentUser,
 	}
@@ -56,15 +69,16 @@ func NewDeletionForFile(genericFileID int64, currentUser *pgmodels.User, baseURL
 // NewDeletionForObject creates a new DeletionRequest for an IntellectualObject
 // and returns the Deletion object. This constructor is only for initializing
 // new DeletionRequests, not for reviewing, approving or cancelling
-// existing requests.
-func NewDeletionForObject(objID int64, currentUser *pgmodels.User, baseURL string) (*Deletion, error) {
+// existing requests. The audit log attributes changes to the
+// DeletionRequest to actor.
+func NewDeletionForObject(objID int64, currentUser *pgmodels.User, baseURL string, actor *pgmodels.AuditActor) (*Deletion, error) {
 	obj, err := pgmodels.IntellectualObjectByID(objID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Make sure there are no pending work items for this object.
-	pendingWorkItems, err := pgmodels.WorkItemsPendingForObject(obj.ID, obj.BagName)
+	pendingWorkItems, err := pgmodels.WorkItemsPendingForObject(obj.InstitutionID, obj.BagName)
 	if err != nil {
 		return nil, err
 	}
@@ -72,7 +86,14 @@ func NewDeletionForObject(objID int64, currentUser *pgmodels.User, baseURL strin
 		return nil, common.ErrPendingWorkItems
 	}
 
+	// Make sure the object isn't under a legal hold.
+	err = pgmodels.AssertNoLegalHold(objID)
+	if err != nil {
+		return nil, err
+	}
+
 	del := &Deletion{
+		auditActor:  actor,
 		baseURL:     baseURL,
 		currentUser: currentUser,
 	}
@@ -87,8 +108,9 @@ func NewDeletionForObject(objID int64, currentUser *pgmodels.User, baseURL strin
 // NewDeletionForObjectBatch creates a new DeletionRequest for a batch of
 // IntellectualObjects and returns the Deletion object. This constructor
 // is only for initializing new DeletionRequests, not for reviewing, approving
-// or cancelling existing requests.
-func NewDeletionForObjectBatch(requestorID, institutionID int64, objIDs []int64, baseURL string) (*Deletion, error) {
+// or cancelling existing requests. The audit log attributes changes to
+// the DeletionRequest to actor.
+func NewDeletionForObjectBatch(requestorID, institutionID int64, objIDs []int64, baseURL string, actor *pgmodels.AuditActor) (*Deletion, error) {
 
 	requestingUser, err := pgmodels.UserByID(requestorID)
 	if err != nil {
@@ -120,7 +142,15 @@ func NewDeletionForObjectBatch(requestorID, institutionID int64, objIDs []int64,
 		return nil, common.ErrPendingWorkItems
 	}
 
+	// Make sure none of these objects is under a legal hold.
+	err = pgmodels.AssertNoLegalHold(objIDs...)
+	if err != nil {
+		common.Context().Log.Warn().Msgf("Some objects in batch deletion request are under a legal hold. Object IDs: %v", objIDs)
+		return nil, err
+	}
+
 	del := &Deletion{
+		auditActor:  actor,
 		baseURL:     baseURL,
 		currentUser: requestingUser,
 	}
@@ -134,9 +164,11 @@ func NewDeletionForObjectBatch(requestorID, institutionID int64, objIDs []int64,
 
 // NewDeletionForReview pulls up information about an existing deletion
 // request that an institutional admin will review before deciding whether
-// to approve or cancel the request.
-func NewDeletionForReview(deletionRequestID int64, currentUser *pgmodels.User, baseURL, token string) (*Deletion, error) {
+// to approve or cancel the request. The audit log attributes the
+// approval or cancellation to actor.
+func NewDeletionForReview(deletionRequestID int64, currentUser *pgmodels.User, baseURL, token string, actor *pgmodels.AuditActor) (*Deletion, error) {
 	del := &Deletion{
+		auditActor:  actor,
 		baseURL:     baseURL,
 		currentUser: currentUser,
 	}
@@ -153,6 +185,27 @@ func NewDeletionForReview(deletionRequestID int64, currentUser *pgmodels.User, b
 	return del, err
 }
 
+// NewDeletionForAPIReview pulls up an existing deletion request for
+// an institutional admin who is approving or cancelling it through the
+// member API. API users authenticate with their API key and never see
+// the emailed confirmation token, so this skips the token check.
+// DeletionRequest.Validate still enforces the two-admin rule when the
+// request is approved. The audit log attributes the approval or
+// cancellation to actor.
+func NewDeletionForAPIReview(deletionRequestID int64, currentUser *pgmodels.User, baseURL string, actor *pgmodels.AuditActor) (*Deletion, error) {
+	del := &Deletion{
+		auditActor:  actor,
+		baseURL:     baseURL,
+		currentUser: currentUser,
+	}
+	err := del.loadDeletionRequest(deletionRequestID)
+	if err != nil {
+		return nil, err
+	}
+	err = del.loadInstAdmins()
+	return del, err
+}
+
 // loadDeletionRequest loads an existing request so an admin can
 // review it for approval or cancellation.
 func (del *Deletion) loadDeletionRequest(deletionRequestID int64) error {
@@ -160,6 +213,10 @@ func (del *Deletion) loadDeletionRequest(deletionRequestID int64) error {
 	if err != nil {
 		return err
 	}
+	err = pgmodels.AuditChangesBy(deletionRequest, del.auditActor)
+	if err != nil {
+		return err
+	}
 	del.DeletionRequest = deletionRequest
 	return nil
 }
@@ -195,6 +252,10 @@ func (del *Deletion) initFileDeletionRequest(genericFileID int64) error {
 	if err != nil {
 		return err
 	}
+	err = pgmodels.AuditChangesBy(deletionRequest, del.auditActor)
+	if err != nil {
+		return err
+	}
 	deletionRequest.InstitutionID = gf.InstitutionID
 	deletionRequest.RequestedByID = del.currentUser.ID
 	deletionRequest.RequestedAt = time.Now().UTC()
@@ -218,6 +279,10 @@ func (del *Deletion) initObjectDeletionRequest(institutionID int64, objIDs []int
 	if err != nil {
 		return err
 	}
+	err = pgmodels.AuditChangesBy(deletionRequest, del.auditActor)
+	if err != nil {
+		return err
+	}
 	deletionRequest.InstitutionID = institutionID
 	deletionRequest.RequestedByID = del.currentUser.ID
 	deletionRequest.RequestedAt = time.Now().UTC()
@@ -237,8 +302,9 @@ func (del *Deletion) initObjectDeletionRequest(institutionID int64, objIDs []int
 	return nil
 }
 
-// CreateWorkItem creates a WorkItem describing this deletion. We call
-// this only if the admin approves the deletion.
+// CreateObjDeletionWorkItem adds a WorkItem describing the deletion of
+// obj to the DeletionRequest. This doesn't save or queue the item. See
+// CreateAndQueueWorkItems.
 func (del *Deletion) CreateObjDeletionWorkItem(obj *pgmodels.IntellectualObject) error {
 	if del.DeletionRequest == nil || del.DeletionRequest.ID == 0 {
 		errMsg := "Cannot create deletion work item because deletion request id is zero."
@@ -251,11 +317,13 @@ func (del *Deletion) CreateObjDeletionWorkItem(obj *pgmodels.IntellectualObject)
 		common.Context().Log.Error().Msgf("%s", err.Error())
 		return err
 	}
-	common.Context().Log.Warn().Msgf("Created deletion work item %d with deletion request id %d", workItem.ID, workItem.DeletionRequestID)
 	del.DeletionRequest.WorkItems = append(del.DeletionRequest.WorkItems, workItem)
 	return nil
 }
 
+// CreateFileDeletionWorkItem adds a WorkItem describing the deletion of
+// gf to the DeletionRequest. This doesn't save or queue the item. See
+// CreateAndQueueWorkItems.
 func (del *Deletion) CreateFileDeletionWorkItem(gf *pgmodels.GenericFile) error {
 	obj, err := pgmodels.IntellectualObjectByID(gf.IntellectualObjectID)
 	if err != nil {
@@ -270,29 +338,12 @@ func (del *Deletion) CreateFileDeletionWorkItem(gf *pgmodels.GenericFile) error
 	return nil
 }
 
-// QueueWorkItem sends the WorkItem.ID into the appropriate NSQ topic.
-// We call this after calling CreateWorkItem, and only if the admin
-// approves the deletion.
-func (del *Deletion) QueueWorkItems() error {
-	for _, item := range del.DeletionRequest.WorkItems {
-		if item == nil {
-			return common.ErrInternal
-		}
-		topic, err := constants.TopicFor(item.Action, item.Stage)
-		if err != nil {
-			return err
-		}
-		ctx := common.Context()
-		ctx.Log.Info().Msgf("Queueing WorkItem %d to topic %s", item.ID, topic)
-		err = ctx.NSQClient.Enqueue(topic, item.ID)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-// CreateAndQueueWorkItem creates and queues a deletion WorkItem.
+// CreateAndQueueWorkItems creates a deletion WorkItem for each file and
+// object in the DeletionRequest and saves them, with an NSQ outbox
+// message for each, in the transaction that saves the request. A failure
+// partway through saves none of them, so we can't end up deleting some
+// of the request's files and objects but not others. Messages that
+// can't be delivered right away are retried by the outbox cron job.
 // We call this only if the admin approves the DeletionRequest.
 func (del *Deletion) CreateAndQueueWorkItems() error {
 	var err error
@@ -308,11 +359,87 @@ func (del *Deletion) CreateAndQueueWorkItems() error {
 			return err
 		}
 	}
+	err = del.DeletionRequest.SaveAndQueueWorkItems()
+	if err != nil {
+		return err
+	}
+	for _, item := range del.DeletionRequest.WorkItems {
+		common.Context().Log.Warn().Msgf("Queued deletion work item %d with deletion request id %d", item.ID, item.DeletionRequestID)
+	}
+	return nil
+}
+
+// Approve marks the DeletionRequest as confirmed by the current user,
+// then creates and queues the deletion WorkItems and alerts the
+// institution's admins. This returns common.ErrRequestAlreadyCancelled
+// or common.ErrRequestAlreadyApproved if someone has already reviewed
+// the request, common.ErrLegalHold if someone placed a legal hold on
+// any of the request's objects or files after the request was made,
+// and a validation error if the current user is not allowed to
+// approve it.
+func (del *Deletion) Approve() error {
+	err := del.assertNotReviewed()
+	if err == nil {
+		err = del.assertNoLegalHold()
+	}
+	if err != nil {
+		common.Context().Log.Error().Msgf("Cannot approve deletion request %d: %v", del.DeletionRequest.ID, err)
+		return err
+	}
+	del.DeletionRequest.Confirm(del.currentUser)
 	err = del.DeletionRequest.Save()
 	if err != nil {
 		return err
 	}
-	return del.QueueWorkItems()
+	err = del.CreateAndQueueWorkItems()
+	if err != nil {
+		return err
+	}
+	_, err = del.CreateApprovalAlert()
+	return err
+}
+
+// Cancel marks the DeletionRequest as cancelled by the current user
+// and alerts the institution's admins. This returns
+// common.ErrRequestAlreadyCancelled or common.ErrRequestAlreadyApproved
+// if someone has already reviewed the request.
+func (del *Deletion) Cancel() error {
+	err := del.assertNotReviewed()
+	if err != nil {
+		common.Context().Log.Error().Msgf("Cannot cancel deletion request %d: %v", del.DeletionRequest.ID, err)
+		return err
+	}
+	del.DeletionRequest.Cancel(del.currentUser)
+	err = del.DeletionRequest.Save()
+	if err != nil {
+		return err
+	}
+	_, err = del.CreateCancellationAlert()
+	return err
+}
+
+// assertNotReviewed returns an error if the DeletionRequest has
+// already been approved or cancelled.
+func (del *Deletion) assertNotReviewed() error {
+	if !del.DeletionRequest.CancelledAt.IsZero() {
+		return common.ErrRequestAlreadyCancelled
+	} else if !del.DeletionRequest.ConfirmedAt.IsZero() {
+		return common.ErrRequestAlreadyApproved
+	}
+	return nil
+}
+
+// assertNoLegalHold returns common.ErrLegalHold if any of the objects
+// or files in the DeletionRequest is under an active legal hold.
+func (del *Deletion) assertNoLegalHold() error {
+	objIDs := make([]int64, 0)
+	for _, obj := range del.DeletionRequest.IntellectualObjects {
+		objIDs = append(objIDs, obj.ID)
+	}
+	for _, gf := range del.DeletionRequest.GenericFiles {
+		objIDs = append(objIDs, gf.IntellectualObjectID)
+	}
+	return pgmodels.AssertNoLegalHold(objIDs...)
 }
 
 // CreateRequestAlert creates an alert saying that a user has requested
@@ -6,6 +6,7 @@ import (
 
 	"github.com/APTrust/registry/common"
 	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/helpers"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/gin-gonic/gin"
 )
@@ -68,7 +69,7 @@ func deletionRequestLoad(req *Request) error {
 // GET /deletions/review/:id?token=<token>
 func DeletionRequestReview(c *gin.Context) {
 	req := NewRequest(c)
-	del, err := NewDeletionForReview(req.Auth.ResourceID, req.CurrentUser, req.BaseURL(), c.Query("token"))
+	del, err := NewDeletionForReview(req.Auth.ResourceID, req.CurrentUser, req.BaseURL(), c.Query("token"), helpers.AuditActor(c))
 	if AbortIfError(c, err) {
 		return
 	}
@@ -114,32 +115,12 @@ func DeletionRequestReview(c *gin.Context) {
 // POST /deletions/approve/:id
 func DeletionRequestApprove(c *gin.Context) {
 	req := NewRequest(c)
-	del, err := NewDeletionForReview(req.Auth.ResourceID, req.CurrentUser, req.BaseURL(), c.PostForm("token"))
+	del, err := NewDeletionForReview(req.Auth.ResourceID, req.CurrentUser, req.BaseURL(), c.PostForm("token"), helpers.AuditActor(c))
 	if AbortIfError(c, err) {
 		return
 	}
 
-	if !del.DeletionRequest.CancelledAt.IsZero() {
-		err = common.ErrRequestAlreadyCancelled
-	} else if !del.DeletionRequest.ConfirmedAt.IsZero() {
-		err = common.ErrRequestAlreadyApproved
-	}
-
-	if AbortIfError(c, err) {
-		common.Context().Log.Error().Msgf("Cannot approve deletion request %d: %v", del.DeletionRequest.ID, err)
-		return
-	}
-
-	del.DeletionRequest.Confirm(req.CurrentUser)
-	err = del.DeletionRequest.Save()
-	if AbortIfError(c, err) {
-		return
-	}
-	err = del.CreateAndQueueWorkItems()
-	if AbortIfError(c, err) {
-		return
-	}
-	_, err = del.CreateApprovalAlert()
+	err = del.Approve()
 	if AbortIfError(c, err) {
 		return
 	}
@@ -158,28 +139,12 @@ func DeletionRequestApprove(c *gin.Context) {
 // POST /deletions/cancel/:id
 func DeletionRequestCancel(c *gin.Context) {
 	req := NewRequest(c)
-	del, err := NewDeletionForReview(req.Auth.ResourceID, req.CurrentUser, req.BaseURL(), c.PostForm("token"))
-	if AbortIfError(c, err) {
-		return
-	}
-
-	if !del.DeletionRequest.CancelledAt.IsZero() {
-		err = common.ErrRequestAlreadyCancelled
-	} else if !del.DeletionRequest.ConfirmedAt.IsZero() {
-		err = common.ErrRequestAlreadyApproved
-	}
-
+	del, err := NewDeletionForReview(req.Auth.ResourceID, req.CurrentUser, req.BaseURL(), c.PostForm("token"), helpers.AuditActor(c))
 	if AbortIfError(c, err) {
-		common.Context().Log.Error().Msgf("Cannot cancel deletion request %d: %v", del.DeletionRequest.ID, err)
 		return
 	}
 
-	del.DeletionRequest.Cancel(req.CurrentUser)
-	err = del.DeletionRequest.Save()
-	if AbortIfError(c, err) {
-		return
-	}
-	_, err = del.CreateCancellationAlert()
+	err = del.Cancel()
 	if AbortIfError(c, err) {
 		return
 	}
@@ -40,7 +40,7 @@ func TestNewDeletionForFile(t *testing.T) {
 	require.Nil(t, err)
 	require.True(t, len(instAdmins) > 0)
 
-	del, err := webui.NewDeletionForFile(gf.ID, instAdmins[0], exampleURL)
+	del, err := webui.NewDeletionForFile(gf.ID, instAdmins[0], exampleURL, nil)
 	require.Nil(t, err)
 	require.NotNil(t, del)
 
@@ -72,18 +72,54 @@ func TestNewDeletionForFileWithPendingItems(t *testing.T) {
 
 	// The user param doesn't matter here, because we should get
 	// ErrPendingWorkItems before the function even looks at the user.
-	del, err := webui.NewDeletionForFile(gf.ID, &pgmodels.User{}, exampleURL)
+	del, err := webui.NewDeletionForFile(gf.ID, &pgmodels.User{}, exampleURL, nil)
 	assert.Nil(t, del)
 	assert.Equal(t, common.ErrPendingWorkItems, err)
 }
 
+func TestNewDeletionWithLegalHold(t *testing.T) {
+	db.LoadFixtures()
+	defer db.ForceFixtureReload()
+	admin, err := pgmodels.UserByEmail("admin@inst1.edu")
+	require.Nil(t, err)
+
+	// Object 10 is under a legal hold in the fixture data.
+	del, err := webui.NewDeletionForObject(10, admin, exampleURL, nil)
+	assert.Nil(t, del)
+	assert.Equal(t, common.ErrLegalHold, err)
+
+	// So are its files.
+	gf, err := pgmodels.GenericFileByID(53)
+	require.Nil(t, err)
+	require.Equal(t, int64(10), gf.IntellectualObjectID)
+	del, err = webui.NewDeletionForFile(gf.ID, admin, exampleURL, nil)
+	assert.Nil(t, del)
+	assert.Equal(t, common.ErrLegalHold, err)
+
+	// A hold placed after the request was made blocks approval.
+	// Deletion request 1 includes files from object 3.
+	hold := &pgmodels.LegalHold{
+		InstitutionID:        2,
+		IntellectualObjectID: 3,
+		Reason:               "Deletion test",
+		PlacedByID:           admin.ID,
+	}
+	require.Nil(t, hold.Save())
+	del, err = webui.NewDeletionForReview(1, admin, exampleURL, confToken, nil)
+	require.Nil(t, err)
+	assert.Equal(t, common.ErrLegalHold, del.Approve())
+	reloaded, err := pgmodels.DeletionRequestByID(1)
+	require.Nil(t, err)
+	assert.True(t, reloaded.ConfirmedAt.IsZero())
+}
+
 func TestNewDeletionBadToken(t *testing.T) {
 	db.LoadFixtures()
 	admin, err := pgmodels.UserByEmail("admin@inst1.edu")
 	require.Nil(t, err)
 	require.NotNil(t, admin)
 
-	del, err := webui.NewDeletionForReview(1, admin, exampleURL, "InvalidToken")
+	del, err := webui.NewDeletionForReview(1, admin, exampleURL, "InvalidToken", nil)
 	require.Nil(t, del)
 	assert.Equal(t, common.ErrInvalidToken, err)
 }
@@ -94,7 +130,7 @@ func TestNewDeletionForReview(t *testing.T) {
 	require.Nil(t, err)
 	require.NotNil(t, admin)
 
-	del, err := webui.NewDeletionForReview(1, admin, exampleURL, confToken)
+	del, err := webui.NewDeletionForReview(1, admin, exampleURL, confToken, nil)
 	require.Nil(t, err)
 	require.NotNil(t, del)
 
@@ -136,6 +172,14 @@ func testCreateAndQueueWorkItem(t *testing.T, del *webui.Deletion) {
 	assert.True(t, item.ID > 0)
 	assert.Equal(t, del.DeletionRequest.GenericFiles[0].ID, item.GenericFileID)
 	assert.Equal(t, constants.ActionDelete, item.Action)
+
+	// Each item is saved with the request and queued through the outbox.
+	for _, item := range del.DeletionRequest.WorkItems {
+		assert.Equal(t, del.DeletionRequest.ID, item.DeletionRequestID)
+		msg, err := pgmodels.NSQOutboxMessageGet(pgmodels.NewQuery().Where("work_item_id", "=", item.ID))
+		require.Nil(t, err)
+		assert.Equal(t, constants.TopicDelete, msg.Topic)
+	}
 }
 
 func testCreateRequestAlert(t *testing.T, del *webui.Deletion) {
@@ -64,6 +64,9 @@ func ErrorShowModal(c *gin.Context) {
 // error. If the error doesn't map to a code, this returns 500 by
 // default.
 func StatusCodeForError(err error) (status int) {
+	if _, ok := err.(*common.IllegalTransitionError); ok {
+		return http.StatusConflict
+	}
 	switch err {
 	case common.ErrInvalidLogin:
 		status = http.StatusUnauthorized
@@ -73,7 +76,7 @@ func StatusCodeForError(err error) (status int) {
 		status = http.StatusForbidden
 	case common.ErrParentRecordNotFound:
 		status = http.StatusNotFound
-	case common.ErrWrongDataType:
+	case common.ErrWrongDataType, common.ErrIDMismatch, common.ErrNoFilters, common.ErrTooManyItems, common.ErrInvalidFileFilter:
 		status = http.StatusBadRequest
 	case common.ErrDecodeCookie:
 		status = http.StatusBadRequest
@@ -81,7 +84,8 @@ func StatusCodeForError(err error) (status int) {
 		status = http.StatusMethodNotAllowed
 	case common.ErrInternal:
 		status = http.StatusInternalServerError
-	case common.ErrPendingWorkItems, common.ErrRequestAlreadyApproved, common.ErrRequestAlreadyCancelled:
+	case common.ErrPendingWorkItems, common.ErrRequestAlreadyApproved, common.ErrRequestAlreadyCancelled, common.ErrLegalHold,
+		common.ErrNotDeleted, common.ErrStorageRecordsMissing, common.ErrObjectDeleted, common.ErrNotOrphaned:
 		status = http.StatusConflict
 	default:
 		status = http.StatusInternalServerError
@@ -3,10 +3,8 @@ package webui
 import (
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/APTrust/registry/common"
-	"github.com/APTrust/registry/constants"
 	"github.com/APTrust/registry/forms"
 	"github.com/APTrust/registry/helpers"
 	"github.com/APTrust/registry/pgmodels"
@@ -95,7 +93,7 @@ func GenericFileInitRestore(c *gin.Context) {
 func GenericFileInitDelete(c *gin.Context) {
 	req := NewRequest(c)
 	c.Set("showErrorInModal", true)
-	del, err := NewDeletionForFile(req.Auth.ResourceID, req.CurrentUser, req.BaseURL())
+	del, err := NewDeletionForFile(req.Auth.ResourceID, req.CurrentUser, req.BaseURL(), helpers.AuditActor(c))
 	if AbortIfError(c, err) {
 		return
 	}
@@ -107,6 +105,30 @@ func GenericFileInitDelete(c *gin.Context) {
 	c.HTML(http.StatusCreated, "files/deletion_requested.html", req.TemplateData)
 }
 
+// GenericFileUndelete reverts the soft-delete of a file that a failed
+// deletion run marked as deleted. See GenericFile.Undelete for the
+// conditions under which this is allowed.
+//
+// POST /files/undelete/:id
+// PUT /files/undelete/:id
+func GenericFileUndelete(c *gin.Context) {
+	req := NewRequest(c)
+	gf, err := pgmodels.GenericFileByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(gf, helpers.AuditActor(c))) {
+		return
+	}
+	err = gf.Undelete(req.CurrentUser)
+	if AbortIfError(c, err) {
+		return
+	}
+	common.Context().Log.Info().Msgf("User %s undeleted file %d (%s)", req.CurrentUser.Email, gf.ID, gf.Identifier)
+	helpers.SetFlashCookie(c, fmt.Sprintf("File %s has been undeleted.", gf.Identifier))
+	c.Redirect(http.StatusSeeOther, fmt.Sprintf("/files/show/%d", gf.ID))
+}
+
 func genericFileInitRestore(req *Request) (*pgmodels.GenericFile, *pgmodels.IntellectualObject, *pgmodels.WorkItem, error) {
 	ctx := common.Context()
 	ctx.Log.Info().Msgf("[GenericFileInitRestore] Got restore request for GenericFile %d", req.Auth.ResourceID)
@@ -115,57 +137,41 @@ func genericFileInitRestore(req *Request) (*pgmodels.GenericFile, *pgmodels.Inte
 		ctx.Log.Error().Msgf("[GenericFileInitRestore] Error finding GenericFile %d: %v", req.Auth.ResourceID, err)
 		return nil, nil, nil, err
 	}
+	obj, workItem, err := InitFileRestoration(gf, req.CurrentUser)
+	return gf, obj, workItem, err
+}
+
+// InitFileRestoration creates and queues a restoration WorkItem for
+// the specified file on behalf of user. It returns the file's parent
+// object and the new WorkItem. This returns common.ErrPendingWorkItems
+// if other operations are pending on the file.
+func InitFileRestoration(gf *pgmodels.GenericFile, user *pgmodels.User) (*pgmodels.IntellectualObject, *pgmodels.WorkItem, error) {
+	ctx := common.Context()
 
 	// Make sure there are no pending work items...
 	pendingWorkItems, err := pgmodels.WorkItemsPendingForFile(gf.ID)
 	if err != nil {
-		ctx.Log.Error().Msgf("[GenericFileInitRestore] Error finding pending WorkItems for GenericFile %d: %v", req.Auth.ResourceID, err)
-		return gf, nil, nil, err
+		ctx.Log.Error().Msgf("[GenericFileInitRestore] Error finding pending WorkItems for GenericFile %d: %v", gf.ID, err)
+		return nil, nil, err
 	}
 	if len(pendingWorkItems) > 0 {
 		ctx.Log.Warn().Msgf("[GenericFileInitRestore] GenericFile %d can't be restored due to pending work items (%s)", gf.ID, gf.Identifier)
-		return gf, nil, nil, common.ErrPendingWorkItems
+		return nil, nil, common.ErrPendingWorkItems
 	}
 
 	// Create the new restoration work item
 	obj, err := pgmodels.IntellectualObjectByID(gf.IntellectualObjectID)
 	if err != nil {
-		ctx.Log.Error().Msgf("[GenericFileInitRestore] Error finding parent object of GenericFile %d (IntellectualObjectID = %d): %v", req.Auth.ResourceID, gf.IntellectualObjectID, err)
-		return gf, nil, nil, err
-	}
-	ctx.Log.Info().Msgf("[GenericFileInitRestore] Found Object %d for GenericFile %d", gf.ID, obj.ID)
-
-	workItem, err := pgmodels.NewRestorationItem(obj, gf, req.CurrentUser)
-	if err != nil {
-		ctx.Log.Error().Msgf("[GenericFileInitRestore] Error creating restoration WorkItem for GenericFile %d: %v", req.Auth.ResourceID, err)
-		return gf, obj, nil, err
-	}
-	ctx.Log.Info().Msgf("[GenericFileInitRestore] Created restoration WorkItem %d for GenericFile %d", workItem.ID, gf.ID)
-
-	// Get the name of the NSQ topic for file restorations
-	topic, err := constants.TopicFor(workItem.Action, workItem.Stage)
-	if err != nil {
-		ctx.Log.Error().Msgf("[GenericFileInitRestore] Error NSQ topic for GenericFile %d restoration (action=%s, stage=%s): %v", req.Auth.ResourceID, workItem.Action, workItem.Stage, err)
-		return gf, obj, workItem, err
+		ctx.Log.Error().Msgf("[GenericFileInitRestore] Error finding parent object of GenericFile %d (IntellectualObjectID = %d): %v", gf.ID, gf.IntellectualObjectID, err)
+		return nil, nil, err
 	}
+	ctx.Log.Info().Msgf("[GenericFileInitRestore] Found Object %d for GenericFile %d", obj.ID, gf.ID)
 
-	// Queue the new work item in NSQ
-	err = ctx.NSQClient.Enqueue(topic, workItem.ID)
+	workItem, err := pgmodels.NewRestorationItem(obj, gf, user)
 	if err != nil {
-		ctx.Log.Error().Msgf("[GenericFileInitRestore] NSQ returned error when queueing GenericFile %d. WorkItem %d, topic=%s: %v", req.Auth.ResourceID, workItem.ID, topic, err)
-		return gf, obj, workItem, err
+		ctx.Log.Error().Msgf("[GenericFileInitRestore] Error creating restoration WorkItem for GenericFile %d: %v", gf.ID, err)
+		return obj, nil, err
 	}
-	ctx.Log.Info().Msgf("[GenericFileInitRestore] Queued WorkItem %d in topic %s", workItem.ID, topic)
-
-	// Mark the WorkItem as queued
-	workItem.QueuedAt = time.Now().UTC()
-	err = workItem.Save()
-
-	if err == nil {
-		ctx.Log.Info().Msgf("[GenericFileInitRestore] Marked WorkItem %d as queued", workItem.ID)
-	} else {
-		ctx.Log.Error().Msgf("[GenericFileInitRestore] Error saving WorkItem %d with QueuedAt timestamp for GenericFile %d: %v", workItem.ID, req.Auth.ResourceID, err)
-	}
-
-	return gf, obj, workItem, err
+	ctx.Log.Info().Msgf("[GenericFileInitRestore] Created and queued restoration WorkItem %d for GenericFile %d", workItem.ID, gf.ID)
+	return obj, workItem, nil
 }
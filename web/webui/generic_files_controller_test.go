@@ -262,3 +262,35 @@ func TestGenericFileInitRestore(t *testing.T) {
 		WithFormField(constants.CSRFTokenName, testutil.Inst1UserToken).
 		Expect().Status(http.StatusForbidden)
 }
+
+func TestGenericFileUndelete(t *testing.T) {
+	defer db.ForceFixtureReload()
+	testutil.InitHTTPTests(t)
+
+	// Only sys admins see the undelete button, and only on deleted files.
+	html := testutil.SysAdminClient.GET("/files/show/10").Expect().
+		Status(http.StatusOK).Body().Raw()
+	assert.Contains(t, html, "/files/undelete/10")
+	html = testutil.SysAdminClient.GET("/files/show/1").Expect().
+		Status(http.StatusOK).Body().Raw()
+	assert.NotContains(t, html, "/files/undelete/1")
+	html = testutil.Inst1AdminClient.GET("/files/show/10").Expect().
+		Status(http.StatusOK).Body().Raw()
+	assert.NotContains(t, html, "/files/undelete/10")
+
+	testutil.Inst1AdminClient.POST("/files/undelete/10").
+		WithFormField(constants.CSRFTokenName, testutil.Inst1AdminToken).
+		Expect().Status(http.StatusForbidden)
+
+	testutil.SysAdminClient.POST("/files/undelete/10").
+		WithFormField(constants.CSRFTokenName, testutil.SysAdminToken).
+		Expect().Status(http.StatusOK)
+	gf, err := pgmodels.GenericFileByID(10)
+	require.Nil(t, err)
+	assert.Equal(t, constants.StateActive, gf.State)
+
+	// File is active now, so this is a conflict.
+	testutil.SysAdminClient.POST("/files/undelete/10").
+		WithFormField(constants.CSRFTokenName, testutil.SysAdminToken).
+		Expect().Status(http.StatusConflict)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/APTrust/registry/constants"
 	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/helpers"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/gin-gonic/gin"
 )
@@ -26,6 +27,9 @@ func InstitutionDelete(c *gin.Context) {
 	if AbortIfError(c, err) {
 		return
 	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(inst, helpers.AuditActor(c))) {
+		return
+	}
 	err = inst.Delete()
 	if AbortIfError(c, err) {
 		return
@@ -42,6 +46,9 @@ func InstitutionUndelete(c *gin.Context) {
 	if AbortIfError(c, err) {
 		return
 	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(inst, helpers.AuditActor(c))) {
+		return
+	}
 	err = inst.Undelete()
 	if AbortIfError(c, err) {
 		return
@@ -157,6 +164,9 @@ func saveInstitutionForm(c *gin.Context) {
 			return
 		}
 	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(institution, helpers.AuditActor(c))) {
+		return
+	}
 	// Bind submitted form values in case we have to
 	// re-display the form with an error message.
 	c.ShouldBind(institution)
@@ -4,11 +4,10 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/APTrust/registry/common"
-	"github.com/APTrust/registry/constants"
 	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/helpers"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/gin-gonic/gin"
 )
@@ -35,7 +34,7 @@ func IntellectualObjectRequestDelete(c *gin.Context) {
 func IntellectualObjectInitDelete(c *gin.Context) {
 	req := NewRequest(c)
 	c.Set("showErrorInModal", true)
-	del, err := NewDeletionForObject(req.Auth.ResourceID, req.CurrentUser, req.BaseURL())
+	del, err := NewDeletionForObject(req.Auth.ResourceID, req.CurrentUser, req.BaseURL(), helpers.AuditActor(c))
 	if AbortIfError(c, err) {
 		return
 	}
@@ -93,6 +92,10 @@ func IntellectualObjectIndex(c *gin.Context) {
 	if AbortIfError(c, err) {
 		return
 	}
+	err = pgmodels.AddSearchSnippets(objects, c.Query("q"))
+	if AbortIfError(c, err) {
+		return
+	}
 	// If user searched by identifier and we have exactly one
 	// result, show them the object detail page. This brings us
 	// in line with old Pharos behavior of accessing an object
@@ -103,6 +106,31 @@ func IntellectualObjectIndex(c *gin.Context) {
 	c.HTML(http.StatusOK, template, req.TemplateData)
 }
 
+// IntellectualObjectUndelete reverts the soft-delete of an object that
+// a failed deletion run marked as deleted, along with those of its
+// files that still have storage records. See IntellectualObject.Undelete
+// for the conditions under which this is allowed.
+//
+// POST /objects/undelete/:id
+// PUT /objects/undelete/:id
+func IntellectualObjectUndelete(c *gin.Context) {
+	req := NewRequest(c)
+	obj, err := pgmodels.IntellectualObjectByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(obj, helpers.AuditActor(c))) {
+		return
+	}
+	err = obj.Undelete(req.CurrentUser)
+	if AbortIfError(c, err) {
+		return
+	}
+	common.Context().Log.Info().Msgf("User %s undeleted object %d (%s)", req.CurrentUser.Email, obj.ID, obj.Identifier)
+	helpers.SetFlashCookie(c, fmt.Sprintf("Object %s has been undeleted.", obj.Identifier))
+	c.Redirect(http.StatusSeeOther, fmt.Sprintf("/objects/show/%d", obj.ID))
+}
+
 // IntellectualObjectShow returns the object with the specified id.
 // GET /objects/show/:id
 func IntellectualObjectShow(c *gin.Context) {
@@ -120,12 +148,23 @@ func IntellectualObjectShow(c *gin.Context) {
 	if AbortIfError(c, err) {
 		return
 	}
+	versions, err := pgmodels.ObjectVersionsForObject(object.ID)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["versions"] = versions
 	stats, err := pgmodels.DepositFormatStatsSelect(object.InstitutionID, object.ID)
 	if AbortIfError(c, err) {
 		return
 	}
 	req.TemplateData["depositFormatStats"] = stats
 
+	legalHolds, err := pgmodels.LegalHoldsForObject(object.InstitutionID, object.ID, object.BagGroupIdentifier)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["legalHolds"] = legalHolds
+
 	pendingWorkItems, _ := pgmodels.WorkItemsPendingForObject(object.InstitutionID, object.BagName)
 	req.TemplateData["hasPendingWorkItems"] = len(pendingWorkItems) > 0
 
@@ -241,25 +280,6 @@ func InitObjectRestoration(obj *pgmodels.IntellectualObject, user *pgmodels.User
 		return nil, common.ErrPendingWorkItems
 	}
 
-	// Create the new restoration work item
-	workItem, err := pgmodels.NewRestorationItem(obj, nil, user)
-	if err != nil {
-		return nil, err
-	}
-
-	// Queue the new work item in NSQ
-	topic, err := constants.TopicFor(workItem.Action, workItem.Stage)
-	if err != nil {
-		return nil, err
-	}
-
-	ctx := common.Context()
-	err = ctx.NSQClient.Enqueue(topic, workItem.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	workItem.QueuedAt = time.Now().UTC()
-	err = workItem.Save()
-	return workItem, err
+	// Create and queue the new restoration work item
+	return pgmodels.NewRestorationItem(obj, nil, user)
 }
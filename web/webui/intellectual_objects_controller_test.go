@@ -179,6 +179,26 @@ func TestObjectStartsWith(t *testing.T) {
 	testutil.AssertMatchesAll(t, html, items)
 }
 
+func TestObjectSearch(t *testing.T) {
+	testutil.InitHTTPTests(t)
+
+	// Inst 1 has two objects with glacier in the title.
+	// Inst 2's glacier object should not appear.
+	resp := testutil.Inst1UserClient.GET("/objects").
+		WithQuery("q", "glacier").Expect()
+	assert.Equal(t, http.StatusOK, resp.Raw().StatusCode)
+	html := resp.Body().Raw()
+	testutil.AssertMatchesAll(t, html, []string{
+		"institution1.edu/gl-dp-oh",
+		"institution1.edu/gl-or",
+		"<mark>",
+	})
+	testutil.AssertMatchesNone(t, html, []string{
+		"institution2.edu/gl-dp-va",
+		"institution1.edu/photos",
+	})
+}
+
 func TestObjectRequestDelete(t *testing.T) {
 	testutil.InitHTTPTests(t)
 
@@ -367,3 +387,28 @@ func TestIntellectualObjectFiles(t *testing.T) {
 	}
 	testutil.AssertMatchesAll(t, html, expected)
 }
+
+func TestIntellectualObjectUndelete(t *testing.T) {
+	defer db.ForceFixtureReload()
+	testutil.InitHTTPTests(t)
+
+	html := testutil.SysAdminClient.GET("/objects/show/14").Expect().
+		Status(http.StatusOK).Body().Raw()
+	assert.Contains(t, html, "/objects/undelete/14")
+	html = testutil.Inst2AdminClient.GET("/objects/show/14").Expect().
+		Status(http.StatusOK).Body().Raw()
+	assert.NotContains(t, html, "/objects/undelete/14")
+
+	testutil.Inst2AdminClient.POST("/objects/undelete/14").
+		WithFormField(constants.CSRFTokenName, testutil.Inst2AdminToken).
+		Expect().Status(http.StatusForbidden)
+
+	// Object 14's only file has no storage records,
+	// so its bytes are gone.
+	testutil.SysAdminClient.POST("/objects/undelete/14").
+		WithFormField(constants.CSRFTokenName, testutil.SysAdminToken).
+		Expect().Status(http.StatusConflict)
+	obj, err := pgmodels.IntellectualObjectByID(14)
+	require.Nil(t, err)
+	assert.Equal(t, constants.StateDeleted, obj.State)
+}
@@ -0,0 +1,74 @@
+package webui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/pgmodels"
+)
+
+// CreateLegalHoldPlacedAlert creates an alert telling the institutional
+// admins at the hold's institution that someone has placed a legal
+// hold on one of their objects or bag groups.
+func CreateLegalHoldPlacedAlert(req *Request, hold *pgmodels.LegalHoldView) (*pgmodels.Alert, error) {
+	expiresAt := ""
+	if !hold.ExpiresAt.IsZero() {
+		expiresAt = hold.ExpiresAt.Format("2006-01-02")
+	}
+	alertData := map[string]interface{}{
+		"placedByName":      hold.PlacedByName,
+		"targetDescription": legalHoldTarget(hold),
+		"reason":            hold.Reason,
+		"expiresAt":         expiresAt,
+		"legalHoldURL":      legalHoldURL(req, hold),
+	}
+	subject := fmt.Sprintf("Legal hold placed on %s", legalHoldTarget(hold))
+	return createLegalHoldAlert(hold, constants.AlertLegalHoldPlaced, subject, "alerts/legal_hold_placed.txt", alertData)
+}
+
+// CreateLegalHoldReleasedAlert creates an alert telling the
+// institutional admins at the hold's institution that someone has
+// released a legal hold.
+func CreateLegalHoldReleasedAlert(req *Request, hold *pgmodels.LegalHoldView) (*pgmodels.Alert, error) {
+	alertData := map[string]interface{}{
+		"releasedByName":    hold.ReleasedByName,
+		"placedByName":      hold.PlacedByName,
+		"targetDescription": legalHoldTarget(hold),
+		"reason":            hold.Reason,
+		"legalHoldURL":      legalHoldURL(req, hold),
+	}
+	subject := fmt.Sprintf("Legal hold released on %s", legalHoldTarget(hold))
+	return createLegalHoldAlert(hold, constants.AlertLegalHoldReleased, subject, "alerts/legal_hold_released.txt", alertData)
+}
+
+func createLegalHoldAlert(hold *pgmodels.LegalHoldView, alertType, subject, templateName string, alertData map[string]interface{}) (*pgmodels.Alert, error) {
+	adminsQuery := pgmodels.NewQuery().
+		Where("institution_id", "=", hold.InstitutionID).
+		Where("role", "=", constants.RoleInstAdmin).
+		IsNull("deactivated_at")
+	instAdmins, err := pgmodels.UserSelect(adminsQuery)
+	if err != nil {
+		return nil, err
+	}
+	alert := &pgmodels.Alert{
+		InstitutionID: hold.InstitutionID,
+		Type:          alertType,
+		Subject:       subject,
+		CreatedAt:     time.Now().UTC(),
+		Users:         instAdmins,
+	}
+	return pgmodels.CreateAlert(alert, templateName, alertData)
+}
+
+// legalHoldTarget describes the object or bag group a hold applies to.
+func legalHoldTarget(hold *pgmodels.LegalHoldView) string {
+	if hold.IntellectualObjectID > 0 {
+		return fmt.Sprintf("object %s", hold.ObjectIdentifier)
+	}
+	return fmt.Sprintf("bag group %s", hold.BagGroupIdentifier)
+}
+
+func legalHoldURL(req *Request, hold *pgmodels.LegalHoldView) string {
+	return fmt.Sprintf("%s/legal_holds/show/%d", req.BaseURL(), hold.ID)
+}
@@ -0,0 +1,171 @@
+package webui
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/helpers"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/gin-gonic/gin"
+)
+
+// LegalHoldCreate places a new legal hold on an object or bag group
+// and alerts the institution's admins.
+//
+// POST /legal_holds/new
+func LegalHoldCreate(c *gin.Context) {
+	req := NewRequest(c)
+	hold, err := newLegalHoldFromRequest(req)
+	if AbortIfError(c, err) {
+		return
+	}
+	c.ShouldBind(hold)
+	hold.ID = 0
+	if AbortIfError(c, pgmodels.AuditChangesBy(hold, helpers.AuditActor(c))) {
+		return
+	}
+	hold.PlacedByID = req.CurrentUser.ID
+
+	form := forms.NewLegalHoldForm(hold)
+	req.TemplateData["form"] = form
+	if !form.Save() {
+		req.TemplateData["FormError"] = form.Error
+		c.HTML(form.Status, form.Template, req.TemplateData)
+		return
+	}
+	holdView, err := pgmodels.LegalHoldViewByID(hold.ID)
+	if AbortIfError(c, err) {
+		return
+	}
+	common.Context().Log.Info().Msgf("User %s placed legal hold %d on %s", req.CurrentUser.Email, hold.ID, legalHoldTarget(holdView))
+	_, err = CreateLegalHoldPlacedAlert(req, holdView)
+	if AbortIfError(c, err) {
+		return
+	}
+	c.Redirect(form.Status, form.PostSaveURL())
+}
+
+// LegalHoldIndex shows a list of legal holds, newest first.
+// Non-admins see only holds at their own institution.
+//
+// GET /legal_holds
+func LegalHoldIndex(c *gin.Context) {
+	req := NewRequest(c)
+	var holds []*pgmodels.LegalHoldView
+	err := req.LoadResourceList(&holds, "created_at", "desc", forms.NewLegalHoldFilterForm)
+	if AbortIfError(c, err) {
+		return
+	}
+	c.HTML(http.StatusOK, "legal_holds/index.html", req.TemplateData)
+}
+
+// LegalHoldNew shows the form for placing a legal hold on the object
+// or bag group specified in the query string.
+//
+// GET /legal_holds/new?intellectual_object_id=<id>
+// GET /legal_holds/new?bag_group_id=<id>
+func LegalHoldNew(c *gin.Context) {
+	req := NewRequest(c)
+	hold, err := newLegalHoldFromRequest(req)
+	if AbortIfError(c, err) {
+		return
+	}
+	form := forms.NewLegalHoldForm(hold)
+	req.TemplateData["form"] = form
+	c.HTML(http.StatusOK, form.Template, req.TemplateData)
+}
+
+// LegalHoldRelease releases a legal hold and alerts the
+// institution's admins.
+//
+// POST /legal_holds/release/:id
+// PUT /legal_holds/release/:id
+func LegalHoldRelease(c *gin.Context) {
+	req := NewRequest(c)
+	hold, err := pgmodels.LegalHoldByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(hold, helpers.AuditActor(c))) {
+		return
+	}
+	if !hold.IsActive() {
+		helpers.SetFlashCookie(c, "This legal hold is no longer in effect.")
+		c.Redirect(http.StatusSeeOther, fmt.Sprintf("/legal_holds/show/%d", hold.ID))
+		return
+	}
+	err = hold.Release(req.CurrentUser)
+	if AbortIfError(c, err) {
+		return
+	}
+	holdView, err := pgmodels.LegalHoldViewByID(hold.ID)
+	if AbortIfError(c, err) {
+		return
+	}
+	common.Context().Log.Info().Msgf("User %s released legal hold %d on %s", req.CurrentUser.Email, hold.ID, legalHoldTarget(holdView))
+	_, err = CreateLegalHoldReleasedAlert(req, holdView)
+	if AbortIfError(c, err) {
+		return
+	}
+	helpers.SetFlashCookie(c, fmt.Sprintf("The legal hold on %s has been released.", legalHoldTarget(holdView)))
+	c.Redirect(http.StatusSeeOther, fmt.Sprintf("/legal_holds/show/%d", hold.ID))
+}
+
+// LegalHoldShow shows a legal hold.
+//
+// GET /legal_holds/show/:id
+func LegalHoldShow(c *gin.Context) {
+	req := NewRequest(c)
+	hold, err := pgmodels.LegalHoldViewByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["hold"] = hold
+	c.HTML(http.StatusOK, "legal_holds/show.html", req.TemplateData)
+}
+
+// newLegalHoldFromRequest returns a new, unsaved legal hold on the
+// object or bag group specified by the intellectual_object_id or
+// bag_group_id request param. The authorization middleware can't tell
+// which institution owns the target, so we check here that the
+// current user is allowed to place holds there.
+func newLegalHoldFromRequest(req *Request) (*pgmodels.LegalHold, error) {
+	hold := &pgmodels.LegalHold{
+		IntellectualObjectID: legalHoldParam(req.GinContext, "intellectual_object_id"),
+		BagGroupID:           legalHoldParam(req.GinContext, "bag_group_id"),
+	}
+	if hold.IntellectualObjectID > 0 {
+		obj, err := pgmodels.IntellectualObjectByID(hold.IntellectualObjectID)
+		if err != nil {
+			return nil, err
+		}
+		hold.InstitutionID = obj.InstitutionID
+		hold.BagGroupID = 0
+	} else if hold.BagGroupID > 0 {
+		bagGroup, err := pgmodels.BagGroupByID(hold.BagGroupID)
+		if err != nil {
+			return nil, err
+		}
+		hold.InstitutionID = bagGroup.InstitutionID
+	} else {
+		return nil, common.ErrInvalidParam
+	}
+	if !req.CurrentUser.HasPermission(constants.LegalHoldCreate, hold.InstitutionID) {
+		common.Context().Log.Warn().Msgf("Permission denied: User %s tried to place a legal hold at institution %d", req.CurrentUser.Email, hold.InstitutionID)
+		return nil, common.ErrPermissionDenied
+	}
+	return hold, nil
+}
+
+func legalHoldParam(c *gin.Context, name string) int64 {
+	value := c.PostForm(name)
+	if value == "" {
+		value = c.Query(name)
+	}
+	id, _ := strconv.ParseInt(value, 10, 64)
+	return id
+}
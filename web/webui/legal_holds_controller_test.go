@@ -0,0 +1,144 @@
+package webui_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/db"
+	"github.com/APTrust/registry/pgmodels"
+	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegalHoldIndex(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	inst1Holds := []string{
+		"Litigation hold: Smith v. Institution One",
+		"Records audit of carolina-2 collection",
+		"Hold pending copyright review",
+	}
+	inst2Holds := []string{
+		"Annual audit",
+	}
+
+	html := tu.SysAdminClient.GET("/legal_holds").
+		Expect().Status(http.StatusOK).Body().Raw()
+	tu.AssertMatchesAll(t, html, inst1Holds)
+	tu.AssertMatchesAll(t, html, inst2Holds)
+	tu.AssertMatchesResultCount(t, html, 4)
+
+	html = tu.SysAdminClient.GET("/legal_holds").
+		WithQuery("active", "false").
+		Expect().Status(http.StatusOK).Body().Raw()
+	tu.AssertMatchesResultCount(t, html, 2)
+
+	// Inst users can see their own institution's holds.
+	html = tu.Inst1UserClient.GET("/legal_holds").
+		Expect().Status(http.StatusOK).Body().Raw()
+	tu.AssertMatchesAll(t, html, inst1Holds)
+	tu.AssertMatchesNone(t, html, inst2Holds)
+}
+
+func TestLegalHoldShow(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	html := tu.Inst1AdminClient.GET("/legal_holds/show/1").
+		Expect().Status(http.StatusOK).Body().Raw()
+	tu.AssertMatchesAll(t, html, []string{
+		"Litigation hold: Smith v. Institution One",
+		"admin@inst1.edu",
+		"Release Hold",
+	})
+
+	// Inst users can see holds but can't release them.
+	html = tu.Inst1UserClient.GET("/legal_holds/show/1").
+		Expect().Status(http.StatusOK).Body().Raw()
+	assert.NotContains(t, html, "Release Hold")
+
+	tu.Inst2AdminClient.GET("/legal_holds/show/1").
+		Expect().Status(http.StatusForbidden)
+}
+
+func TestLegalHoldPlaceAndRelease(t *testing.T) {
+	defer db.ForceFixtureReload()
+	tu.InitHTTPTests(t)
+
+	// Object 9 belongs to institution 2.
+	tu.Inst1AdminClient.GET("/legal_holds/new").
+		WithQuery("intellectual_object_id", 9).
+		Expect().Status(http.StatusOK)
+	tu.Inst1UserClient.GET("/legal_holds/new").
+		WithQuery("intellectual_object_id", 9).
+		Expect().Status(http.StatusForbidden)
+	tu.Inst2AdminClient.GET("/legal_holds/new").
+		WithQuery("intellectual_object_id", 9).
+		Expect().Status(http.StatusForbidden)
+
+	// Inst admins can't place holds on other institutions' bag groups.
+	tu.Inst2AdminClient.POST("/legal_holds/new").
+		WithFormField(constants.CSRFTokenName, tu.Inst2AdminToken).
+		WithFormField("bag_group_id", 1).
+		WithFormField("Reason", "Wrong institution").
+		Expect().Status(http.StatusForbidden)
+
+	// Reason is required.
+	tu.Inst1AdminClient.POST("/legal_holds/new").
+		WithFormField(constants.CSRFTokenName, tu.Inst1AdminToken).
+		WithFormField("intellectual_object_id", 9).
+		Expect().Status(http.StatusBadRequest)
+
+	tu.Inst1AdminClient.POST("/legal_holds/new").
+		WithFormField(constants.CSRFTokenName, tu.Inst1AdminToken).
+		WithFormField("intellectual_object_id", 9).
+		WithFormField("Reason", "Legal hold controller test").
+		WithFormField("ExpiresAt", "2099-01-01").
+		Expect().Status(http.StatusOK)
+
+	query := pgmodels.NewQuery().Where("reason", "=", "Legal hold controller test")
+	hold, err := pgmodels.LegalHoldGet(query)
+	require.Nil(t, err)
+	assert.Equal(t, int64(9), hold.IntellectualObjectID)
+	assert.Equal(t, tu.Inst1Admin.InstitutionID, hold.InstitutionID)
+	assert.Equal(t, tu.Inst1Admin.ID, hold.PlacedByID)
+	assert.Equal(t, 2099, hold.ExpiresAt.Year())
+	assert.True(t, hold.IsActive())
+
+	alertQuery := pgmodels.NewQuery().Where("type", "=", constants.AlertLegalHoldPlaced)
+	alerts, err := pgmodels.AlertSelect(alertQuery)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(alerts))
+	assert.Equal(t, tu.Inst1Admin.InstitutionID, alerts[0].InstitutionID)
+
+	// The object page shows the hold and disables deletion.
+	html := tu.Inst1AdminClient.GET("/objects/show/9").
+		Expect().Status(http.StatusOK).Body().Raw()
+	tu.AssertMatchesAll(t, html, []string{
+		"Legal hold controller test",
+		"Object cannot be deleted while it is under a legal hold.",
+	})
+	tu.Inst1AdminClient.POST("/objects/init_delete/9").
+		WithFormField(constants.CSRFTokenName, tu.Inst1AdminToken).
+		Expect().Status(http.StatusConflict)
+
+	releaseURL := fmt.Sprintf("/legal_holds/release/%d", hold.ID)
+	tu.Inst1UserClient.POST(releaseURL).
+		WithFormField(constants.CSRFTokenName, tu.Inst1UserToken).
+		Expect().Status(http.StatusForbidden)
+	tu.Inst1AdminClient.POST(releaseURL).
+		WithFormField(constants.CSRFTokenName, tu.Inst1AdminToken).
+		Expect().Status(http.StatusOK)
+
+	hold, err = pgmodels.LegalHoldByID(hold.ID)
+	require.Nil(t, err)
+	assert.False(t, hold.IsActive())
+	assert.Equal(t, tu.Inst1Admin.ID, hold.ReleasedByID)
+
+	alertQuery = pgmodels.NewQuery().Where("type", "=", constants.AlertLegalHoldReleased)
+	alerts, err = pgmodels.AlertSelect(alertQuery)
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(alerts))
+}
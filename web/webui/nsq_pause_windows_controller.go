@@ -0,0 +1,108 @@
+package webui
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/helpers"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/gin-gonic/gin"
+)
+
+// NSQPauseWindowCancel cancels a scheduled or active NSQ pause window.
+// If the window's topic or channel is paused, we unpause it right away
+// rather than waiting for the cron job.
+//
+// POST /nsq/pauses/cancel/:id
+// PUT /nsq/pauses/cancel/:id
+func NSQPauseWindowCancel(c *gin.Context) {
+	req := NewRequest(c)
+	window, err := pgmodels.NSQPauseWindowByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(window, helpers.AuditActor(c))) {
+		return
+	}
+	err = window.Cancel(req.CurrentUser)
+	if AbortIfError(c, err) {
+		return
+	}
+	common.Context().Log.Info().Msgf("User %s cancelled NSQ pause window %d for %s", req.CurrentUser.Email, window.ID, window.Target())
+	applyNSQPauseWindows()
+	helpers.SetFlashCookie(c, fmt.Sprintf("The pause of %s has been cancelled.", window.Target()))
+	c.Redirect(http.StatusSeeOther, "/nsq/pauses")
+}
+
+// NSQPauseWindowCreate schedules a new NSQ pause window. If the window
+// starts right away, we pause its topic or channel immediately rather
+// than waiting for the cron job.
+//
+// POST /nsq/pauses/new
+func NSQPauseWindowCreate(c *gin.Context) {
+	req := NewRequest(c)
+	window := &pgmodels.NSQPauseWindow{}
+	if AbortIfError(c, pgmodels.AuditChangesBy(window, helpers.AuditActor(c))) {
+		return
+	}
+	c.ShouldBind(window)
+	window.ID = 0
+	window.CreatedByID = req.CurrentUser.ID
+
+	form := forms.NewNSQPauseWindowForm(window)
+	req.TemplateData["form"] = form
+	if !form.Save() {
+		req.TemplateData["FormError"] = form.Error
+		c.HTML(form.Status, form.Template, req.TemplateData)
+		return
+	}
+	common.Context().Log.Info().Msgf("User %s scheduled NSQ pause window %d for %s from %s to %s", req.CurrentUser.Email, window.ID, window.Target(), window.StartsAt, window.EndsAt)
+	applyNSQPauseWindows()
+	helpers.SetFlashCookie(c, fmt.Sprintf("Scheduled a pause of %s.", window.Target()))
+	c.Redirect(form.Status, "/nsq/pauses")
+}
+
+// NSQPauseWindowIndex shows current and upcoming NSQ pause windows,
+// followed by the most recent past windows.
+//
+// GET /nsq/pauses
+func NSQPauseWindowIndex(c *gin.Context) {
+	req := NewRequest(c)
+	query := pgmodels.NewQuery().
+		Relations("CreatedBy", "CancelledBy").
+		OrderBy("starts_at", "desc").
+		Limit(100)
+	windows, err := pgmodels.NSQPauseWindowSelect(query)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["windows"] = windows
+	c.HTML(http.StatusOK, "nsq/pauses.html", req.TemplateData)
+}
+
+// NSQPauseWindowNew shows the form for scheduling an NSQ pause window.
+// The topic and channel query params let the NSQ page link here with
+// the target filled in.
+//
+// GET /nsq/pauses/new
+func NSQPauseWindowNew(c *gin.Context) {
+	req := NewRequest(c)
+	window := &pgmodels.NSQPauseWindow{
+		Topic:   c.Query("topic"),
+		Channel: c.Query("channel"),
+	}
+	form := forms.NewNSQPauseWindowForm(window)
+	req.TemplateData["form"] = form
+	c.HTML(http.StatusOK, form.Template, req.TemplateData)
+}
+
+// applyNSQPauseWindows applies windows that just started or ended.
+// Errors are logged, and the cron job will try again.
+func applyNSQPauseWindows() {
+	err := pgmodels.ApplyNSQPauseWindows()
+	if err != nil {
+		common.Context().Log.Error().Msgf("Error applying NSQ pause windows: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package webui_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/pgmodels"
+	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNSQPauseWindows(t *testing.T) {
+	tu.InitHTTPTests(t)
+	pgmodels.ClearNSQPauseWindowCache()
+
+	// Only APTrust admins can schedule pauses.
+	tu.Inst1AdminClient.GET("/nsq/pauses").Expect().Status(http.StatusForbidden)
+	tu.Inst1AdminClient.GET("/nsq/pauses/new").Expect().Status(http.StatusForbidden)
+	tu.Inst1UserClient.GET("/nsq/pauses").Expect().Status(http.StatusForbidden)
+	tu.SysAdminClient.GET("/nsq/pauses").Expect().Status(http.StatusOK)
+	html := tu.SysAdminClient.GET("/nsq/pauses/new").
+		WithQuery("topic", constants.TopicGlacierRestore).
+		Expect().Status(http.StatusOK).Body().Raw()
+	assert.Contains(t, html, constants.TopicGlacierRestore)
+
+	now := time.Now().UTC()
+	tu.SysAdminClient.POST("/nsq/pauses/new").
+		WithFormField(constants.CSRFTokenName, tu.SysAdminToken).
+		WithFormField("Topic", constants.TopicGlacierRestore).
+		WithFormField("Reason", "Glacier restorations are paused for maintenance.").
+		WithFormField("StartsAt", now.Add(-time.Hour).Format("2006-01-02T15:04")).
+		WithFormField("EndsAt", now.Add(time.Hour).Format("2006-01-02T15:04")).
+		Expect().Status(http.StatusOK)
+
+	query := pgmodels.NewQuery().Where("topic", "=", constants.TopicGlacierRestore).OrderBy("id", "desc")
+	window, err := pgmodels.NSQPauseWindowGet(query)
+	require.Nil(t, err)
+	assert.Equal(t, tu.SysAdmin.ID, window.CreatedByID)
+	assert.True(t, window.IsActive())
+
+	// Everyone sees the banner while the pause is in effect.
+	html = tu.Inst1UserClient.GET("/dashboard").Expect().Status(http.StatusOK).Body().Raw()
+	assert.Contains(t, html, "Glacier restorations are paused for maintenance.")
+	html = tu.SysAdminClient.GET("/nsq/pauses").Expect().Status(http.StatusOK).Body().Raw()
+	assert.Contains(t, html, tu.SysAdmin.Email)
+
+	// Windows must end after they start.
+	tu.SysAdminClient.POST("/nsq/pauses/new").
+		WithFormField(constants.CSRFTokenName, tu.SysAdminToken).
+		WithFormField("Topic", constants.TopicDelete).
+		WithFormField("Reason", "Bad window").
+		WithFormField("StartsAt", now.Add(time.Hour).Format("2006-01-02T15:04")).
+		WithFormField("EndsAt", now.Format("2006-01-02T15:04")).
+		Expect().Status(http.StatusBadRequest)
+
+	cancelURL := fmt.Sprintf("/nsq/pauses/cancel/%d", window.ID)
+	tu.Inst1AdminClient.POST(cancelURL).
+		WithFormField(constants.CSRFTokenName, tu.Inst1AdminToken).
+		Expect().Status(http.StatusForbidden)
+	tu.SysAdminClient.POST(cancelURL).
+		WithFormField(constants.CSRFTokenName, tu.SysAdminToken).
+		Expect().Status(http.StatusOK)
+	window, err = pgmodels.NSQPauseWindowByID(window.ID)
+	require.Nil(t, err)
+	assert.Equal(t, pgmodels.NSQPauseCancelled, window.Status())
+	assert.Equal(t, tu.SysAdmin.ID, window.CancelledByID)
+
+	html = tu.Inst1UserClient.GET("/dashboard").Expect().Status(http.StatusOK).Body().Raw()
+	assert.NotContains(t, html, "Glacier restorations are paused for maintenance.")
+}
@@ -0,0 +1,44 @@
+package webui
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/gin-gonic/gin"
+)
+
+// ObjectVersionDiff shows the files added, removed and changed between
+// two versions of an object. The from param is the id of the older
+// version. If it's missing, we compare to the previous version.
+//
+// GET /object_versions/diff/:id
+func ObjectVersionDiff(c *gin.Context) {
+	req := NewRequest(c)
+	var fromID int64
+	var err error
+	if c.Query("from") != "" {
+		fromID, err = strconv.ParseInt(c.Query("from"), 10, 64)
+		if err != nil {
+			AbortIfError(c, common.ErrWrongDataType)
+			return
+		}
+	}
+	diff, err := pgmodels.ObjectVersionDiffFor(fromID, req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	object, err := pgmodels.IntellectualObjectViewByID(diff.ToVersion.IntellectualObjectID)
+	if AbortIfError(c, err) {
+		return
+	}
+	versions, err := pgmodels.ObjectVersionsForObject(object.ID)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["diff"] = diff
+	req.TemplateData["object"] = object
+	req.TemplateData["versions"] = versions
+	c.HTML(http.StatusOK, "object_versions/diff.html", req.TemplateData)
+}
@@ -0,0 +1,40 @@
+package webui_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/APTrust/registry/web/testutil"
+)
+
+func TestObjectVersionDiff(t *testing.T) {
+	testutil.InitHTTPTests(t)
+
+	expected := []string{
+		"institution1.edu/photos",
+		"Version 1",
+		"3 added, 0 removed, 0 changed, 0 unchanged",
+		"institution1.edu/photos/picture1",
+		"institution1.edu/photos/picture3",
+	}
+	html := testutil.Inst1UserClient.GET("/object_versions/diff/1").
+		Expect().Status(http.StatusOK).Body().Raw()
+	testutil.AssertMatchesAll(t, html, expected)
+
+	testutil.Inst1UserClient.GET("/object_versions/diff/1").
+		WithQuery("from", 2).
+		Expect().Status(http.StatusBadRequest)
+
+	testutil.Inst2UserClient.GET("/object_versions/diff/1").
+		Expect().Status(http.StatusForbidden)
+}
+
+func TestObjectShowVersions(t *testing.T) {
+	testutil.InitHTTPTests(t)
+	html := testutil.Inst1UserClient.GET("/objects/show/1").
+		Expect().Status(http.StatusOK).Body().Raw()
+	testutil.AssertMatchesAll(t, html, []string{
+		"Version History",
+		`href="/object_versions/diff/1"`,
+	})
+}
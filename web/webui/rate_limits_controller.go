@@ -0,0 +1,135 @@
+package webui
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/helpers"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitIndex shows the default API rate limits, the overrides
+// for specific users and institutions, and the current state of
+// each user's and institution's token bucket in this Registry
+// process.
+//
+// GET /rate_limits
+func RateLimitIndex(c *gin.Context) {
+	req := NewRequest(c)
+	ctx := common.Context()
+	query := pgmodels.NewQuery().Relations("Institution", "User").OrderBy("institution_id", "asc").OrderBy("user_id", "desc")
+	overrides, err := pgmodels.RateLimitOverrideSelect(query)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["config"] = ctx.Config.RateLimit
+	req.TemplateData["overrides"] = overrides
+	req.TemplateData["stats"] = ctx.RateLimiter.Stats()
+	c.HTML(http.StatusOK, "rate_limits/index.html", req.TemplateData)
+}
+
+// RateLimitOverrideCreate creates a new rate limit override.
+//
+// POST /rate_limits/new
+func RateLimitOverrideCreate(c *gin.Context) {
+	saveRateLimitOverrideForm(c)
+}
+
+// RateLimitOverrideDelete deletes a rate limit override, returning
+// the user or institution to the default limits.
+//
+// POST /rate_limits/delete/:id
+// DELETE /rate_limits/delete/:id
+func RateLimitOverrideDelete(c *gin.Context) {
+	req := NewRequest(c)
+	override, err := pgmodels.RateLimitOverrideByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(override, helpers.AuditActor(c))) {
+		return
+	}
+	err = override.Delete()
+	if AbortIfError(c, err) {
+		return
+	}
+	common.Context().Log.Info().Msgf("User %s deleted rate limit override %d (institution %d, user %d)", req.CurrentUser.Email, override.ID, override.InstitutionID, override.UserID)
+	helpers.SetFlashCookie(c, fmt.Sprintf("Rate limit override %d has been deleted.", override.ID))
+	c.Redirect(http.StatusSeeOther, "/rate_limits")
+}
+
+// RateLimitOverrideEdit shows the form to edit a rate limit override.
+//
+// GET /rate_limits/edit/:id
+func RateLimitOverrideEdit(c *gin.Context) {
+	req := NewRequest(c)
+	override, err := pgmodels.RateLimitOverrideByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	form, err := forms.NewRateLimitOverrideForm(override)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["form"] = form
+	c.HTML(http.StatusOK, form.Template, req.TemplateData)
+}
+
+// RateLimitOverrideNew shows the form for creating a new rate limit
+// override.
+//
+// GET /rate_limits/new
+func RateLimitOverrideNew(c *gin.Context) {
+	req := NewRequest(c)
+	form, err := forms.NewRateLimitOverrideForm(&pgmodels.RateLimitOverride{})
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["form"] = form
+	c.HTML(http.StatusOK, form.Template, req.TemplateData)
+}
+
+// RateLimitOverrideUpdate saves changes to an existing rate limit
+// override.
+//
+// PUT /rate_limits/edit/:id
+// POST /rate_limits/edit/:id
+func RateLimitOverrideUpdate(c *gin.Context) {
+	saveRateLimitOverrideForm(c)
+}
+
+func saveRateLimitOverrideForm(c *gin.Context) {
+	req := NewRequest(c)
+	var err error
+	override := &pgmodels.RateLimitOverride{}
+	if req.Auth.ResourceID > 0 {
+		override, err = pgmodels.RateLimitOverrideByID(req.Auth.ResourceID)
+		if AbortIfError(c, err) {
+			return
+		}
+	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(override, helpers.AuditActor(c))) {
+		return
+	}
+
+	// Bind submitted form values in case we have to
+	// re-display the form with an error message.
+	c.ShouldBind(override)
+	override.ID = req.Auth.ResourceID
+
+	form, err := forms.NewRateLimitOverrideForm(override)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["form"] = form
+	if form.Save() {
+		common.Context().Log.Info().Msgf("User %s saved rate limit override %d (institution %d, user %d, per minute %d, burst %d, exempt %t)", req.CurrentUser.Email, override.ID, override.InstitutionID, override.UserID, override.PerMinute, override.Burst, override.Exempt)
+		c.Redirect(form.Status, "/rate_limits")
+	} else {
+		req.TemplateData["FormError"] = form.Error
+		c.HTML(form.Status, form.Template, req.TemplateData)
+	}
+}
@@ -0,0 +1,89 @@
+package webui_test
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/pgmodels"
+	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitOverrideCRUD(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	// Only APTrust admins can see and change rate limits.
+	tu.Inst1AdminClient.GET("/rate_limits").Expect().Status(http.StatusForbidden)
+	tu.Inst1AdminClient.GET("/rate_limits/new").Expect().Status(http.StatusForbidden)
+	tu.Inst1UserClient.GET("/rate_limits").Expect().Status(http.StatusForbidden)
+	tu.SysAdminClient.GET("/rate_limits").Expect().Status(http.StatusOK)
+	tu.SysAdminClient.GET("/rate_limits/new").Expect().Status(http.StatusOK)
+
+	instID := strconv.FormatInt(tu.Inst1User.InstitutionID, 10)
+	tu.SysAdminClient.POST("/rate_limits/new").
+		WithFormField(constants.CSRFTokenName, tu.SysAdminToken).
+		WithFormField("InstitutionID", instID).
+		WithFormField("UserID", strconv.FormatInt(tu.Inst1User.ID, 10)).
+		WithFormField("PerMinute", "30").
+		WithFormField("Burst", "5").
+		WithFormField("Exempt", "false").
+		WithFormField("Note", "Rate limit controller test").
+		Expect().Status(http.StatusOK)
+
+	query := pgmodels.NewQuery().Where("note", "=", "Rate limit controller test")
+	override, err := pgmodels.RateLimitOverrideGet(query)
+	require.Nil(t, err)
+	assert.Equal(t, tu.Inst1User.ID, override.UserID)
+	assert.Equal(t, 30, override.PerMinute)
+	assert.Equal(t, 5, override.Burst)
+
+	html := tu.SysAdminClient.GET("/rate_limits").Expect().Status(http.StatusOK).Body().Raw()
+	assert.Contains(t, html, tu.Inst1User.Email)
+	assert.Contains(t, html, "Rate limit controller test")
+
+	// Missing limits should re-display the form.
+	tu.SysAdminClient.POST("/rate_limits/new").
+		WithFormField(constants.CSRFTokenName, tu.SysAdminToken).
+		WithFormField("InstitutionID", instID).
+		WithFormField("Exempt", "false").
+		Expect().Status(http.StatusBadRequest)
+
+	editURL := fmt.Sprintf("/rate_limits/edit/%d", override.ID)
+	tu.SysAdminClient.GET(editURL).Expect().Status(http.StatusOK)
+	tu.Inst1AdminClient.GET(editURL).Expect().Status(http.StatusForbidden)
+	tu.SysAdminClient.PUT(editURL).
+		WithFormField(constants.CSRFTokenName, tu.SysAdminToken).
+		WithFormField("InstitutionID", instID).
+		WithFormField("UserID", strconv.FormatInt(tu.Inst1User.ID, 10)).
+		WithFormField("Exempt", "true").
+		WithFormField("Note", "Rate limit controller test").
+		Expect().Status(http.StatusOK)
+	override, err = pgmodels.RateLimitOverrideByID(override.ID)
+	require.Nil(t, err)
+	assert.True(t, override.Exempt)
+
+	deleteURL := fmt.Sprintf("/rate_limits/delete/%d", override.ID)
+	tu.Inst1AdminClient.POST(deleteURL).
+		WithFormField(constants.CSRFTokenName, tu.Inst1AdminToken).
+		Expect().Status(http.StatusForbidden)
+	tu.SysAdminClient.POST(deleteURL).
+		WithFormField(constants.CSRFTokenName, tu.SysAdminToken).
+		Expect().Status(http.StatusOK)
+	_, err = pgmodels.RateLimitOverrideByID(override.ID)
+	assert.True(t, pgmodels.IsNoRowError(err))
+
+	// The deletion should be in the audit log.
+	auditQuery := pgmodels.NewQuery().
+		Where("model_type", "=", "RateLimitOverride").
+		Where("model_id", "=", override.ID).
+		OrderBy("id", "desc")
+	entry, err := pgmodels.AuditLogGet(auditQuery)
+	require.Nil(t, err)
+	assert.Equal(t, constants.AuditActionDelete, entry.Action)
+	assert.Equal(t, tu.SysAdmin.Email, entry.ActorEmail)
+	assert.Nil(t, entry.After)
+}
@@ -0,0 +1,33 @@
+package webui
+
+import (
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/pgmodels"
+)
+
+// FixOrphanedWorkItem applies the fix that reconciliation found for
+// orphan, either requeueing the item or deleting its stale Redis state,
+// and records the outcome in orphan.
+//
+// The web UI and admin API share this function.
+func FixOrphanedWorkItem(orphan *pgmodels.OrphanedWorkItem, user *pgmodels.User) {
+	ctx := common.Context()
+	var err error
+	if orphan.Fix == pgmodels.OrphanFixRedisDelete {
+		ctx.Log.Info().Msgf("User %s is deleting stale Redis data for WorkItem %d", user.Email, orphan.WorkItemID)
+		_, err = ctx.RedisClient.WorkItemDelete(orphan.WorkItemID)
+	} else {
+		var workItem *pgmodels.WorkItem
+		workItem, err = pgmodels.WorkItemByID(orphan.WorkItemID)
+		if err == nil {
+			ctx.Log.Info().Msgf("User %s is requeueing orphaned WorkItem %d to %s", user.Email, orphan.WorkItemID, orphan.TargetStage)
+			err = workItem.SetForRequeue(orphan.TargetStage)
+		}
+	}
+	if err != nil {
+		ctx.Log.Error().Msgf("Reconciliation of WorkItem %d failed: %v", orphan.WorkItemID, err)
+		orphan.Error = err.Error()
+		return
+	}
+	orphan.Fixed = true
+}
@@ -0,0 +1,34 @@
+package webui
+
+import (
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/network"
+	"github.com/stretchr/stew/slice"
+)
+
+// LoadRedisWorkItemState returns the typed Redis state for a WorkItem,
+// with its file list narrowed to the page of files matching fileFilter
+// that pager describes. The file summary still counts all files. This
+// sets the pager's counts from the number of matching files. It returns
+// common.ErrInvalidFileFilter if fileFilter isn't one of the
+// network.RedisFileFilters, and common.ErrWrongDataType if the page
+// size is less than one.
+//
+// The web UI and admin API share this function.
+func LoadRedisWorkItemState(workItemID int64, fileFilter string, pager *common.Pager) (*network.RedisWorkItemState, error) {
+	if !slice.Contains(network.RedisFileFilters, fileFilter) {
+		return nil, common.ErrInvalidFileFilter
+	}
+	if pager.PerPage < 1 {
+		return nil, common.ErrWrongDataType
+	}
+	state, err := common.Context().RedisClient.WorkItemState(workItemID, fileFilter, pager.QueryOffset, pager.PerPage)
+	if err != nil {
+		return nil, err
+	}
+	pager.SetCounts(state.FilesMatched, len(state.Files))
+	if len(state.Files) == 0 {
+		pager.ItemFirst = 0
+	}
+	return state, nil
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/APTrust/registry/common"
 	"github.com/APTrust/registry/forms"
 	"github.com/APTrust/registry/pgmodels"
 	"github.com/gin-gonic/gin"
@@ -13,12 +15,14 @@ import (
 )
 
 type DepositReportParams struct {
-	ChartMetric   string
-	InstitutionID int64
-	StorageOption string
-	ReportType    string
-	StartDate     time.Time
-	EndDate       time.Time
+	BagGroupID         int64
+	BagGroupIdentifier string
+	ChartMetric        string
+	InstitutionID      int64
+	StorageOption      string
+	ReportType         string
+	StartDate          time.Time
+	EndDate            time.Time
 }
 
 // GET /reports/billing
@@ -66,16 +70,30 @@ func DepositReportShow(c *gin.Context) {
 	if !req.CurrentUser.IsAdmin() {
 		params.InstitutionID = req.CurrentUser.InstitutionID
 	}
+	bagGroup, err := depositBagGroup(req, params)
+	if AbortIfError(c, err) {
+		return
+	}
+	if bagGroup != nil {
+		params.InstitutionID = bagGroup.InstitutionID
+	}
 	var deposits []*pgmodels.DepositStats
-	var err error
 	if params.ReportType == "over_time" {
 		req.TemplateData["chartTitle"] = "Deposits Over Time"
 		req.TemplateData["chartAltText"] = fmt.Sprintf("APTrust Deposits Over Time from %s to %s", params.StartDate.Format("January 2, 2006"), params.EndDate.Format("January 2, 2006"))
-		deposits, err = pgmodels.DepositStatsOverTime(params.InstitutionID, params.StorageOption, params.StartDate, params.EndDate)
+		if bagGroup != nil {
+			deposits, err = pgmodels.DepositStatsOverTimeForBagGroup(bagGroup.ID, params.StorageOption, params.StartDate, params.EndDate)
+		} else {
+			deposits, err = pgmodels.DepositStatsOverTime(params.InstitutionID, params.StorageOption, params.StartDate, params.EndDate)
+		}
 	} else {
 		req.TemplateData["chartTitle"] = "Deposits By Institution"
 		req.TemplateData["chartAltText"] = fmt.Sprintf("APTrust Deposits By Institution from %s to %s", params.StartDate.Format("January 2, 2006"), params.EndDate.Format("January 2, 2006"))
-		deposits, err = pgmodels.DepositStatsSelect(params.InstitutionID, params.StorageOption, params.EndDate)
+		if bagGroup != nil {
+			deposits, err = pgmodels.DepositStatsForBagGroup(bagGroup.ID, params.StorageOption, params.EndDate)
+		} else {
+			deposits, err = pgmodels.DepositStatsSelect(params.InstitutionID, params.StorageOption, params.EndDate)
+		}
 	}
 	if AbortIfError(c, err) {
 		return
@@ -114,6 +132,37 @@ func DepositReportShow(c *gin.Context) {
 	c.HTML(http.StatusOK, template, req.TemplateData)
 }
 
+// PipelineReportShow shows WorkItem throughput and latency by action,
+// stage and node. This is an admin-only report.
+//
+// GET /reports/pipeline
+func PipelineReportShow(c *gin.Context) {
+	req := NewRequest(c)
+	params, err := GetPipelineReportParams(c)
+	if AbortIfError(c, err) {
+		return
+	}
+	stats, err := pgmodels.PipelineStatsSelect(params.InstitutionID, params.StartDate, params.EndDate)
+	if AbortIfError(c, err) {
+		return
+	}
+	filterCollection := req.GetFilterCollection()
+	if filterCollection.ValueOf("start_date") == "" {
+		filterCollection.Add("start_date", []string{params.StartDate.Format("2006-01-02")})
+	}
+	if filterCollection.ValueOf("end_date") == "" {
+		filterCollection.Add("end_date", []string{params.EndDate.AddDate(0, 0, -1).Format("2006-01-02")})
+	}
+	filterForm, err := forms.NewPipelineReportFilterForm(filterCollection, req.CurrentUser)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["stats"] = stats
+	req.TemplateData["filterForm"] = filterForm
+	req.TemplateData["reportParams"] = params
+	c.HTML(http.StatusOK, "reports/pipeline.html", req.TemplateData)
+}
+
 func depositInstList(deposits []*pgmodels.DepositStats) []string {
 	instList := make([]string, 0)
 	for _, stats := range deposits {
@@ -134,6 +183,33 @@ func depositStorageOptions(deposits []*pgmodels.DepositStats) []string {
 	return list
 }
 
+// depositBagGroup returns the bag group the deposit report is filtered
+// on, or nil if the report isn't filtered on a bag group. Non-admins can
+// report only on their own institution's bag groups.
+//
+// The report filters on bag_group_id, unless the institution has too
+// many bag groups to list. Then it filters on bag_group_identifier,
+// which identifies a group only within the report's institution.
+func depositBagGroup(req *Request, params DepositReportParams) (*pgmodels.BagGroup, error) {
+	var bagGroup *pgmodels.BagGroup
+	var err error
+	if params.BagGroupID > 0 {
+		bagGroup, err = pgmodels.BagGroupByID(params.BagGroupID)
+	} else if params.BagGroupIdentifier != "" && params.InstitutionID > 0 {
+		bagGroup, err = pgmodels.BagGroupByIdentifier(params.InstitutionID, params.BagGroupIdentifier)
+	} else {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !req.CurrentUser.IsAdmin() && bagGroup.InstitutionID != req.CurrentUser.InstitutionID {
+		common.Context().Log.Warn().Msgf("User %d illegally tried to run a deposit report on bag group %d belonging to institution %d. Permission was denied.", req.CurrentUser.ID, bagGroup.ID, bagGroup.InstitutionID)
+		return nil, common.ErrPermissionDenied
+	}
+	return bagGroup, nil
+}
+
 // getDeositReportParams parses params from the query string for our
 // deposit report. It ignores parse errors for updatedBefore and
 // institutionID because these fields can legitimately be empty.
@@ -147,6 +223,8 @@ func getDepositReportParams(c *gin.Context) DepositReportParams {
 		endDate = time.Now().UTC()
 	}
 	institutionID, _ := strconv.ParseInt(c.Query("institution_id"), 10, 64)
+	bagGroupID, _ := strconv.ParseInt(c.Query("bag_group_id"), 10, 64)
+	bagGroupIdentifier := strings.TrimSpace(c.Query("bag_group_identifier"))
 	storageOption := c.Query("storage_option")
 	chartMetric := c.Query("chart_metric")
 	reportType := c.Query("report_type")
@@ -154,11 +232,70 @@ func getDepositReportParams(c *gin.Context) DepositReportParams {
 		reportType = "by_inst"
 	}
 	return DepositReportParams{
-		ChartMetric:   chartMetric,
-		InstitutionID: institutionID,
-		ReportType:    reportType,
-		StorageOption: storageOption,
-		StartDate:     startDate,
-		EndDate:       endDate,
+		BagGroupID:         bagGroupID,
+		BagGroupIdentifier: bagGroupIdentifier,
+		ChartMetric:        chartMetric,
+		InstitutionID:      institutionID,
+		ReportType:         reportType,
+		StorageOption:      storageOption,
+		StartDate:          startDate,
+		EndDate:            endDate,
+	}
+}
+
+// PipelineReportParams describes the institution and date range for the
+// pipeline report. StartDate is inclusive and EndDate is exclusive.
+type PipelineReportParams struct {
+	InstitutionID int64
+	StartDate     time.Time
+	EndDate       time.Time
+}
+
+// GetPipelineReportParams parses pipeline report params from the query
+// string. See GetReportDateRange for the date params and their
+// defaults. This returns common.ErrWrongDataType if either date or the
+// institution id can't be parsed. The web UI and the admin API both
+// use this.
+func GetPipelineReportParams(c *gin.Context) (PipelineReportParams, error) {
+	params := PipelineReportParams{}
+	var err error
+	if c.Query("institution_id") != "" {
+		params.InstitutionID, err = strconv.ParseInt(c.Query("institution_id"), 10, 64)
+		if err != nil {
+			return params, common.ErrWrongDataType
+		}
+	}
+	params.StartDate, params.EndDate, err = GetReportDateRange(c)
+	return params, err
+}
+
+// GetReportDateRange parses the start_date and end_date query params,
+// which are in YYYY-MM-DD format, for reports that cover whole days.
+// The end_date param is inclusive, so the returned endDate is midnight
+// at the start of the following day. Use it with startDate as a
+// half-open range: startDate <= t < endDate.
+//
+// The end date defaults to today, so endDate defaults to midnight
+// tomorrow, and the start date defaults to 30 days before endDate.
+// This returns common.ErrWrongDataType if either date can't be parsed.
+// Reports in the web UI and the admin API share this, so they all
+// cover the same days for the same params.
+func GetReportDateRange(c *gin.Context) (startDate, endDate time.Time, err error) {
+	endDate = time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if c.Query("end_date") != "" {
+		date, err := time.Parse("2006-01-02", c.Query("end_date"))
+		if err != nil {
+			return startDate, endDate, common.ErrWrongDataType
+		}
+		endDate = date.AddDate(0, 0, 1)
+	}
+	startDate = endDate.AddDate(0, 0, -30)
+	if c.Query("start_date") != "" {
+		date, err := time.Parse("2006-01-02", c.Query("start_date"))
+		if err != nil {
+			return startDate, endDate, common.ErrWrongDataType
+		}
+		startDate = date
 	}
+	return startDate, endDate, nil
 }
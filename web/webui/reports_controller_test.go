@@ -2,11 +2,17 @@ package webui_test
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/APTrust/registry/common"
 	"github.com/APTrust/registry/constants"
 	"github.com/APTrust/registry/web/testutil"
+	"github.com/APTrust/registry/web/webui"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestDepositReportShow(t *testing.T) {
@@ -71,3 +77,120 @@ func TestDepositReportShow(t *testing.T) {
 		Status(http.StatusOK).Body().Raw()
 	testutil.AssertMatchesAll(t, html, expectedForInst0)
 }
+
+func TestDepositReportShowBagGroup(t *testing.T) {
+	testutil.InitHTTPTests(t)
+
+	// Bag group 2 (carolina-2) belongs to Inst1.
+	expected := []string{
+		"Institution One</td>",
+		"Glacier-Deep-OH</td>",
+		"Standard</td>",
+		"Total</td>",
+	}
+	for _, client := range []*httpexpect.Expect{testutil.SysAdminClient, testutil.Inst1AdminClient} {
+		html := client.GET("/reports/deposits").
+			WithQuery("bag_group_id", 2).
+			Expect().
+			Status(http.StatusOK).Body().Raw()
+		testutil.AssertMatchesAll(t, html, expected)
+		testutil.AssertMatchesNone(t, html, []string{"Wasabi-VA</td>"})
+	}
+
+	// Institutions with too many bag groups to list filter by
+	// identifier instead. That finds the same group, but only
+	// within the user's own institution.
+	html := testutil.Inst1AdminClient.GET("/reports/deposits").
+		WithQuery("bag_group_identifier", "carolina-2").
+		Expect().
+		Status(http.StatusOK).Body().Raw()
+	testutil.AssertMatchesAll(t, html, expected)
+	testutil.AssertMatchesNone(t, html, []string{"Wasabi-VA</td>"})
+	testutil.Inst2AdminClient.GET("/reports/deposits").
+		WithQuery("bag_group_identifier", "carolina-2").
+		Expect().
+		Status(http.StatusNotFound)
+
+	// Inst2 can't report on Inst1's bag group.
+	testutil.Inst2AdminClient.GET("/reports/deposits").
+		WithQuery("bag_group_id", 2).
+		Expect().
+		Status(http.StatusForbidden)
+}
+
+func TestPipelineReportShow(t *testing.T) {
+	testutil.InitHTTPTests(t)
+
+	expected := []string{
+		"Pipeline Throughput",
+		"pipelineActions",
+		"pipelineStages",
+		"pipelineNodes",
+		"Ingest</td>",
+		"Receive</td>",
+		"Restore Object</td>",
+		`value="2016-08-01"`,
+		`value="2016-08-31"`,
+	}
+	html := testutil.SysAdminClient.GET("/reports/pipeline").
+		WithQuery("start_date", "2016-08-01").
+		WithQuery("end_date", "2016-08-31").
+		Expect().
+		Status(http.StatusOK).Body().Raw()
+	testutil.AssertMatchesAll(t, html, expected)
+
+	// Nothing was processed in 2015.
+	html = testutil.SysAdminClient.GET("/reports/pipeline").
+		WithQuery("start_date", "2015-01-01").
+		WithQuery("end_date", "2015-12-31").
+		Expect().
+		Status(http.StatusOK).Body().Raw()
+	testutil.AssertMatchesAll(t, html, []string{"No work items were processed in this period."})
+
+	// Default is the last 30 days.
+	testutil.SysAdminClient.GET("/reports/pipeline").
+		Expect().
+		Status(http.StatusOK)
+
+	testutil.SysAdminClient.GET("/reports/pipeline").
+		WithQuery("start_date", "yesterday").
+		Expect().
+		Status(http.StatusBadRequest)
+
+	// Only APTrust admins can see this report.
+	for _, client := range []*httpexpect.Expect{testutil.Inst1AdminClient, testutil.Inst1UserClient} {
+		client.GET("/reports/pipeline").Expect().Status(http.StatusForbidden)
+	}
+}
+
+func TestGetReportDateRange(t *testing.T) {
+	dateRange := func(query string) (time.Time, time.Time, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/reports/pipeline?"+query, nil)
+		return webui.GetReportDateRange(c)
+	}
+
+	// End date is inclusive, so the range ends the next day.
+	startDate, endDate, err := dateRange("start_date=2016-08-01&end_date=2016-08-31")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2016, 8, 1, 0, 0, 0, 0, time.UTC), startDate)
+	assert.Equal(t, time.Date(2016, 9, 1, 0, 0, 0, 0, time.UTC), endDate)
+
+	// Start date defaults to 30 days before the end of the range.
+	startDate, endDate, err = dateRange("end_date=2016-08-31")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2016, 8, 2, 0, 0, 0, 0, time.UTC), startDate)
+	assert.Equal(t, time.Date(2016, 9, 1, 0, 0, 0, 0, time.UTC), endDate)
+
+	// End date defaults to today, so the range ends at midnight tomorrow.
+	tomorrow := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	startDate, endDate, err = dateRange("")
+	assert.Nil(t, err)
+	assert.Equal(t, tomorrow, endDate)
+	assert.Equal(t, tomorrow.AddDate(0, 0, -30), startDate)
+
+	_, _, err = dateRange("start_date=yesterday")
+	assert.Equal(t, common.ErrWrongDataType, err)
+	_, _, err = dateRange("end_date=2016-13-01")
+	assert.Equal(t, common.ErrWrongDataType, err)
+}
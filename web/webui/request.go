@@ -53,10 +53,24 @@ func NewRequest(c *gin.Context) *Request {
 			constants.CSRFTokenName: csrfToken,
 		},
 	}
+	if currentUser != nil {
+		req.TemplateData["nsqPauseWindows"] = activeNSQPauseWindows()
+	}
 	helpers.DeleteFlashCookie(c)
 	return req
 }
 
+// activeNSQPauseWindows returns the NSQ pause windows now in effect,
+// so the header can show a banner for each. We don't want a database
+// problem to break every page, so this logs errors and returns nil.
+func activeNSQPauseWindows() []*pgmodels.NSQPauseWindow {
+	windows, err := pgmodels.NSQPauseWindowsActive()
+	if err != nil {
+		common.Context().Log.Error().Msgf("Error getting active NSQ pause windows: %v", err)
+	}
+	return windows
+}
+
 func ShowOpenSubMenu(auth *middleware.ResourceAuthorization) bool {
 	submenuItems := []string{
 		"AlertIndex",
@@ -71,6 +85,11 @@ func ShowOpenSubMenu(auth *middleware.ResourceAuthorization) bool {
 		"NsqShow",
 		"NsqInit",
 		"NsqAdmin",
+		"NSQPauseWindowCreate",
+		"NSQPauseWindowIndex",
+		"NSQPauseWindowNew",
+		"WebhookIndex",
+		"WebhookShow",
 	}
 	return slice.Contains(submenuItems, auth.Handler)
 }
@@ -90,6 +109,9 @@ func (req *Request) GetFilterCollection() *pgmodels.FilterCollection {
 			chips = append(chips, filter)
 		}
 	}
+	// Invalid OR group params are ignored, like other unknown params.
+	groupFilters, _ := fc.AddOrGroups(req.GinContext.Request.URL.Query(), allowedFilters)
+	chips = append(chips, groupFilters...)
 	for _, value := range req.GinContext.QueryArray("sort") {
 		fc.AddOrderBy(value)
 	}
@@ -135,6 +157,10 @@ func (req *Request) LoadResourceList(items interface{}, orderByColumn, direction
 		}
 	}
 	if !filterCollection.HasExplicitSorting() {
+		// Full-text searches show best matches first.
+		if search := filterCollection.SearchFilter(); search != nil {
+			query.OrderByRank(search.Column, search.Values[0])
+		}
 		query.OrderBy(orderByColumn, direction)
 	}
 	pager, err := common.NewPager(req.GinContext, req.PathAndQuery, 20)
@@ -9,6 +9,7 @@ import (
 	"github.com/APTrust/registry/constants"
 	"github.com/APTrust/registry/forms"
 	"github.com/APTrust/registry/helpers"
+	"github.com/APTrust/registry/pgmodels"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/stew/slice"
 )
@@ -41,6 +42,9 @@ func UserTwoFactorBackup(c *gin.Context) {
 // POST /users/2fa_sms
 func UserTwoFactorGenerateSMS(c *gin.Context) {
 	req := NewRequest(c)
+	if AbortIfError(c, pgmodels.AuditChangesBy(req.CurrentUser, helpers.AuditActor(c))) {
+		return
+	}
 	token, err := req.CurrentUser.CreateOTPToken()
 	if AbortIfError(c, err) {
 		return
@@ -73,6 +77,9 @@ func UserTwoFactorGenerateSMS(c *gin.Context) {
 // POST /users/2fa_push/
 func UserTwoFactorPush(c *gin.Context) {
 	req := NewRequest(c)
+	if AbortIfError(c, pgmodels.AuditChangesBy(req.CurrentUser, helpers.AuditActor(c))) {
+		return
+	}
 	approved, err := userSendAuthyOneTouch(req)
 	if AbortIfError(c, err) {
 		return
@@ -90,6 +97,9 @@ func UserTwoFactorPush(c *gin.Context) {
 // POST /users/2fa_verify/
 func UserTwoFactorVerify(c *gin.Context) {
 	req := NewRequest(c)
+	if AbortIfError(c, pgmodels.AuditChangesBy(req.CurrentUser, helpers.AuditActor(c))) {
+		return
+	}
 	otp := c.PostForm("otp")
 	method := c.PostForm("two_factor_method")
 
@@ -151,6 +161,9 @@ func UserInit2FASetup(c *gin.Context) {
 // POST /users/2fa_setup
 func UserComplete2FASetup(c *gin.Context) {
 	req := NewRequest(c)
+	if AbortIfError(c, pgmodels.AuditChangesBy(req.CurrentUser, helpers.AuditActor(c))) {
+		return
+	}
 	user := req.CurrentUser
 	prefs, err := NewTwoFactorPreferences(req)
 	if err != nil {
@@ -234,6 +247,9 @@ func UserComplete2FASetup(c *gin.Context) {
 // POST /users/confirm_phone
 func UserConfirmPhone(c *gin.Context) {
 	req := NewRequest(c)
+	if AbortIfError(c, pgmodels.AuditChangesBy(req.CurrentUser, helpers.AuditActor(c))) {
+		return
+	}
 	otp := c.PostForm("otp")
 	user := req.CurrentUser
 	if common.ComparePasswords(user.EncryptedOTPSecret, otp) {
@@ -282,6 +298,9 @@ func UserAuthyRegister(req *Request) error {
 // POST /users/backup_codes
 func UserGenerateBackupCodes(c *gin.Context) {
 	req := NewRequest(c)
+	if AbortIfError(c, pgmodels.AuditChangesBy(req.CurrentUser, helpers.AuditActor(c))) {
+		return
+	}
 	backupCodes := make([]string, 6)
 	encCodes := make([]string, 6)
 	for i := 0; i < 6; i++ {
@@ -1,6 +1,7 @@
 package webui_test
 
 import (
+	"fmt"
 	"net/http"
 	"regexp"
 	"strings"
@@ -302,6 +303,21 @@ func TestUserBackupCodes(t *testing.T) {
 	require.Nil(t, err)
 	assert.Equal(t, 6, len(reloadedUser.OTPBackupCodes))
 
+	// The audit log should show that the codes changed, without
+	// showing the codes.
+	auditQuery := pgmodels.NewQuery().
+		Where("model_type", "=", "User").
+		Where("model_id", "=", testutil.Inst1User.ID).
+		Where("route", "=", "/users/backup_codes").
+		OrderBy("id", "desc")
+	entry, err := pgmodels.AuditLogGet(auditQuery)
+	require.Nil(t, err)
+	assert.Equal(t, testutil.Inst1User.Email, entry.ActorEmail)
+	assert.Contains(t, entry.After, "otp_backup_codes_fingerprint")
+	for _, code := range backupCodes {
+		assert.NotContains(t, fmt.Sprintf("%v", entry.After), code)
+	}
+
 	// Make sure backup code can be verified
 	testutil.Inst1UserClient.POST("/users/2fa_verify").
 		WithHeader("Referer", testutil.BaseURL).
@@ -32,6 +32,9 @@ func UserDelete(c *gin.Context) {
 	if AbortIfError(c, err) {
 		return
 	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(user, helpers.AuditActor(c))) {
+		return
+	}
 	err = user.Delete()
 	if AbortIfError(c, err) {
 		return
@@ -47,6 +50,9 @@ func UserUndelete(c *gin.Context) {
 	if AbortIfError(c, err) {
 		return
 	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(user, helpers.AuditActor(c))) {
+		return
+	}
 	err = user.Undelete()
 	if AbortIfError(c, err) {
 		return
@@ -213,6 +219,9 @@ func UserChangePassword(c *gin.Context) {
 		AbortIfError(c, err)
 		return
 	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(userToEdit, helpers.AuditActor(c))) {
+		return
+	}
 
 	pwd := c.PostForm("NewPassword")
 	confirm := c.PostForm("ConfirmNewPassword")
@@ -392,6 +401,9 @@ func UserCompletePasswordReset(c *gin.Context) {
 		return
 	}
 
+	if AbortIfError(c, pgmodels.AuditChangesBy(user, helpers.AuditActor(c))) {
+		return
+	}
 	user.SignInCount = user.SignInCount + 1
 	if user.CurrentSignInIP != "" {
 		user.LastSignInIP = user.CurrentSignInIP
@@ -430,6 +442,9 @@ func UserGetAPIKey(c *gin.Context) {
 	if AbortIfError(c, err) {
 		return
 	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(req.CurrentUser, helpers.AuditActor(c))) {
+		return
+	}
 	req.CurrentUser.EncryptedAPISecretKey = encKey
 	err = req.CurrentUser.Save()
 	if AbortIfError(c, err) {
@@ -546,6 +561,9 @@ func UserUpdateXHR(c *gin.Context) {
 	if api.AbortIfError(c, err) {
 		return
 	}
+	if api.AbortIfError(c, pgmodels.AuditChangesBy(userToEdit, helpers.AuditActor(c))) {
+		return
+	}
 	if strings.TrimSpace(c.PostForm("Name")) != "" {
 		userToEdit.Name = strings.TrimSpace(c.PostForm("Name"))
 	}
@@ -608,6 +626,9 @@ func saveUserForm(c *gin.Context) {
 		}
 		userToEdit.EncryptedPassword = encPwd
 	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(userToEdit, helpers.AuditActor(c))) {
+		return
+	}
 
 	// Bind submitted form values in case we have to
 	// re-display the form with an error message.
@@ -1,6 +1,7 @@
 package webui_test
 
 import (
+	"fmt"
 	"net/http"
 	"regexp"
 	"testing"
@@ -172,6 +173,18 @@ func TestUserCreateEditDeleteUndelete(t *testing.T) {
 		WithHeader(constants.CSRFHeaderName, testutil.Inst1AdminToken).
 		Expect().Status(http.StatusOK)
 
+	// The audit log should attribute the deletion to the admin
+	// who made the request.
+	auditQuery := pgmodels.NewQuery().
+		Where("model_type", "=", "User").
+		Where("model_id", "=", user.ID).
+		OrderBy("id", "desc")
+	entry, err := pgmodels.AuditLogGet(auditQuery)
+	require.Nil(t, err)
+	assert.Equal(t, constants.AuditActionDelete, entry.Action)
+	assert.Equal(t, testutil.Inst1Admin.Email, entry.ActorEmail)
+	assert.Equal(t, fmt.Sprintf("/users/delete/%d", user.ID), entry.Route)
+
 	// Undelete the user. Again, we get a redirect ending with an OK.
 	testutil.Inst1AdminClient.POST("/users/undelete/{id}", user.ID).
 		WithHeader("Referer", testutil.BaseURL).
@@ -473,6 +486,20 @@ func TestUserGetAPIKey(t *testing.T) {
 		testutil.AssertMatchesAll(t, html, items)
 	}
 
+	// The audit log should show that the key changed, without
+	// showing the key.
+	auditQuery := pgmodels.NewQuery().
+		Where("model_type", "=", "User").
+		Where("model_id", "=", testutil.Inst1User.ID).
+		Where("route", "=", fmt.Sprintf("/users/get_api_key/%d", testutil.Inst1User.ID)).
+		OrderBy("id", "desc")
+	entry, err := pgmodels.AuditLogGet(auditQuery)
+	require.Nil(t, err)
+	assert.Equal(t, constants.AuditActionUpdate, entry.Action)
+	assert.Equal(t, testutil.Inst1User.Email, entry.ActorEmail)
+	assert.Contains(t, entry.After, "encrypted_api_secret_key_fingerprint")
+	assert.NotContains(t, entry.After, "encrypted_api_secret_key")
+
 	// No user can get another user's API key
 	testutil.Inst1AdminClient.POST("/users/get_api_key/{id}", testutil.Inst1User.ID).
 		WithHeader("Referer", testutil.BaseURL).
@@ -0,0 +1,192 @@
+package webui
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/APTrust/registry/common"
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/forms"
+	"github.com/APTrust/registry/helpers"
+	"github.com/APTrust/registry/pgmodels"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookCreate creates a new webhook.
+//
+// POST /webhooks/new
+func WebhookCreate(c *gin.Context) {
+	saveWebhookForm(c)
+}
+
+// WebhookDelete deletes a webhook along with its delivery log.
+//
+// POST /webhooks/delete/:id
+// DELETE /webhooks/delete/:id
+func WebhookDelete(c *gin.Context) {
+	req := NewRequest(c)
+	hook, err := pgmodels.WebhookByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(hook, helpers.AuditActor(c))) {
+		return
+	}
+	err = hook.Delete()
+	if AbortIfError(c, err) {
+		return
+	}
+	common.Context().Log.Info().Msgf("User %s deleted webhook %d (%s) for institution %d", req.CurrentUser.Email, hook.ID, hook.URL, hook.InstitutionID)
+	helpers.SetFlashCookie(c, fmt.Sprintf("Webhook %s has been deleted.", hook.URL))
+	c.Redirect(http.StatusSeeOther, "/webhooks")
+}
+
+// WebhookDeliveryShow shows the payload and response of a single
+// webhook delivery.
+//
+// GET /webhooks/deliveries/:id
+func WebhookDeliveryShow(c *gin.Context) {
+	req := NewRequest(c)
+	delivery, err := pgmodels.WebhookDeliveryByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["delivery"] = delivery
+	c.HTML(http.StatusOK, "webhooks/delivery.html", req.TemplateData)
+}
+
+// WebhookEdit shows the form to edit a webhook.
+//
+// GET /webhooks/edit/:id
+func WebhookEdit(c *gin.Context) {
+	req := NewRequest(c)
+	hook, err := pgmodels.WebhookByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	form, err := forms.NewWebhookForm(hook, req.CurrentUser)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["form"] = form
+	c.HTML(http.StatusOK, form.Template, req.TemplateData)
+}
+
+// WebhookIndex shows the webhooks configured for the current user's
+// institution. SysAdmin sees webhooks for all institutions.
+//
+// GET /webhooks
+func WebhookIndex(c *gin.Context) {
+	req := NewRequest(c)
+	query := pgmodels.NewQuery().Relations("Institution").OrderBy("institution_id", "asc").OrderBy("url", "asc")
+	if !req.CurrentUser.IsAdmin() {
+		query.Where("institution_id", "=", req.CurrentUser.InstitutionID)
+	}
+	hooks, err := pgmodels.WebhookSelect(query)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["webhooks"] = hooks
+	c.HTML(http.StatusOK, "webhooks/index.html", req.TemplateData)
+}
+
+// WebhookNew shows the form for creating a new webhook.
+//
+// GET /webhooks/new
+func WebhookNew(c *gin.Context) {
+	req := NewRequest(c)
+	form, err := forms.NewWebhookForm(pgmodels.NewWebhook(req.CurrentUser.InstitutionID), req.CurrentUser)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["form"] = form
+	c.HTML(http.StatusOK, form.Template, req.TemplateData)
+}
+
+// WebhookShow shows a webhook, its signing secret, and its most
+// recent deliveries.
+//
+// GET /webhooks/show/:id
+func WebhookShow(c *gin.Context) {
+	req := NewRequest(c)
+	hook, err := pgmodels.WebhookByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	query := pgmodels.NewQuery().Where("webhook_id", "=", hook.ID).OrderBy("created_at", "desc").OrderBy("id", "desc").Limit(50)
+	deliveries, err := pgmodels.WebhookDeliverySelect(query)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["webhook"] = hook
+	req.TemplateData["deliveries"] = deliveries
+	req.TemplateData["eventTypes"] = forms.WebhookEventTypeList
+	c.HTML(http.StatusOK, "webhooks/show.html", req.TemplateData)
+}
+
+// WebhookTest sends a test event to a webhook right away and
+// reports whether the endpoint accepted it.
+//
+// POST /webhooks/test/:id
+func WebhookTest(c *gin.Context) {
+	req := NewRequest(c)
+	hook, err := pgmodels.WebhookByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	delivery, err := pgmodels.SendWebhookTestEvent(hook)
+	if AbortIfError(c, err) {
+		return
+	}
+	message := fmt.Sprintf("Test event was delivered. The endpoint responded with status %d.", delivery.ResponseCode)
+	if delivery.Status != constants.WebhookStatusSucceeded {
+		message = fmt.Sprintf("Test event could not be delivered. Response code: %d. Error: %s. We will retry it later.", delivery.ResponseCode, delivery.Error)
+	}
+	helpers.SetFlashCookie(c, message)
+	c.Redirect(http.StatusSeeOther, fmt.Sprintf("/webhooks/show/%d", hook.ID))
+}
+
+// WebhookUpdate saves changes to an existing webhook.
+//
+// PUT /webhooks/edit/:id
+// POST /webhooks/edit/:id
+func WebhookUpdate(c *gin.Context) {
+	saveWebhookForm(c)
+}
+
+func saveWebhookForm(c *gin.Context) {
+	req := NewRequest(c)
+	var err error
+	hook := pgmodels.NewWebhook(req.CurrentUser.InstitutionID)
+	if req.Auth.ResourceID > 0 {
+		hook, err = pgmodels.WebhookByID(req.Auth.ResourceID)
+		if AbortIfError(c, err) {
+			return
+		}
+	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(hook, helpers.AuditActor(c))) {
+		return
+	}
+	instID := hook.InstitutionID
+
+	// Bind submitted form values in case we have to
+	// re-display the form with an error message.
+	c.ShouldBind(hook)
+	hook.ID = req.Auth.ResourceID
+	hook.EventTypes = c.PostFormArray("EventTypes")
+	if !req.CurrentUser.IsAdmin() {
+		hook.InstitutionID = instID
+	}
+
+	form, err := forms.NewWebhookForm(hook, req.CurrentUser)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["form"] = form
+	if form.Save() {
+		c.Redirect(form.Status, form.PostSaveURL())
+	} else {
+		req.TemplateData["FormError"] = form.Error
+		c.HTML(form.Status, form.Template, req.TemplateData)
+	}
+}
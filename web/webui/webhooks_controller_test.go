@@ -0,0 +1,114 @@
+package webui_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/APTrust/registry/constants"
+	"github.com/APTrust/registry/pgmodels"
+	tu "github.com/APTrust/registry/web/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookCRUD(t *testing.T) {
+	tu.InitHTTPTests(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Inst users can't see or create webhooks.
+	tu.Inst1UserClient.GET("/webhooks").Expect().Status(http.StatusForbidden)
+	tu.Inst1UserClient.GET("/webhooks/new").Expect().Status(http.StatusForbidden)
+
+	tu.Inst1AdminClient.GET("/webhooks").Expect().Status(http.StatusOK)
+	tu.Inst1AdminClient.GET("/webhooks/new").Expect().Status(http.StatusOK)
+
+	// Inst admin creates a webhook for their own institution.
+	tu.Inst1AdminClient.POST("/webhooks/new").
+		WithFormField(constants.CSRFTokenName, tu.Inst1AdminToken).
+		WithFormField("URL", server.URL).
+		WithFormField("Description", "Webhook controller test").
+		WithFormField("Enabled", "true").
+		WithFormField("EventTypes", constants.WebhookEventWorkItemCompleted).
+		WithFormField("EventTypes", constants.WebhookEventFixityFailed).
+		Expect().Status(http.StatusOK)
+
+	query := pgmodels.NewQuery().Where("description", "=", "Webhook controller test")
+	hook, err := pgmodels.WebhookGet(query)
+	require.Nil(t, err)
+	assert.Equal(t, tu.Inst1Admin.InstitutionID, hook.InstitutionID)
+	assert.Equal(t, []string{constants.WebhookEventWorkItemCompleted, constants.WebhookEventFixityFailed}, hook.EventTypes)
+	assert.True(t, hook.Enabled)
+
+	// Missing events should re-display the form.
+	tu.Inst1AdminClient.POST("/webhooks/new").
+		WithFormField(constants.CSRFTokenName, tu.Inst1AdminToken).
+		WithFormField("URL", server.URL).
+		WithFormField("Enabled", "true").
+		Expect().Status(http.StatusBadRequest)
+
+	showURL := fmt.Sprintf("/webhooks/show/%d", hook.ID)
+	html := tu.Inst1AdminClient.GET(showURL).Expect().Status(http.StatusOK).Body().Raw()
+	assert.Contains(t, html, hook.Secret)
+
+	// Other institutions can't see or change this webhook.
+	tu.Inst2AdminClient.GET(showURL).Expect().Status(http.StatusForbidden)
+	tu.Inst2AdminClient.POST(fmt.Sprintf("/webhooks/test/%d", hook.ID)).
+		WithFormField(constants.CSRFTokenName, tu.Inst2AdminToken).
+		Expect().Status(http.StatusForbidden)
+
+	// Send a test event, which should show up in the delivery log.
+	html = tu.Inst1AdminClient.POST(fmt.Sprintf("/webhooks/test/%d", hook.ID)).
+		WithFormField(constants.CSRFTokenName, tu.Inst1AdminToken).
+		Expect().Status(http.StatusOK).Body().Raw()
+	assert.Contains(t, html, constants.WebhookEventTest)
+
+	deliveries, err := pgmodels.WebhookDeliverySelect(pgmodels.NewQuery().Where("webhook_id", "=", hook.ID))
+	require.Nil(t, err)
+	require.Equal(t, 1, len(deliveries))
+	assert.Equal(t, constants.WebhookStatusSucceeded, deliveries[0].Status)
+	deliveryURL := fmt.Sprintf("/webhooks/deliveries/%d", deliveries[0].ID)
+	tu.Inst1AdminClient.GET(deliveryURL).Expect().Status(http.StatusOK)
+	tu.Inst2AdminClient.GET(deliveryURL).Expect().Status(http.StatusForbidden)
+
+	// Edit
+	tu.Inst1AdminClient.GET(fmt.Sprintf("/webhooks/edit/%d", hook.ID)).Expect().Status(http.StatusOK)
+	tu.Inst1AdminClient.POST(fmt.Sprintf("/webhooks/edit/%d", hook.ID)).
+		WithFormField(constants.CSRFTokenName, tu.Inst1AdminToken).
+		WithFormField("URL", server.URL).
+		WithFormField("Description", "Webhook controller test").
+		WithFormField("Enabled", "false").
+		WithFormField("EventTypes", constants.WebhookEventDeletionCompleted).
+		Expect().Status(http.StatusOK)
+	hook, err = pgmodels.WebhookByID(hook.ID)
+	require.Nil(t, err)
+	assert.False(t, hook.Enabled)
+	assert.Equal(t, []string{constants.WebhookEventDeletionCompleted}, hook.EventTypes)
+
+	// SysAdmin sees webhooks for all institutions.
+	html = tu.SysAdminClient.GET("/webhooks").Expect().Status(http.StatusOK).Body().Raw()
+	assert.Contains(t, html, server.URL)
+
+	// Delete
+	tu.Inst1AdminClient.POST(fmt.Sprintf("/webhooks/delete/%d", hook.ID)).
+		WithFormField(constants.CSRFTokenName, tu.Inst1AdminToken).
+		Expect().Status(http.StatusOK)
+	_, err = pgmodels.WebhookByID(hook.ID)
+	assert.True(t, pgmodels.IsNoRowError(err))
+
+	// The deletion should be in the audit log.
+	auditQuery := pgmodels.NewQuery().
+		Where("model_type", "=", "Webhook").
+		Where("model_id", "=", hook.ID).
+		OrderBy("id", "desc")
+	entry, err := pgmodels.AuditLogGet(auditQuery)
+	require.Nil(t, err)
+	assert.Equal(t, constants.AuditActionDelete, entry.Action)
+	assert.Equal(t, tu.Inst1Admin.Email, entry.ActorEmail)
+	assert.Nil(t, entry.After)
+}
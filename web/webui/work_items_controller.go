@@ -74,6 +74,12 @@ func WorkItemShow(c *gin.Context) {
 		req.TemplateData["showMissingObjWarning"] = true
 	}
 
+	history, err := pgmodels.WorkItemHistoryFor(item.ID)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["history"] = history
+
 	getRedisInfo(req, item)
 	c.HTML(http.StatusOK, "work_items/show.html", req.TemplateData)
 }
@@ -86,6 +92,11 @@ func WorkItemUpdate(c *gin.Context) {
 	if AbortIfError(c, err) {
 		return
 	}
+	// This form is how admins repair items by hand, so it may move
+	// them to earlier stages without requeueing.
+	item := form.Model.(*pgmodels.WorkItem)
+	common.Context().Log.Warn().Msgf("User %s is overriding the transition check for WorkItem %d", req.CurrentUser.Email, item.ID)
+	item.OverrideTransitionCheck = true
 	if form.Save() {
 		c.Redirect(form.Status, form.PostSaveURL())
 	} else {
@@ -138,6 +149,9 @@ func WorkItemRequeue(c *gin.Context) {
 	if AbortIfError(c, err) {
 		return
 	}
+	if AbortIfError(c, pgmodels.AuditChangesBy(item, helpers.AuditActor(c))) {
+		return
+	}
 
 	stage := c.Request.PostFormValue("Stage")
 	aptContext.Log.Info().Msgf("Requeueing WorkItem %d to %s", item.ID, stage)
@@ -151,14 +165,103 @@ func WorkItemRequeue(c *gin.Context) {
 	if AbortIfError(c, err) {
 		return
 	}
+	helpers.SetFlashCookie(c, fmt.Sprintf("Item has been requeued to %s", topic))
+	redirectTo := fmt.Sprintf("/work_items/show/%d", item.ID)
+	c.Redirect(http.StatusSeeOther, redirectTo)
+}
 
-	err = aptContext.NSQClient.Enqueue(topic, item.ID)
+// WorkItemBulkRequeuePreview shows a form for choosing WorkItems to
+// requeue in bulk. If the query string includes filters, this also
+// shows which items match, and the stage and NSQ topic to which each
+// would be requeued. This is an admin-only feature.
+//
+// GET /work_items/bulk_requeue
+func WorkItemBulkRequeuePreview(c *gin.Context) {
+	req := NewRequest(c)
+	fc := BulkRequeueFilterCollection(c)
+	if AbortIfError(c, setBulkRequeueForm(req, fc)) {
+		return
+	}
+	if fc.HasFilters() {
+		result, err := BulkRequeueWorkItems(fc, c.Query("target_stage"), req.CurrentUser, true)
+		if err == common.ErrTooManyItems {
+			req.TemplateData["FormError"] = err
+		} else if AbortIfError(c, err) {
+			return
+		}
+		req.TemplateData["result"] = result
+	}
+	c.HTML(http.StatusOK, "work_items/bulk_requeue.html", req.TemplateData)
+}
+
+// WorkItemBulkRequeue requeues all WorkItems matching the filters
+// in the query string and shows the result for each item. This is
+// an admin-only feature typically used to recover from outages.
+//
+// PUT or POST /work_items/bulk_requeue
+func WorkItemBulkRequeue(c *gin.Context) {
+	req := NewRequest(c)
+	fc := BulkRequeueFilterCollection(c)
+	if AbortIfError(c, setBulkRequeueForm(req, fc)) {
+		return
+	}
+	result, err := BulkRequeueWorkItems(fc, c.Query("target_stage"), req.CurrentUser, false)
 	if AbortIfError(c, err) {
 		return
 	}
-	helpers.SetFlashCookie(c, fmt.Sprintf("Item has been requeued to %s", topic))
-	redirectTo := fmt.Sprintf("/work_items/show/%d", item.ID)
-	c.Redirect(http.StatusSeeOther, redirectTo)
+	req.TemplateData["result"] = result
+	c.HTML(http.StatusOK, "work_items/bulk_requeue.html", req.TemplateData)
+}
+
+func setBulkRequeueForm(req *Request, fc *pgmodels.FilterCollection) error {
+	form, err := forms.NewWorkItemBulkRequeueForm(fc, req.CurrentUser)
+	if err != nil {
+		return err
+	}
+	form.GetFields()["target_stage"].Value = req.GinContext.Query("target_stage")
+	req.TemplateData["form"] = form
+	req.TemplateData["requeueAction"] = req.PathAndQuery
+	return nil
+}
+
+// WorkItemReconcileShow shows WorkItems that NSQ and Redis show no
+// worker is handling, along with stale Redis state left behind by
+// completed items. Each row has a button to fix the item. This is an
+// admin-only feature.
+//
+// GET /work_items/reconcile
+func WorkItemReconcileShow(c *gin.Context) {
+	req := NewRequest(c)
+	gracePeriod := common.Context().Config.Reconciliation.GracePeriod
+	result, err := pgmodels.ReconcileWorkItems(gracePeriod)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["result"] = result
+	c.HTML(http.StatusOK, "work_items/reconcile.html", req.TemplateData)
+}
+
+// WorkItemReconcile checks a single WorkItem against NSQ and Redis
+// again and, if it's still orphaned, applies the fix. This is an
+// admin-only feature.
+//
+// PUT or POST /work_items/reconcile/:id
+func WorkItemReconcile(c *gin.Context) {
+	req := NewRequest(c)
+	gracePeriod := common.Context().Config.Reconciliation.GracePeriod
+	orphan, err := pgmodels.ReconcileWorkItem(req.Auth.ResourceID, gracePeriod)
+	if AbortIfError(c, err) {
+		return
+	}
+	FixOrphanedWorkItem(orphan, req.CurrentUser)
+	if orphan.Error != "" {
+		helpers.SetFlashCookie(c, fmt.Sprintf("Could not fix WorkItem %d: %s", orphan.WorkItemID, orphan.Error))
+	} else if orphan.Fix == pgmodels.OrphanFixRedisDelete {
+		helpers.SetFlashCookie(c, fmt.Sprintf("Deleted stale Redis data for WorkItem %d.", orphan.WorkItemID))
+	} else {
+		helpers.SetFlashCookie(c, fmt.Sprintf("WorkItem %d has been requeued to %s", orphan.WorkItemID, orphan.Topic))
+	}
+	c.Redirect(http.StatusSeeOther, "/work_items/reconcile")
 }
 
 // WorkItemRedisIndex shows a list of WorkItems that have records
@@ -221,6 +324,35 @@ func WorkItemRedisIndex(c *gin.Context) {
 	c.HTML(http.StatusOK, "work_items/index.html", req.TemplateData)
 }
 
+// WorkItemRedisShow shows the ingest or restoration state the workers
+// have stored in Redis for a WorkItem, including per-file progress.
+// The optional file_filter param limits the file list to files in error,
+// not validated, or not stored. Use the page and per_page params to page
+// through files. This is an admin-only feature.
+//
+// GET /work_items/redis/:id
+func WorkItemRedisShow(c *gin.Context) {
+	req := NewRequest(c)
+	item, err := pgmodels.WorkItemViewByID(req.Auth.ResourceID)
+	if AbortIfError(c, err) {
+		return
+	}
+	pager, err := common.NewPager(c, req.PathAndQuery, 50)
+	if AbortIfError(c, err) {
+		return
+	}
+	fileFilter := c.Query("file_filter")
+	state, err := LoadRedisWorkItemState(item.ID, fileFilter, pager)
+	if AbortIfError(c, err) {
+		return
+	}
+	req.TemplateData["item"] = item
+	req.TemplateData["state"] = state
+	req.TemplateData["fileFilter"] = fileFilter
+	req.TemplateData["filePager"] = pager
+	c.HTML(http.StatusOK, "work_items/redis.html", req.TemplateData)
+}
+
 // WorkItemRedisDelete deletes a WorkItem's Redis record.
 // This is an admin-only feature.
 //
@@ -247,6 +379,10 @@ func getFormAndRequest(c *gin.Context) (*forms.WorkItemForm, *Request, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	err = pgmodels.AuditChangesBy(workItem, helpers.AuditActor(c))
+	if err != nil {
+		return nil, nil, err
+	}
 	c.ShouldBind(workItem)
 	form := forms.NewWorkItemForm(workItem)
 	req.TemplateData["form"] = form
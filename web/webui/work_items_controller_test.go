@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/APTrust/registry/common"
 	"github.com/APTrust/registry/constants"
@@ -45,6 +46,32 @@ func TestWorkItemShow(t *testing.T) {
 	}
 }
 
+func TestWorkItemShowHistory(t *testing.T) {
+	testutil.InitHTTPTests(t)
+
+	// Item 26 has transition fixtures and belongs to inst 2.
+	items := []string{
+		"Time in Stage",
+		"stageDurations",
+		"transitionHistory",
+		"Copying files to preservation",
+		"Requeued for Store",
+		"worker-4",
+		"2h30m0s",
+		"Finished cleanup. Ingest complete.",
+	}
+	clients := []*httpexpect.Expect{
+		testutil.Inst2UserClient,
+		testutil.Inst2AdminClient,
+		testutil.SysAdminClient,
+	}
+	for _, client := range clients {
+		html := client.GET("/work_items/show/26").Expect().
+			Status(http.StatusOK).Body().Raw()
+		testutil.AssertMatchesAll(t, html, items)
+	}
+}
+
 func TestWorkItemShowMissingObjectLink(t *testing.T) {
 	testutil.InitHTTPTests(t)
 	item, err := pgmodels.WorkItemGet(pgmodels.NewQuery().Where("name", "=", "chocolate.tar"))
@@ -158,6 +185,23 @@ func TestWorkItemIndex(t *testing.T) {
 
 }
 
+func TestWorkItemIndexOrGroups(t *testing.T) {
+	testutil.InitHTTPTests(t)
+	html := testutil.SysAdminClient.GET("/work_items").
+		WithQuery("or.stage", constants.StageRecord).
+		WithQuery("or.stage", constants.StageFormatIdentification).
+		Expect().
+		Status(http.StatusOK).Body().Raw()
+	testutil.AssertMatchesAll(t, html, []string{
+		"/work_items/show/27",
+		"/work_items/show/29",
+		"Stage (or):</strong>",
+	})
+	testutil.AssertMatchesNone(t, html, []string{
+		"/work_items/show/28",
+	})
+}
+
 func TestWorkItemEditUpdate(t *testing.T) {
 	testutil.InitHTTPTests(t)
 
@@ -282,6 +326,152 @@ func TestWorkItemRequeue(t *testing.T) {
 
 }
 
+func TestWorkItemBulkRequeue(t *testing.T) {
+	testutil.InitHTTPTests(t)
+
+	workItem := testutil.CreateWorkItem(t, "unit_test_bulk_requeue.tar")
+
+	// Give this item a node no other item has, so our filters match only this.
+	workItem.Node = fmt.Sprintf("bulk-requeue-test-%d", workItem.ID)
+	require.Nil(t, workItem.Save())
+
+	// Preview shows what we'd requeue without changing anything.
+	html := testutil.SysAdminClient.GET("/work_items/bulk_requeue").
+		WithQuery("node", workItem.Node).
+		WithQuery("target_stage", constants.StageValidate).
+		Expect().Status(http.StatusOK).Body().Raw()
+	assert.Contains(t, html, workItem.Name)
+	assert.Contains(t, html, constants.IngestValidation)
+	assert.Contains(t, html, "Requeue Items")
+
+	item, err := pgmodels.WorkItemByID(workItem.ID)
+	require.Nil(t, err)
+	assert.Equal(t, constants.StageRecord, item.Stage)
+	assert.Equal(t, constants.StatusStarted, item.Status)
+
+	// No filters, no preview.
+	html = testutil.SysAdminClient.GET("/work_items/bulk_requeue").
+		Expect().Status(http.StatusOK).Body().Raw()
+	assert.NotContains(t, html, "Requeue Items")
+
+	// Now requeue for real.
+	testutil.SysAdminClient.POST("/work_items/bulk_requeue").
+		WithQuery("node", workItem.Node).
+		WithQuery("target_stage", constants.StageValidate).
+		WithHeader("Referer", testutil.BaseURL).
+		WithFormField(constants.CSRFTokenName, testutil.SysAdminToken).
+		Expect().Status(http.StatusOK)
+
+	item, err = pgmodels.WorkItemByID(workItem.ID)
+	require.Nil(t, err)
+	assert.Equal(t, constants.StageValidate, item.Stage)
+	assert.Equal(t, constants.StatusPending, item.Status)
+	assert.Empty(t, item.Node)
+
+	// Other roles can't requeue.
+	testutil.Inst1AdminClient.GET("/work_items/bulk_requeue").
+		WithQuery("status", constants.StatusPending).
+		Expect().Status(http.StatusForbidden)
+	testutil.Inst1AdminClient.POST("/work_items/bulk_requeue").
+		WithQuery("status", constants.StatusPending).
+		WithHeader("Referer", testutil.BaseURL).
+		WithFormField(constants.CSRFTokenName, testutil.Inst1AdminToken).
+		Expect().Status(http.StatusForbidden)
+}
+
+func TestWorkItemReconcile(t *testing.T) {
+	testutil.InitHTTPTests(t)
+	ctx := common.Context()
+
+	// This item was never queued, and no one has touched it in a day.
+	workItem := testutil.CreateWorkItem(t, "unit_test_reconcile.tar")
+	_, err := ctx.DB.Exec("update work_items set status = ?, node = null, queued_at = null, updated_at = ? where id = ?",
+		constants.StatusPending, time.Now().UTC().Add(-24*time.Hour), workItem.ID)
+	require.Nil(t, err)
+
+	// This one completed, but left state in Redis.
+	require.Nil(t, ctx.RedisClient.SaveItem(30, "object:test", `{"key":"value"}`))
+	defer ctx.RedisClient.WorkItemDelete(30)
+
+	html := testutil.SysAdminClient.GET("/work_items/reconcile").
+		Expect().Status(http.StatusOK).Body().Raw()
+	assert.Contains(t, html, workItem.Name)
+	assert.Contains(t, html, pgmodels.OrphanNeverQueued)
+	assert.Contains(t, html, pgmodels.OrphanStaleRedisState)
+	assert.Contains(t, html, fmt.Sprintf("/work_items/reconcile/%d", workItem.ID))
+
+	testutil.SysAdminClient.POST("/work_items/reconcile/{id}", workItem.ID).
+		WithHeader("Referer", testutil.BaseURL).
+		WithFormField(constants.CSRFTokenName, testutil.SysAdminToken).
+		Expect().Status(http.StatusOK)
+	item, err := pgmodels.WorkItemByID(workItem.ID)
+	require.Nil(t, err)
+	assert.Equal(t, constants.StageRecord, item.Stage)
+	assert.Equal(t, constants.StatusPending, item.Status)
+	assert.False(t, item.QueuedAt.IsZero())
+
+	testutil.SysAdminClient.POST("/work_items/reconcile/30").
+		WithHeader("Referer", testutil.BaseURL).
+		WithFormField(constants.CSRFTokenName, testutil.SysAdminToken).
+		Expect().Status(http.StatusOK)
+	assert.False(t, ctx.RedisClient.KeyExists(30))
+
+	// Item 30 no longer needs reconciliation.
+	testutil.SysAdminClient.POST("/work_items/reconcile/30").
+		WithHeader("Referer", testutil.BaseURL).
+		WithFormField(constants.CSRFTokenName, testutil.SysAdminToken).
+		Expect().Status(http.StatusConflict)
+
+	// Other roles can't reconcile.
+	testutil.Inst1AdminClient.GET("/work_items/reconcile").
+		Expect().Status(http.StatusForbidden)
+	testutil.Inst1AdminClient.POST("/work_items/reconcile/{id}", workItem.ID).
+		WithHeader("Referer", testutil.BaseURL).
+		WithFormField(constants.CSRFTokenName, testutil.Inst1AdminToken).
+		Expect().Status(http.StatusForbidden)
+}
+
+func TestWorkItemRedisShow(t *testing.T) {
+	testutil.InitHTTPTests(t)
+	ctx := common.Context()
+	require.Nil(t, ctx.RedisClient.SaveItem(32, "file:test.edu/bag/data/good.txt",
+		`{"path_in_bag":"data/good.txt","checksums":[{"algorithm":"md5","digest":"aaa","source":"manifest"},{"algorithm":"md5","digest":"aaa","source":"ingest"}]}`))
+	require.Nil(t, ctx.RedisClient.SaveItem(32, "file:test.edu/bag/data/bad.txt",
+		`{"path_in_bag":"data/bad.txt","error_message":"digest mismatch"}`))
+	require.Nil(t, ctx.RedisClient.SaveItem(32, "mystery", `{"odd_key":"odd_value"}`))
+	defer ctx.RedisClient.WorkItemDelete(32)
+
+	html := testutil.SysAdminClient.GET("/work_items/redis/32").
+		Expect().Status(http.StatusOK).Body().Raw()
+	assert.Contains(t, html, "data/good.txt")
+	assert.Contains(t, html, "data/bad.txt")
+	assert.Contains(t, html, "digest mismatch")
+	assert.Contains(t, html, "odd_value")
+
+	html = testutil.SysAdminClient.GET("/work_items/redis/32").
+		WithQuery("file_filter", "error").
+		Expect().Status(http.StatusOK).Body().Raw()
+	assert.NotContains(t, html, "data/good.txt")
+	assert.Contains(t, html, "data/bad.txt")
+
+	// Files are sorted by path and paged.
+	html = testutil.SysAdminClient.GET("/work_items/redis/32").
+		WithQuery("page", 2).
+		WithQuery("per_page", 1).
+		Expect().Status(http.StatusOK).Body().Raw()
+	assert.Contains(t, html, "data/good.txt")
+	assert.NotContains(t, html, "data/bad.txt")
+	assert.Contains(t, html, "2 - 2 of 2")
+
+	testutil.SysAdminClient.GET("/work_items/redis/32").
+		WithQuery("file_filter", "bogus").
+		Expect().Status(http.StatusBadRequest)
+
+	// Only sys admins can read Redis data.
+	testutil.Inst1AdminClient.GET("/work_items/redis/32").
+		Expect().Status(http.StatusForbidden)
+}
+
 /*
 Note:
 